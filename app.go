@@ -1,9 +1,13 @@
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/EnvCLI/EnvCLI/pkg/cmd"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
 	"github.com/mattn/go-colorable"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -43,9 +47,17 @@ func init() {
 
 // CLI Main Entrypoint
 func main() {
+	// cancel on interrupt, this stops downloads and the container commands
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
 	// run
-	cmdErr := cmd.Execute()
+	cmdErr := cmd.Execute(ctx)
+	stop()
 	if cmdErr != nil {
-		log.Fatal().Err(cmdErr).Msg("cli error")
+		// failed commands already printed their output, the exit code is passed through
+		if !exitcode.IsSilent(cmdErr) {
+			log.Error().Msg(cmdErr.Error())
+		}
+		os.Exit(exitcode.Of(cmdErr))
 	}
 }
@@ -0,0 +1,28 @@
+// Example of embedding envcli: runs a command within the container configured in the .envcli.yml of the current project.
+//
+//	go run ./examples/library go version
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/EnvCLI/EnvCLI/pkg/envcli"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: library <command> [args...]")
+		os.Exit(2)
+	}
+
+	runner := envcli.NewRunner(envcli.Options{
+		Env: []string{"EMBEDDED_BY=library-example"},
+	})
+	code, err := runner.Run(context.Background(), os.Args[1], os.Args[2:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to run %s: %v\n", os.Args[1], err)
+	}
+	os.Exit(code)
+}
@@ -1,9 +1,12 @@
 package aliases
 
 import (
+	"bytes"
 	"github.com/cidverse/cidverseutils/pkg/filesystem"
 	"github.com/rs/zerolog/log"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"runtime"
 
 	common "github.com/EnvCLI/EnvCLI/pkg/common"
@@ -40,3 +43,38 @@ func InstallAlias(command string, scope string) error {
 
 	return nil
 }
+
+// ListInstalledAliases returns the paths of all alias scripts that have been installed by envcli
+func ListInstalledAliases() ([]string, error) {
+	var installed []string
+	directory := filesystem.GetExecutionDirectory()
+
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return installed, err
+	}
+
+	var scripts [][]byte
+	for _, name := range AssetNames() {
+		scripts = append(scripts, MustAsset(name))
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file := filepath.Join(directory, entry.Name())
+		content, readErr := ioutil.ReadFile(file)
+		if readErr != nil {
+			continue
+		}
+		for _, script := range scripts {
+			if bytes.Equal(content, script) {
+				installed = append(installed, file)
+				break
+			}
+		}
+	}
+
+	return installed, nil
+}
@@ -0,0 +1,36 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".envcli.yml")
+
+	if err := WriteFile(file, []byte("images: []\n"), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := os.Chmod(file, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(file, []byte("images:\n  - name: node\n"), 0644); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil || string(content) != "images:\n  - name: node\n" {
+		t.Errorf("unexpected content %q (%v)", content, err)
+	}
+	if info, _ := os.Stat(file); runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Errorf("expected the permissions of the existing file to be kept, got %v", info.Mode().Perm())
+	}
+
+	// no temporary files are left behind
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected only the file within the directory, got %v", entries)
+	}
+}
@@ -0,0 +1,153 @@
+// Package audit writes a record of each container run as JSON line into a append-only file, the syslog or a unix socket (audit-log property).
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// syslogPriority is the priority of the syslog messages: facility log audit (13), severity informational (6)
+const syslogPriority = 13*8 + 6
+
+// syslogTag identifies the messages of envcli within the syslog
+const syslogTag = "envcli"
+
+// dialTimeout limits connecting to the syslog and unix socket endpoints
+const dialTimeout = 5 * time.Second
+
+// syslogSockets are the local syslog sockets of linux, macOS and the BSDs, the first existing socket is used
+var syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// Record is the audit record of a single run
+type Record struct {
+	Time time.Time `json:"time"`
+
+	// User and Host executed the run
+	User string `json:"user"`
+	Host string `json:"host"`
+
+	// Project is the project directory, the working directory outside of projects
+	Project string `json:"project"`
+
+	// Command is the command with its arguments, secrets are masked
+	Command []string `json:"command"`
+
+	Entry  string `json:"entry,omitempty"`
+	Image  string `json:"image,omitempty"`
+	Digest string `json:"digest,omitempty"`
+
+	ExitCode int `json:"exitCode"`
+
+	// Error is the reason of a failed run, ex. a run that failed to start - secrets are masked
+	Error string `json:"error,omitempty"`
+}
+
+// Target is a audit log endpoint:
+//   - a file path, the records are appended to the file
+//   - syslog for the local syslog, syslog://host:port for a remote syslog via udp
+//   - unix:///path/to/socket for a unix stream socket
+type Target string
+
+// Write writes the record as a single JSON line
+func (t Target) Write(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case string(t) == "syslog":
+		return writeLocalSyslog(line)
+	case strings.HasPrefix(string(t), "syslog://"):
+		u, parseErr := url.Parse(string(t))
+		if parseErr != nil || u.Host == "" {
+			return fmt.Errorf("invalid audit log %s, expected syslog://host:port", t)
+		}
+		return writeSyslog("udp", u.Host, line)
+	case strings.HasPrefix(string(t), "unix://"):
+		return writeSocket("unix", strings.TrimPrefix(string(t), "unix://"), append(line, '\n'))
+	}
+	return appendFile(string(t), append(line, '\n'))
+}
+
+// Check returns a error if records can't be written to the target, without writing a record
+func (t Target) Check() error {
+	switch {
+	case string(t) == "syslog":
+		_, err := localSyslogSocket()
+		return err
+	case strings.HasPrefix(string(t), "syslog://"):
+		if u, err := url.Parse(string(t)); err != nil || u.Host == "" {
+			return fmt.Errorf("invalid audit log %s, expected syslog://host:port", t)
+		}
+		return nil
+	case strings.HasPrefix(string(t), "unix://"):
+		return writeSocket("unix", strings.TrimPrefix(string(t), "unix://"), nil)
+	}
+	return appendFile(string(t), nil)
+}
+
+// appendFile appends the data to the file, the file and its directory are created if missing
+func appendFile(file string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	// a single write per record, so that concurrent runs don't interleave their records
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeSocket sends the data to the socket
+func writeSocket(network string, address string, data []byte) error {
+	conn, err := net.DialTimeout(network, address, dialTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_ = conn.SetWriteDeadline(time.Now().Add(dialTimeout))
+	if len(data) > 0 {
+		_, err = conn.Write(data)
+	}
+	return err
+}
+
+// localSyslogSocket returns the first existing local syslog socket
+func localSyslogSocket() (string, error) {
+	for _, socket := range syslogSockets {
+		if _, err := os.Stat(socket); err == nil {
+			return socket, nil
+		}
+	}
+	return "", errors.New("no local syslog socket found (" + strings.Join(syslogSockets, ", ") + ")")
+}
+
+// writeLocalSyslog sends the record to the local syslog
+func writeLocalSyslog(line []byte) error {
+	socket, err := localSyslogSocket()
+	if err != nil {
+		return err
+	}
+	return writeSyslog("unixgram", socket, line)
+}
+
+// writeSyslog sends the record as syslog message (RFC 3164)
+func writeSyslog(network string, address string, line []byte) error {
+	hostname, _ := os.Hostname()
+	message := fmt.Sprintf("<%d>%s %s %s[%d]: %s", syslogPriority, time.Now().Format(time.Stamp), hostname, syslogTag, os.Getpid(), line)
+	return writeSocket(network, address, []byte(message))
+}
@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testRecord = Record{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), User: "dev", Host: "laptop", Project: "/src/app", Command: []string{"npm", "test"}, ExitCode: 1}
+
+func TestWriteFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+	for i := 0; i < 2; i++ {
+		if err := Target(file).Write(testRecord); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, _ := os.ReadFile(file)
+	expected := `{"time":"2024-01-02T03:04:05Z","user":"dev","host":"laptop","project":"/src/app","command":["npm","test"],"exitCode":1}` + "\n"
+	if string(content) != expected+expected {
+		t.Errorf("expected the records to be appended, got %s", content)
+	}
+}
+
+func TestWriteUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are not supported")
+	}
+	socket := filepath.Join(t.TempDir(), "audit.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, acceptErr := listener.Accept()
+		if acceptErr != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+
+	if err = Target("unix://" + socket).Write(testRecord); err != nil {
+		t.Fatal(err)
+	}
+	var record Record
+	if err = json.Unmarshal([]byte(<-received), &record); err != nil || record.Project != "/src/app" {
+		t.Errorf("unexpected record %+v (%v)", record, err)
+	}
+}
+
+func TestWriteSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err = Target("syslog://" + conn.LocalAddr().String()).Write(testRecord); err != nil {
+		t.Fatal(err)
+	}
+	buffer := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := string(buffer[:n])
+	if !strings.HasPrefix(message, "<110>") || !strings.Contains(message, " envcli[") || !strings.HasSuffix(message, `"exitCode":1}`) {
+		t.Errorf("unexpected syslog message %s", message)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Target(filepath.Join(t.TempDir(), "audit.jsonl")).Check(); err != nil {
+		t.Errorf("expected the file to be writable, got %v", err)
+	}
+	if err := Target("unix://" + filepath.Join(t.TempDir(), "missing.sock")).Check(); err == nil {
+		t.Errorf("expected a error for a missing socket")
+	}
+	if err := Target("syslog://").Check(); err == nil {
+		t.Errorf("expected a error for a syslog endpoint without host")
+	}
+}
@@ -0,0 +1,100 @@
+// Package catalog provides the curated list of tool images, used to discover images and add them to the envcli configuration.
+package catalog
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/download"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultURL is the official catalog, used if the catalog-url property isn't set
+const DefaultURL = "https://raw.githubusercontent.com/EnvCLI/catalog/main/catalog.yml"
+
+// CacheTTL is the duration a downloaded catalog is used, before it is downloaded again
+var CacheTTL = 24 * time.Hour
+
+// Catalog is the schema of the catalog file
+type Catalog struct {
+	Tools []Tool `yaml:"tools"`
+}
+
+// Tool is a single image of the catalog
+type Tool struct {
+	// name of the entry added to the configuration
+	Name string `yaml:"name"`
+
+	// short description, shown in search results
+	Description string `yaml:"description"`
+
+	// container image
+	Image string `yaml:"image"`
+
+	// keywords used by the search (ex. javascript)
+	Tags []string `yaml:"tags"`
+
+	// the commands provided by the image
+	Provides []string `yaml:"provides"`
+
+	// recommended cache directories of the tool
+	Caching []config.CachingEntry `yaml:"cache"`
+}
+
+// Load returns the catalog from the url, a cached catalog is used until it expires or refresh is set
+func Load(ctx context.Context, url string, cacheDir string, refresh bool) (Catalog, error) {
+	d := download.New(cacheDir)
+
+	file := d.CacheFile(url)
+	if info, err := os.Stat(file); err == nil && !refresh && time.Since(info.ModTime()) < CacheTTL {
+		log.Debug().Str("url", url).Str("file", file).Msg("using cached catalog")
+	} else {
+		file, err = d.Download(ctx, url, "")
+		if err != nil {
+			return Catalog{}, err
+		}
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Catalog{}, err
+	}
+
+	var catalog Catalog
+	if err = yaml.Unmarshal(data, &catalog); err != nil {
+		return Catalog{}, err
+	}
+	return catalog, nil
+}
+
+// Search returns the tools whose name, image, tags, provided commands or description contain the term (case-insensitive)
+func (c Catalog) Search(term string) []Tool {
+	term = strings.ToLower(term)
+
+	var tools []Tool
+	for _, tool := range c.Tools {
+		fields := append([]string{tool.Name, tool.Image, tool.Description}, tool.Tags...)
+		fields = append(fields, tool.Provides...)
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), term) {
+				tools = append(tools, tool)
+				break
+			}
+		}
+	}
+	return tools
+}
+
+// Get returns the tool with the given name
+func (c Catalog) Get(name string) (Tool, bool) {
+	for _, tool := range c.Tools {
+		if tool.Name == name {
+			return tool, true
+		}
+	}
+	return Tool{}, false
+}
@@ -0,0 +1,129 @@
+package catalog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+const testCatalog = `tools:
+  - name: node
+    description: JavaScript runtime
+    image: docker.io/node:20
+    tags: [javascript, npm]
+    provides: [node, npm, npx]
+    cache:
+      - name: npm
+        directory: /root/.npm
+  - name: golang
+    description: The Go programming language
+    image: docker.io/golang:1.21
+    provides: [go, gofmt]
+`
+
+func TestLoad(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = w.Write([]byte(testCatalog))
+	}))
+	defer server.Close()
+	cacheDir := t.TempDir()
+
+	c, err := Load(context.Background(), server.URL, cacheDir, false)
+	if err != nil || len(c.Tools) != 2 {
+		t.Fatalf("expected two tools, got %v (%v)", c.Tools, err)
+	}
+
+	// cached
+	if _, err = Load(context.Background(), server.URL, cacheDir, false); err != nil || requests != 1 {
+		t.Errorf("expected the cached catalog to be used, requests: %d, err: %v", requests, err)
+	}
+	if _, err = Load(context.Background(), server.URL, cacheDir, true); err != nil || requests != 2 {
+		t.Errorf("expected refresh to download the catalog again, requests: %d, err: %v", requests, err)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	var c Catalog
+	if err := yaml.Unmarshal([]byte(testCatalog), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		term     string
+		expected string
+	}{
+		{"", "node,golang"},
+		{"NODE", "node"},
+		{"javascript", "node"},
+		{"gofmt", "golang"},
+		{"programming", "golang"},
+		{"docker.io", "node,golang"},
+		{"python", ""},
+	}
+
+	for _, test := range tests {
+		var names []string
+		for _, tool := range c.Search(test.term) {
+			names = append(names, tool.Name)
+		}
+		if strings.Join(names, ",") != test.expected {
+			t.Errorf("%s: expected %s, got %v", test.term, test.expected, names)
+		}
+	}
+}
+
+func TestAddToConfigFile(t *testing.T) {
+	var c Catalog
+	if err := yaml.Unmarshal([]byte(testCatalog), &c); err != nil {
+		t.Fatal(err)
+	}
+	node, _ := c.Get("node")
+	golang, _ := c.Get("golang")
+
+	// new file
+	file := filepath.Join(t.TempDir(), ".envcli.yml")
+	if err := AddToConfigFile(file, node); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// existing file, keeps the other keys
+	if err := os.WriteFile(file, []byte("inheritParentConfigs: true\n"+mustRead(t, file)+"tasks:\n  - name: build\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddToConfigFile(file, golang); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	cfg, err := config.LoadProjectConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.InheritParentConfigs || len(cfg.Tasks) != 1 || len(cfg.Images) != 2 {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	if cfg.Images[0].Name != "node" || cfg.Images[0].Caching[0].ContainerDirectory != "/root/.npm" || cfg.Images[1].Image != "docker.io/golang:1.21" {
+		t.Errorf("unexpected images %+v", cfg.Images)
+	}
+
+	// duplicate
+	if err = AddToConfigFile(file, node); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected the duplicate entry to be rejected, got %v", err)
+	}
+}
+
+func mustRead(t *testing.T, file string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,75 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/EnvCLI/EnvCLI/pkg/atomicfile"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigEntry returns the configuration entry of the tool, empty fields are omitted
+func (t Tool) ConfigEntry() yaml.MapSlice {
+	entry := yaml.MapSlice{{Key: "name", Value: t.Name}}
+	if t.Description != "" {
+		entry = append(entry, yaml.MapItem{Key: "description", Value: t.Description})
+	}
+	entry = append(entry, yaml.MapItem{Key: "image", Value: t.Image})
+	if len(t.Provides) > 0 {
+		entry = append(entry, yaml.MapItem{Key: "provides", Value: t.Provides})
+	}
+	if len(t.Caching) > 0 {
+		entry = append(entry, yaml.MapItem{Key: "cache", Value: t.Caching})
+	}
+	return entry
+}
+
+// AddToConfigFile appends the tool to the images of the configuration file, the file is created if it doesn't exist.
+// The order of the existing keys is kept, comments are not preserved.
+func AddToConfigFile(file string, tool Tool) error {
+	var content yaml.MapSlice
+	if data, err := os.ReadFile(file); err == nil {
+		if err = yaml.Unmarshal(data, &content); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// existing entries
+	var existing config.ConfigurationFile
+	if data, err := yaml.Marshal(content); err == nil {
+		_ = yaml.Unmarshal(data, &existing)
+	}
+	for _, entry := range existing.Images {
+		if entry.Name == tool.Name {
+			return errors.New("an entry named " + tool.Name + " already exists in " + file)
+		}
+	}
+
+	// append to the images
+	added := false
+	for i, item := range content {
+		if item.Key != "images" {
+			continue
+		}
+		images, _ := item.Value.([]interface{})
+		content[i].Value = append(images, tool.ConfigEntry())
+		added = true
+	}
+	if !added {
+		content = append(content, yaml.MapItem{Key: "images", Value: []interface{}{tool.ConfigEntry()}})
+	}
+
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(file, data, 0644)
+}
@@ -0,0 +1,168 @@
+// Package ciannotation writes the log markers of CI providers, to group the output of a run into a collapsible section and to surface failures in the UI.
+package ciannotation
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Providers supported by --ci-annotations
+const (
+	ProviderAuto   = "auto"
+	ProviderGitHub = "github"
+	ProviderGitLab = "gitlab"
+	ProviderOff    = "off"
+)
+
+// Providers are the valid values of --ci-annotations
+var Providers = []string{ProviderAuto, ProviderGitHub, ProviderGitLab, ProviderOff}
+
+// Resolve validates the provider and resolves auto using the environment variables of the CI providers, auto resolves to off outside of CI and empty to off
+func Resolve(provider string, getenv func(string) string) (string, error) {
+	switch provider {
+	case "":
+		return ProviderOff, nil
+	case ProviderAuto:
+		if getenv("GITHUB_ACTIONS") == "true" {
+			return ProviderGitHub, nil
+		} else if getenv("GITLAB_CI") == "true" {
+			return ProviderGitLab, nil
+		}
+		return ProviderOff, nil
+	case ProviderGitHub, ProviderGitLab, ProviderOff:
+		return provider, nil
+	}
+	return "", fmt.Errorf("invalid ci annotations %s, allowed: %s", provider, strings.Join(Providers, ", "))
+}
+
+// sectionNameChars are not allowed in gitlab section names
+var sectionNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// StartSection writes the marker of a collapsible section with the title
+func StartSection(w io.Writer, provider string, name string, title string, started time.Time) {
+	switch provider {
+	case ProviderGitHub:
+		fmt.Fprintf(w, "::group::%s\n", title)
+	case ProviderGitLab:
+		fmt.Fprintf(w, "\x1b[0Ksection_start:%d:%s[collapsed=true]\r\x1b[0K%s\n", started.Unix(), sectionName(name), title)
+	}
+}
+
+// EndSection writes the marker ending the section, the summary is written as last line of the section
+func EndSection(w io.Writer, provider string, name string, summary string, ended time.Time) {
+	switch provider {
+	case ProviderGitHub:
+		fmt.Fprintf(w, "%s\n::endgroup::\n", summary)
+	case ProviderGitLab:
+		fmt.Fprintf(w, "%s\n\x1b[0Ksection_end:%d:%s\r\x1b[0K\n", summary, ended.Unix(), sectionName(name))
+	}
+}
+
+func sectionName(name string) string {
+	return "envcli_" + sectionNameChars.ReplaceAllString(name, "_")
+}
+
+// colorPattern matches the ansi color codes of colored output
+var colorPattern = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
+
+// locationPattern matches the file location at the start of compiler errors (ex. src/main.go:12:5: message)
+var locationPattern = regexp.MustCompile(`^([^\s:]+|[a-zA-Z]:\\[^\s:]+):(\d+)(?::(\d+))?:\s*(.*)$`)
+
+// Annotation is a error shown by the CI provider in its UI
+type Annotation struct {
+	File    string
+	Line    string
+	Column  string
+	Message string
+}
+
+// ParseAnnotation converts a output line into a annotation, the file location is detected at the start of the line.
+// Absolute files within the workspace are made relative to the workspace.
+func ParseAnnotation(line string, workspace string) Annotation {
+	line = strings.TrimSpace(colorPattern.ReplaceAllString(line, ""))
+	match := locationPattern.FindStringSubmatch(line)
+	if match == nil {
+		return Annotation{Message: line}
+	}
+
+	file := match[1]
+	if workspace != "" && filepath.IsAbs(file) {
+		if relative, err := filepath.Rel(workspace, file); err == nil && !strings.HasPrefix(relative, "..") {
+			file = filepath.ToSlash(relative)
+		}
+	}
+	return Annotation{File: file, Line: match[2], Column: match[3], Message: match[4]}
+}
+
+// WriteAnnotation writes the error annotation, only github supports annotations
+func WriteAnnotation(w io.Writer, provider string, annotation Annotation) {
+	if provider != ProviderGitHub || annotation.Message == "" {
+		return
+	}
+
+	var properties []string
+	if annotation.File != "" {
+		properties = append(properties, "file="+escapeProperty(annotation.File), "line="+annotation.Line)
+		if annotation.Column != "" {
+			properties = append(properties, "col="+annotation.Column)
+		}
+	}
+	if len(properties) > 0 {
+		fmt.Fprintf(w, "::error %s::%s\n", strings.Join(properties, ","), escapeData(annotation.Message))
+	} else {
+		fmt.Fprintf(w, "::error::%s\n", escapeData(annotation.Message))
+	}
+}
+
+func escapeData(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(value)
+}
+
+func escapeProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}
+
+// maxLineLength is the maximum length of the remembered line, longer lines are truncated
+const maxLineLength = 4096
+
+// LastLine remembers the last non-empty line written to it, it can be shared by stdout and stderr
+type LastLine struct {
+	mutex   sync.Mutex
+	current []byte
+	last    string
+}
+
+func (l *LastLine) Write(p []byte) (int, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for _, b := range p {
+		if b != '\n' {
+			if len(l.current) < maxLineLength {
+				l.current = append(l.current, b)
+			}
+			continue
+		}
+		if line := strings.TrimSpace(string(l.current)); line != "" {
+			l.last = line
+		}
+		l.current = l.current[:0]
+	}
+	return len(p), nil
+}
+
+// String returns the last non-empty line, including a incomplete line
+func (l *LastLine) String() string {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if line := strings.TrimSpace(string(l.current)); line != "" {
+		return line
+	}
+	return l.last
+}
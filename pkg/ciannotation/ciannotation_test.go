@@ -0,0 +1,94 @@
+package ciannotation
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestResolve(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+
+	cases := []struct {
+		provider string
+		env      map[string]string
+		expected string
+	}{
+		{ProviderAuto, map[string]string{"GITHUB_ACTIONS": "true"}, ProviderGitHub},
+		{ProviderAuto, map[string]string{"GITLAB_CI": "true"}, ProviderGitLab},
+		{ProviderAuto, nil, ProviderOff},
+		{"", map[string]string{"GITHUB_ACTIONS": "true"}, ProviderOff},
+		{ProviderGitLab, map[string]string{"GITHUB_ACTIONS": "true"}, ProviderGitLab},
+	}
+	for _, c := range cases {
+		if provider, err := Resolve(c.provider, env(c.env)); err != nil || provider != c.expected {
+			t.Errorf("expected %s for %s (%v), got %s (%v)", c.expected, c.provider, c.env, provider, err)
+		}
+	}
+
+	if _, err := Resolve("jenkins", env(nil)); err == nil {
+		t.Errorf("expected unknown provider to be rejected")
+	}
+}
+
+func TestSections(t *testing.T) {
+	started := time.Unix(1700000000, 0)
+
+	var github bytes.Buffer
+	StartSection(&github, ProviderGitHub, "go", "envcli: go test", started)
+	EndSection(&github, ProviderGitHub, "go", "done", started)
+	if github.String() != "::group::envcli: go test\ndone\n::endgroup::\n" {
+		t.Errorf("unexpected github section %q", github.String())
+	}
+
+	var gitlab bytes.Buffer
+	StartSection(&gitlab, ProviderGitLab, "go vet", "envcli: go vet", started)
+	EndSection(&gitlab, ProviderGitLab, "go vet", "done", started)
+	expected := "\x1b[0Ksection_start:1700000000:envcli_go_vet[collapsed=true]\r\x1b[0Kenvcli: go vet\ndone\n\x1b[0Ksection_end:1700000000:envcli_go_vet\r\x1b[0K\n"
+	if gitlab.String() != expected {
+		t.Errorf("unexpected gitlab section %q", gitlab.String())
+	}
+
+	var off bytes.Buffer
+	StartSection(&off, ProviderOff, "go", "envcli: go test", started)
+	if off.Len() != 0 {
+		t.Errorf("expected no output, got %q", off.String())
+	}
+}
+
+func TestParseAnnotation(t *testing.T) {
+	cases := []struct {
+		line     string
+		expected Annotation
+	}{
+		{"/work/repo/src/main.go:12:5: undefined: foo", Annotation{File: "src/main.go", Line: "12", Column: "5", Message: "undefined: foo"}},
+		{"\x1b[31msrc/app.ts:3: error TS2304\x1b[0m", Annotation{File: "src/app.ts", Line: "3", Message: "error TS2304"}},
+		{"FAIL: tests failed", Annotation{Message: "FAIL: tests failed"}},
+	}
+	for _, c := range cases {
+		if annotation := ParseAnnotation(c.line, "/work/repo"); annotation != c.expected {
+			t.Errorf("expected %+v for %q, got %+v", c.expected, c.line, annotation)
+		}
+	}
+}
+
+func TestWriteAnnotation(t *testing.T) {
+	var out bytes.Buffer
+	WriteAnnotation(&out, ProviderGitHub, Annotation{File: "src/a,b.go", Line: "3", Column: "1", Message: "100% broken"})
+	WriteAnnotation(&out, ProviderGitHub, Annotation{Message: "failed"})
+	WriteAnnotation(&out, ProviderGitLab, Annotation{Message: "failed"})
+	if out.String() != "::error file=src/a%2Cb.go,line=3,col=1::100%25 broken\n::error::failed\n" {
+		t.Errorf("unexpected annotations %q", out.String())
+	}
+}
+
+func TestLastLine(t *testing.T) {
+	var l LastLine
+	_, _ = l.Write([]byte("first\nsrc/main.go:3: er"))
+	_, _ = l.Write([]byte("ror\n\n  \n"))
+	if l.String() != "src/main.go:3: error" {
+		t.Errorf("unexpected last line %q", l.String())
+	}
+}
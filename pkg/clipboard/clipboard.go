@@ -0,0 +1,64 @@
+// Package clipboard places text on the system clipboard using the clipboard tool of the operating system.
+package clipboard
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNoClipboard is returned if the system has no supported clipboard tool, ex. on headless systems
+var ErrNoClipboard = errors.New("no clipboard available")
+
+// timeout of the clipboard tool, it must not delay envcli
+const timeout = 5 * time.Second
+
+// Copy places the text on the clipboard: pbcopy on macOS, clip on Windows and WSL, wl-copy, xclip or xsel on Linux
+func Copy(ctx context.Context, text string) error {
+	args := Command(runtime.GOOS, os.Getenv, exec.LookPath)
+	if args == nil {
+		return ErrNoClipboard
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	log.Debug().Str("tool", args[0]).Msg("copying text to the clipboard")
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// Command returns the first installed clipboard tool for the operating system, or nil if there is none.
+// On Linux the tools of a display server are only used within a graphical session, clip.exe is used within WSL.
+func Command(goos string, getenv func(key string) string, lookPath func(file string) (string, error)) []string {
+	var candidates [][]string
+	switch goos {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		if getenv("WAYLAND_DISPLAY") != "" {
+			candidates = append(candidates, []string{"wl-copy"})
+		}
+		if getenv("DISPLAY") != "" {
+			candidates = append(candidates, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+		}
+		if getenv("WSL_DISTRO_NAME") != "" {
+			candidates = append(candidates, []string{"clip.exe"})
+		}
+	}
+
+	for _, args := range candidates {
+		if _, err := lookPath(args[0]); err == nil {
+			return args
+		}
+	}
+	return nil
+}
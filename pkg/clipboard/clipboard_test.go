@@ -0,0 +1,41 @@
+package clipboard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	installed := func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	only := func(tool string) func(file string) (string, error) {
+		return func(file string) (string, error) {
+			if file == tool {
+				return "/usr/bin/" + file, nil
+			}
+			return "", errors.New("not found")
+		}
+	}
+	env := func(values map[string]string) func(key string) string {
+		return func(key string) string { return values[key] }
+	}
+
+	if args := Command("darwin", env(nil), installed); strings.Join(args, " ") != "pbcopy" {
+		t.Errorf("unexpected macos command %v", args)
+	}
+	if args := Command("windows", env(nil), installed); strings.Join(args, " ") != "clip" {
+		t.Errorf("unexpected windows command %v", args)
+	}
+	if args := Command("linux", env(map[string]string{"WAYLAND_DISPLAY": "wayland-0", "DISPLAY": ":0"}), installed); strings.Join(args, " ") != "wl-copy" {
+		t.Errorf("expected wl-copy within wayland, got %v", args)
+	}
+	if args := Command("linux", env(map[string]string{"DISPLAY": ":0"}), only("xsel")); strings.Join(args, " ") != "xsel --clipboard --input" {
+		t.Errorf("expected xsel if xclip is missing, got %v", args)
+	}
+	if args := Command("linux", env(map[string]string{"WSL_DISTRO_NAME": "Ubuntu"}), installed); strings.Join(args, " ") != "clip.exe" {
+		t.Errorf("expected clip.exe within wsl, got %v", args)
+	}
+	if args := Command("linux", env(nil), installed); args != nil {
+		t.Errorf("expected no command on a headless system, got %v", args)
+	}
+}
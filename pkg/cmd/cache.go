@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/spf13/cobra"
+)
+
+// defaultCacheImageMaxSize is the default maximum size of cache images
+const defaultCacheImageMaxSize = "5GB"
+
+// newCacheCmd creates the cache command
+func newCacheCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:     "cache",
+		Short:   "shares the cache directories of the entries using images in a container registry",
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cacheCmd.PersistentFlags().String("max-size", defaultCacheImageMaxSize, "Maximum size of the cache (ex. 500MB, 2GB)")
+	cacheCmd.AddCommand(newCachePushCmd(detectRuntime))
+	cacheCmd.AddCommand(newCachePullCmd(detectRuntime))
+	cacheCmd.AddCommand(newCacheClearCmd())
+
+	return cacheCmd
+}
+
+// clearableCaches are the internal caches of envcli, which can be removed using envcli cache clear
+var clearableCaches = []string{"config"}
+
+// newCacheClearCmd creates the cache clear command
+func newCacheClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:       "clear <" + strings.Join(clearableCaches, "|") + ">",
+		Short:     "removes a internal cache of envcli, config removes the parsed configuration files",
+		Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		ValidArgs: clearableCaches,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := config.GetConfigCacheDirectory(propConfig)
+			if err := config.ClearConfigCache(dir); err != nil {
+				return fmt.Errorf("failed to clear the %s cache: %w", args[0], err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Cleared the %s cache (%s)\n", args[0], dir)
+			return nil
+		},
+	}
+}
+
+// newCachePushCmd creates the cache push command
+func newCachePushCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	pushCmd := &cobra.Command{
+		Use:   "push <cache> --to <image>",
+		Short: "packs the cache directory into a image and pushes it to the registry",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			image, _ := cmd.Flags().GetString("to")
+			runtime, dir, maxSize, err := cacheImageSetup(cmd, detectRuntime, args[0])
+			if err != nil {
+				return err
+			}
+			if _, statErr := os.Stat(dir); statErr != nil {
+				return exitcode.New(exitcode.ConfigError, fmt.Errorf("cache %s doesn't exist in %s, run a command using the cache first", args[0], dir))
+			}
+
+			digest, size, err := containerutil.DirectoryDigest(dir)
+			if err != nil {
+				return fmt.Errorf("failed to read the cache %s: %w", args[0], err)
+			}
+			if size > maxSize {
+				return fmt.Errorf("cache %s has %s, which exceeds the maximum size of %s (--max-size)", args[0], formatSize(size), formatSize(maxSize))
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Pushing cache %s (%s) to %s\n", args[0], formatSize(size), image)
+			if err = containerutil.PushCacheImage(cmd.Context(), runtime, dir, image, digest, cmd.ErrOrStderr()); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Pushed cache %s to %s\n", args[0], image)
+			return nil
+		},
+	}
+	pushCmd.Flags().String("to", "", "Image reference the cache is pushed to (ex. registry.company.com/caches/npm:main)")
+	_ = pushCmd.MarkFlagRequired("to")
+
+	return pushCmd
+}
+
+// newCachePullCmd creates the cache pull command
+func newCachePullCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	pullCmd := &cobra.Command{
+		Use:   "pull <cache> --from <image>",
+		Short: "pulls the cache image and extracts it into the cache directory",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			image, _ := cmd.Flags().GetString("from")
+			runtime, dir, maxSize, err := cacheImageSetup(cmd, detectRuntime, args[0])
+			if err != nil {
+				return err
+			}
+			if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Pulling cache %s from %s\n", args[0], image)
+			if err = containerutil.PullCacheImage(cmd.Context(), runtime, image, dir, maxSize, cmd.ErrOrStderr()); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Pulled cache %s into %s\n", args[0], dir)
+			return nil
+		},
+	}
+	pullCmd.Flags().String("from", "", "Image reference the cache is pulled from (ex. registry.company.com/caches/npm:main)")
+	_ = pullCmd.MarkFlagRequired("from")
+
+	return pullCmd
+}
+
+// cacheImageSetup returns the container runtime, the directory of the cache and the maximum cache size
+func cacheImageSetup(cmd *cobra.Command, detectRuntime func() containerutil.ContainerRuntime, name string) (containerutil.ContainerRuntime, string, int64, error) {
+	maxSizeFlag, _ := cmd.Flags().GetString("max-size")
+	maxSize := containerutil.ParseHumanSize(maxSizeFlag)
+	if maxSize <= 0 {
+		return nil, "", 0, exitcode.New(exitcode.ConfigError, errors.New("invalid value for --max-size, expected a size like 2GB"))
+	}
+
+	if name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+		return nil, "", 0, exitcode.New(exitcode.ConfigError, errors.New("invalid cache name "+name+", expected the name of a cache entry (ex. npm)"))
+	}
+	cachePath := config.GetCachePath(propConfig).Path
+	if cachePath == "" {
+		return nil, "", 0, exitcode.New(exitcode.ConfigError, errors.New("caching is disabled, set the cache-path property first"))
+	}
+
+	runtime := detectRuntime()
+	if err := containerutil.RequireRuntime(runtime); err != nil {
+		return nil, "", 0, err
+	}
+	return runtime, filepath.Join(cachePath, name), maxSize, nil
+}
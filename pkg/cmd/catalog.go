@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/EnvCLI/EnvCLI/pkg/catalog"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/spf13/cobra"
+)
+
+// newCatalogCmd creates the catalog command
+func newCatalogCmd() *cobra.Command {
+	catalogCmd := &cobra.Command{
+		Use:     "catalog",
+		Short:   "searches the catalog of tool images and adds them to the configuration",
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	catalogCmd.PersistentFlags().Bool("refresh", false, "Downloads the catalog again, instead of using the cached catalog")
+	catalogCmd.AddCommand(newCatalogSearchCmd())
+	catalogCmd.AddCommand(newCatalogAddCmd())
+
+	return catalogCmd
+}
+
+// newCatalogSearchCmd creates the catalog search command
+func newCatalogSearchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "search <term>",
+		Short: "lists the catalog tools matching the term, all tools are listed without a term",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadCatalog(cmd)
+			if err != nil {
+				return err
+			}
+
+			term := ""
+			if len(args) > 0 {
+				term = args[0]
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 1, 1, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "NAME\tIMAGE\tPROVIDES\tDESCRIPTION")
+			for _, tool := range c.Search(term) {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", tool.Name, tool.Image, strings.Join(tool.Provides, ","), tool.Description)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// newCatalogAddCmd creates the catalog add command
+func newCatalogAddCmd() *cobra.Command {
+	addCmd := &cobra.Command{
+		Use:   "add <tool>",
+		Short: "adds the catalog tool to the project config, or the global config using --global",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			global, _ := cmd.Flags().GetBool("global")
+
+			c, err := loadCatalog(cmd)
+			if err != nil {
+				return err
+			}
+			tool, found := c.Get(args[0])
+			if !found {
+				return exitcode.New(exitcode.ConfigError, errors.New("tool "+args[0]+" not found in the catalog, use envcli catalog search to list the available tools"))
+			}
+
+			// target config, a project config is created in the working directory if there is none
+			file := config.GetGlobalConfigurationFile(propConfig)
+			if _, synced := config.GetGlobalRepo(propConfig); global && synced {
+				return exitcode.New(exitcode.ConfigError, config.ErrGlobalConfigReadOnly)
+			}
+			if !global {
+				file, err = config.GetProjectConfigFile()
+				if err != nil {
+					file = filepath.Join(filesystem.GetWorkingDirectory(), ".envcli.yml")
+				} else if config.IsEmbeddedConfig(file) {
+					return exitcode.New(exitcode.ConfigError, fmt.Errorf("%s: %w", file, config.ErrEmbeddedConfig))
+				}
+			}
+
+			if err = catalog.AddToConfigFile(file, tool); err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %s [%s] to %s\n", tool.Name, tool.Image, file)
+			return nil
+		},
+	}
+	addCmd.Flags().Bool("global", false, "Adds the tool to the global config instead of the project config")
+
+	return addCmd
+}
+
+// loadCatalog loads the catalog from the catalog-url property or the official catalog
+func loadCatalog(cmd *cobra.Command) (catalog.Catalog, error) {
+	refresh, _ := cmd.Flags().GetBool("refresh")
+	url := collection.MapGetValueOrDefault(propConfig.Properties, "catalog-url", "")
+	if url == "" {
+		url = catalog.DefaultURL
+	}
+
+	c, err := catalog.Load(cmd.Context(), url, config.GetDownloadCacheDirectory(propConfig), refresh)
+	if err != nil {
+		return catalog.Catalog{}, fmt.Errorf("failed to load the catalog from %s: %w", url, err)
+	}
+	return c, nil
+}
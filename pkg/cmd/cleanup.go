@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/thoas/go-funk"
+)
+
+// staleContainerCleanupInterval is the minimum time between two automatic cleanups of stale containers
+const staleContainerCleanupInterval = time.Hour
+
+// defaultContainerRetentionAge is the time stopped containers are kept before they are removed automatically
+const defaultContainerRetentionAge = "24h"
+
+// newCleanupCmd creates the cleanup command
+func newCleanupCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	cleanupCmd := &cobra.Command{
+		Use:     "cleanup",
+		Short:   "removes containers that have been retained by envcli run",
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+			runtime := detectRuntime()
+
+			containers, err := containerutil.ListRetainedContainers(cmd.Context(), runtime)
+			if err != nil {
+				return fmt.Errorf("failed to list retained containers: %w", err)
+			}
+			if all {
+				stopped, stoppedErr := containerutil.ListStoppedContainers(cmd.Context(), runtime, time.Time{})
+				if stoppedErr != nil {
+					return fmt.Errorf("failed to list stopped containers: %w", stoppedErr)
+				}
+				containers = appendUnique(containers, stopped...)
+
+				// the automatic cleanup is done for now
+				if markerErr := recordContainerCleanup(propConfig, time.Now()); markerErr != nil {
+					log.Debug().Err(markerErr).Msg("failed to store the time of the container cleanup")
+				}
+			}
+
+			for _, container := range containers {
+				log.Debug().Str("container", container).Msg("removing retained container")
+				if removeErr := containerutil.RemoveContainer(cmd.Context(), runtime, container); removeErr != nil {
+					log.Warn().Err(removeErr).Str("container", container).Msg("failed to remove retained container")
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed container [%s].\n", container)
+			}
+
+			return nil
+		},
+	}
+	cleanupCmd.Flags().Bool("all", false, "Also removes all other stopped containers started by envcli, ignoring the container-retention-age")
+
+	return cleanupCmd
+}
+
+// lastContainerCleanup returns the time of the last container cleanup, zero if there hasn't been one
+func lastContainerCleanup(props config.PropertyConfigurationFile) time.Time {
+	content, err := os.ReadFile(config.GetContainerCleanupMarker(props))
+	if err != nil {
+		return time.Time{}
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// recordContainerCleanup stores the time of the container cleanup within the cache directory
+func recordContainerCleanup(props config.PropertyConfigurationFile, now time.Time) error {
+	marker := config.GetContainerCleanupMarker(props)
+	if err := os.MkdirAll(filepath.Dir(marker), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(marker, []byte(strconv.FormatInt(now.Unix(), 10)+"\n"), 0644)
+}
+
+// removeStaleContainers removes the stopped containers started by envcli that finished more than container-retention-age ago.
+// It runs at most once per hour (see GetContainerCleanupMarker), a container-retention-age of 0 disables it.
+func removeStaleContainers(ctx context.Context, runtime containerutil.ContainerRuntime, props config.PropertyConfigurationFile, now time.Time) []string {
+	retentionAge, err := time.ParseDuration(collection.MapGetValueOrDefault(props.Properties, "container-retention-age", defaultContainerRetentionAge))
+	if err != nil || retentionAge <= 0 {
+		return nil
+	}
+	if now.Before(lastContainerCleanup(props).Add(staleContainerCleanupInterval)) {
+		return nil
+	}
+	if containerutil.RequireRuntime(runtime) != nil {
+		return nil
+	}
+
+	// store the time first, so that concurrent invocations don't clean up as well
+	if err = recordContainerCleanup(props, now); err != nil {
+		log.Debug().Err(err).Msg("failed to store the time of the container cleanup, skipping it")
+		return nil
+	}
+
+	containers, err := containerutil.ListStoppedContainers(ctx, runtime, now.Add(-retentionAge))
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to list the stale containers")
+		return nil
+	}
+	var removed []string
+	for _, container := range containers {
+		if removeErr := containerutil.RemoveContainer(ctx, runtime, container); removeErr != nil {
+			log.Debug().Err(removeErr).Str("container", container).Msg("failed to remove stale container")
+			continue
+		}
+		log.Debug().Str("container", container).Dur("retention-age", retentionAge).Msg("removed stale container")
+		removed = append(removed, container)
+	}
+	return removed
+}
+
+// appendUnique appends the values that aren't part of the slice yet
+func appendUnique(slice []string, values ...string) []string {
+	for _, value := range values {
+		if !funk.ContainsString(slice, value) {
+			slice = append(slice, value)
+		}
+	}
+	return slice
+}
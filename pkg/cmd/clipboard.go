@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/EnvCLI/EnvCLI/pkg/clipboard"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// copyText places the text on the system clipboard
+var copyText = clipboard.Copy
+
+// copyOrPrint copies the text to the clipboard and prints a confirmation, the text is printed instead if there is no clipboard (ex. on headless systems)
+func copyOrPrint(cmd *cobra.Command, text string, description string) {
+	if err := copyText(cmd.Context(), text); err != nil {
+		if errors.Is(err, clipboard.ErrNoClipboard) {
+			log.Debug().Msg("no clipboard available, printing the " + description + " instead")
+		} else {
+			log.Warn().Err(err).Msg("failed to copy the " + description + " to the clipboard, printing it instead")
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), text)
+		return
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Copied the %s to the clipboard.\n", description)
+}
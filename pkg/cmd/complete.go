@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/spf13/cobra"
+)
+
+// completeRunArgs completes the commands provided by the entries and the paths of the arguments as seen by the container of the matched entry.
+// The completion scripts generated by envcli completion call it using the hidden __complete command.
+func completeRunArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+
+	// the command
+	if len(args) == 0 {
+		mergedConfig, err := config.LoadMergedConfiguration(ctx, configIncludes)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var commands []string
+		for _, entry := range mergedConfig.Images {
+			for _, command := range entry.Provides {
+				if strings.HasPrefix(command, toComplete) {
+					commands = append(commands, command+"\t"+entry.Name)
+				}
+			}
+		}
+		return commands, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// the arguments, the host paths are only valid if the project is mounted at the same path
+	workingDirectory := filesystem.GetWorkingDirectory()
+	entry, err := config.GetCommandConfiguration(ctx, args[0], workingDirectory, configIncludes)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	mountRoot, err := config.ResolveMountRoot(workingDirectory, false)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	mount := config.ResolveMountPaths(mountRoot, workingDirectory, entry.Directory)
+	if mount.Target == filepath.ToSlash(mount.Source) {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+
+	completions := completeContainerPaths(mount, workingDirectory, toComplete)
+	directive := cobra.ShellCompDirectiveNoFileComp
+	for _, completion := range completions {
+		if strings.HasSuffix(completion, "/") {
+			directive |= cobra.ShellCompDirectiveNoSpace
+		}
+	}
+	return completions, directive
+}
+
+// completeContainerPaths completes the path as seen by the container, for flags (--name=path) only the value is completed - the completion scripts keep the flag.
+// Relative paths are relative to the working directory, which is mounted at the same relative location. Absolute paths use the container paths,
+// absolute host paths within the project are converted to their container path. Paths outside of the mount don't exist within the container and aren't completed.
+func completeContainerPaths(mount config.MountPaths, workingDirectory string, toComplete string) []string {
+	value := toComplete
+	if strings.HasPrefix(toComplete, "-") {
+		index := strings.Index(toComplete, "=")
+		if index < 0 {
+			return nil
+		}
+		value = toComplete[index+1:]
+	}
+
+	// directory that is listed and the prefix of the names within it
+	directory, namePrefix := "", value
+	if index := strings.LastIndex(value, "/"); index >= 0 {
+		directory, namePrefix = value[:index+1], value[index+1:]
+	}
+
+	var hostDirectory string
+	displayDirectory := directory
+	switch {
+	case strings.HasPrefix(value, "/"):
+		if hostPath, inside := mount.HostPath(directory); inside {
+			hostDirectory = hostPath
+		} else if containerPath, inside := mount.ContainerPath(filepath.FromSlash(directory)); inside {
+			hostDirectory = filepath.FromSlash(directory)
+			displayDirectory = strings.TrimRight(containerPath, "/") + "/"
+		} else if target := strings.TrimRight(mount.Target, "/") + "/"; strings.HasPrefix(target, value) {
+			// ex. /work completes the mount target /workspace/
+			return []string{target}
+		} else {
+			return nil
+		}
+	default:
+		hostDirectory = filepath.Join(workingDirectory, filepath.FromSlash(directory))
+		if _, inside := mount.ContainerPath(hostDirectory); !inside {
+			return nil
+		}
+	}
+
+	entries, err := os.ReadDir(hostDirectory)
+	if err != nil {
+		return nil
+	}
+	var completions []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, namePrefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(namePrefix, ".")) {
+			continue
+		}
+		if entry.IsDir() {
+			name += "/"
+		}
+		completions = append(completions, displayDirectory+name)
+	}
+	sort.Strings(completions)
+	return completions
+}
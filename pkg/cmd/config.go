@@ -1,83 +1,269 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/EnvCLI/EnvCLI/pkg/atomicfile"
 	"github.com/EnvCLI/EnvCLI/pkg/config"
-	"github.com/rs/zerolog/log"
+	"github.com/EnvCLI/EnvCLI/pkg/encryption"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/output"
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(configCmd)
-	configCmd.AddCommand(setCmd)
-	configCmd.AddCommand(getCmd)
-	configCmd.AddCommand(getAllCmd)
-	configCmd.AddCommand(unsetCmd)
+// newConfigCmd creates the config command
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:     "config",
+		Short:   "updates the config",
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	configCmd.AddCommand(newSetCmd())
+	configCmd.AddCommand(newGetCmd())
+	configCmd.AddCommand(newGetAllCmd())
+	configCmd.AddCommand(newUnsetCmd())
+	configCmd.AddCommand(newEncryptCmd())
+	configCmd.AddCommand(newDecryptCmd())
+	configCmd.AddCommand(newSyncCmd())
+
+	return configCmd
+}
+
+// newSetCmd creates the config set command
+func newSetCmd() *cobra.Command {
+	setCmd := &cobra.Command{
+		Use: "set",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Check Parameters
+			if len(args) != 2 {
+				return errors.New("please provide the variable name and the value you want to set in this format. [envcli config set variable value]")
+			}
+			varName := args[0]
+			varValue := args[1]
+
+			// Set value
+			force, _ := cmd.Flags().GetBool("force")
+			if err := config.SetPropertyConfigEntry(varName, varValue, force); err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Set value of %s to [%s]\n", varName, varValue)
+			return nil
+		},
+	}
+	setCmd.Flags().Bool("force", false, "Sets the value without validating it")
+
+	return setCmd
 }
 
-var configCmd = &cobra.Command{
-	Use:     "config",
-	Short:   "updates the config",
-	Aliases: []string{},
-	Run: func(cmd *cobra.Command, args []string) {
-		_ = cmd.Help()
-		os.Exit(0)
-	},
+// newGetCmd creates the config get command
+func newGetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use: "get",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Check Parameters
+			if len(args) != 1 {
+				return errors.New("please provide the variable name you want to read. [envcli config get variable]")
+			}
+			varName := args[0]
+
+			// Get Value
+			value, err := config.GetPropertyConfigEntry(varName)
+			if err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s [%s]\n", varName, value)
+			return nil
+		},
+	}
+}
+
+// propertyRow is a property set in the property file
+type propertyRow struct {
+	Name  string `json:"name" yaml:"name" table:"NAME"`
+	Value string `json:"value" yaml:"value" table:"VALUE"`
+}
+
+// newGetAllCmd creates the config get-all command
+func newGetAllCmd() *cobra.Command {
+	return &cobra.Command{
+		Use: "get-all",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var names []string
+			for key := range propConfig.Properties {
+				names = append(names, key)
+			}
+			sort.Strings(names)
+
+			properties := []propertyRow{}
+			for _, name := range names {
+				properties = append(properties, propertyRow{Name: name, Value: propConfig.Properties[name]})
+			}
+			return output.Render(cmd.OutOrStdout(), outputFormat(), properties)
+		},
+	}
 }
 
-var setCmd = &cobra.Command{
-	Use: "set",
-	Run: func(cmd *cobra.Command, args []string) {
-		// Check Parameters
-		if len(args) != 2 {
-			log.Fatal().Msg("Please provide the variable name and the value you want to set in this format. [envcli config set variable value]")
-		}
-		varName := args[0]
-		varValue := args[1]
-
-		// Set value
-		config.SetPropertyConfigEntry(varName, varValue)
-		fmt.Printf("Set value of %s to [%s]\n", varName, varValue)
-	},
+// newUnsetCmd creates the config unset command
+func newUnsetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use: "unset",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Check Parameters
+			if len(args) != 1 {
+				return errors.New("please provide the variable name you want to unset. [envcli config unset variable]")
+			}
+			varName := args[0]
+
+			// Unset value
+			if err := config.UnsetPropertyConfigEntry(varName); err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Value of variable %s set to [].\n", varName)
+			return nil
+		},
+	}
 }
 
-var getCmd = &cobra.Command{
-	Use: "get",
-	Run: func(cmd *cobra.Command, args []string) {
-		// Check Parameters
-		if len(args) != 1 {
-			log.Fatal().Msg("Please provide the variable name you want to read. [envcli config get variable]")
-		}
-		varName := args[0]
-
-		// Get Value
-		fmt.Printf("%s [%s]\n", varName, config.GetPropertyConfigEntry(varName))
-	},
+// newEncryptCmd creates the config encrypt command
+func newEncryptCmd() *cobra.Command {
+	encryptCmd := &cobra.Command{
+		Use:   "encrypt <file>",
+		Short: "encrypts a config file for the age recipients, envcli decrypts it using the key file of ENVCLI_KEY_FILE or the config-key-file property",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			recipientKeys, _ := cmd.Flags().GetStringArray("recipient")
+			outputFile, _ := cmd.Flags().GetString("output-file")
+
+			var recipients []encryption.Recipient
+			for _, key := range recipientKeys {
+				recipient, err := encryption.ParseRecipient(key)
+				if err != nil {
+					return exitcode.New(exitcode.ConfigError, err)
+				}
+				recipients = append(recipients, recipient)
+			}
+			if len(recipients) == 0 {
+				return exitcode.New(exitcode.ConfigError, errors.New("at least one --recipient is required, ex. the public key printed by age-keygen"))
+			}
+
+			content, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			if encryption.IsEncrypted(content) {
+				return exitcode.New(exitcode.ConfigError, errors.New(args[0]+" is already encrypted"))
+			}
+			// only valid config files are encrypted, errors are hard to find afterwards
+			if _, err = config.LoadProjectConfig(args[0]); err != nil {
+				return exitcode.New(exitcode.ConfigError, fmt.Errorf("invalid config file %s: %w", args[0], err))
+			}
+
+			encrypted, err := encryption.Encrypt(content, recipients...)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt %s: %w", args[0], err)
+			}
+			if outputFile == "" {
+				outputFile = strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ".enc" + filepath.Ext(args[0])
+			}
+			if err = atomicfile.WriteFile(outputFile, encrypted, 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Encrypted %s into %s for %d recipient(s).\n", args[0], outputFile, len(recipients))
+			return nil
+		},
+	}
+	encryptCmd.Flags().StringArrayP("recipient", "r", []string{}, "age public key (age1...) of a recipient, can be repeated")
+	encryptCmd.Flags().String("output-file", "", "file to write, defaults to the file name with .enc before the extension (ex. include.enc.yml)")
+
+	return encryptCmd
 }
 
-var getAllCmd = &cobra.Command{
-	Use: "get-all",
-	Run: func(cmd *cobra.Command, args []string) {
-		// Print all values
-		for key, value := range propConfig.Properties {
-			fmt.Printf("%s [%s]\n", key, value)
-		}
-	},
+// newDecryptCmd creates the config decrypt command
+func newDecryptCmd() *cobra.Command {
+	decryptCmd := &cobra.Command{
+		Use:   "decrypt <file>",
+		Short: "decrypts a encrypted config file using the key file of ENVCLI_KEY_FILE or the config-key-file property and prints it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outputFile, _ := cmd.Flags().GetString("output-file")
+
+			keyFile := config.GetKeyFile(propConfig)
+			if keyFile == "" {
+				return exitcode.New(exitcode.ConfigError, errors.New("no key file configured, set "+config.KeyFileEnv+" or the config-key-file property"))
+			}
+			identities, err := config.LoadIdentities(keyFile)
+			if err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+
+			content, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			decrypted, err := encryption.Decrypt(content, identities...)
+			if err != nil {
+				return exitcode.New(exitcode.ConfigError, fmt.Errorf("failed to decrypt %s: %w", args[0], err))
+			}
+
+			if outputFile == "" {
+				_, err = cmd.OutOrStdout().Write(decrypted)
+				return err
+			}
+			return atomicfile.WriteFile(outputFile, decrypted, 0600)
+		},
+	}
+	decryptCmd.Flags().String("output-file", "", "file to write, the content is printed if not set")
+
+	return decryptCmd
 }
 
-var unsetCmd = &cobra.Command{
-	Use: "unset",
-	Run: func(cmd *cobra.Command, args []string) {
-		// Check Parameters
-		if len(args) != 1 {
-			log.Fatal().Msg("Please provide the variable name you want to unset. [envcli config unset variable]")
-		}
-		varName := args[0]
-
-		// Unset value
-		config.UnsetPropertyConfigEntry(varName)
-		fmt.Printf("Value of variable %s set to [].\n", varName)
-	},
+// newSyncCmd creates the config sync command
+func newSyncCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sync",
+		Short: "syncs the global configuration from the global-configuration-repo and shows the changed entries",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, configured := config.GetGlobalRepo(propConfig)
+			if !configured {
+				return exitcode.New(exitcode.ConfigError, errors.New("the global-configuration-repo property is not set"))
+			}
+
+			// a missing or invalid previous copy counts as empty
+			before, _ := config.LoadProjectConfig(repo.ConfigFile())
+			commit, err := repo.Sync(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to sync %s: %w", repo, err)
+			}
+			after, err := config.LoadProjectConfig(repo.ConfigFile())
+			if err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Synced %s at %s\n", repo, commit)
+			changes := config.DiffEntries(before.Images, after.Images)
+			if changes.IsEmpty() {
+				fmt.Fprintln(out, "No entries changed.")
+				return nil
+			}
+			for _, group := range []struct {
+				label string
+				names []string
+			}{{"Added", changes.Added}, {"Removed", changes.Removed}, {"Changed", changes.Changed}} {
+				if len(group.names) > 0 {
+					fmt.Fprintf(out, "%-8s %s\n", group.label+":", strings.Join(group.names, ", "))
+				}
+			}
+			return nil
+		},
+	}
 }
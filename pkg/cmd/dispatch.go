@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// dispatchArgs supports envcli as multi-call binary: if envcli is invoked by a symlink or hardlink named like a command (npm -> envcli),
+// the arguments to run the command are returned. The arguments are passed after -- to keep all flags untouched.
+// Returns nil if envcli has been invoked by its own name, renamed binaries have to keep the envcli prefix (ex. envcli-linux-amd64).
+func dispatchArgs(arg0 string, goos string, args []string) []string {
+	command := invocationName(arg0, goos)
+	if command == "" || strings.HasPrefix(strings.ToLower(command), "envcli") {
+		return nil
+	}
+	// go test binaries and debug builds
+	if strings.HasSuffix(command, ".test") || strings.HasPrefix(command, "__debug_bin") {
+		return nil
+	}
+
+	return append([]string{"run", "--", command}, args...)
+}
+
+// invocationName returns the name envcli has been invoked with, the .exe suffix is removed on windows
+func invocationName(arg0 string, goos string) string {
+	if goos == "windows" {
+		arg0 = strings.ReplaceAll(arg0, `\`, "/")
+	}
+	name := filepath.Base(filepath.ToSlash(arg0))
+	if name == "." || name == "/" {
+		return ""
+	}
+	if goos == "windows" && strings.HasSuffix(strings.ToLower(name), ".exe") {
+		name = name[:len(name)-len(".exe")]
+	}
+	return name
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDispatchArgs(t *testing.T) {
+	var tests = []struct {
+		arg0     string
+		goos     string
+		expected []string
+	}{
+		{"envcli", "linux", nil},
+		{"/usr/local/bin/envcli", "linux", nil},
+		{"/opt/envcli-linux-amd64", "linux", nil},
+		{"/tmp/go-build123/cmd.test", "linux", nil},
+		{"/usr/local/bin/npm", "linux", []string{"run", "--", "npm", "install", "--save-dev"}},
+		{"./node", "darwin", []string{"run", "--", "node", "install", "--save-dev"}},
+		{`C:\tools\envcli.exe`, "windows", nil},
+		{`C:\tools\EnvCLI.EXE`, "windows", nil},
+		{`C:\tools\npm.exe`, "windows", []string{"run", "--", "npm", "install", "--save-dev"}},
+	}
+
+	for _, test := range tests {
+		result := dispatchArgs(test.arg0, test.goos, []string{"install", "--save-dev"})
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("dispatchArgs(%q, %s): expected %v, got %v", test.arg0, test.goos, test.expected, result)
+		}
+	}
+}
+
+func TestDispatchPassesFlags(t *testing.T) {
+	env := newTestEnv(t)
+	env.writeFile(".envcli.yml", testProjectConfig)
+
+	if _, _, err := env.execute(dispatchArgs("/usr/local/bin/echo", "linux", []string{"--help", "-e", "--output", "json"})...); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	runs := env.runtime.executed("docker run ")
+	if len(runs) != 1 || !strings.Contains(runs[0], "echo --help -e --output json") {
+		t.Errorf("expected the flags to be passed to the command, got %v", env.runtime.commands)
+	}
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/output"
+	"github.com/EnvCLI/EnvCLI/pkg/support"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// doctorReport holds the result of the environment checks
+type doctorReport struct {
+	ContainerRuntime string `json:"containerRuntime" yaml:"containerRuntime" table:"Container Runtime"`
+	DetectedRuntimes string `json:"detectedRuntimes" yaml:"detectedRuntimes" table:"Detected Runtimes"`
+	PropertyFile     string `json:"propertyFile" yaml:"propertyFile" table:"Property File"`
+	GlobalConfig     string `json:"globalConfig" yaml:"globalConfig" table:"Global Config"`
+	ProjectConfig    string `json:"projectConfig" yaml:"projectConfig" table:"Project Config"`
+	CachePath        string `json:"cachePath" yaml:"cachePath" table:"Cache Path"`
+	Problems         int    `json:"problems" yaml:"problems"`
+}
+
+// newDoctorCmd creates the doctor command
+func newDoctorCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	var bundle string
+
+	cmd := &cobra.Command{
+		Use:     "doctor",
+		Short:   "checks the environment and configuration of envcli",
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var report doctorReport
+
+			// container runtime
+			runtime := detectRuntime()
+			if err := containerutil.RequireRuntime(runtime); err != nil {
+				report.Problems++
+				report.ContainerRuntime = err.Error()
+			} else {
+				report.ContainerRuntime = runtime.Name()
+			}
+			report.DetectedRuntimes = detectedRuntimes(cmd.Context(), runtime.Name())
+
+			// configuration
+			report.PropertyFile = config.GetPropertyConfigFile()
+			report.GlobalConfig = config.GetGlobalConfigurationFile(propConfig)
+			if projectConfigFile, err := config.GetProjectConfigFile(); err == nil {
+				report.ProjectConfig = config.ConfigSource(projectConfigFile)
+			} else {
+				report.ProjectConfig = "none, " + err.Error()
+			}
+
+			// cache path
+			cacheStatus := config.GetCachePath(propConfig)
+			if cacheStatus.Configured == "" {
+				report.CachePath = "not set, caching is disabled"
+			} else if cacheStatus.Fallback {
+				report.Problems++
+				report.CachePath = fmt.Sprintf("%s is not usable (%s), using %s instead", cacheStatus.Configured, cacheStatus.Problem, cacheStatus.Path)
+			} else {
+				report.CachePath = cacheStatus.Path
+			}
+
+			w := cmd.OutOrStdout()
+			if err := output.Render(w, outputFormat(), report); err != nil {
+				return err
+			}
+			if bundle != "" {
+				if err := writeSupportBundle(cmd.Context(), bundle, report); err != nil {
+					return fmt.Errorf("failed to write the support bundle: %w", err)
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "Support bundle written to %s\n", bundle)
+			}
+			if report.Problems > 0 {
+				if outputFormat() == output.FormatTable {
+					fmt.Fprintf(w, "\nFound %d problem(s).\n", report.Problems)
+				}
+				return exitcode.NewSilent(exitcode.GeneralError, fmt.Errorf("found %d problem(s)", report.Problems))
+			}
+
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&bundle, "bundle", "", "writes a zip file with the doctor output, the log file and the configuration with secrets removed, ex. to attach it to a issue")
+
+	return cmd
+}
+
+// writeSupportBundle packages the doctor report, the log file of --log-to-file, the properties and the loaded configuration files, all secrets are removed
+func writeSupportBundle(ctx context.Context, file string, report doctorReport) error {
+	files := make(map[string][]byte)
+
+	var doctor bytes.Buffer
+	if err := output.Render(&doctor, output.FormatTable, report); err != nil {
+		return err
+	}
+	files["doctor.txt"] = []byte(support.SanitizeText(doctor.String()))
+
+	if cfg.LogToFile != "" {
+		if content, err := os.ReadFile(cfg.LogToFile); err == nil {
+			files["envcli.log"] = []byte(support.SanitizeText(string(content)))
+		} else {
+			log.Warn().Err(err).Str("file", cfg.LogToFile).Msg("failed to read the log file for the support bundle")
+		}
+	}
+
+	properties, err := yaml.Marshal(support.SanitizeProperties(propConfig.Properties))
+	if err != nil {
+		return err
+	}
+	files["properties.yml"] = properties
+
+	// configuration files, a error in the configuration is part of the report
+	var manifest []string
+	mergedConfig, err := config.LoadMergedConfiguration(ctx, nil)
+	if err != nil {
+		manifest = append(manifest, "error: "+support.SanitizeText(err.Error()))
+	}
+	for i, configFile := range mergedConfig.ConfigFiles {
+		content, err := os.ReadFile(configFile)
+		if err != nil {
+			manifest = append(manifest, configFile+": "+err.Error())
+			continue
+		}
+		sanitized, err := support.SanitizeConfig(content)
+		if err != nil {
+			manifest = append(manifest, configFile+": "+err.Error())
+			continue
+		}
+		name := fmt.Sprintf("config/%d-%s", i, filepath.Base(configFile))
+		files[name] = sanitized
+		manifest = append(manifest, name+": "+configFile)
+	}
+	files["files.txt"] = []byte(strings.Join(manifest, "\n") + "\n")
+
+	return support.WriteBundle(file, files)
+}
+
+// detectedRuntimes lists the runtimes available on the host with their version and marks the active runtime
+func detectedRuntimes(ctx context.Context, active string) string {
+	var runtimes []string
+	for _, name := range containerutil.DetectRuntimes() {
+		result := name
+		if version, err := containerutil.RuntimeVersion(ctx, containerutil.DetectRuntime(name)); err == nil && version != "" {
+			result += " " + strings.TrimSpace(version)
+		}
+		if name == active {
+			result += " (active)"
+		}
+		runtimes = append(runtimes, result)
+	}
+	if len(runtimes) == 0 {
+		return "none"
+	}
+	return strings.Join(runtimes, ", ")
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/export"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/spf13/cobra"
+)
+
+// newExportCmd creates the export command
+func newExportCmd() *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:     "export",
+		Short:   "writes the merged configuration with rendered templates for external tools, as envcli yaml, json or docker-compose file",
+		Aliases: []string{},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+			format, _ := cmd.Flags().GetString("format")
+			file, _ := cmd.Flags().GetString("file")
+			variant, _ := cmd.Flags().GetString("variant")
+			if err := export.ValidateFormat(format); err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+
+			propConfig, err := config.LoadPropertyConfig()
+			if err != nil {
+				return err
+			}
+			cfg, err := config.LoadMergedConfiguration(cmd.Context(), configIncludes)
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", exitcode.New(exitcode.ConfigError, err))
+			}
+
+			opts := export.Options{
+				ProjectDirectory: config.GetProjectOrDirectory(filesystem.GetWorkingDirectory()),
+				CachePath:        config.GetCachePath(propConfig).Path,
+				Variant:          variant,
+				Getenv:           os.Getenv,
+				Environ:          os.Environ(),
+			}
+			resolved, err := export.Resolve(cmd.Context(), cfg, opts)
+			if err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+
+			var content bytes.Buffer
+			if err = export.Write(&content, format, resolved, opts); err != nil {
+				return err
+			}
+			if file == "" {
+				_, err = cmd.OutOrStdout().Write(content.Bytes())
+				return err
+			}
+			if err = os.WriteFile(file, content.Bytes(), 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d entries to %s\n", len(resolved.Images), file)
+			return nil
+		},
+	}
+	exportCmd.Flags().String("format", export.FormatEnvCLI, "Format of the export - allowed: "+strings.Join(export.Formats, ","))
+	exportCmd.Flags().StringP("file", "f", "", "Writes the export into the file instead of stdout")
+	exportCmd.Flags().String("variant", "", "Applies the variant to the entries defining variants, defaults to ENVCLI_VARIANT")
+
+	return exportCmd
+}
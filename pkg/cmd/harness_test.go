@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+)
+
+var update = flag.Bool("update", false, "updates the golden files")
+
+// testdataDir is resolved before the tests change into their working directories
+var testdataDir, _ = filepath.Abs("testdata")
+
+// mockRuntime records the commands instead of executing them
+type mockRuntime struct {
+	name     string
+	commands []string
+
+	// mu guards the commands of parallel runs
+	mu sync.Mutex
+
+	// output answers the commands executed by Output, all commands succeed without output if not set
+	output func(command string) (string, error)
+
+	// execErr is returned for the commands executed by Exec
+	execErr error
+}
+
+func (r *mockRuntime) Name() string {
+	return r.name
+}
+
+func (r *mockRuntime) Exec(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	command := containerutil.FormatCommand(args)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands = append(r.commands, command)
+	return r.execErr
+}
+
+func (r *mockRuntime) Output(ctx context.Context, args []string) (string, error) {
+	command := containerutil.FormatCommand(args)
+	r.mu.Lock()
+	r.commands = append(r.commands, command)
+	r.mu.Unlock()
+	if r.output == nil {
+		return "", nil
+	}
+	return r.output(command)
+}
+
+// executed returns the commands starting with the prefix
+func (r *mockRuntime) executed(prefix string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var commands []string
+	for _, command := range r.commands {
+		if strings.HasPrefix(command, prefix) {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+// testEnv is a temporary environment with its own config directory, working directory and container runtime
+type testEnv struct {
+	t         *testing.T
+	runtime   *mockRuntime
+	configDir string
+	workDir   string
+
+	// preferredRuntime is the runtime requested by the last execution
+	preferredRuntime string
+}
+
+// newTestEnv creates the environment and changes into its working directory
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	env := &testEnv{t: t, runtime: &mockRuntime{name: "docker"}, configDir: t.TempDir(), workDir: t.TempDir()}
+
+	// CI environments pass all environment variables into the container
+	t.Setenv("CI", "false")
+
+	// keeps the caches of the tests (ex. the command history) out of the user cache directory
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	config.SetConfigurationDirectory(env.configDir)
+	t.Cleanup(func() { config.SetConfigurationDirectory(config.UserConfigurationDirectory()) })
+
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(env.workDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(previous) })
+
+	return env
+}
+
+// writeFile writes the file relative to the working directory
+func (e *testEnv) writeFile(name string, content string) {
+	e.t.Helper()
+	file := filepath.Join(e.workDir, name)
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		e.t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+// writeGlobalConfig writes the global configuration into the config directory
+func (e *testEnv) writeGlobalConfig(content string) {
+	e.t.Helper()
+	if err := os.WriteFile(config.GetGlobalConfigurationFile(config.PropertyConfigurationFile{}), []byte(content), 0644); err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+// chdir changes into a directory relative to the working directory
+func (e *testEnv) chdir(name string) {
+	e.t.Helper()
+	if err := os.MkdirAll(filepath.Join(e.workDir, name), os.ModePerm); err != nil {
+		e.t.Fatal(err)
+	}
+	if err := os.Chdir(filepath.Join(e.workDir, name)); err != nil {
+		e.t.Fatal(err)
+	}
+}
+
+// execute runs envcli in-process and returns the captured output
+func (e *testEnv) execute(args ...string) (string, string, error) {
+	e.t.Helper()
+	var stdout, stderr bytes.Buffer
+
+	rootCmd := NewRootCommand(func(preferred string) containerutil.ContainerRuntime {
+		e.preferredRuntime = preferred
+		return e.runtime
+	})
+	rootCmd.SetArgs(append([]string{"--log-format", "plain", "--log-level", "warn"}, args...))
+	rootCmd.SetIn(strings.NewReader(""))
+	rootCmd.SetOut(&stdout)
+	rootCmd.SetErr(&stderr)
+	err := rootCmd.Execute()
+
+	return stdout.String(), stderr.String(), err
+}
+
+// assertGolden compares the output with the golden file within testdata, the working directory is replaced by <workdir>.
+// Run go test -update to rewrite the golden files.
+func (e *testEnv) assertGolden(file string, actual string) {
+	e.t.Helper()
+	file = filepath.Join(testdataDir, file)
+	actual = strings.ReplaceAll(actual, e.workDir, "<workdir>")
+	if *update {
+		if err := os.WriteFile(file, []byte(actual), 0644); err != nil {
+			e.t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(file)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	if string(expected) != actual {
+		e.t.Errorf("%s: expected\n%s\ngot\n%s", file, expected, actual)
+	}
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/spf13/cobra"
+)
+
+// newHelpCmd creates the help command
+func newHelpCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "help [command|tool]",
+		Short: "Help about any command or the tools provided by the configuration",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// envcli commands
+			target, _, err := cmd.Root().Find(args)
+			if len(args) == 0 || (err == nil && target != nil && target != cmd.Root()) {
+				return target.Help()
+			}
+
+			// configured tools
+			configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+			commandConfig, matchType, configErr := config.GetCommandMatch(cmd.Context(), args[0], filesystem.GetWorkingDirectory(), configIncludes)
+			if configErr != nil {
+				return fmt.Errorf("unknown command or tool: %w", configErr)
+			}
+
+			printToolHelp(cmd.OutOrStdout(), args[0], commandConfig, matchType)
+			return nil
+		},
+	}
+}
+
+// printToolHelp renders the help of a configured tool
+func printToolHelp(w io.Writer, commandName string, entry config.RunConfigurationEntry, matchType string) {
+	width := terminalWidth()
+
+	fmt.Fprintf(w, "%s (provided by %s, scope: %s, match: %s)\n", commandName, entry.Name, entry.Scope, matchType)
+	fmt.Fprintf(w, "Image: %s\n", entry.Image)
+	if len(entry.Provides) > 0 {
+		fmt.Fprintf(w, "Provides: %s\n", strings.Join(entry.Provides, ", "))
+	}
+
+	if entry.DocsURL != "" {
+		fmt.Fprintf(w, "Documentation: %s\n", entry.DocsURL)
+	}
+
+	if entry.Description == "" && len(entry.Examples) == 0 && entry.DocsURL == "" {
+		fmt.Fprintf(w, "\nNo help configured for this tool, add a description or examples to the entry in your .envcli.yml.\n")
+		return
+	}
+
+	if entry.Description != "" {
+		fmt.Fprintf(w, "\n")
+		for _, line := range common.WrapText(entry.Description, width) {
+			fmt.Fprintf(w, "%s\n", line)
+		}
+	}
+
+	if len(entry.Examples) > 0 {
+		fmt.Fprintf(w, "\nExamples:\n")
+		for _, example := range entry.Examples {
+			for _, line := range common.WrapText(example, width-2) {
+				fmt.Fprintf(w, "  %s\n", line)
+			}
+		}
+	}
+}
+
+// terminalWidth returns the width of the terminal based on the COLUMNS variable, defaults to 80
+func terminalWidth() int {
+	width, err := strconv.Atoi(os.Getenv("COLUMNS"))
+	if err != nil || width < 20 {
+		return 80
+	}
+	return width
+}
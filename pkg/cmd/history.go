@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/history"
+	"github.com/EnvCLI/EnvCLI/pkg/output"
+	"github.com/EnvCLI/EnvCLI/pkg/support"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// historyRow is a row of the history list
+type historyRow struct {
+	Index     int    `json:"index" yaml:"index" table:"#"`
+	Time      string `json:"time" yaml:"time" table:"TIME"`
+	ExitCode  int    `json:"exitCode" yaml:"exitCode" table:"EXIT"`
+	Duration  string `json:"duration" yaml:"duration" table:"DURATION"`
+	Directory string `json:"directory" yaml:"directory" table:"DIRECTORY"`
+	Command   string `json:"command" yaml:"command" table:"COMMAND"`
+}
+
+// newHistoryCmd creates the history command
+func newHistoryCmd() *cobra.Command {
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "lists the recorded envcli run invocations, execute one again using envcli rerun <index>",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			project, _ := cmd.Flags().GetBool("project")
+
+			entries, err := history.Read(config.GetHistoryFile(propConfig))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+
+			projectDir := config.GetProjectOrWorkingDirectory()
+			rows := []historyRow{}
+			for i, entry := range entries {
+				if project && !isWithinDirectory(entry.Directory, projectDir) {
+					continue
+				}
+				rows = append(rows, historyRow{
+					Index:     i + 1,
+					Time:      entry.Time.Local().Format("2006-01-02 15:04:05"),
+					ExitCode:  entry.ExitCode,
+					Duration:  entry.Duration.Round(100 * time.Millisecond).String(),
+					Directory: entry.Directory,
+					Command:   entry.CommandLine(),
+				})
+			}
+			return output.Render(cmd.OutOrStdout(), outputFormat(), rows)
+		},
+	}
+	historyCmd.Flags().Bool("project", false, "Only lists the invocations within the current project")
+
+	return historyCmd
+}
+
+// newRerunCmd creates the rerun command
+func newRerunCmd() *cobra.Command {
+	rerunCmd := &cobra.Command{
+		Use:   "rerun <index>",
+		Short: "executes a invocation of envcli history again, within its original working directory",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			here, _ := cmd.Flags().GetBool("here")
+			index, err := strconv.Atoi(args[0])
+			if err != nil {
+				return exitcode.New(exitcode.ConfigError, errors.New("invalid history index "+args[0]))
+			}
+
+			entries, err := history.Read(config.GetHistoryFile(propConfig))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			if index < 1 || index > len(entries) {
+				return exitcode.New(exitcode.ConfigError, fmt.Errorf("no history entry %d, list the entries using envcli history", index))
+			}
+			entry := entries[index-1]
+			if entry.Masked {
+				return exitcode.New(exitcode.ConfigError, fmt.Errorf("history entry %d contains masked secrets and can't be executed again, run it manually: %s", index, entry.CommandLine()))
+			}
+
+			if !here {
+				previous, wdErr := os.Getwd()
+				if wdErr != nil {
+					return wdErr
+				}
+				if err = os.Chdir(entry.Directory); err != nil {
+					return exitcode.New(exitcode.ConfigError, fmt.Errorf("can't change into the original working directory, run it in the current directory using --here: %w", err))
+				}
+				defer func() { _ = os.Chdir(previous) }()
+			}
+
+			target, flags, err := cmd.Root().Find(entry.Args)
+			if err != nil || target.RunE == nil || target == cmd.Root() {
+				return exitcode.New(exitcode.ConfigError, fmt.Errorf("history entry %d is not a envcli command: %s", index, entry.CommandLine()))
+			}
+			if err = target.ParseFlags(flags); err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+			log.Info().Str("directory", entry.Directory).Msg("executing " + entry.CommandLine())
+			target.SetContext(cmd.Context())
+			return target.RunE(target, target.Flags().Args())
+		},
+	}
+	rerunCmd.Flags().Bool("here", false, "Executes the invocation within the current instead of the original working directory")
+
+	return rerunCmd
+}
+
+// recordHistory appends the invocation to the command history, unless the history property is false.
+// Arguments containing secrets are masked, failures are only logged.
+func recordHistory(cmd *cobra.Command, args []string, started time.Time, err error) {
+	if collection.MapGetValueOrDefault(propConfig.Properties, "history", "true") == "false" {
+		return
+	}
+
+	directory, wdErr := os.Getwd()
+	if wdErr != nil {
+		return
+	}
+	entry := history.Entry{Time: started, Directory: directory, ExitCode: exitcode.Of(err), Duration: time.Since(started)}
+	for _, arg := range invocationArgs(cmd, args) {
+		masked := support.SanitizeText(arg)
+		entry.Masked = entry.Masked || masked != arg
+		entry.Args = append(entry.Args, masked)
+	}
+
+	size, sizeErr := strconv.Atoi(collection.MapGetValueOrDefault(propConfig.Properties, "history-size", ""))
+	if sizeErr != nil {
+		size = history.DefaultSize
+	}
+	if appendErr := history.Append(config.GetHistoryFile(propConfig), entry, size); appendErr != nil {
+		log.Debug().Err(appendErr).Msg("failed to record the command history")
+	}
+}
+
+// historyIgnoredFlags only change the logging and output of envcli, they aren't recorded
+var historyIgnoredFlags = []string{"log-level", "log-format", "log-caller", "log-to-file", "output"}
+
+// invocationArgs returns the envcli arguments of the invocation: the subcommand, the flags that have been set and the positional arguments
+func invocationArgs(cmd *cobra.Command, args []string) []string {
+	result := strings.Fields(cmd.CommandPath())[1:]
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		if ignored, _ := collection.InArray(flag.Name, historyIgnoredFlags); ignored {
+			return
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			for _, value := range slice.GetSlice() {
+				result = append(result, "--"+flag.Name+"="+value)
+			}
+			return
+		}
+		result = append(result, "--"+flag.Name+"="+flag.Value.String())
+	})
+	return append(result, args...)
+}
+
+// isWithinDirectory returns true if the path is the directory or within it
+func isWithinDirectory(path string, directory string) bool {
+	rel, err := filepath.Rel(directory, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/githooks"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/spf13/cobra"
+)
+
+// newHooksCmd creates the hooks command
+func newHooksCmd() *cobra.Command {
+	hooksCmd := &cobra.Command{
+		Use:     "hooks",
+		Short:   "manages the git hooks that pull the changed images of the envcli config after a merge or checkout",
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	hooksCmd.AddCommand(newHooksInstallCmd())
+	hooksCmd.AddCommand(newHooksUninstallCmd())
+
+	return hooksCmd
+}
+
+// newHooksInstallCmd creates the hooks install command
+func newHooksInstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "install",
+		Short: "adds the envcli block to the post-merge and post-checkout hooks of the git repository",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hooksDir, err := githooks.Directory(cmd.Context(), filesystem.GetWorkingDirectory())
+			if err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+
+			changed, err := githooks.Install(hooksDir, githooks.Block(config.GetProjectConfigFilenames()))
+			if err != nil {
+				return fmt.Errorf("failed to install the git hooks: %w", err)
+			}
+			if len(changed) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "The git hooks in %s are already up to date.\n", hooksDir)
+				return nil
+			}
+			for _, file := range changed {
+				fmt.Fprintf(cmd.OutOrStdout(), "Installed %s\n", file)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Changed images are pulled in the background, disable it with: envcli config set %s false\n", githooks.BackgroundPullProperty)
+
+			return nil
+		},
+	}
+}
+
+// newHooksUninstallCmd creates the hooks uninstall command
+func newHooksUninstallCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall",
+		Short: "removes the envcli block from the git hooks, hooks only containing the block are deleted",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hooksDir, err := githooks.Directory(cmd.Context(), filesystem.GetWorkingDirectory())
+			if err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+
+			changed, err := githooks.Uninstall(hooksDir)
+			if err != nil {
+				return fmt.Errorf("failed to uninstall the git hooks: %w", err)
+			}
+			if len(changed) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "The git hooks in %s don't contain the envcli block.\n", hooksDir)
+				return nil
+			}
+			for _, file := range changed {
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed the envcli block from %s\n", file)
+			}
+
+			return nil
+		},
+	}
+}
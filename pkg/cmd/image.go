@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/envcli"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// newImageCmd creates the image command
+func newImageCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	imageCmd := &cobra.Command{
+		Use:     "image",
+		Short:   "exports and imports the images of the configured entries, ex. to cache them between CI jobs",
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	imageCmd.AddCommand(newImageExportCmd(detectRuntime))
+	imageCmd.AddCommand(newImageImportCmd(detectRuntime))
+
+	return imageCmd
+}
+
+// newImageExportCmd creates the image export command
+func newImageExportCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:   "export [commands...] -f <file>",
+		Short: "writes the images of the commands (or all entries using --all) into a single archive, missing images are pulled first",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+			file, _ := cmd.Flags().GetString("file")
+			configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+			if file == "" || (all == (len(args) > 0)) {
+				return exitcode.New(exitcode.ConfigError, errors.New("expected --file <file> and either --all or the commands whose images should be exported"))
+			}
+
+			runtime := detectRuntime()
+			if runtimeErr := containerutil.RequireRuntime(runtime); runtimeErr != nil {
+				return runtimeErr
+			}
+			runner := envcli.NewRunner(envcli.Options{ConfigIncludes: configIncludes, Properties: &propConfig, Runtime: runtime})
+
+			var entries []config.RunConfigurationEntry
+			if all {
+				mergedConfig, err := config.LoadMergedConfiguration(cmd.Context(), configIncludes)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", exitcode.New(exitcode.ConfigError, err))
+				}
+				entries = mergedConfig.Images
+			} else {
+				for _, commandName := range args {
+					entry, err := config.GetCommandConfiguration(cmd.Context(), commandName, filesystem.GetWorkingDirectory(), configIncludes)
+					if err != nil {
+						return fmt.Errorf("failed to load command config: %w", err)
+					}
+					entries = append(entries, entry)
+				}
+			}
+
+			// the images as used by envcli run, missing images are pulled and images of build entries are only exported once they have been built
+			var images []string
+			seen := map[string]bool{}
+			for _, entry := range entries {
+				image := entry.Image
+				if entry.IsBuild() {
+					if !containerutil.ImageExists(cmd.Context(), runtime, image) {
+						fmt.Fprintf(cmd.ErrOrStderr(), "Skipping [%s], the image hasn't been built yet.\n", entry.Name)
+						continue
+					}
+				} else {
+					mirrored, mirrorErr := runner.MirroredImage(entry)
+					if mirrorErr != nil {
+						return mirrorErr
+					}
+					image = mirrored
+					if !containerutil.ImageExists(cmd.Context(), runtime, image) {
+						pulled, pullErr := runner.PullImage(cmd.Context(), image, entry.Image)
+						if pullErr != nil {
+							return pullErr
+						}
+						image = pulled
+					}
+				}
+				if image == "" || seen[image] {
+					continue
+				}
+				seen[image] = true
+				images = append(images, image)
+			}
+			if len(images) == 0 {
+				return exitcode.New(exitcode.ConfigError, errors.New("no images to export"))
+			}
+
+			// the archive is written to stdout for -, the progress to stderr
+			var w io.Writer = cmd.OutOrStdout()
+			progress := cmd.OutOrStdout()
+			if file != "-" {
+				target, err := os.Create(file)
+				if err != nil {
+					return fmt.Errorf("failed to create %s: %w", file, err)
+				}
+				defer target.Close()
+				w = target
+			} else {
+				progress = cmd.ErrOrStderr()
+			}
+
+			err := containerutil.ExportImages(cmd.Context(), runtime, images, w, func(image containerutil.ArchivedImage, size int64) {
+				fmt.Fprintf(progress, "Exported %s (%s)\n", image.Image, formatSize(size))
+			})
+			if err != nil {
+				if file != "-" {
+					_ = os.Remove(file)
+				}
+				return exitcode.New(exitcode.ImagePullFailure, err)
+			}
+			log.Debug().Str("file", file).Int("images", len(images)).Msg("exported the images")
+			return nil
+		},
+	}
+	exportCmd.Flags().Bool("all", false, "Exports the images of all configured entries")
+	exportCmd.Flags().StringP("file", "f", "", "File the archive is written to, - writes it to stdout")
+
+	return exportCmd
+}
+
+// newImageImportCmd creates the image import command
+func newImageImportCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <file>",
+		Short: "loads the images of a archive created by envcli image export, images that are already present are skipped",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runtime := detectRuntime()
+			if runtimeErr := containerutil.RequireRuntime(runtime); runtimeErr != nil {
+				return runtimeErr
+			}
+
+			var r io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				file, err := os.Open(args[0])
+				if err != nil {
+					return exitcode.New(exitcode.ConfigError, fmt.Errorf("failed to open %s: %w", args[0], err))
+				}
+				defer file.Close()
+				r = file
+			}
+
+			loaded, skipped := 0, 0
+			err := containerutil.ImportImages(cmd.Context(), runtime, r, func(result containerutil.ImportResult) {
+				if result.Loaded {
+					loaded++
+					fmt.Fprintf(cmd.OutOrStdout(), "Loaded %s (%s)\n", result.Image, formatSize(result.Size))
+				} else {
+					skipped++
+					fmt.Fprintf(cmd.OutOrStdout(), "Skipped %s, the image is already present\n", result.Image)
+				}
+			})
+			if errors.Is(err, containerutil.ErrNoImageArchive) {
+				return exitcode.New(exitcode.ConfigError, fmt.Errorf("%s: %w", args[0], err))
+			}
+			if err != nil {
+				return exitcode.New(exitcode.ImagePullFailure, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Imported %d images, skipped %d images\n", loaded, skipped)
+			return nil
+		},
+	}
+}
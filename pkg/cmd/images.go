@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/output"
+	"github.com/EnvCLI/EnvCLI/pkg/registry"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// imageRow holds the manifest details of a image, the details are unknown if the manifest can't be inspected
+type imageRow struct {
+	Image          string     `json:"image" yaml:"image" table:"IMAGE"`
+	Platforms      []string   `json:"platforms" yaml:"platforms"`
+	PlatformsLabel string     `json:"-" yaml:"-" table:"PLATFORMS"`
+	Size           int64      `json:"size" yaml:"size"`
+	SizeLabel      string     `json:"-" yaml:"-" table:"SIZE"`
+	Created        *time.Time `json:"created,omitempty" yaml:"created,omitempty"`
+	CreatedLabel   string     `json:"-" yaml:"-" table:"CREATED"`
+}
+
+// newImagesCmd creates the images command
+func newImagesCmd() *cobra.Command {
+	imagesCmd := &cobra.Command{
+		Use:     "images",
+		Short:   "lists the available platforms, size and creation date of all configured images",
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			refresh, _ := cmd.Flags().GetBool("refresh")
+			configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+
+			cfg, err := config.LoadMergedConfiguration(cmd.Context(), configIncludes)
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", exitcode.New(exitcode.ConfigError, err))
+			}
+
+			client := registry.New()
+			cacheDir := config.GetManifestCacheDirectory(propConfig)
+			inspected := make(map[string]bool)
+
+			images := []imageRow{}
+			for _, entry := range cfg.Images {
+				if entry.Image == "" || entry.IsBuild() || inspected[entry.Image] {
+					continue
+				}
+				inspected[entry.Image] = true
+
+				ref := config.ParseImageReference(entry.Image)
+				reference := ref.Tag
+				if ref.Digest != "" {
+					reference = ref.Digest
+				}
+
+				row := imageRow{Image: entry.Image, PlatformsLabel: "unknown", SizeLabel: "unknown", CreatedLabel: "unknown"}
+				info, inspectErr := client.InspectCached(cacheDir, entry.Image, ref.Registry, ref.Repository, reference, refresh)
+				if inspectErr != nil {
+					log.Debug().Err(inspectErr).Str("image", entry.Image).Msg("failed to inspect image manifest")
+				} else {
+					row.Platforms = info.Platforms
+					row.PlatformsLabel = strings.Join(info.Platforms, ",")
+					row.Size = info.Size
+					row.SizeLabel = formatSize(info.Size)
+					if !info.Created.IsZero() {
+						row.Created = &info.Created
+						row.CreatedLabel = info.Created.Format("2006-01-02")
+					}
+				}
+				images = append(images, row)
+			}
+			return output.Render(cmd.OutOrStdout(), outputFormat(), images)
+		},
+	}
+	imagesCmd.Flags().Bool("refresh", false, "Ignores the cached manifest details and queries the registries again")
+
+	return imagesCmd
+}
+
+// formatSize formats the byte size using binary units
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/registry"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/spf13/cobra"
+)
+
+// newLockCmd creates the lock command
+func newLockCmd() *cobra.Command {
+	lockCmd := &cobra.Command{
+		Use:     "lock [entry...]",
+		Short:   "resolves the images of the project config to their digests and writes them into " + config.LockFileName,
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			update, _ := cmd.Flags().GetBool("update")
+			configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+			if len(args) > 0 && !update {
+				return exitcode.New(exitcode.ConfigError, errors.New("entries can only be passed together with --update"))
+			}
+
+			projectDirectory, _, err := config.FindProjectConfig(filesystem.GetWorkingDirectory(), config.GetProjectConfigFilenames())
+			if err != nil {
+				return exitcode.New(exitcode.ConfigError, errors.New("no envcli project config found"))
+			}
+			cfg, err := config.LoadMergedConfiguration(cmd.Context(), configIncludes)
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", exitcode.New(exitcode.ConfigError, err))
+			}
+
+			// only the entries of the project config are locked
+			var entries []config.RunConfigurationEntry
+			for _, entry := range cfg.Images {
+				if entry.Scope == "Project" {
+					entries = append(entries, entry)
+				}
+			}
+
+			file := config.GetLockFilePath(projectDirectory)
+			lock, _, err := config.LoadLockFile(file)
+			if err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+
+			client := registry.New()
+			lock, changes, err := config.UpdateLock(lock, entries, update, args, func(image string) (string, error) {
+				ref := config.ParseImageReference(image)
+				return client.Digest(ref.Registry, ref.Repository, ref.Tag)
+			})
+			if err != nil {
+				return err
+			}
+			if err = config.WriteLockFile(file, lock); err != nil {
+				return err
+			}
+
+			for _, change := range changes {
+				fmt.Fprintf(cmd.OutOrStdout(), "%-8s %s %s@%s\n", change.Type, change.Image.Name, change.Image.Image, change.Image.Digest)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Locked %d images in %s.\n", len(lock.Images), file)
+			return nil
+		},
+	}
+	lockCmd.Flags().Bool("update", false, "Resolves the digests of all images again, or only of the passed entries")
+
+	return lockCmd
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+)
+
+// logFile is the file opened by --log-to-file, kept to close it if the command tree is executed again
+var logFile *os.File
+
+// levelFilter only writes the log events of at least the level
+type levelFilter struct {
+	io.Writer
+	level zerolog.Level
+}
+
+func (w levelFilter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if level < w.level {
+		return len(p), nil
+	}
+	return w.Write(p)
+}
+
+// openLogFile opens the log file for appending, the directory is created if missing
+func openLogFile(file string) (*os.File, error) {
+	if logFile != nil {
+		_ = logFile.Close()
+		logFile = nil
+	}
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	var err error
+	logFile, err = os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	return logFile, err
+}
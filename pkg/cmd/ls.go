@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// lsEntry is a row of the entry list
+type lsEntry struct {
+	Name          string   `json:"name" yaml:"name" table:"NAME"`
+	Scope         string   `json:"scope" yaml:"scope" table:"SCOPE"`
+	Image         string   `json:"image" yaml:"image" table:"IMAGE"`
+	Provides      []string `json:"provides" yaml:"provides"`
+	Denied        []string `json:"denied,omitempty" yaml:"denied,omitempty"`
+	ProvidesLabel string   `json:"-" yaml:"-" table:"PROVIDES"`
+	Source        string   `json:"source" yaml:"source" table:"SOURCE,long"`
+	Description   string   `json:"description" yaml:"description" table:"DESCRIPTION,long"`
+	DocsURL       string   `json:"docsUrl,omitempty" yaml:"docsUrl,omitempty" table:"DOCS,long"`
+	Status        string   `json:"status" yaml:"status" table:"STATUS,all"`
+}
+
+// newLsCmd creates the ls command
+func newLsCmd() *cobra.Command {
+	lsCmd := &cobra.Command{
+		Use:     "ls",
+		Short:   "lists all configured images and the commands they provide",
+		Aliases: []string{"list"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			long, _ := cmd.Flags().GetBool("long")
+			all, _ := cmd.Flags().GetBool("all")
+			configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+
+			cfg, err := config.LoadMergedConfiguration(cmd.Context(), configIncludes)
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", exitcode.New(exitcode.ConfigError, err))
+			}
+
+			entries := []lsEntry{}
+			for _, entry := range cfg.Images {
+				entries = append(entries, newLsEntry(cfg, entry, "active"))
+			}
+			if all {
+				for _, skipped := range cfg.SkippedImages {
+					skipped.Entry.Scope = "-"
+					entries = append(entries, newLsEntry(cfg, skipped.Entry, "skipped, "+skipped.Reason))
+				}
+			}
+
+			var groups []string
+			if long {
+				groups = append(groups, "long")
+			}
+			if all {
+				groups = append(groups, "all")
+			}
+			return output.Render(cmd.OutOrStdout(), outputFormat(), entries, groups...)
+		},
+	}
+	lsCmd.Flags().BoolP("long", "l", false, "Includes the source file and description of each entry")
+	lsCmd.Flags().BoolP("all", "a", false, "Includes the entries skipped because of their when condition")
+
+	return lsCmd
+}
+
+// newLsEntry creates the row of the entry, the commands denied by the project config are flagged
+func newLsEntry(cfg config.ConfigurationFile, entry config.RunConfigurationEntry, status string) lsEntry {
+	row := lsEntry{Name: entry.Name, Scope: entry.Scope, Image: entry.Image, Provides: entry.Provides, Source: entry.Source, Description: entry.Description, DocsURL: entry.DocsURL, Status: status}
+
+	var labels []string
+	for _, command := range entry.Provides {
+		if _, denied := cfg.GetDeniedCommand(command); denied {
+			row.Denied = append(row.Denied, command)
+			command += "(denied)"
+		}
+		labels = append(labels, command)
+	}
+	row.ProvidesLabel = strings.Join(labels, ",")
+
+	return row
+}
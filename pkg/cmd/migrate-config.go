@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/atomicfile"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/encryption"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// newMigrateConfigCmd creates the migrate-config command
+func newMigrateConfigCmd() *cobra.Command {
+	migrateConfigCmd := &cobra.Command{
+		Use:   "migrate-config [file]",
+		Short: "rewrites the deprecated fields of a configuration file (defaults to the project config) to the current schema",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yes, _ := cmd.Flags().GetBool("yes")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			w := cmd.OutOrStdout()
+
+			var file string
+			if len(args) > 0 {
+				file = args[0]
+			} else {
+				projectFile, err := config.GetProjectConfigFile()
+				if err != nil {
+					return exitcode.New(exitcode.ConfigError, err)
+				} else if projectFile == "" {
+					return exitcode.New(exitcode.ConfigError, errors.New("no envcli project config found, pass the file to migrate"))
+				}
+				file = projectFile
+			}
+			if config.IsEmbeddedConfig(file) {
+				return exitcode.New(exitcode.ConfigError, fmt.Errorf("%s: %w", file, config.ErrEmbeddedConfig))
+			}
+
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+			if encryption.IsEncrypted(content) {
+				return exitcode.New(exitcode.ConfigError, errors.New(file+" is encrypted, decrypt it using envcli config decrypt before migrating it"))
+			}
+
+			migrated, warnings := config.MigrateConfig(content)
+			for _, warning := range warnings {
+				fmt.Fprintf(w, "%s %s\n", file, warning)
+			}
+			if bytes.Equal(content, migrated) {
+				fmt.Fprintf(w, "%s uses the current schema, nothing to migrate.\n", file)
+				return nil
+			}
+			var check config.ConfigurationFile
+			if err = yaml.Unmarshal(migrated, &check); err != nil {
+				return fmt.Errorf("the migrated configuration is invalid, migrate %s manually: %w", file, err)
+			}
+
+			fmt.Fprintln(w)
+			writeLineDiff(w, strings.Split(string(content), "\n"), strings.Split(string(migrated), "\n"))
+			if dryRun {
+				return nil
+			}
+			if !yes && !confirm(cmd.InOrStdin(), w, "Write the migrated configuration to "+file+"?") {
+				return nil
+			}
+			if err = atomicfile.WriteFile(file, migrated, 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "Migrated %s.\n", file)
+			return nil
+		},
+	}
+	migrateConfigCmd.Flags().BoolP("yes", "y", false, "Writes the migrated configuration without asking for confirmation")
+	migrateConfigCmd.Flags().Bool("dry-run", false, "Only prints the changes")
+
+	return migrateConfigCmd
+}
+
+// writeLineDiff prints the changed lines between both versions, prefixed with - and + below the line number of the original
+func writeLineDiff(w io.Writer, before []string, after []string) {
+	// longest common subsequence of the lines
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	inHunk := false
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			inHunk = false
+			i++
+			j++
+			continue
+		case !inHunk:
+			fmt.Fprintf(w, "@@ line %d @@\n", i+1)
+			inHunk = true
+		}
+
+		if j >= len(after) || (i < len(before) && lcs[i+1][j] >= lcs[i][j+1]) {
+			fmt.Fprintf(w, "-%s\n", before[i])
+			i++
+		} else {
+			fmt.Fprintf(w, "+%s\n", after[j])
+			j++
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// newPruneCmd creates the prune command
+func newPruneCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	pruneCmd := &cobra.Command{
+		Use:     "prune",
+		Short:   "removes resources that are no longer used by envcli",
+		Aliases: []string{},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			system, _ := cmd.Flags().GetBool("system")
+			if !system {
+				return cmd.Help()
+			}
+			w := cmd.OutOrStdout()
+			yes, _ := cmd.Flags().GetBool("yes")
+
+			runtime := detectRuntime()
+			if err := containerutil.RequireRuntime(runtime); err != nil {
+				return err
+			}
+			if usage, err := containerutil.DiskUsage(cmd.Context(), runtime); err == nil && strings.TrimSpace(usage) != "" {
+				fmt.Fprintf(w, "Disk usage of %s:\n%s\n", runtime.Name(), strings.ReplaceAll(strings.TrimSpace(usage), "\t", "  "))
+			}
+			if !yes && !confirm(cmd.InOrStdin(), w, "Remove the stopped containers, dangling images and build cache created by envcli?") {
+				return nil
+			}
+			return containerutil.PruneSystem(cmd.Context(), runtime, w)
+		},
+	}
+	pruneCmd.Flags().Bool("system", false, "Runs system prune restricted to the resources created by envcli (stopped containers, dangling built images and build cache)")
+	pruneCmd.Flags().BoolP("yes", "y", false, "Prunes without asking for confirmation")
+	pruneCmd.AddCommand(newPruneImagesCmd(detectRuntime))
+
+	return pruneCmd
+}
+
+// newPruneImagesCmd creates the prune images command
+func newPruneImagesCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	pruneImagesCmd := &cobra.Command{
+		Use:     "images",
+		Short:   "removes local images of configured entries, whose tag isn't referenced by the configuration anymore",
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := cmd.OutOrStdout()
+			yes, _ := cmd.Flags().GetBool("yes")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			olderThanFlag, _ := cmd.Flags().GetString("older-than")
+			configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+
+			var olderThan time.Duration
+			if olderThanFlag != "" {
+				var ageErr error
+				olderThan, ageErr = common.ParseAge(olderThanFlag)
+				if ageErr != nil {
+					return fmt.Errorf("invalid value for --older-than: %w", ageErr)
+				}
+			}
+
+			cfg, err := config.LoadMergedConfiguration(cmd.Context(), configIncludes)
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", exitcode.New(exitcode.ConfigError, err))
+			}
+
+			// entries skipped by their condition are still referenced
+			var configured []string
+			for _, entry := range cfg.Images {
+				configured = append(configured, entry.Image)
+			}
+			for _, skipped := range cfg.SkippedImages {
+				configured = append(configured, skipped.Entry.Image)
+			}
+
+			runtime := detectRuntime()
+			localImages, err := containerutil.ListImages(cmd.Context(), runtime)
+			if err != nil {
+				return fmt.Errorf("failed to list local images: %w", err)
+			}
+
+			prunable := config.SelectPrunableImages(localImages, configured, olderThan, time.Now())
+			if len(prunable) == 0 {
+				fmt.Fprintln(w, "No unreferenced images found.")
+				return nil
+			}
+
+			var reclaimable int64
+			for _, image := range prunable {
+				reclaimable += image.Size
+				fmt.Fprintf(w, "%s (%s, created %s)\n", image.Reference(), formatSize(image.Size), image.Created.Format("2006-01-02"))
+			}
+			fmt.Fprintf(w, "%d image(s), %s can be reclaimed.\n", len(prunable), formatSize(reclaimable))
+
+			if dryRun {
+				return nil
+			}
+			if !yes && !confirm(cmd.InOrStdin(), w, "Remove these images?") {
+				return nil
+			}
+
+			for _, image := range prunable {
+				if removeErr := containerutil.RemoveImage(cmd.Context(), runtime, image.Reference()); removeErr != nil {
+					log.Warn().Err(removeErr).Str("image", image.Reference()).Msg("failed to remove image")
+					continue
+				}
+				fmt.Fprintf(w, "Removed image [%s].\n", image.Reference())
+			}
+
+			return nil
+		},
+	}
+	pruneImagesCmd.Flags().BoolP("yes", "y", false, "Removes the images without asking for confirmation")
+	pruneImagesCmd.Flags().Bool("dry-run", false, "Only prints the images that would be removed")
+	pruneImagesCmd.Flags().String("older-than", "", "Only removes images created before the given age (ex. 30d, 12h)")
+
+	return pruneImagesCmd
+}
+
+// confirm asks the user a yes/no question, defaults to no
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
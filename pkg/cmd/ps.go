@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/spf13/cobra"
+)
+
+// newPsCmd creates the ps command
+func newPsCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	psCmd := &cobra.Command{
+		Use:     "ps",
+		Short:   "lists the running containers started by envcli run",
+		Aliases: []string{},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, _ := cmd.Flags().GetBool("all")
+			runtime := detectRuntime()
+			if err := containerutil.RequireRuntime(runtime); err != nil {
+				return err
+			}
+
+			containers, err := containerutil.ListManagedContainers(cmd.Context(), runtime, all)
+			if err != nil {
+				return fmt.Errorf("failed to list the containers: %w", err)
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 1, 1, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "NAME\tIMAGE\tSTATUS")
+			for _, container := range containers {
+				status := container.Status
+				// detached containers started with a readiness probe show whether they are ready yet
+				if strings.HasPrefix(status, "Up") {
+					if readiness := containerutil.ContainerReadiness(cmd.Context(), runtime, container.Name); readiness != "" {
+						status += " (" + readiness + ")"
+					}
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", container.Name, container.Image, status)
+			}
+			return w.Flush()
+		},
+	}
+	psCmd.Flags().BoolP("all", "a", false, "Includes stopped containers, ex. retained containers")
+
+	return psCmd
+}
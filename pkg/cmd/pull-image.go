@@ -4,38 +4,94 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/EnvCLI/EnvCLI/pkg/common"
 	"github.com/EnvCLI/EnvCLI/pkg/config"
-	"github.com/cidverse/cidverseutils/pkg/containerruntime"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/envcli"
 	"github.com/cidverse/cidverseutils/pkg/filesystem"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(pullImageCmd)
-}
+// newPullImageCmd creates the pull-image command
+func newPullImageCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	pullImageCmd := &cobra.Command{
+		Use:     "pull-image",
+		Short:   "pulls the needed images for the specified commands",
+		Aliases: []string{"pull"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			warm, _ := cmd.Flags().GetBool("warm")
+			changedOnly, _ := cmd.Flags().GetBool("changed-only")
+			configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+
+			runtime := detectRuntime()
+			runner := envcli.NewRunner(envcli.Options{ConfigIncludes: configIncludes, Properties: &propConfig, Runtime: runtime})
+			if runtimeErr := containerutil.RequireRuntime(runtime); runtimeErr != nil {
+				return runtimeErr
+			}
+
+			// config: the entries of the commands, or all entries for --changed-only without commands
+			var entries []config.RunConfigurationEntry
+			if len(args) == 0 && changedOnly {
+				mergedConfig, err := config.LoadMergedConfiguration(cmd.Context(), configIncludes)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", err)
+				}
+				entries = mergedConfig.Images
+				fmt.Fprintln(cmd.OutOrStdout(), "Pulling changed images of all entries.")
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "Pulling images for [%s].\n", strings.Join(args, ", "))
+				for _, commandName := range args {
+					commandConfig, err := config.GetCommandConfiguration(cmd.Context(), commandName, filesystem.GetWorkingDirectory(), configIncludes)
+					if err != nil {
+						return fmt.Errorf("failed to load command config: %w", err)
+					}
+					entries = append(entries, commandConfig)
+				}
+			}
+
+			for _, commandConfig := range entries {
+				log.Debug().Msg("Pulling image for entry [" + commandConfig.Name + "].")
+
+				// images of build entries are built by envcli run
+				if commandConfig.IsBuild() {
+					fmt.Fprintf(cmd.OutOrStdout(), "Skipping [%s], the image is built from %s.\n", commandConfig.Name, commandConfig.Build.Dockerfile)
+					continue
+				}
+
+				// feature: registry mirrors
+				image, mirrorErr := runner.MirroredImage(commandConfig)
+				if mirrorErr != nil {
+					return mirrorErr
+				}
+
+				// feature: only pull images that are missing locally, ex. after the image reference has been changed in the config
+				if changedOnly && containerutil.ImageExists(cmd.Context(), runtime, image) {
+					log.Debug().Str("image", image).Msg("image is present locally, skipping")
+					continue
+				}
+
+				// image
+				pulledImage, pullErr := runner.PullImage(cmd.Context(), image, commandConfig.Image)
+				if pullErr != nil {
+					return pullErr
+				}
+				commandConfig.Image = pulledImage
+
+				// feature: warmup
+				if warm && commandConfig.Warmup != "" {
+					if warmupErr := runner.Warmup(cmd.Context(), commandConfig); warmupErr != nil && commandConfig.WarmupRequired {
+						return fmt.Errorf("warmup of entry %s failed: %w", commandConfig.Name, warmupErr)
+					} else if warmupErr != nil {
+						log.Warn().Err(warmupErr).Str("entry", commandConfig.Name).Msg("warmup failed")
+					}
+				}
+			}
+
+			return nil
+		},
+	}
+	pullImageCmd.Flags().Bool("warm", false, "Runs the warmup command of each entry after pulling the image")
+	pullImageCmd.Flags().Bool("changed-only", false, "Only pulls images that are missing locally, ex. after a version bump in the config - uses all entries if no command is specified")
 
-var pullImageCmd = &cobra.Command{
-	Use:     "pull-image",
-	Short:   "pulls the needed images for the specified commands",
-	Aliases: []string{},
-	Run: func(cmd *cobra.Command, args []string) {
-		configIncludes, _ := cmd.PersistentFlags().GetStringArray("config-include")
-		fmt.Printf("Pulling images for [%s].\n", strings.Join(args, ", "))
-
-		for _, cmd := range args {
-			log.Debug().Msg("Pulling image for command [" + cmd + "].")
-
-			// config: try to load command configuration
-			commandConfig, err := config.GetCommandConfiguration(cmd, filesystem.GetWorkingDirectory(), configIncludes)
-			common.CheckForError(err)
-
-			// container
-			containerRuntime := &containerruntime.ContainerRuntime{}
-			container := containerRuntime.NewContainer()
-			container.SetImage(commandConfig.Image)
-			container.PullImage()
-		}
-	},
+	return pullImageCmd
 }
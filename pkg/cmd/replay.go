@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/envcli"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/spf13/cobra"
+)
+
+// newReplayCmd creates the replay command
+func newReplayCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	return &cobra.Command{
+		Use:     "replay <file>",
+		Short:   "executes the runs recorded using envcli run --record in order and reports the divergences from the recording",
+		Aliases: []string{},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			runner := envcli.NewRunner(envcli.Options{
+				Properties: &propConfig,
+				Runtime:    detectRuntime(),
+				Stdin:      cmd.InOrStdin(),
+				Stdout:     cmd.OutOrStdout(),
+				Stderr:     cmd.ErrOrStderr(),
+			})
+
+			divergences, err := runner.Replay(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if len(divergences) == 0 {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Replay finished, all runs match the recording.")
+				return nil
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "Replay finished with %d divergence(s):\n", len(divergences))
+			for _, divergence := range divergences {
+				fmt.Fprintf(cmd.ErrOrStderr(), "  %s\n", divergence)
+			}
+			return exitcode.NewSilent(exitcode.GeneralError, errors.New("replay diverged from the recording"))
+		},
+	}
+}
@@ -1,10 +1,20 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	goruntime "runtime"
 	"strings"
+	"time"
 
 	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/output"
+	"github.com/EnvCLI/EnvCLI/pkg/proxy"
 	"github.com/cidverse/cidverseutils/pkg/collection"
 	"github.com/mattn/go-colorable"
 	"github.com/rs/zerolog"
@@ -18,6 +28,9 @@ var (
 		LogLevel  string
 		LogFormat string
 		LogCaller bool
+		LogToFile string
+		Runtime   string
+		Output    string
 	}{}
 	validLogLevels  = []string{"trace", "debug", "info", "warn", "error"}
 	validLogFormats = []string{"plain", "color", "json"}
@@ -25,82 +38,180 @@ var (
 
 var propConfig config.PropertyConfigurationFile
 
-func init() {
+// NewRootCommand creates the envcli command tree, commands that need a container runtime get it from detectRuntime.
+// detectRuntime receives the runtime set by the --runtime flag or the container-runtime property, empty if not configured.
+func NewRootCommand(detectRuntime func(preferred string) containerutil.ContainerRuntime) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   `envcli`,
+		Short: "Runs cli commands within docker containers to provide a modern development environment",
+		// errors are reported by the main function, which also maps them to the exit code
+		SilenceErrors: true,
+		SilenceUsage:  true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// log format
+			if !funk.ContainsString(validLogFormats, cfg.LogFormat) {
+				return exitcode.New(exitcode.ConfigError, errors.New("invalid log format "+cfg.LogFormat+" specified, allowed: "+strings.Join(validLogFormats, ",")))
+			}
+			// log level
+			if !funk.ContainsString(validLogLevels, cfg.LogLevel) {
+				return exitcode.New(exitcode.ConfigError, errors.New("invalid log level "+cfg.LogLevel+" specified, allowed: "+strings.Join(validLogLevels, ",")))
+			}
+			level, _ := zerolog.ParseLevel(cfg.LogLevel)
+
+			// detect debug mode
+			debugValue, debugIsSet := os.LookupEnv("ENVCLI_DEBUG")
+			if debugIsSet && strings.ToLower(debugValue) == "true" {
+				level = zerolog.TraceLevel
+			}
+
+			var console io.Writer
+			if cfg.LogFormat == "plain" {
+				console = zerolog.ConsoleWriter{Out: cmd.ErrOrStderr(), NoColor: true}
+			} else if cfg.LogFormat == "color" {
+				console = zerolog.ConsoleWriter{Out: colorable.NewColorableStdout(), NoColor: false}
+			} else if cfg.LogFormat == "json" {
+				console = cmd.ErrOrStderr()
+			}
+
+			// the log file receives all events at trace level, the console keeps its level
+			logOutput := console
+			zerolog.SetGlobalLevel(level)
+			if cfg.LogToFile != "" {
+				file, err := openLogFile(cfg.LogToFile)
+				if err != nil {
+					return exitcode.New(exitcode.ConfigError, fmt.Errorf("failed to open the log file: %w", err))
+				}
+				logOutput = zerolog.MultiLevelWriter(levelFilter{Writer: console, level: level}, file)
+				zerolog.SetGlobalLevel(zerolog.TraceLevel)
+			}
+
+			logContext := zerolog.New(logOutput).With().Timestamp()
+			if cfg.LogCaller {
+				logContext = logContext.Caller()
+			}
+			log.Logger = logContext.Logger()
+
+			// log time format
+			zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+
+			// output format of the listing commands
+			if err := output.Validate(cfg.Output); err != nil {
+				return exitcode.New(exitcode.ConfigError, err)
+			}
+
+			// logging config
+			log.Debug().Str("log-level", cfg.LogLevel).Str("log-format", cfg.LogFormat).Bool("log-caller", cfg.LogCaller).Str("log-to-file", cfg.LogToFile).Msg("configured logging")
+
+			// Global Configuration
+			var propConfigErr error
+			propConfig, propConfigErr = config.LoadPropertyConfig()
+
+			// Configure Proxy Server
+			if propConfigErr == nil {
+				proxy.ApplyEnvironment(
+					collection.MapGetValueOrDefault(propConfig.Properties, "http-proxy", ""),
+					collection.MapGetValueOrDefault(propConfig.Properties, "https-proxy", ""),
+					getNoProxy(),
+				)
+				containerutil.ApplyDockerSocket(goruntime.GOOS, collection.MapGetValueOrDefault(propConfig.Properties, "docker-socket", ""))
+				containerutil.ApplyNerdctlNamespace(collection.MapGetValueOrDefault(propConfig.Properties, "nerdctl-namespace", ""))
+				config.SetConfigCacheDirectory(config.GetConfigCacheDirectory(propConfig))
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
 	rootCmd.PersistentFlags().StringVar(&cfg.LogLevel, "log-level", "info", "log level - allowed: "+strings.Join(validLogLevels, ","))
 	rootCmd.PersistentFlags().StringVar(&cfg.LogFormat, "log-format", "color", "log format - allowed: "+strings.Join(validLogFormats, ","))
 	rootCmd.PersistentFlags().BoolVar(&cfg.LogCaller, "log-caller", false, "include caller in log functions")
+	rootCmd.PersistentFlags().StringVar(&cfg.LogToFile, "log-to-file", "", "additionally writes all log events at trace level into the file, ex. to attach it to a issue")
+	rootCmd.PersistentFlags().StringVarP(&cfg.Output, "output", "o", output.FormatTable, "output format of ls, which, images, doctor and config get-all - allowed: "+strings.Join(output.Formats, ","))
+	rootCmd.PersistentFlags().StringVar(&cfg.Runtime, "runtime", "", "container runtime to use, overrides the container-runtime property - allowed: "+strings.Join(containerutil.RuntimeNames(), ","))
 	rootCmd.PersistentFlags().StringArray("config-include", []string{}, "Additionally include these configuration files, please take note that precedence will be in this order: project config, included, system config")
+
+	// the flags and properties are only available once the command is executed
+	runtime := func() containerutil.ContainerRuntime {
+		return detectRuntime(preferredRuntime())
+	}
+
+	rootCmd.SetHelpCommand(newHelpCmd())
+	rootCmd.AddCommand(newCatalogCmd())
+	rootCmd.AddCommand(newCacheCmd(runtime))
+	rootCmd.AddCommand(newCleanupCmd(runtime))
+	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newDoctorCmd(runtime))
+	rootCmd.AddCommand(newExportCmd())
+	rootCmd.AddCommand(newHistoryCmd())
+	rootCmd.AddCommand(newHooksCmd())
+	rootCmd.AddCommand(newImageCmd(runtime))
+	rootCmd.AddCommand(newImagesCmd())
+	rootCmd.AddCommand(newInstallAliasesCmd())
+	rootCmd.AddCommand(newLockCmd())
+	rootCmd.AddCommand(newLsCmd())
+	rootCmd.AddCommand(newMigrateConfigCmd())
+	rootCmd.AddCommand(newPruneCmd(runtime))
+	rootCmd.AddCommand(newPsCmd(runtime))
+	rootCmd.AddCommand(newPullImageCmd(runtime))
+	rootCmd.AddCommand(newReplayCmd(runtime))
+	rootCmd.AddCommand(newRerunCmd())
+	rootCmd.AddCommand(newRunCmd(runtime))
+	rootCmd.AddCommand(newSchemaCmd())
+	rootCmd.AddCommand(newServeCmd(runtime))
+	rootCmd.AddCommand(newSetupShellCmd())
+	rootCmd.AddCommand(newTaskCmd())
+	rootCmd.AddCommand(newTrustCmd())
+	rootCmd.AddCommand(newUninstallCmd(runtime))
+	rootCmd.AddCommand(newUpdateCmd())
+	rootCmd.AddCommand(newValidateCmd())
+	rootCmd.AddCommand(newVersionCmd())
+	rootCmd.AddCommand(newWhichCmd(runtime))
+
+	return rootCmd
 }
 
-var rootCmd = &cobra.Command{
-	Use:   `envcli`,
-	Short: "Runs cli commands within docker containers to provide a modern development environment",
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// log format
-		if !funk.ContainsString(validLogFormats, cfg.LogFormat) {
-			log.Error().Str("current", cfg.LogFormat).Strs("valid", validLogFormats).Msg("invalid log format specified")
-			os.Exit(1)
-		}
-		var logContext zerolog.Context
-		if cfg.LogFormat == "plain" {
-			logContext = zerolog.New(os.Stderr).Output(zerolog.ConsoleWriter{Out: os.Stderr, NoColor: true}).With().Timestamp()
-		} else if cfg.LogFormat == "color" {
-			colorableOutput := colorable.NewColorableStdout()
-			logContext = zerolog.New(os.Stderr).Output(zerolog.ConsoleWriter{Out: colorableOutput, NoColor: false}).With().Timestamp()
-		} else if cfg.LogFormat == "json" {
-			logContext = zerolog.New(os.Stderr).Output(os.Stderr).With().Timestamp()
-		}
-		if cfg.LogCaller {
-			logContext = logContext.Caller()
-		}
-		log.Logger = logContext.Logger()
+// getNoProxy returns the hosts that should not be accessed using the proxy server
+func getNoProxy() string {
+	return proxy.MergeNoProxy(collection.MapGetValueOrDefault(propConfig.Properties, "no-proxy", ""), proxy.GetNoProxyEnvironment())
+}
 
-		// log time format
-		zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+// preferredRuntime returns the runtime set by the --runtime flag or the container-runtime property, empty if not configured
+func preferredRuntime() string {
+	if cfg.Runtime != "" {
+		return cfg.Runtime
+	}
+	return collection.MapGetValueOrDefault(propConfig.Properties, "container-runtime", "")
+}
 
-		// detect debug mode
-		debugValue, debugIsSet := os.LookupEnv("ENVCLI_DEBUG")
-		if debugIsSet && strings.ToLower(debugValue) == "true" {
-			zerolog.SetGlobalLevel(zerolog.TraceLevel)
-		}
+// Execute executes the root command, the context cancels running downloads and container commands.
+func Execute(ctx context.Context) error {
+	rootCmd := NewRootCommand(containerutil.DetectRuntime)
 
-		// log level
-		if !funk.ContainsString(validLogLevels, cfg.LogLevel) {
-			log.Error().Str("current", cfg.LogLevel).Strs("valid", validLogLevels).Msg("invalid log level specified")
-			os.Exit(1)
+	// feature: remove stale containers in the background, without delaying the command
+	preRun := rootCmd.PersistentPreRunE
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := preRun(cmd, args); err != nil {
+			return err
 		}
-		if cfg.LogLevel == "trace" {
-			zerolog.SetGlobalLevel(zerolog.TraceLevel)
-		} else if cfg.LogLevel == "debug" {
-			zerolog.SetGlobalLevel(zerolog.DebugLevel)
-		} else if cfg.LogLevel == "info" {
-			zerolog.SetGlobalLevel(zerolog.InfoLevel)
-		} else if cfg.LogLevel == "warn" {
-			zerolog.SetGlobalLevel(zerolog.WarnLevel)
-		} else if cfg.LogLevel == "error" {
-			zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+		if cmd.Name() != "cleanup" {
+			props, preferred := propConfig, preferredRuntime()
+			go func() {
+				removeStaleContainers(cmd.Context(), containerutil.DetectRuntime(preferred), props, time.Now())
+			}()
 		}
+		return nil
+	}
 
-		// logging config
-		log.Debug().Str("log-level", cfg.LogLevel).Str("log-format", cfg.LogFormat).Bool("log-caller", cfg.LogCaller).Msg("configured logging")
+	if args := dispatchArgs(os.Args[0], goruntime.GOOS, os.Args[1:]); args != nil {
+		rootCmd.SetArgs(args)
+	}
 
-		// Global Configuration
-		propConfig, propConfigErr := config.LoadPropertyConfig()
-
-		// Configure Proxy Server
-		if propConfigErr == nil {
-			// Set Proxy Server
-			os.Setenv("HTTP_PROXY", collection.MapGetValueOrDefault(propConfig.Properties, "http-proxy", ""))
-			os.Setenv("HTTPS_PROXY", collection.MapGetValueOrDefault(propConfig.Properties, "https-proxy", ""))
-		}
-	},
-	Run: func(cmd *cobra.Command, args []string) {
-		_ = cmd.Help()
-		os.Exit(0)
-	},
+	return rootCmd.ExecuteContext(ctx)
 }
 
-// Execute executes the root command.
-func Execute() error {
-	return rootCmd.Execute()
+// outputFormat returns the format selected by the global --output flag
+func outputFormat() string {
+	return cfg.Output
 }
@@ -1,130 +1,230 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"strings"
+	"time"
 
-	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/ciannotation"
 	"github.com/EnvCLI/EnvCLI/pkg/config"
-	"github.com/cidverse/cidverseutils/pkg/cihelper"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/envcli"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
 	"github.com/cidverse/cidverseutils/pkg/collection"
-	"github.com/cidverse/cidverseutils/pkg/containerruntime"
 	"github.com/cidverse/cidverseutils/pkg/filesystem"
-	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(runCmd)
-	installAliasesCmd.Flags().StringArrayP("env", "e", []string{}, "Sets environment variables within the containers")
-	installAliasesCmd.Flags().StringArrayP("port", "p", []string{}, "Publish ports of the container")
-	installAliasesCmd.Flags().StringArray("userArgs", []string{}, "Allows to specify custom arguments that will be passed to the docker run command for special cases")
-}
-
-var runCmd = &cobra.Command{
-	Use:     "run",
-	Short:   "runs 3rd party commands within their respective docker containers",
-	Aliases: []string{},
-	Run: func(cmd *cobra.Command, args []string) {
-		env, _ := cmd.Flags().GetStringArray("env")
-		port, _ := cmd.Flags().GetStringArray("port")
-		userArgs, _ := cmd.Flags().GetStringArray("userArgs")
-		configIncludes, _ := cmd.PersistentFlags().GetStringArray("config-include")
-
-		// parse command
-		commandName := args[0]
-
-		// iterate and quote args if needed
-		commandArgs := append([]string{commandName}, strings.Join(args[1:], " "))
-		commandWithArguments := common.ParseAndEscapeArgs(commandArgs)
-
-		log.Debug().Msg("Received request to run command [" + commandName + "] - with Arguments [" + commandWithArguments + "].")
-
-		// config: try to load command configuration
-		commandConfig, commandConfigErr := config.GetCommandConfiguration(commandName, filesystem.GetWorkingDirectory(), configIncludes)
-		if commandConfigErr != nil {
-			log.Fatal().Err(commandConfigErr).Msg("failed to load command config")
-		}
-
-		// container runtime
-		containerRuntime := &containerruntime.ContainerRuntime{}
-		container := containerRuntime.NewContainer()
-		container.SetImage(commandConfig.Image)
-		container.SetEntrypoint(commandConfig.Entrypoint)
-		container.SetCommandShell(commandConfig.Shell)
-
-		// mounts
-		projectOrExecutionDir := config.GetProjectOrWorkingDirectory()
-		mountDir := commandConfig.Directory
-		if mountDir == "" {
-			mountDir = projectOrExecutionDir
-		}
-		log.Debug().Str("source", projectOrExecutionDir).Str("target", mountDir).Msg("Adding volume mount")
-		container.AddVolume(containerruntime.ContainerMount{MountType: "directory", Source: projectOrExecutionDir, Target: mountDir})
-		container.SetWorkingDirectory(commandConfig.Directory + "/" + filesystem.GetPathRelativeToDirectory(filesystem.GetWorkingDirectory(), projectOrExecutionDir))
-
-		// core: expose ports (command args)
-		container.AddContainerPorts(port)
-
-		// core: pass environment variables (command args)
-		container.AddEnvironmentVariables(env)
-
-		// feature: user args
-		if len(userArgs) > 0 {
-			container.SetUserArgs(strings.Join(userArgs, " "))
-		}
-
-		// feature: before_script
-		var commandWithBeforeScript = ""
-		commandWithBeforeScript = strings.TrimSpace(commandWithArguments)
-		if commandConfig.BeforeScript != nil {
-			commandWithBeforeScript = strings.Join(commandConfig.BeforeScript[:], ";") + " && " + commandWithBeforeScript
-
-			commandWithBeforeScript = strings.Replace(commandWithBeforeScript, "{HTTPProxy}", collection.MapGetValueOrDefault(propConfig.Properties, "http-proxy", ""), -1)
-			commandWithBeforeScript = strings.Replace(commandWithBeforeScript, "{HTTPSProxy}", collection.MapGetValueOrDefault(propConfig.Properties, "https-proxy", ""), -1)
-		}
-		log.Debug().Msg("Setting new command with before_script: " + commandWithBeforeScript)
-		container.SetCommand(commandWithBeforeScript)
-
-		// feature: container runtime access
-		if commandConfig.ContainerRuntimeAccess {
-			container.AllowContainerRuntimeAcccess()
-		}
+// newRunCmd creates the run command
+func newRunCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	runCmd := &cobra.Command{
+		Use:     "run [flags] <command> [args...]",
+		Args:    cobra.MinimumNArgs(1),
+		Short:   "runs 3rd party commands within their respective docker containers",
+		Aliases: []string{},
+		// paths of the arguments are completed as seen by the container of the matched entry
+		ValidArgsFunction: completeRunArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			variant, _ := cmd.Flags().GetString("variant")
+			env, _ := cmd.Flags().GetStringArray("env")
+			envFiles, _ := cmd.Flags().GetStringArray("env-file")
+			port, _ := cmd.Flags().GetStringArray("port")
+			userArgs, _ := cmd.Flags().GetStringArray("userArgs")
+			keepContainer, _ := cmd.Flags().GetBool("keep-container")
+			logFile, _ := cmd.Flags().GetString("log-file")
+			shellOverride, _ := cmd.Flags().GetString("shell")
+			retries, _ := cmd.Flags().GetInt("retries")
+			rebuild, _ := cmd.Flags().GetBool("rebuild")
+			noDerive, _ := cmd.Flags().GetBool("no-derive")
+			noDefaultArgs, _ := cmd.Flags().GetBool("no-default-args")
+			frozen, _ := cmd.Flags().GetBool("frozen")
+			watchPatterns, _ := cmd.Flags().GetStringArray("watch")
+			ignorePatterns, _ := cmd.Flags().GetStringArray("ignore")
+			recordFile, _ := cmd.Flags().GetString("record")
+			notify, _ := cmd.Flags().GetBool("notify")
+			timings, _ := cmd.Flags().GetBool("timings")
+			lowPriority, _ := cmd.Flags().GetBool("low-priority")
+			ciAnnotations, _ := cmd.Flags().GetString("ci-annotations")
+			summaryFormat, summaryErr := resolveSummaryFormat(cmd)
+			if summaryErr != nil {
+				return summaryErr
+			}
+			configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+			chain, _ := cmd.Flags().GetBool("chain")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			copyCommand, _ := cmd.Flags().GetBool("copy")
+			detach, _ := cmd.Flags().GetBool("detach")
+			batchPattern, _ := cmd.Flags().GetString("in")
+			parallel, _ := cmd.Flags().GetInt("parallel")
+			strict, _ := cmd.Flags().GetBool("strict")
+
+			opts := envcli.Options{
+				ConfigIncludes: configIncludes,
+				Properties:     &propConfig,
+				Runtime:        detectRuntime(),
+				Variant:        variant,
+				Env:            env,
+				EnvFiles:       envFiles,
+				Ports:          port,
+				UserArgs:       userArgs,
+				KeepContainer:  keepContainer,
+				LogFile:        logFile,
+				Shell:          shellOverride,
+				Rebuild:        rebuild,
+				NoDerive:       noDerive,
+				NoDefaultArgs:  noDefaultArgs,
+				Frozen:         frozen,
+				RecordFile:     recordFile,
+				Timings:        timings,
+				Notify:         notify,
+				CIAnnotations:  ciAnnotations,
+				Summary:        summaryFormat,
+				DryRun:         dryRun,
+				Detach:         detach,
+				Stdin:          cmd.InOrStdin(),
+				Stdout:         cmd.OutOrStdout(),
+				Stderr:         cmd.ErrOrStderr(),
+			}
+			if cmd.Flags().Changed("retries") {
+				opts.Retries = &retries
+			}
+			if lowPriority {
+				opts.Priority = config.PriorityLow
+			}
+			if containerutil.IsTerminal(cmd.InOrStdin()) {
+				opts.ConfirmTrust = func(hooks config.ProjectHooks) bool {
+					printHooks(cmd.ErrOrStderr(), hooks)
+					return confirm(cmd.InOrStdin(), cmd.ErrOrStderr(), "Trust this project config and execute its hooks?")
+				}
+			}
 
-		// feature: caching
-		for _, cachingEntry := range commandConfig.Caching {
-			if collection.MapGetValueOrDefault(propConfig.Properties, "cache-path", "") == "" {
-				log.Warn().Msg("Cache is disabled, CachePath not set.")
-				break
+			// feature: dry-run, prints (or copies) the rendered run command
+			if copyCommand && !dryRun {
+				return exitcode.New(exitcode.ConfigError, errors.New("--copy requires --dry-run"))
+			}
+			if detach && (len(watchPatterns) > 0 || chain) {
+				return exitcode.New(exitcode.ConfigError, errors.New("--detach can't be combined with --watch or --chain"))
+			}
+			if dryRun && len(watchPatterns) > 0 {
+				return exitcode.New(exitcode.ConfigError, errors.New("--dry-run can't be combined with --watch"))
+			}
+			var rendered strings.Builder
+			if copyCommand {
+				opts.Stdout = &rendered
 			}
 
-			var cacheFolder = collection.MapGetValueOrDefault(propConfig.Properties, "cache-path", "") + "/" + cachingEntry.Name
-			filesystem.CreateDirectory(cacheFolder)
-			container.AddCacheMount(cachingEntry.Name, cacheFolder, cachingEntry.ContainerDirectory)
-		}
+			// feature: batch mode, the command runs once per matching directory
+			if batchPattern != "" {
+				if chain || len(watchPatterns) > 0 || copyCommand {
+					return exitcode.New(exitcode.ConfigError, errors.New("--in can't be combined with --chain, --watch or --copy"))
+				}
+				if parallel < 1 {
+					return exitcode.New(exitcode.ConfigError, errors.New("--parallel must be at least 1"))
+				}
+				directories, batchErr := envcli.BatchDirectories(filesystem.GetWorkingDirectory(), batchPattern)
+				if batchErr != nil {
+					return exitcode.New(exitcode.ConfigError, batchErr)
+				}
+				started := time.Now()
+				_, err := envcli.NewRunner(opts).RunBatch(cmd.Context(), directories, args[0], args[1:], envcli.BatchOptions{Parallel: parallel, Strict: strict})
+				if !dryRun {
+					recordHistory(cmd, args, started, err)
+				}
+				return err
+			} else if cmd.Flags().Changed("parallel") || strict {
+				return exitcode.New(exitcode.ConfigError, errors.New("--parallel and --strict require --in"))
+			}
 
-		// feature: capabilities
-		for _, cap := range commandConfig.CapAdd {
-			container.AddCapability(cap)
-		}
+			// feature: command chains, each command runs within the container of its entry
+			if chain {
+				if len(args) != 1 || len(watchPatterns) > 0 {
+					return exitcode.New(exitcode.ConfigError, errors.New("--chain expects the chain as a single quoted argument (ex. envcli run --chain \"npm ci && npm test\") and can't be combined with --watch"))
+				}
+				commands, chainErr := envcli.ParseChain(args[0])
+				if chainErr != nil {
+					return exitcode.New(exitcode.ConfigError, chainErr)
+				}
+				started := time.Now()
+				_, err := envcli.NewRunner(opts).RunChain(cmd.Context(), commands)
+				if dryRun {
+					return printDryRun(cmd, copyCommand, rendered.String(), err)
+				}
+				recordHistory(cmd, args, started, err)
+				return err
+			}
 
-		// feature: pass all env variables (excludes system variables like PATH, ...) in CI environments
-		if cihelper.IsCIEnvironment() {
-			container.AddAllEnvironmentVariables()
-		}
+			// feature: watch mode, stopped using ctrl+c
+			if len(watchPatterns) > 0 {
+				return envcli.NewRunner(opts).Watch(cmd.Context(), args[0], args[1:], envcli.WatchOptions{Patterns: watchPatterns, Ignore: ignorePatterns})
+			}
 
-		// feature: proxy environment
-		httpProxy := collection.MapGetValueOrDefault(propConfig.Properties, "http-proxy", "")
-		if httpProxy != "" {
-			container.AddEnvironmentVariable("http_proxy", httpProxy)
-		}
+			// the exit code of the command is passed through
+			started := time.Now()
+			_, err := envcli.NewRunner(opts).Run(cmd.Context(), args[0], args[1:])
+			if dryRun {
+				return printDryRun(cmd, copyCommand, rendered.String(), err)
+			}
+			recordHistory(cmd, args, started, err)
+			return err
+		},
+	}
+	runCmd.Flags().String("variant", "", "Applies the variant of the entry (ex. prod), defaults to the "+config.VariantEnv+" environment variable")
+	runCmd.Flags().StringArrayP("env", "e", []string{}, "Sets environment variables within the containers")
+	runCmd.Flags().StringArray("env-file", []string{}, "Sets the environment variables of the dotenv file within the containers, can be repeated")
+	runCmd.Flags().StringArrayP("port", "p", []string{}, "Publish ports of the container")
+	runCmd.Flags().StringArray("userArgs", []string{}, "Allows to specify custom arguments that will be passed to the docker run command for special cases")
+	runCmd.Flags().Bool("keep-container", false, "Keeps the container after it exited, to allow inspecting it (remove it using envcli cleanup)")
+	runCmd.Flags().String("log-file", "", "Additionally writes the command output into the specified file")
+	runCmd.Flags().SetInterspersed(false)
+	runCmd.Flags().Int("retries", 0, "Executes the command up to N additional times if it fails, overrides the retries of the entry")
+	runCmd.Flags().Bool("rebuild", false, "Builds the image of entries with a build section, even if it already exists")
+	runCmd.Flags().Bool("no-derive", false, "Installs the extraPackages of the entry at container start, instead of deriving a image with the packages installed (property no-derive)")
+	runCmd.Flags().Bool("no-default-args", false, "Skips the default arguments configured for the command (defaultArgs)")
+	runCmd.Flags().Bool("frozen", false, "Fails if the project has no lock file or the image of the entry changed since it has been locked (envcli lock)")
+	runCmd.Flags().StringArray("watch", []string{}, "Runs the command again whenever a file matching the glob changes (ex. \"src/**/*.go\"), can be repeated")
+	runCmd.Flags().StringArray("ignore", []string{}, "Ignores changes of files matching the glob in watch mode, can be repeated")
+	runCmd.Flags().String("record", "", "Appends the container run (image digest, run command, names of the environment variables, exit code) to the file, replay it using envcli replay")
+	runCmd.Flags().Bool("timings", false, "Prints the breakdown of the run duration after the command: config, image pull (duration and size) or local image, container startup and execution")
+	runCmd.Flags().Bool("low-priority", false, "Runs the container with lower cpu and io weights, and the runtime client under nice and ionice on linux, to keep the host responsive")
+	runCmd.Flags().Bool("notify", false, "Shows a desktop notification once the command finished, regardless of the notify-after property")
+	runCmd.Flags().Bool("summary", false, "Prints a single summary line (command, image, digest, exit code, duration) as last line to stderr, like the emit-summary property")
+	runCmd.Flags().String("summary-format", "", "Format of the summary line ("+strings.Join(envcli.SummaryFormats, ", ")+"), defaults to the summary-format property or text")
+	runCmd.Flags().String("ci-annotations", ciannotation.ProviderOff, "Groups the output into a collapsible section and annotates failures ("+strings.Join(ciannotation.Providers, ", ")+"), auto detects GitHub Actions and GitLab CI")
+	runCmd.Flags().Bool("chain", false, "Splits the command on && and ; and runs each command within the container of its entry, ex. envcli run --chain \"terraform fmt && tflint\"")
+	runCmd.Flags().Bool("dry-run", false, "Prints the rendered container run command instead of running it, the image is neither pulled nor built")
+	runCmd.Flags().Bool("copy", false, "Copies the command printed by --dry-run to the clipboard, it is printed if there is no clipboard")
+	runCmd.Flags().BoolP("detach", "d", false, "Starts the container in the background (ex. a dev server) and waits until the readiness probe of the entry succeeds")
+	runCmd.Flags().String("in", "", "Runs the command once per directory matching the glob (ex. \"services/*\"), each directory uses its nearest project config")
+	runCmd.Flags().Int("parallel", 1, "Number of directories of --in processed at once, the output is prefixed with the directory")
+	runCmd.Flags().Bool("strict", false, "Fails the directories of --in without a entry providing the command, instead of skipping them")
+	runCmd.Flags().String("shell", "", "Overrides the configured shell for this invocation ("+strings.Join(containerutil.SupportedShells, ", ")+")")
+
+	return runCmd
+}
 
-		httpsProxy := collection.MapGetValueOrDefault(propConfig.Properties, "https-proxy", "")
-		if httpsProxy != "" {
-			container.AddEnvironmentVariable("https_proxy", httpsProxy)
-		}
+// printDryRun copies the rendered commands of a dry-run to the clipboard, they have already been printed without --copy
+func printDryRun(cmd *cobra.Command, copyCommand bool, rendered string, err error) error {
+	if err != nil || !copyCommand {
+		return err
+	}
+	copyOrPrint(cmd, strings.TrimSuffix(rendered, "\n"), "command")
+	return nil
+}
 
-		// detect container service and send command
-		log.Info().Msg("Executing command in container [" + commandConfig.Image + "].")
-		container.StartContainer()
-	},
+// resolveSummaryFormat returns the format of the summary line, empty if neither --summary, --summary-format nor the emit-summary property enable it
+func resolveSummaryFormat(cmd *cobra.Command) (string, error) {
+	summary, _ := cmd.Flags().GetBool("summary")
+	format, _ := cmd.Flags().GetString("summary-format")
+	if !summary && format == "" && propConfig.Properties["emit-summary"] != "true" {
+		return "", nil
+	}
+
+	if format == "" {
+		format = collection.MapGetValueOrDefault(propConfig.Properties, "summary-format", envcli.SummaryFormatText)
+	}
+	if found, _ := collection.InArray(format, envcli.SummaryFormats); !found {
+		return "", exitcode.New(exitcode.ConfigError, fmt.Errorf("invalid summary format %s, allowed: %s", format, strings.Join(envcli.SummaryFormats, ", ")))
+	}
+	return format, nil
 }
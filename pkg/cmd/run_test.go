@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunArgumentParsing(t *testing.T) {
+	var tests = []struct {
+		args     []string
+		expected []string
+		env      string
+	}{
+		// flags are parsed only before the command name
+		{[]string{"--env", "GOOS=linux", "go", "build", "--env", "ignored"}, []string{"go", "build", "--env", "ignored"}, "GOOS=linux"},
+		{[]string{"npm", "install", "--save-dev", "left-pad"}, []string{"npm", "install", "--save-dev", "left-pad"}, ""},
+		{[]string{"ls", "-la"}, []string{"ls", "-la"}, ""},
+		{[]string{"go", "--", "test"}, []string{"go", "--", "test"}, ""},
+		{[]string{"--", "npm", "-v"}, []string{"npm", "-v"}, ""},
+		{[]string{"git", "--env", "A=B", "--shell", "sh", "-p", "80", "--log-level", "debug", "--config-include", "x.yml"}, []string{"git", "--env", "A=B", "--shell", "sh", "-p", "80", "--log-level", "debug", "--config-include", "x.yml"}, ""},
+	}
+
+	for _, test := range tests {
+		runCmd := newRunCmd(nil)
+		if err := runCmd.ParseFlags(test.args); err != nil {
+			t.Errorf("%v: unexpected error %v", test.args, err)
+			continue
+		}
+
+		args := runCmd.Flags().Args()
+		if strings.Join(args, "|") != strings.Join(test.expected, "|") {
+			t.Errorf("%v: expected args %v, got %v", test.args, test.expected, args)
+		}
+		if env, _ := runCmd.Flags().GetStringArray("env"); strings.Join(env, ",") != test.env {
+			t.Errorf("%v: expected env %s, got %v", test.args, test.env, env)
+		}
+	}
+}
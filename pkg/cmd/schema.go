@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/EnvCLI/EnvCLI/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+// newSchemaCmd creates the schema command
+func newSchemaCmd() *cobra.Command {
+	schemaCmd := &cobra.Command{
+		Use:     "schema",
+		Short:   "prints the JSON schema of the .envcli.yml, used by editors for validation and completion",
+		Aliases: []string{},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, _ := cmd.Flags().GetString("file")
+
+			data, err := json.MarshalIndent(schema.Generate(), "", "  ")
+			if err != nil {
+				return err
+			}
+			data = append(data, '\n')
+
+			if file == "" {
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+			if err = os.WriteFile(file, data, 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Written the schema to %s\n", file)
+			return nil
+		},
+	}
+	schemaCmd.Flags().StringP("file", "f", "", "Writes the schema into the file instead of stdout")
+
+	return schemaCmd
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/server"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// newServeCmd creates the serve command
+func newServeCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:   "serve --socket <path|port>",
+		Short: "serves a local JSON API for editor integrations (list commands, resolve a command, validate the config, schema), stopped using ctrl+c",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			socket, _ := cmd.Flags().GetString("socket")
+			tokenFile, _ := cmd.Flags().GetString("token-file")
+			configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+			if socket == "" {
+				return exitcode.New(exitcode.ConfigError, errors.New("expected --socket with the path of a unix socket or a port on localhost"))
+			}
+
+			listener, err := server.Listen(socket)
+			if err != nil {
+				return exitcode.New(exitcode.ConfigError, fmt.Errorf("failed to listen on %s: %w", socket, err))
+			}
+			defer listener.Close()
+
+			// the token file of a port is named after the port, as the port may have been chosen by the system (--socket 0)
+			address := "unix://" + socket
+			if tcpAddr, isTCP := listener.Addr().(*net.TCPAddr); isTCP {
+				address = "http://" + tcpAddr.String()
+				if tokenFile == "" {
+					tokenFile = filepath.Join(config.GetServeDirectory(propConfig), strconv.Itoa(tcpAddr.Port)+".token")
+				}
+			} else if tokenFile == "" {
+				tokenFile = socket + ".token"
+			}
+
+			token, err := server.NewToken()
+			if err != nil {
+				return err
+			}
+			if err = os.MkdirAll(filepath.Dir(tokenFile), 0700); err != nil {
+				return err
+			}
+			if err = server.WriteToken(tokenFile, token); err != nil {
+				return fmt.Errorf("failed to write the token file: %w", err)
+			}
+			defer func() {
+				if removeErr := os.Remove(tokenFile); removeErr != nil {
+					log.Warn().Err(removeErr).Str("file", tokenFile).Msg("failed to remove the token file")
+				}
+			}()
+
+			srv := &server.Server{
+				Version:        displayVersion(),
+				Token:          token,
+				ConfigIncludes: configIncludes,
+				Properties:     propConfig.Properties,
+				RuntimeAvailable: func() bool {
+					return containerutil.RequireRuntime(detectRuntime()) == nil
+				},
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Listening on %s, the token has been written to %s\n", address, tokenFile)
+			return srv.Serve(cmd.Context(), listener)
+		},
+	}
+	serveCmd.Flags().String("socket", "", "Path of the unix socket or port on localhost (127.0.0.1) to listen on, 0 picks a free port")
+	serveCmd.Flags().String("token-file", "", "File the token is written to, defaults to <socket>.token or <cache>/serve/<port>.token")
+
+	return serveCmd
+}
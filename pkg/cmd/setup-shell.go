@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+
+	"github.com/EnvCLI/EnvCLI/pkg/shellsetup"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/spf13/cobra"
+)
+
+// newSetupShellCmd creates the setup-shell command
+func newSetupShellCmd() *cobra.Command {
+	setupShellCmd := &cobra.Command{
+		Use:     "setup-shell",
+		Short:   "adds the alias directory to the PATH and enables completions in the rc / profile file of your shell",
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shell, _ := cmd.Flags().GetString("shell")
+			printOnly, _ := cmd.Flags().GetBool("print-only")
+			remove, _ := cmd.Flags().GetBool("remove")
+
+			if shell == "auto" {
+				var err error
+				shell, err = shellsetup.DetectShell(goruntime.GOOS, os.Getenv)
+				if err != nil {
+					return fmt.Errorf("failed to detect the shell: %w", err)
+				}
+			}
+
+			snippet, err := shellsetup.Snippet(shell, filesystem.GetExecutionDirectory())
+			if err != nil {
+				return fmt.Errorf("invalid shell: %w", err)
+			}
+			if printOnly {
+				fmt.Fprint(cmd.OutOrStdout(), snippet)
+				return nil
+			}
+
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return fmt.Errorf("failed to determine the home directory: %w", err)
+			}
+			profileFile, err := shellsetup.ProfileFile(shell, goruntime.GOOS, home, os.Getenv)
+			if err != nil {
+				return fmt.Errorf("invalid shell: %w", err)
+			}
+
+			content, err := os.ReadFile(profileFile)
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to read the profile %s: %w", profileFile, err)
+			}
+
+			var updated string
+			var changed bool
+			if remove {
+				updated, changed = shellsetup.RemoveBlock(string(content))
+			} else {
+				updated, changed = shellsetup.ApplyBlock(string(content), snippet)
+			}
+			if !changed {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s is already up to date.\n", profileFile)
+				return nil
+			}
+
+			if err = os.MkdirAll(filepath.Dir(profileFile), os.ModePerm); err != nil {
+				return fmt.Errorf("failed to create the profile directory of %s: %w", profileFile, err)
+			}
+			if err = os.WriteFile(profileFile, []byte(updated), 0644); err != nil {
+				return fmt.Errorf("failed to write the profile %s: %w", profileFile, err)
+			}
+
+			if remove {
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed the envcli block from %s.\n", profileFile)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "Updated %s with:\n%s", profileFile, snippet)
+				fmt.Fprintln(cmd.OutOrStdout(), "Restart your shell to apply the changes.")
+			}
+
+			return nil
+		},
+	}
+	setupShellCmd.Flags().String("shell", "auto", "The shell to configure (auto, bash, zsh, fish or powershell)")
+	setupShellCmd.Flags().Bool("print-only", false, "Only prints the snippet instead of modifying the profile")
+	setupShellCmd.Flags().Bool("remove", false, "Removes the snippet from the profile")
+
+	return setupShellCmd
+}
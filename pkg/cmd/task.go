@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/report"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// taskOutputTailSize is the amount of output retained per step for failure messages in reports
+const taskOutputTailSize = 4096
+
+// newTaskCmd creates the task command
+func newTaskCmd() *cobra.Command {
+	taskCmd := &cobra.Command{
+		Use:     "task",
+		Short:   "runs all steps of a task defined in the configuration",
+		Aliases: []string{},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reports, _ := cmd.Flags().GetStringArray("report")
+			configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+
+			// validate report targets before running anything
+			for _, target := range reports {
+				if _, _, err := report.ParseTarget(target); err != nil {
+					return fmt.Errorf("invalid report %s: %w", target, err)
+				}
+			}
+
+			cfg, err := config.LoadMergedConfiguration(cmd.Context(), configIncludes)
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", exitcode.New(exitcode.ConfigError, err))
+			}
+			task, found := cfg.GetTask(args[0])
+			if !found {
+				return exitcode.New(exitcode.ConfigError, fmt.Errorf("task %s not found in configuration", args[0]))
+			}
+
+			executable, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("failed to detect the envcli executable: %w", err)
+			}
+
+			// run steps
+			result := report.TaskResult{Name: task.Name}
+			taskStarted := time.Now()
+			for i, step := range task.Steps {
+				stepName := step.Name
+				if stepName == "" {
+					stepName = fmt.Sprintf("step-%d", i+1)
+				}
+				log.Info().Str("task", task.Name).Str("step", stepName).Msg("Running step [" + step.Run + "].")
+
+				runArgs := []string{"run"}
+				for _, include := range configIncludes {
+					runArgs = append(runArgs, "--config-include", include)
+				}
+				runArgs = append(runArgs, common.SplitArgs(step.Run)...)
+
+				tail := report.NewRingBuffer(taskOutputTailSize)
+				stepCmd := exec.Command(executable, runArgs...)
+				stepCmd.Stdin = cmd.InOrStdin()
+				stepCmd.Stdout = io.MultiWriter(cmd.OutOrStdout(), tail)
+				stepCmd.Stderr = io.MultiWriter(cmd.ErrOrStderr(), tail)
+
+				stepStarted := time.Now()
+				stepResult := report.StepResult{Name: stepName, Command: step.Run}
+				if runErr := stepCmd.Run(); runErr != nil {
+					stepResult.ExitCode = 1
+					var exitErr *exec.ExitError
+					if errors.As(runErr, &exitErr) {
+						stepResult.ExitCode = exitErr.ExitCode()
+					}
+					stepResult.Output = tail.String()
+				}
+				stepResult.Duration = time.Since(stepStarted)
+				result.Steps = append(result.Steps, stepResult)
+
+				if stepResult.Failed() {
+					log.Error().Str("task", task.Name).Str("step", stepName).Int("exit-code", stepResult.ExitCode).Msg("step failed")
+					break
+				}
+			}
+			result.Duration = time.Since(taskStarted)
+
+			// reports
+			for _, target := range reports {
+				format, file, _ := report.ParseTarget(target)
+				if reportErr := report.Write(format, file, result); reportErr != nil {
+					log.Error().Err(reportErr).Str("file", file).Msg("failed to write report")
+				}
+			}
+
+			// the failed step has already been logged
+			if result.Failures() > 0 {
+				failed := result.Steps[len(result.Steps)-1]
+				return exitcode.NewSilent(failed.ExitCode, fmt.Errorf("step %s of task %s failed", failed.Name, task.Name))
+			}
+
+			return nil
+		},
+	}
+	taskCmd.Flags().StringArray("report", []string{}, "Writes a report of the task run in the format format=file, supported formats: junit, json")
+
+	return taskCmd
+}
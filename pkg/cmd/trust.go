@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/spf13/cobra"
+)
+
+// newTrustCmd creates the trust command
+func newTrustCmd() *cobra.Command {
+	trustCmd := &cobra.Command{
+		Use:     "trust",
+		Short:   "trusts the project config, which allows envcli run to execute its hooks on the host",
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			yes, _ := cmd.Flags().GetBool("yes")
+			hooks, err := config.GetProjectHooks()
+			if err != nil {
+				return fmt.Errorf("failed to load the hooks: %w", exitcode.New(exitcode.ConfigError, err))
+			}
+			if hooks.ConfigFile == "" {
+				return exitcode.New(exitcode.ConfigError, errors.New("no envcli project config found"))
+			}
+
+			// the hooks are reviewed before the config is trusted
+			if !hooks.IsEmpty() {
+				printHooks(cmd.OutOrStdout(), hooks)
+				if !yes && !confirm(cmd.InOrStdin(), cmd.OutOrStdout(), "Trust this project config and execute its hooks?") {
+					return nil
+				}
+			}
+			if err = config.Trust(hooks); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Trusted %s, it needs to be trusted again after it or the scripts of its hooks have been modified.\n", hooks.ConfigFile)
+			return nil
+		},
+	}
+	trustCmd.Flags().BoolP("yes", "y", false, "Trusts the project config without asking for confirmation")
+
+	return trustCmd
+}
+
+// printHooks prints the hooks of the project config and the scripts they call, to review them before trusting the config
+func printHooks(w io.Writer, hooks config.ProjectHooks) {
+	fmt.Fprintf(w, "The project config %s defines hooks executed on the host:\n", hooks.ConfigFile)
+	if hooks.PreRun != "" {
+		fmt.Fprintf(w, "  preRun:  %s\n", hooks.PreRun)
+	}
+	if hooks.PostRun != "" {
+		fmt.Fprintf(w, "  postRun: %s\n", hooks.PostRun)
+	}
+	for _, file := range config.HookFiles(hooks) {
+		fmt.Fprintf(w, "  script:  %s\n", file)
+	}
+}
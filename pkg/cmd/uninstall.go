@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/EnvCLI/EnvCLI/pkg/aliases"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// newUninstallCmd creates the uninstall command
+func newUninstallCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	uninstallCmd := &cobra.Command{
+		Use:     "uninstall",
+		Short:   "removes aliases, retained containers, caches and optionally the configuration created by envcli",
+		Aliases: []string{},
+		Run: func(cmd *cobra.Command, args []string) {
+			w := cmd.OutOrStdout()
+			purge, _ := cmd.Flags().GetBool("purge")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			// aliases
+			installedAliases, err := aliases.ListInstalledAliases()
+			if err != nil {
+				log.Warn().Err(err).Msg("failed to list installed aliases")
+			}
+			for _, file := range installedAliases {
+				uninstallRemove(w, "alias", file, dryRun, func() error { return os.Remove(file) })
+			}
+
+			// retained containers
+			runtime := detectRuntime()
+			if containerutil.RequireRuntime(runtime) == nil {
+				containers, listErr := containerutil.ListRetainedContainers(cmd.Context(), runtime)
+				if listErr != nil {
+					log.Warn().Err(listErr).Msg("failed to list retained containers")
+				}
+				for _, container := range containers {
+					uninstallRemove(w, "container", container, dryRun, func() error { return containerutil.RemoveContainer(cmd.Context(), runtime, container) })
+				}
+			}
+
+			// cache directory
+			cachePath := collection.MapGetValueOrDefault(propConfig.Properties, "cache-path", "")
+			if cachePath != "" {
+				uninstallRemove(w, "cache", cachePath, dryRun, func() error { return os.RemoveAll(cachePath) })
+			}
+
+			// configuration
+			if purge {
+				for _, file := range []string{config.GetGlobalConfigurationFile(propConfig), config.GetPropertyConfigFile()} {
+					if _, statErr := os.Stat(file); statErr == nil {
+						uninstallRemove(w, "config", file, dryRun, func() error { return os.Remove(file) })
+					}
+				}
+			}
+
+			executable, _ := os.Executable()
+			fmt.Fprintf(w, "The envcli binary has not been removed, delete [%s] to complete the uninstallation.\n", executable)
+		},
+	}
+	uninstallCmd.Flags().Bool("purge", false, "Also removes the property file and the global configuration")
+	uninstallCmd.Flags().Bool("dry-run", false, "Only prints what would be removed")
+
+	return uninstallCmd
+}
+
+// uninstallRemove prints and removes a single item, or only prints it in dry-run mode
+func uninstallRemove(w io.Writer, kind string, name string, dryRun bool, remove func() error) {
+	if dryRun {
+		fmt.Fprintf(w, "Would remove %s [%s]\n", kind, name)
+		return
+	}
+
+	if err := remove(); err != nil {
+		log.Warn().Err(err).Str(kind, name).Msg("failed to remove " + kind)
+		return
+	}
+	fmt.Fprintf(w, "Removed %s [%s]\n", kind, name)
+}
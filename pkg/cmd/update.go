@@ -1,9 +1,16 @@
 package cmd
 
 import (
+	"fmt"
+	"io"
+	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/download"
 	"github.com/EnvCLI/EnvCLI/pkg/updater"
 	"github.com/cidverse/cidverseutils/pkg/cihelper"
 	"github.com/cidverse/cidverseutils/pkg/collection"
@@ -11,28 +18,85 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func init() {
-	rootCmd.AddCommand(updateCmd)
+// newUpdateCmd creates the self-update command
+func newUpdateCmd() *cobra.Command {
+	updateCmd := &cobra.Command{
+		Use:     "self-update",
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, _ := cmd.Flags().GetString("target")
+			force, _ := cmd.Flags().GetBool("force")
+			maxRetries, _ := cmd.Flags().GetInt("max-retries")
+			skipChecksum, _ := cmd.Flags().GetBool("skip-checksum")
+
+			// Update Check, once a day (not in CI)
+			appUpdater := updater.ApplicationUpdater{GitHubOrg: "EnvCLI", GitHubRepository: "EnvCLI", DownloadCacheDir: config.GetDownloadCacheDirectory(propConfig), MaxRetries: maxRetries, SkipChecksum: skipChecksum}
+			if stderr, ok := cmd.ErrOrStderr().(*os.File); ok && containerutil.IsTerminal(stderr) {
+				bar := &progressBar{out: stderr, label: "Downloading " + target}
+				appUpdater.Progress = bar.update
+				defer bar.done()
+			}
+			var lastUpdateCheck, _ = strconv.ParseInt(collection.MapGetValueOrDefault(propConfig.Properties, "last-update-check", strconv.Itoa(int(time.Now().Unix()))), 10, 64)
+			if time.Now().Unix() >= lastUpdateCheck+86400 && cihelper.IsCIEnvironment() == false {
+				if appUpdater.IsUpdateAvailable(cmd.Context(), cmd.Version) {
+					log.Warn().Msg("You are using a old version, please consider to update using `envcli self-update`!")
+				}
+			}
+
+			return appUpdater.Update(cmd.Context(), target, force, cmd.Version)
+		},
+	}
 	updateCmd.Flags().BoolP("force", "f", false, "A forced update would also redownload the current version.")
 	updateCmd.Flags().String("target", "latest", "A target version that should be upgraded/downgraded to.")
+	updateCmd.Flags().Bool("skip-checksum", false, "Install releases which publish no checksums.txt without verifying the download.")
+	updateCmd.Flags().Int("max-retries", 5, "Additional attempts of the download after network errors, each attempt resumes the download.")
+
+	return updateCmd
 }
 
-var updateCmd = &cobra.Command{
-	Use:     "self-update",
-	Aliases: []string{},
-	Run: func(cmd *cobra.Command, args []string) {
-		target, _ := cmd.Flags().GetString("target")
-		force, _ := cmd.Flags().GetBool("force")
-
-		// Update Check, once a day (not in CI)
-		appUpdater := updater.ApplicationUpdater{GitHubOrg: "EnvCLI", GitHubRepository: "EnvCLI"}
-		var lastUpdateCheck, _ = strconv.ParseInt(collection.MapGetValueOrDefault(propConfig.Properties, "last-update-check", strconv.Itoa(int(time.Now().Unix()))), 10, 64)
-		if time.Now().Unix() >= lastUpdateCheck+86400 && cihelper.IsCIEnvironment() == false {
-			if appUpdater.IsUpdateAvailable(cmd.Version) {
-				log.Warn().Msg("You are using a old version, please consider to update using `envcli self-update`!")
-			}
-		}
+// progressInterval limits how often the progress bar is redrawn
+const progressInterval = 100 * time.Millisecond
+
+// progressBar renders the progress of a download on a single terminal line
+type progressBar struct {
+	out     io.Writer
+	label   string
+	drawn   time.Time
+	visible bool
+}
+
+func (b *progressBar) update(progress download.Progress) {
+	complete := progress.Total > 0 && progress.Received >= progress.Total
+	if !complete && time.Since(b.drawn) < progressInterval {
+		return
+	}
+	b.drawn, b.visible = time.Now(), true
+	fmt.Fprintf(b.out, "\r\033[K%s", formatProgress(b.label, progress))
+}
+
+// done ends the line of the progress bar
+func (b *progressBar) done() {
+	if b.visible {
+		fmt.Fprintln(b.out)
+	}
+}
+
+// formatProgress renders the progress, ex. "Downloading v1.2.0  45% [=========>           ] 27.1 MiB / 60.0 MiB, ETA 12s"
+func formatProgress(label string, progress download.Progress) string {
+	if progress.Total <= 0 {
+		return fmt.Sprintf("%s %s", label, formatSize(progress.Received))
+	}
 
-		appUpdater.Update(target, force, cmd.Version)
-	},
+	const width = 20
+	percent := int(progress.Received * 100 / progress.Total)
+	filled := int(progress.Received * width / progress.Total)
+	bar := strings.Repeat("=", filled)
+	if filled < width {
+		bar += ">" + strings.Repeat(" ", width-filled-1)
+	}
+	result := fmt.Sprintf("%s %3d%% [%s] %s / %s", label, percent, bar, formatSize(progress.Received), formatSize(progress.Total))
+	if eta := progress.ETA(); eta > 0 {
+		result += ", ETA " + eta.Round(time.Second).String()
+	}
+	return result
 }
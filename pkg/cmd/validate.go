@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+// newValidateCmd creates the validate command
+func newValidateCmd() *cobra.Command {
+	validateCmd := &cobra.Command{
+		Use:     "validate",
+		Short:   "validates the configuration and checks the configured lint rules",
+		Aliases: []string{},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+			validateSchema, _ := cmd.Flags().GetBool("schema")
+			printReference, _ := cmd.Flags().GetBool("print-reference")
+			explain, _ := cmd.Flags().GetString("explain")
+
+			// feature: documentation of the configuration fields, generated from the config structs
+			if printReference {
+				schema.WriteReference(cmd.OutOrStdout())
+				return nil
+			} else if explain != "" {
+				field, explainErr := schema.Explain(explain)
+				if explainErr != nil {
+					return exitcode.New(exitcode.ConfigError, explainErr)
+				}
+				schema.WriteExplanation(cmd.OutOrStdout(), field)
+				return nil
+			}
+
+			cfg, err := config.LoadMergedConfiguration(cmd.Context(), configIncludes)
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", exitcode.New(exitcode.ConfigError, err))
+			}
+
+			violations := config.ValidateConfiguration(cfg)
+			if validateSchema {
+				schemaViolations, schemaErr := validateConfigSchema(cfg.ConfigFiles)
+				if schemaErr != nil {
+					return exitcode.New(exitcode.ConfigError, schemaErr)
+				}
+				violations = append(schemaViolations, violations...)
+			}
+			for _, violation := range violations {
+				fmt.Fprintln(cmd.OutOrStdout(), violation.String())
+			}
+
+			// the violations have already been printed
+			if config.HasErrors(violations) {
+				return exitcode.NewSilent(exitcode.ConfigError, errors.New("configuration is invalid"))
+			}
+			if len(violations) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "Configuration is valid.")
+			}
+
+			return nil
+		},
+	}
+	validateCmd.Flags().Bool("schema", false, "Additionally validates the configuration files against the JSON schema (envcli schema), ex. to find unknown properties")
+	validateCmd.Flags().Bool("print-reference", false, "Prints a commented yaml document with all supported fields, their types and defaults instead of validating")
+	validateCmd.Flags().String("explain", "", "Prints the documentation of a single field instead of validating (ex. images[].cache)")
+	validateCmd.MarkFlagsMutuallyExclusive("print-reference", "explain")
+
+	return validateCmd
+}
+
+// validateConfigSchema validates the configuration files against the JSON schema
+func validateConfigSchema(files []string) ([]config.LintViolation, error) {
+	s := schema.Generate()
+
+	var violations []config.LintViolation
+	for _, file := range files {
+		messages, err := s.ValidateFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, message := range messages {
+			violations = append(violations, config.LintViolation{Rule: "schema", Severity: config.SeverityError, Entry: file, Message: message})
+		}
+	}
+	return violations, nil
+}
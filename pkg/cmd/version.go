@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"runtime"
 
 	"github.com/spf13/cobra"
@@ -20,20 +19,27 @@ var CommitHash string
 // BuildAt will be set at build time
 var BuildAt string
 
-func init() {
-	rootCmd.AddCommand(versionCmd)
+// displayVersion returns the version, dev for builds without version
+func displayVersion() string {
+	if Version == "" {
+		return "dev"
+	}
+	return Version
 }
 
-var versionCmd = &cobra.Command{
-	Use:   "version",
-	Short: "print version information",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Fprintf(os.Stdout, "GitVersion:    %s\n", Version)
-		fmt.Fprintf(os.Stdout, "GitCommit:     %s\n", CommitHash)
-		fmt.Fprintf(os.Stdout, "GitTreeState:  %s\n", RepositoryStatus)
-		fmt.Fprintf(os.Stdout, "BuildDate:     %s\n", BuildAt)
-		fmt.Fprintf(os.Stdout, "GoVersion:     %s\n", runtime.Version())
-		fmt.Fprintf(os.Stdout, "Compiler:      %s\n", runtime.Compiler)
-		fmt.Fprintf(os.Stdout, "Platform:      %s\n", runtime.GOOS+"/"+runtime.GOARCH)
-	},
+// newVersionCmd creates the version command
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "print version information",
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Fprintf(cmd.OutOrStdout(), "GitVersion:    %s\n", Version)
+			fmt.Fprintf(cmd.OutOrStdout(), "GitCommit:     %s\n", CommitHash)
+			fmt.Fprintf(cmd.OutOrStdout(), "GitTreeState:  %s\n", RepositoryStatus)
+			fmt.Fprintf(cmd.OutOrStdout(), "BuildDate:     %s\n", BuildAt)
+			fmt.Fprintf(cmd.OutOrStdout(), "GoVersion:     %s\n", runtime.Version())
+			fmt.Fprintf(cmd.OutOrStdout(), "Compiler:      %s\n", runtime.Compiler)
+			fmt.Fprintf(cmd.OutOrStdout(), "Platform:      %s\n", runtime.GOOS+"/"+runtime.GOARCH)
+		},
+	}
 }
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/envcli"
+	"github.com/EnvCLI/EnvCLI/pkg/output"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/spf13/cobra"
+)
+
+// whichResult describes how a command would be run
+type whichResult struct {
+	Command     string   `json:"command" yaml:"command" table:"Command"`
+	Entry       string   `json:"entry" yaml:"entry" table:"Entry"`
+	Scope       string   `json:"scope" yaml:"scope" table:"Scope"`
+	Image       string   `json:"image" yaml:"image" table:"Image"`
+	Mirror      string   `json:"mirror,omitempty" yaml:"mirror,omitempty" table:"Mirror,omitempty"`
+	Variant     string   `json:"variant,omitempty" yaml:"variant,omitempty" table:"Variant,omitempty"`
+	Match       string   `json:"match" yaml:"match"`
+	MatchLabel  string   `json:"-" yaml:"-" table:"Match"`
+	Args        []string `json:"args,omitempty" yaml:"args,omitempty"`
+	ArgPosition string   `json:"argPosition,omitempty" yaml:"argPosition,omitempty"`
+	ArgsLabel   string   `json:"-" yaml:"-" table:"Args,omitempty"`
+	Local       bool     `json:"local" yaml:"local"`
+	Path        string   `json:"path,omitempty" yaml:"path,omitempty"`
+	Reason      string   `json:"reason" yaml:"reason"`
+	RunsLabel   string   `json:"-" yaml:"-" table:"Runs"`
+	Env         []string `json:"env,omitempty" yaml:"env,omitempty" table:"Env,omitempty"`
+	Ulimits     []string `json:"ulimits,omitempty" yaml:"ulimits,omitempty" table:"Ulimits,omitempty"`
+	SecurityOpt []string `json:"securityOpt,omitempty" yaml:"securityOpt,omitempty" table:"Security,omitempty"`
+}
+
+// newWhichCmd creates the which command
+func newWhichCmd(detectRuntime func() containerutil.ContainerRuntime) *cobra.Command {
+	whichCmd := &cobra.Command{
+		Use:     "which",
+		Short:   "shows which image will be used to run the specified command",
+		Aliases: []string{},
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configIncludes, _ := cmd.Flags().GetStringArray("config-include")
+			variant, _ := cmd.Flags().GetString("variant")
+			commandName := args[0]
+
+			commandConfig, matchType, err := config.GetCommandVariantMatch(cmd.Context(), commandName, variant, filesystem.GetWorkingDirectory(), configIncludes)
+			if err != nil {
+				return fmt.Errorf("failed to load command config: %w", err)
+			}
+
+			result := whichResult{Command: commandName, Entry: commandConfig.Name, Scope: commandConfig.Scope, Image: commandConfig.Image, Variant: commandConfig.Variant, Match: matchType}
+			if matchType == config.MatchByName {
+				result.MatchLabel = matchType + " (no image provides the command, the image default command will be used)"
+			} else if matchType == config.MatchByFallback {
+				result.MatchLabel = matchType + " (no configuration for the command, the fallback-image property is used - the result may differ from a properly configured entry)"
+			} else {
+				result.MatchLabel = matchType
+			}
+
+			execution, err := envcli.ResolveExecution(commandConfig, matchType, commandName, func() bool {
+				return containerutil.RequireRuntime(detectRuntime()) == nil
+			})
+			if err != nil {
+				return err
+			}
+			if defaultArgs := config.GetDefaultArgs(commandConfig, commandName); len(defaultArgs) > 0 {
+				result.Args = defaultArgs
+				result.ArgPosition = commandConfig.ArgPosition
+				if result.ArgPosition == "" {
+					result.ArgPosition = config.ArgPositionAppend
+				}
+				result.ArgsLabel = fmt.Sprintf("%s (%s)", common.ParseAndEscapeArgs(defaultArgs), result.ArgPosition)
+			}
+			result.Local = execution.Local
+			result.Reason = execution.Reason
+			if execution.Local {
+				result.Path = execution.Path
+				result.RunsLabel = fmt.Sprintf("local %s (%s)", execution.Path, execution.Reason)
+			} else {
+				result.RunsLabel = fmt.Sprintf("container (%s)", execution.Reason)
+				mirroredImage, mirrorErr := envcli.ResolveImageMirror(commandConfig, propConfig.Properties)
+				if mirrorErr != nil {
+					return mirrorErr
+				}
+				if mirroredImage != commandConfig.Image {
+					result.Mirror = mirroredImage
+				}
+				result.Ulimits, _ = config.GetUlimits(commandConfig)
+				result.SecurityOpt = commandConfig.SecurityOpt
+				for _, env := range commandConfig.Env {
+					name, _, _ := strings.Cut(env, "=")
+					result.Env = append(result.Env, name)
+				}
+			}
+
+			// feature: copy the rendered run command or a snippet for issues
+			copyCommand, _ := cmd.Flags().GetBool("copy")
+			share, _ := cmd.Flags().GetBool("share")
+			if !copyCommand && !share {
+				return output.Render(cmd.OutOrStdout(), outputFormat(), result)
+			}
+			runtime := detectRuntime()
+			runCommand, renderErr := renderDryRun(cmd, runtime, configIncludes, variant, commandName)
+			if share {
+				snippet := shareSnippet(result, runtime, runCommand, renderErr)
+				if copyCommand {
+					copyOrPrint(cmd, snippet, "snippet")
+				} else {
+					fmt.Fprintln(cmd.OutOrStdout(), snippet)
+				}
+				return nil
+			}
+			if renderErr != nil {
+				return renderErr
+			}
+			copyOrPrint(cmd, runCommand, "command")
+			return nil
+		},
+	}
+	whichCmd.Flags().String("variant", "", "Shows the entry with the variant applied, defaults to the "+config.VariantEnv+" environment variable")
+	whichCmd.Flags().Bool("copy", false, "Copies the rendered run command (or the --share snippet) to the clipboard, it is printed if there is no clipboard")
+	whichCmd.Flags().Bool("share", false, "Prints a markdown code block with the envcli version, the matched entry and the rendered run command, for pasting into issues")
+
+	return whichCmd
+}
+
+// renderDryRun returns the run command envcli run would execute for the command without arguments
+func renderDryRun(cmd *cobra.Command, runtime containerutil.ContainerRuntime, configIncludes []string, variant string, commandName string) (string, error) {
+	var rendered strings.Builder
+	runner := envcli.NewRunner(envcli.Options{ConfigIncludes: configIncludes, Properties: &propConfig, Runtime: runtime, Variant: variant, DryRun: true, Stdout: &rendered, Stderr: cmd.ErrOrStderr()})
+	if _, err := runner.Run(cmd.Context(), commandName, nil); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(rendered.String()), nil
+}
+
+// shareSnippet renders the entry and the run command as markdown code block, the error is included if the command couldn't be rendered
+func shareSnippet(result whichResult, runtime containerutil.ContainerRuntime, runCommand string, renderErr error) string {
+	lines := []string{
+		"```text",
+		fmt.Sprintf("envcli %s (%s/%s, %s)", displayVersion(), goruntime.GOOS, goruntime.GOARCH, runtime.Name()),
+		fmt.Sprintf("command: %s", result.Command),
+		fmt.Sprintf("entry:   %s (scope %s, match %s)", result.Entry, result.Scope, result.Match),
+		fmt.Sprintf("image:   %s", result.Image),
+	}
+	if result.Mirror != "" {
+		lines = append(lines, fmt.Sprintf("mirror:  %s", result.Mirror))
+	}
+	if result.Variant != "" {
+		lines = append(lines, fmt.Sprintf("variant: %s", result.Variant))
+	}
+	lines = append(lines, fmt.Sprintf("runs:    %s", result.RunsLabel))
+	if renderErr != nil {
+		lines = append(lines, "", "# the run command couldn't be rendered: "+renderErr.Error())
+	} else {
+		lines = append(lines, "", "$ "+runCommand)
+	}
+	return strings.Join(append(lines, "```"), "\n")
+}
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -11,11 +12,60 @@ func TestParseAndEscapeArgs(t *testing.T) {
 	testArgs = append(testArgs, "-ldflags=-w -X main.Example=common")
 
 	AssertStringEquals(t, ParseAndEscapeArgs(testArgs), "\"go\" \"build\" \"-ldflags=-w -X main.Example=common\"")
+	AssertStringEquals(t, ParseAndEscapeArgs([]string{"npm", "install", "--save-dev", "--", "left-pad"}), "\"npm\" \"install\" \"--save-dev\" \"--\" \"left-pad\"")
 
 }
 
+func TestSplitArgs(t *testing.T) {
+	args := SplitArgs(`go build -ldflags="-w -X main.Example=common" 'single quoted' ""`)
+
+	AssertStringEquals(t, strings.Join(args, "|"), "go|build|-ldflags=-w -X main.Example=common|single quoted|")
+}
+
+func TestWrapText(t *testing.T) {
+	lines := WrapText("Go is a general purpose programming language.\nSecond paragraph", 20)
+
+	AssertStringEquals(t, strings.Join(lines, "|"), "Go is a general|purpose programming|language.|Second paragraph")
+}
+
+func TestParseAge(t *testing.T) {
+	age, err := ParseAge("30d")
+	AssertStringEquals(t, age.String(), "720h0m0s")
+	if err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	age, _ = ParseAge("90m")
+	AssertStringEquals(t, age.String(), "1h30m0s")
+
+	if _, err = ParseAge("xd"); err == nil {
+		t.Errorf("expected an error for an invalid age")
+	}
+}
+
 func AssertStringEquals(t *testing.T, value string, expected string) {
 	if value != expected {
 		t.Errorf("Failed to correctly parse the provided arguments! Expected: " + expected + ", got " + value)
 	}
 }
+
+func TestLevenshteinDistance(t *testing.T) {
+	var tests = []struct {
+		a        string
+		b        string
+		distance int
+	}{
+		{"", "", 0},
+		{"http-proxy", "http-proxy", 0},
+		{"http-prxy", "http-proxy", 1},
+		{"hhtp-proxyy", "http-proxy", 2},
+		{"", "cache", 5},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, test := range tests {
+		if distance := LevenshteinDistance(test.a, test.b); distance != test.distance {
+			t.Errorf("%s -> %s: expected distance %d, got %d", test.a, test.b, test.distance, distance)
+		}
+	}
+}
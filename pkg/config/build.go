@@ -0,0 +1,80 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// BuildImageRepository is the repository prefix of the images built from the dockerfile of a entry
+const BuildImageRepository = "envcli-build/"
+
+// invalidImageNameChars matches all characters that are not allowed within a image repository name
+var invalidImageNameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// IsBuild returns true if the image of the entry is built from a dockerfile
+func (e RunConfigurationEntry) IsBuild() bool {
+	return e.Build.Dockerfile != ""
+}
+
+// GetBuildPaths returns the dockerfile and the build context of the entry, relative paths are resolved against the directory of the configuration file
+func GetBuildPaths(entry RunConfigurationEntry) (string, string) {
+	baseDir := filepath.Dir(entry.Source)
+
+	dockerfile := entry.Build.Dockerfile
+	if !filepath.IsAbs(dockerfile) {
+		dockerfile = filepath.Join(baseDir, dockerfile)
+	}
+	context := entry.Build.Context
+	if context == "" {
+		context = baseDir
+	} else if !filepath.IsAbs(context) {
+		context = filepath.Join(baseDir, context)
+	}
+
+	return dockerfile, context
+}
+
+// GetBuildImageName returns the name of the image built for the entry: envcli-build/<project>-<entry>:<dockerfile hash>
+// The name only changes if the dockerfile is modified, which triggers a rebuild.
+func GetBuildImageName(entry RunConfigurationEntry) (string, error) {
+	dockerfile, _ := GetBuildPaths(entry)
+	content, err := os.ReadFile(dockerfile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the dockerfile of entry %s: %w", entry.Name, err)
+	}
+	hash := sha256.Sum256(content)
+
+	project := filepath.Base(filepath.Dir(dockerfile))
+	if entry.Source != "" {
+		project = filepath.Base(filepath.Dir(entry.Source))
+	}
+	repository := strings.Trim(invalidImageNameChars.ReplaceAllString(strings.ToLower(project+"-"+entry.Name), "-"), "-._")
+
+	return BuildImageRepository + repository + ":" + hex.EncodeToString(hash[:])[:12], nil
+}
+
+// ResolveBuildImages sets the image of all build entries to the name of their built image
+func ResolveBuildImages(images []RunConfigurationEntry) []RunConfigurationEntry {
+	for i, entry := range images {
+		if !entry.IsBuild() {
+			continue
+		}
+
+		image, err := GetBuildImageName(entry)
+		if err != nil {
+			log.Warn().Err(err).Str("entry", entry.Name).Msg("can't determine the image of the build entry")
+			images[i].Image = ""
+			continue
+		}
+		images[i].Image = image
+	}
+
+	return images
+}
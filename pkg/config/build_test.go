@@ -0,0 +1,57 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetBuildImageName(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "My Project")
+	dockerfile := filepath.Join(dir, ".envcli", "Dockerfile.node")
+	if err := os.MkdirAll(filepath.Dir(dockerfile), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dockerfile, []byte("FROM node:20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entry := RunConfigurationEntry{Name: "Node", Build: BuildConfiguration{Dockerfile: ".envcli/Dockerfile.node"}, Source: filepath.Join(dir, ".envcli.yml")}
+
+	image, err := GetBuildImageName(entry)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.HasPrefix(image, "envcli-build/my-project-node:") || len(image) != len("envcli-build/my-project-node:")+12 {
+		t.Errorf("unexpected image name %s", image)
+	}
+	if again, _ := GetBuildImageName(entry); again != image {
+		t.Errorf("expected a deterministic name, got %s and %s", image, again)
+	}
+
+	// modified dockerfile
+	if err = os.WriteFile(dockerfile, []byte("FROM node:20\nRUN apt-get install -y jq\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if modified, _ := GetBuildImageName(entry); modified == image {
+		t.Errorf("expected the name to change with the dockerfile")
+	}
+
+	// paths
+	if file, context := GetBuildPaths(entry); file != dockerfile || context != dir {
+		t.Errorf("unexpected build paths %s, %s", file, context)
+	}
+	entry.Build.Context = ".envcli"
+	if _, context := GetBuildPaths(entry); context != filepath.Join(dir, ".envcli") {
+		t.Errorf("unexpected build context %s", context)
+	}
+
+	// missing dockerfile
+	entry.Build.Dockerfile = "missing"
+	if _, err = GetBuildImageName(entry); err == nil {
+		t.Errorf("expected an error for a missing dockerfile")
+	}
+	if images := ResolveBuildImages([]RunConfigurationEntry{entry}); images[0].Image != "" {
+		t.Errorf("expected no image for a missing dockerfile, got %s", images[0].Image)
+	}
+}
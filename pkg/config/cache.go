@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/rs/zerolog/log"
+)
+
+// CachePathStatus holds the result of the cache path validation
+type CachePathStatus struct {
+	// Configured is the value of the cache-path property
+	Configured string
+
+	// Path is the usable cache path, empty if caching is disabled
+	Path string
+
+	// Fallback is true if the configured path is unusable and a temporary directory is used instead
+	Fallback bool
+
+	// Problem holds the reason why the configured path can't be used
+	Problem error
+}
+
+var cachePathStatus = make(map[string]CachePathStatus)
+var cachePathMutex sync.Mutex
+
+// cacheFallbackDirectory is used if the configured cache path is unusable
+var cacheFallbackDirectory = filepath.Join(os.TempDir(), "envcli-cache")
+
+// GetCachePath returns the validated cache path, see ResolveCachePath
+func GetCachePath(propConfig PropertyConfigurationFile) CachePathStatus {
+	return ResolveCachePath(collection.MapGetValueOrDefault(propConfig.Properties, "cache-path", ""))
+}
+
+// ResolveCachePath validates the cache path at first use, creates it if missing and falls back to a temporary directory if it is unusable
+func ResolveCachePath(configured string) CachePathStatus {
+	cachePathMutex.Lock()
+	defer cachePathMutex.Unlock()
+
+	if status, ok := cachePathStatus[configured]; ok {
+		return status
+	}
+
+	status := CachePathStatus{Configured: configured, Path: configured}
+	if configured != "" {
+		if err := EnsureWritableDirectory(configured); err != nil {
+			status.Problem = err
+			status.Fallback = true
+			status.Path = cacheFallbackDirectory
+			log.Warn().Err(err).Str("cache-path", configured).Str("fallback", cacheFallbackDirectory).Msg("cache path is not usable, using a temporary directory instead")
+
+			if fallbackErr := EnsureWritableDirectory(cacheFallbackDirectory); fallbackErr != nil {
+				log.Warn().Err(fallbackErr).Str("fallback", cacheFallbackDirectory).Msg("fallback cache path is not usable, caching is disabled")
+				status.Path = ""
+			}
+		}
+	}
+
+	cachePathStatus[configured] = status
+	return status
+}
+
+// EnsureWritableDirectory creates the directory if missing and checks that files can be written into it
+func EnsureWritableDirectory(directory string) error {
+	if err := os.MkdirAll(directory, os.ModePerm); err != nil {
+		return err
+	}
+
+	probe, err := os.CreateTemp(directory, ".envcli-probe-*")
+	if err != nil {
+		return err
+	}
+	probe.Close()
+
+	return os.Remove(probe.Name())
+}
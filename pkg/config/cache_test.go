@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveCachePathCreatesMissingDirectory(t *testing.T) {
+	directory := filepath.Join(t.TempDir(), "missing", "cache")
+
+	status := ResolveCachePath(directory)
+	if status.Fallback || status.Path != directory {
+		t.Errorf("expected configured path to be used, got %+v", status)
+	}
+	if _, err := os.Stat(directory); err != nil {
+		t.Errorf("expected cache path to be created: %v", err)
+	}
+}
+
+func TestResolveCachePathNonexistentParent(t *testing.T) {
+	// a path below a regular file can never be created
+	file := filepath.Join(t.TempDir(), "file")
+	_ = os.WriteFile(file, []byte("x"), 0600)
+	cacheFallbackDirectory = filepath.Join(t.TempDir(), "fallback")
+
+	status := ResolveCachePath(filepath.Join(file, "cache"))
+	if !status.Fallback || status.Problem == nil || status.Path != cacheFallbackDirectory {
+		t.Errorf("expected fallback to the temporary directory, got %+v", status)
+	}
+}
+
+func TestResolveCachePathReadOnly(t *testing.T) {
+	directory := filepath.Join(t.TempDir(), "readonly")
+	_ = os.MkdirAll(directory, 0500)
+	if err := EnsureWritableDirectory(directory); err == nil {
+		t.Skip("directory permissions are not enforced for the current user")
+	}
+	cacheFallbackDirectory = filepath.Join(t.TempDir(), "fallback")
+
+	status := ResolveCachePath(directory)
+	if !status.Fallback || status.Path != cacheFallbackDirectory {
+		t.Errorf("expected fallback to the temporary directory, got %+v", status)
+	}
+}
+
+func TestResolveCachePathDisabled(t *testing.T) {
+	status := ResolveCachePath("")
+	if status.Path != "" || status.Fallback {
+		t.Errorf("expected caching to be disabled, got %+v", status)
+	}
+}
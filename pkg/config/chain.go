@@ -0,0 +1,60 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// GetProjectConfigChain returns the project config file followed by all configs it extends or inherits, nearest first
+func GetProjectConfigChain(projectDirectory string, configFile string, filenames []string) ([]string, error) {
+	var chain []string
+	visited := make(map[string]bool)
+
+	for configFile != "" {
+		absFile, err := filepath.Abs(configFile)
+		if err != nil {
+			return chain, err
+		}
+		if visited[absFile] {
+			return chain, errors.New("cycle detected in project configuration chain: " + strings.Join(append(chain, absFile), " -> "))
+		}
+		visited[absFile] = true
+		chain = append(chain, absFile)
+
+		cfg, err := LoadProjectConfig(absFile)
+		if err != nil {
+			return chain, err
+		}
+
+		// explicit parent
+		if cfg.Extends != "" {
+			configFile = filepath.Join(filepath.Dir(absFile), filepath.FromSlash(cfg.Extends))
+			projectDirectory = filepath.Dir(configFile)
+			log.Debug().Str("file", absFile).Str("extends", configFile).Msg("project config extends another config")
+			continue
+		}
+
+		// parent directories
+		configFile = ""
+		if cfg.InheritParentConfigs {
+			parentDirectory := filepath.Dir(projectDirectory)
+			if parentDirectory == projectDirectory {
+				break
+			}
+
+			parentProjectDirectory, parentConfigFile, findErr := FindProjectConfig(parentDirectory, filenames)
+			if findErr != nil {
+				log.Debug().Str("file", absFile).Msg("inheritParentConfigs is set, but no config was found in the parent directories")
+				break
+			}
+			log.Debug().Str("file", absFile).Str("parent", parentConfigFile).Msg("project config inherits parent config")
+			configFile = parentConfigFile
+			projectDirectory = parentProjectDirectory
+		}
+	}
+
+	return chain, nil
+}
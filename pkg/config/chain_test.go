@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, file string, content string) {
+	_ = os.MkdirAll(filepath.Dir(file), os.ModePerm)
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", file, err)
+	}
+}
+
+func TestGetProjectConfigChainThreeLevels(t *testing.T) {
+	root := t.TempDir()
+	rootConfig := filepath.Join(root, ".envcli.yml")
+	servicesConfig := filepath.Join(root, "services", ".envcli.yml")
+	apiConfig := filepath.Join(root, "services", "api", ".envcli.yml")
+
+	writeConfig(t, rootConfig, "images:\n- name: root\n  provides: [kubectl]\n  image: kubectl:1\n")
+	writeConfig(t, servicesConfig, "inheritParentConfigs: true\nimages:\n- name: services\n  provides: [helm]\n  image: helm:3\n")
+	writeConfig(t, apiConfig, "extends: ../.envcli.yml\nimages:\n- name: api\n  provides: [kubectl]\n  image: kubectl:2\n")
+
+	chain, err := GetProjectConfigChain(filepath.Join(root, "services", "api"), apiConfig, defaultProjectConfigFilenames)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{apiConfig, servicesConfig, rootConfig}
+	if strings.Join(chain, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected chain %v, got %v", expected, chain)
+	}
+}
+
+func TestGetProjectConfigChainWithoutInheritance(t *testing.T) {
+	root := t.TempDir()
+	writeConfig(t, filepath.Join(root, ".envcli.yml"), "images: []\n")
+	serviceConfig := filepath.Join(root, "service", ".envcli.yml")
+	writeConfig(t, serviceConfig, "images: []\n")
+
+	chain, err := GetProjectConfigChain(filepath.Join(root, "service"), serviceConfig, defaultProjectConfigFilenames)
+	if err != nil || len(chain) != 1 {
+		t.Errorf("expected only the nearest config, got %v %v", chain, err)
+	}
+}
+
+func TestGetProjectConfigChainCycle(t *testing.T) {
+	root := t.TempDir()
+	a := filepath.Join(root, "a", ".envcli.yml")
+	b := filepath.Join(root, "b", ".envcli.yml")
+	writeConfig(t, a, "extends: ../b/.envcli.yml\n")
+	writeConfig(t, b, "extends: ../a/.envcli.yml\n")
+
+	_, err := GetProjectConfigChain(filepath.Join(root, "a"), a, defaultProjectConfigFilenames)
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected cycle error, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/atomicfile"
+)
+
+// configWithImages returns a configuration with count entries, large enough to be written in multiple chunks
+func configWithImages(count int) []byte {
+	var b strings.Builder
+	b.WriteString("images:\n")
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(&b, "  - name: tool%d\n    image: docker.io/library/tool%d:latest\n    description: %s\n    provides:\n      - tool%d\n", i, i, strings.Repeat("x", 200), i)
+	}
+	return []byte(b.String())
+}
+
+func TestLoadProjectConfigConcurrentWrites(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".envcli.yml")
+	versions := [][]byte{configWithImages(10), configWithImages(200)}
+	if err := atomicfile.WriteFile(file, versions[0], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	var writers sync.WaitGroup
+	for w := 0; w < 2; w++ {
+		writers.Add(1)
+		go func(w int) {
+			defer writers.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err := atomicfile.WriteFile(file, versions[(i+w)%2], 0644); err != nil {
+					t.Errorf("unexpected write error %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+
+	var readers sync.WaitGroup
+	deadline := time.Now().Add(300 * time.Millisecond)
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for time.Now().Before(deadline) {
+				cfg, err := LoadProjectConfig(file)
+				if err != nil {
+					t.Errorf("torn read: %v", err)
+					return
+				}
+				if count := len(cfg.Images); count != 10 && count != 200 {
+					t.Errorf("torn read: expected 10 or 200 entries, got %d", count)
+					return
+				}
+			}
+		}()
+	}
+
+	readers.Wait()
+	close(stop)
+	writers.Wait()
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// condition is a parsed when expression, the lookup function resolves the variables os, arch and env.<NAME>
+type condition func(lookup func(name string) string) bool
+
+// conditionToken is a single token of a when expression
+type conditionToken struct {
+	kind  string // ident, string or operator
+	value string
+}
+
+// conditionParser is a recursive descent parser for when expressions
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+}
+
+// ParseCondition parses a when expression, ex. os == "windows" && (arch == "arm64" || env.CI != "")
+func ParseCondition(expression string) (condition, error) {
+	tokens, err := tokenizeCondition(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &conditionParser{tokens: tokens}
+	result, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, errors.New("unexpected " + p.tokens[p.pos].value + " in condition " + expression)
+	}
+
+	return result, nil
+}
+
+// EvaluateCondition evaluates the when expression against the current os, arch and environment, an empty expression is always true
+func EvaluateCondition(expression string) (bool, error) {
+	if strings.TrimSpace(expression) == "" {
+		return true, nil
+	}
+
+	cond, err := ParseCondition(expression)
+	if err != nil {
+		return false, err
+	}
+
+	return cond(conditionVariable), nil
+}
+
+// conditionVariable resolves the variables available in when expressions
+func conditionVariable(name string) string {
+	switch name {
+	case "os":
+		return runtime.GOOS
+	case "arch":
+		return runtime.GOARCH
+	}
+	return os.Getenv(strings.TrimPrefix(name, "env."))
+}
+
+func tokenizeCondition(expression string) ([]conditionToken, error) {
+	var tokens []conditionToken
+
+	for i := 0; i < len(expression); {
+		c := expression[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(expression[i+1:], c)
+			if end < 0 {
+				return nil, errors.New("unterminated string in condition " + expression)
+			}
+			tokens = append(tokens, conditionToken{kind: "string", value: expression[i+1 : i+1+end]})
+			i += end + 2
+		case c == '(' || c == ')':
+			tokens = append(tokens, conditionToken{kind: "operator", value: string(c)})
+			i++
+		case strings.HasPrefix(expression[i:], "==") || strings.HasPrefix(expression[i:], "!=") || strings.HasPrefix(expression[i:], "&&") || strings.HasPrefix(expression[i:], "||"):
+			tokens = append(tokens, conditionToken{kind: "operator", value: expression[i : i+2]})
+			i += 2
+		case c == '!':
+			tokens = append(tokens, conditionToken{kind: "operator", value: "!"})
+			i++
+		case isConditionIdentChar(c):
+			start := i
+			for i < len(expression) && isConditionIdentChar(expression[i]) {
+				i++
+			}
+			name := expression[start:i]
+			if name != "os" && name != "arch" && (!strings.HasPrefix(name, "env.") || len(name) == len("env.")) {
+				return nil, errors.New("unknown variable " + name + " in condition " + expression + ", allowed: os, arch, env.<NAME>")
+			}
+			tokens = append(tokens, conditionToken{kind: "ident", value: name})
+		default:
+			return nil, errors.New("unexpected character " + string(c) + " in condition " + expression)
+		}
+	}
+
+	return tokens, nil
+}
+
+func isConditionIdentChar(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *conditionParser) peek(value string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == "operator" && p.tokens[p.pos].value == value
+}
+
+func (p *conditionParser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek("||") {
+		p.pos++
+		right, rightErr := p.parseAnd()
+		if rightErr != nil {
+			return nil, rightErr
+		}
+		l := left
+		left = func(lookup func(string) string) bool { return l(lookup) || right(lookup) }
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (condition, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek("&&") {
+		p.pos++
+		right, rightErr := p.parseUnary()
+		if rightErr != nil {
+			return nil, rightErr
+		}
+		l := left
+		left = func(lookup func(string) string) bool { return l(lookup) && right(lookup) }
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseUnary() (condition, error) {
+	if p.peek("!") {
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(lookup func(string) string) bool { return !inner(lookup) }, nil
+	}
+	if p.peek("(") {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(")") {
+			return nil, errors.New("missing closing parenthesis in condition")
+		}
+		p.pos++
+		return inner, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek("==") || p.peek("!=") {
+		negate := p.tokens[p.pos].value == "!="
+		p.pos++
+		right, rightErr := p.parseOperand()
+		if rightErr != nil {
+			return nil, rightErr
+		}
+		return func(lookup func(string) string) bool { return (left(lookup) == right(lookup)) != negate }, nil
+	}
+
+	// a single operand is true if it isn't empty
+	return func(lookup func(string) string) bool { return left(lookup) != "" }, nil
+}
+
+func (p *conditionParser) parseOperand() (func(lookup func(string) string) string, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.New("unexpected end of condition")
+	}
+
+	token := p.tokens[p.pos]
+	p.pos++
+	switch token.kind {
+	case "string":
+		return func(lookup func(string) string) string { return token.value }, nil
+	case "ident":
+		return func(lookup func(string) string) string { return lookup(token.value) }, nil
+	}
+	return nil, errors.New("unexpected " + token.value + " in condition")
+}
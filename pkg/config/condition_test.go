@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestParseCondition(t *testing.T) {
+	variables := map[string]string{"os": "linux", "arch": "arm64", "env.CI": "true"}
+	lookup := func(name string) string { return variables[name] }
+
+	var tests = []struct {
+		expression string
+		expected   bool
+	}{
+		{`os == "windows"`, false},
+		{`os == "linux"`, true},
+		{`os != 'windows'`, true},
+		{`arch == "arm64" && os == "linux"`, true},
+		{`arch == "amd64" || env.CI == "true"`, true},
+		{`!(os == "linux")`, false},
+		{`env.CI`, true},
+		{`env.MISSING`, false},
+		{`!env.MISSING && (os == "darwin" || arch == "arm64")`, true},
+	}
+
+	for _, test := range tests {
+		cond, err := ParseCondition(test.expression)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.expression, err)
+			continue
+		}
+		if cond(lookup) != test.expected {
+			t.Errorf("%s: expected %v", test.expression, test.expected)
+		}
+	}
+}
+
+func TestParseConditionSyntaxErrors(t *testing.T) {
+	for _, expression := range []string{`os ==`, `os = "linux"`, `(os == "linux"`, `os == "linux`, `platform == "linux"`, `env. == "x"`, `os == "linux" "x"`, `&& os`} {
+		if _, err := ParseCondition(expression); err == nil {
+			t.Errorf("%s: expected a syntax error", expression)
+		}
+	}
+}
@@ -1,11 +1,17 @@
 package config
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"github.com/EnvCLI/EnvCLI/pkg/atomicfile"
+	"github.com/EnvCLI/EnvCLI/pkg/encryption"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
 	"github.com/cidverse/cidverseutils/pkg/collection"
 	"github.com/cidverse/cidverseutils/pkg/filesystem"
 	"github.com/jinzhu/configor"
@@ -15,41 +21,104 @@ import (
 )
 
 // Configuration
-var defaultConfigurationDirectory = filesystem.GetExecutionDirectory()
-var defaultConfigurationFile = ".envclirc"
+var defaultConfigurationDirectory = UserConfigurationDirectory()
+var defaultConfigurationFile = "config.yml"
 
-// Constants
-var validConfigurationOptions = []string{"http-proxy", "https-proxy", "global-configuration-path", "cache-path", "last-update-check"}
+// SetConfigurationDirectory changes the directory of the property file and the global configuration, defaults to the user config directory
+func SetConfigurationDirectory(directory string) {
+	defaultConfigurationDirectory = directory
+}
+
+// defaultProjectConfigFilenames are the candidate filenames of the project config, relative to the project directory
+var defaultProjectConfigFilenames = []string{".envcli.yml", ".envcli.yaml", "envcli.yml", ".config/envcli/config.yml"}
+
+// maxParentDirectoryDepth limits the number of parent directories searched for the project config
+const maxParentDirectoryDepth = 256
+
+// MatchByProvides is used for commands that are listed in the provides section of a image
+const MatchByProvides = "provides"
+
+// MatchByName is used for commands that matched the name of a image, the image default command will be used
+const MatchByName = "name"
+
+// MatchByFallback is used for commands that aren't configured at all and run in the image of the fallback-image property
+const MatchByFallback = "fallback"
 
 // LoadProjectConfig loads the project configuration
 func LoadProjectConfig(configFile string) (ConfigurationFile, error) {
 	log.Debug().Msg("Loading project configuration file " + configFile)
+
+	// a file that is replaced while it is read (ex. by a editor saving it in place) is read again once
+	before, statErr := os.Stat(configFile)
+	cfg, err := loadProjectConfig(configFile)
+	if err != nil && statErr == nil {
+		if after, afterErr := os.Stat(configFile); afterErr == nil && (!after.ModTime().Equal(before.ModTime()) || after.Size() != before.Size()) {
+			log.Debug().Err(err).Str("file", configFile).Msg("configuration file changed while it was read, reading it again")
+			return loadProjectConfig(configFile)
+		}
+	}
+	return cfg, err
+}
+
+// loadProjectConfig reads, decrypts and parses the project configuration file, unchanged files are loaded from the cache
+func loadProjectConfig(configFile string) (ConfigurationFile, error) {
 	var cfg ConfigurationFile
 
-	file, err := os.Open(configFile)
+	// feature: cache of the parsed configuration files
+	info, statErr := os.Stat(configFile)
+	if statErr == nil {
+		if cached, found := loadCachedConfig(configFile, info); found {
+			return cached, nil
+		}
+	}
+
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return ConfigurationFile{}, err
+	}
+
+	// feature: encrypted config files, they are never cached
+	encrypted := encryption.IsEncrypted(content)
+	ok := false
+	if IsEmbeddedConfig(configFile) {
+		// feature: project config embedded in pyproject.toml or package.json
+		encrypted = false
+		content, ok, err = extractEmbeddedConfig(configFile, content)
+	} else {
+		content, ok, err = decryptConfig(configFile, content)
+	}
 	if err != nil {
 		return ConfigurationFile{}, err
+	} else if !ok {
+		return ConfigurationFile{}, nil
 	}
-	defer file.Close()
 
-	decoder := yaml.NewDecoder(file)
+	// feature: deprecated fields are migrated before the configuration is parsed
+	content, deprecations := MigrateConfig(content)
+	reportDeprecations(configFile, deprecations)
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
 	err = decoder.Decode(&cfg)
 	if err != nil {
 		return ConfigurationFile{}, err
 	}
 
+	// the file is only cached if it didn't change while it was read
+	if after, afterErr := os.Stat(configFile); statErr == nil && afterErr == nil && !encrypted && after.ModTime().Equal(info.ModTime()) && after.Size() == info.Size() {
+		storeCachedConfig(configFile, info, cfg, deprecations)
+	}
 	return cfg, nil
 }
 
 // LoadPropertyConfig loads the property data
 func LoadPropertyConfig() (PropertyConfigurationFile, error) {
-	propConfigFile := defaultConfigurationDirectory + "/" + defaultConfigurationFile
+	propConfigFile := propertyConfigFileForReading()
 
 	if _, err := os.Stat(propConfigFile); err == nil {
-		return LoadPropertyConfigFile(defaultConfigurationDirectory + "/" + defaultConfigurationFile)
+		return LoadPropertyConfigFile(propConfigFile)
 	}
 
-	return PropertyConfigurationFile{}, nil
+	return PropertyConfigurationFile{Properties: make(map[string]string)}, nil
 }
 
 // LoadPropertyConfigFile loads the property config file
@@ -63,9 +132,60 @@ func LoadPropertyConfigFile(configFile string) (PropertyConfigurationFile, error
 	return cfg, nil
 }
 
+// GetPropertyConfigFile returns the path of the property config file
+func GetPropertyConfigFile() string {
+	return filepath.Join(defaultConfigurationDirectory, defaultConfigurationFile)
+}
+
+// GetGlobalConfigurationFile returns the path of the global (user-scope) configuration file, the local copy of the global-configuration-repo if set
+func GetGlobalConfigurationFile(propConfig PropertyConfigurationFile) string {
+	if repo, configured := GetGlobalRepo(propConfig); configured {
+		return repo.ConfigFile()
+	}
+	return collection.MapGetValueOrDefault(propConfig.Properties, "global-configuration-path", defaultConfigurationDirectory) + "/.envcli.yml"
+}
+
+// GetHistoryFile returns the file of the command history (cache-path/history/history.jsonl or the user cache directory)
+func GetHistoryFile(propConfig PropertyConfigurationFile) string {
+	return filepath.Join(getCacheSubdirectory(propConfig, "history"), "history.jsonl")
+}
+
+// GetDownloadCacheDirectory returns the directory used to cache downloads (cache-path/downloads or the user cache directory)
+func GetDownloadCacheDirectory(propConfig PropertyConfigurationFile) string {
+	return getCacheSubdirectory(propConfig, "downloads")
+}
+
+// GetManifestCacheDirectory returns the directory used to cache image manifest details (cache-path/manifests or the user cache directory)
+func GetManifestCacheDirectory(propConfig PropertyConfigurationFile) string {
+	return getCacheSubdirectory(propConfig, "manifests")
+}
+
+// GetContainerCleanupMarker returns the file storing the time of the last automatic container cleanup (cache-path/cleanup or the user cache directory).
+// It is kept out of the property file, which the background cleanup would otherwise rewrite while the command changes it.
+func GetContainerCleanupMarker(propConfig PropertyConfigurationFile) string {
+	return filepath.Join(getCacheSubdirectory(propConfig, "cleanup"), "last-container-cleanup")
+}
+
+// GetServeDirectory returns the directory of the token files of envcli serve (cache-path/serve or the user cache directory)
+func GetServeDirectory(propConfig PropertyConfigurationFile) string {
+	return getCacheSubdirectory(propConfig, "serve")
+}
+
+func getCacheSubdirectory(propConfig PropertyConfigurationFile, name string) string {
+	cachePath := GetCachePath(propConfig).Path
+	if cachePath == "" {
+		cachePath = UserCacheDirectory()
+	}
+
+	return filepath.Join(cachePath, name)
+}
+
 // SavePropertyConfig saves the global config
 func SavePropertyConfig(cfg PropertyConfigurationFile) error {
-	return SavePropertyConfigFile(defaultConfigurationDirectory+"/"+defaultConfigurationFile, cfg)
+	if err := os.MkdirAll(defaultConfigurationDirectory, 0700); err != nil {
+		return err
+	}
+	return SavePropertyConfigFile(GetPropertyConfigFile(), cfg)
 }
 
 // SavePropertyConfigFile saves the property file
@@ -77,93 +197,173 @@ func SavePropertyConfigFile(configFile string, cfg PropertyConfigurationFile) er
 		return err
 	}
 
-	return os.WriteFile(configFile, fileContent, 0600)
+	return atomicfile.WriteFile(configFile, fileContent, 0600)
 }
 
-// SetPropertyConfigEntry sets a property in the property config
-func SetPropertyConfigEntry(varName string, varValue string) {
+// SetPropertyConfigEntry validates and sets a property in the property config, force skips the validation of the value
+func SetPropertyConfigEntry(varName string, varValue string, force bool) error {
+	option, found := GetPropertyOption(varName)
+	if !found {
+		return newUnknownPropertyError(varName)
+	}
+	if !force {
+		if err := option.Validate(varValue); err != nil {
+			return err
+		}
+	}
+
 	// Load Config
 	propConfig, _ := LoadPropertyConfig()
 
 	// Set value
-	isValidValue, _ := collection.InArray(varName, validConfigurationOptions)
-	if isValidValue {
-		propConfig.Properties[varName] = varValue
+	propConfig.Properties[varName] = varValue
 
-		// Save Config
-		SavePropertyConfig(propConfig)
-	}
+	// Save Config
+	return SavePropertyConfig(propConfig)
 }
 
 // GetPropertyConfigEntry gets a property from the property config
-func GetPropertyConfigEntry(varName string) string {
+func GetPropertyConfigEntry(varName string) (string, error) {
+	if _, found := GetPropertyOption(varName); !found {
+		return "", newUnknownPropertyError(varName)
+	}
+
 	// Load Config
 	propConfig, _ := LoadPropertyConfig()
 
 	// Get Value
-	isValidValue, _ := collection.InArray(varName, validConfigurationOptions)
-	if isValidValue {
-		return propConfig.Properties[varName]
-	}
-
-	return ""
+	return propConfig.Properties[varName], nil
 }
 
 // UnsetPropertyConfigEntry clears a property
-func UnsetPropertyConfigEntry(varName string) {
+func UnsetPropertyConfigEntry(varName string) error {
+	if _, found := GetPropertyOption(varName); !found {
+		return newUnknownPropertyError(varName)
+	}
+
 	// Load Config
 	propConfig, _ := LoadPropertyConfig()
 
-	// Get Value
-	isValidValue, _ := collection.InArray(varName, validConfigurationOptions)
-	if isValidValue {
-		propConfig.Properties[varName] = ""
+	// Clear Value
+	propConfig.Properties[varName] = ""
 
-		// Save Config
-		SavePropertyConfig(propConfig)
-	}
+	// Save Config
+	return SavePropertyConfig(propConfig)
 }
 
 // GetProjectOrWorkingDirectory returns either the project directory, if one can be found or the working directory
 func GetProjectOrWorkingDirectory() string {
-	var directory, err = GetProjectDirectory()
+	return GetProjectOrDirectory(filesystem.GetWorkingDirectory())
+}
+
+// GetProjectOrDirectory returns the project directory of the directory, if one can be found or the directory itself
+func GetProjectOrDirectory(directory string) string {
+	projectDirectory, _, err := FindProjectConfig(directory, GetProjectConfigFilenames())
 	if err != nil {
-		directory = filesystem.GetWorkingDirectory()
+		return directory
 	}
-	return directory
+	return projectDirectory
 }
 
 // GetProjectDirectory searches for the project root directory by looking for the envcli config
 func GetProjectDirectory() (string, error) {
+	directory, _, err := FindProjectConfig(filesystem.GetWorkingDirectory(), GetProjectConfigFilenames())
+	return directory, err
+}
+
+// GetProjectConfigFile searches for the project configuration file in the working directory and its parents
+func GetProjectConfigFile() (string, error) {
+	_, file, err := FindProjectConfig(filesystem.GetWorkingDirectory(), GetProjectConfigFilenames())
+	return file, err
+}
+
+// GetProjectConfigFilenames returns the candidate filenames of the project config, extended by the config-filenames property
+func GetProjectConfigFilenames() []string {
+	filenames := append([]string{}, defaultProjectConfigFilenames...)
+
+	propConfig, _ := LoadPropertyConfig()
+	for _, filename := range strings.Split(collection.MapGetValueOrDefault(propConfig.Properties, "config-filenames", ""), ",") {
+		filename = strings.TrimSpace(filename)
+		if filename != "" {
+			filenames = append(filenames, filename)
+		}
+	}
+
+	return filenames
+}
+
+// FindConfigInDirectory returns the config file within the directory, an empty string if there is none or an error if multiple candidates exist.
+// A dedicated config file takes precedence over a config embedded in pyproject.toml or package.json.
+func FindConfigInDirectory(directory string, filenames []string) (string, error) {
+	var found []string
+	for _, filename := range filenames {
+		file := filepath.Join(directory, filepath.FromSlash(filename))
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			found = append(found, file)
+		}
+	}
+
+	if len(found) > 1 {
+		return "", errors.New("found multiple envcli project configs in " + directory + ": " + strings.Join(found, ", ") + ", please remove all but one")
+	} else if len(found) == 1 {
+		return found[0], nil
+	}
+
+	// feature: project config embedded in pyproject.toml or package.json, if there is no dedicated config file
+	return findEmbeddedConfig(directory), nil
+}
+
+// FindProjectConfig searches the directory and its parents for a project config, returns the project directory and the config file
+func FindProjectConfig(startDirectory string, filenames []string) (string, string, error) {
 	log.Trace().Msg("Trying to detect project directory ...")
+	log.Trace().Str("dir", startDirectory).Msg("current working directory")
+
+	var configFile string
+	projectDirectory, err := walkParentDirectories(startDirectory, func(directory string) (bool, error) {
+		file, findErr := FindConfigInDirectory(directory, filenames)
+		configFile = file
+		return file != "", findErr
+	})
+	if err != nil {
+		return "", "", err
+	} else if projectDirectory == "" {
+		log.Debug().Msg("didn't find a envcli project config in any parent directories")
+		return "", "", errors.New("didn't find a envcli project config in any parent directories")
+	}
 
-	currentDirectory := filesystem.GetWorkingDirectory()
-	var projectDirectory = ""
-	log.Trace().Str("dir", currentDirectory).Msg("current working directory")
+	log.Debug().Str("dir", projectDirectory).Str("file", configFile).Msg("found project config in directory")
+	return projectDirectory, configFile, nil
+}
 
-	directoryParts := strings.Split(currentDirectory, string(os.PathSeparator))
+// walkParentDirectories calls visit for the directory and each of its parents, until visit returns true or the root directory is reached.
+// It returns the directory visit returned true for, or an empty string if no directory matched.
+func walkParentDirectories(directory string, visit func(directory string) (bool, error)) (string, error) {
+	currentDirectory := filepath.Clean(directory)
 
-	for projectDirectory == "" {
-		if _, err := os.Stat(filepath.Join(currentDirectory, "/.envcli.yml")); err == nil {
-			log.Debug().Str("dir", currentDirectory).Msg("found project config in directory")
+	for depth := 0; depth < maxParentDirectoryDepth; depth++ {
+		found, err := visit(currentDirectory)
+		if err != nil {
+			return "", err
+		} else if found {
 			return currentDirectory, nil
 		}
 
-		if directoryParts[0]+"\\" == currentDirectory || currentDirectory == "/" {
-			log.Debug().Msg("didn't find a envcli project config in any parent directories")
-			return "", errors.New("didn't find a envcli project config in any parent directories")
+		// the parent of a root directory (/, C:\, \\server\share\) is the directory itself
+		parentDirectory := filepath.Dir(currentDirectory)
+		if parentDirectory == currentDirectory {
+			return "", nil
 		}
 
-		currentDirectory = filepath.Dir(currentDirectory)
+		currentDirectory = parentDirectory
 		log.Trace().Str("dir", currentDirectory).Msg("proceed to search next directory")
 	}
 
-	return "", errors.New("didn't find a envcli project config in any parent directories")
+	return "", errors.New("stopped searching for a envcli project config after " + strconv.Itoa(maxParentDirectoryDepth) + " parent directories")
 }
 
 // MergeConfigurations merges two configurations and keep the origin in the scope
 func MergeConfigurations(configProject ConfigurationFile, configGlobal ConfigurationFile) ConfigurationFile {
-	var cfg = ConfigurationFile{}
+	var cfg = ConfigurationFile{ImagePolicies: configProject.ImagePolicies, SkippedImages: configProject.SkippedImages, DeniedCommands: configProject.DeniedCommands}
 
 	for _, image := range configProject.Images {
 		image.Scope = "Project"
@@ -174,39 +374,248 @@ func MergeConfigurations(configProject ConfigurationFile, configGlobal Configura
 		cfg.Images = append(cfg.Images, image)
 	}
 
+	// policies apply regardless of the scope they have been defined in
+	cfg.Policy.Lint = append(append(cfg.Policy.Lint, configProject.Policy.Lint...), configGlobal.Policy.Lint...)
+
+	// tasks, the first definition of a task name takes precedence
+	cfg.Tasks = append(cfg.Tasks, configProject.Tasks...)
+	for _, task := range configGlobal.Tasks {
+		if _, found := cfg.GetTask(task.Name); !found {
+			cfg.Tasks = append(cfg.Tasks, task)
+		}
+	}
+
 	return cfg
 }
 
+// FilterImageConditions sets the source of the entries and drops all entries whose when condition is not met or invalid
+func FilterImageConditions(images []RunConfigurationEntry, source string) ([]RunConfigurationEntry, []SkippedEntry) {
+	var active []RunConfigurationEntry
+	var skipped []SkippedEntry
+
+	for _, image := range images {
+		image.Source = source
+
+		matches, err := EvaluateCondition(image.When)
+		if err != nil {
+			log.Warn().Err(err).Str("entry", image.Name).Str("file", source).Msg("ignoring entry with an invalid when condition")
+			skipped = append(skipped, SkippedEntry{Entry: image, Reason: "invalid condition: " + err.Error()})
+			continue
+		} else if !matches {
+			log.Debug().Str("entry", image.Name).Str("when", image.When).Msg("skipping entry, condition is not met")
+			skipped = append(skipped, SkippedEntry{Entry: image, Reason: "condition not met: " + image.When})
+			continue
+		}
+
+		active = append(active, image)
+	}
+
+	return active, skipped
+}
+
 // GetCommandConfiguration gets the configuration entry for a specified command in the specified directory
-func GetCommandConfiguration(commandName string, currentDirectory string, customIncludes []string) (RunConfigurationEntry, error) {
+func GetCommandConfiguration(ctx context.Context, commandName string, currentDirectory string, customIncludes []string) (RunConfigurationEntry, error) {
+	entry, _, err := GetCommandMatch(ctx, commandName, currentDirectory, customIncludes)
+	return entry, err
+}
+
+// LoadMergedConfiguration loads and merges the project, included and global configuration files
+func LoadMergedConfiguration(ctx context.Context, customIncludes []string) (ConfigurationFile, error) {
+	return LoadMergedConfigurationIn(ctx, filesystem.GetWorkingDirectory(), customIncludes)
+}
+
+// LoadMergedConfigurationIn loads and merges the configuration files, the project config is searched from the directory
+func LoadMergedConfigurationIn(ctx context.Context, directory string, customIncludes []string) (ConfigurationFile, error) {
 	// Global Configuration
 	propConfig, propConfigErr := LoadPropertyConfig()
 	if propConfigErr != nil {
 		// error, when loading the config
-		var emptyEntry RunConfigurationEntry
-		return emptyEntry, propConfigErr
+		return ConfigurationFile{}, propConfigErr
 	}
 
 	// Configuration file list
 	var configFiles []string
+	projectFiles := map[string]bool{}
 	// - project directory
-	projectDir, projectDirErr := GetProjectDirectory()
-	if projectDirErr == nil {
-		log.Debug().Msg("Project Directory: " + projectDir)
-		configFiles = append(configFiles, projectDir+"/.envcli.yml")
+	projectDir, projectConfigFile, projectConfigErr := FindProjectConfig(directory, GetProjectConfigFilenames())
+	if projectConfigErr == nil {
+		log.Debug().Msg("Project Config: " + projectConfigFile)
+		projectConfigFiles, chainErr := GetProjectConfigChain(projectDir, projectConfigFile, GetProjectConfigFilenames())
+		if chainErr != nil {
+			return ConfigurationFile{}, chainErr
+		}
+		configFiles = append(configFiles, projectConfigFiles...)
+		for _, file := range projectConfigFiles {
+			projectFiles[file] = true
+		}
 	}
 	// - custom includes
-	configFiles = append(configFiles, customIncludes...)
+	for _, include := range customIncludes {
+		if IsRemoteInclude(include) {
+			includeFile, includeErr := DownloadRemoteInclude(ctx, include, propConfig)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ConfigurationFile{}, ctxErr
+			} else if includeErr != nil {
+				log.Warn().Err(includeErr).Str("include", include).Msg("failed to download remote include")
+				continue
+			}
+			include = includeFile
+		}
+		configFiles = append(configFiles, include)
+	}
 	// - global (user-scope) configuration
-	var globalConfigPath = collection.MapGetValueOrDefault(propConfig.Properties, "global-configuration-path", defaultConfigurationDirectory)
-	log.Debug().Msg("Will load the global configuration from " + globalConfigPath + ".")
-	configFiles = append(configFiles, globalConfigPath+"/.envcli.yml")
+	syncGlobalRepo(ctx, propConfig)
+	globalConfigFile := GetGlobalConfigurationFile(propConfig)
+	log.Debug().Msg("Will load the global configuration from " + globalConfigFile + ".")
+	configFiles = append(configFiles, globalConfigFile)
 
 	// load configuration files
 	var finalConfiguration ConfigurationFile
+	var loadedFiles []string
 	for _, configFile := range configFiles {
-		configContent, _ := LoadProjectConfig(configFile)
+		configContent, loadErr := LoadProjectConfig(configFile)
+		var encryptedErr *EncryptedConfigError
+		if errors.As(loadErr, &encryptedErr) {
+			return ConfigurationFile{}, loadErr
+		} else if loadErr == nil {
+			loadedFiles = append(loadedFiles, configFile)
+		}
+		// the registry mirror replaces the registry the image is pulled from, a project can't redirect its images to another registry
+		if configFile != globalConfigFile {
+			for i := range configContent.Images {
+				configContent.Images[i] = dropImageMirror(configContent.Images[i], configFile)
+			}
+		}
+
+		var skipped []SkippedEntry
+		configContent.Images, skipped = FilterImageConditions(configContent.Images, configFile)
 		finalConfiguration = MergeConfigurations(finalConfiguration, configContent)
+		finalConfiguration.SkippedImages = append(finalConfiguration.SkippedImages, skipped...)
+
+		// denied commands are only honored from the project, the global config and includes can't block commands
+		if projectFiles[configFile] {
+			for _, command := range configContent.Deny {
+				finalConfiguration.DeniedCommands = append(finalConfiguration.DeniedCommands, DeniedCommand{Command: command, Source: configFile})
+			}
+		} else if len(configContent.Deny) > 0 {
+			log.Warn().Str("file", configFile).Msg("ignoring the deny list, it is only supported in the project config")
+		}
+
+		// credential helpers execute commands on the host, see GetCredentialHelpers
+		if configFile != globalConfigFile && len(configContent.CredentialHelpers) > 0 {
+			log.Warn().Str("file", configFile).Msg("ignoring the credential helpers, they are only supported in the global config")
+		}
+
+		// the signature public key decides which images run, a project can't set it
+		if configFile != globalConfigFile && configContent.Policy.Signature.Required {
+			log.Warn().Str("file", configFile).Msg("ignoring the signature policy, it is only supported in the global config")
+			configContent.Policy.Signature = SignaturePolicy{}
+		}
+
+		// image and security policies are kept per file, so that a project can't relax the policy of the global configuration
+		if len(configContent.Policy.AllowedImagePatterns) > 0 || len(configContent.Policy.SecurityOpt) > 0 || configContent.Policy.Signature.Required {
+			configContent.Policy.Source = configFile
+			finalConfiguration.ImagePolicies = append(finalConfiguration.ImagePolicies, configContent.Policy)
+		}
+	}
+
+	// image entry inheritance
+	images, inheritanceErr := ResolveImageInheritance(finalConfiguration.Images)
+	if inheritanceErr != nil {
+		return ConfigurationFile{}, inheritanceErr
+	}
+	finalConfiguration.Images = ResolveBuildImages(images)
+	finalConfiguration.ConfigFiles = loadedFiles
+	finalConfiguration.GlobalConfigFile = globalConfigFile
+
+	return finalConfiguration, nil
+}
+
+// GetCommandMatch gets the configuration entry for a specified command and returns how it was matched (MatchByProvides or MatchByName)
+func GetCommandMatch(ctx context.Context, commandName string, currentDirectory string, customIncludes []string) (RunConfigurationEntry, string, error) {
+	return GetCommandVariantMatch(ctx, commandName, "", currentDirectory, customIncludes)
+}
+
+// GetCommandVariantMatch gets the configuration entry for a specified command with the variant applied, see ApplyVariant
+func GetCommandVariantMatch(ctx context.Context, commandName string, variant string, currentDirectory string, customIncludes []string) (RunConfigurationEntry, string, error) {
+	finalConfiguration, err := LoadMergedConfigurationIn(ctx, currentDirectory, customIncludes)
+	if ctx.Err() != nil {
+		return RunConfigurationEntry{}, "", err
+	} else if err != nil {
+		var emptyEntry RunConfigurationEntry
+		return emptyEntry, "", exitcode.New(exitcode.ConfigError, err)
+	}
+
+	entry, matchType, err := FindCommandMatch(finalConfiguration, commandName)
+	if err == nil {
+		entry, err = ApplyVariant(entry, variant, os.Getenv)
+		if err == nil && entry.Variant != "" {
+			// the variant can change the image and the security options
+			entry, err = applyPolicies(entry, finalConfiguration.ImagePolicies)
+		}
+		return entry, matchType, err
+	}
+	var noMatch *NoMatchError
+	if errors.As(err, &noMatch) {
+		propConfig, _ := LoadPropertyConfig()
+		if image := collection.MapGetValueOrDefault(propConfig.Properties, "fallback-image", ""); image != "" {
+			log.Debug().Str("image", image).Msg("no configuration for command " + commandName + " found, using the fallback image")
+			entry, policyErr := applyPolicies(FallbackEntry(commandName, image), finalConfiguration.ImagePolicies)
+			return entry, MatchByFallback, policyErr
+		}
+	}
+	return entry, matchType, err
+}
+
+// FallbackEntry returns the entry running a command that isn't configured in the fallback image
+func FallbackEntry(commandName string, image string) RunConfigurationEntry {
+	return RunConfigurationEntry{
+		Name:        "fallback",
+		Description: "fallback for commands that aren't configured",
+		Provides:    []string{commandName},
+		Image:       image,
+		Scope:       MatchByFallback,
+		Source:      "property fallback-image",
+	}
+}
+
+// dropImageMirror removes the imageMirror of the entry and its variants, it is only honored from the global config
+func dropImageMirror(entry RunConfigurationEntry, configFile string) RunConfigurationEntry {
+	if entry.ImageMirror != "" {
+		log.Warn().Str("file", configFile).Str("entry", entry.Name).Msg("ignoring the imageMirror, it is only supported in the global config")
+		entry.ImageMirror = ""
+	}
+	for name, variant := range entry.Variants {
+		if variant.ImageMirror != "" {
+			log.Warn().Str("file", configFile).Str("entry", entry.Name).Str("variant", name).Msg("ignoring the imageMirror, it is only supported in the global config")
+			variant.ImageMirror = ""
+			entry.Variants[name] = variant
+		}
+	}
+	return entry
+}
+
+// applyPolicies checks the image of the entry against the policies, adds the pinned security options and the required signatures
+func applyPolicies(entry RunConfigurationEntry, policies []PolicyConfiguration) (RunConfigurationEntry, error) {
+	if err := CheckImagePolicies(entry.Image, policies); err != nil {
+		return RunConfigurationEntry{}, exitcode.New(exitcode.ConfigError, err)
+	}
+
+	entry, err := ApplySecurityPolicies(entry, policies)
+	if err != nil {
+		return RunConfigurationEntry{}, exitcode.New(exitcode.ConfigError, err)
+	}
+	if entry, err = ApplySignaturePolicy(entry, policies); err != nil {
+		return RunConfigurationEntry{}, exitcode.New(exitcode.ConfigError, err)
+	}
+	entry.ImagePolicies = policies
+	return entry, nil
+}
+
+// FindCommandMatch searches the configuration for the entry providing the command, falls back to a entry with a matching name
+func FindCommandMatch(finalConfiguration ConfigurationFile, commandName string) (RunConfigurationEntry, string, error) {
+	if denied, found := finalConfiguration.GetDeniedCommand(commandName); found {
+		return RunConfigurationEntry{}, "", exitcode.New(exitcode.ConfigError, errors.New("the command "+commandName+" is denied by "+denied.Source))
 	}
 
 	// search for command definition
@@ -215,13 +624,22 @@ func GetCommandConfiguration(commandName string, currentDirectory string, custom
 		for _, providedCommand := range element.Provides {
 			if providedCommand == commandName {
 				log.Debug().Msg("Matched command " + commandName + " in package [" + element.Name + "]")
-
-				return element, nil
+				entry, policyErr := applyPolicies(element, finalConfiguration.ImagePolicies)
+				return entry, MatchByProvides, policyErr
 			}
 		}
 	}
 
+	// fallback: search for a entry with a matching name
+	for _, element := range finalConfiguration.Images {
+		if strings.EqualFold(element.Name, commandName) {
+			log.Info().Msg("No image provides the command " + commandName + ", matched by the name of package [" + element.Name + "] instead")
+			entry, policyErr := applyPolicies(element, finalConfiguration.ImagePolicies)
+			return entry, MatchByName, policyErr
+		}
+	}
+
 	// didn't find a match, error
 	var emptyEntry RunConfigurationEntry
-	return emptyEntry, errors.New("no configuration for command " + commandName + " found")
+	return emptyEntry, "", exitcode.New(exitcode.ConfigError, &NoMatchError{Command: commandName, Suggestions: SuggestCommands(finalConfiguration, commandName), Hints: missingCommandHints(finalConfiguration, commandName)})
 }
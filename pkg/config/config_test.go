@@ -0,0 +1,143 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+)
+
+func writeFile(t *testing.T, file string) {
+	_ = os.MkdirAll(filepath.Dir(file), os.ModePerm)
+	if err := os.WriteFile(file, []byte("images: []\n"), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", file, err)
+	}
+}
+
+func TestFindProjectConfigCandidates(t *testing.T) {
+	for _, filename := range defaultProjectConfigFilenames {
+		root := t.TempDir()
+		writeFile(t, filepath.Join(root, filepath.FromSlash(filename)))
+		nested := filepath.Join(root, "src", "pkg")
+		_ = os.MkdirAll(nested, os.ModePerm)
+
+		directory, file, err := FindProjectConfig(nested, defaultProjectConfigFilenames)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", filename, err)
+		}
+		if directory != root || file != filepath.Join(root, filepath.FromSlash(filename)) {
+			t.Errorf("%s: unexpected result %s %s", filename, directory, file)
+		}
+	}
+}
+
+func TestFindProjectConfigCustomFilename(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "tools.yml"))
+
+	_, file, err := FindProjectConfig(root, append(defaultProjectConfigFilenames, "tools.yml"))
+	if err != nil || file != filepath.Join(root, "tools.yml") {
+		t.Errorf("expected custom filename to be found, got %s %v", file, err)
+	}
+}
+
+func TestFindProjectConfigAmbiguous(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".envcli.yml"))
+	writeFile(t, filepath.Join(root, "envcli.yml"))
+
+	_, _, err := FindProjectConfig(root, defaultProjectConfigFilenames)
+	if err == nil {
+		t.Fatalf("expected ambiguity error")
+	}
+	if !strings.Contains(err.Error(), ".envcli.yml") || !strings.Contains(err.Error(), string(os.PathSeparator)+"envcli.yml") {
+		t.Errorf("expected error to name both files, got %v", err)
+	}
+}
+
+func TestWalkParentDirectories(t *testing.T) {
+	var tests = []struct {
+		goos     string
+		start    string
+		expected []string
+	}{
+		{"", "/", []string{"/"}},
+		{"", "/home/user/project", []string{"/home/user/project", "/home/user", "/home", "/"}},
+		{"", "/home/user/project/", []string{"/home/user/project", "/home/user", "/home", "/"}},
+		{"", "/home//user///", []string{"/home/user", "/home", "/"}},
+		{"", "relative/dir", []string{"relative/dir", "relative", "."}},
+		{"windows", `C:\`, []string{`C:\`}},
+		{"windows", `C:\Users\project\`, []string{`C:\Users\project`, `C:\Users`, `C:\`}},
+		{"windows", `C:project`, []string{`C:project`, `C:.`}},
+		{"windows", `\\server\share\dir\sub`, []string{`\\server\share\dir\sub`, `\\server\share\dir`, `\\server\share\`}},
+		{"windows", `\\server\share\`, []string{`\\server\share\`}},
+	}
+
+	for _, test := range tests {
+		if (test.goos == "windows") != (runtime.GOOS == "windows") {
+			continue
+		}
+		if test.goos == "" && runtime.GOOS == "windows" {
+			continue
+		}
+
+		var visited []string
+		directory, err := walkParentDirectories(test.start, func(directory string) (bool, error) {
+			visited = append(visited, directory)
+			return false, nil
+		})
+		if err != nil || directory != "" {
+			t.Errorf("%s: unexpected result %s %v", test.start, directory, err)
+		}
+		if strings.Join(visited, "|") != strings.Join(test.expected, "|") {
+			t.Errorf("%s: expected %v, got %v", test.start, test.expected, visited)
+		}
+	}
+}
+
+func TestWalkParentDirectoriesDepthLimit(t *testing.T) {
+	deep := strings.Repeat("/d", maxParentDirectoryDepth+10)
+
+	_, err := walkParentDirectories(deep, func(directory string) (bool, error) { return false, nil })
+	if err == nil {
+		t.Errorf("expected depth limit error")
+	}
+}
+
+func TestFilterImageConditions(t *testing.T) {
+	images := []RunConfigurationEntry{
+		{Name: "always"},
+		{Name: "never", When: `os == "plan10"`},
+		{Name: "invalid", When: `os = "linux"`},
+	}
+
+	active, skipped := FilterImageConditions(images, "test.yml")
+	if len(active) != 1 || active[0].Name != "always" || active[0].Source != "test.yml" {
+		t.Errorf("unexpected active entries %+v", active)
+	}
+	if len(skipped) != 2 || !strings.HasPrefix(skipped[0].Reason, "condition not met") || !strings.HasPrefix(skipped[1].Reason, "invalid condition") {
+		t.Errorf("unexpected skipped entries %+v", skipped)
+	}
+}
+
+func TestFindCommandMatchExitCodes(t *testing.T) {
+	cfg := ConfigurationFile{
+		Images:        []RunConfigurationEntry{{Name: "node", Provides: []string{"npm"}, Image: "docker.io/node:18"}},
+		ImagePolicies: []PolicyConfiguration{{AllowedImagePatterns: []string{"quay.io/**"}}},
+	}
+
+	if _, _, err := FindCommandMatch(cfg, "yarn"); exitcode.Of(err) != exitcode.ConfigError {
+		t.Errorf("expected a config error for a unknown command, got %v", err)
+	}
+	if _, _, err := FindCommandMatch(cfg, "npm"); exitcode.Of(err) != exitcode.ConfigError {
+		t.Errorf("expected a config error for a policy violation, got %v", err)
+	}
+
+	cfg.ImagePolicies = nil
+	if entry, matchType, err := FindCommandMatch(cfg, "npm"); err != nil || entry.Name != "node" || matchType != MatchByProvides {
+		t.Errorf("unexpected match %+v %s %v", entry, matchType, err)
+	}
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/EnvCLI/EnvCLI/pkg/atomicfile"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// configCacheDirectory stores the parsed configuration files on disk, only the in-process cache is used if empty
+	configCacheDirectory string
+
+	// configCache holds the parsed configuration files of this process, keyed by path, modification time and size
+	configCache      = make(map[string][]byte)
+	configCacheMutex sync.Mutex
+
+	// configSchema changes whenever the configuration types change, so that entries of other envcli versions are ignored
+	configSchema     string
+	configSchemaOnce sync.Once
+)
+
+// configCacheEntry is a parsed configuration file
+type configCacheEntry struct {
+	Schema       string
+	Path         string
+	ModTime      int64
+	Size         int64
+	Deprecations []DeprecationWarning
+	Config       ConfigurationFile
+}
+
+// SetConfigCacheDirectory sets the directory of the on-disk cache of the parsed configuration files, an empty directory disables it
+func SetConfigCacheDirectory(dir string) {
+	configCacheMutex.Lock()
+	defer configCacheMutex.Unlock()
+	configCacheDirectory = dir
+}
+
+// GetConfigCacheDirectory returns the directory used to cache the parsed configuration files (cache-path/config or the user cache directory)
+func GetConfigCacheDirectory(propConfig PropertyConfigurationFile) string {
+	return getCacheSubdirectory(propConfig, "config")
+}
+
+// ClearConfigCache removes all parsed configuration files from the in-process and the on-disk cache
+func ClearConfigCache(dir string) error {
+	configCacheMutex.Lock()
+	configCache = make(map[string][]byte)
+	configCacheMutex.Unlock()
+
+	return os.RemoveAll(dir)
+}
+
+// loadCachedConfig returns the cached configuration of the file, if the file didn't change since it has been cached
+func loadCachedConfig(configFile string, info os.FileInfo) (ConfigurationFile, bool) {
+	path, key, err := configCacheKey(configFile, info)
+	if err != nil {
+		return ConfigurationFile{}, false
+	}
+
+	configCacheMutex.Lock()
+	data, found := configCache[key]
+	dir := configCacheDirectory
+	configCacheMutex.Unlock()
+	if !found && dir != "" {
+		// entries of concurrent processes are replaced atomically, unreadable or outdated entries are parsed again
+		data, err = os.ReadFile(filepath.Join(dir, configCacheFileName(path)))
+		found = err == nil
+	}
+	if !found {
+		return ConfigurationFile{}, false
+	}
+
+	// each load decodes a copy, the callers modify the entries of the configuration
+	var entry configCacheEntry
+	if err = json.Unmarshal(data, &entry); err != nil || entry.Schema != getConfigSchema() || entry.Path != path || entry.ModTime != info.ModTime().UnixNano() || entry.Size != info.Size() {
+		return ConfigurationFile{}, false
+	}
+	configCacheMutex.Lock()
+	configCache[key] = data
+	configCacheMutex.Unlock()
+
+	log.Trace().Str("file", configFile).Msg("using the cached configuration")
+	reportDeprecations(configFile, entry.Deprecations)
+	return entry.Config, true
+}
+
+// storeCachedConfig caches the parsed configuration of the file
+func storeCachedConfig(configFile string, info os.FileInfo, cfg ConfigurationFile, deprecations []DeprecationWarning) {
+	path, key, err := configCacheKey(configFile, info)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(configCacheEntry{Schema: getConfigSchema(), Path: path, ModTime: info.ModTime().UnixNano(), Size: info.Size(), Deprecations: deprecations, Config: cfg})
+	if err != nil {
+		return
+	}
+
+	configCacheMutex.Lock()
+	configCache[key] = data
+	dir := configCacheDirectory
+	configCacheMutex.Unlock()
+	if dir == "" {
+		return
+	}
+	if err = os.MkdirAll(dir, os.ModePerm); err == nil {
+		err = atomicfile.WriteFile(filepath.Join(dir, configCacheFileName(path)), data, 0600)
+	}
+	if err != nil {
+		log.Debug().Err(err).Str("file", configFile).Msg("failed to cache the configuration")
+	}
+}
+
+// configCacheKey returns the absolute path of the file and the key of the in-process cache
+func configCacheKey(configFile string, info os.FileInfo) (string, string, error) {
+	path, err := filepath.Abs(configFile)
+	if err != nil {
+		return "", "", err
+	}
+	return path, fmt.Sprintf("%s|%d|%d", path, info.ModTime().UnixNano(), info.Size()), nil
+}
+
+// configCacheFileName returns the name of the on-disk cache file of the configuration file
+func configCacheFileName(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return hex.EncodeToString(sum[:]) + ".json"
+}
+
+// getConfigSchema returns the signature of the configuration types
+func getConfigSchema() string {
+	configSchemaOnce.Do(func() {
+		var b strings.Builder
+		writeTypeSignature(&b, reflect.TypeOf(ConfigurationFile{}), make(map[reflect.Type]bool))
+		sum := sha256.Sum256([]byte(b.String()))
+		configSchema = hex.EncodeToString(sum[:8])
+	})
+	return configSchema
+}
+
+func writeTypeSignature(b *strings.Builder, t reflect.Type, visited map[reflect.Type]bool) {
+	b.WriteString(t.String())
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		if t.Kind() == reflect.Map {
+			writeTypeSignature(b, t.Key(), visited)
+		}
+		writeTypeSignature(b, t.Elem(), visited)
+	case reflect.Struct:
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+		b.WriteString("{")
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			b.WriteString(field.Name + " " + string(field.Tag) + " ")
+			writeTypeSignature(b, field.Type, visited)
+			b.WriteString(";")
+		}
+		b.WriteString("}")
+	}
+}
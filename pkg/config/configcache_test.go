@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadProjectConfigCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	SetConfigCacheDirectory(cacheDir)
+	t.Cleanup(func() { SetConfigCacheDirectory("") })
+
+	file := filepath.Join(t.TempDir(), ".envcli.yml")
+	if err := os.WriteFile(file, []byte("images:\n  - name: node\n    image: node:18\n    init: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadProjectConfig(file)
+	if err != nil || len(cfg.Images) != 1 {
+		t.Fatalf("unexpected config %+v (%v)", cfg, err)
+	}
+
+	// the cached copy is independent of the returned configuration
+	cfg.Images[0].Image = "modified"
+	if cfg, err = LoadProjectConfig(file); err != nil || cfg.Images[0].Image != "node:18" || cfg.Images[0].UsesInit() {
+		t.Errorf("expected a unmodified copy of the cached config, got %+v (%v)", cfg.Images, err)
+	}
+
+	// entries of other processes are read from disk
+	entries, _ := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	if len(entries) != 1 {
+		t.Fatalf("expected a on-disk cache entry, got %v", entries)
+	}
+	data, _ := os.ReadFile(entries[0])
+	if err = os.WriteFile(entries[0], []byte(strings.Replace(string(data), "node:18", "node:from-disk", 1)), 0600); err != nil {
+		t.Fatal(err)
+	}
+	configCacheMutex.Lock()
+	configCache = make(map[string][]byte)
+	configCacheMutex.Unlock()
+	if cfg, err = LoadProjectConfig(file); err != nil || cfg.Images[0].Image != "node:from-disk" {
+		t.Errorf("expected the on-disk cache entry to be used, got %+v (%v)", cfg.Images, err)
+	}
+
+	// changed files are parsed again
+	if err = os.WriteFile(file, []byte("images:\n  - name: node\n    image: node:20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	_ = os.Chtimes(file, later, later)
+	if cfg, err = LoadProjectConfig(file); err != nil || cfg.Images[0].Image != "node:20" {
+		t.Errorf("expected the changed file to be parsed again, got %+v (%v)", cfg.Images, err)
+	}
+
+	if err = ClearConfigCache(cacheDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(cacheDir); !os.IsNotExist(err) {
+		t.Errorf("expected the cache directory to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CredentialHelper obtains short-lived credentials on the host, which are passed into the container as environment variables
+type CredentialHelper struct {
+	// command executed on the host, can use templates (ex. {{ .ProjectName }})
+	Command string `yaml:"command"`
+
+	// maps the container variables to the output of the command: a field of the json output, or empty for the whole output (ex. a token)
+	Env map[string]string `yaml:"env"`
+}
+
+// BuiltinCredentialHelpers are the credential helpers available without configuration, the global config can override or extend them
+var BuiltinCredentialHelpers = map[string]CredentialHelper{
+	"aws": {
+		Command: "aws configure export-credentials --format process",
+		Env: map[string]string{
+			"AWS_ACCESS_KEY_ID":         "AccessKeyId",
+			"AWS_SECRET_ACCESS_KEY":     "SecretAccessKey",
+			"AWS_SESSION_TOKEN":         "SessionToken",
+			"AWS_CREDENTIAL_EXPIRATION": "Expiration",
+		},
+	},
+	"gcloud": {
+		Command: "gcloud auth print-access-token",
+		Env:     map[string]string{"GOOGLE_OAUTH_ACCESS_TOKEN": ""},
+	},
+}
+
+// GetCredentialHelpers returns the builtin credential helpers merged with the helpers of the global config.
+// The helpers execute commands on the host, so they are only read from the global config of the user.
+func GetCredentialHelpers() (map[string]CredentialHelper, error) {
+	helpers := make(map[string]CredentialHelper, len(BuiltinCredentialHelpers))
+	for name, helper := range BuiltinCredentialHelpers {
+		helpers[name] = helper
+	}
+
+	propConfig, err := LoadPropertyConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := LoadProjectConfig(GetGlobalConfigurationFile(propConfig))
+	if err != nil {
+		// the global config is optional
+		return helpers, nil
+	}
+	for name, helper := range cfg.CredentialHelpers {
+		if err = ValidateCredentialHelper(helper); err != nil {
+			return nil, fmt.Errorf("invalid credential helper %s: %w", name, err)
+		}
+		helpers[name] = helper
+	}
+	return helpers, nil
+}
+
+// ValidateCredentialHelper returns a error if the helper has no command or maps no variables
+func ValidateCredentialHelper(helper CredentialHelper) error {
+	if strings.TrimSpace(helper.Command) == "" {
+		return errors.New("command is required")
+	}
+	if len(helper.Env) == 0 {
+		return errors.New("env must map at least one variable")
+	}
+	return nil
+}
+
+// CredentialHelperNames returns the sorted names of the credential helpers
+func CredentialHelperNames(helpers map[string]CredentialHelper) []string {
+	var names []string
+	for name := range helpers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseCredentialOutput maps the output of the credential helper to the variables (NAME=value), sorted by name.
+// Fields that are missing or empty in the output are skipped, ex. the session token of long-lived aws keys.
+func ParseCredentialOutput(helper CredentialHelper, output string) ([]string, error) {
+	output = strings.TrimSpace(output)
+	var fields map[string]interface{}
+
+	var env []string
+	for name, field := range helper.Env {
+		value := output
+		if field != "" {
+			if fields == nil {
+				// the output may contain the credentials, it is never part of the error
+				if err := json.Unmarshal([]byte(output), &fields); err != nil {
+					return nil, errors.New("the output is not a json object")
+				}
+			}
+			raw, found := fields[field]
+			if !found || raw == nil {
+				continue
+			}
+			value = fmt.Sprint(raw)
+		}
+		if value == "" {
+			continue
+		}
+		env = append(env, name+"="+value)
+	}
+	sort.Strings(env)
+	return env, nil
+}
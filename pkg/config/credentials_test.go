@@ -0,0 +1,44 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCredentialOutput(t *testing.T) {
+	aws := BuiltinCredentialHelpers["aws"]
+	env, err := ParseCredentialOutput(aws, `{"Version": 1, "AccessKeyId": "AKIA", "SecretAccessKey": "secret", "SessionToken": "token", "Expiration": "2026-10-16T12:00:00Z"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(env, ",") != "AWS_ACCESS_KEY_ID=AKIA,AWS_CREDENTIAL_EXPIRATION=2026-10-16T12:00:00Z,AWS_SECRET_ACCESS_KEY=secret,AWS_SESSION_TOKEN=token" {
+		t.Errorf("unexpected variables %v", env)
+	}
+
+	// long-lived keys have no session token
+	if env, err = ParseCredentialOutput(aws, `{"Version": 1, "AccessKeyId": "AKIA", "SecretAccessKey": "secret"}`); err != nil || len(env) != 2 {
+		t.Errorf("expected the missing fields to be skipped, got %v (%v)", env, err)
+	}
+
+	// the whole output is the token
+	if env, err = ParseCredentialOutput(BuiltinCredentialHelpers["gcloud"], "ya29.token\n"); err != nil || strings.Join(env, ",") != "GOOGLE_OAUTH_ACCESS_TOKEN=ya29.token" {
+		t.Errorf("unexpected variables %v (%v)", env, err)
+	}
+
+	// the output is never part of the error
+	if _, err = ParseCredentialOutput(aws, "AKIA secret"); err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("expected a error without the output, got %v", err)
+	}
+}
+
+func TestValidateCredentialHelper(t *testing.T) {
+	if err := ValidateCredentialHelper(CredentialHelper{Env: map[string]string{"TOKEN": ""}}); err == nil {
+		t.Errorf("expected a error for a helper without command")
+	}
+	if err := ValidateCredentialHelper(CredentialHelper{Command: "vault print token"}); err == nil {
+		t.Errorf("expected a error for a helper without variables")
+	}
+	if err := ValidateCredentialHelper(CredentialHelper{Command: "vault print token", Env: map[string]string{"VAULT_TOKEN": ""}}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
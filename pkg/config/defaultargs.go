@@ -0,0 +1,48 @@
+package config
+
+import (
+	"errors"
+)
+
+// Positions of the default arguments, relative to the arguments of the user
+const (
+	// ArgPositionAppend adds the default arguments after the arguments of the user (default)
+	ArgPositionAppend = "append"
+
+	// ArgPositionPrepend adds the default arguments before the arguments of the user
+	ArgPositionPrepend = "prepend"
+)
+
+// ValidateArgPosition returns a error if the position of the default arguments is unknown
+func ValidateArgPosition(position string) error {
+	switch position {
+	case "", ArgPositionAppend, ArgPositionPrepend:
+		return nil
+	}
+	return errors.New("invalid argPosition " + position + ", allowed: " + ArgPositionAppend + ", " + ArgPositionPrepend)
+}
+
+// GetDefaultArgs returns the default arguments configured for the command, nil if there are none
+func GetDefaultArgs(entry RunConfigurationEntry, command string) []string {
+	return entry.DefaultArgs[command]
+}
+
+// ApplyDefaultArgs adds the default arguments of the command to the arguments of the user, the arguments are never split or quoted again
+func ApplyDefaultArgs(entry RunConfigurationEntry, command string, args []string) ([]string, error) {
+	if err := ValidateArgPosition(entry.ArgPosition); err != nil {
+		return nil, err
+	}
+
+	defaultArgs := GetDefaultArgs(entry, command)
+	if len(defaultArgs) == 0 {
+		return args, nil
+	}
+
+	result := make([]string, 0, len(args)+len(defaultArgs))
+	if entry.ArgPosition == ArgPositionPrepend {
+		result = append(append(result, defaultArgs...), args...)
+	} else {
+		result = append(append(result, args...), defaultArgs...)
+	}
+	return result, nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyDefaultArgs(t *testing.T) {
+	entry := RunConfigurationEntry{DefaultArgs: map[string][]string{"eslint": {"--max-warnings", "0"}, "terraform": {"-no-color"}}}
+
+	args, err := ApplyDefaultArgs(entry, "eslint", []string{"src dir"})
+	if err != nil || !reflect.DeepEqual(args, []string{"src dir", "--max-warnings", "0"}) {
+		t.Errorf("expected appended default args, got %v (%v)", args, err)
+	}
+
+	entry.ArgPosition = ArgPositionPrepend
+	args, err = ApplyDefaultArgs(entry, "terraform", []string{"plan"})
+	if err != nil || !reflect.DeepEqual(args, []string{"-no-color", "plan"}) {
+		t.Errorf("expected prepended default args, got %v (%v)", args, err)
+	}
+
+	args, err = ApplyDefaultArgs(entry, "node", []string{"index.js"})
+	if err != nil || !reflect.DeepEqual(args, []string{"index.js"}) {
+		t.Errorf("expected unchanged args, got %v (%v)", args, err)
+	}
+
+	entry.ArgPosition = "middle"
+	if _, err = ApplyDefaultArgs(entry, "eslint", nil); err == nil {
+		t.Errorf("expected invalid argPosition to be rejected")
+	}
+}
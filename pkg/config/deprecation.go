@@ -0,0 +1,230 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Deprecation is a deprecated field of the configuration files
+type Deprecation struct {
+	// Path of the field, list items are marked with [] (ex. images[].scope)
+	Path string
+
+	// Replacement is the new name of the field, the field is removed if empty
+	Replacement string
+
+	// Message explains the deprecation
+	Message string
+}
+
+// Deprecations are the deprecated fields, they are migrated when the configuration is loaded and by envcli migrate-config
+var Deprecations = []Deprecation{
+	{Path: "commands", Replacement: "images", Message: "commands has been renamed to images"},
+	{Path: "images[].scope", Message: "scope is set by envcli and has no effect"},
+}
+
+// DeprecationWarning is a use of a deprecated field
+type DeprecationWarning struct {
+	Deprecation
+
+	// Line of the field, starting at 1
+	Line int
+
+	// Skipped is set if the field couldn't be migrated, because the replacement is already set
+	Skipped bool
+}
+
+func (w DeprecationWarning) String() string {
+	switch {
+	case w.Skipped:
+		return fmt.Sprintf("line %d: %s, %s is already set - merge the entries manually", w.Line, w.Message, w.Replacement)
+	case w.Replacement != "":
+		return fmt.Sprintf("line %d: %s, use %s instead", w.Line, w.Message, w.Replacement)
+	}
+	return fmt.Sprintf("line %d: %s, remove it", w.Line, w.Message)
+}
+
+// yamlKeyLine matches lines with a key: indentation, optional list item dash, key and value
+var yamlKeyLine = regexp.MustCompile(`^(\s*)(-\s+)?([A-Za-z_][A-Za-z0-9_-]*)(\s*:)(.*)$`)
+
+// yamlField is a key of the configuration file
+type yamlField struct {
+	line   int
+	column int
+	path   string
+	key    string
+
+	// parent identifies the mapping containing the key
+	parent int
+
+	// item is set if the key starts a list item (- key: value)
+	item bool
+
+	// end is the line after the field including its nested lines
+	end int
+}
+
+// scanYAMLFields returns the keys of the block style yaml document with their paths, the contents of block scalars are skipped
+func scanYAMLFields(lines []string) []yamlField {
+	type level struct {
+		column int
+		path   string
+		id     int
+		item   bool
+	}
+	var fields []yamlField
+	var stack []level
+	nextID := 1
+	blockColumn := -1
+	parentOf := func() level {
+		if len(stack) == 0 {
+			return level{}
+		}
+		return stack[len(stack)-1]
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || (blockColumn >= 0 && indent > blockColumn) {
+			continue
+		}
+		blockColumn = -1
+
+		match := yamlKeyLine.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		column := len(match[1]) + len(match[2])
+
+		if match[2] != "" {
+			// list item, the items of compact lists (without indentation) are at the column of their parent key
+			dash := len(match[1])
+			for len(stack) > 0 && (stack[len(stack)-1].column > dash || (stack[len(stack)-1].column == dash && stack[len(stack)-1].item)) {
+				stack = stack[:len(stack)-1]
+			}
+			stack = append(stack, level{column: dash, path: parentOf().path + "[]", id: nextID, item: true})
+			nextID++
+		}
+		for len(stack) > 0 && stack[len(stack)-1].column >= column {
+			stack = stack[:len(stack)-1]
+		}
+
+		parent := parentOf()
+		path := match[3]
+		if parent.path != "" {
+			path = parent.path + "." + match[3]
+		}
+		fields = append(fields, yamlField{line: i, column: column, path: path, key: match[3], parent: parent.id, item: match[2] != ""})
+
+		// the nested fields of renamed fields use the new name
+		nestedPath := path
+		for _, deprecation := range Deprecations {
+			if deprecation.Path == path && deprecation.Replacement != "" {
+				nestedPath = strings.TrimSuffix(path, match[3]) + deprecation.Replacement
+			}
+		}
+		stack = append(stack, level{column: column, path: nestedPath, id: nextID})
+		nextID++
+
+		if value := strings.TrimSpace(match[5]); strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			blockColumn = column
+		}
+	}
+
+	// a field ends at the next line that isn't nested below it, compact list items are nested at the column of the key
+	for i := range fields {
+		field := &fields[i]
+		field.end = len(lines)
+		for j := field.line + 1; j < len(lines); j++ {
+			trimmed := strings.TrimSpace(lines[j])
+			indent := len(lines[j]) - len(strings.TrimLeft(lines[j], " "))
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") || indent > field.column {
+				continue
+			}
+			if indent == field.column && !field.item && strings.HasPrefix(trimmed, "-") {
+				continue
+			}
+			field.end = j
+			break
+		}
+		for field.end-1 > field.line && strings.TrimSpace(lines[field.end-1]) == "" {
+			field.end--
+		}
+	}
+	return fields
+}
+
+// MigrateConfig rewrites the deprecated fields of the configuration file, the other lines (including comments) are kept as-is
+func MigrateConfig(content []byte) ([]byte, []DeprecationWarning) {
+	lines := strings.Split(string(content), "\n")
+	fields := scanYAMLFields(lines)
+
+	siblings := make(map[int]map[string]bool)
+	for _, field := range fields {
+		if siblings[field.parent] == nil {
+			siblings[field.parent] = make(map[string]bool)
+		}
+		siblings[field.parent][field.key] = true
+	}
+
+	var warnings []DeprecationWarning
+	removed := make(map[int]bool)
+	for _, field := range fields {
+		for _, deprecation := range Deprecations {
+			if deprecation.Path != field.path {
+				continue
+			}
+
+			warning := DeprecationWarning{Deprecation: deprecation, Line: field.line + 1}
+			switch {
+			case deprecation.Replacement != "" && siblings[field.parent][deprecation.Replacement]:
+				warning.Skipped = true
+			case deprecation.Replacement != "":
+				line := lines[field.line]
+				lines[field.line] = line[:field.column] + deprecation.Replacement + line[field.column+len(field.key):]
+			default:
+				for i := field.line; i < field.end; i++ {
+					removed[i] = true
+				}
+				if field.item {
+					// the next key of the item starts the item
+					prefix := lines[field.line][:field.column]
+					if field.end < len(lines) && len(lines[field.end]) > field.column && strings.TrimSpace(lines[field.end][:field.column]) == "" {
+						lines[field.end] = prefix + lines[field.end][field.column:]
+					} else {
+						lines[field.line] = prefix + "{}"
+						removed[field.line] = false
+					}
+				}
+			}
+			warnings = append(warnings, warning)
+		}
+	}
+
+	var result []string
+	for i, line := range lines {
+		if !removed[i] || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			result = append(result, line)
+		}
+	}
+	return []byte(strings.Join(result, "\n")), warnings
+}
+
+// reportedDeprecations deduplicates the deprecation warnings of a run, the configuration files are loaded multiple times
+var reportedDeprecations sync.Map
+
+// reportDeprecations logs each deprecation warning of the configuration file once
+func reportDeprecations(configFile string, warnings []DeprecationWarning) {
+	for _, warning := range warnings {
+		message := configFile + " " + warning.String()
+		if _, reported := reportedDeprecations.LoadOrStore(message, true); reported {
+			continue
+		}
+		log.Warn().Str("file", configFile).Int("line", warning.Line).Msg(warning.String() + " (fix it using envcli migrate-config)")
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMigrateConfig(t *testing.T) {
+	var tests = []struct {
+		name     string
+		content  string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "current schema",
+			content:  "images:\n  - name: node\n    image: node:20\n",
+			expected: "images:\n  - name: node\n    image: node:20\n",
+		},
+		{
+			name:     "renamed list with comments",
+			content:  "# tools\ncommands: # all tools\n- name: node # node.js\n  image: node:20\n",
+			expected: "# tools\nimages: # all tools\n- name: node # node.js\n  image: node:20\n",
+			warnings: 1,
+		},
+		{
+			name:     "removed fields below a renamed field",
+			content:  "commands:\n  - name: node\n    scope: Global\n    image: node:20\n  - scope: Project\n    name: go\n  - scope: Project\n",
+			expected: "images:\n  - name: node\n    image: node:20\n  - name: go\n  - {}\n",
+			warnings: 4,
+		},
+		{
+			name:     "block scalars are skipped",
+			content:  "images:\n  - name: node\n    before_script:\n      - |\n        commands:\n        scope: x\n",
+			expected: "images:\n  - name: node\n    before_script:\n      - |\n        commands:\n        scope: x\n",
+		},
+		{
+			name:     "replacement already set",
+			content:  "images: []\ncommands:\n  - name: node\n",
+			expected: "images: []\ncommands:\n  - name: node\n",
+			warnings: 1,
+		},
+	}
+
+	for _, test := range tests {
+		migrated, warnings := MigrateConfig([]byte(test.content))
+		if string(migrated) != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.name, test.expected, migrated)
+		}
+		if len(warnings) != test.warnings {
+			t.Errorf("%s: expected %d warnings, got %v", test.name, test.warnings, warnings)
+		}
+	}
+}
+
+func TestDeprecationWarningString(t *testing.T) {
+	_, warnings := MigrateConfig([]byte("images: []\ncommands:\n  - name: node\n    scope: Global\n"))
+	if len(warnings) != 2 || warnings[0].String() != "line 2: commands has been renamed to images, images is already set - merge the entries manually" || !strings.HasPrefix(warnings[1].String(), "line 4: scope") {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+}
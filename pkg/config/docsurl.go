@@ -0,0 +1,22 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ValidateDocsURL checks that the documentation url of a entry is a absolute http or https url
+func ValidateDocsURL(docsURL string) error {
+	if docsURL == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(docsURL)
+	if err != nil {
+		return fmt.Errorf("invalid docsUrl %q: %w", docsURL, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid docsUrl %q, expected a absolute http or https url", docsURL)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+)
+
+// Files of other tools that can hold the project config, used if the directory has no dedicated project config file
+const (
+	PyprojectFile   = "pyproject.toml"
+	PackageJSONFile = "package.json"
+)
+
+// embeddedConfigFilenames are the files that can embed the project config, in order of precedence
+var embeddedConfigFilenames = []string{PyprojectFile, PackageJSONFile}
+
+// ErrEmbeddedConfig is returned by commands that would have to rewrite a project config embedded in the file of another tool
+var ErrEmbeddedConfig = errors.New("the project config is embedded in the file of another tool, edit it manually or move it into a .envcli.yml")
+
+// IsEmbeddedConfig returns true if the project config is embedded in the file of another tool (pyproject.toml or package.json)
+func IsEmbeddedConfig(file string) bool {
+	name := filepath.Base(file)
+	for _, filename := range embeddedConfigFilenames {
+		if name == filename {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigSource describes where the project config is read from, ex. for envcli doctor
+func ConfigSource(file string) string {
+	switch filepath.Base(file) {
+	case PyprojectFile:
+		return file + " [tool.envcli]"
+	case PackageJSONFile:
+		return file + " (envcli key)"
+	}
+	return file
+}
+
+// findEmbeddedConfig returns the first file of the directory with a embedded project config, an empty string if there is none.
+// Files of other tools without a envcli section are ignored, a embedded config of lower precedence is reported.
+func findEmbeddedConfig(directory string) string {
+	var found string
+	for _, filename := range embeddedConfigFilenames {
+		file := filepath.Join(directory, filename)
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if _, ok, err := extractEmbeddedConfig(file, content); err != nil {
+			log.Debug().Err(err).Str("file", file).Msg("failed to parse the file, ignoring it as project config")
+			continue
+		} else if !ok {
+			continue
+		}
+
+		if found != "" {
+			log.Warn().Str("file", file).Str("used", found).Msg("ignoring the embedded envcli config, " + filepath.Base(found) + " takes precedence")
+			continue
+		}
+		found = file
+	}
+	return found
+}
+
+// extractEmbeddedConfig returns the envcli section of the file as yaml, ok is false if the file has no envcli section
+func extractEmbeddedConfig(file string, content []byte) (result []byte, ok bool, err error) {
+	var section interface{}
+	switch filepath.Base(file) {
+	case PyprojectFile:
+		var pyproject struct {
+			Tool map[string]interface{} `toml:"tool"`
+		}
+		if err = toml.Unmarshal(content, &pyproject); err != nil {
+			return nil, false, err
+		}
+		section, ok = pyproject.Tool["envcli"]
+	case PackageJSONFile:
+		var pkg map[string]interface{}
+		if err = json.Unmarshal(content, &pkg); err != nil {
+			return nil, false, err
+		}
+		section, ok = pkg["envcli"]
+	default:
+		return nil, false, fmt.Errorf("%s can't embed a envcli config", file)
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	if _, isMap := section.(map[string]interface{}); !isMap {
+		return nil, false, fmt.Errorf("the envcli section of %s must be a table / object", file)
+	}
+
+	result, err = yaml.Marshal(section)
+	return result, err == nil, err
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testPyproject = `[project]
+name = "app"
+
+[tool.black]
+line-length = 100
+
+[[tool.envcli.images]]
+name = "python"
+image = "docker.io/library/python:3.12"
+provides = ["python", "pip"]
+`
+
+const testPackageJSON = `{
+  "name": "app",
+  "version": "1.0.0",
+  "envcli": {
+    "images": [{"name": "node", "image": "docker.io/library/node:20", "provides": ["npm"]}]
+  }
+}`
+
+func TestFindProjectConfigEmbedded(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "src")
+	_ = os.MkdirAll(nested, os.ModePerm)
+
+	// files of other tools without a envcli section aren't project markers
+	if err := os.WriteFile(filepath.Join(nested, PackageJSONFile), []byte(`{"name": "lib"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, PackageJSONFile), []byte(testPackageJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	directory, file, err := FindProjectConfig(nested, defaultProjectConfigFilenames)
+	if err != nil || directory != root || file != filepath.Join(root, PackageJSONFile) {
+		t.Fatalf("expected the package.json of the root, got %s %s %v", directory, file, err)
+	}
+
+	// pyproject.toml takes precedence over package.json
+	if err = os.WriteFile(filepath.Join(root, PyprojectFile), []byte(testPyproject), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, file, _ = FindProjectConfig(nested, defaultProjectConfigFilenames); file != filepath.Join(root, PyprojectFile) {
+		t.Errorf("expected pyproject.toml to take precedence, got %s", file)
+	}
+
+	// a dedicated config file takes precedence over the embedded configs
+	writeFile(t, filepath.Join(root, ".envcli.yml"))
+	if _, file, _ = FindProjectConfig(nested, defaultProjectConfigFilenames); file != filepath.Join(root, ".envcli.yml") {
+		t.Errorf("expected .envcli.yml to take precedence, got %s", file)
+	}
+}
+
+func TestLoadProjectConfigEmbedded(t *testing.T) {
+	for filename, content := range map[string]string{PyprojectFile: testPyproject, PackageJSONFile: testPackageJSON} {
+		file := filepath.Join(t.TempDir(), filename)
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := LoadProjectConfig(file)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", filename, err)
+		}
+		if len(cfg.Images) != 1 || cfg.Images[0].Image == "" || len(cfg.Images[0].Provides) == 0 {
+			t.Errorf("%s: unexpected config %+v", filename, cfg)
+		}
+	}
+}
+
+func TestEmbeddedConfigChecksum(t *testing.T) {
+	file := filepath.Join(t.TempDir(), PackageJSONFile)
+	if err := os.WriteFile(file, []byte(testPackageJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := trustChecksum(ProjectHooks{ConfigFile: file})
+
+	// changes outside of the envcli section don't change the checksum
+	if err := os.WriteFile(file, []byte(`{"version": "1.1.0", "envcli": {"images": [{"name": "node", "image": "docker.io/library/node:20", "provides": ["npm"]}]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := trustChecksum(ProjectHooks{ConfigFile: file}); after != before {
+		t.Errorf("expected the checksum to only cover the envcli section")
+	}
+}
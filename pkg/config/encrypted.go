@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/EnvCLI/EnvCLI/pkg/encryption"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/rs/zerolog/log"
+)
+
+// KeyFileEnv is the environment variable pointing to the age identity file, it takes precedence over the config-key-file property
+const KeyFileEnv = "ENVCLI_KEY_FILE"
+
+// EncryptedConfigsSkip is the value of the encrypted-configs property to skip encrypted config files instead of decrypting them
+const EncryptedConfigsSkip = "skip"
+
+// EncryptedConfigError is returned if a encrypted config file can't be decrypted, unlike other load errors it stops loading the configuration
+type EncryptedConfigError struct {
+	File string
+	Err  error
+}
+
+func (e *EncryptedConfigError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *EncryptedConfigError) Unwrap() error {
+	return e.Err
+}
+
+// GetKeyFile returns the age identity file used to decrypt the config files, empty if none is configured
+func GetKeyFile(propConfig PropertyConfigurationFile) string {
+	if file := os.Getenv(KeyFileEnv); file != "" {
+		return file
+	}
+	return collection.MapGetValueOrDefault(propConfig.Properties, "config-key-file", "")
+}
+
+// LoadIdentities loads the age identities of the key file
+func LoadIdentities(keyFile string) ([]encryption.Identity, error) {
+	content, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := encryption.ParseIdentities(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the key file %s: %w", keyFile, err)
+	}
+	return identities, nil
+}
+
+// decryptConfig returns the decrypted content of a encrypted config file, plain config files are returned unchanged.
+// Returns false if the file is skipped, because the encrypted-configs property is set to skip.
+func decryptConfig(configFile string, content []byte) ([]byte, bool, error) {
+	if !encryption.IsEncrypted(content) {
+		return content, true, nil
+	}
+
+	propConfig, _ := LoadPropertyConfig()
+	if collection.MapGetValueOrDefault(propConfig.Properties, "encrypted-configs", "") == EncryptedConfigsSkip {
+		log.Warn().Str("file", configFile).Msg("skipping the encrypted config file, as the encrypted-configs property is set to skip")
+		return nil, false, nil
+	}
+
+	keyFile := GetKeyFile(propConfig)
+	if keyFile == "" {
+		return nil, false, &EncryptedConfigError{File: configFile, Err: errors.New(configFile + " is encrypted, set " + KeyFileEnv + " or the config-key-file property to a age identity file to decrypt it, or set the encrypted-configs property to skip")}
+	}
+	identities, err := LoadIdentities(keyFile)
+	if err != nil {
+		return nil, false, &EncryptedConfigError{File: configFile, Err: fmt.Errorf("%s is encrypted, but the key file can't be loaded: %w", configFile, err)}
+	}
+
+	decrypted, err := encryption.Decrypt(content, identities...)
+	if err != nil {
+		return nil, false, &EncryptedConfigError{File: configFile, Err: fmt.Errorf("failed to decrypt %s using the key file %s: %w", configFile, keyFile, err)}
+	}
+	log.Debug().Str("file", configFile).Msg("decrypted the config file")
+	return decrypted, true, nil
+}
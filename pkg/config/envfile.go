@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// envFileKeyPattern matches the variable names of env files
+var envFileKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// ResolveEnvFilePath resolves the path of a env file relative to the configuration file that defined it
+func ResolveEnvFilePath(path string, source string) string {
+	if filepath.IsAbs(path) || source == "" {
+		return path
+	}
+	return filepath.Join(filepath.Dir(source), path)
+}
+
+// ValidateEnvFiles returns a error if a envFile entry has no path
+func ValidateEnvFiles(files []EnvFileEntry) error {
+	for i, file := range files {
+		if strings.TrimSpace(file.Path) == "" {
+			return fmt.Errorf("envFile[%d] has no path", i)
+		}
+	}
+	return nil
+}
+
+// LoadEnvFile reads the variables (NAME=value) of the env file in order of their definition.
+// A missing optional file has no variables, a missing required file is a error.
+func LoadEnvFile(file string, optional bool) ([]string, error) {
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) && optional {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read env file %s: %w", file, err)
+	}
+
+	env, err := ParseEnvFile(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("invalid env file %s: %w", file, err)
+	}
+	return env, nil
+}
+
+// ParseEnvFile parses the dotenv syntax and returns the variables (NAME=value) in order of their definition.
+// Lines can start with export, # starts a comment. Double quoted values support the escapes \n, \r, \t, \" and \\,
+// single quoted values are taken literally, both can span multiple lines. Variables within the values are not expanded.
+func ParseEnvFile(content string) ([]string, error) {
+	var env []string
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	lines := strings.Split(content, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || !envFileKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("line %d: expected NAME=value, got %q", lineNumber, line)
+		}
+		value = strings.TrimLeft(value, " \t")
+
+		if value == "" || (value[0] != '"' && value[0] != '\'') {
+			// unquoted, a # preceded by whitespace starts a comment
+			if index := strings.Index(value, " #"); index >= 0 {
+				value = value[:index]
+			} else if index = strings.Index(value, "\t#"); index >= 0 {
+				value = value[:index]
+			}
+			env = append(env, key+"="+strings.TrimSpace(value))
+			continue
+		}
+
+		// quoted, the value continues on the following lines until the closing quote
+		quote := value[0]
+		rest := value[1:]
+		var result strings.Builder
+		for {
+			end, parsed := scanQuoted(rest, quote)
+			result.WriteString(parsed)
+			if end >= 0 {
+				if trailing := strings.TrimSpace(rest[end+1:]); trailing != "" && !strings.HasPrefix(trailing, "#") {
+					return nil, fmt.Errorf("line %d: unexpected characters after the closing quote of %s", i+1, key)
+				}
+				break
+			}
+			if i+1 >= len(lines) {
+				return nil, fmt.Errorf("line %d: missing closing quote of %s", lineNumber, key)
+			}
+			result.WriteString("\n")
+			i++
+			rest = lines[i]
+		}
+		env = append(env, key+"="+result.String())
+	}
+
+	return env, nil
+}
+
+// scanQuoted returns the index of the closing quote within the line (-1 if the value continues) and the unescaped value up to it
+func scanQuoted(line string, quote byte) (int, string) {
+	var result strings.Builder
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == quote {
+			return i, result.String()
+		}
+		if quote == '"' && c == '\\' && i+1 < len(line) {
+			i++
+			switch line[i] {
+			case 'n':
+				result.WriteByte('\n')
+			case 'r':
+				result.WriteByte('\r')
+			case 't':
+				result.WriteByte('\t')
+			case '"', '\\':
+				result.WriteByte(line[i])
+			default:
+				result.WriteByte('\\')
+				result.WriteByte(line[i])
+			}
+			continue
+		}
+		result.WriteByte(c)
+	}
+	return -1, result.String()
+}
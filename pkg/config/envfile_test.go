@@ -0,0 +1,76 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	content := strings.Join([]string{
+		"# comment",
+		"",
+		"PLAIN=value",
+		"export EXPORTED=yes",
+		"SPACED = trimmed  ",
+		"COMMENTED=value # comment",
+		"HASH=a#b",
+		"EMPTY=",
+		`DOUBLE="line1\nline2 \"quoted\" # kept"`,
+		`SINGLE='literal \n $HOME'`,
+		`MULTI="first`,
+		`second" # comment`,
+		"CRLF=value\r",
+	}, "\n")
+
+	expected := []string{
+		"PLAIN=value",
+		"EXPORTED=yes",
+		"SPACED=trimmed",
+		"COMMENTED=value",
+		"HASH=a#b",
+		"EMPTY=",
+		"DOUBLE=line1\nline2 \"quoted\" # kept",
+		`SINGLE=literal \n $HOME`,
+		"MULTI=first\nsecond",
+		"CRLF=value",
+	}
+	env, err := ParseEnvFile(content)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("expected %q, got %q", expected, env)
+	}
+}
+
+func TestParseEnvFileInvalid(t *testing.T) {
+	var tests = []struct {
+		content string
+		message string
+	}{
+		{"VALID=1\nINVALID", "line 2"},
+		{"1NAME=value", "line 1"},
+		{"OPEN=\"value\nNEXT=1", "missing closing quote of OPEN"},
+		{"TRAILING='value' rest", "after the closing quote"},
+	}
+
+	for _, test := range tests {
+		if _, err := ParseEnvFile(test.content); err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("ParseEnvFile(%q): expected error containing %q, got %v", test.content, test.message, err)
+		}
+	}
+}
+
+func TestLoadEnvFileMissing(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".env")
+	if env, err := LoadEnvFile(file, true); err != nil || env != nil {
+		t.Errorf("expected no variables for a missing optional file, got %v (%v)", env, err)
+	}
+	if _, err := LoadEnvFile(file, false); err == nil || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected a error for a missing required file, got %v", err)
+	}
+}
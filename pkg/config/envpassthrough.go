@@ -0,0 +1,54 @@
+package config
+
+import (
+	"errors"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ValidateEnvPassthrough returns a error if a pattern of envPassthrough is invalid
+func ValidateEnvPassthrough(patterns []string) error {
+	for _, pattern := range patterns {
+		name := strings.TrimPrefix(pattern, "!")
+		if name == "" {
+			return errors.New("invalid envPassthrough pattern " + pattern + ", expected a variable name or glob (ex. AWS_*)")
+		}
+		if _, err := path.Match(name, ""); err != nil {
+			return errors.New("invalid envPassthrough pattern " + pattern + ": " + err.Error())
+		}
+	}
+	return nil
+}
+
+// ExpandEnvPassthrough returns the sorted names of the host environment variables matching the patterns.
+// Patterns are globs (ex. AWS_*), a pattern prefixed with ! excludes the matching variables, regardless of the order of the patterns.
+func ExpandEnvPassthrough(patterns []string, environ []string) []string {
+	var include, exclude []string
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			exclude = append(exclude, pattern[1:])
+		} else {
+			include = append(include, pattern)
+		}
+	}
+
+	var names []string
+	for _, variable := range environ {
+		name, _, _ := strings.Cut(variable, "=")
+		if name != "" && matchesAny(include, name) && !matchesAny(exclude, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
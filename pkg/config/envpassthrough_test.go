@@ -0,0 +1,47 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandEnvPassthrough(t *testing.T) {
+	environ := []string{
+		"AWS_REGION=eu-central-1",
+		"AWS_ACCESS_KEY_ID=id",
+		"AWS_SECRET_ACCESS_KEY=secret",
+		"TF_VAR_name=value",
+		"TF_VAR_empty=",
+		"HOME=/root",
+		"=C:=C:\\",
+	}
+
+	var tests = []struct {
+		patterns []string
+		expected []string
+	}{
+		{nil, nil},
+		{[]string{"HOME"}, []string{"HOME"}},
+		{[]string{"AWS_*", "TF_VAR_*", "!AWS_SECRET_ACCESS_KEY"}, []string{"AWS_ACCESS_KEY_ID", "AWS_REGION", "TF_VAR_empty", "TF_VAR_name"}},
+		{[]string{"!AWS_SECRET_*", "AWS_*"}, []string{"AWS_ACCESS_KEY_ID", "AWS_REGION"}},
+		{[]string{"MISSING_*"}, nil},
+	}
+
+	for _, test := range tests {
+		result := ExpandEnvPassthrough(test.patterns, environ)
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("ExpandEnvPassthrough(%v): expected %v, got %v", test.patterns, test.expected, result)
+		}
+	}
+}
+
+func TestValidateEnvPassthrough(t *testing.T) {
+	if err := ValidateEnvPassthrough([]string{"AWS_*", "!AWS_SECRET_ACCESS_KEY", "TF_VAR_?"}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	for _, pattern := range []string{"", "!", "AWS_[", "!["} {
+		if err := ValidateEnvPassthrough([]string{pattern}); err == nil {
+			t.Errorf("expected a error for %q", pattern)
+		}
+	}
+}
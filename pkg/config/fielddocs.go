@@ -0,0 +1,66 @@
+package config
+
+import (
+	"embed"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"sync"
+)
+
+// typeSources are the files defining the structs of the configuration file, their comments document the fields
+//
+//go:embed types.go credentials.go signature.go
+var typeSources embed.FS
+
+var fieldDocs map[string]string
+var fieldDocsOnce sync.Once
+
+// FieldDocs returns the doc comments of the configuration struct fields, keyed by struct and field name (ex. RunConfigurationEntry.Image)
+func FieldDocs() map[string]string {
+	fieldDocsOnce.Do(func() {
+		fieldDocs = make(map[string]string)
+
+		files, _ := typeSources.ReadDir(".")
+		for _, file := range files {
+			content, err := typeSources.ReadFile(file.Name())
+			if err != nil {
+				continue
+			}
+			parsed, err := parser.ParseFile(token.NewFileSet(), file.Name(), content, parser.ParseComments)
+			if err != nil {
+				continue
+			}
+
+			ast.Inspect(parsed, func(node ast.Node) bool {
+				spec, ok := node.(*ast.TypeSpec)
+				if !ok {
+					return true
+				}
+				if structType, isStruct := spec.Type.(*ast.StructType); isStruct {
+					for _, field := range structType.Fields.List {
+						for _, name := range field.Names {
+							fieldDocs[spec.Name.Name+"."+name.Name] = cleanDocComment(field.Doc.Text())
+						}
+					}
+				}
+				return false
+			})
+		}
+	})
+
+	return fieldDocs
+}
+
+// cleanDocComment joins the lines of the comment, the leading stars of block comments are removed
+func cleanDocComment(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
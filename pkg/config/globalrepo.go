@@ -0,0 +1,263 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultGlobalRepoSyncInterval is the interval of the automatic syncs of the global-configuration-repo
+const DefaultGlobalRepoSyncInterval = time.Hour
+
+// GlobalRepoSyncTimeout limits the automatic syncs, a unreachable git server must not block the commands
+const GlobalRepoSyncTimeout = 10 * time.Second
+
+// GlobalRepoRetryDelay is the delay before a failed automatic sync is retried, doubled for each following failure up to the sync interval
+const GlobalRepoRetryDelay = time.Minute
+
+// ErrGlobalConfigReadOnly is returned when changing the global configuration, while it is synced from the global-configuration-repo
+var ErrGlobalConfigReadOnly = errors.New("the global configuration is synced from the global-configuration-repo property and is read-only, change it within the repository")
+
+// GlobalRepo is the git repository the global configuration is synced from, set using the global-configuration-repo property (url or url#ref)
+type GlobalRepo struct {
+	URL string
+
+	// Ref is the branch, tag or commit, the default branch of the repository if empty
+	Ref string
+
+	// Directory is the local copy of the repository within the cache
+	Directory string
+}
+
+// GetGlobalRepo returns the repository of the global-configuration-repo property, false if it isn't set
+func GetGlobalRepo(propConfig PropertyConfigurationFile) (GlobalRepo, bool) {
+	value := collection.MapGetValueOrDefault(propConfig.Properties, "global-configuration-repo", "")
+	if value == "" {
+		return GlobalRepo{}, false
+	}
+
+	repo := GlobalRepo{URL: value}
+	if idx := strings.LastIndex(value, "#"); idx >= 0 {
+		repo.URL, repo.Ref = value[:idx], value[idx+1:]
+	}
+	sum := sha256.Sum256([]byte(value))
+	repo.Directory = filepath.Join(getCacheSubdirectory(propConfig, "global-config"), hex.EncodeToString(sum[:8]))
+	return repo, true
+}
+
+// ConfigFile returns the global configuration file within the local copy
+func (r GlobalRepo) ConfigFile() string {
+	return filepath.Join(r.Directory, ".envcli.yml")
+}
+
+// String returns the url and the ref
+func (r GlobalRepo) String() string {
+	if r.Ref == "" {
+		return r.URL
+	}
+	return r.URL + " (" + r.Ref + ")"
+}
+
+// syncMarker is touched after each successful sync
+func (r GlobalRepo) syncMarker() string {
+	return filepath.Join(r.Directory, ".git", "envcli-synced")
+}
+
+// LastSync returns the time of the last successful sync, zero if the repository has never been synced
+func (r GlobalRepo) LastSync() time.Time {
+	info, err := os.Stat(r.syncMarker())
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// attemptMarker records the failed automatic syncs, it is kept next to the local copy as the copy may not exist yet
+func (r GlobalRepo) attemptMarker() string {
+	return r.Directory + ".attempt"
+}
+
+// LastFailedAttempt returns the time of the last failed sync and the number of consecutive failures, zero if the last sync succeeded
+func (r GlobalRepo) LastFailedAttempt() (time.Time, int) {
+	info, err := os.Stat(r.attemptMarker())
+	if err != nil {
+		return time.Time{}, 0
+	}
+	content, err := os.ReadFile(r.attemptMarker())
+	if err != nil {
+		return time.Time{}, 0
+	}
+	failures, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil || failures < 1 {
+		failures = 1
+	}
+	return info.ModTime(), failures
+}
+
+// recordAttempt records a sync attempt as failure before it starts, so that a attempt that never finishes (ex. killed) counts as well
+func (r GlobalRepo) recordAttempt(failures int) error {
+	if err := os.MkdirAll(filepath.Dir(r.attemptMarker()), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(r.attemptMarker(), []byte(strconv.Itoa(failures)+"\n"), 0644)
+}
+
+// Sync fetches the ref (only the latest commit) and checks it out, the local copy is created on the first sync.
+// Returns the short hash of the checked out commit.
+func (r GlobalRepo) Sync(ctx context.Context) (string, error) {
+	if _, err := os.Stat(filepath.Join(r.Directory, ".git")); err != nil {
+		if err = os.MkdirAll(r.Directory, os.ModePerm); err != nil {
+			return "", err
+		}
+		if _, err = r.git(ctx, "init", "--quiet"); err != nil {
+			return "", err
+		}
+		if _, err = r.git(ctx, "remote", "add", "origin", r.URL); err != nil {
+			return "", err
+		}
+	}
+
+	ref := r.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if _, err := r.git(ctx, "fetch", "--quiet", "--depth", "1", "origin", ref); err != nil {
+		return "", err
+	}
+	if _, err := r.git(ctx, "checkout", "--quiet", "--force", "--detach", "FETCH_HEAD"); err != nil {
+		return "", err
+	}
+	commit, err := r.git(ctx, "rev-parse", "--short", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	if err = os.WriteFile(r.syncMarker(), []byte(now.Format(time.RFC3339)+"\n"), 0644); err != nil {
+		return "", err
+	}
+	if err = os.Remove(r.attemptMarker()); err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	return commit, os.Chtimes(r.syncMarker(), now, now)
+}
+
+// git runs the git command within the local copy and returns the trimmed output, credential prompts are disabled so that runs never block on a sync
+func (r GlobalRepo) git(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.Directory
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// syncRetryDelay returns the delay before the next attempt after the consecutive failed syncs, it doubles for each failure up to the sync interval
+func syncRetryDelay(failures int, interval time.Duration) time.Duration {
+	delay := GlobalRepoRetryDelay
+	for i := 1; i < failures && delay < interval; i++ {
+		delay *= 2
+	}
+	if delay > interval {
+		return interval
+	}
+	return delay
+}
+
+// syncGlobalRepo syncs the global-configuration-repo once the global-configuration-sync-interval elapsed since the last sync.
+// If the sync fails (ex. offline), the last synced copy is used and the sync is retried after a delay, see syncRetryDelay.
+// The sync is limited to GlobalRepoSyncTimeout and stops once the context is cancelled.
+func syncGlobalRepo(ctx context.Context, propConfig PropertyConfigurationFile) {
+	repo, configured := GetGlobalRepo(propConfig)
+	if !configured {
+		return
+	}
+
+	interval := DefaultGlobalRepoSyncInterval
+	if value, err := time.ParseDuration(collection.MapGetValueOrDefault(propConfig.Properties, "global-configuration-sync-interval", "")); err == nil {
+		interval = value
+	}
+	lastSync := repo.LastSync()
+	if !lastSync.IsZero() && time.Since(lastSync) < interval {
+		return
+	}
+	lastAttempt, failures := repo.LastFailedAttempt()
+	if failures > 0 && time.Since(lastAttempt) < syncRetryDelay(failures, interval) {
+		log.Debug().Str("repo", repo.String()).Time("lastAttempt", lastAttempt).Int("failures", failures).Msg("the last sync of the global configuration failed, waiting before the next attempt")
+		return
+	}
+	if err := repo.recordAttempt(failures + 1); err != nil {
+		log.Debug().Err(err).Str("repo", repo.String()).Msg("failed to record the sync attempt")
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, GlobalRepoSyncTimeout)
+	defer cancel()
+	commit, err := repo.Sync(syncCtx)
+	if err != nil && lastSync.IsZero() {
+		log.Warn().Err(err).Str("repo", repo.String()).Msg("failed to sync the global configuration, it isn't available until the repository can be synced (envcli config sync)")
+	} else if err != nil {
+		log.Warn().Err(err).Str("repo", repo.String()).Time("lastSync", lastSync).Msg("failed to sync the global configuration, using the last synced copy")
+	} else {
+		log.Debug().Str("repo", repo.String()).Str("commit", commit).Msg("synced the global configuration")
+	}
+}
+
+// EntryChanges are the entries added, removed or changed between two versions of a configuration file, by name
+type EntryChanges struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// IsEmpty returns true if no entry changed
+func (c EntryChanges) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// DiffEntries compares the entries of two versions of a configuration file by name
+func DiffEntries(before []RunConfigurationEntry, after []RunConfigurationEntry) EntryChanges {
+	previous := make(map[string]RunConfigurationEntry)
+	for _, entry := range before {
+		previous[entry.Name] = entry
+	}
+
+	var changes EntryChanges
+	current := make(map[string]bool)
+	for _, entry := range after {
+		current[entry.Name] = true
+		old, found := previous[entry.Name]
+		if !found {
+			changes.Added = append(changes.Added, entry.Name)
+		} else if !reflect.DeepEqual(old, entry) {
+			changes.Changed = append(changes.Changed, entry.Name)
+		}
+	}
+	for _, entry := range before {
+		if !current[entry.Name] {
+			changes.Removed = append(changes.Removed, entry.Name)
+		}
+	}
+
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Changed)
+	return changes
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// commitFile commits the file to the git repository in dir, the repository is created if needed
+func commitFile(t *testing.T, dir string, name string, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", name},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "update " + name},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func TestGlobalRepoSync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	source := t.TempDir()
+	commitFile(t, source, ".envcli.yml", "images:\n  - name: node\n    image: node:20\n    provides: [node]\n")
+
+	props := PropertyConfigurationFile{Properties: map[string]string{
+		"cache-path":                         t.TempDir(),
+		"global-configuration-repo":          source,
+		"global-configuration-sync-interval": "0s",
+	}}
+	repo, configured := GetGlobalRepo(props)
+	if !configured || repo.URL != source || repo.Ref != "" {
+		t.Fatalf("unexpected repo %+v", repo)
+	}
+	if GetGlobalConfigurationFile(props) != repo.ConfigFile() {
+		t.Errorf("expected the global config to be read from the local copy, got %s", GetGlobalConfigurationFile(props))
+	}
+
+	syncGlobalRepo(context.Background(), props)
+	if repo.LastSync().IsZero() {
+		t.Fatal("expected the repository to be synced")
+	}
+	cfg, err := LoadProjectConfig(repo.ConfigFile())
+	if err != nil || len(cfg.Images) != 1 || cfg.Images[0].Image != "node:20" {
+		t.Fatalf("unexpected synced config %+v (%v)", cfg, err)
+	}
+
+	// offline, the last synced copy stays in use
+	if err = os.RemoveAll(source); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.Sync(context.Background()); err == nil {
+		t.Errorf("expected the sync of a missing repository to fail")
+	}
+	syncGlobalRepo(context.Background(), props)
+	if _, err = LoadProjectConfig(repo.ConfigFile()); err != nil {
+		t.Errorf("expected the last synced copy to be kept, got %v", err)
+	}
+
+	// the failed attempt is recorded, the next attempt waits for the retry delay
+	lastAttempt, failures := repo.LastFailedAttempt()
+	if lastAttempt.IsZero() || failures != 1 {
+		t.Fatalf("expected one failed attempt, got %v %d", lastAttempt, failures)
+	}
+	props.Properties["global-configuration-sync-interval"] = "1h"
+	outdated := time.Now().Add(-2 * time.Hour)
+	if err = os.Chtimes(repo.syncMarker(), outdated, outdated); err != nil {
+		t.Fatal(err)
+	}
+	syncGlobalRepo(context.Background(), props)
+	if _, failures = repo.LastFailedAttempt(); failures != 1 {
+		t.Errorf("expected the sync to wait for the retry delay, got %d failed attempts", failures)
+	}
+}
+
+func TestSyncRetryDelay(t *testing.T) {
+	for _, test := range []struct {
+		failures int
+		interval time.Duration
+		expected time.Duration
+	}{
+		{1, time.Hour, time.Minute},
+		{2, time.Hour, 2 * time.Minute},
+		{4, time.Hour, 8 * time.Minute},
+		{10, time.Hour, time.Hour},
+		{1, 30 * time.Second, 30 * time.Second},
+	} {
+		if delay := syncRetryDelay(test.failures, test.interval); delay != test.expected {
+			t.Errorf("expected %s after %d failures, got %s", test.expected, test.failures, delay)
+		}
+	}
+}
+
+func TestGetGlobalRepoRef(t *testing.T) {
+	repo, configured := GetGlobalRepo(PropertyConfigurationFile{Properties: map[string]string{
+		"global-configuration-repo": "https://git.company.com/platform/envcli-config.git#v2",
+	}})
+	if !configured || repo.URL != "https://git.company.com/platform/envcli-config.git" || repo.Ref != "v2" {
+		t.Errorf("unexpected repo %+v", repo)
+	}
+	if _, configured = GetGlobalRepo(PropertyConfigurationFile{}); configured {
+		t.Errorf("expected no repo without the property")
+	}
+}
+
+func TestDiffEntries(t *testing.T) {
+	before := []RunConfigurationEntry{{Name: "node", Image: "node:18"}, {Name: "ruby", Image: "ruby"}, {Name: "go", Image: "golang"}}
+	after := []RunConfigurationEntry{{Name: "node", Image: "node:20"}, {Name: "python", Image: "python"}, {Name: "go", Image: "golang"}}
+
+	changes := DiffEntries(before, after)
+	expected := EntryChanges{Added: []string{"python"}, Removed: []string{"ruby"}, Changed: []string{"node"}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected %+v, got %+v", expected, changes)
+	}
+	if !DiffEntries(after, after).IsEmpty() {
+		t.Errorf("expected no changes for the same entries")
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"path/filepath"
+
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+)
+
+// ProjectHooks are the hooks defined in the project config
+type ProjectHooks struct {
+	HooksConfiguration
+
+	// the project config defining the hooks, hooks are only executed if it is trusted
+	ConfigFile string
+
+	// the project directory, hooks are executed within it
+	Directory string
+}
+
+// IsEmpty returns true if no hook is configured
+func (h HooksConfiguration) IsEmpty() bool {
+	return h.PreRun == "" && h.PostRun == ""
+}
+
+// GetProjectHooks returns the hooks of the nearest project config, without a project config no hooks are returned
+func GetProjectHooks() (ProjectHooks, error) {
+	return GetProjectHooksIn(filesystem.GetWorkingDirectory())
+}
+
+// GetProjectHooksIn returns the hooks of the project config nearest to the directory
+func GetProjectHooksIn(workingDirectory string) (ProjectHooks, error) {
+	directory, file, err := FindProjectConfig(workingDirectory, GetProjectConfigFilenames())
+	if err != nil {
+		return ProjectHooks{}, nil
+	}
+
+	cfg, err := LoadProjectConfig(file)
+	if err != nil {
+		return ProjectHooks{}, err
+	}
+
+	return ProjectHooks{HooksConfiguration: cfg.Hooks, ConfigFile: filepath.Clean(file), Directory: directory}, nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+)
+
+// defaultRegistry is used for image references that don't specify a registry
+const defaultRegistry = "docker.io"
+
+// ImageReference holds the parts of a container image reference
+type ImageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImageReference splits a image reference like quay.io/cidverse/build-go:1.20 into its parts
+func ParseImageReference(image string) ImageReference {
+	var ref ImageReference
+
+	// digest
+	if idx := strings.Index(image, "@"); idx >= 0 {
+		ref.Digest = image[idx+1:]
+		image = image[:idx]
+	}
+
+	// registry, the first component is a registry if it contains a dot or port or is localhost
+	parts := strings.SplitN(image, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Registry = parts[0]
+		image = parts[1]
+	} else {
+		ref.Registry = defaultRegistry
+	}
+
+	// tag
+	if idx := strings.LastIndex(image, ":"); idx >= 0 {
+		ref.Tag = image[idx+1:]
+		image = image[:idx]
+	} else if ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	ref.Repository = image
+
+	return ref
+}
+
+// FullRepository returns the registry and repository, official docker hub images are prefixed with library (docker.io/library/alpine)
+func (r ImageReference) FullRepository() string {
+	if r.Registry == defaultRegistry && !strings.Contains(r.Repository, "/") {
+		return r.Registry + "/library/" + r.Repository
+	}
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the fully qualified reference, ex. docker.io/library/alpine:3.19 for alpine:3.19
+func (r ImageReference) String() string {
+	reference := r.FullRepository()
+	if r.Tag != "" {
+		reference += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		reference += "@" + r.Digest
+	}
+	return reference
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"context"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/download"
+)
+
+// IsRemoteInclude returns true if the include is a http(s) url
+func IsRemoteInclude(include string) bool {
+	return strings.HasPrefix(include, "http://") || strings.HasPrefix(include, "https://")
+}
+
+// SplitRemoteInclude splits a remote include into the url and the optional checksum, provided as #sha256=<hex> suffix
+func SplitRemoteInclude(include string) (string, string) {
+	if idx := strings.LastIndex(include, "#sha256="); idx >= 0 {
+		return include[:idx], include[idx+len("#sha256="):]
+	}
+	return include, ""
+}
+
+// DownloadRemoteInclude downloads a remote include into the download cache and returns the local file
+func DownloadRemoteInclude(ctx context.Context, include string, propConfig PropertyConfigurationFile) (string, error) {
+	url, checksum := SplitRemoteInclude(include)
+	return download.New(GetDownloadCacheDirectory(propConfig)).Download(ctx, url, checksum)
+}
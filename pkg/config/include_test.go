@@ -0,0 +1,21 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestSplitRemoteInclude(t *testing.T) {
+	url, checksum := SplitRemoteInclude("https://example.com/envcli.yml#sha256=abc123")
+	if url != "https://example.com/envcli.yml" || checksum != "abc123" {
+		t.Errorf("unexpected result %s %s", url, checksum)
+	}
+
+	url, checksum = SplitRemoteInclude("https://example.com/envcli.yml")
+	if url != "https://example.com/envcli.yml" || checksum != "" {
+		t.Errorf("unexpected result %s %s", url, checksum)
+	}
+
+	if !IsRemoteInclude("https://example.com/envcli.yml") || IsRemoteInclude("/etc/envcli.yml") {
+		t.Errorf("unexpected remote include detection")
+	}
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"errors"
+	"strings"
+)
+
+// ResolveImageInheritance applies the extends field of all image entries, an entry inherits all fields of the referenced entry and overrides the fields it sets itself.
+// An entry can extend another entry with the same name (ex. to customize a global entry), the entry itself is never used as its own parent.
+func ResolveImageInheritance(images []RunConfigurationEntry) ([]RunConfigurationEntry, error) {
+	resolved := append([]RunConfigurationEntry{}, images...)
+	done := make([]bool, len(images))
+
+	var resolve func(index int, chain []int) error
+	resolve = func(index int, chain []int) error {
+		if done[index] {
+			return nil
+		}
+		for i, chainIndex := range chain {
+			if chainIndex == index {
+				return errors.New("image entry " + images[index].Name + " has a cyclic extends: " + inheritanceChainString(images, append(chain[i:], index)))
+			}
+		}
+
+		entry := resolved[index]
+		if entry.Extends != "" {
+			parentIndex := findInheritanceParent(images, entry.Extends, index)
+			if parentIndex < 0 {
+				return errors.New("image entry " + entry.Name + " extends the unknown entry " + entry.Extends)
+			}
+			if err := resolve(parentIndex, append(chain, index)); err != nil {
+				return err
+			}
+			resolved[index] = inheritEntry(resolved[parentIndex], entry)
+		}
+
+		done[index] = true
+		return nil
+	}
+
+	for i := range images {
+		if err := resolve(i, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
+
+// findInheritanceParent returns the index of the first entry with the name, excluding the entry itself
+func findInheritanceParent(images []RunConfigurationEntry, name string, self int) int {
+	for i, image := range images {
+		if i != self && image.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func inheritanceChainString(images []RunConfigurationEntry, chain []int) string {
+	var names []string
+	for _, index := range chain {
+		names = append(names, images[index].Name)
+	}
+	return strings.Join(names, " -> ")
+}
+
+// inheritEntry returns the parent entry overridden by all fields the child sets
+func inheritEntry(parent RunConfigurationEntry, child RunConfigurationEntry) RunConfigurationEntry {
+	result := parent
+	result.Name = child.Name
+	result.Extends = child.Extends
+	result.When = child.When
+	result.Scope = child.Scope
+	result.Source = child.Source
+
+	if child.Description != "" {
+		result.Description = child.Description
+	}
+	if child.DocsURL != "" {
+		result.DocsURL = child.DocsURL
+	}
+	if child.Image != "" || child.Build.Dockerfile != "" {
+		result.Image = child.Image
+		result.Build = child.Build
+	}
+	if child.ImageMirror != "" {
+		result.ImageMirror = child.ImageMirror
+	}
+	if child.Passthrough != "" {
+		result.Passthrough = child.Passthrough
+	}
+	if child.Directory != "" {
+		result.Directory = child.Directory
+	}
+	if child.Entrypoint != "" {
+		result.Entrypoint = child.Entrypoint
+	}
+	if child.Shell != "" {
+		result.Shell = child.Shell
+	}
+	if child.Caching != nil {
+		result.Caching = child.Caching
+	}
+	if child.Warmup != "" {
+		result.Warmup = child.Warmup
+	}
+	if child.Retries != 0 {
+		result.Retries = child.Retries
+	}
+	if child.RetryDelay != "" {
+		result.RetryDelay = child.RetryDelay
+	}
+	if child.Priority != "" {
+		result.Priority = child.Priority
+	}
+	if child.CPUShares != 0 {
+		result.CPUShares = child.CPUShares
+	}
+	if child.BlkioWeight != 0 {
+		result.BlkioWeight = child.BlkioWeight
+	}
+	if child.Init != nil {
+		result.Init = child.Init
+	}
+	if child.Readiness != nil {
+		result.Readiness = child.Readiness
+	}
+	if child.RetryOnExitCodes != nil {
+		result.RetryOnExitCodes = child.RetryOnExitCodes
+	}
+	result.Examples = inheritList(parent.Examples, child.Examples)
+	result.Provides = inheritList(parent.Provides, child.Provides)
+	result.BeforeScript = inheritList(parent.BeforeScript, child.BeforeScript)
+	result.ExtraPackages = inheritList(parent.ExtraPackages, child.ExtraPackages)
+	result.CapAdd = inheritList(parent.CapAdd, child.CapAdd)
+	result.Env = inheritList(parent.Env, child.Env)
+	if child.EnvFile != nil {
+		result.EnvFile = child.EnvFile
+	} else {
+		// the inherited paths stay relative to the configuration file of the parent
+		result.EnvFile = nil
+		for _, file := range parent.EnvFile {
+			result.EnvFile = append(result.EnvFile, EnvFileEntry{Path: ResolveEnvFilePath(file.Path, parent.Source), Optional: file.Optional})
+		}
+	}
+	result.EnvPassthrough = inheritList(parent.EnvPassthrough, child.EnvPassthrough)
+	result.CredentialHelpers = inheritList(parent.CredentialHelpers, child.CredentialHelpers)
+	result.SecurityOpt = inheritList(parent.SecurityOpt, child.SecurityOpt)
+	result.TmpDirs = inheritList(parent.TmpDirs, child.TmpDirs)
+	if child.Variants != nil {
+		result.Variants = child.Variants
+	}
+	if child.ArgPosition != "" {
+		result.ArgPosition = child.ArgPosition
+	}
+	if child.DefaultArgs != nil {
+		result.DefaultArgs = make(map[string][]string)
+		for command, args := range parent.DefaultArgs {
+			result.DefaultArgs[command] = args
+		}
+		for command, args := range child.DefaultArgs {
+			result.DefaultArgs[command] = args
+		}
+	}
+	if child.Ulimits != nil {
+		result.Ulimits = make(map[string]string)
+		for name, value := range parent.Ulimits {
+			result.Ulimits[name] = value
+		}
+		for name, value := range child.Ulimits {
+			result.Ulimits[name] = value
+		}
+	}
+	if child.RequiresRuntime != nil {
+		result.RequiresRuntime = make(map[string]string)
+		for runtime, constraint := range parent.RequiresRuntime {
+			result.RequiresRuntime[runtime] = constraint
+		}
+		for runtime, constraint := range child.RequiresRuntime {
+			result.RequiresRuntime[runtime] = constraint
+		}
+	}
+	result.ContainerRuntimeAccess = parent.ContainerRuntimeAccess || child.ContainerRuntimeAccess
+	result.ForwardGitConfig = parent.ForwardGitConfig || child.ForwardGitConfig
+	result.ForwardSSHAgent = parent.ForwardSSHAgent || child.ForwardSSHAgent
+	result.SSHAgentRequired = parent.SSHAgentRequired || child.SSHAgentRequired
+	result.KeepOnFailure = parent.KeepOnFailure || child.KeepOnFailure
+	result.RewritePaths = parent.RewritePaths || child.RewritePaths
+	result.LoginShell = parent.LoginShell || child.LoginShell
+	result.WarmupRequired = parent.WarmupRequired || child.WarmupRequired
+
+	return result
+}
+
+// inheritList replaces the parent list, unless the first item of the child list starts with + - then all items are appended (without the + prefix)
+func inheritList(parent []string, child []string) []string {
+	if child == nil {
+		return parent
+	}
+	if len(child) == 0 || !strings.HasPrefix(child[0], "+") {
+		return child
+	}
+
+	result := append([]string{}, parent...)
+	for _, item := range child {
+		result = append(result, strings.TrimPrefix(item, "+"))
+	}
+	return result
+}
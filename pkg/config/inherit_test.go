@@ -0,0 +1,81 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveImageInheritance(t *testing.T) {
+	images := []RunConfigurationEntry{
+		{Name: "npm", Extends: "node", Provides: []string{"+npm", "+npx"}, BeforeScript: []string{"npm ci"}},
+		{Name: "node", Image: "node:18", Provides: []string{"node"}, BeforeScript: []string{"echo node"}, Shell: "sh"},
+		{Name: "yarn", Extends: "npm", Image: "node:20", Provides: []string{"yarn"}},
+	}
+
+	resolved, err := ResolveImageInheritance(images)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resolved[0].Image != "node:18" || resolved[0].Shell != "sh" || strings.Join(resolved[0].Provides, ",") != "node,npm,npx" || strings.Join(resolved[0].BeforeScript, ",") != "npm ci" {
+		t.Errorf("unexpected npm entry %+v", resolved[0])
+	}
+	if resolved[2].Image != "node:20" || strings.Join(resolved[2].Provides, ",") != "yarn" || strings.Join(resolved[2].BeforeScript, ",") != "npm ci" {
+		t.Errorf("unexpected yarn entry %+v", resolved[2])
+	}
+	if images[0].Image != "" {
+		t.Errorf("input entries must not be modified")
+	}
+}
+
+func TestResolveImageInheritanceSameName(t *testing.T) {
+	images := []RunConfigurationEntry{
+		{Name: "node", Extends: "node", Scope: "Project", Image: "node:20"},
+		{Name: "node", Scope: "Global", Image: "node:18", Provides: []string{"node"}},
+	}
+
+	resolved, err := ResolveImageInheritance(images)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved[0].Image != "node:20" || resolved[0].Scope != "Project" || strings.Join(resolved[0].Provides, ",") != "node" {
+		t.Errorf("unexpected entry %+v", resolved[0])
+	}
+}
+
+func TestResolveImageInheritanceBuild(t *testing.T) {
+	images := []RunConfigurationEntry{
+		{Name: "node", Image: "node:20", Provides: []string{"node"}},
+		{Name: "node-jq", Extends: "node", Build: BuildConfiguration{Dockerfile: "Dockerfile.node"}},
+		{Name: "node-18", Extends: "node-jq", Image: "node:18"},
+	}
+
+	resolved, err := ResolveImageInheritance(images)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resolved[1].IsBuild() || resolved[1].Image != "" || strings.Join(resolved[1].Provides, ",") != "node" {
+		t.Errorf("expected the build to replace the image, got %+v", resolved[1])
+	}
+	if resolved[2].IsBuild() || resolved[2].Image != "node:18" {
+		t.Errorf("expected the image to replace the build, got %+v", resolved[2])
+	}
+}
+
+func TestResolveImageInheritanceErrors(t *testing.T) {
+	var tests = []struct {
+		images   []RunConfigurationEntry
+		expected string
+	}{
+		{[]RunConfigurationEntry{{Name: "npm", Extends: "nodejs"}}, "npm extends the unknown entry nodejs"},
+		{[]RunConfigurationEntry{{Name: "a", Extends: "b"}, {Name: "b", Extends: "a"}}, "cyclic extends: a -> b -> a"},
+		{[]RunConfigurationEntry{{Name: "a", Extends: "a"}}, "a extends the unknown entry a"},
+	}
+
+	for _, test := range tests {
+		_, err := ResolveImageInheritance(test.images)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("expected error containing %q, got %v", test.expected, err)
+		}
+	}
+}
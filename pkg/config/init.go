@@ -0,0 +1,7 @@
+package config
+
+// UsesInit returns true if a init process runs as pid 1 of the container (--init), the default unless the entry sets init: false.
+// The init process reaps orphaned zombie processes and forwards signals to the command.
+func (e RunConfigurationEntry) UsesInit() bool {
+	return e.Init == nil || *e.Init
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+)
+
+// Lint severities
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Lint rules
+const (
+	RuleNoLatestTag       = "no-latest-tag"
+	RuleTagPattern        = "tag-pattern"
+	RuleAllowedRegistries = "allowed-registries"
+)
+
+// LintViolation is a single finding of envcli validate
+type LintViolation struct {
+	Rule     string
+	Severity string
+	Entry    string
+	Message  string
+}
+
+func (v LintViolation) String() string {
+	return fmt.Sprintf("[%s] %s: %s (rule: %s)", v.Severity, v.Entry, v.Message, v.Rule)
+}
+
+// HasErrors returns true if any of the violations has the error severity
+func HasErrors(violations []LintViolation) bool {
+	for _, v := range violations {
+		if v.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateConfiguration checks the configuration for invalid entries and violations of the configured lint rules
+func ValidateConfiguration(cfg ConfigurationFile) []LintViolation {
+	var violations []LintViolation
+
+	// structural checks
+	for _, entry := range cfg.Images {
+		if entry.IsBuild() {
+			if _, err := GetBuildImageName(entry); err != nil {
+				violations = append(violations, LintViolation{Rule: "build", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+			}
+		} else if entry.Image == "" {
+			violations = append(violations, LintViolation{Rule: "required", Severity: SeverityError, Entry: entry.Name, Message: "image is not set"})
+		}
+		if len(entry.Provides) == 0 {
+			violations = append(violations, LintViolation{Rule: "required", Severity: SeverityWarning, Entry: entry.Name, Message: "entry doesn't provide any commands"})
+		}
+		if err := containerutil.ValidateShell(entry.Shell); err != nil {
+			violations = append(violations, LintViolation{Rule: "shell", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		if _, err := GetRetryPolicy(entry); err != nil {
+			violations = append(violations, LintViolation{Rule: "retry", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		if err := ValidatePassthrough(entry.Passthrough); err != nil {
+			violations = append(violations, LintViolation{Rule: "passthrough", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		if err := ValidateArgPosition(entry.ArgPosition); err != nil {
+			violations = append(violations, LintViolation{Rule: "defaultArgs", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		for command := range entry.DefaultArgs {
+			if command != entry.Name && !containsString(entry.Provides, command) {
+				violations = append(violations, LintViolation{Rule: "defaultArgs", Severity: SeverityWarning, Entry: entry.Name, Message: "default arguments of " + command + " are never used, the entry doesn't provide the command"})
+			}
+		}
+		for command, args := range entry.DefaultArgs {
+			for _, arg := range args {
+				if err := ValidateTemplate(entry.Name+".defaultArgs."+command, arg); err != nil {
+					violations = append(violations, LintViolation{Rule: "template", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+				}
+			}
+		}
+		for i, env := range entry.Env {
+			if err := ValidateEnv(env); err != nil {
+				violations = append(violations, LintViolation{Rule: "env", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+			} else if err = ValidateTemplate(fmt.Sprintf("%s.env[%d]", entry.Name, i), env); err != nil {
+				violations = append(violations, LintViolation{Rule: "template", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+			}
+		}
+		if err := ValidateDocsURL(entry.DocsURL); err != nil {
+			violations = append(violations, LintViolation{Rule: "docsUrl", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		if _, err := GetReadinessProbe(entry); err != nil {
+			violations = append(violations, LintViolation{Rule: "readiness", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		if err := ValidateExtraPackages(entry.ExtraPackages); err != nil {
+			violations = append(violations, LintViolation{Rule: "extraPackages", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		if err := ValidateTmpDirs(entry.TmpDirs); err != nil {
+			violations = append(violations, LintViolation{Rule: "tmpDirs", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		if err := ValidateVariants(entry); err != nil {
+			violations = append(violations, LintViolation{Rule: "variants", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		if err := ValidateEnvFiles(entry.EnvFile); err != nil {
+			violations = append(violations, LintViolation{Rule: "envFile", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		if err := ValidateEnvPassthrough(entry.EnvPassthrough); err != nil {
+			violations = append(violations, LintViolation{Rule: "envPassthrough", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		if _, err := GetUlimits(entry); err != nil {
+			violations = append(violations, LintViolation{Rule: "ulimits", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		if _, err := GetSecurityOpts(entry); err != nil {
+			violations = append(violations, LintViolation{Rule: "securityOpt", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		if _, err := GetPriority(entry, ""); err != nil {
+			violations = append(violations, LintViolation{Rule: "priority", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+		if err := ValidateRequiresRuntime(entry); err != nil {
+			violations = append(violations, LintViolation{Rule: "requiresRuntime", Severity: SeverityError, Entry: entry.Name, Message: err.Error()})
+		}
+	}
+
+	// conditions
+	for _, skipped := range cfg.SkippedImages {
+		if _, err := ParseCondition(skipped.Entry.When); err != nil {
+			violations = append(violations, LintViolation{Rule: "when", Severity: SeverityError, Entry: skipped.Entry.Name, Message: "invalid when condition: " + err.Error()})
+		}
+	}
+
+	// image policies
+	for _, policy := range cfg.ImagePolicies {
+		if policy.Mode != "" && policy.Mode != PolicyModeWarn && policy.Mode != PolicyModeEnforce {
+			violations = append(violations, LintViolation{Rule: "policy", Severity: SeverityError, Entry: policy.Source, Message: "invalid policy mode " + policy.Mode + ", allowed: warn, enforce"})
+		}
+		for _, pattern := range policy.AllowedImagePatterns {
+			if _, err := CompileImagePattern(pattern); err != nil {
+				violations = append(violations, LintViolation{Rule: "policy", Severity: SeverityError, Entry: policy.Source, Message: "invalid image pattern " + pattern + ": " + err.Error()})
+			}
+		}
+		for _, opt := range policy.SecurityOpt {
+			if err := ValidateSecurityOpt(opt); err != nil {
+				violations = append(violations, LintViolation{Rule: "policy", Severity: SeverityError, Entry: policy.Source, Message: err.Error()})
+			}
+		}
+	}
+
+	// configured rules
+	for _, rule := range cfg.Policy.Lint {
+		severity := rule.Severity
+		if severity == "" {
+			severity = SeverityWarning
+		}
+		if severity != SeverityInfo && severity != SeverityWarning && severity != SeverityError {
+			violations = append(violations, LintViolation{Rule: rule.Rule, Severity: SeverityError, Entry: "policy", Message: "invalid severity " + rule.Severity + ", allowed: info, warning, error"})
+			continue
+		}
+
+		var tagPattern *regexp.Regexp
+		if rule.Rule == RuleTagPattern {
+			var err error
+			tagPattern, err = regexp.Compile(rule.Pattern)
+			if err != nil {
+				violations = append(violations, LintViolation{Rule: rule.Rule, Severity: SeverityError, Entry: "policy", Message: "invalid pattern: " + err.Error()})
+				continue
+			}
+		} else if rule.Rule != RuleNoLatestTag && rule.Rule != RuleAllowedRegistries {
+			violations = append(violations, LintViolation{Rule: rule.Rule, Severity: SeverityError, Entry: "policy", Message: "unknown lint rule"})
+			continue
+		}
+
+		for _, entry := range cfg.Images {
+			if entry.Image == "" || entry.IsBuild() {
+				continue
+			}
+			ref := ParseImageReference(entry.Image)
+
+			switch rule.Rule {
+			case RuleNoLatestTag:
+				if ref.Tag == "latest" {
+					violations = append(violations, LintViolation{Rule: rule.Rule, Severity: severity, Entry: entry.Name, Message: "image " + entry.Image + " uses the floating tag latest"})
+				}
+			case RuleTagPattern:
+				if ref.Digest == "" && !tagPattern.MatchString(ref.Tag) {
+					violations = append(violations, LintViolation{Rule: rule.Rule, Severity: severity, Entry: entry.Name, Message: "tag " + ref.Tag + " doesn't match " + rule.Pattern})
+				}
+			case RuleAllowedRegistries:
+				if !isRegistryAllowed(ref.Registry, rule.Registries) {
+					violations = append(violations, LintViolation{Rule: rule.Rule, Severity: severity, Entry: entry.Name, Message: "registry " + ref.Registry + " is not allowed, allowed: " + strings.Join(rule.Registries, ", ")})
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+func isRegistryAllowed(registry string, allowed []string) bool {
+	for _, a := range allowed {
+		if strings.EqualFold(registry, a) {
+			return true
+		}
+	}
+	return false
+}
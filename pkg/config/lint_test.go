@@ -0,0 +1,119 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestParseImageReference(t *testing.T) {
+	var tests = []struct {
+		image    string
+		expected ImageReference
+	}{
+		{"alpine", ImageReference{Registry: "docker.io", Repository: "alpine", Tag: "latest"}},
+		{"docker.io/node:10-alpine", ImageReference{Registry: "docker.io", Repository: "node", Tag: "10-alpine"}},
+		{"quay.io/cidverse/build-go:1.20", ImageReference{Registry: "quay.io", Repository: "cidverse/build-go", Tag: "1.20"}},
+		{"localhost:5000/tool:1", ImageReference{Registry: "localhost:5000", Repository: "tool", Tag: "1"}},
+		{"envcli/envcli@sha256:abc", ImageReference{Registry: "docker.io", Repository: "envcli/envcli", Digest: "sha256:abc"}},
+	}
+
+	for _, test := range tests {
+		ref := ParseImageReference(test.image)
+		if ref != test.expected {
+			t.Errorf("ParseImageReference(%s): expected %+v, got %+v", test.image, test.expected, ref)
+		}
+	}
+}
+
+func TestValidateConfiguration(t *testing.T) {
+	cfg := ConfigurationFile{
+		Images: []RunConfigurationEntry{
+			{Name: "alpine", Provides: []string{"sh"}, Image: "alpine"},
+			{Name: "go", Provides: []string{"go"}, Image: "quay.io/cidverse/build-go:1.20"},
+			{Name: "node", Provides: []string{"npm"}, Image: "docker.io/node:lts"},
+		},
+		Policy: PolicyConfiguration{Lint: []LintRule{
+			{Rule: RuleNoLatestTag, Severity: SeverityWarning},
+			{Rule: RuleTagPattern, Severity: SeverityInfo, Pattern: `^\d`},
+			{Rule: RuleAllowedRegistries, Severity: SeverityError, Registries: []string{"quay.io"}},
+		}},
+	}
+
+	violations := ValidateConfiguration(cfg)
+	if len(violations) != 5 {
+		t.Errorf("expected 5 violations, got %d: %v", len(violations), violations)
+	}
+	if !HasErrors(violations) {
+		t.Errorf("expected error level violations")
+	}
+}
+
+func TestValidateConfigurationWarningsOnly(t *testing.T) {
+	cfg := ConfigurationFile{
+		Images: []RunConfigurationEntry{{Name: "alpine", Provides: []string{"sh"}, Image: "alpine:latest"}},
+		Policy: PolicyConfiguration{Lint: []LintRule{{Rule: RuleNoLatestTag}}},
+	}
+
+	violations := ValidateConfiguration(cfg)
+	if len(violations) != 1 || violations[0].Severity != SeverityWarning {
+		t.Errorf("expected a single warning, got %v", violations)
+	}
+	if HasErrors(violations) {
+		t.Errorf("expected no error level violations")
+	}
+}
+
+func TestValidateConfigurationInvalidRule(t *testing.T) {
+	cfg := ConfigurationFile{Policy: PolicyConfiguration{Lint: []LintRule{{Rule: "unknown"}, {Rule: RuleTagPattern, Pattern: "("}}}}
+
+	violations := ValidateConfiguration(cfg)
+	if len(violations) != 2 || !HasErrors(violations) {
+		t.Errorf("expected two errors, got %v", violations)
+	}
+}
+
+func TestValidateConfigurationInvalidShell(t *testing.T) {
+	cfg := ConfigurationFile{Images: []RunConfigurationEntry{{Name: "maven", Provides: []string{"mvn"}, Image: "maven:3", Shell: "bsh"}}}
+
+	violations := ValidateConfiguration(cfg)
+	if len(violations) != 1 || violations[0].Rule != "shell" || !HasErrors(violations) {
+		t.Errorf("expected a shell error, got %v", violations)
+	}
+}
+
+func TestValidateConfigurationInvalidRetry(t *testing.T) {
+	cfg := ConfigurationFile{Images: []RunConfigurationEntry{
+		{Name: "maven", Provides: []string{"mvn"}, Image: "maven:3", Retries: 2, RetryDelay: "5 seconds"},
+		{Name: "npm", Provides: []string{"npm"}, Image: "node:18", Retries: 2, RetryDelay: "5s", RetryOnExitCodes: []int{1, 7}},
+	}}
+
+	violations := ValidateConfiguration(cfg)
+	if len(violations) != 1 || violations[0].Rule != "retry" || violations[0].Entry != "maven" {
+		t.Errorf("expected a retry error, got %v", violations)
+	}
+}
+
+func TestValidateConfigurationInvalidRequiresRuntime(t *testing.T) {
+	cfg := ConfigurationFile{Images: []RunConfigurationEntry{
+		{Name: "cuda", Provides: []string{"nvcc"}, Image: "nvidia/cuda:12.4.1-base-ubuntu22.04", RequiresRuntime: map[string]string{"docker": ">=19.03", "podman": ">=4.0"}},
+		{Name: "maven", Provides: []string{"mvn"}, Image: "maven:3", RequiresRuntime: map[string]string{"docker": ">= latest"}},
+		{Name: "npm", Provides: []string{"npm"}, Image: "node:18", RequiresRuntime: map[string]string{"rkt": ">=1.0"}},
+	}}
+
+	violations := ValidateConfiguration(cfg)
+	if len(violations) != 2 || violations[0].Rule != "requiresRuntime" || violations[0].Entry != "maven" || violations[1].Entry != "npm" {
+		t.Errorf("expected requiresRuntime errors for maven and npm, got %v", violations)
+	}
+}
+
+func TestValidateConfigurationInvalidDocsURL(t *testing.T) {
+	cfg := ConfigurationFile{Images: []RunConfigurationEntry{
+		{Name: "helm", Provides: []string{"helm"}, Image: "alpine/helm:3", DocsURL: "https://wiki.company.com/runbooks/helm"},
+		{Name: "kubectl", Provides: []string{"kubectl"}, Image: "bitnami/kubectl", DocsURL: "wiki.company.com/kubectl"},
+		{Name: "terraform", Provides: []string{"terraform"}, Image: "hashicorp/terraform", DocsURL: "file:///etc/passwd"},
+	}}
+
+	violations := ValidateConfiguration(cfg)
+	if len(violations) != 2 || violations[0].Rule != "docsUrl" || violations[0].Entry != "kubectl" || violations[1].Entry != "terraform" {
+		t.Errorf("expected docsUrl errors for kubectl and terraform, got %v", violations)
+	}
+}
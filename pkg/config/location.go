@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/rs/zerolog/log"
+)
+
+// legacyConfigurationDirectory is the directory of the executable, used by envcli versions that didn't follow the XDG base directory spec
+var legacyConfigurationDirectory = filesystem.GetExecutionDirectory()
+
+// legacyConfigurationFile is the name of the property file in the legacy configuration directory
+var legacyConfigurationFile = ".envclirc"
+
+// legacyConfigurationFiles are the files copied from the legacy into the configuration directory as legacy and new name,
+// the property file comes last as its presence marks the migration as done
+func legacyConfigurationFiles() [][2]string {
+	return [][2]string{
+		{".envcli.yml", ".envcli.yml"},
+		{trustFile, trustFile},
+		{legacyConfigurationFile, defaultConfigurationFile},
+	}
+}
+
+// UserConfigurationDirectory returns the envcli directory within the user config directory ($XDG_CONFIG_HOME/envcli, ~/Library/Application Support/envcli or %AppData%\envcli), the directory of the executable if there is none
+func UserConfigurationDirectory() string {
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return filesystem.GetExecutionDirectory()
+	}
+	return filepath.Join(userConfigDir, "envcli")
+}
+
+// UserCacheDirectory returns the envcli directory within the user cache directory ($XDG_CACHE_HOME/envcli, ~/Library/Caches/envcli or %LocalAppData%\envcli), a temporary directory if there is none
+func UserCacheDirectory() string {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		userCacheDir = os.TempDir()
+	}
+	return filepath.Join(userCacheDir, "envcli")
+}
+
+// propertyConfigFileForReading returns the property file to read, the legacy property file is migrated into the configuration directory first
+func propertyConfigFileForReading() string {
+	propConfigFile := GetPropertyConfigFile()
+	if _, err := os.Stat(propConfigFile); err == nil {
+		return propConfigFile
+	}
+
+	legacyFile := filepath.Join(legacyConfigurationDirectory, legacyConfigurationFile)
+	if legacyConfigurationDirectory == "" || filepath.Clean(legacyFile) == filepath.Clean(propConfigFile) {
+		return propConfigFile
+	}
+	if _, err := os.Stat(legacyFile); err != nil {
+		return propConfigFile
+	}
+
+	if err := migrateLegacyConfiguration(); err != nil {
+		log.Warn().Err(err).Str("legacy", legacyFile).Str("file", propConfigFile).Msg("failed to migrate the property file, reading it from the legacy location")
+		return legacyFile
+	}
+	log.Warn().Str("legacy", legacyFile).Str("file", propConfigFile).Msg("migrated the configuration from the directory of the executable, the legacy files can be removed")
+	return propConfigFile
+}
+
+// migrateLegacyConfiguration copies the files of the legacy configuration directory, files that already exist in the configuration directory are kept
+func migrateLegacyConfiguration() error {
+	if err := os.MkdirAll(defaultConfigurationDirectory, 0700); err != nil {
+		return err
+	}
+
+	for _, file := range legacyConfigurationFiles() {
+		legacyName, target := file[0], filepath.Join(defaultConfigurationDirectory, file[1])
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(legacyConfigurationDirectory, legacyName))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if err = os.WriteFile(target, content, 0600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
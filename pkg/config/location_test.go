@@ -0,0 +1,121 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// useConfigurationDirectories points the configuration and the legacy directory to temporary directories
+func useConfigurationDirectories(t *testing.T) (string, string) {
+	t.Helper()
+	previous, previousLegacy := defaultConfigurationDirectory, legacyConfigurationDirectory
+	t.Cleanup(func() {
+		defaultConfigurationDirectory = previous
+		legacyConfigurationDirectory = previousLegacy
+	})
+
+	// the configuration directory doesn't exist on fresh installs
+	defaultConfigurationDirectory = filepath.Join(t.TempDir(), "envcli")
+	legacyConfigurationDirectory = t.TempDir()
+	return defaultConfigurationDirectory, legacyConfigurationDirectory
+}
+
+func TestUserDirectoriesFollowXDG(t *testing.T) {
+	configHome, cacheHome := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	if _, err := os.UserConfigDir(); err != nil {
+		t.Skip("no user config directory on this platform")
+	}
+
+	configDir, _ := os.UserConfigDir()
+	if dir := UserConfigurationDirectory(); dir != filepath.Join(configDir, "envcli") {
+		t.Errorf("unexpected config directory %s", dir)
+	}
+	cacheDir, _ := os.UserCacheDir()
+	if dir := UserCacheDirectory(); dir != filepath.Join(cacheDir, "envcli") {
+		t.Errorf("unexpected cache directory %s", dir)
+	}
+	if dir := GetDownloadCacheDirectory(PropertyConfigurationFile{}); dir != filepath.Join(cacheDir, "envcli", "downloads") {
+		t.Errorf("expected the downloads to be cached in the user cache directory, got %s", dir)
+	}
+}
+
+func TestPropertyConfigFreshInstall(t *testing.T) {
+	configDir, legacyDir := useConfigurationDirectories(t)
+
+	propConfig, err := LoadPropertyConfig()
+	if err != nil || len(propConfig.Properties) != 0 {
+		t.Fatalf("expected empty properties, got %v (%v)", propConfig.Properties, err)
+	}
+	if _, err = os.Stat(configDir); !os.IsNotExist(err) {
+		t.Errorf("expected loading to not create the configuration directory")
+	}
+
+	if err = SetPropertyConfigEntry("history", "false", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(configDir, "config.yml")); err != nil {
+		t.Errorf("expected the property file to be created in the configuration directory: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(legacyDir, ".envclirc")); !os.IsNotExist(err) {
+		t.Errorf("expected no property file in the legacy directory")
+	}
+}
+
+func TestPropertyConfigLegacyMigration(t *testing.T) {
+	configDir, legacyDir := useConfigurationDirectories(t)
+	_ = os.WriteFile(filepath.Join(legacyDir, ".envclirc"), []byte("properties:\n  history: \"false\"\n"), 0600)
+	_ = os.WriteFile(filepath.Join(legacyDir, ".envcli.yml"), []byte("images: []\n"), 0600)
+
+	propConfig, err := LoadPropertyConfig()
+	if err != nil || propConfig.Properties["history"] != "false" {
+		t.Fatalf("expected the legacy properties to be loaded, got %v (%v)", propConfig.Properties, err)
+	}
+	for _, name := range []string{"config.yml", ".envcli.yml"} {
+		if _, err = os.Stat(filepath.Join(configDir, name)); err != nil {
+			t.Errorf("expected %s to be migrated: %v", name, err)
+		}
+	}
+	if _, err = os.Stat(filepath.Join(legacyDir, ".envclirc")); err != nil {
+		t.Errorf("expected the legacy property file to be kept: %v", err)
+	}
+
+	// the migration only happens once, later changes of the legacy file are ignored
+	_ = os.WriteFile(filepath.Join(legacyDir, ".envclirc"), []byte("properties:\n  history: \"true\"\n"), 0600)
+	if propConfig, _ = LoadPropertyConfig(); propConfig.Properties["history"] != "false" {
+		t.Errorf("expected the migrated property file to be used, got %v", propConfig.Properties)
+	}
+}
+
+func TestPropertyConfigLegacyMigrationFails(t *testing.T) {
+	_, legacyDir := useConfigurationDirectories(t)
+	_ = os.WriteFile(filepath.Join(legacyDir, ".envclirc"), []byte("properties:\n  history: \"false\"\n"), 0600)
+
+	// the configuration directory can't be created below a regular file
+	file := filepath.Join(t.TempDir(), "file")
+	_ = os.WriteFile(file, []byte("x"), 0600)
+	defaultConfigurationDirectory = filepath.Join(file, "envcli")
+
+	propConfig, err := LoadPropertyConfig()
+	if err != nil || propConfig.Properties["history"] != "false" {
+		t.Errorf("expected the legacy property file to be read in place, got %v (%v)", propConfig.Properties, err)
+	}
+}
+
+func TestPropertyConfigOverriddenPaths(t *testing.T) {
+	configDir, _ := useConfigurationDirectories(t)
+	globalDir, cacheDir := t.TempDir(), t.TempDir()
+	propConfig := PropertyConfigurationFile{Properties: map[string]string{"global-configuration-path": globalDir, "cache-path": cacheDir}}
+
+	if file := GetGlobalConfigurationFile(propConfig); file != globalDir+"/.envcli.yml" {
+		t.Errorf("expected global-configuration-path to be used, got %s", file)
+	}
+	if file := GetGlobalConfigurationFile(PropertyConfigurationFile{}); file != configDir+"/.envcli.yml" {
+		t.Errorf("expected the configuration directory to be used, got %s", file)
+	}
+	if dir := GetDownloadCacheDirectory(propConfig); dir != filepath.Join(cacheDir, "downloads") {
+		t.Errorf("expected cache-path to be used, got %s", dir)
+	}
+}
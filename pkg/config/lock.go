@@ -0,0 +1,173 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/EnvCLI/EnvCLI/pkg/atomicfile"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+)
+
+// LockFileName is the name of the lock file, it is stored next to the project config
+const LockFileName = ".envcli.lock.yml"
+
+// LockFile is the schema of the lock file, the images are sorted by the entry name
+type LockFile struct {
+	Images []LockedImage `yaml:"images"`
+}
+
+// LockedImage is the digest the image of a entry resolved to, when it has been locked
+type LockedImage struct {
+	// Name of the entry
+	Name string `yaml:"name"`
+
+	// Image as configured, used to detect configuration changes without locking again
+	Image string `yaml:"image"`
+
+	// Digest of the image manifest or index (ex. sha256:...)
+	Digest string `yaml:"digest"`
+}
+
+// Lock change types
+const (
+	LockAdded   = "added"
+	LockUpdated = "updated"
+	LockRemoved = "removed"
+)
+
+// LockChange describes a change of the lock file
+type LockChange struct {
+	Type  string
+	Image LockedImage
+}
+
+// Get returns the locked image of the entry
+func (l LockFile) Get(name string) (LockedImage, bool) {
+	for _, image := range l.Images {
+		if image.Name == name {
+			return image, true
+		}
+	}
+	return LockedImage{}, false
+}
+
+// GetLockFilePath returns the path of the lock file within the project directory
+func GetLockFilePath(projectDirectory string) string {
+	return filepath.Join(projectDirectory, LockFileName)
+}
+
+// LoadLockFile reads the lock file, a missing lock file is returned as empty lock file
+func LoadLockFile(file string) (LockFile, bool, error) {
+	var lock LockFile
+
+	content, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return lock, false, nil
+	} else if err != nil {
+		return lock, false, err
+	}
+
+	if err = yaml.Unmarshal(content, &lock); err != nil {
+		return lock, false, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return lock, true, nil
+}
+
+// WriteLockFile writes the lock file, the images are sorted by name for stable diffs
+func WriteLockFile(file string, lock LockFile) error {
+	sort.SliceStable(lock.Images, func(i, j int) bool {
+		return lock.Images[i].Name < lock.Images[j].Name
+	})
+
+	content, err := yaml.Marshal(&lock)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(file, append([]byte("# generated by envcli lock, do not edit manually\n"), content...), 0644)
+}
+
+// IsLockable returns true if the image of the entry can be locked, built images and images referenced by digest are not locked
+func IsLockable(entry RunConfigurationEntry) bool {
+	return entry.Image != "" && !entry.IsBuild() && ParseImageReference(entry.Image).Digest == ""
+}
+
+// UpdateLock adds the missing entries to the lock file, locks entries whose image changed again and removes entries that are no longer configured.
+// If update is set, the digests of the entries are resolved again - all entries if names is empty, the named entries otherwise.
+func UpdateLock(lock LockFile, entries []RunConfigurationEntry, update bool, names []string, resolve func(image string) (string, error)) (LockFile, []LockChange, error) {
+	var result LockFile
+	var changes []LockChange
+
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if !IsLockable(entry) || seen[entry.Name] {
+			continue
+		}
+		seen[entry.Name] = true
+
+		locked, found := lock.Get(entry.Name)
+		refresh := update && (len(names) == 0 || containsString(names, entry.Name))
+		if found && locked.Image == entry.Image && !refresh {
+			result.Images = append(result.Images, locked)
+			continue
+		}
+
+		digest, err := resolve(entry.Image)
+		if err != nil {
+			return lock, nil, fmt.Errorf("failed to resolve the digest of %s (entry %s): %w", entry.Image, entry.Name, err)
+		}
+		image := LockedImage{Name: entry.Name, Image: entry.Image, Digest: digest}
+		result.Images = append(result.Images, image)
+		log.Debug().Str("entry", entry.Name).Str("image", entry.Image).Str("digest", digest).Msg("locked image")
+
+		if !found {
+			changes = append(changes, LockChange{Type: LockAdded, Image: image})
+		} else if locked != image {
+			changes = append(changes, LockChange{Type: LockUpdated, Image: image})
+		}
+	}
+
+	for _, locked := range lock.Images {
+		if !seen[locked.Name] {
+			changes = append(changes, LockChange{Type: LockRemoved, Image: locked})
+		}
+	}
+
+	for _, name := range names {
+		if !seen[name] {
+			return lock, nil, errors.New("entry " + name + " doesn't exist or its image can't be locked")
+		}
+	}
+
+	sort.SliceStable(result.Images, func(i, j int) bool {
+		return result.Images[i].Name < result.Images[j].Name
+	})
+	return result, changes, nil
+}
+
+// ResolveLockedImage returns the image to run for the entry: the locked digest if the lock file contains the entry and the image didn't change.
+// A entry that isn't locked or whose image changed since it has been locked is reported as error in frozen mode, and only logged as warning otherwise.
+func ResolveLockedImage(entry RunConfigurationEntry, lock LockFile, frozen bool) (string, error) {
+	if !IsLockable(entry) {
+		return entry.Image, nil
+	}
+
+	locked, found := lock.Get(entry.Name)
+	var message string
+	if !found {
+		message = "entry " + entry.Name + " isn't locked, run envcli lock to lock its image"
+	} else if locked.Image != entry.Image {
+		message = "the image of entry " + entry.Name + " changed from " + locked.Image + " to " + entry.Image + " since it has been locked, run envcli lock to lock it again"
+	} else {
+		return entry.Image + "@" + locked.Digest, nil
+	}
+
+	if frozen {
+		return "", errors.New(message)
+	}
+	log.Warn().Str("entry", entry.Name).Msg(message)
+	return entry.Image, nil
+}
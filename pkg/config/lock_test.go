@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUpdateLock(t *testing.T) {
+	resolved := 0
+	resolve := func(image string) (string, error) {
+		resolved++
+		return "sha256:" + strings.ReplaceAll(image, ":", "-"), nil
+	}
+	entries := []RunConfigurationEntry{
+		{Name: "node", Image: "node:20"},
+		{Name: "go", Image: "golang:1.21"},
+		{Name: "built", Build: BuildConfiguration{Dockerfile: "Dockerfile"}},
+		{Name: "pinned", Image: "alpine@sha256:abc"},
+	}
+
+	// add
+	lock, changes, err := UpdateLock(LockFile{}, entries, false, nil, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []LockedImage{{Name: "go", Image: "golang:1.21", Digest: "sha256:golang-1.21"}, {Name: "node", Image: "node:20", Digest: "sha256:node-20"}}
+	if !reflect.DeepEqual(lock.Images, expected) || len(changes) != 2 || changes[0].Type != LockAdded {
+		t.Fatalf("unexpected lock %v, changes %v", lock.Images, changes)
+	}
+
+	// unchanged entries are kept, changed images are locked again and removed entries are dropped
+	resolved = 0
+	lock, changes, err = UpdateLock(lock, []RunConfigurationEntry{{Name: "node", Image: "node:21"}, {Name: "go", Image: "golang:1.21"}}, false, nil, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved != 1 || len(lock.Images) != 2 || lock.Images[1].Digest != "sha256:node-21" {
+		t.Errorf("unexpected lock %v (resolved %d)", lock.Images, resolved)
+	}
+	if len(changes) != 1 || changes[0].Type != LockUpdated || changes[0].Image.Name != "node" {
+		t.Errorf("unexpected changes %v", changes)
+	}
+	lock, changes, err = UpdateLock(lock, []RunConfigurationEntry{{Name: "go", Image: "golang:1.21"}}, false, nil, resolve)
+	if err != nil || len(lock.Images) != 1 || len(changes) != 1 || changes[0].Type != LockRemoved || changes[0].Image.Name != "node" {
+		t.Errorf("unexpected lock %v, changes %v (%v)", lock.Images, changes, err)
+	}
+
+	// update only the named entries
+	resolved = 0
+	resolve = func(image string) (string, error) {
+		resolved++
+		return "sha256:new", nil
+	}
+	lock, _, err = UpdateLock(LockFile{Images: expected}, entries, true, []string{"node"}, resolve)
+	if err != nil || resolved != 1 || lock.Images[0].Digest != "sha256:golang-1.21" || lock.Images[1].Digest != "sha256:new" {
+		t.Errorf("unexpected lock %v (resolved %d, %v)", lock.Images, resolved, err)
+	}
+	if _, _, err = UpdateLock(lock, entries, true, []string{"missing"}, resolve); err == nil {
+		t.Errorf("expected unknown entries to be rejected")
+	}
+}
+
+func TestLockFileRoundTrip(t *testing.T) {
+	file := filepath.Join(t.TempDir(), LockFileName)
+	if _, found, err := LoadLockFile(file); found || err != nil {
+		t.Fatalf("expected missing lock file, got %v (%v)", found, err)
+	}
+
+	lock := LockFile{Images: []LockedImage{{Name: "node", Image: "node:20", Digest: "sha256:b"}, {Name: "go", Image: "golang:1.21", Digest: "sha256:a"}}}
+	if err := WriteLockFile(file, lock); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(file)
+	if strings.Index(string(content), "name: go") > strings.Index(string(content), "name: node") {
+		t.Errorf("expected the images to be sorted by name:\n%s", content)
+	}
+
+	loaded, found, err := LoadLockFile(file)
+	if err != nil || !found || len(loaded.Images) != 2 || loaded.Images[0].Name != "go" {
+		t.Errorf("unexpected lock file %v (%v)", loaded, err)
+	}
+}
+
+func TestResolveLockedImage(t *testing.T) {
+	lock := LockFile{Images: []LockedImage{{Name: "node", Image: "node:20", Digest: "sha256:abc"}}}
+
+	if image, err := ResolveLockedImage(RunConfigurationEntry{Name: "node", Image: "node:20"}, lock, true); err != nil || image != "node:20@sha256:abc" {
+		t.Errorf("expected the locked digest, got %s (%v)", image, err)
+	}
+
+	// changed tag: warning, or error in frozen mode
+	if image, err := ResolveLockedImage(RunConfigurationEntry{Name: "node", Image: "node:21"}, lock, false); err != nil || image != "node:21" {
+		t.Errorf("expected the configured image, got %s (%v)", image, err)
+	}
+	if _, err := ResolveLockedImage(RunConfigurationEntry{Name: "node", Image: "node:21"}, lock, true); err == nil {
+		t.Errorf("expected the changed image to fail in frozen mode")
+	}
+	if _, err := ResolveLockedImage(RunConfigurationEntry{Name: "go", Image: "golang:1.21"}, lock, true); err == nil {
+		t.Errorf("expected the missing entry to fail in frozen mode")
+	}
+}
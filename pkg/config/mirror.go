@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseRegistryMirrors parses the registry-mirrors property, a comma-separated list of registry=mirror pairs (ex. docker.io=mirror.company.com)
+func ParseRegistryMirrors(value string) (map[string]string, error) {
+	mirrors := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		registry, mirror, found := strings.Cut(pair, "=")
+		registry, mirror = strings.TrimSpace(registry), strings.TrimSuffix(strings.TrimSpace(mirror), "/")
+		if !found || registry == "" || mirror == "" {
+			return nil, fmt.Errorf("invalid registry mirror %q, expected registry=mirror like docker.io=mirror.company.com", pair)
+		}
+		mirrors[registry] = mirror
+	}
+	return mirrors, nil
+}
+
+// MirrorImage replaces the registry of the image with its mirror, the imageMirror of the entry takes precedence over the registry-mirrors property.
+// Official docker hub images keep the library prefix (alpine becomes mirror.company.com/library/alpine:latest), images without a mirror are returned unchanged.
+func MirrorImage(image string, imageMirror string, mirrors map[string]string) string {
+	if image == "" {
+		return image
+	}
+	ref := ParseImageReference(image)
+	mirror := strings.TrimSuffix(imageMirror, "/")
+	if mirror == "" {
+		mirror = mirrors[ref.Registry]
+	}
+	if mirror == "" || mirror == ref.Registry {
+		return image
+	}
+
+	mirrored := mirror + strings.TrimPrefix(ref.FullRepository(), ref.Registry)
+	if ref.Tag != "" {
+		mirrored += ":" + ref.Tag
+	}
+	if ref.Digest != "" {
+		mirrored += "@" + ref.Digest
+	}
+	return mirrored
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestParseRegistryMirrors(t *testing.T) {
+	mirrors, err := ParseRegistryMirrors("docker.io=mirror.company.com,ghcr.io=mirror.company.com/ghcr/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mirrors["docker.io"] != "mirror.company.com" || mirrors["ghcr.io"] != "mirror.company.com/ghcr" {
+		t.Errorf("unexpected mirrors %v", mirrors)
+	}
+
+	if mirrors, err = ParseRegistryMirrors(""); err != nil || len(mirrors) != 0 {
+		t.Errorf("expected no mirrors for a empty value, got %v (%v)", mirrors, err)
+	}
+	if _, err = ParseRegistryMirrors("mirror.company.com"); err == nil {
+		t.Errorf("expected a error for a mirror without registry")
+	}
+}
+
+func TestMirrorImage(t *testing.T) {
+	mirrors := map[string]string{"docker.io": "mirror.company.com", "ghcr.io": "mirror.company.com/ghcr"}
+	tests := []struct {
+		image       string
+		imageMirror string
+		expected    string
+	}{
+		{"alpine", "", "mirror.company.com/library/alpine:latest"},
+		{"docker.io/golang:1.22", "", "mirror.company.com/library/golang:1.22"},
+		{"cidverse/build-go:1.20", "", "mirror.company.com/cidverse/build-go:1.20"},
+		{"ghcr.io/cidverse/envcli@sha256:abc", "", "mirror.company.com/ghcr/cidverse/envcli@sha256:abc"},
+		{"quay.io/cidverse/build-go:1.20", "", "quay.io/cidverse/build-go:1.20"},
+		{"quay.io/cidverse/build-go:1.20", "quay-mirror.company.com", "quay-mirror.company.com/cidverse/build-go:1.20"},
+		{"node:20", "entry-mirror.company.com/", "entry-mirror.company.com/library/node:20"},
+		{"mirror.company.com/library/alpine:latest", "mirror.company.com", "mirror.company.com/library/alpine:latest"},
+	}
+	for _, test := range tests {
+		if mirrored := MirrorImage(test.image, test.imageMirror, mirrors); mirrored != test.expected {
+			t.Errorf("expected %s to be mirrored as %s, got %s", test.image, test.expected, mirrored)
+		}
+	}
+}
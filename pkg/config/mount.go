@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/rs/zerolog/log"
+)
+
+// MountPaths describes how the project (or working) directory is mounted into the container
+type MountPaths struct {
+	// Source is the host directory that is mounted
+	Source string
+
+	// Target is the directory within the container
+	Target string
+
+	// WorkingDirectory is the working directory within the container
+	WorkingDirectory string
+}
+
+// ResolveMountRoot returns the project directory, or the working directory if no project config can be found and requireProject is false
+func ResolveMountRoot(workingDirectory string, requireProject bool) (string, error) {
+	projectDirectory, _, err := FindProjectConfig(workingDirectory, GetProjectConfigFilenames())
+	if err == nil {
+		return projectDirectory, nil
+	}
+
+	if requireProject {
+		return "", errors.New("no envcli project config found in " + workingDirectory + " or any parent directory, but the require-project property is set")
+	}
+
+	log.Info().Str("dir", workingDirectory).Msg("no project config found, mounting the working directory instead")
+	return workingDirectory, nil
+}
+
+// ResolveMountPaths calculates the mount and the container working directory.
+// The root is mounted at containerDirectory (or its host path, if empty) and the working directory is mapped to the same relative location.
+// The host paths are made absolute and cleaned, ex. trailing slashes are removed.
+func ResolveMountPaths(rootDirectory string, workingDirectory string, containerDirectory string) MountPaths {
+	rootDirectory = absolutePath(rootDirectory)
+	workingDirectory = absolutePath(workingDirectory)
+	target := containerDirectory
+	if target == "" {
+		target = rootDirectory
+	}
+
+	workdir := strings.TrimRight(target, "/")
+	if relativePath := filesystem.GetPathRelativeToDirectory(workingDirectory, rootDirectory); relativePath != "" {
+		workdir = workdir + "/" + relativePath
+	} else if workdir == "" {
+		workdir = "/"
+	}
+
+	return MountPaths{Source: rootDirectory, Target: target, WorkingDirectory: workdir}
+}
+
+// ContainerPath maps the absolute host path to its path within the container, false if the path is outside of the mounted directory
+func (m MountPaths) ContainerPath(hostPath string) (string, bool) {
+	relativePath, err := filepath.Rel(m.Source, hostPath)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if relativePath == "." {
+		return m.Target, true
+	}
+	return strings.TrimRight(m.Target, "/") + "/" + filepath.ToSlash(relativePath), true
+}
+
+// HostPath maps the absolute container path to its path on the host, false if the path is outside of the mount target
+func (m MountPaths) HostPath(containerPath string) (string, bool) {
+	target := strings.TrimRight(m.Target, "/")
+	if containerPath != m.Target && containerPath != target && !strings.HasPrefix(containerPath, target+"/") {
+		return "", false
+	}
+	relativePath := strings.TrimPrefix(strings.TrimPrefix(containerPath, target), "/")
+	if relativePath == "" {
+		return m.Source, true
+	}
+	hostPath := filepath.Join(m.Source, filepath.FromSlash(relativePath))
+	if _, inside := m.ContainerPath(hostPath); !inside {
+		// ex. /project/../etc
+		return "", false
+	}
+	return hostPath, true
+}
+
+// absolutePath returns the absolute and cleaned path, or the cleaned path if it can't be made absolute
+func absolutePath(path string) string {
+	if absolute, err := filepath.Abs(path); err == nil {
+		return absolute
+	}
+	return filepath.Clean(path)
+}
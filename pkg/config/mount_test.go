@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveMountPaths(t *testing.T) {
+	var tests = []struct {
+		root      string
+		working   string
+		container string
+		expected  MountPaths
+	}{
+		{"/home/user/project", "/home/user/project", "/project", MountPaths{"/home/user/project", "/project", "/project"}},
+		{"/home/user/project", "/home/user/project/src/app", "/project", MountPaths{"/home/user/project", "/project", "/project/src/app"}},
+		{"/home/user/project", "/home/user/project/src", "/project/", MountPaths{"/home/user/project", "/project/", "/project/src"}},
+		{"/home/user/project", "/home/user/project/src", "", MountPaths{"/home/user/project", "/home/user/project", "/home/user/project/src"}},
+		{"/", "/tmp", "/project", MountPaths{"/", "/project", "/project/tmp"}},
+		{"/", "/", "/", MountPaths{"/", "/", "/"}},
+		// working directory fallback: the mount root is the working directory itself
+		{"/tmp/data", "/tmp/data", "/project", MountPaths{"/tmp/data", "/project", "/project"}},
+		{"/tmp/data", "/tmp/data", "", MountPaths{"/tmp/data", "/tmp/data", "/tmp/data"}},
+		// paths with spaces and unicode characters, trailing slashes are removed from the host paths
+		{"/home/user/My Projects/app/", "/home/user/My Projects/app/src/", "/project", MountPaths{"/home/user/My Projects/app", "/project", "/project/src"}},
+		{"/home/jürgen/Übung", "/home/jürgen/Übung/größe", "", MountPaths{"/home/jürgen/Übung", "/home/jürgen/Übung", "/home/jürgen/Übung/größe"}},
+		{"/Users/用户/项目", "/Users/用户/项目/源码", "/project", MountPaths{"/Users/用户/项目", "/project", "/project/源码"}},
+	}
+
+	for _, test := range tests {
+		result := ResolveMountPaths(test.root, test.working, test.container)
+		if result != test.expected {
+			t.Errorf("ResolveMountPaths(%q, %q, %q): expected %+v, got %+v", test.root, test.working, test.container, test.expected, result)
+		}
+	}
+}
+
+func TestResolveMountRootWithoutProject(t *testing.T) {
+	workingDirectory := t.TempDir()
+
+	root, err := ResolveMountRoot(workingDirectory, false)
+	if err != nil || root != workingDirectory {
+		t.Errorf("expected working directory fallback, got %s %v", root, err)
+	}
+
+	if _, err = ResolveMountRoot(workingDirectory, true); err == nil {
+		t.Errorf("expected an error if a project is required")
+	}
+}
+
+func TestResolveMountRootWithProject(t *testing.T) {
+	projectDirectory := t.TempDir()
+	workingDirectory := filepath.Join(projectDirectory, "sub")
+	_ = os.MkdirAll(workingDirectory, os.ModePerm)
+	_ = os.WriteFile(filepath.Join(projectDirectory, ".envcli.yml"), []byte("images: []\n"), 0644)
+
+	root, err := ResolveMountRoot(workingDirectory, true)
+	if err != nil || root != projectDirectory {
+		t.Errorf("expected project directory %s, got %s %v", projectDirectory, root, err)
+	}
+}
+
+func TestMountPathsMapping(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "project")
+	mount := ResolveMountPaths(root, root, "/workspace")
+
+	if path, inside := mount.ContainerPath(filepath.Join(root, "envs", "prod")); !inside || path != "/workspace/envs/prod" {
+		t.Errorf("unexpected container path %s (%v)", path, inside)
+	}
+	if path, inside := mount.ContainerPath(root); !inside || path != "/workspace" {
+		t.Errorf("unexpected container path of the root %s (%v)", path, inside)
+	}
+	if _, inside := mount.ContainerPath(filepath.Dir(root)); inside {
+		t.Errorf("expected the parent directory to be outside of the mount")
+	}
+
+	if path, inside := mount.HostPath("/workspace/envs/prod"); !inside || path != filepath.Join(root, "envs", "prod") {
+		t.Errorf("unexpected host path %s (%v)", path, inside)
+	}
+	for _, path := range []string{"/workspaces", "/etc", "/workspace/../etc"} {
+		if _, inside := mount.HostPath(path); inside {
+			t.Errorf("expected %s to be outside of the mount", path)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// validPackageName matches package names with optional version constraints of apk (jq~1.7), apt (jq=1.6-2) and dnf (jq-1.6)
+var validPackageName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9.+_:=~@-]*$`)
+
+// ValidateExtraPackages returns a error if a package name contains characters that are not allowed, the names are passed to a shell
+func ValidateExtraPackages(packages []string) error {
+	for _, name := range packages {
+		if !validPackageName.MatchString(name) {
+			return fmt.Errorf("invalid package %q, expected a package name like jq or jq=1.6", name)
+		}
+	}
+	return nil
+}
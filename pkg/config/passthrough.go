@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+)
+
+// Passthrough modes of entries, entries without a mode always run in the container
+const (
+	// PassthroughPreferLocal runs the local binary if it is installed, the container otherwise
+	PassthroughPreferLocal = "prefer-local"
+
+	// PassthroughPreferContainer runs the container, the local binary is only used if no container runtime is available
+	PassthroughPreferContainer = "prefer-container"
+
+	// PassthroughLocalOnly always runs the local binary, the command fails if it isn't installed
+	PassthroughLocalOnly = "local-only"
+)
+
+// Execution describes where a command is executed
+type Execution struct {
+	// Local is set if the local binary is executed instead of the container
+	Local bool
+
+	// Path of the local binary
+	Path string
+
+	// Reason explains the decision, shown by envcli which
+	Reason string
+}
+
+// ValidatePassthrough returns a error if the passthrough mode is unknown
+func ValidatePassthrough(mode string) error {
+	switch mode {
+	case "", PassthroughPreferLocal, PassthroughPreferContainer, PassthroughLocalOnly:
+		return nil
+	}
+	return errors.New("invalid passthrough " + mode + ", allowed: " + PassthroughPreferLocal + ", " + PassthroughPreferContainer + ", " + PassthroughLocalOnly)
+}
+
+// ResolveExecution decides if the command is executed by the local binary or within the container of the entry.
+// localPath is the path of the command on the host (empty if it isn't installed), runtimeAvailable is only called for prefer-container.
+func ResolveExecution(entry RunConfigurationEntry, matchType string, localPath string, runtimeAvailable func() bool) (Execution, error) {
+	if err := ValidatePassthrough(entry.Passthrough); err != nil {
+		return Execution{}, exitcode.New(exitcode.ConfigError, fmt.Errorf("entry %s: %w", entry.Name, err))
+	}
+
+	switch {
+	case entry.Passthrough == "":
+		return Execution{Reason: "passthrough is not configured"}, nil
+	case matchType == MatchByName && entry.Passthrough == PassthroughLocalOnly:
+		return Execution{}, exitcode.New(exitcode.ConfigError, errors.New("entry "+entry.Name+" is local-only, run one of the provided commands instead"))
+	case matchType == MatchByName:
+		return Execution{Reason: "matched by image name, passthrough only applies to provided commands"}, nil
+	case entry.Passthrough == PassthroughPreferLocal && localPath != "":
+		return Execution{Local: true, Path: localPath, Reason: "prefer-local, the command is installed locally"}, nil
+	case entry.Passthrough == PassthroughPreferLocal:
+		return Execution{Reason: "prefer-local, the command isn't installed locally"}, nil
+	case entry.Passthrough == PassthroughLocalOnly && localPath != "":
+		return Execution{Local: true, Path: localPath, Reason: "local-only"}, nil
+	case entry.Passthrough == PassthroughLocalOnly:
+		return Execution{}, exitcode.New(exitcode.RuntimeUnavailable, errors.New("entry "+entry.Name+" is local-only, but the command isn't installed locally"))
+	case runtimeAvailable():
+		return Execution{Reason: "prefer-container, a container runtime is available"}, nil
+	case localPath != "":
+		return Execution{Local: true, Path: localPath, Reason: "prefer-container, but no container runtime is available"}, nil
+	}
+
+	return Execution{Reason: "prefer-container, the command isn't installed locally"}, nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+)
+
+func TestResolveExecution(t *testing.T) {
+	var tests = []struct {
+		passthrough      string
+		matchType        string
+		localPath        string
+		runtimeAvailable bool
+		local            bool
+		code             int
+	}{
+		{"", MatchByProvides, "/usr/bin/go", true, false, exitcode.Success},
+		{PassthroughPreferLocal, MatchByProvides, "/usr/bin/go", true, true, exitcode.Success},
+		{PassthroughPreferLocal, MatchByProvides, "", true, false, exitcode.Success},
+		{PassthroughPreferLocal, MatchByName, "/usr/bin/go", true, false, exitcode.Success},
+		{PassthroughPreferContainer, MatchByProvides, "/usr/bin/go", true, false, exitcode.Success},
+		{PassthroughPreferContainer, MatchByProvides, "/usr/bin/go", false, true, exitcode.Success},
+		{PassthroughPreferContainer, MatchByProvides, "", false, false, exitcode.Success},
+		{PassthroughLocalOnly, MatchByProvides, "/usr/bin/go", false, true, exitcode.Success},
+		{PassthroughLocalOnly, MatchByProvides, "", true, false, exitcode.RuntimeUnavailable},
+		{PassthroughLocalOnly, MatchByName, "/usr/bin/go", true, false, exitcode.ConfigError},
+		{"always", MatchByProvides, "/usr/bin/go", true, false, exitcode.ConfigError},
+	}
+
+	for _, test := range tests {
+		entry := RunConfigurationEntry{Name: "golang", Passthrough: test.passthrough}
+		execution, err := ResolveExecution(entry, test.matchType, test.localPath, func() bool { return test.runtimeAvailable })
+		if code := exitcode.Of(err); code != test.code || execution.Local != test.local {
+			t.Errorf("%+v: expected local %t with exit code %d, got %t with %d (%v)", test, test.local, test.code, execution.Local, code, err)
+		}
+		if execution.Local && execution.Path != test.localPath {
+			t.Errorf("%+v: expected path %s, got %s", test, test.localPath, execution.Path)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Policy modes
+const (
+	PolicyModeWarn    = "warn"
+	PolicyModeEnforce = "enforce"
+)
+
+// CompileImagePattern compiles a image pattern, patterns wrapped in slashes are regular expressions, all others are globs (* matches within a path segment, ** across segments)
+func CompileImagePattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return regexp.Compile(pattern[1 : len(pattern)-1])
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		}
+	}
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+}
+
+// compiledImagePatterns caches the compiled image patterns, the policies are checked for each entry
+var compiledImagePatterns sync.Map
+
+// compiledImagePattern returns the compiled pattern, each pattern is only compiled once
+func compiledImagePattern(pattern string) (*regexp.Regexp, error) {
+	if expr, ok := compiledImagePatterns.Load(pattern); ok {
+		return expr.(*regexp.Regexp), nil
+	}
+	expr, err := CompileImagePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	compiledImagePatterns.Store(pattern, expr)
+	return expr, nil
+}
+
+// CheckImagePolicies checks the image against each policy, in warn mode violations are logged and in enforce mode an error is returned.
+// The patterns are matched against the fully qualified image reference, ex. docker.io/library/alpine:3.19 for alpine:3.19.
+func CheckImagePolicies(image string, policies []PolicyConfiguration) error {
+	qualified := ParseImageReference(image).String()
+	for _, policy := range policies {
+		if len(policy.AllowedImagePatterns) == 0 || matchesAnyImagePattern(qualified, policy.AllowedImagePatterns) {
+			continue
+		}
+
+		message := fmt.Sprintf("image %s is not allowed by the policy defined in %s, allowed patterns: %s", image, policy.Source, strings.Join(policy.AllowedImagePatterns, ", "))
+		if policy.Mode == PolicyModeWarn {
+			log.Warn().Str("image", image).Str("policy", policy.Source).Msg(message)
+			continue
+		}
+
+		return errors.New(message)
+	}
+
+	return nil
+}
+
+func matchesAnyImagePattern(image string, patterns []string) bool {
+	for _, pattern := range patterns {
+		expr, err := compiledImagePattern(pattern)
+		if err != nil {
+			log.Warn().Err(err).Str("pattern", pattern).Msg("invalid image pattern")
+			continue
+		}
+		if expr.MatchString(image) {
+			log.Trace().Str("image", image).Str("pattern", pattern).Msg("image allowed by policy")
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompileImagePattern(t *testing.T) {
+	var tests = []struct {
+		pattern string
+		image   string
+		matches bool
+	}{
+		{"quay.io/cidverse/*", "quay.io/cidverse/build-go:1.20", true},
+		{"quay.io/cidverse/*", "quay.io/cidverse/nested/build-go:1.20", false},
+		{"registry.company.com/**", "registry.company.com/team/tools/node:20", true},
+		{"docker.io/library/node:??", "docker.io/library/node:20", true},
+		{"/^quay\\.io/.+:[0-9.]+$/", "quay.io/cidverse/build-go:1.20", true},
+		{"/^quay\\.io/.+:[0-9.]+$/", "quay.io/cidverse/build-go:latest", false},
+	}
+
+	for _, test := range tests {
+		expr, err := CompileImagePattern(test.pattern)
+		if err != nil {
+			t.Fatalf("failed to compile %s: %v", test.pattern, err)
+		}
+		if expr.MatchString(test.image) != test.matches {
+			t.Errorf("pattern %s on image %s: expected %v", test.pattern, test.image, test.matches)
+		}
+	}
+}
+
+func TestCheckImagePoliciesEnforce(t *testing.T) {
+	policies := []PolicyConfiguration{{AllowedImagePatterns: []string{"quay.io/**"}, Source: "/etc/envcli/.envcli.yml"}}
+
+	if err := CheckImagePolicies("quay.io/cidverse/build-go:1.20", policies); err != nil {
+		t.Errorf("expected image to be allowed, got %v", err)
+	}
+
+	err := CheckImagePolicies("docker.io/alpine:latest", policies)
+	if err == nil {
+		t.Fatalf("expected image to be rejected")
+	}
+	if !strings.Contains(err.Error(), "/etc/envcli/.envcli.yml") || !strings.Contains(err.Error(), "quay.io/**") {
+		t.Errorf("expected error to name the policy source and patterns, got %v", err)
+	}
+}
+
+func TestCheckImagePoliciesWarn(t *testing.T) {
+	policies := []PolicyConfiguration{{AllowedImagePatterns: []string{"quay.io/**"}, Mode: PolicyModeWarn}}
+
+	if err := CheckImagePolicies("docker.io/alpine:latest", policies); err != nil {
+		t.Errorf("expected only a warning, got %v", err)
+	}
+}
+
+func TestCheckImagePoliciesProjectCantRelax(t *testing.T) {
+	// a project policy allowing everything must not override the global enforce policy
+	policies := []PolicyConfiguration{
+		{AllowedImagePatterns: []string{"**"}, Mode: PolicyModeWarn, Source: ".envcli.yml"},
+		{AllowedImagePatterns: []string{"quay.io/**"}, Mode: PolicyModeEnforce, Source: "global/.envcli.yml"},
+	}
+
+	if err := CheckImagePolicies("docker.io/alpine:latest", policies); err == nil {
+		t.Errorf("expected image to be rejected by the global policy")
+	}
+}
+
+func TestCheckImagePoliciesNormalized(t *testing.T) {
+	policies := []PolicyConfiguration{{AllowedImagePatterns: []string{"docker.io/library/*", "quay.io/cidverse/*"}, Source: "global/.envcli.yml"}}
+
+	for _, image := range []string{"alpine:3.19", "alpine", "docker.io/alpine:3.19", "docker.io/library/alpine@sha256:abc", "quay.io/cidverse/build-go"} {
+		if err := CheckImagePolicies(image, policies); err != nil {
+			t.Errorf("expected %s to be allowed, got %v", image, err)
+		}
+	}
+	for _, image := range []string{"evil.example.com/library/alpine:3.19", "someone/alpine:3.19"} {
+		if err := CheckImagePolicies(image, policies); err == nil {
+			t.Errorf("expected %s to be rejected", image)
+		}
+	}
+}
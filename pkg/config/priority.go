@@ -0,0 +1,81 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Scheduling priorities of the entries (priority) and of envcli run --low-priority
+const (
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// Priorities are the valid priorities
+var Priorities = []string{PriorityNormal, PriorityLow}
+
+// PriorityPreset holds the container options and the host scheduling of a priority, zero values keep the defaults of the runtime
+type PriorityPreset struct {
+	// CPUShares is the relative cpu weight of the container (--cpu-shares), the default is 1024
+	CPUShares int
+
+	// BlkioWeight is the relative block io weight of the container (--blkio-weight) between 10 and 1000, the default is 500
+	BlkioWeight int
+
+	// Nice is the niceness of the runtime client on linux hosts
+	Nice int
+
+	// IOClass and IOLevel are the io scheduling class and level (ionice) of the runtime client on linux hosts, no ionice if the class is 0
+	IOClass int
+	IOLevel int
+}
+
+// PriorityPresets are the settings of the priorities
+var PriorityPresets = map[string]PriorityPreset{
+	PriorityNormal: {},
+	PriorityLow:    {CPUShares: 256, BlkioWeight: 100, Nice: 10, IOClass: 2, IOLevel: 7},
+}
+
+// GetPriority returns the priority preset of the entry, the override (ex. --low-priority) takes precedence over the priority of the entry.
+// The cpuShares and blkioWeight of the entry override the values of the preset.
+func GetPriority(entry RunConfigurationEntry, override string) (PriorityPreset, error) {
+	name := entry.Priority
+	if override != "" {
+		name = override
+	}
+	if name == "" {
+		name = PriorityNormal
+	}
+	preset, found := PriorityPresets[name]
+	if !found {
+		return PriorityPreset{}, errors.New("unknown priority " + name + ", allowed: " + strings.Join(Priorities, ", "))
+	}
+
+	if entry.CPUShares != 0 {
+		if entry.CPUShares < 2 {
+			return PriorityPreset{}, fmt.Errorf("invalid cpuShares %d, expected at least 2", entry.CPUShares)
+		}
+		preset.CPUShares = entry.CPUShares
+	}
+	if entry.BlkioWeight != 0 {
+		if entry.BlkioWeight < 10 || entry.BlkioWeight > 1000 {
+			return PriorityPreset{}, fmt.Errorf("invalid blkioWeight %d, expected a value between 10 and 1000", entry.BlkioWeight)
+		}
+		preset.BlkioWeight = entry.BlkioWeight
+	}
+	return preset, nil
+}
+
+// ContainerArgs returns the run arguments of the container options
+func (p PriorityPreset) ContainerArgs() []string {
+	var args []string
+	if p.CPUShares != 0 {
+		args = append(args, "--cpu-shares", strconv.Itoa(p.CPUShares))
+	}
+	if p.BlkioWeight != 0 {
+		args = append(args, "--blkio-weight", strconv.Itoa(p.BlkioWeight))
+	}
+	return args
+}
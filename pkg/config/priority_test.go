@@ -0,0 +1,30 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetPriority(t *testing.T) {
+	preset, err := GetPriority(RunConfigurationEntry{}, "")
+	if err != nil || len(preset.ContainerArgs()) != 0 || preset.Nice != 0 {
+		t.Errorf("expected the runtime defaults for the normal priority, got %+v (%v)", preset, err)
+	}
+
+	preset, err = GetPriority(RunConfigurationEntry{Priority: PriorityLow}, "")
+	if err != nil || strings.Join(preset.ContainerArgs(), " ") != "--cpu-shares 256 --blkio-weight 100" || preset.Nice != 10 {
+		t.Errorf("unexpected low priority %+v (%v)", preset, err)
+	}
+
+	// the override takes precedence, the raw values of the entry override the preset
+	preset, err = GetPriority(RunConfigurationEntry{Priority: PriorityNormal, CPUShares: 512}, PriorityLow)
+	if err != nil || strings.Join(preset.ContainerArgs(), " ") != "--cpu-shares 512 --blkio-weight 100" {
+		t.Errorf("unexpected overridden priority %+v (%v)", preset, err)
+	}
+
+	for _, entry := range []RunConfigurationEntry{{Priority: "high"}, {CPUShares: 1}, {BlkioWeight: 5000}} {
+		if _, err = GetPriority(entry, ""); err == nil {
+			t.Errorf("expected a error for %+v", entry)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+)
+
+// Property types
+const (
+	PropertyTypeString   = "string"
+	PropertyTypeURL      = "url"
+	PropertyTypePath     = "path"
+	PropertyTypeDuration = "duration"
+	PropertyTypeInteger  = "integer"
+	PropertyTypeEnum     = "enum"
+	PropertyTypeList     = "list"
+)
+
+// PropertyOption is a valid property of the property config
+type PropertyOption struct {
+	Name string
+	Type string
+
+	// Values are the allowed values of enum properties
+	Values []string
+
+	// Example is used in the error message of invalid values
+	Example string
+}
+
+// validConfigurationOptions are all properties that can be set in the property config
+var validConfigurationOptions = []PropertyOption{
+	{Name: "http-proxy", Type: PropertyTypeURL, Example: "http://proxy:3128"},
+	{Name: "https-proxy", Type: PropertyTypeURL, Example: "http://proxy:3128"},
+	{Name: "no-proxy", Type: PropertyTypeList, Example: "registry.local,10.0.0.0/8"},
+	{Name: "global-configuration-path", Type: PropertyTypePath, Example: "/home/user/envcli"},
+	{Name: "global-configuration-repo", Type: PropertyTypeString, Example: "https://git.company.com/platform/envcli-config.git#main"},
+	{Name: "global-configuration-sync-interval", Type: PropertyTypeDuration, Example: "1h"},
+	{Name: "cache-path", Type: PropertyTypePath, Example: "/home/user/.cache"},
+	{Name: "last-update-check", Type: PropertyTypeInteger, Example: "1672531200"},
+	{Name: "history", Type: PropertyTypeEnum, Values: []string{"true", "false"}},
+	{Name: "history-size", Type: PropertyTypeInteger, Example: "1000"},
+	{Name: "log-directory", Type: PropertyTypePath, Example: "/var/log/envcli"},
+	{Name: "log-retention-count", Type: PropertyTypeInteger, Example: "50"},
+	{Name: "log-retention-age", Type: PropertyTypeDuration, Example: "168h"},
+	{Name: "container-retention-age", Type: PropertyTypeDuration, Example: "24h"},
+	{Name: "forward-git-config", Type: PropertyTypeEnum, Values: []string{"true", "false"}},
+	{Name: "config-filenames", Type: PropertyTypeList, Example: "tools.yml,.ci/envcli.yml"},
+	{Name: "require-project", Type: PropertyTypeEnum, Values: []string{"true", "false"}},
+	{Name: "catalog-url", Type: PropertyTypeURL, Example: "https://example.com/catalog.yml"},
+	{Name: "notify-after", Type: PropertyTypeDuration, Example: "2m"},
+	{Name: "container-runtime", Type: PropertyTypeEnum, Values: containerutil.RuntimeNames()},
+	{Name: "nerdctl-namespace", Type: PropertyTypeString, Example: "k8s.io"},
+	{Name: "file-sharing-probe", Type: PropertyTypeEnum, Values: []string{"true", "false"}},
+	{Name: "docker-socket", Type: PropertyTypeString, Example: "/home/user/.colima/default/docker.sock"},
+	{Name: "config-key-file", Type: PropertyTypeString, Example: "/home/user/.config/envcli/key.txt"},
+	{Name: "encrypted-configs", Type: PropertyTypeEnum, Values: []string{"decrypt", EncryptedConfigsSkip}},
+	{Name: "fallback-image", Type: PropertyTypeString, Example: "docker.io/library/ubuntu:24.04"},
+	{Name: "hooks-background-pull", Type: PropertyTypeEnum, Values: []string{"true", "false"}},
+	{Name: "emit-summary", Type: PropertyTypeEnum, Values: []string{"true", "false"}},
+	{Name: "summary-format", Type: PropertyTypeEnum, Values: []string{"text", "json"}},
+	{Name: "registry-mirrors", Type: PropertyTypeList, Example: "docker.io=mirror.company.com,ghcr.io=mirror.company.com/ghcr"},
+	{Name: "mirror-fallback", Type: PropertyTypeEnum, Values: []string{"true", "false"}},
+	{Name: "no-derive", Type: PropertyTypeEnum, Values: []string{"true", "false"}},
+	{Name: "audit-log", Type: PropertyTypeString, Example: "/var/log/envcli/audit.jsonl"},
+	{Name: "audit-strict", Type: PropertyTypeEnum, Values: []string{"true", "false"}},
+	{Name: "daemon-wait-timeout", Type: PropertyTypeDuration, Example: "30s"},
+}
+
+// maxSuggestionDistance is the maximum edit distance of a suggested property name
+const maxSuggestionDistance = 3
+
+// UnknownPropertyError is returned for property names that aren't valid
+type UnknownPropertyError struct {
+	Name string
+
+	// ValidOptions are the names of all valid properties
+	ValidOptions []string
+
+	// Suggestion is the closest valid property name, empty if none is similar
+	Suggestion string
+}
+
+func (e *UnknownPropertyError) Error() string {
+	message := "unknown property " + e.Name + ", "
+	if e.Suggestion != "" {
+		message += "did you mean " + e.Suggestion + "? "
+	}
+	return message + "valid properties are: " + strings.Join(e.ValidOptions, ", ")
+}
+
+// newUnknownPropertyError creates the error and suggests the closest valid property name
+func newUnknownPropertyError(name string) *UnknownPropertyError {
+	err := &UnknownPropertyError{Name: name, ValidOptions: GetPropertyNames()}
+
+	bestDistance := maxSuggestionDistance + 1
+	for _, option := range err.ValidOptions {
+		if distance := common.LevenshteinDistance(strings.ToLower(name), option); distance < bestDistance {
+			bestDistance = distance
+			err.Suggestion = option
+		}
+	}
+
+	return err
+}
+
+// GetPropertyOption returns the property with the given name
+func GetPropertyOption(name string) (PropertyOption, bool) {
+	for _, option := range validConfigurationOptions {
+		if option.Name == name {
+			return option, true
+		}
+	}
+	return PropertyOption{}, false
+}
+
+// GetPropertyNames returns the names of all valid properties
+func GetPropertyNames() []string {
+	var names []string
+	for _, option := range validConfigurationOptions {
+		names = append(names, option.Name)
+	}
+	return names
+}
+
+// Validate checks that the value matches the type of the property
+func (o PropertyOption) Validate(value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("%s must not be empty, use envcli config unset %s to clear it", o.Name, o.Name)
+	}
+
+	switch o.Type {
+	case PropertyTypeURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%s must be a URL like %s", o.Name, o.Example)
+		}
+	case PropertyTypePath:
+		if err := validateDirectory(value); err != nil {
+			return fmt.Errorf("%s must be a existing or creatable directory like %s: %w", o.Name, o.Example, err)
+		}
+	case PropertyTypeDuration:
+		if duration, err := time.ParseDuration(value); err != nil || duration < 0 {
+			return fmt.Errorf("%s must be a duration like %s", o.Name, o.Example)
+		}
+	case PropertyTypeInteger:
+		if number, err := strconv.Atoi(value); err != nil || number < 0 {
+			return fmt.Errorf("%s must be a non-negative number like %s", o.Name, o.Example)
+		}
+	case PropertyTypeEnum:
+		for _, allowed := range o.Values {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s", o.Name, strings.Join(o.Values, ", "))
+	case PropertyTypeList:
+		if strings.ContainsAny(value, " \t") {
+			return fmt.Errorf("%s must be a comma-separated list without spaces like %s", o.Name, o.Example)
+		}
+		if o.Name == "registry-mirrors" {
+			if _, err := ParseRegistryMirrors(value); err != nil {
+				return fmt.Errorf("%s must be a comma-separated list of registry=mirror pairs like %s", o.Name, o.Example)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateDirectory checks that the path is a directory or can be created, the closest existing parent must be a directory
+func validateDirectory(path string) error {
+	path = filepath.Clean(path)
+	if info, err := os.Stat(path); err == nil {
+		if !info.IsDir() {
+			return errors.New(path + " is a file")
+		}
+		return nil
+	}
+
+	parent := filepath.Dir(path)
+	if parent == path {
+		return errors.New(path + " doesn't exist")
+	}
+	return validateDirectory(parent)
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPropertyValidate(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, []byte{}, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var tests = []struct {
+		name  string
+		value string
+		valid bool
+		err   string
+	}{
+		{"http-proxy", "http://proxy:3128", true, ""},
+		{"http-proxy", "notaurl", false, "http-proxy must be a URL like http://proxy:3128"},
+		{"https-proxy", "", false, "must not be empty"},
+		{"cache-path", dir, true, ""},
+		{"cache-path", filepath.Join(dir, "new", "cache"), true, ""},
+		{"cache-path", "", false, "must not be empty"},
+		{"cache-path", filepath.Join(file, "cache"), false, "is a file"},
+		{"log-retention-age", "168h", true, ""},
+		{"log-retention-age", "7 days", false, "must be a duration"},
+		{"log-retention-count", "50", true, ""},
+		{"log-retention-count", "-1", false, "non-negative number"},
+		{"require-project", "true", true, ""},
+		{"require-project", "yes", false, "must be one of true, false"},
+		{"config-filenames", "tools.yml,.ci/envcli.yml", true, ""},
+		{"no-proxy", "registry.local, 10.0.0.0/8", false, "without spaces"},
+		{"registry-mirrors", "docker.io=mirror.company.com", true, ""},
+		{"registry-mirrors", "mirror.company.com", false, "registry=mirror pairs"},
+	}
+
+	for _, test := range tests {
+		option, found := GetPropertyOption(test.name)
+		if !found {
+			t.Fatalf("property %s not found", test.name)
+		}
+
+		err := option.Validate(test.value)
+		if (err == nil) != test.valid {
+			t.Errorf("%s=%q: expected valid %t, got %v", test.name, test.value, test.valid, err)
+		} else if err != nil && !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s=%q: expected %q in %v", test.name, test.value, test.err, err)
+		}
+	}
+}
+
+func TestSetPropertyConfigEntry(t *testing.T) {
+	previous := defaultConfigurationDirectory
+	SetConfigurationDirectory(t.TempDir())
+	defer SetConfigurationDirectory(previous)
+
+	if err := SetPropertyConfigEntry("http-proxy", "notaurl", false); err == nil {
+		t.Errorf("expected the invalid value to be rejected")
+	}
+	if err := SetPropertyConfigEntry("http-proxy", "notaurl", true); err != nil {
+		t.Errorf("expected force to skip the validation, got %v", err)
+	}
+	if value, _ := GetPropertyConfigEntry("http-proxy"); value != "notaurl" {
+		t.Errorf("unexpected value %s", value)
+	}
+
+	var unknownErr *UnknownPropertyError
+	if err := SetPropertyConfigEntry("unknown", "value", true); !errors.As(err, &unknownErr) || len(unknownErr.ValidOptions) != len(validConfigurationOptions) {
+		t.Errorf("expected the valid properties to be listed, got %v", err)
+	}
+	if _, err := GetPropertyConfigEntry("unknown"); !errors.As(err, &unknownErr) {
+		t.Errorf("expected get to reject unknown properties, got %v", err)
+	}
+	if err := UnsetPropertyConfigEntry("unknown"); !errors.As(err, &unknownErr) {
+		t.Errorf("expected unset to reject unknown properties, got %v", err)
+	}
+}
+
+func TestUnknownPropertySuggestion(t *testing.T) {
+	var tests = []struct {
+		name       string
+		suggestion string
+	}{
+		{"http-prxy", "http-proxy"},
+		{"https_proxy", "https-proxy"},
+		{"HTTP-PROXY", "http-proxy"},
+		{"cach-path", "cache-path"},
+		{"noproxy", "no-proxy"},
+		{"log-retention", ""},
+		{"unknown", ""},
+	}
+
+	for _, test := range tests {
+		err := newUnknownPropertyError(test.name)
+		if err.Suggestion != test.suggestion {
+			t.Errorf("%s: expected suggestion %q, got %q", test.name, test.suggestion, err.Suggestion)
+		}
+		if test.suggestion != "" && !strings.Contains(err.Error(), "did you mean "+test.suggestion+"?") {
+			t.Errorf("%s: expected the suggestion in %s", test.name, err.Error())
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+)
+
+// SelectPrunableImages returns the local images of configured repositories, whose tag isn't referenced by any of the configured images.
+// Repositories that are referenced by digest are never pruned, since the tag of the digest is unknown.
+// If olderThan is set, only images created before now - olderThan are returned.
+func SelectPrunableImages(local []containerutil.LocalImage, configured []string, olderThan time.Duration, now time.Time) []containerutil.LocalImage {
+	referencedTags := make(map[string]map[string]bool)
+	for _, image := range configured {
+		ref := ParseImageReference(image)
+		repository := ref.FullRepository()
+		if referencedTags[repository] == nil {
+			referencedTags[repository] = make(map[string]bool)
+		}
+		if ref.Digest != "" {
+			referencedTags[repository]["*"] = true
+		} else {
+			referencedTags[repository][ref.Tag] = true
+		}
+	}
+
+	var prunable []containerutil.LocalImage
+	for _, image := range local {
+		if image.Repository == "<none>" {
+			continue
+		}
+
+		tags, configuredRepository := referencedTags[ParseImageReference(image.Repository).FullRepository()]
+		if !configuredRepository || tags["*"] || tags[image.Tag] {
+			continue
+		}
+		if olderThan > 0 && image.Created.After(now.Add(-olderThan)) {
+			continue
+		}
+
+		prunable = append(prunable, image)
+	}
+
+	return prunable
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+)
+
+func TestSelectPrunableImages(t *testing.T) {
+	now := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	old := now.Add(-60 * 24 * time.Hour)
+	recent := now.Add(-24 * time.Hour)
+
+	local := []containerutil.LocalImage{
+		{Repository: "node", Tag: "18", Created: old},
+		{Repository: "docker.io/library/node", Tag: "16", Created: old},
+		{Repository: "node", Tag: "14", Created: recent},
+		{Repository: "quay.io/cidverse/build-go", Tag: "1.19", Created: old},
+		{Repository: "quay.io/cidverse/build-go", Tag: "1.20", Created: old},
+		{Repository: "golang", Tag: "1.18", Created: old},
+		{Repository: "alpine", Tag: "3.16", Created: old},
+		{Repository: "<none>", Tag: "<none>", ID: "abc", Created: old},
+	}
+	configured := []string{"docker.io/node:18", "quay.io/cidverse/build-go:1.20", "golang@sha256:1234"}
+
+	prunable := SelectPrunableImages(local, configured, 0, now)
+	if len(prunable) != 3 || prunable[0].Tag != "16" || prunable[1].Tag != "14" || prunable[2].Tag != "1.19" {
+		t.Errorf("unexpected prunable images %+v", prunable)
+	}
+
+	prunable = SelectPrunableImages(local, configured, 30*24*time.Hour, now)
+	if len(prunable) != 2 || prunable[0].Tag != "16" || prunable[1].Tag != "1.19" {
+		t.Errorf("unexpected prunable images with age filter %+v", prunable)
+	}
+}
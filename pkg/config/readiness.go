@@ -0,0 +1,50 @@
+package config
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+)
+
+// Readiness probe defaults
+const (
+	DefaultReadinessTimeout  = 60 * time.Second
+	DefaultReadinessInterval = time.Second
+)
+
+// GetReadinessProbe returns the readiness probe of the entry, nil if the entry has none
+func GetReadinessProbe(entry RunConfigurationEntry) (*containerutil.ReadinessProbe, error) {
+	if entry.Readiness == nil {
+		return nil, nil
+	}
+
+	readiness := entry.Readiness
+	probe := &containerutil.ReadinessProbe{HTTPGet: readiness.HTTPGet, Command: readiness.Command, Timeout: DefaultReadinessTimeout, Interval: DefaultReadinessInterval}
+	if (probe.HTTPGet == "") == (probe.Command == "") {
+		return nil, errors.New("readiness requires either httpGet or command")
+	}
+	if probe.HTTPGet != "" {
+		if u, err := url.Parse(probe.HTTPGet); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, errors.New("invalid readiness httpGet " + probe.HTTPGet + ", expected a http or https url like http://localhost:3000/health")
+		}
+	}
+
+	if readiness.Timeout != "" {
+		timeout, err := time.ParseDuration(readiness.Timeout)
+		if err != nil || timeout <= 0 {
+			return nil, errors.New("invalid readiness timeout " + readiness.Timeout + ", expected a duration like 60s")
+		}
+		probe.Timeout = timeout
+	}
+	if readiness.Interval != "" {
+		interval, err := time.ParseDuration(readiness.Interval)
+		if err != nil || interval <= 0 {
+			return nil, errors.New("invalid readiness interval " + readiness.Interval + ", expected a duration like 1s")
+		}
+		probe.Interval = interval
+	}
+
+	return probe, nil
+}
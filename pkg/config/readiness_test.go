@@ -0,0 +1,39 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetReadinessProbe(t *testing.T) {
+	if probe, err := GetReadinessProbe(RunConfigurationEntry{}); probe != nil || err != nil {
+		t.Errorf("expected no probe, got %+v (%v)", probe, err)
+	}
+
+	probe, err := GetReadinessProbe(RunConfigurationEntry{Readiness: &ReadinessProbe{HTTPGet: "http://localhost:3000/health"}})
+	if err != nil || probe.HTTPGet != "http://localhost:3000/health" || probe.Timeout != DefaultReadinessTimeout || probe.Interval != DefaultReadinessInterval {
+		t.Errorf("expected the defaults to be applied, got %+v (%v)", probe, err)
+	}
+	probe, err = GetReadinessProbe(RunConfigurationEntry{Readiness: &ReadinessProbe{Command: "pg_isready", Timeout: "2m", Interval: "500ms"}})
+	if err != nil || probe.Command != "pg_isready" || probe.Timeout != 2*time.Minute || probe.Interval != 500*time.Millisecond {
+		t.Errorf("unexpected probe %+v (%v)", probe, err)
+	}
+
+	var tests = []struct {
+		readiness ReadinessProbe
+		message   string
+	}{
+		{ReadinessProbe{}, "either httpGet or command"},
+		{ReadinessProbe{HTTPGet: "http://localhost", Command: "true"}, "either httpGet or command"},
+		{ReadinessProbe{HTTPGet: "localhost:3000"}, "invalid readiness httpGet"},
+		{ReadinessProbe{Command: "true", Timeout: "soon"}, "invalid readiness timeout"},
+		{ReadinessProbe{Command: "true", Interval: "-1s"}, "invalid readiness interval"},
+	}
+	for _, test := range tests {
+		readiness := test.readiness
+		if _, err = GetReadinessProbe(RunConfigurationEntry{Readiness: &readiness}); err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("%+v: expected %q, got %v", test.readiness, test.message, err)
+		}
+	}
+}
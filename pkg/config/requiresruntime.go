@@ -0,0 +1,51 @@
+package config
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/versionutil"
+)
+
+// ValidateRequiresRuntime returns a error if the entry requires a unknown runtime or a constraint is invalid
+func ValidateRequiresRuntime(entry RunConfigurationEntry) error {
+	var runtimes []string
+	for runtime := range entry.RequiresRuntime {
+		runtimes = append(runtimes, runtime)
+	}
+	sort.Strings(runtimes)
+
+	for _, runtime := range runtimes {
+		if !isRuntimeName(runtime) {
+			return errors.New("unknown container runtime " + runtime + " in requiresRuntime, supported: " + strings.Join(containerutil.RuntimeNames(), ", "))
+		}
+		if _, _, err := GetRuntimeConstraints(entry, runtime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRuntimeConstraints returns the version constraints of the entry for the runtime, found is false if the entry has no constraint for the runtime
+func GetRuntimeConstraints(entry RunConfigurationEntry, runtime string) (constraints versionutil.Constraints, found bool, err error) {
+	value, found := entry.RequiresRuntime[runtime]
+	if !found {
+		return versionutil.Constraints{}, false, nil
+	}
+	constraints, err = versionutil.ParseConstraints(value)
+	if err != nil {
+		return versionutil.Constraints{}, true, errors.New("requiresRuntime." + runtime + ": " + err.Error())
+	}
+	return constraints, true, nil
+}
+
+func isRuntimeName(name string) bool {
+	for _, runtime := range containerutil.RuntimeNames() {
+		if runtime == name {
+			return true
+		}
+	}
+	return false
+}
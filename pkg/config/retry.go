@@ -0,0 +1,26 @@
+package config
+
+import (
+	"errors"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+)
+
+// GetRetryPolicy returns the retry policy of the entry
+func GetRetryPolicy(entry RunConfigurationEntry) (containerutil.RetryPolicy, error) {
+	policy := containerutil.RetryPolicy{Retries: entry.Retries, ExitCodes: entry.RetryOnExitCodes}
+	if entry.Retries < 0 {
+		return policy, errors.New("retries must not be negative")
+	}
+
+	if entry.RetryDelay != "" {
+		delay, err := time.ParseDuration(entry.RetryDelay)
+		if err != nil {
+			return policy, errors.New("invalid retryDelay " + entry.RetryDelay + ", expected a duration like 5s")
+		}
+		policy.Delay = delay
+	}
+
+	return policy, nil
+}
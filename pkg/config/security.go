@@ -0,0 +1,152 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// validUlimits are the resource limits supported by the container runtimes
+var validUlimits = []string{"core", "cpu", "data", "fsize", "locks", "memlock", "msgqueue", "nice", "nofile", "nproc", "rss", "rtprio", "rttime", "sigpending", "stack"}
+
+// validSecurityOptKeys are the supported keys of --security-opt
+var validSecurityOptKeys = []string{"seccomp", "apparmor", "label", "no-new-privileges", "systempaths"}
+
+// ValidateUlimit checks the name and the value of a ulimit, the value is a limit (soft and hard) or soft:hard, -1 is unlimited
+func ValidateUlimit(name string, value string) error {
+	if !containsString(validUlimits, name) {
+		return errors.New("unknown ulimit " + name + ", allowed: " + strings.Join(validUlimits, ", "))
+	}
+
+	limits := strings.Split(value, ":")
+	if len(limits) > 2 {
+		return errors.New("invalid ulimit " + name + "=" + value + ", expected a limit or soft:hard like 1024:65535")
+	}
+	var parsed []int64
+	for _, limit := range limits {
+		number, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || number < -1 {
+			return errors.New("invalid ulimit " + name + "=" + value + ", expected a limit or soft:hard like 1024:65535")
+		}
+		parsed = append(parsed, number)
+	}
+	if len(parsed) == 2 && parsed[1] != -1 && (parsed[0] == -1 || parsed[0] > parsed[1]) {
+		return errors.New("invalid ulimit " + name + "=" + value + ", the soft limit exceeds the hard limit")
+	}
+
+	return nil
+}
+
+// ValidateSecurityOpt checks that the security option is supported (ex. seccomp=profile.json, apparmor=profile or no-new-privileges)
+func ValidateSecurityOpt(opt string) error {
+	key, value := splitSecurityOpt(opt)
+	if !containsString(validSecurityOptKeys, key) {
+		return errors.New("unknown security option " + opt + ", allowed: " + strings.Join(validSecurityOptKeys, ", "))
+	}
+	if value == "" && key != "no-new-privileges" {
+		return errors.New("security option " + key + " requires a value, ex. " + key + "=unconfined")
+	}
+	return nil
+}
+
+// splitSecurityOpt returns the key and the value of the option, no-new-privileges is the only option without a value or using a colon
+func splitSecurityOpt(opt string) (string, string) {
+	if strings.HasPrefix(opt, "no-new-privileges") {
+		value := strings.TrimLeft(strings.TrimPrefix(opt, "no-new-privileges"), ":=")
+		if value == "" {
+			value = "true"
+		}
+		return "no-new-privileges", value
+	}
+
+	key, value, _ := strings.Cut(opt, "=")
+	return key, value
+}
+
+// ResolveSecurityOpt resolves the path of seccomp profiles relative to the configuration file that defined the option
+func ResolveSecurityOpt(opt string, source string) string {
+	key, value := splitSecurityOpt(opt)
+	if key != "seccomp" || value == "unconfined" || strings.HasPrefix(value, "{") || filepath.IsAbs(value) || source == "" {
+		return opt
+	}
+	return key + "=" + filepath.Join(filepath.Dir(source), value)
+}
+
+// GetUlimits returns the ulimits of the entry as name=value, sorted by name
+func GetUlimits(entry RunConfigurationEntry) ([]string, error) {
+	var ulimits []string
+	for name, value := range entry.Ulimits {
+		if err := ValidateUlimit(name, value); err != nil {
+			return nil, err
+		}
+		ulimits = append(ulimits, name+"="+value)
+	}
+	sort.Strings(ulimits)
+	return ulimits, nil
+}
+
+// GetSecurityOpts returns the validated security options of the entry, with resolved seccomp profile paths
+func GetSecurityOpts(entry RunConfigurationEntry) ([]string, error) {
+	var opts []string
+	for _, opt := range entry.SecurityOpt {
+		if err := ValidateSecurityOpt(opt); err != nil {
+			return nil, err
+		}
+		opts = append(opts, ResolveSecurityOpt(opt, entry.Source))
+	}
+	return opts, nil
+}
+
+// ApplySecurityPolicies adds the security options pinned by the policies to the entry.
+// An entry that sets a pinned option to another value violates the policy: in warn mode the violation is logged and the value of the entry is used, in enforce mode a error is returned.
+func ApplySecurityPolicies(entry RunConfigurationEntry, policies []PolicyConfiguration) (RunConfigurationEntry, error) {
+	var opts []string
+	for _, opt := range entry.SecurityOpt {
+		opts = append(opts, ResolveSecurityOpt(opt, entry.Source))
+	}
+
+	for _, policy := range policies {
+		for _, pinned := range policy.SecurityOpt {
+			pinned = ResolveSecurityOpt(pinned, policy.Source)
+			pinnedKey, pinnedValue := splitSecurityOpt(pinned)
+
+			found := false
+			for _, opt := range opts {
+				key, value := splitSecurityOpt(opt)
+				if key != pinnedKey {
+					continue
+				}
+				found = true
+				if value == pinnedValue {
+					continue
+				}
+
+				message := fmt.Sprintf("security option %s of entry %s weakens the option %s pinned by the policy defined in %s", opt, entry.Name, pinned, policy.Source)
+				if policy.Mode != PolicyModeWarn {
+					return RunConfigurationEntry{}, errors.New(message)
+				}
+				log.Warn().Str("entry", entry.Name).Str("policy", policy.Source).Msg(message)
+			}
+			if !found {
+				opts = append(opts, pinned)
+			}
+		}
+	}
+
+	entry.SecurityOpt = opts
+	return entry, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
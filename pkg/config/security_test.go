@@ -0,0 +1,90 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateUlimit(t *testing.T) {
+	for _, value := range []string{"1024", "1024:65535", "-1", "1024:-1"} {
+		if err := ValidateUlimit("nofile", value); err != nil {
+			t.Errorf("expected %s to be valid, got %v", value, err)
+		}
+	}
+	for _, value := range []string{"", "many", "1:2:3", "65535:1024", "-2"} {
+		if err := ValidateUlimit("nofile", value); err == nil {
+			t.Errorf("expected %s to be invalid", value)
+		}
+	}
+	if err := ValidateUlimit("files", "1024"); err == nil {
+		t.Errorf("expected unknown ulimit to be invalid")
+	}
+}
+
+func TestValidateSecurityOpt(t *testing.T) {
+	for _, opt := range []string{"seccomp=unconfined", "apparmor=docker-default", "label=disable", "no-new-privileges", "no-new-privileges:true"} {
+		if err := ValidateSecurityOpt(opt); err != nil {
+			t.Errorf("expected %s to be valid, got %v", opt, err)
+		}
+	}
+	for _, opt := range []string{"privileged", "seccomp", "apparmor="} {
+		if err := ValidateSecurityOpt(opt); err == nil {
+			t.Errorf("expected %s to be invalid", opt)
+		}
+	}
+}
+
+func TestGetSecurityOptsResolvesProfiles(t *testing.T) {
+	source := filepath.Join("/project", ".envcli.yml")
+	entry := RunConfigurationEntry{Source: source, SecurityOpt: []string{"seccomp=profiles/go.json", "seccomp=unconfined", "apparmor=custom"}}
+
+	opts, err := GetSecurityOpts(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"seccomp=" + filepath.Join("/project", "profiles/go.json"), "seccomp=unconfined", "apparmor=custom"}
+	if !reflect.DeepEqual(opts, expected) {
+		t.Errorf("expected %v, got %v", expected, opts)
+	}
+}
+
+func TestGetUlimitsSorted(t *testing.T) {
+	ulimits, err := GetUlimits(RunConfigurationEntry{Ulimits: map[string]string{"nproc": "512", "nofile": "1024:65535"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ulimits, []string{"nofile=1024:65535", "nproc=512"}) {
+		t.Errorf("unexpected ulimits %v", ulimits)
+	}
+}
+
+func TestApplySecurityPolicies(t *testing.T) {
+	policies := []PolicyConfiguration{{SecurityOpt: []string{"no-new-privileges", "apparmor=docker-default"}, Source: "/etc/envcli/.envcli.yml"}}
+
+	// pinned options are added
+	entry, err := ApplySecurityPolicies(RunConfigurationEntry{Name: "go", SecurityOpt: []string{"label=disable"}}, policies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(entry.SecurityOpt, []string{"label=disable", "no-new-privileges", "apparmor=docker-default"}) {
+		t.Errorf("unexpected security options %v", entry.SecurityOpt)
+	}
+
+	// weakening a pinned option is rejected
+	_, err = ApplySecurityPolicies(RunConfigurationEntry{Name: "go", SecurityOpt: []string{"apparmor=unconfined"}}, policies)
+	if err == nil || !strings.Contains(err.Error(), "/etc/envcli/.envcli.yml") {
+		t.Errorf("expected the policy to reject the entry, got %v", err)
+	}
+
+	// warn mode keeps the value of the entry
+	policies[0].Mode = PolicyModeWarn
+	entry, err = ApplySecurityPolicies(RunConfigurationEntry{Name: "go", SecurityOpt: []string{"apparmor=unconfined"}}, policies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(entry.SecurityOpt, []string{"apparmor=unconfined", "no-new-privileges"}) {
+		t.Errorf("unexpected security options %v", entry.SecurityOpt)
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// SignaturePolicy requires cosign signatures of the images, it is only supported in the policy of the global configuration
+type SignaturePolicy struct {
+	// images need a valid signature of the public key to be run
+	Required bool `yaml:"required"`
+
+	// cosign public key used to verify the signatures, relative to the configuration file
+	PublicKey string `yaml:"publicKey"`
+
+	// warn or enforce, the mode of the policy (internal use only)
+	Mode string `yaml:"-"`
+
+	// the configuration file that defined this policy (internal use only)
+	Source string `yaml:"-"`
+}
+
+// ApplySignaturePolicy sets the signature policy required by the policies on the entry, the public key is resolved relative to the configuration file of the policy
+func ApplySignaturePolicy(entry RunConfigurationEntry, policies []PolicyConfiguration) (RunConfigurationEntry, error) {
+	for _, policy := range policies {
+		if !policy.Signature.Required {
+			continue
+		}
+		if policy.Signature.PublicKey == "" {
+			return RunConfigurationEntry{}, errors.New("the signature policy defined in " + policy.Source + " requires signatures, but sets no publicKey")
+		}
+
+		signature := policy.Signature
+		if !filepath.IsAbs(signature.PublicKey) && policy.Source != "" {
+			signature.PublicKey = filepath.Join(filepath.Dir(policy.Source), signature.PublicKey)
+		}
+		signature.Mode, signature.Source = policy.Mode, policy.Source
+		entry.Signature = signature
+	}
+	return entry, nil
+}
+
+// PublicKeyFingerprint returns the SHA256 fingerprint of the PEM encoded public key
+func PublicKeyFingerprint(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return "", errors.New(path + " is not a PEM encoded public key")
+	}
+
+	sum := sha256.Sum256(block.Bytes)
+	return "SHA256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// GetSignatureCacheDirectory returns the directory used to cache the verified image signatures (cache-path/signatures or the user cache directory)
+func GetSignatureCacheDirectory(propConfig PropertyConfigurationFile) string {
+	return getCacheSubdirectory(propConfig, "signatures")
+}
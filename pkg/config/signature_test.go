@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplySignaturePolicy(t *testing.T) {
+	policies := []PolicyConfiguration{
+		{AllowedImagePatterns: []string{"**"}, Source: "/etc/envcli/.envcli.yml"},
+		{Signature: SignaturePolicy{Required: true, PublicKey: "cosign.pub"}, Mode: PolicyModeWarn, Source: "/etc/envcli/.envcli.yml"},
+	}
+	entry, err := ApplySignaturePolicy(RunConfigurationEntry{Name: "node"}, policies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := SignaturePolicy{Required: true, PublicKey: filepath.Join("/etc/envcli", "cosign.pub"), Mode: PolicyModeWarn, Source: "/etc/envcli/.envcli.yml"}
+	if entry.Signature != expected {
+		t.Errorf("expected %+v, got %+v", expected, entry.Signature)
+	}
+
+	if entry, err = ApplySignaturePolicy(RunConfigurationEntry{Name: "node"}, policies[:1]); err != nil || entry.Signature.Required {
+		t.Errorf("expected no signature requirement, got %+v (%v)", entry.Signature, err)
+	}
+	if _, err = ApplySignaturePolicy(RunConfigurationEntry{}, []PolicyConfiguration{{Signature: SignaturePolicy{Required: true}}}); err == nil {
+		t.Errorf("expected a error for a signature policy without public key")
+	}
+}
+
+func TestPublicKeyFingerprint(t *testing.T) {
+	key := filepath.Join(t.TempDir(), "cosign.pub")
+	if err := os.WriteFile(key, []byte("-----BEGIN PUBLIC KEY-----\nAAAA\n-----END PUBLIC KEY-----\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fingerprint, err := PublicKeyFingerprint(key)
+	if err != nil || !strings.HasPrefix(fingerprint, "SHA256:") || len(fingerprint) != 71 {
+		t.Errorf("unexpected fingerprint %s (%v)", fingerprint, err)
+	}
+
+	if err = os.WriteFile(key, []byte("not a key"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = PublicKeyFingerprint(key); err == nil {
+		t.Errorf("expected a error for a invalid key")
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+)
+
+// maxCommandSuggestions is the maximum number of suggested commands
+const maxCommandSuggestions = 3
+
+// NoMatchError is returned if no entry provides the command or is named like it
+type NoMatchError struct {
+	Command string
+
+	// Suggestions are the closest provided commands and entry names
+	Suggestions []string
+
+	// Hints explain why the command may be missing, ex. a entry that provides it has been skipped
+	Hints []string
+}
+
+func (e *NoMatchError) Error() string {
+	message := "no configuration for command " + e.Command + " found"
+	if len(e.Suggestions) > 0 {
+		message += ", did you mean " + strings.Join(e.Suggestions, ", ") + "?"
+	}
+	for _, hint := range e.Hints {
+		message += "\n" + hint
+	}
+	return message
+}
+
+// SuggestCommands returns the provided commands and entry names closest to the command, by their edit distance.
+// Only the names with the smallest distance are suggested, if it is within half of the command length (at least 1, at most 3).
+func SuggestCommands(cfg ConfigurationFile, command string) []string {
+	// the best distance starts at the maximum distance and shrinks with each closer name
+	bestDistance := (len(command) + 1) / 2
+	if bestDistance < 1 {
+		bestDistance = 1
+	} else if bestDistance > 3 {
+		bestDistance = 3
+	}
+
+	distances := make(map[string]int)
+	for _, entry := range cfg.Images {
+		for _, name := range append([]string{entry.Name}, entry.Provides...) {
+			distance := common.LevenshteinDistance(strings.ToLower(command), strings.ToLower(name))
+			if distance <= bestDistance {
+				distances[name] = distance
+				bestDistance = distance
+			}
+		}
+	}
+
+	var suggestions []string
+	for name, distance := range distances {
+		if distance == bestDistance {
+			suggestions = append(suggestions, name)
+		}
+	}
+	sort.Strings(suggestions)
+	if len(suggestions) > maxCommandSuggestions {
+		suggestions = suggestions[:maxCommandSuggestions]
+	}
+	return suggestions
+}
+
+// missingCommandHints explains why the command may be missing: entries that provide it but have been skipped, or a global configuration that hasn't been loaded
+func missingCommandHints(cfg ConfigurationFile, command string) []string {
+	var hints []string
+	for _, skipped := range cfg.SkippedImages {
+		if skipped.Entry.Name == command || containsString(skipped.Entry.Provides, command) {
+			hints = append(hints, "entry "+skipped.Entry.Name+" of "+skipped.Entry.Source+" provides the command, but has been skipped: "+skipped.Reason)
+		}
+	}
+
+	if cfg.GlobalConfigFile == "" || containsString(cfg.ConfigFiles, cfg.GlobalConfigFile) {
+		return hints
+	}
+	hint := "the global configuration " + cfg.GlobalConfigFile + " doesn't exist"
+	defaultFile := filepath.Join(defaultConfigurationDirectory, ".envcli.yml")
+	if filepath.Clean(cfg.GlobalConfigFile) != filepath.Clean(defaultFile) {
+		hint += ", check the global-configuration-path property"
+		if defaultConfig, err := LoadProjectConfig(defaultFile); err == nil {
+			for _, entry := range defaultConfig.Images {
+				if entry.Name == command || containsString(entry.Provides, command) {
+					hint += " - " + defaultFile + " provides the command"
+					break
+				}
+			}
+		}
+	}
+	return append(hints, hint)
+}
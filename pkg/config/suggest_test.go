@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSuggestCommands(t *testing.T) {
+	cfg := ConfigurationFile{Images: []RunConfigurationEntry{
+		{Name: "node", Provides: []string{"node", "npm", "npx"}},
+		{Name: "golang", Provides: []string{"go", "gofmt"}},
+	}}
+
+	cases := []struct {
+		command  string
+		expected []string
+	}{
+		{"npmm", []string{"npm"}},
+		{"NPM", []string{"npm"}},
+		{"gofmtt", []string{"gofmt"}},
+		{"nmp", []string{"npm", "npx"}},
+		{"terraform", nil},
+	}
+	for _, c := range cases {
+		if suggestions := SuggestCommands(cfg, c.command); !reflect.DeepEqual(suggestions, c.expected) {
+			t.Errorf("expected %v for %s, got %v", c.expected, c.command, suggestions)
+		}
+	}
+}
+
+func TestFindCommandMatchNoMatch(t *testing.T) {
+	cfg := ConfigurationFile{
+		Images:        []RunConfigurationEntry{{Name: "node", Provides: []string{"npm"}}},
+		SkippedImages: []SkippedEntry{{Entry: RunConfigurationEntry{Name: "terraform", Provides: []string{"terraform"}, Source: ".envcli.yml"}, Reason: `when condition os == "windows" is false`}},
+	}
+
+	_, _, err := FindCommandMatch(cfg, "npmm")
+	var noMatch *NoMatchError
+	if !errors.As(err, &noMatch) || !strings.Contains(err.Error(), "did you mean npm?") {
+		t.Errorf("expected a suggestion, got %v", err)
+	}
+
+	_, _, err = FindCommandMatch(cfg, "terraform")
+	if err == nil || !strings.Contains(err.Error(), "entry terraform of .envcli.yml provides the command, but has been skipped") {
+		t.Errorf("expected a hint about the skipped entry, got %v", err)
+	}
+}
+
+func TestMissingCommandHintsGlobalConfig(t *testing.T) {
+	previous := defaultConfigurationDirectory
+	SetConfigurationDirectory(t.TempDir())
+	defer SetConfigurationDirectory(previous)
+
+	if err := os.WriteFile(filepath.Join(defaultConfigurationDirectory, ".envcli.yml"), []byte("images:\n  - name: node\n    image: node:20\n    provides:\n      - npm\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the global configuration-path points to a directory without config
+	cfg := ConfigurationFile{GlobalConfigFile: "/missing/.envcli.yml"}
+	hints := missingCommandHints(cfg, "npm")
+	if len(hints) != 1 || !strings.Contains(hints[0], "global-configuration-path") || !strings.Contains(hints[0], "provides the command") {
+		t.Errorf("unexpected hints %v", hints)
+	}
+
+	// loaded global configuration
+	cfg = ConfigurationFile{GlobalConfigFile: "/global/.envcli.yml", ConfigFiles: []string{"/global/.envcli.yml"}}
+	if hints = missingCommandHints(cfg, "npm"); len(hints) != 0 {
+		t.Errorf("expected no hints, got %v", hints)
+	}
+}
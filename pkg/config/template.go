@@ -0,0 +1,146 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+// TemplateContext holds the project metadata available in the templates of the env and defaultArgs values (ex. {{ .GitBranch }}).
+// The git and user values are computed on first use, so entries without templates don't run git.
+type TemplateContext struct {
+	// ProjectDirectory is the root directory of the project
+	ProjectDirectory string
+
+	// OS and Arch of the host (ex. linux, amd64)
+	OS   string
+	Arch string
+
+	ctx    context.Context
+	values map[string]string
+}
+
+// NewTemplateContext creates the template context of the project directory
+func NewTemplateContext(ctx context.Context, projectDirectory string) *TemplateContext {
+	return &TemplateContext{ProjectDirectory: projectDirectory, OS: runtime.GOOS, Arch: runtime.GOARCH, ctx: ctx, values: map[string]string{}}
+}
+
+// ProjectName returns the name of the project directory
+func (c *TemplateContext) ProjectName() string {
+	return filepath.Base(c.ProjectDirectory)
+}
+
+// GitBranch returns the checked out branch, empty outside of git repositories
+func (c *TemplateContext) GitBranch() string {
+	return c.lazy("GitBranch", func() string { return c.git("rev-parse", "--abbrev-ref", "HEAD") })
+}
+
+// GitCommit returns the hash of the checked out commit, empty outside of git repositories
+func (c *TemplateContext) GitCommit() string {
+	return c.lazy("GitCommit", func() string { return c.git("rev-parse", "HEAD") })
+}
+
+// UserName returns the name of the user on the host
+func (c *TemplateContext) UserName() string {
+	return c.lazy("UserName", func() string {
+		current, err := user.Current()
+		if err != nil {
+			return ""
+		}
+		return current.Username
+	})
+}
+
+// lazy computes the value once
+func (c *TemplateContext) lazy(name string, compute func() string) string {
+	if value, ok := c.values[name]; ok {
+		return value
+	}
+	value := compute()
+	c.values[name] = value
+	return value
+}
+
+// git returns the trimmed output of the git command, empty if it fails
+func (c *TemplateContext) git(args ...string) string {
+	cmd := exec.CommandContext(c.ctx, "git", args...)
+	cmd.Dir = c.ProjectDirectory
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// RenderTemplate renders the value as go template, values without template actions are returned as is
+func RenderTemplate(name string, value string, data *TemplateContext) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", err
+	}
+	var result bytes.Buffer
+	if err = tmpl.Execute(&result, data); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// ValidateTemplate returns a error if the value is not a valid template
+func ValidateTemplate(name string, value string) error {
+	if !strings.Contains(value, "{{") {
+		return nil
+	}
+	_, err := template.New(name).Parse(value)
+	return err
+}
+
+// ValidateEnv returns a error if the environment variable isn't in the NAME=value format
+func ValidateEnv(env string) error {
+	if name, _, found := strings.Cut(env, "="); !found || name == "" {
+		return fmt.Errorf("invalid env %s, expected NAME=value", env)
+	}
+	return nil
+}
+
+// RenderEntryTemplates renders the templates of the env and defaultArgs values of the entry, the errors name the field of the template
+func RenderEntryTemplates(entry RunConfigurationEntry, data *TemplateContext) (RunConfigurationEntry, error) {
+	if entry.Env != nil {
+		env := make([]string, 0, len(entry.Env))
+		for i, value := range entry.Env {
+			rendered, err := RenderTemplate(fmt.Sprintf("%s.env[%d]", entry.Name, i), value, data)
+			if err != nil {
+				return entry, err
+			}
+			env = append(env, rendered)
+		}
+		entry.Env = env
+	}
+
+	if entry.DefaultArgs != nil {
+		defaultArgs := make(map[string][]string, len(entry.DefaultArgs))
+		for command, args := range entry.DefaultArgs {
+			var renderedArgs []string
+			for i, value := range args {
+				rendered, err := RenderTemplate(fmt.Sprintf("%s.defaultArgs.%s[%d]", entry.Name, command, i), value, data)
+				if err != nil {
+					return entry, err
+				}
+				renderedArgs = append(renderedArgs, rendered)
+			}
+			defaultArgs[command] = renderedArgs
+		}
+		entry.DefaultArgs = defaultArgs
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenderEntryTemplates(t *testing.T) {
+	data := NewTemplateContext(context.Background(), "/home/user/my-project")
+	entry := RunConfigurationEntry{
+		Name:        "node",
+		Env:         []string{"PROJECT={{ .ProjectName }}", "PLATFORM={{ .OS }}/{{ .Arch }}", "PLAIN=value"},
+		DefaultArgs: map[string][]string{"npm": {"--prefix", "{{ .ProjectDirectory }}"}},
+	}
+
+	rendered, err := RenderEntryTemplates(entry, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rendered.Env, []string{"PROJECT=my-project", "PLATFORM=" + data.OS + "/" + data.Arch, "PLAIN=value"}) {
+		t.Errorf("unexpected env %v", rendered.Env)
+	}
+	if !reflect.DeepEqual(rendered.DefaultArgs["npm"], []string{"--prefix", "/home/user/my-project"}) {
+		t.Errorf("unexpected default args %v", rendered.DefaultArgs)
+	}
+	if entry.Env[0] != "PROJECT={{ .ProjectName }}" {
+		t.Errorf("expected the entry to be unchanged, got %v", entry.Env)
+	}
+	if len(data.values) != 0 {
+		t.Errorf("expected no lazy values to be computed, got %v", data.values)
+	}
+
+	entry.Env = []string{"TAG={{ .Unknown }}"}
+	if _, err = RenderEntryTemplates(entry, data); err == nil || !strings.Contains(err.Error(), "node.env[0]") {
+		t.Errorf("expected a error naming the location, got %v", err)
+	}
+}
+
+func TestTemplateContextGit(t *testing.T) {
+	data := NewTemplateContext(context.Background(), t.TempDir())
+	if branch := data.GitBranch(); branch != "" {
+		t.Errorf("expected no branch outside of a git repository, got %s", branch)
+	}
+	if _, ok := data.values["GitBranch"]; !ok {
+		t.Errorf("expected the branch to be cached")
+	}
+}
+
+func TestValidateEnv(t *testing.T) {
+	if ValidateEnv("NAME=value") != nil || ValidateEnv("NAME=") != nil {
+		t.Errorf("expected valid env")
+	}
+	if ValidateEnv("NAME") == nil || ValidateEnv("=value") == nil {
+		t.Errorf("expected invalid env to be rejected")
+	}
+	if ValidateTemplate("env", "TAG={{ .GitBranch") == nil {
+		t.Errorf("expected a invalid template to be rejected")
+	}
+}
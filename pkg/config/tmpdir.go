@@ -0,0 +1,26 @@
+package config
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+// ValidateTmpDirs returns a error if a tmpDirs path isn't a absolute container path or is used twice
+func ValidateTmpDirs(dirs []string) error {
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if !strings.HasPrefix(dir, "/") {
+			return errors.New("invalid tmpDirs path " + dir + ", expected a absolute path within the container (ex. /workspace-tmp)")
+		}
+		cleaned := path.Clean(dir)
+		if cleaned == "/" {
+			return errors.New("invalid tmpDirs path " + dir + ", the root directory can't be mounted")
+		}
+		if seen[cleaned] {
+			return errors.New("duplicate tmpDirs path " + dir)
+		}
+		seen[cleaned] = true
+	}
+	return nil
+}
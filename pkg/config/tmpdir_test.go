@@ -0,0 +1,16 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestValidateTmpDirs(t *testing.T) {
+	if err := ValidateTmpDirs([]string{"/workspace-tmp", "/var/cache/tool/"}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	for _, dirs := range [][]string{{"workspace-tmp"}, {`C:\tmp`}, {"/"}, {"/tmp", "/tmp/"}} {
+		if err := ValidateTmpDirs(dirs); err == nil {
+			t.Errorf("expected a error for %v", dirs)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/atomicfile"
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v2"
+)
+
+// trustFile stores the checksums of the trusted project configs
+var trustFile = ".envcli-trust.yml"
+
+// TrustStore is the schema of the trust file
+type TrustStore struct {
+	// sha256 checksums of the trusted config files, by absolute path
+	Files map[string]string `yaml:"files"`
+}
+
+// IsTrusted returns true if the config file of the hooks has been trusted and neither the file nor the scripts called by the hooks have been modified since
+func IsTrusted(hooks ProjectHooks) (bool, error) {
+	checksum, err := trustChecksum(hooks)
+	if err != nil {
+		return false, err
+	}
+
+	store, err := loadTrustStore()
+	if err != nil {
+		return false, err
+	}
+	return store.Files[absPath(hooks.ConfigFile)] == checksum, nil
+}
+
+// Trust marks the current content of the config file of the hooks and of the scripts called by the hooks as trusted
+func Trust(hooks ProjectHooks) error {
+	file := hooks.ConfigFile
+	checksum, err := trustChecksum(hooks)
+	if err != nil {
+		return err
+	}
+
+	store, err := loadTrustStore()
+	if err != nil {
+		return err
+	}
+	store.Files[absPath(file)] = checksum
+
+	content, err := yaml.Marshal(&store)
+	if err != nil {
+		return err
+	}
+	log.Debug().Str("file", file).Str("checksum", checksum).Msg("trusting config file")
+	if err = os.MkdirAll(defaultConfigurationDirectory, 0700); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filepath.Join(defaultConfigurationDirectory, trustFile), content, 0600)
+}
+
+func loadTrustStore() (TrustStore, error) {
+	store := TrustStore{Files: make(map[string]string)}
+
+	content, err := os.ReadFile(filepath.Join(defaultConfigurationDirectory, trustFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	} else if err != nil {
+		return store, err
+	}
+
+	if err = yaml.Unmarshal(content, &store); err != nil {
+		return store, err
+	}
+	if store.Files == nil {
+		store.Files = make(map[string]string)
+	}
+	return store, nil
+}
+
+// trustChecksum returns the checksum of the config file and of the files referenced by the hooks (ex. ./scripts/pre.sh), a modified script needs to be trusted again
+func trustChecksum(hooks ProjectHooks) (string, error) {
+	content, err := os.ReadFile(hooks.ConfigFile)
+	if err != nil {
+		return "", err
+	}
+	// only the envcli section of a embedded config is trusted, changes of the other tools don't require to trust the file again
+	if IsEmbeddedConfig(hooks.ConfigFile) {
+		if content, _, err = extractEmbeddedConfig(hooks.ConfigFile, content); err != nil {
+			return "", err
+		}
+	}
+
+	hash := sha256.New()
+	hash.Write(content)
+	for _, file := range HookFiles(hooks) {
+		script, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hash, "\x00%s\x00%d\x00", file, len(script))
+		hash.Write(script)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// HookFiles returns the files within the project referenced by the arguments of the hooks, ex. ./scripts/pre.sh of the preRun hook ./scripts/pre.sh --db postgres.
+// The targets of output redirections (> out.txt) are written by the hooks and are skipped.
+func HookFiles(hooks ProjectHooks) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, hook := range []string{hooks.PreRun, hooks.PostRun} {
+		redirect := false
+		for _, arg := range common.SplitArgs(hook) {
+			if redirect || strings.HasPrefix(strings.TrimLeft(arg, "0123456789&"), ">") {
+				redirect = strings.HasSuffix(arg, ">")
+				continue
+			}
+			file := arg
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(hooks.Directory, file)
+			}
+			if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() || seen[file] {
+				continue
+			}
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+func absPath(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+	return file
+}
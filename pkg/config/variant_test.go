@@ -0,0 +1,61 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyVariant(t *testing.T) {
+	entry := RunConfigurationEntry{
+		Name:     "terraform",
+		Image:    "terraform:1.5",
+		Provides: []string{"terraform"},
+		Env:      []string{"TF_WORKSPACE=dev"},
+		Variants: map[string]RunConfigurationEntry{
+			"prod":    {Env: []string{"+AWS_PROFILE=prod"}},
+			"staging": {Image: "terraform:1.6", Env: []string{"TF_WORKSPACE=staging"}},
+		},
+	}
+	getenv := func(name string) string { return "" }
+
+	result, err := ApplyVariant(entry, "prod", getenv)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if result.Name != "terraform" || result.Image != "terraform:1.5" || result.Variant != "prod" || !reflect.DeepEqual(result.Env, []string{"TF_WORKSPACE=dev", "AWS_PROFILE=prod"}) {
+		t.Errorf("unexpected entry %+v", result)
+	}
+
+	result, err = ApplyVariant(entry, "", func(name string) string { return map[string]string{VariantEnv: "staging"}[name] })
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if result.Image != "terraform:1.6" || !reflect.DeepEqual(result.Env, []string{"TF_WORKSPACE=staging"}) || !reflect.DeepEqual(result.Provides, []string{"terraform"}) {
+		t.Errorf("unexpected entry %+v", result)
+	}
+
+	// without a variant
+	if result, err = ApplyVariant(entry, "", getenv); err != nil || result.Variant != "" || result.Image != "terraform:1.5" {
+		t.Errorf("expected the entry to be unchanged, got %+v (%v)", result, err)
+	}
+
+	// unknown variants
+	if _, err = ApplyVariant(entry, "qa", getenv); err == nil || !strings.Contains(err.Error(), "available: prod, staging") {
+		t.Errorf("expected the available variants to be listed, got %v", err)
+	}
+	plain := RunConfigurationEntry{Name: "node"}
+	if _, err = ApplyVariant(plain, "qa", getenv); err == nil {
+		t.Errorf("expected a error for a entry without variants")
+	}
+	if _, err = ApplyVariant(plain, "", func(name string) string { return "qa" }); err != nil {
+		t.Errorf("expected %s to be ignored for entries without variants, got %v", VariantEnv, err)
+	}
+}
+
+func TestValidateVariants(t *testing.T) {
+	entry := RunConfigurationEntry{Name: "terraform", Variants: map[string]RunConfigurationEntry{"prod": {Extends: "other"}}}
+	if err := ValidateVariants(entry); err == nil {
+		t.Errorf("expected a error for a variant setting extends")
+	}
+}
@@ -0,0 +1,116 @@
+package containerutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/rs/zerolog/log"
+)
+
+// CacheImageDigestLabel is the label of cache images, that holds the digest of the packed cache directory
+const CacheImageDigestLabel = "envcli.cache.digest"
+
+// cacheImageDockerfile packs the build context (the cache directory) into the single layer of the image
+const cacheImageDockerfile = "FROM scratch\nCOPY . /cache/\n"
+
+// DirectoryDigest returns a digest over the paths, sizes, modes and modification times of all files within the directory and their total size
+func DirectoryDigest(dir string) (string, int64, error) {
+	hash := sha256.New()
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relative, _ := filepath.Rel(dir, path)
+		if !entry.IsDir() {
+			size += info.Size()
+		}
+
+		_, err = fmt.Fprintf(hash, "%s\x00%d\x00%s\x00%d\n", filepath.ToSlash(relative), info.Size(), info.Mode(), info.ModTime().UnixNano())
+		return err
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), size, nil
+}
+
+// cacheImageDigest returns the digest label of the local image, empty if the image doesn't exist
+func cacheImageDigest(ctx context.Context, runtime ContainerRuntime, image string) string {
+	output, err := runtime.Output(ctx, runtimeCommand(runtime, "image", "inspect", "--format", "{{json .Config.Labels}}", image))
+	if err != nil {
+		return ""
+	}
+
+	var labels map[string]string
+	_ = json.Unmarshal([]byte(output), &labels)
+	return labels[CacheImageDigestLabel]
+}
+
+// PushCacheImage packs the cache directory into a image with a single layer and pushes it to the registry, the build and push progress is written to the output.
+// The image is only built again if the digest of the directory changed, the runtime skips uploading layers that already exist in the registry.
+func PushCacheImage(ctx context.Context, runtime ContainerRuntime, dir string, image string, digest string, output io.Writer) error {
+	if cacheImageDigest(ctx, runtime, image) == digest {
+		log.Info().Str("image", image).Msg("cache is unchanged, reusing the existing cache image")
+	} else {
+		command := runtimeCommand(runtime, "build", "-t", image, "--label", CacheImageDigestLabel+"="+digest, "-f", "-", dir)
+		if err := runtime.Exec(ctx, command, strings.NewReader(cacheImageDockerfile), output, output); err != nil {
+			return fmt.Errorf("failed to pack %s into image %s: %w", dir, image, err)
+		}
+	}
+
+	if err := runtime.Exec(ctx, runtimeCommand(runtime, "push", image), nil, output, output); err != nil {
+		return fmt.Errorf("failed to push image %s: %w", image, err)
+	}
+	return nil
+}
+
+// PullCacheImage pulls the cache image and extracts its content into the cache directory, existing files are overwritten.
+// The extraction is refused if the uncompressed image is larger than maxSize (0 = unlimited).
+func PullCacheImage(ctx context.Context, runtime ContainerRuntime, image string, dir string, maxSize int64, output io.Writer) error {
+	if err := runtime.Exec(ctx, runtimeCommand(runtime, "pull", image), nil, output, output); err != nil {
+		return exitcode.New(exitcode.ImagePullFailure, fmt.Errorf("failed to pull image %s: %w", image, err))
+	}
+
+	if maxSize > 0 {
+		sizeOutput, err := runtime.Output(ctx, runtimeCommand(runtime, "image", "inspect", "--format", "{{.Size}}", image))
+		if err != nil {
+			return fmt.Errorf("failed to inspect image %s: %w", image, err)
+		}
+		if size, _ := strconv.ParseInt(sizeOutput, 10, 64); size > maxSize {
+			return fmt.Errorf("cache image %s has %d bytes, which exceeds the maximum size of %d bytes", image, size, maxSize)
+		}
+	}
+
+	// the files are copied out of a temporary container, which is never started
+	container, err := runtime.Output(ctx, runtimeCommand(runtime, "create", image, "/noop"))
+	if err != nil {
+		return fmt.Errorf("failed to create a container of image %s: %w", image, err)
+	}
+	if container == "" {
+		return errors.New("failed to create a container of image " + image)
+	}
+	defer func() {
+		_ = RemoveContainer(context.Background(), runtime, container)
+	}()
+
+	if _, err = runtime.Output(ctx, runtimeCommand(runtime, "cp", container+":/cache/.", dir)); err != nil {
+		return fmt.Errorf("failed to extract image %s into %s: %w", image, dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package containerutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDirectoryDigest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "package.tgz"), []byte("content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	digest, size, err := DirectoryDigest(dir)
+	if err != nil || size != 7 || !strings.HasPrefix(digest, "sha256:") {
+		t.Fatalf("unexpected digest %s with size %d (%v)", digest, size, err)
+	}
+	if again, _, _ := DirectoryDigest(dir); again != digest {
+		t.Errorf("expected the digest of the unchanged directory to be stable")
+	}
+
+	later := time.Now().Add(time.Hour)
+	if err = os.Chtimes(filepath.Join(dir, "package.tgz"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if changed, _, _ := DirectoryDigest(dir); changed == digest {
+		t.Errorf("expected the digest to change once a file has been modified")
+	}
+}
+
+func TestPushCacheImage(t *testing.T) {
+	// unchanged cache, only pushed
+	runtime := &fakeRuntime{name: "docker", output: func(command string) (string, error) {
+		return `{"envcli.cache.digest":"sha256:abc"}`, nil
+	}}
+	if err := PushCacheImage(context.Background(), runtime, "/cache/npm", "registry.local/caches/npm:main", "sha256:abc", &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(runtime.commands) != 2 || runtime.commands[1] != "docker push registry.local/caches/npm:main" {
+		t.Errorf("expected the build to be skipped, got %v", runtime.commands)
+	}
+
+	// changed cache
+	runtime = &fakeRuntime{name: "docker", output: func(command string) (string, error) { return "null", nil }}
+	if err := PushCacheImage(context.Background(), runtime, "/cache/npm", "registry.local/caches/npm:main", "sha256:def", &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(runtime.commands) != 3 || runtime.commands[1] != `docker build -t registry.local/caches/npm:main --label envcli.cache.digest=sha256:def -f - /cache/npm` {
+		t.Errorf("expected the image to be built, got %v", runtime.commands)
+	}
+}
+
+func TestPullCacheImage(t *testing.T) {
+	runtime := &fakeRuntime{name: "docker", output: func(command string) (string, error) {
+		switch {
+		case strings.Contains(command, "{{.Size}}"):
+			return "2000", nil
+		case strings.Contains(command, " create "):
+			return "c0ffee", nil
+		}
+		return "", nil
+	}}
+	if err := PullCacheImage(context.Background(), runtime, "registry.local/caches/npm:main", "/cache/npm", 5000, &bytes.Buffer{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.Contains(strings.Join(runtime.commands, "|"), `docker cp c0ffee:/cache/. /cache/npm|docker rm -f c0ffee`) {
+		t.Errorf("unexpected commands %v", runtime.commands)
+	}
+
+	// size limit
+	runtime.commands = nil
+	if err := PullCacheImage(context.Background(), runtime, "registry.local/caches/npm:main", "/cache/npm", 1000, &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "exceeds the maximum size") {
+		t.Errorf("expected the size limit to be enforced, got %v", err)
+	}
+	for _, command := range runtime.commands {
+		if strings.Contains(command, " cp ") {
+			t.Errorf("expected no extraction, got %v", runtime.commands)
+		}
+	}
+
+	// pull failure
+	runtime = &fakeRuntime{name: "docker", output: func(command string) (string, error) { return "", errors.New("exit status 1") }}
+	if err := PullCacheImage(context.Background(), runtime, "registry.local/caches/npm:main", "/cache/npm", 0, &bytes.Buffer{}); err == nil {
+		t.Errorf("expected the pull failure to be returned")
+	}
+}
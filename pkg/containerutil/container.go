@@ -0,0 +1,242 @@
+package containerutil
+
+import (
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+
+	"github.com/cidverse/cidverseutils/pkg/cihelper"
+	"github.com/cidverse/cidverseutils/pkg/containerruntime"
+)
+
+// excludedHostVariables are not passed by AddAllEnvironmentVariables, they describe the host and would break the container (ex. PATH, HOME)
+var excludedHostVariables = []string{
+	// unix
+	"_", "PWD", "OLDPWD", "PATH", "HOME", "HOSTNAME", "TERM", "SHLVL",
+	// windows
+	"PROGRAMDATA", "PROGRAMFILES", "PROGRAMW6432", "COMMONPROGRAMFILES", "COMMONPROGRAMW6432", "PATHEXT",
+	// proxy
+	"HTTP_PROXY", "HTTPS_PROXY",
+}
+
+// Container is a container started by envcli, it renders the arguments of the run command of the container runtime.
+// The arguments are passed to the runtime client without a shell, the values need no quoting.
+type Container struct {
+	name             string
+	image            string
+	entrypoint       *string
+	command          []string
+	workingDirectory string
+	volumes          []containerruntime.ContainerMount
+	environment      []containerruntime.EnvironmentProperty
+	hostEnvironment  []string
+	ports            []containerruntime.ContainerPort
+	capabilities     []string
+	args             []string
+	retain           bool
+}
+
+// SetName sets the name of the container
+func (c *Container) SetName(name string) {
+	c.name = name
+}
+
+// GetName returns the name of the container
+func (c *Container) GetName() string {
+	return c.name
+}
+
+// SetImage sets the image of the container
+func (c *Container) SetImage(image string) {
+	c.image = image
+}
+
+// GetImage returns the image of the container
+func (c *Container) GetImage() string {
+	return c.image
+}
+
+// SetEntrypoint overwrites the entrypoint of the image, an empty entrypoint clears it
+func (c *Container) SetEntrypoint(entrypoint string) {
+	c.entrypoint = &entrypoint
+}
+
+// SetCommand sets the command and its arguments, the default command of the image is used if empty
+func (c *Container) SetCommand(command []string) {
+	c.command = command
+}
+
+// SetWorkingDirectory sets the working directory within the container
+func (c *Container) SetWorkingDirectory(directory string) {
+	c.workingDirectory = directory
+}
+
+// AddVolume mounts a host directory or a volume into the container
+func (c *Container) AddVolume(mount containerruntime.ContainerMount) {
+	mount.Target = containerruntime.ToUnixPath(mount.Target)
+	c.volumes = append(c.volumes, mount)
+}
+
+// AddCacheMount mounts the cache directory, its paths are passed as cache_<name>_source and cache_<name>_target
+func (c *Container) AddCacheMount(name string, source string, target string) {
+	c.AddVolume(containerruntime.ContainerMount{MountType: "directory", Source: containerruntime.ToUnixPath(source), Target: target})
+	c.AddEnvironmentVariable("cache_"+name+"_source", containerruntime.ToUnixPath(source))
+	c.AddEnvironmentVariable("cache_"+name+"_target", target)
+}
+
+// AllowContainerRuntimeAccess mounts the docker socket into the container
+func (c *Container) AllowContainerRuntimeAccess() {
+	socket := "/var/run/docker.sock"
+	if goruntime.GOOS == "windows" && containerruntime.IsDockerNative() {
+		// docker desktop
+		socket = "//var/run/docker.sock"
+	}
+	c.AddVolume(containerruntime.ContainerMount{MountType: "directory", Source: socket, Target: "/var/run/docker.sock"})
+}
+
+// AddContainerPorts publishes the ports (host:container)
+func (c *Container) AddContainerPorts(ports []string) {
+	for _, port := range ports {
+		source, target, _ := strings.Cut(port, ":")
+		sourcePort, _ := strconv.Atoi(source)
+		targetPort, _ := strconv.Atoi(target)
+		c.ports = append(c.ports, containerruntime.ContainerPort{Source: sourcePort, Target: targetPort})
+	}
+}
+
+// AddCapability adds a linux capability to the container
+func (c *Container) AddCapability(capability string) {
+	c.capabilities = append(c.capabilities, capability)
+}
+
+// AddEnvironmentVariable sets a environment variable within the container
+func (c *Container) AddEnvironmentVariable(name string, value string) {
+	c.environment = append(c.environment, containerruntime.EnvironmentProperty{Name: name, Value: value})
+}
+
+// AddEnvironmentVariables sets the environment variables (NAME=value) within the container
+func (c *Container) AddEnvironmentVariables(variables []string) {
+	for _, variable := range variables {
+		name, value, _ := strings.Cut(variable, "=")
+		c.AddEnvironmentVariable(name, value)
+	}
+}
+
+// AddAllEnvironmentVariables passes all variables of the host, except the variables describing the host (PATH, HOME, ...)
+func (c *Container) AddAllEnvironmentVariables() {
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		// git bash / mingw sets invalid unix variables, ex. ProgramFiles(x86)
+		if name == "" || isExcludedHostVariable(name) || strings.ContainsAny(name, "()") {
+			continue
+		}
+		c.AddEnvironmentVariable(name, value)
+	}
+}
+
+// isExcludedHostVariable returns true if the variable describes the host, the names of windows are case-insensitive
+func isExcludedHostVariable(name string) bool {
+	for _, excluded := range excludedHostVariables {
+		if strings.EqualFold(name, excluded) {
+			return true
+		}
+	}
+	return false
+}
+
+// PassHostEnvironment passes the variables by name, the runtime client takes the values from its own environment.
+// The values don't appear in the arguments of the runtime client, the logs or the recorded runs.
+func (c *Container) PassHostEnvironment(names []string) {
+	c.hostEnvironment = append(c.hostEnvironment, names...)
+}
+
+// AddArgs adds run options of the runtime, ex. --init or --ulimit nofile=1024
+func (c *Container) AddArgs(args ...string) {
+	c.args = append(c.args, args...)
+}
+
+// SetRetain keeps the container after it exits, it is removed automatically otherwise
+func (c *Container) SetRetain(retain bool) {
+	c.retain = retain
+}
+
+// Redacted returns a copy of the container without the values of the environment variables, the variables are passed by name
+func (c *Container) Redacted() *Container {
+	redacted := *c
+	redacted.environment = nil
+	redacted.hostEnvironment = append([]string{}, c.hostEnvironment...)
+	for _, variable := range c.environment {
+		redacted.hostEnvironment = append(redacted.hostEnvironment, variable.Name)
+	}
+	return &redacted
+}
+
+// EnvironmentNames returns the names of all environment variables of the container
+func (c *Container) EnvironmentNames() []string {
+	var names []string
+	for _, variable := range c.environment {
+		names = append(names, variable.Name)
+	}
+	return append(names, c.hostEnvironment...)
+}
+
+// runOptions returns the options of the run command, nerdctl uses the mount syntax as it splits -v at every colon, which breaks windows paths (C:\...)
+func (c *Container) runOptions(runtime string) []string {
+	var options []string
+	if !c.retain {
+		options = append(options, "--rm")
+	}
+	if !cihelper.IsCIEnvironment() && cihelper.IsInteractiveTerminal() {
+		options = append(options, "-ti")
+	}
+	if c.name != "" {
+		options = append(options, "--name", c.name)
+	}
+	if c.entrypoint != nil && *c.entrypoint != "" {
+		options = append(options, "--entrypoint", *c.entrypoint)
+	} else if c.entrypoint != nil {
+		options = append(options, "--entrypoint=")
+	}
+	for _, variable := range c.environment {
+		options = append(options, "-e", variable.Name+"="+variable.Value)
+	}
+	for _, name := range c.hostEnvironment {
+		options = append(options, "-e", name)
+	}
+	for _, port := range c.ports {
+		options = append(options, "-p", strconv.Itoa(port.Source)+":"+strconv.Itoa(port.Target))
+	}
+	for _, capability := range c.capabilities {
+		options = append(options, "--cap-add", capability)
+	}
+	if c.workingDirectory != "" {
+		options = append(options, "--workdir", c.workingDirectory)
+	}
+	for _, mount := range c.volumes {
+		source := mount.Source
+		switch {
+		case mount.MountType == "volume" && runtime == "podman":
+			source = filepath.Join(os.TempDir(), "podman-volume", mount.Source)
+			_ = os.MkdirAll(source, os.ModePerm)
+		case mount.MountType != "directory" && mount.MountType != "volume":
+			continue
+		}
+
+		if runtime == "nerdctl" {
+			bind := "type=bind,source=" + source + ",target=" + mount.Target
+			if mount.Mode == containerruntime.ReadMode {
+				bind += ",readonly"
+			}
+			options = append(options, "--mount", bind)
+			continue
+		}
+		volume := source + ":" + mount.Target
+		if mount.Mode == containerruntime.ReadMode {
+			volume += ":ro"
+		}
+		options = append(options, "-v", volume)
+	}
+	return append(options, c.args...)
+}
@@ -0,0 +1,55 @@
+package containerutil
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cidverse/cidverseutils/pkg/containerruntime"
+)
+
+func TestRenderRunCommandPaths(t *testing.T) {
+	for _, source := range []string{"/home/user/My Projects/app", "/home/jürgen/Übung", "/Users/用户/项目 一", "/tmp/$HOME/`id`", `C:\Users\José María\app`} {
+		container := Container{}
+		container.SetImage("alpine")
+		container.AddVolume(containerruntime.ContainerMount{MountType: "directory", Source: source, Target: "/project"})
+		container.SetWorkingDirectory("/project")
+		container.SetCommand([]string{"echo", "hello world"})
+
+		args, err := RenderRunCommand(context.Background(), &fakeRuntime{name: "docker"}, &container)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"-v", source + ":/project", "alpine", "echo", "hello world"}
+		if !reflect.DeepEqual(args[len(args)-len(expected):], expected) {
+			t.Errorf("%s: expected the arguments to end with %q, got %q", strconv.Quote(source), expected, args)
+		}
+	}
+}
+
+func TestContainerRunOptions(t *testing.T) {
+	container := Container{}
+	container.SetName("envcli-app-go-abc123")
+	container.SetEntrypoint("")
+	container.AddEnvironmentVariables([]string{`A=price $5 "net"`, "B="})
+	container.PassHostEnvironment([]string{"TOKEN"})
+	container.AddContainerPorts([]string{"8080:80"})
+	container.AddArgs("--label", "envcli.managed=true")
+	container.SetRetain(true)
+
+	options := strings.Join(container.runOptions("docker"), "|")
+	if expected := `--name|envcli-app-go-abc123|--entrypoint=|-e|A=price $5 "net"|-e|B=|-e|TOKEN|-p|8080:80|--label|envcli.managed=true`; options != expected {
+		t.Errorf("expected %s, got %s", expected, options)
+	}
+
+	redacted := strings.Join(container.Redacted().runOptions("docker"), "|")
+	if strings.Contains(redacted, "price") || !strings.Contains(redacted, "-e|TOKEN|-e|A|-e|B|") {
+		t.Errorf("expected the values to be removed, got %s", redacted)
+	}
+	if names := strings.Join(container.EnvironmentNames(), ","); names != "A,B,TOKEN" {
+		t.Errorf("unexpected names %s", names)
+	}
+}
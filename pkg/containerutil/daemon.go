@@ -0,0 +1,145 @@
+package containerutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/rs/zerolog/log"
+)
+
+// daemonConnectionErrors are parts of the errors of the container runtimes if the daemon can't be reached, ex. because it is still starting
+var daemonConnectionErrors = []string{
+	"cannot connect to the docker daemon",
+	"is the docker daemon running",
+	"error during connect",
+	"connection refused",
+	"connect: no such file or directory",
+	"the system cannot find the file specified",
+	"cannot connect to podman",
+	"unable to connect to podman",
+	"cannot access containerd socket",
+}
+
+// daemonPollInterval is the time between the pings of a daemon that isn't reachable yet
+var daemonPollInterval = time.Second
+
+// daemonPingTimeout limits a single ping of the daemon
+const daemonPingTimeout = 10 * time.Second
+
+// spinnerFrames are the frames of the spinner shown while waiting for the daemon
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// IsDaemonConnectionError returns true if the output of the runtime reports that the daemon can't be reached
+func IsDaemonConnectionError(output string) bool {
+	output = strings.ToLower(output)
+	for _, message := range daemonConnectionErrors {
+		if strings.Contains(output, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// daemonVersionTemplate returns the info template printing the version of the daemon, podman has no ServerVersion field
+func daemonVersionTemplate(runtime ContainerRuntime) string {
+	if runtime.Name() == "podman" {
+		return "{{.Version.Version}}"
+	}
+	return "{{.ServerVersion}}"
+}
+
+// PingDaemon returns nil if the daemon of the runtime answers, the error contains the output of the runtime otherwise
+func PingDaemon(ctx context.Context, runtime ContainerRuntime) error {
+	ctx, cancel := context.WithTimeout(ctx, daemonPingTimeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	if err := runtime.Exec(ctx, runtimeCommand(runtime, "info", "--format", daemonVersionTemplate(runtime)), nil, &output, &output); err != nil {
+		if message := strings.TrimSpace(output.String()); message != "" {
+			return fmt.Errorf("%w: %s", err, message)
+		}
+		return err
+	}
+	return nil
+}
+
+// WaitForDaemon waits up to the timeout for the daemon of the runtime to answer, ex. if Docker Desktop is still starting after the login.
+// Only errors connecting to the daemon are waited for, other errors are left to the following runtime commands.
+// A spinner with the elapsed time is written to the output if it is a terminal.
+func WaitForDaemon(ctx context.Context, runtime ContainerRuntime, timeout time.Duration, output io.Writer) error {
+	if timeout <= 0 {
+		return nil
+	}
+	err := PingDaemon(ctx, runtime)
+	if err == nil || !IsDaemonConnectionError(err.Error()) {
+		return nil
+	}
+
+	start := time.Now()
+	spinner := newSpinner(output)
+	defer spinner.clear()
+	log.Debug().Err(err).Str("runtime", runtime.Name()).Dur("timeout", timeout).Msg("waiting for the daemon of the container runtime")
+	if !spinner.enabled {
+		log.Info().Str("runtime", runtime.Name()).Msgf("waiting up to %s for the %s daemon to start", timeout, runtime.Name())
+	}
+
+	for {
+		elapsed := time.Since(start)
+		if elapsed >= timeout {
+			return exitcode.New(exitcode.RuntimeUnavailable, errors.New("the "+runtime.Name()+" daemon did not answer within "+timeout.String()+" (daemon-wait-timeout): "+err.Error()))
+		}
+		spinner.update(fmt.Sprintf("waiting for the %s daemon to start (%s)", runtime.Name(), elapsed.Truncate(time.Second)))
+
+		wait := daemonPollInterval
+		if remaining := timeout - elapsed; remaining < wait {
+			wait = remaining
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		if err = PingDaemon(ctx, runtime); err == nil {
+			log.Debug().Str("runtime", runtime.Name()).Dur("waited", time.Since(start)).Msg("the daemon of the container runtime answered")
+			return nil
+		} else if !IsDaemonConnectionError(err.Error()) {
+			return nil
+		}
+	}
+}
+
+// spinner shows a single status line that is updated in place, it is only enabled for terminals
+type spinner struct {
+	output  io.Writer
+	enabled bool
+	frame   int
+}
+
+func newSpinner(output io.Writer) *spinner {
+	file, ok := output.(*os.File)
+	return &spinner{output: output, enabled: ok && IsTerminal(file)}
+}
+
+// update replaces the status line
+func (s *spinner) update(message string) {
+	if !s.enabled {
+		return
+	}
+	fmt.Fprintf(s.output, "\r\x1b[K%s %s", spinnerFrames[s.frame%len(spinnerFrames)], message)
+	s.frame++
+}
+
+// clear removes the status line
+func (s *spinner) clear() {
+	if s.enabled && s.frame > 0 {
+		fmt.Fprint(s.output, "\r\x1b[K")
+	}
+}
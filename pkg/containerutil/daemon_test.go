@@ -0,0 +1,97 @@
+package containerutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+)
+
+func TestIsDaemonConnectionError(t *testing.T) {
+	tests := []struct {
+		output string
+		want   bool
+	}{
+		{"Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?", true},
+		{"error during connect: this error may indicate that the docker daemon is not running", true},
+		{"Cannot connect to Podman. Please verify your connection to the Linux system", true},
+		{"dial unix /var/run/docker.sock: connect: no such file or directory", true},
+		{"permission denied while trying to connect to the Docker daemon socket", false},
+		{"unknown flag: --format", false},
+	}
+	for _, test := range tests {
+		if got := IsDaemonConnectionError(test.output); got != test.want {
+			t.Errorf("IsDaemonConnectionError(%q) = %v, want %v", test.output, got, test.want)
+		}
+	}
+}
+
+func TestPingDaemon(t *testing.T) {
+	for name, expected := range map[string]string{
+		"docker":  `docker info --format "{{.ServerVersion}}"`,
+		"nerdctl": `nerdctl info --format "{{.ServerVersion}}"`,
+		"podman":  `podman info --format "{{.Version.Version}}"`,
+	} {
+		runtime := &fakeRuntime{name: name, output: func(string) (string, error) {
+			return "5.0.0", nil
+		}}
+		if err := PingDaemon(context.Background(), runtime); err != nil || len(runtime.commands) != 1 || runtime.commands[0] != expected {
+			t.Errorf("expected %s, got %v (%v)", expected, runtime.commands, err)
+		}
+	}
+}
+
+func TestWaitForDaemon(t *testing.T) {
+	previous := daemonPollInterval
+	t.Cleanup(func() { daemonPollInterval = previous })
+	daemonPollInterval = 10 * time.Millisecond
+
+	t.Run("disabled", func(t *testing.T) {
+		runtime := &fakeRuntime{name: "docker", output: func(string) (string, error) {
+			return "", errors.New("Cannot connect to the Docker daemon")
+		}}
+		if err := WaitForDaemon(context.Background(), runtime, 0, &bytes.Buffer{}); err != nil || len(runtime.commands) != 0 {
+			t.Errorf("expected no ping without timeout, got %v %v", err, runtime.commands)
+		}
+	})
+
+	t.Run("daemon starting", func(t *testing.T) {
+		pings := 0
+		runtime := &fakeRuntime{name: "docker", output: func(command string) (string, error) {
+			pings++
+			if pings < 3 {
+				return "", errors.New("Cannot connect to the Docker daemon at unix:///var/run/docker.sock")
+			}
+			return "27.0.1", nil
+		}}
+		if err := WaitForDaemon(context.Background(), runtime, time.Minute, &bytes.Buffer{}); err != nil {
+			t.Fatalf("expected the daemon to answer, got %v", err)
+		}
+		if pings != 3 || !strings.HasPrefix(runtime.commands[0], "docker info") {
+			t.Errorf("expected 3 pings using docker info, got %v", runtime.commands)
+		}
+	})
+
+	t.Run("other error", func(t *testing.T) {
+		runtime := &fakeRuntime{name: "docker", output: func(string) (string, error) {
+			return "", errors.New("permission denied while trying to connect to the Docker daemon socket")
+		}}
+		if err := WaitForDaemon(context.Background(), runtime, time.Minute, &bytes.Buffer{}); err != nil || len(runtime.commands) != 1 {
+			t.Errorf("expected no wait for other errors, got %v %v", err, runtime.commands)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		runtime := &fakeRuntime{name: "docker", output: func(string) (string, error) {
+			return "", errors.New("Cannot connect to the Docker daemon")
+		}}
+		err := WaitForDaemon(context.Background(), runtime, 50*time.Millisecond, &bytes.Buffer{})
+		if exitcode.Of(err) != exitcode.RuntimeUnavailable || !strings.Contains(err.Error(), "daemon-wait-timeout") {
+			t.Errorf("expected the runtime unavailable exit code, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package containerutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// outOfSpaceMessages are the errors of the container runtimes and the tools within the containers if the disk (ex. of the Docker Desktop VM) is full
+var outOfSpaceMessages = []string{
+	"no space left on device",
+	"disk quota exceeded",
+	"not enough space on the disk",
+	"enospc",
+}
+
+// ErrOutOfSpace is returned by PullImage if the disk of the container runtime is full
+var ErrOutOfSpace = errors.New("the container runtime ran out of disk space (no space left on device)")
+
+// outOfSpaceGuidance explains how to reclaim disk space
+const outOfSpaceGuidance = "reclaim space using envcli prune --system or envcli prune images"
+
+// IsOutOfSpace returns true if the output contains one of the known out of disk space errors
+func IsOutOfSpace(output string) bool {
+	output = strings.ToLower(output)
+	for _, message := range outOfSpaceMessages {
+		if strings.Contains(output, message) {
+			return true
+		}
+	}
+	return false
+}
+
+// OutOfSpaceDetector is a writer that detects out of disk space errors within the output, also if a message is split across writes
+type OutOfSpaceDetector struct {
+	mutex    sync.Mutex
+	tail     []byte
+	detected bool
+}
+
+func (d *OutOfSpaceDetector) Write(p []byte) (int, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.detected {
+		return len(p), nil
+	}
+
+	d.tail = append(d.tail, p...)
+	d.detected = IsOutOfSpace(string(d.tail))
+	if keep := 64; len(d.tail) > keep {
+		d.tail = append([]byte{}, d.tail[len(d.tail)-keep:]...)
+	}
+	return len(p), nil
+}
+
+// Detected returns true if the output contained a out of disk space error
+func (d *OutOfSpaceDetector) Detected() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.detected
+}
+
+// DiskUsage returns the disk usage of the images, containers, volumes and build cache as reported by system df
+func DiskUsage(ctx context.Context, runtime ContainerRuntime) (string, error) {
+	return runtime.Output(ctx, runtimeCommand(runtime, "system", "df", "--format", `{{.Type}}\t{{.Size}}\t{{.Reclaimable}}`))
+}
+
+// WriteOutOfSpaceGuidance explains a out of disk space failure: the current disk usage of the runtime and how to reclaim space
+func WriteOutOfSpaceGuidance(ctx context.Context, w io.Writer, runtime ContainerRuntime) {
+	fmt.Fprintf(w, "The container runtime ran out of disk space (no space left on device).\n")
+	if usage, err := DiskUsage(ctx, runtime); err == nil && strings.TrimSpace(usage) != "" {
+		fmt.Fprintf(w, "Disk usage of %s:\n", runtime.Name())
+		fmt.Fprintf(w, "  %-16s %-10s %s\n", "TYPE", "SIZE", "RECLAIMABLE")
+		for _, line := range strings.Split(strings.TrimSpace(usage), "\n") {
+			fields := strings.Split(line, "\t")
+			for len(fields) < 3 {
+				fields = append(fields, "")
+			}
+			fmt.Fprintf(w, "  %-16s %-10s %s\n", fields[0], fields[1], fields[2])
+		}
+	}
+	fmt.Fprintf(w, "Reclaim the space of unused envcli containers and images using: envcli prune --system\n")
+	fmt.Fprintf(w, "Or reclaim the space of all unused resources using: %s system prune\n", runtime.Name())
+	fmt.Fprintf(w, "Docker Desktop limits the disk of its VM, it can be raised in Settings > Resources.\n")
+}
+
+// PruneSystem removes the stopped containers, dangling images and build cache labeled as managed by envcli, the prune output is written to the output
+func PruneSystem(ctx context.Context, runtime ContainerRuntime, output io.Writer) error {
+	command := runtimeCommand(runtime, "system", "prune", "--force", "--filter", "label="+ManagedLabel+"=true")
+	if err := runtime.Exec(ctx, command, nil, output, output); err != nil {
+		return fmt.Errorf("%s system prune failed: %w", runtime.Name(), err)
+	}
+	return nil
+}
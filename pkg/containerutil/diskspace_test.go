@@ -0,0 +1,50 @@
+package containerutil
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestIsOutOfSpace(t *testing.T) {
+	for _, output := range []string{
+		"write /var/lib/docker/tmp/GetImageBlob123: no space left on device",
+		"npm ERR! code ENOSPC",
+		"There is not enough space on the disk.",
+	} {
+		if !IsOutOfSpace(output) {
+			t.Errorf("expected %q to be detected", output)
+		}
+	}
+	if IsOutOfSpace("error: permission denied") {
+		t.Errorf("expected other errors to be ignored")
+	}
+}
+
+func TestOutOfSpaceDetector(t *testing.T) {
+	detector := &OutOfSpaceDetector{}
+	_, _ = detector.Write([]byte(strings.Repeat("x", 100) + "write failed: no space le"))
+	if detector.Detected() {
+		t.Fatalf("expected no detection of a partial message")
+	}
+	_, _ = detector.Write([]byte("ft on device\n"))
+	if !detector.Detected() {
+		t.Errorf("expected the message split across writes to be detected")
+	}
+}
+
+func TestWriteOutOfSpaceGuidance(t *testing.T) {
+	runtime := &fakeRuntime{name: "docker", output: func(command string) (string, error) {
+		return "Images\t45.2GB\t30.1GB (66%)\nBuild Cache\t12GB\t12GB\n", nil
+	}}
+	var out bytes.Buffer
+	WriteOutOfSpaceGuidance(context.Background(), &out, runtime)
+
+	if !strings.Contains(out.String(), "Images           45.2GB     30.1GB (66%)") || !strings.Contains(out.String(), "envcli prune --system") {
+		t.Errorf("unexpected guidance %q", out.String())
+	}
+	if len(runtime.commands) != 1 || !strings.HasPrefix(runtime.commands[0], "docker system df --format") {
+		t.Errorf("expected the disk usage to be queried, got %v", runtime.commands)
+	}
+}
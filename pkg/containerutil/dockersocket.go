@@ -0,0 +1,230 @@
+package containerutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DockerHostEnv is the environment variable used by the docker cli to select the daemon
+const DockerHostEnv = "DOCKER_HOST"
+
+// defaultDockerSocket is the socket used by the docker cli if neither DOCKER_HOST nor a context is set
+const defaultDockerSocket = "/var/run/docker.sock"
+
+// defaultDockerPipe is the named pipe used by the docker cli on windows if neither DOCKER_HOST nor a context is set
+const defaultDockerPipe = "//./pipe/docker_engine"
+
+// dockerDesktopPipes are the named pipes of Docker Desktop, used if the default pipe is missing
+var dockerDesktopPipes = []string{
+	"//./pipe/dockerDesktopLinuxEngine",
+	"//./pipe/dockerDesktopWindowsEngine",
+}
+
+// wslDockerSockets are the sockets shared between WSL distributions, ex. if the daemon runs in another distribution
+var wslDockerSockets = []string{
+	"/mnt/wsl/shared-docker/docker.sock",
+}
+
+// ErrDockerPipeAccessDenied is returned by CheckDockerHost if the named pipe of the docker daemon exists, but the user isn't allowed to open it
+var ErrDockerPipeAccessDenied = errors.New("access to the docker named pipe has been denied")
+
+// wellKnownDockerSockets are the sockets of docker alternatives, relative to the home directory
+var wellKnownDockerSockets = []string{
+	".colima/default/docker.sock",
+	".colima/docker.sock",
+	".lima/docker/sock/docker.sock",
+	".rd/docker.sock",
+	".docker/run/docker.sock",
+}
+
+// ApplyDockerSocket points the docker cli to the pinned socket, or to a working socket if the default socket is missing (ex. colima on macOS).
+// DOCKER_HOST takes precedence if set.
+func ApplyDockerSocket(goos string, pinned string) {
+	if host := os.Getenv(DockerHostEnv); host != "" {
+		log.Debug().Str("host", host).Msg("using the docker daemon of DOCKER_HOST")
+		return
+	}
+	if pinned != "" {
+		log.Debug().Str("socket", pinned).Msg("using the docker socket of the docker-socket property")
+		_ = os.Setenv(DockerHostEnv, DockerHostURL(pinned))
+		return
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	if socket := ResolveDockerSocket(goos, home, os.Getenv, DockerSocketReachable); socket != "" {
+		_ = os.Setenv(DockerHostEnv, DockerHostURL(socket))
+	}
+}
+
+// DockerHostURL returns the DOCKER_HOST value of a socket path or named pipe, urls (ex. npipe://, tcp://) are returned unchanged
+func DockerHostURL(socket string) string {
+	if strings.Contains(socket, "://") {
+		return socket
+	}
+	if IsNamedPipe(socket) {
+		return "npipe://" + strings.ReplaceAll(socket, `\`, "/")
+	}
+	return "unix://" + socket
+}
+
+// IsNamedPipe returns true if the path is a windows named pipe, ex. //./pipe/docker_engine or \\.\pipe\docker_engine
+func IsNamedPipe(path string) bool {
+	return strings.HasPrefix(strings.ReplaceAll(path, `\`, "/"), "//./pipe/")
+}
+
+// namedPipePath returns the path of a npipe:// url, ex. npipe:////./pipe/docker_engine
+func namedPipePath(host string) string {
+	path := strings.TrimPrefix(host, "npipe://")
+	if !IsNamedPipe(path) {
+		return ""
+	}
+	return strings.ReplaceAll(path, `\`, "/")
+}
+
+// CheckDockerHost returns ErrDockerPipeAccessDenied if the docker daemon is reached using a named pipe the user isn't allowed to open.
+// Other errors are left to the docker cli, as the daemon might just not be started yet.
+func CheckDockerHost(goos string) error {
+	return checkDockerHost(goos, os.Getenv(DockerHostEnv), probeNamedPipe)
+}
+
+func checkDockerHost(goos string, host string, probe func(string) error) error {
+	if host == "" && goos == "windows" {
+		host = "npipe://" + defaultDockerPipe
+	}
+	pipe := namedPipePath(host)
+	if pipe == "" {
+		return nil
+	}
+
+	if err := probe(pipe); errors.Is(err, fs.ErrPermission) {
+		return fmt.Errorf("%w: %s, add your user to the docker-users group (ex. net localgroup docker-users %%USERNAME%% /add as administrator) and sign out and in again", ErrDockerPipeAccessDenied, pipe)
+	}
+	return nil
+}
+
+// ResolveDockerSocket returns the socket the docker cli should use, empty if the docker cli finds the daemon by itself
+func ResolveDockerSocket(goos string, home string, getenv func(string) string, reachable func(string) bool) string {
+	configDir := getenv("DOCKER_CONFIG")
+	if configDir == "" {
+		configDir = filepath.Join(home, ".docker")
+	}
+	if current := currentDockerContext(configDir); current != "" && current != "default" {
+		log.Debug().Str("context", current).Msg("using the active docker context")
+		return ""
+	}
+
+	defaultSocket := defaultDockerSocket
+	if goos == "windows" {
+		defaultSocket = defaultDockerPipe
+	}
+	if reachable(defaultSocket) {
+		log.Debug().Str("socket", defaultSocket).Msg("using the default docker socket")
+		return ""
+	}
+
+	var candidates []string
+	for _, socket := range dockerContextSockets(configDir) {
+		if IsNamedPipe(socket) == (goos == "windows") {
+			candidates = append(candidates, socket)
+		}
+	}
+	if goos == "windows" {
+		candidates = append(candidates, dockerDesktopPipes...)
+	} else {
+		for _, socket := range wellKnownDockerSockets {
+			candidates = append(candidates, filepath.Join(home, socket))
+		}
+		if goos == "linux" {
+			candidates = append(candidates, wslDockerSockets...)
+		}
+	}
+	for _, socket := range candidates {
+		if reachable(socket) {
+			log.Debug().Str("socket", socket).Msg("the default docker socket is not available, using the detected socket")
+			return socket
+		}
+		log.Trace().Str("socket", socket).Msg("docker socket is not reachable")
+	}
+
+	log.Debug().Msg("no docker socket found")
+	return ""
+}
+
+// DockerSocketReachable returns true if the socket or named pipe accepts connections
+func DockerSocketReachable(socket string) bool {
+	if IsNamedPipe(socket) {
+		return probeNamedPipe(socket) == nil
+	}
+	conn, err := net.DialTimeout("unix", socket, 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// currentDockerContext returns the context selected by docker context use
+func currentDockerContext(configDir string) string {
+	content, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+	if err != nil {
+		return ""
+	}
+	var dockerConfig struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	if err = json.Unmarshal(content, &dockerConfig); err != nil {
+		return ""
+	}
+	return dockerConfig.CurrentContext
+}
+
+// dockerContextSockets returns the unix sockets and named pipes of all docker contexts, sorted by the context name
+func dockerContextSockets(configDir string) []string {
+	files, _ := filepath.Glob(filepath.Join(configDir, "contexts", "meta", "*", "meta.json"))
+
+	sockets := map[string]string{}
+	var names []string
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		var meta struct {
+			Name      string
+			Endpoints map[string]struct {
+				Host string
+			}
+		}
+		if err = json.Unmarshal(content, &meta); err != nil {
+			continue
+		}
+		host := meta.Endpoints["docker"].Host
+		if strings.HasPrefix(host, "unix://") {
+			sockets[meta.Name] = strings.TrimPrefix(host, "unix://")
+			names = append(names, meta.Name)
+		} else if pipe := namedPipePath(host); pipe != "" {
+			sockets[meta.Name] = pipe
+			names = append(names, meta.Name)
+		}
+	}
+	sort.Strings(names)
+
+	var result []string
+	for _, name := range names {
+		result = append(result, sockets[name])
+	}
+	return result
+}
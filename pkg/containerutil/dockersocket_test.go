@@ -0,0 +1,146 @@
+package containerutil
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveDockerSocket(t *testing.T) {
+	home := t.TempDir()
+	getenv := func(string) string { return "" }
+	colima := filepath.Join(home, ".colima/default/docker.sock")
+	reachable := func(sockets ...string) func(string) bool {
+		return func(socket string) bool {
+			for _, s := range sockets {
+				if s == socket {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	if socket := ResolveDockerSocket("darwin", home, getenv, reachable(defaultDockerSocket, colima)); socket != "" {
+		t.Errorf("expected the default socket to be used, got %s", socket)
+	}
+	if socket := ResolveDockerSocket("darwin", home, getenv, reachable(colima)); socket != colima {
+		t.Errorf("expected the colima socket, got %s", socket)
+	}
+	if socket := ResolveDockerSocket("darwin", home, getenv, reachable()); socket != "" {
+		t.Errorf("expected no socket, got %s", socket)
+	}
+	if socket := ResolveDockerSocket("windows", home, getenv, reachable(colima)); socket != "" {
+		t.Errorf("expected no socket on windows, got %s", socket)
+	}
+
+	// docker contexts are preferred over the well-known sockets
+	meta := filepath.Join(home, ".docker/contexts/meta/abc/meta.json")
+	if err := os.MkdirAll(filepath.Dir(meta), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(meta, []byte(`{"Name":"lima","Endpoints":{"docker":{"Host":"unix:///tmp/lima.sock"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if socket := ResolveDockerSocket("darwin", home, getenv, reachable("/tmp/lima.sock", colima)); socket != "/tmp/lima.sock" {
+		t.Errorf("expected the context socket, got %s", socket)
+	}
+
+	// an active context is used by the docker cli
+	if err := os.WriteFile(filepath.Join(home, ".docker/config.json"), []byte(`{"currentContext":"lima"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if socket := ResolveDockerSocket("darwin", home, getenv, reachable(colima)); socket != "" {
+		t.Errorf("expected the active context to be used, got %s", socket)
+	}
+}
+
+func TestResolveDockerSocketWindows(t *testing.T) {
+	home := t.TempDir()
+	getenv := func(string) string { return "" }
+	reachable := func(pipes ...string) func(string) bool {
+		return func(socket string) bool {
+			for _, p := range pipes {
+				if p == socket {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	if socket := ResolveDockerSocket("windows", home, getenv, reachable(defaultDockerPipe)); socket != "" {
+		t.Errorf("expected the default pipe to be used, got %s", socket)
+	}
+	if socket := ResolveDockerSocket("windows", home, getenv, reachable("//./pipe/dockerDesktopLinuxEngine")); socket != "//./pipe/dockerDesktopLinuxEngine" {
+		t.Errorf("expected the docker desktop pipe, got %s", socket)
+	}
+
+	// unix sockets of contexts are ignored on windows, named pipes are ignored elsewhere
+	for name, host := range map[string]string{"a-unix": "unix:///tmp/docker.sock", "b-pipe": "npipe:////./pipe/custom_engine"} {
+		meta := filepath.Join(home, ".docker/contexts/meta", name, "meta.json")
+		if err := os.MkdirAll(filepath.Dir(meta), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(meta, []byte(`{"Name":"`+name+`","Endpoints":{"docker":{"Host":"`+host+`"}}}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if socket := ResolveDockerSocket("windows", home, getenv, reachable("/tmp/docker.sock", "//./pipe/custom_engine")); socket != "//./pipe/custom_engine" {
+		t.Errorf("expected the context pipe, got %s", socket)
+	}
+	if socket := ResolveDockerSocket("darwin", home, getenv, reachable("/tmp/docker.sock", "//./pipe/custom_engine")); socket != "/tmp/docker.sock" {
+		t.Errorf("expected the context socket, got %s", socket)
+	}
+}
+
+func TestDockerHostURL(t *testing.T) {
+	tests := map[string]string{
+		"/home/user/.colima/default/docker.sock": "unix:///home/user/.colima/default/docker.sock",
+		`\\.\pipe\docker_engine`:                 "npipe:////./pipe/docker_engine",
+		"//./pipe/docker_engine":                 "npipe:////./pipe/docker_engine",
+		"npipe:////./pipe/docker_engine":         "npipe:////./pipe/docker_engine",
+		"tcp://127.0.0.1:2375":                   "tcp://127.0.0.1:2375",
+	}
+	for socket, expected := range tests {
+		if url := DockerHostURL(socket); url != expected {
+			t.Errorf("expected %s for %s, got %s", expected, socket, url)
+		}
+	}
+}
+
+func TestCheckDockerHost(t *testing.T) {
+	var probed string
+	denied := func(pipe string) error {
+		probed = pipe
+		return &fs.PathError{Op: "open", Path: pipe, Err: fs.ErrPermission}
+	}
+	missing := func(pipe string) error {
+		return &fs.PathError{Op: "open", Path: pipe, Err: fs.ErrNotExist}
+	}
+
+	err := checkDockerHost("windows", "", denied)
+	if !errors.Is(err, ErrDockerPipeAccessDenied) || !strings.Contains(err.Error(), "docker-users") {
+		t.Errorf("expected the access denied guidance, got %v", err)
+	}
+	if probed != defaultDockerPipe {
+		t.Errorf("expected the default pipe to be probed, got %s", probed)
+	}
+	if err = checkDockerHost("windows", "npipe:////./pipe/dockerDesktopLinuxEngine", denied); err == nil || probed != "//./pipe/dockerDesktopLinuxEngine" {
+		t.Errorf("expected the pipe of DOCKER_HOST to be probed, got %s: %v", probed, err)
+	}
+
+	// a missing pipe is reported by the docker cli, ex. if docker desktop isn't started yet
+	if err = checkDockerHost("windows", "", missing); err != nil {
+		t.Errorf("expected no error for a missing pipe, got %v", err)
+	}
+	if err = checkDockerHost("windows", "tcp://127.0.0.1:2375", denied); err != nil {
+		t.Errorf("expected tcp hosts to be skipped, got %v", err)
+	}
+	if err = checkDockerHost("linux", "", denied); err != nil {
+		t.Errorf("expected unix sockets to be skipped, got %v", err)
+	}
+}
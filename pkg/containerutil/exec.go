@@ -0,0 +1,160 @@
+package containerutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// shellCommand wraps a command string into the platform shell
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("powershell", command)
+	} else if runtime.GOOS == "linux" {
+		return exec.Command("/usr/bin/env", "sh", "-c", command)
+	}
+
+	return exec.Command("sh", "-c", command)
+}
+
+// ExecHostCommand runs the command within the directory, the environment variables (NAME=value) are added to the environment of the current process
+//
+// Hooks are shell commands written by the user (pipes, redirects, variables), so this is the only function running through the platform shell.
+// Everything else must use the argv based functions below, which never pass values through a shell.
+func ExecHostCommand(ctx context.Context, command string, directory string, env []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	log.Trace().Str("command", command).Str("dir", directory).Msg("executing command")
+	cmd := shellCommand(command)
+	cmd.Dir = directory
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	return runCommand(ctx, cmd)
+}
+
+// ExecArgsWithIO runs the command without a shell, the first argument is the executable.
+// Paths with spaces, unicode or shell characters ($, `) reach the command unchanged.
+func ExecArgsWithIO(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	return ExecArgsWithEnv(ctx, args, nil, stdin, stdout, stderr)
+}
+
+// ExecArgsWithEnv runs the command without a shell, the environment variables (NAME=value) are only added to the environment of the command
+func ExecArgsWithEnv(ctx context.Context, args []string, env []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	cmd, err := argsCommand(args, env)
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	return runCommand(ctx, cmd)
+}
+
+// ExecArgsOutput runs the command without a shell and returns the trimmed stdout
+func ExecArgsOutput(ctx context.Context, args []string) (string, error) {
+	return ExecArgsOutputWithEnv(ctx, args, nil)
+}
+
+// ExecArgsOutputWithEnv runs the command without a shell and returns the trimmed stdout, the environment variables (NAME=value) are only added to the environment of the command
+func ExecArgsOutputWithEnv(ctx context.Context, args []string, env []string) (string, error) {
+	cmd, err := argsCommand(args, env)
+	if err != nil {
+		return "", err
+	}
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	err = runCommand(ctx, cmd)
+	return strings.TrimSpace(stdout.String()), err
+}
+
+// argsCommand creates the command of the executable and its arguments, the environment variables are added to the environment of the current process
+func argsCommand(args []string, env []string) (*exec.Cmd, error) {
+	if len(args) == 0 {
+		return nil, errors.New("empty command")
+	}
+	log.Trace().Strs("args", args).Msg("executing command")
+	cmd := exec.Command(args[0], args[1:]...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	return cmd, nil
+}
+
+// FormatCommand renders the arguments as a command line for the output and the logs, arguments containing spaces or special characters are quoted
+func FormatCommand(args []string) string {
+	formatted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" || strings.IndexFunc(arg, needsQuoting) >= 0 {
+			arg = strconv.Quote(arg)
+		}
+		formatted = append(formatted, arg)
+	}
+	return strings.Join(formatted, " ")
+}
+
+// needsQuoting returns true for the characters that need quoting in a command line
+func needsQuoting(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@,+%", r))
+}
+
+// runCommand runs the command and kills it once the context is cancelled.
+// Commands without a terminal as input run in their own process group, which is killed as a whole (ex. the docker client started by the shell).
+// Interactive commands stay in the foreground process group of the terminal and receive the signals of the terminal directly.
+func runCommand(ctx context.Context, cmd *exec.Cmd) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	group := !IsTerminal(cmd.Stdin)
+	if group {
+		setProcessGroup(cmd)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	killed := make(chan struct{})
+	go func() {
+		defer close(killed)
+		select {
+		case <-ctx.Done():
+			log.Debug().Int("pid", cmd.Process.Pid).Msg("context cancelled, killing the command")
+			killProcess(cmd, group)
+		case <-done:
+		}
+	}()
+
+	err := cmd.Wait()
+	close(done)
+	<-killed
+
+	if ctxErr := ctx.Err(); ctxErr != nil && err != nil {
+		return fmt.Errorf("%w: %v", ctxErr, err)
+	}
+	return err
+}
+
+// IsTerminal returns true if the input is a terminal
+func IsTerminal(input io.Reader) bool {
+	file, ok := input.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,77 @@
+package containerutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecHostCommandCancel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	// the background process keeps the output open, the command only returns early if the whole process group is killed
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	started := time.Now()
+	var output bytes.Buffer
+	err := ExecHostCommand(ctx, "sleep 30 & sleep 30", "", nil, strings.NewReader(""), &output, &output)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled error, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("expected the command to be killed promptly, took %s", elapsed)
+	}
+}
+
+func TestExecArgsCancelledBeforeStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := ExecArgsOutput(ctx, []string{"echo", "hello"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled error, got %v", err)
+	}
+}
+
+func TestExecArgsOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires printf")
+	}
+
+	// the argument reaches the command unchanged, without shell expansion
+	argument := "/tmp/José María/项目 $HOME `id`"
+	output, err := ExecArgsOutput(context.Background(), []string{"printf", "%s", argument})
+	if err != nil || output != argument {
+		t.Errorf("expected %q, got %q (%v)", argument, output, err)
+	}
+}
+
+func TestWithEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires printenv")
+	}
+
+	// the variables are only passed to the runtime client, the environment of the current process is unchanged
+	withEnv := WithEnvironment(hostRuntime{name: "printenv"}, []string{"ENVCLI_TEST_SECRET=s3cr3t"})
+	output, err := withEnv.Output(context.Background(), []string{"printenv", "ENVCLI_TEST_SECRET"})
+	if err != nil || output != "s3cr3t" {
+		t.Errorf("expected the variable within the environment of the command, got %q (%v)", output, err)
+	}
+	if _, set := os.LookupEnv("ENVCLI_TEST_SECRET"); set {
+		t.Errorf("expected the environment of the current process to be unchanged")
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	command := FormatCommand([]string{"docker", "run", "-v", "/home/user/My Projects:/project", "--label", "envcli.managed=true", "-e", "A=", "", "{{.Id}}"})
+	if expected := `docker run -v "/home/user/My Projects:/project" --label envcli.managed=true -e A= "" "{{.Id}}"`; command != expected {
+		t.Errorf("expected %s, got %s", expected, command)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package containerutil
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup isn't required on windows, killing the process is sufficient
+func setProcessGroup(cmd *exec.Cmd) {
+}
+
+// killProcess kills the command
+func killProcess(cmd *exec.Cmd, group bool) {
+	_ = cmd.Process.Kill()
+}
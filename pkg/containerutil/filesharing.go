@@ -0,0 +1,67 @@
+package containerutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrUnsharedPath is returned by ProbeFileSharing if the directory is mounted as a empty directory, as it isn't shared with the vm of the container runtime
+var ErrUnsharedPath = errors.New("the directory isn't shared with the container runtime")
+
+// fileSharingGuidance explains how to share a directory with the vm of the container runtime
+const fileSharingGuidance = "add the directory or one of its parents to Settings > Resources > File sharing of Docker Desktop (or to the mounts of colima / podman machine) and restart the runtime, set the property file-sharing-probe to false to skip this check"
+
+// defaultSharedPaths are the directories Docker Desktop on macOS shares by default, in addition to the home directory
+var defaultSharedPaths = []string{
+	"/Users",
+	"/Volumes",
+	"/private",
+	"/tmp",
+	"/var/folders",
+}
+
+// fileSharingProbeTarget is the directory the probe container mounts the project to
+const fileSharingProbeTarget = "/envcli-probe"
+
+// NeedsFileSharingProbe returns true if the directory might not be shared with the vm of the container runtime.
+// Only macOS runs the containers within a vm that shares selected directories, the home directory and the defaults of Docker Desktop are skipped.
+func NeedsFileSharingProbe(goos string, home string, dir string) bool {
+	if goos != "darwin" {
+		return false
+	}
+
+	shared := append([]string{home}, defaultSharedPaths...)
+	for _, path := range shared {
+		if path == "" {
+			continue
+		}
+		if rel, err := filepath.Rel(path, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ProbeFileSharing starts a short-lived container of the image to check that the mounted directory contains the expected file.
+// Images without ls can't be probed, the check is skipped for them.
+func ProbeFileSharing(ctx context.Context, runtime ContainerRuntime, image string, source string, expected string) error {
+	command := runtimeCommand(runtime, "run", "--rm", "--entrypoint", "ls", "-v", source+":"+fileSharingProbeTarget, image, "-A", fileSharingProbeTarget)
+	output, err := runtime.Output(ctx, command)
+	if err != nil {
+		log.Debug().Err(err).Str("image", image).Msg("failed to probe the file sharing, skipping the check")
+		return nil
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == expected {
+			log.Debug().Str("source", source).Msg("the directory is shared with the container runtime")
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is mounted as a empty directory, %s is missing within the container; %s", ErrUnsharedPath, source, expected, fileSharingGuidance)
+}
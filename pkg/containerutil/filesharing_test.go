@@ -0,0 +1,45 @@
+package containerutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNeedsFileSharingProbe(t *testing.T) {
+	tests := []struct {
+		goos     string
+		dir      string
+		expected bool
+	}{
+		{"darwin", "/Users/dev/project", false},
+		{"darwin", "/home/dev/project", false},
+		{"darwin", "/Volumes/data/project", false},
+		{"darwin", "/opt/projects/app", true},
+		{"darwin", "/Users2/project", true},
+		{"linux", "/opt/projects/app", false},
+	}
+	for _, test := range tests {
+		if result := NeedsFileSharingProbe(test.goos, "/home/dev", test.dir); result != test.expected {
+			t.Errorf("expected %v for %s on %s, got %v", test.expected, test.dir, test.goos, result)
+		}
+	}
+}
+
+func TestProbeFileSharing(t *testing.T) {
+	shared := &fakeRuntime{name: "docker", output: func(command string) (string, error) { return ".envcli.yml\nsrc\n", nil }}
+	if err := ProbeFileSharing(context.Background(), shared, "alpine", "/opt/app", ".envcli.yml"); err != nil {
+		t.Errorf("expected the shared directory to pass, got %v", err)
+	}
+
+	unshared := &fakeRuntime{name: "docker", output: func(command string) (string, error) { return "", nil }}
+	if err := ProbeFileSharing(context.Background(), unshared, "alpine", "/opt/app", ".envcli.yml"); !errors.Is(err, ErrUnsharedPath) {
+		t.Errorf("expected ErrUnsharedPath, got %v", err)
+	}
+
+	// images without ls can't be probed
+	distroless := &fakeRuntime{name: "docker", output: func(command string) (string, error) { return "", errors.New("exec: \"ls\": executable file not found") }}
+	if err := ProbeFileSharing(context.Background(), distroless, "distroless", "/opt/app", ".envcli.yml"); err != nil {
+		t.Errorf("expected the probe to be skipped, got %v", err)
+	}
+}
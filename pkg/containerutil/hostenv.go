@@ -0,0 +1,17 @@
+package containerutil
+
+// EnvironmentRuntime is a runtime that can add environment variables to the runtime client
+type EnvironmentRuntime interface {
+	// WithEnvironment returns a copy of the runtime, which adds the variables (NAME=value) to the environment of the runtime client
+	WithEnvironment(env []string) ContainerRuntime
+}
+
+// WithEnvironment returns the runtime adding the variables (NAME=value) to the environment of the runtime client.
+// The environment of the current process is unchanged, parallel runs don't see the variables of each other.
+// Runtimes that don't implement EnvironmentRuntime are returned unchanged.
+func WithEnvironment(runtime ContainerRuntime, env []string) ContainerRuntime {
+	if environmentRuntime, ok := runtime.(EnvironmentRuntime); ok && len(env) > 0 {
+		return environmentRuntime.WithEnvironment(env)
+	}
+	return runtime
+}
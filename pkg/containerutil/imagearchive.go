@@ -0,0 +1,157 @@
+package containerutil
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// imageArchiveIndex is the first file of a image archive, it lists the images of the archive
+const imageArchiveIndex = "envcli-images.json"
+
+// ErrNoImageArchive is returned by ImportImages if the file isn't a image archive created by ExportImages
+var ErrNoImageArchive = errors.New("not a image archive of envcli image export")
+
+// ArchivedImage is a image within a image archive
+type ArchivedImage struct {
+	// Image is the image reference (ex. docker.io/library/node:20)
+	Image string `json:"image"`
+
+	// ID is the image id of the runtime that exported the image
+	ID string `json:"id"`
+
+	// File is the name of the archive of the image (docker save format) within the image archive
+	File string `json:"file"`
+}
+
+// ImportResult is the outcome of the import of a image
+type ImportResult struct {
+	ArchivedImage
+
+	// Loaded is false if the image has been skipped, as a image with the same id is present
+	Loaded bool
+
+	// Size of the archive of the image
+	Size int64
+}
+
+// ExportImages writes the local images into a single archive: a index followed by the archive of each image (docker save format).
+// The images are saved into a temporary file one after another, as tar requires the size of a file before its content, so the archive is never held in memory.
+func ExportImages(ctx context.Context, runtime ContainerRuntime, images []string, w io.Writer, progress func(image ArchivedImage, size int64)) error {
+	var index []ArchivedImage
+	for i, image := range images {
+		id, err := ImageID(ctx, runtime, image)
+		if err != nil {
+			return fmt.Errorf("image %s isn't present locally: %w", image, err)
+		}
+		index = append(index, ArchivedImage{Image: image, ID: strings.TrimSpace(id), File: fmt.Sprintf("images/%d.tar", i)})
+	}
+
+	archive := tar.NewWriter(w)
+	content, _ := json.MarshalIndent(index, "", "  ")
+	if err := archive.WriteHeader(&tar.Header{Name: imageArchiveIndex, Mode: 0644, Size: int64(len(content)), ModTime: time.Now()}); err != nil {
+		return err
+	}
+	if _, err := archive.Write(content); err != nil {
+		return err
+	}
+
+	for _, image := range index {
+		size, err := appendSavedImage(ctx, runtime, archive, image)
+		if err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(image, size)
+		}
+	}
+	return archive.Close()
+}
+
+// appendSavedImage saves the image into a temporary file and appends it to the archive
+func appendSavedImage(ctx context.Context, runtime ContainerRuntime, archive *tar.Writer, image ArchivedImage) (int64, error) {
+	file, err := os.CreateTemp("", "envcli-image-*.tar")
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	var stderr bytes.Buffer
+	if err = runtime.Exec(ctx, runtimeCommand(runtime, "save", image.Image), nil, file, &stderr); err != nil {
+		return 0, fmt.Errorf("failed to save image %s: %w %s", image.Image, err, strings.TrimSpace(stderr.String()))
+	}
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	if err = archive.WriteHeader(&tar.Header{Name: image.File, Mode: 0644, Size: size, ModTime: time.Now()}); err != nil {
+		return 0, err
+	}
+	if _, err = io.Copy(archive, file); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+// ImportImages loads the images of a archive created by ExportImages, images whose id is already present are skipped.
+// The archive of each image is streamed into the runtime.
+func ImportImages(ctx context.Context, runtime ContainerRuntime, r io.Reader, report func(result ImportResult)) error {
+	archive := tar.NewReader(r)
+	header, err := archive.Next()
+	if err != nil || header.Name != imageArchiveIndex {
+		return ErrNoImageArchive
+	}
+	var index []ArchivedImage
+	if err = json.NewDecoder(archive).Decode(&index); err != nil {
+		return fmt.Errorf("%w: %v", ErrNoImageArchive, err)
+	}
+	images := map[string]ArchivedImage{}
+	for _, image := range index {
+		images[image.File] = image
+	}
+
+	for {
+		header, err = archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the image archive: %w", err)
+		}
+		image, found := images[header.Name]
+		if !found {
+			return fmt.Errorf("%w: unexpected file %s", ErrNoImageArchive, header.Name)
+		}
+
+		result := ImportResult{ArchivedImage: image, Size: header.Size}
+		if id, idErr := ImageID(ctx, runtime, image.Image); idErr != nil || normalizeImageID(id) != normalizeImageID(image.ID) {
+			var stderr bytes.Buffer
+			if err = runtime.Exec(ctx, runtimeCommand(runtime, "load"), archive, io.Discard, &stderr); err != nil {
+				return fmt.Errorf("failed to load image %s: %w %s", image.Image, err, strings.TrimSpace(stderr.String()))
+			}
+			result.Loaded = true
+		}
+		if report != nil {
+			report(result)
+		}
+	}
+}
+
+// normalizeImageID removes the algorithm of the id, podman reports the id without it
+func normalizeImageID(id string) string {
+	return strings.TrimPrefix(strings.TrimSpace(id), "sha256:")
+}
@@ -0,0 +1,81 @@
+package containerutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// archiveRuntime saves and loads images in memory
+type archiveRuntime struct {
+	// ids are the ids of the local images
+	ids map[string]string
+
+	// loaded are the archives passed to load
+	loaded []string
+}
+
+func (r *archiveRuntime) Name() string {
+	return "docker"
+}
+
+func (r *archiveRuntime) Exec(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	command := strings.Join(args, " ")
+	if image := strings.TrimPrefix(command, "docker save "); image != command {
+		_, err := io.WriteString(stdout, "layers of "+image)
+		return err
+	}
+	if command == "docker load" {
+		content, err := io.ReadAll(stdin)
+		r.loaded = append(r.loaded, string(content))
+		return err
+	}
+	return errors.New("unexpected command " + command)
+}
+
+func (r *archiveRuntime) Output(ctx context.Context, args []string) (string, error) {
+	for image, id := range r.ids {
+		if args[len(args)-1] == image {
+			return id + "\n", nil
+		}
+	}
+	return "", errors.New("no such image")
+}
+
+func TestExportImportImages(t *testing.T) {
+	source := &archiveRuntime{ids: map[string]string{"node:20": "sha256:aaa", "golang:1.22": "sha256:bbb"}}
+	var archive bytes.Buffer
+	var exported []string
+	err := ExportImages(context.Background(), source, []string{"node:20", "golang:1.22"}, &archive, func(image ArchivedImage, size int64) {
+		exported = append(exported, image.Image)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(exported, ",") != "node:20,golang:1.22" {
+		t.Errorf("unexpected exported images %v", exported)
+	}
+
+	// node is present with the same id (podman reports ids without sha256:), golang has another id
+	target := &archiveRuntime{ids: map[string]string{"node:20": "aaa", "golang:1.22": "sha256:old"}}
+	var results []ImportResult
+	if err = ImportImages(context.Background(), target, bytes.NewReader(archive.Bytes()), func(result ImportResult) { results = append(results, result) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Loaded || !results[1].Loaded || results[1].Size != int64(len("layers of golang:1.22")) {
+		t.Errorf("unexpected import results %+v", results)
+	}
+	if len(target.loaded) != 1 || target.loaded[0] != "layers of golang:1.22" {
+		t.Errorf("expected only golang to be loaded, got %v", target.loaded)
+	}
+
+	if err = ExportImages(context.Background(), source, []string{"missing:1"}, &bytes.Buffer{}, nil); err == nil {
+		t.Error("expected a error for a missing image")
+	}
+	if err = ImportImages(context.Background(), target, strings.NewReader("docker save output"), nil); !errors.Is(err, ErrNoImageArchive) {
+		t.Errorf("expected ErrNoImageArchive, got %v", err)
+	}
+}
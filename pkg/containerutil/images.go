@@ -0,0 +1,146 @@
+package containerutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalImage is a image present in the local image store of the container runtime
+type LocalImage struct {
+	Repository string
+	Tag        string
+	ID         string
+	Created    time.Time
+	Size       int64
+}
+
+// Reference returns repository:tag or the image id for untagged images
+func (i LocalImage) Reference() string {
+	if i.Tag == "" || i.Tag == "<none>" || i.Repository == "<none>" {
+		return i.ID
+	}
+	return i.Repository + ":" + i.Tag
+}
+
+// imageListFormat is supported by docker and podman
+const imageListFormat = `{{.Repository}}\t{{.Tag}}\t{{.ID}}\t{{.CreatedAt}}\t{{.Size}}`
+
+// ListImages returns all images in the local image store
+func ListImages(ctx context.Context, runtime ContainerRuntime) ([]LocalImage, error) {
+	output, err := runtime.Output(ctx, runtimeCommand(runtime, "image", "ls", "--format", imageListFormat))
+	if err != nil {
+		return nil, err
+	}
+
+	var images []LocalImage
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		image, parseErr := ParseImageListLine(line)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		images = append(images, image)
+	}
+
+	return images, nil
+}
+
+// ParseImageListLine parses a single line of the image list
+func ParseImageListLine(line string) (LocalImage, error) {
+	fields := strings.Split(line, "\t")
+	if len(fields) != 5 {
+		return LocalImage{}, fmt.Errorf("unexpected image list format: %s", line)
+	}
+
+	created, err := time.Parse("2006-01-02 15:04:05 -0700 MST", fields[3])
+	if err != nil {
+		return LocalImage{}, fmt.Errorf("unexpected image creation date %s: %w", fields[3], err)
+	}
+
+	return LocalImage{Repository: fields[0], Tag: fields[1], ID: fields[2], Created: created, Size: ParseHumanSize(fields[4])}, nil
+}
+
+// ParseHumanSize parses sizes like 48.2MB or 1.1 GB (decimal units, as printed by docker and podman), returns 0 if the size can't be parsed
+func ParseHumanSize(size string) int64 {
+	size = strings.ReplaceAll(strings.TrimSpace(size), " ", "")
+	units := []struct {
+		suffix     string
+		multiplier float64
+	}{{"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"kB", 1e3}, {"KB", 1e3}, {"B", 1}}
+
+	for _, unit := range units {
+		if strings.HasSuffix(size, unit.suffix) {
+			value, err := strconv.ParseFloat(strings.TrimSuffix(size, unit.suffix), 64)
+			if err != nil {
+				return 0
+			}
+			return int64(value * unit.multiplier)
+		}
+	}
+	return 0
+}
+
+// RemoveImage removes the image with the given reference or id
+func RemoveImage(ctx context.Context, runtime ContainerRuntime, image string) error {
+	_, err := runtime.Output(ctx, runtimeCommand(runtime, "rmi", image))
+	return err
+}
+
+// ImageID returns the id of the local image, the id changes whenever the image content changes
+func ImageID(ctx context.Context, runtime ContainerRuntime, image string) (string, error) {
+	return runtime.Output(ctx, runtimeCommand(runtime, "image", "inspect", "--format", "{{.Id}}", image))
+}
+
+// ImageSize returns the size of the local image in bytes
+func ImageSize(ctx context.Context, runtime ContainerRuntime, image string) (int64, error) {
+	size, err := runtime.Output(ctx, runtimeCommand(runtime, "image", "inspect", "--format", "{{.Size}}", image))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+}
+
+// ImageRepoDigest returns the registry reference of the local image as repository@digest, images pinned to a digest are returned without their tag.
+// Images that haven't been pulled from a registry (ex. built images) have no digest.
+func ImageRepoDigest(ctx context.Context, runtime ContainerRuntime, image string) (string, error) {
+	repository := imageRepository(image)
+	if idx := strings.Index(image, "@"); idx >= 0 {
+		return repository + image[idx:], nil
+	}
+
+	output, err := runtime.Output(ctx, runtimeCommand(runtime, "image", "inspect", "--format", "{{json .RepoDigests}}", image))
+	if err != nil {
+		return "", err
+	}
+	var digests []string
+	_ = json.Unmarshal([]byte(output), &digests)
+	if len(digests) == 0 {
+		return "", errors.New("image " + image + " has no registry digest, only pulled images can be verified")
+	}
+
+	// podman reports the fully qualified repository (docker.io/library/alpine), docker the repository of the pull (alpine)
+	for _, digest := range digests {
+		if name := imageRepository(digest); name == repository || strings.HasSuffix(name, "/"+repository) {
+			return digest, nil
+		}
+	}
+	return digests[0], nil
+}
+
+// imageRepository returns the image reference without tag and digest
+func imageRepository(image string) string {
+	if idx := strings.Index(image, "@"); idx >= 0 {
+		image = image[:idx]
+	}
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		image = image[:idx]
+	}
+	return image
+}
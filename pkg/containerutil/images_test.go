@@ -0,0 +1,52 @@
+package containerutil
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestParseImageListLine(t *testing.T) {
+	image, err := ParseImageListLine("docker.io/library/node\t18-alpine\t5d0a2b3c\t2023-02-01 10:00:00.123456 +0000 UTC\t48.2 MB")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if image.Reference() != "docker.io/library/node:18-alpine" || image.Size != 48200000 || !image.Created.Equal(time.Date(2023, 2, 1, 10, 0, 0, 123456000, time.UTC)) {
+		t.Errorf("unexpected image %+v", image)
+	}
+
+	image, err = ParseImageListLine("<none>\t<none>\t5d0a2b3c\t2023-02-01 10:00:00 +0100 CET\t1.1GB")
+	if err != nil || image.Reference() != "5d0a2b3c" || image.Size != 1100000000 {
+		t.Errorf("unexpected untagged image %+v %v", image, err)
+	}
+
+	if _, err = ParseImageListLine("invalid"); err == nil {
+		t.Errorf("expected an error for an invalid line")
+	}
+}
+
+func TestParseHumanSize(t *testing.T) {
+	var tests = map[string]int64{"48.2MB": 48200000, "1.5 kB": 1500, "12B": 12, "2GB": 2000000000, "": 0, "xMB": 0}
+	for size, expected := range tests {
+		if result := ParseHumanSize(size); result != expected {
+			t.Errorf("%s: expected %d, got %d", size, expected, result)
+		}
+	}
+}
+
+func TestImageRepoDigest(t *testing.T) {
+	runtime := &fakeRuntime{name: "podman", output: func(command string) (string, error) {
+		return `["docker.io/library/golang@sha256:aaa","docker.io/library/alpine@sha256:bbb"]`, nil
+	}}
+	if digest, err := ImageRepoDigest(context.Background(), runtime, "alpine:3.19"); err != nil || digest != "docker.io/library/alpine@sha256:bbb" {
+		t.Errorf("unexpected digest %s (%v)", digest, err)
+	}
+	if digest, err := ImageRepoDigest(context.Background(), runtime, "registry.company.com:5000/node:20@sha256:ccc"); err != nil || digest != "registry.company.com:5000/node@sha256:ccc" {
+		t.Errorf("expected pinned images to keep their digest, got %s (%v)", digest, err)
+	}
+
+	runtime.output = func(command string) (string, error) { return "[]", nil }
+	if _, err := ImageRepoDigest(context.Background(), runtime, "envcli-build/app:latest"); err == nil {
+		t.Errorf("expected a error for images without registry digest")
+	}
+}
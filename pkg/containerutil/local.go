@@ -0,0 +1,86 @@
+package containerutil
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FindLocalCommand returns the path of the command within the PATH of the host, or a empty string if it isn't installed.
+// The skipped directories are ignored, they contain the envcli aliases which would run envcli again.
+// Candidates which resolve to the running executable (symlinks or hardlinks to envcli) are skipped for the same reason.
+func FindLocalCommand(command string, skipDirectories []string) string {
+	self := executableInfo()
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" || isSkippedDirectory(dir, skipDirectories) {
+			continue
+		}
+
+		if path, err := exec.LookPath(filepath.Join(dir, command)); err == nil {
+			if isSameFile(path, self) {
+				log.Debug().Str("path", path).Msg("skipping local command, it resolves to envcli itself")
+				continue
+			}
+			return path
+		}
+	}
+
+	return ""
+}
+
+// executableInfo returns the file info of the running executable, or nil if it can't be determined
+func executableInfo() os.FileInfo {
+	path, err := os.Executable()
+	if err != nil {
+		return nil
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	return info
+}
+
+// isSameFile checks if the path resolves to the same file as the info
+func isSameFile(path string, info os.FileInfo) bool {
+	if info == nil {
+		return false
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	candidate, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(candidate, info)
+}
+
+func isSkippedDirectory(dir string, skipDirectories []string) bool {
+	for _, skipped := range skipDirectories {
+		if filepath.Clean(dir) == filepath.Clean(skipped) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExecLocalCommand runs the binary with the arguments without a shell within the directory (the current directory if empty), the environment variables (NAME=value) are added to the environment of the current process
+func ExecLocalCommand(ctx context.Context, path string, args []string, dir string, env []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	log.Trace().Str("path", path).Strs("args", args).Msg("executing local command")
+	cmd := exec.Command(path, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	return runCommand(ctx, cmd)
+}
@@ -0,0 +1,50 @@
+package containerutil
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestFindLocalCommandSkipsSelf(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require privileges on windows")
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// envcli linked as npm within the PATH, the real npm comes later
+	dir := t.TempDir()
+	symlinked := filepath.Join(dir, "symlink")
+	hardlinked := filepath.Join(dir, "hardlink")
+	installed := filepath.Join(dir, "installed")
+	for _, d := range []string{symlinked, hardlinked, installed} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(self, filepath.Join(symlinked, "npm")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Link(self, filepath.Join(hardlinked, "npm")); err != nil {
+		t.Skipf("hardlinks are not supported: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(installed, "npm"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", strings.Join([]string{symlinked, hardlinked}, string(os.PathListSeparator)))
+	if path := FindLocalCommand("npm", nil); path != "" {
+		t.Errorf("expected the links to envcli to be skipped, got %s", path)
+	}
+
+	t.Setenv("PATH", strings.Join([]string{symlinked, hardlinked, installed}, string(os.PathListSeparator)))
+	if path := FindLocalCommand("npm", nil); path != filepath.Join(installed, "npm") {
+		t.Errorf("expected %s, got %s", filepath.Join(installed, "npm"), path)
+	}
+}
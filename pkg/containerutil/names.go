@@ -0,0 +1,193 @@
+package containerutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/rs/zerolog/log"
+)
+
+// ManagedLabel marks all containers started by envcli run, used by envcli ps
+const ManagedLabel = "envcli.managed"
+
+// maxNamePartLength limits the length of the project and command within container names
+const maxNamePartLength = 24
+
+// ContainerName returns a readable container name: envcli-<project>-<command>-<id>.
+// The project and command are reduced to the characters allowed by docker, the id keeps simultaneous runs apart.
+func ContainerName(project string, command string) string {
+	return "envcli-" + sanitizeNamePart(project, "project") + "-" + sanitizeNamePart(command, "command") + "-" + shortID()
+}
+
+// sanitizeNamePart lowercases the text and replaces all characters except a-z, 0-9, _ and . by dashes, the fallback is used if nothing remains (ex. for unicode names)
+func sanitizeNamePart(text string, fallback string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '.' {
+			b.WriteRune(r)
+		} else if !strings.HasSuffix(b.String(), "-") {
+			b.WriteRune('-')
+		}
+	}
+
+	part := strings.Trim(b.String(), "-_.")
+	if len(part) > maxNamePartLength {
+		part = strings.Trim(part[:maxNamePartLength], "-_.")
+	}
+	if part == "" {
+		return fallback
+	}
+	return part
+}
+
+// shortID returns 6 random hex characters
+func shortID() string {
+	id := make([]byte, 3)
+	if _, err := rand.Read(id); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano()%0xffffff, 16)
+	}
+	return hex.EncodeToString(id)
+}
+
+// runtimeErrorExitCode is returned by the container runtime if the container can't be created, ex. because the name is already in use
+const runtimeErrorExitCode = 125
+
+// nameConflictMessages are the errors of docker / podman (is already in use) and nerdctl (is already used, already exists) if the container name is taken
+var nameConflictMessages = []string{"is already in use", "is already used", "already exists"}
+
+// nameConflictDetector is a writer that detects the name conflict error of the runtime for the container name within the output
+type nameConflictDetector struct {
+	mutex    sync.Mutex
+	name     string
+	tail     []byte
+	detected bool
+}
+
+func (d *nameConflictDetector) Write(p []byte) (int, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.detected {
+		return len(p), nil
+	}
+
+	d.tail = append(d.tail, p...)
+	for _, line := range strings.Split(string(d.tail), "\n") {
+		if strings.Contains(line, d.name) && containsAny(line, nameConflictMessages) {
+			d.detected = true
+		}
+	}
+	if keep := 512; len(d.tail) > keep {
+		d.tail = append([]byte{}, d.tail[len(d.tail)-keep:]...)
+	}
+	return len(p), nil
+}
+
+// Detected returns true if the runtime reported that the container name is already in use
+func (d *nameConflictDetector) Detected() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.detected
+}
+
+func containsAny(text string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(text, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExecWithUniqueName executes the run command of the named container, if the runtime fails because the name is already in use the command is executed again using a suffixed name.
+// Only the name conflict error of the runtime is retried, the exit code 125 of the command itself (ex. of a retained container) is returned as is.
+// It returns the final name of the container.
+func ExecWithUniqueName(ctx context.Context, runtime ContainerRuntime, runCommand []string, name string, stdin io.Reader, stdout io.Writer, stderr io.Writer) (string, error) {
+	conflict := &nameConflictDetector{name: name}
+	output := io.Writer(conflict)
+	if stderr != nil {
+		output = io.MultiWriter(stderr, conflict)
+	}
+	err := runtime.Exec(ctx, runCommand, stdin, stdout, output)
+	if exitcode.Of(err) != runtimeErrorExitCode || !conflict.Detected() {
+		return name, err
+	}
+
+	unique := UniqueContainerName(ctx, runtime, name)
+	if unique == name {
+		return name, err
+	}
+	log.Warn().Str("container", name).Str("renamed", unique).Msg("container name is already in use, using a suffixed name")
+	return unique, runtime.Exec(ctx, RenameContainer(runCommand, name, unique), stdin, stdout, stderr)
+}
+
+// RenameContainer replaces the container name within the rendered run command
+func RenameContainer(runCommand []string, name string, renamed string) []string {
+	renamedCommand := append([]string{}, runCommand...)
+	for i := 1; i < len(renamedCommand); i++ {
+		if renamedCommand[i-1] == "--name" && renamedCommand[i] == name {
+			renamedCommand[i] = renamed
+			break
+		}
+	}
+	return renamedCommand
+}
+
+// UniqueContainerName returns the name, suffixed by -2, -3, ... if a container with the name already exists
+func UniqueContainerName(ctx context.Context, runtime ContainerRuntime, name string) string {
+	output, err := runtime.Output(ctx, runtimeCommand(runtime, "ps", "-a", "--filter", "name="+name, "--format", "{{.Names}}"))
+	if err != nil || output == "" {
+		return name
+	}
+
+	existing := make(map[string]bool)
+	for _, line := range strings.Fields(output) {
+		existing[line] = true
+	}
+	unique := name
+	for i := 2; existing[unique]; i++ {
+		unique = name + "-" + strconv.Itoa(i)
+	}
+	return unique
+}
+
+// ManagedLabelArgs returns the run arguments to mark a container as started by envcli
+func ManagedLabelArgs() []string {
+	return []string{"--label", ManagedLabel + "=true"}
+}
+
+// ManagedContainer is a container started by envcli run
+type ManagedContainer struct {
+	Name   string
+	Image  string
+	Status string
+}
+
+// ListManagedContainers returns the running containers started by envcli, including the stopped containers if all is set
+func ListManagedContainers(ctx context.Context, runtime ContainerRuntime, all bool) ([]ManagedContainer, error) {
+	command := runtimeCommand(runtime, "ps")
+	if all {
+		command = append(command, "-a")
+	}
+	command = append(command, "--filter", "label="+ManagedLabel, "--format", `{{.Names}}\t{{.Image}}\t{{.Status}}`)
+	output, err := runtime.Output(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+
+	var containers []ManagedContainer
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		containers = append(containers, ManagedContainer{Name: fields[0], Image: fields[1], Status: fields[2]})
+	}
+	return containers, nil
+}
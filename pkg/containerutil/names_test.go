@@ -0,0 +1,111 @@
+package containerutil
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+)
+
+func TestContainerName(t *testing.T) {
+	var tests = []struct {
+		project string
+		command string
+		prefix  string
+	}{
+		{"my-service", "go", "envcli-my-service-go-"},
+		{"My Project (2)", "npm", "envcli-my-project-2-npm-"},
+		{"Café Außen", "go", "envcli-caf-au-en-go-"},
+		{"プロジェクト", "go", "envcli-project-go-"},
+		{"a-very-long-project-name-exceeding-the-limit", "gradle", "envcli-a-very-long-project-name-gradle-"},
+	}
+
+	valid := regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+	for _, test := range tests {
+		name := ContainerName(test.project, test.command)
+		if !strings.HasPrefix(name, test.prefix) || len(name) != len(test.prefix)+6 || !valid.MatchString(name) {
+			t.Errorf("%s %s: expected a valid name starting with %s, got %s", test.project, test.command, test.prefix, name)
+		}
+	}
+
+	if ContainerName("project", "go") == ContainerName("project", "go") {
+		t.Errorf("expected simultaneous runs to get different names")
+	}
+}
+
+func TestUniqueContainerName(t *testing.T) {
+	runtime := &fakeRuntime{name: "docker", output: func(command string) (string, error) {
+		return "envcli-app-go-abc123\nenvcli-app-go-abc123-2\nenvcli-app-go-abc1234", nil
+	}}
+	if name := UniqueContainerName(context.Background(), runtime, "envcli-app-go-abc123"); name != "envcli-app-go-abc123-3" {
+		t.Errorf("expected the name to be suffixed, got %s", name)
+	}
+
+	runtime = &fakeRuntime{name: "docker", output: func(command string) (string, error) { return "", nil }}
+	if name := UniqueContainerName(context.Background(), runtime, "envcli-app-go-abc123"); name != "envcli-app-go-abc123" {
+		t.Errorf("expected the name to be kept, got %s", name)
+	}
+}
+
+func TestListManagedContainers(t *testing.T) {
+	runtime := &fakeRuntime{name: "podman", output: func(command string) (string, error) {
+		return "envcli-app-go-abc123\tgolang:1.21\tUp 2 minutes\n", nil
+	}}
+	containers, err := ListManagedContainers(context.Background(), runtime, true)
+	if err != nil || len(containers) != 1 || containers[0].Name != "envcli-app-go-abc123" || containers[0].Status != "Up 2 minutes" {
+		t.Errorf("unexpected containers %+v (%v)", containers, err)
+	}
+	if !strings.HasPrefix(runtime.commands[0], "podman ps -a --filter label=envcli.managed ") {
+		t.Errorf("unexpected command %s", runtime.commands[0])
+	}
+}
+
+func TestExecWithUniqueName(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	conflict := exitError(t, strconv.Itoa(runtimeErrorExitCode))
+	newRuntime := func(message string) *fakeRuntime {
+		return &fakeRuntime{name: "docker", output: func(command string) (string, error) {
+			switch {
+			case strings.Contains(command, " ps "):
+				return "envcli-app-go-abc123", nil
+			case strings.Contains(command, "--name envcli-app-go-abc123 "):
+				return "", conflict
+			}
+			return "", nil
+		}, errOutput: func(command string) string {
+			if strings.Contains(command, "--name envcli-app-go-abc123 ") {
+				return message
+			}
+			return ""
+		}}
+	}
+	runCommand := []string{"docker", "run", "--rm", "--name", "envcli-app-go-abc123", "golang:1.21", "go"}
+
+	// the name is in use
+	runtime := newRuntime(`docker: Error response from daemon: Conflict. The container name "/envcli-app-go-abc123" is already in use by container "0f3c".` + "\n")
+	var stderr bytes.Buffer
+	name, err := ExecWithUniqueName(context.Background(), runtime, runCommand, "envcli-app-go-abc123", nil, nil, &stderr)
+	if err != nil || name != "envcli-app-go-abc123-2" {
+		t.Fatalf("expected the suffixed name, got %s (%v)", name, err)
+	}
+	if last := runtime.commands[len(runtime.commands)-1]; last != "docker run --rm --name envcli-app-go-abc123-2 golang:1.21 go" {
+		t.Errorf("unexpected run command %s", last)
+	}
+	if !strings.Contains(stderr.String(), "is already in use") {
+		t.Errorf("expected the output of the runtime to be passed through, got %q", stderr.String())
+	}
+
+	// the command itself exits with 125, it isn't executed again
+	runtime = newRuntime("go: build failed\n")
+	name, err = ExecWithUniqueName(context.Background(), runtime, runCommand, "envcli-app-go-abc123", nil, nil, nil)
+	if exitcode.Of(err) != runtimeErrorExitCode || name != "envcli-app-go-abc123" || len(runtime.commands) != 1 {
+		t.Errorf("expected the exit code of the command without a second run, got %s %v (%v)", name, runtime.commands, err)
+	}
+}
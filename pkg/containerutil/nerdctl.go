@@ -0,0 +1,86 @@
+package containerutil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/cidverse/cidverseutils/pkg/cihelper"
+	"github.com/rs/zerolog/log"
+)
+
+// NerdctlNamespaceEnv is the environment variable used by nerdctl to select the containerd namespace
+const NerdctlNamespaceEnv = "CONTAINERD_NAMESPACE"
+
+// nerdctlOptionalFlags are run flags that are missing in some nerdctl versions, they are dropped if unsupported
+var nerdctlOptionalFlags = []string{"--gpus", "--security-opt", "--ulimit"}
+
+// IsNerdctl returns true if nerdctl (containerd) is available
+func IsNerdctl() bool {
+	return cihelper.IsExecutableInPath("nerdctl")
+}
+
+// ApplyNerdctlNamespace selects the containerd namespace used by nerdctl, ex. k8s.io to share the images with kubernetes in Rancher Desktop.
+// A namespace set in the environment takes precedence.
+func ApplyNerdctlNamespace(namespace string) {
+	if namespace == "" || os.Getenv(NerdctlNamespaceEnv) != "" {
+		return
+	}
+	_ = os.Setenv(NerdctlNamespaceEnv, namespace)
+}
+
+// RenderRunCommand renders the arguments to run the container using the runtime, starting with the runtime client
+func RenderRunCommand(ctx context.Context, runtime ContainerRuntime, container *Container) ([]string, error) {
+	if runtime.Name() != "podman" && runtime.Name() != "docker" && runtime.Name() != "nerdctl" {
+		return nil, errors.New("container runtime [" + runtime.Name() + "] is not supported!")
+	}
+
+	options := container.runOptions(runtime.Name())
+	if runtime.Name() == "nerdctl" {
+		// nerdctl is compatible to the docker cli, apart from a few differences
+		var help string
+		for _, flag := range nerdctlOptionalFlags {
+			if !hasFlag(options, flag) {
+				continue
+			}
+			if help == "" {
+				help, _ = runtime.Output(ctx, []string{"nerdctl", "run", "--help"})
+			}
+			if !strings.Contains(help, flag) {
+				log.Warn().Str("flag", flag).Msg("the installed nerdctl version doesn't support the flag, running the container without it")
+				options = removeFlag(options, flag)
+			}
+		}
+	}
+
+	args := append([]string{runtime.Name(), "run"}, options...)
+	args = append(args, container.image)
+	return append(args, container.command...), nil
+}
+
+// hasFlag returns true if the options contain the flag, either followed by its value or as flag=value
+func hasFlag(options []string, flag string) bool {
+	for _, option := range options {
+		if option == flag || strings.HasPrefix(option, flag+"=") {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFlag removes all occurrences of the flag and its value from the options
+func removeFlag(options []string, flag string) []string {
+	var kept []string
+	for i := 0; i < len(options); i++ {
+		if options[i] == flag {
+			i++
+			continue
+		}
+		if strings.HasPrefix(options[i], flag+"=") {
+			continue
+		}
+		kept = append(kept, options[i])
+	}
+	return kept
+}
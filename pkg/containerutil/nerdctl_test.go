@@ -0,0 +1,79 @@
+package containerutil
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cidverse/cidverseutils/pkg/containerruntime"
+)
+
+func TestRenderRunCommandNerdctl(t *testing.T) {
+	container := Container{}
+	container.SetImage("alpine")
+	container.AddVolume(containerruntime.ContainerMount{MountType: "directory", Source: `C:\project`, Target: "/project"})
+	container.AddVolume(containerruntime.ContainerMount{MountType: "directory", Source: "/home/user/.gitconfig", Target: "/etc/gitconfig", Mode: containerruntime.ReadMode})
+	container.AddArgs("--gpus", "all", "--ulimit", "nofile=1024", "--security-opt=no-new-privileges")
+	container.SetCommand([]string{"echo"})
+
+	runtime := &fakeRuntime{name: "nerdctl", output: func(command string) (string, error) {
+		return "Flags:\n  --ulimit strings\n", nil
+	}}
+	args, err := RenderRunCommand(context.Background(), runtime, &container)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runCommand := strings.Join(args, " ")
+	if !strings.HasPrefix(runCommand, "nerdctl run --rm ") {
+		t.Errorf("expected a nerdctl command, got %q", runCommand)
+	}
+	if !strings.Contains(runCommand, `--mount type=bind,source=C:\project,target=/project`) {
+		t.Errorf("expected the project mount, got %q", runCommand)
+	}
+	if !strings.Contains(runCommand, "--mount type=bind,source=/home/user/.gitconfig,target=/etc/gitconfig,readonly") {
+		t.Errorf("expected a readonly mount, got %q", runCommand)
+	}
+	if strings.Contains(runCommand, "-v ") {
+		t.Errorf("expected no volume arguments, got %q", runCommand)
+	}
+	if strings.Contains(runCommand, "--gpus") || strings.Contains(runCommand, "--security-opt") || !strings.Contains(runCommand, "--ulimit nofile=1024 alpine echo") {
+		t.Errorf("expected only the unsupported flags to be removed, got %q", runCommand)
+	}
+	if strings.Join(runtime.commands, "|") != "nerdctl run --help" {
+		t.Errorf("unexpected commands %v", runtime.commands)
+	}
+}
+
+func TestRenderRunCommandDocker(t *testing.T) {
+	container := Container{}
+	container.SetImage("alpine")
+	container.AddVolume(containerruntime.ContainerMount{MountType: "directory", Source: "/src", Target: "/project"})
+
+	args, err := RenderRunCommand(context.Background(), &fakeRuntime{name: "docker"}, &container)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runCommand := strings.Join(args, " "); !strings.HasPrefix(runCommand, "docker run --rm ") || !strings.Contains(runCommand, "-v /src:/project alpine") {
+		t.Errorf("expected the docker command to be unchanged, got %q", runCommand)
+	}
+
+	if _, err = RenderRunCommand(context.Background(), &fakeRuntime{name: "unknown"}, &container); err == nil {
+		t.Errorf("expected an error for an unsupported runtime")
+	}
+}
+
+func TestApplyNerdctlNamespace(t *testing.T) {
+	t.Setenv(NerdctlNamespaceEnv, "")
+	ApplyNerdctlNamespace("k8s.io")
+	if value := os.Getenv(NerdctlNamespaceEnv); value != "k8s.io" {
+		t.Errorf("expected the namespace to be set, got %q", value)
+	}
+
+	t.Setenv(NerdctlNamespaceEnv, "custom")
+	ApplyNerdctlNamespace("k8s.io")
+	if value := os.Getenv(NerdctlNamespaceEnv); value != "custom" {
+		t.Errorf("expected the environment to take precedence, got %q", value)
+	}
+}
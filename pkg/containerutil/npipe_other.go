@@ -0,0 +1,12 @@
+//go:build !windows
+
+package containerutil
+
+import (
+	"errors"
+)
+
+// probeNamedPipe always fails, named pipes only exist on windows
+func probeNamedPipe(path string) error {
+	return errors.New("named pipes are only supported on windows")
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package containerutil
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// errorPipeBusy is returned if all instances of the named pipe are in use, the pipe exists
+const errorPipeBusy = syscall.Errno(231)
+
+// probeNamedPipe opens the named pipe to check if it exists and can be accessed by the current user
+func probeNamedPipe(path string) error {
+	file, err := os.OpenFile(strings.ReplaceAll(path, "/", `\`), os.O_RDWR, 0)
+	if errors.Is(err, errorPipeBusy) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
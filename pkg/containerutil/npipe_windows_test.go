@@ -0,0 +1,49 @@
+//go:build windows
+
+package containerutil
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"unsafe"
+)
+
+var procCreateNamedPipe = syscall.NewLazyDLL("kernel32.dll").NewProc("CreateNamedPipeW")
+
+const (
+	pipeAccessDuplex = 0x00000003
+	pipeUnlimited    = 255
+)
+
+// fakePipeServer creates a named pipe without a daemon behind it, it is closed at the end of the test
+func fakePipeServer(t *testing.T) string {
+	t.Helper()
+	pipe := fmt.Sprintf(`\\.\pipe\envcli-test-%d`, os.Getpid())
+	name, err := syscall.UTF16PtrFromString(pipe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, _, err := procCreateNamedPipe.Call(uintptr(unsafe.Pointer(name)), pipeAccessDuplex, 0, pipeUnlimited, 4096, 4096, 0, 0)
+	handle := syscall.Handle(r)
+	if handle == syscall.InvalidHandle {
+		t.Fatalf("failed to create the named pipe %s: %v", pipe, err)
+	}
+	t.Cleanup(func() { _ = syscall.CloseHandle(handle) })
+	return pipe
+}
+
+func TestProbeNamedPipe(t *testing.T) {
+	pipe := fakePipeServer(t)
+
+	if !DockerSocketReachable(pipe) {
+		t.Errorf("expected the pipe %s to be reachable", pipe)
+	}
+	if DockerSocketReachable(`\\.\pipe\envcli-test-missing`) {
+		t.Error("expected a missing pipe to be unreachable")
+	}
+	if err := checkDockerHost("windows", DockerHostURL(pipe), probeNamedPipe); err != nil {
+		t.Errorf("expected access to the pipe, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package containerutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/rs/zerolog/log"
+)
+
+// DerivedImageRepository is the repository prefix of the images with the extra packages of a entry installed
+const DerivedImageRepository = "envcli-derived/"
+
+// invalidDerivedNameChars matches all characters that are not allowed within a image repository name
+var invalidDerivedNameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// PackageManager installs packages within a container
+type PackageManager struct {
+	// Name of the package manager (ex. apt)
+	Name string
+
+	// Binary is used to detect the package manager within the image (ex. apt-get)
+	Binary string
+
+	// install is the install command, the packages replace %s
+	install string
+}
+
+// PackageManagers are the supported package managers, in the order they are probed
+var PackageManagers = []PackageManager{
+	{Name: "apk", Binary: "apk", install: "apk add --no-cache %s"},
+	{Name: "apt", Binary: "apt-get", install: "apt-get update -qq && DEBIAN_FRONTEND=noninteractive apt-get install -y -qq --no-install-recommends %s && rm -rf /var/lib/apt/lists/*"},
+	{Name: "dnf", Binary: "dnf", install: "dnf install -y -q %s && dnf clean all"},
+}
+
+// InstallCommand returns the shell command installing the packages
+func (m PackageManager) InstallCommand(packages []string) string {
+	return fmt.Sprintf(m.install, strings.Join(packages, " "))
+}
+
+// packageManagerNames returns the names of the supported package managers, ex. for error messages
+func packageManagerNames() string {
+	var names []string
+	for _, manager := range PackageManagers {
+		names = append(names, manager.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// InstallScript returns a shell script that installs the packages using the first package manager found within the container.
+// It is used as prefix of the command if the packages are installed at each container start.
+func InstallScript(packages []string) string {
+	var script strings.Builder
+	for i, manager := range PackageManagers {
+		if i == 0 {
+			script.WriteString("if ")
+		} else {
+			script.WriteString("elif ")
+		}
+		fmt.Fprintf(&script, "command -v %s >/dev/null 2>&1; then %s >&2; ", manager.Binary, manager.InstallCommand(packages))
+	}
+	fmt.Fprintf(&script, "else echo 'envcli: extraPackages requires one of the package managers %s, none has been found in the image' >&2; exit 127; fi", packageManagerNames())
+	return script.String()
+}
+
+// DetectPackageManager probes the image for the supported package managers, the image must provide sh
+func DetectPackageManager(ctx context.Context, runtime ContainerRuntime, image string) (PackageManager, error) {
+	var binaries []string
+	for _, manager := range PackageManagers {
+		binaries = append(binaries, manager.Binary)
+	}
+	script := fmt.Sprintf("for binary in %s; do if command -v $binary >/dev/null 2>&1; then echo $binary; exit 0; fi; done", strings.Join(binaries, " "))
+
+	output, err := runtime.Output(ctx, runtimeCommand(runtime, "run", "--rm", "--entrypoint", "sh", image, "-c", script))
+	if err != nil {
+		log.Debug().Err(err).Str("image", image).Msg("failed to probe the package manager of the image")
+	}
+	for _, manager := range PackageManagers {
+		if strings.TrimSpace(output) == manager.Binary {
+			return manager, nil
+		}
+	}
+	return PackageManager{}, exitcode.New(exitcode.ConfigError, fmt.Errorf("image %s has no known package manager (%s) or no sh, extraPackages can't be installed - use a image that provides the packages instead", image, packageManagerNames()))
+}
+
+// DerivedImageName returns the name of the image with the packages installed: envcli-derived/<repository>:<hash over the id of the image and the packages>
+// The name changes if the base image is updated or the packages are changed, which derives the image again.
+func DerivedImageName(image string, imageID string, packages []string) string {
+	sorted := append([]string{}, packages...)
+	sort.Strings(sorted)
+	hash := sha256.Sum256([]byte(strings.TrimSpace(imageID) + "\n" + strings.Join(sorted, "\n")))
+
+	repository := imageRepository(image)
+	repository = repository[strings.LastIndex(repository, "/")+1:]
+	repository = strings.Trim(invalidDerivedNameChars.ReplaceAllString(strings.ToLower(repository), "-"), "-._")
+	return DerivedImageRepository + repository + ":" + hex.EncodeToString(hash[:])[:12]
+}
+
+// EnsureDerivedImage returns the image with the packages installed, it is built on first use and reused afterwards.
+// The packages are installed as root, the user of the image is restored afterwards. The build output is written to the output writer.
+func EnsureDerivedImage(ctx context.Context, runtime ContainerRuntime, image string, packages []string, output io.Writer) (string, error) {
+	imageID, err := ImageID(ctx, runtime, image)
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect image %s: %w", image, err)
+	}
+	derived := DerivedImageName(image, imageID, packages)
+	if ImageExists(ctx, runtime, derived) {
+		log.Debug().Str("image", derived).Msg("reusing the derived image with the extra packages")
+		return derived, nil
+	}
+
+	manager, err := DetectPackageManager(ctx, runtime, image)
+	if err != nil {
+		return "", err
+	}
+	user, _ := runtime.Output(ctx, runtimeCommand(runtime, "image", "inspect", "--format", "{{.Config.User}}", image))
+
+	dockerfile := fmt.Sprintf("FROM %s\nUSER root\nRUN %s\n", image, manager.InstallCommand(packages))
+	if user = strings.TrimSpace(user); user != "" {
+		dockerfile += "USER " + user + "\n"
+	}
+
+	// the packages need no build context
+	contextDir, err := os.MkdirTemp("", "envcli-derive-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(contextDir)
+
+	log.Info().Str("image", derived).Str("packages", strings.Join(packages, " ")).Str("package-manager", manager.Name).Msg("installing the extra packages into a derived image")
+	command := runtimeCommand(runtime, "build", "-t", derived, "--label", ManagedLabel+"=true", "-f", "-", contextDir)
+	if err = runtime.Exec(ctx, command, strings.NewReader(dockerfile), output, output); err != nil {
+		return "", exitcode.New(exitcode.ImagePullFailure, errors.New("failed to install the extra packages "+strings.Join(packages, ", ")+" into image "+image+" using "+manager.Name+": "+err.Error()))
+	}
+	return derived, nil
+}
@@ -0,0 +1,86 @@
+package containerutil
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+)
+
+func TestInstallScript(t *testing.T) {
+	script := InstallScript([]string{"jq", "curl"})
+	for _, expected := range []string{
+		"if command -v apk >/dev/null 2>&1; then apk add --no-cache jq curl >&2; ",
+		"elif command -v apt-get >/dev/null 2>&1; then apt-get update -qq && ",
+		"elif command -v dnf >/dev/null 2>&1; then dnf install -y -q jq curl && dnf clean all >&2; ",
+		"none has been found in the image' >&2; exit 127; fi",
+	} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("expected %q in %s", expected, script)
+		}
+	}
+}
+
+func TestDerivedImageName(t *testing.T) {
+	name := DerivedImageName("docker.io/library/node:20", "sha256:abc", []string{"jq", "curl"})
+	if !strings.HasPrefix(name, "envcli-derived/node:") || len(name) != len("envcli-derived/node:")+12 {
+		t.Errorf("unexpected name %s", name)
+	}
+	if DerivedImageName("node:20", "sha256:abc", []string{"curl", "jq"}) != name {
+		t.Errorf("expected the order of the packages to not matter")
+	}
+	if DerivedImageName("node:20", "sha256:def", []string{"jq", "curl"}) == name || DerivedImageName("node:20", "sha256:abc", []string{"jq"}) == name {
+		t.Errorf("expected the name to change with the image and the packages")
+	}
+}
+
+func TestDetectPackageManager(t *testing.T) {
+	runtime := &fakeRuntime{name: "podman", output: func(command string) (string, error) { return "apt-get\n", nil }}
+	manager, err := DetectPackageManager(context.Background(), runtime, "debian:12")
+	if err != nil || manager.Name != "apt" {
+		t.Errorf("expected apt, got %+v (%v)", manager, err)
+	}
+	if !strings.HasPrefix(runtime.commands[0], "podman run --rm --entrypoint sh debian:12 -c ") {
+		t.Errorf("unexpected probe %s", runtime.commands[0])
+	}
+
+	runtime.output = func(command string) (string, error) { return "", errors.New("exit status 127") }
+	_, err = DetectPackageManager(context.Background(), runtime, "gcr.io/distroless/static")
+	if exitcode.Of(err) != exitcode.ConfigError || !strings.Contains(err.Error(), "image gcr.io/distroless/static has no known package manager (apk, apt, dnf)") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestEnsureDerivedImage(t *testing.T) {
+	derivedExists := true
+	runtime := &fakeRuntime{name: "docker", output: func(command string) (string, error) {
+		switch {
+		case strings.Contains(command, "{{.Id}}"):
+			return "sha256:abc", nil
+		case strings.Contains(command, "{{.Config.User}}"):
+			return "node", nil
+		case strings.Contains(command, " image inspect "+DerivedImageRepository) && !derivedExists:
+			return "", errors.New("no such image")
+		case strings.Contains(command, " run --rm "):
+			return "apk", nil
+		}
+		return "", nil
+	}}
+
+	image, err := EnsureDerivedImage(context.Background(), runtime, "node:20-alpine", []string{"jq"}, nil)
+	if err != nil || image != DerivedImageName("node:20-alpine", "sha256:abc", []string{"jq"}) || len(runtime.commands) != 2 {
+		t.Errorf("expected the existing derived image to be reused, got %s (%v): %v", image, err, runtime.commands)
+	}
+
+	derivedExists = false
+	runtime.commands = nil
+	if _, err = EnsureDerivedImage(context.Background(), runtime, "node:20-alpine", []string{"jq"}, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	build := runtime.commands[len(runtime.commands)-1]
+	if !strings.HasPrefix(build, "docker build -t "+image+" --label envcli.managed=true -f - ") {
+		t.Errorf("expected the derived image to be built, got %v", runtime.commands)
+	}
+}
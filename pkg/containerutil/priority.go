@@ -0,0 +1,35 @@
+package containerutil
+
+import (
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+// WrapHostPriority runs the command under nice and ionice on linux hosts, the processes started by the runtime client inherit the priority (ex. the containers of podman).
+// nice and ionice are skipped if they are not installed, other operating systems only use the container options.
+func WrapHostPriority(goos string, command []string, nice int, ioClass int, ioLevel int, lookPath func(file string) (string, error)) []string {
+	if nice == 0 && ioClass == 0 {
+		return command
+	}
+	if goos != "linux" {
+		log.Debug().Str("os", goos).Msg("nice and ionice are only supported on linux, using the container cpu and io weights only")
+		return command
+	}
+
+	if ioClass != 0 {
+		if _, err := lookPath("ionice"); err == nil {
+			command = append([]string{"ionice", "-c", strconv.Itoa(ioClass), "-n", strconv.Itoa(ioLevel)}, command...)
+		} else {
+			log.Debug().Msg("ionice is not installed, skipping the io priority of the runtime client")
+		}
+	}
+	if nice != 0 {
+		if _, err := lookPath("nice"); err == nil {
+			command = append([]string{"nice", "-n", strconv.Itoa(nice)}, command...)
+		} else {
+			log.Debug().Msg("nice is not installed, skipping the cpu priority of the runtime client")
+		}
+	}
+	return command
+}
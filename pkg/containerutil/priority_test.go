@@ -0,0 +1,31 @@
+package containerutil
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWrapHostPriority(t *testing.T) {
+	runArgs := []string{"docker", "run", "alpine"}
+	installed := func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	if command := WrapHostPriority("linux", runArgs, 10, 2, 7, installed); strings.Join(command, " ") != "nice -n 10 ionice -c 2 -n 7 docker run alpine" {
+		t.Errorf("unexpected command %s", command)
+	}
+	if command := WrapHostPriority("linux", runArgs, 0, 0, 0, installed); strings.Join(command, " ") != "docker run alpine" {
+		t.Errorf("expected the normal priority to keep the command, got %s", command)
+	}
+	if command := WrapHostPriority("darwin", runArgs, 10, 2, 7, installed); strings.Join(command, " ") != "docker run alpine" {
+		t.Errorf("expected only the container options on macOS, got %s", command)
+	}
+
+	withoutIonice := func(file string) (string, error) {
+		if file == "ionice" {
+			return "", errors.New("not found")
+		}
+		return "/usr/bin/" + file, nil
+	}
+	if command := WrapHostPriority("linux", runArgs, 10, 2, 7, withoutIonice); strings.Join(command, " ") != "nice -n 10 docker run alpine" {
+		t.Errorf("expected a missing ionice to be skipped, got %s", command)
+	}
+}
@@ -0,0 +1,129 @@
+package containerutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Readiness labels store the probe of a detached container, used by envcli ps
+const (
+	ReadinessHTTPLabel    = "envcli.readiness.http"
+	ReadinessCommandLabel = "envcli.readiness.command"
+)
+
+// Readiness states shown by envcli ps
+const (
+	ReadinessReady    = "ready"
+	ReadinessStarting = "starting"
+)
+
+// maxProbeAttemptTimeout limits a single http request or command of the probe
+const maxProbeAttemptTimeout = 5 * time.Second
+
+// ReadinessProbe checks whether a container is ready, using a http request from the host or a command within the container
+type ReadinessProbe struct {
+	HTTPGet  string
+	Command  string
+	Timeout  time.Duration
+	Interval time.Duration
+}
+
+// LabelArgs returns the run arguments storing the probe as labels of the container
+func (p ReadinessProbe) LabelArgs() []string {
+	if p.HTTPGet != "" {
+		return []string{"--label", ReadinessHTTPLabel + "=" + p.HTTPGet}
+	}
+	return []string{"--label", ReadinessCommandLabel + "=" + p.Command}
+}
+
+// Probe checks the readiness of the container once
+func (p ReadinessProbe) Probe(ctx context.Context, runtime ContainerRuntime, container string) error {
+	attemptTimeout := p.Interval
+	if attemptTimeout <= 0 || attemptTimeout > maxProbeAttemptTimeout {
+		attemptTimeout = maxProbeAttemptTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, attemptTimeout)
+	defer cancel()
+
+	if p.HTTPGet != "" {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, p.HTTPGet, nil)
+		if err != nil {
+			return err
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			return err
+		}
+		response.Body.Close()
+		if response.StatusCode < 200 || response.StatusCode >= 400 {
+			return fmt.Errorf("GET %s returned status %d", p.HTTPGet, response.StatusCode)
+		}
+		return nil
+	}
+
+	output, err := runtime.Output(ctx, runtimeCommand(runtime, "exec", container, "sh", "-c", p.Command))
+	if err != nil {
+		if output = strings.TrimSpace(output); output != "" {
+			return fmt.Errorf("%s failed: %w: %s", p.Command, err, output)
+		}
+		return fmt.Errorf("%s failed: %w", p.Command, err)
+	}
+	return nil
+}
+
+// WaitReady probes the container until it is ready, the last probe error is returned once the timeout expired
+func (p ReadinessProbe) WaitReady(ctx context.Context, runtime ContainerRuntime, container string) error {
+	deadline := time.Now().Add(p.Timeout)
+	for {
+		err := p.Probe(ctx, runtime, container)
+		if err == nil {
+			return nil
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		} else if time.Now().Add(p.Interval).After(deadline) {
+			return fmt.Errorf("container %s is not ready after %s, last probe error: %w", container, p.Timeout, err)
+		}
+
+		select {
+		case <-time.After(p.Interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ContainerReadinessProbe returns the probe stored in the labels of the container, nil if it has none
+func ContainerReadinessProbe(ctx context.Context, runtime ContainerRuntime, container string) (*ReadinessProbe, error) {
+	output, err := runtime.Output(ctx, runtimeCommand(runtime, "inspect", "--format", "{{json .Config.Labels}}", container))
+	if err != nil {
+		return nil, err
+	}
+
+	var labels map[string]string
+	if output = strings.TrimSpace(output); output != "" && output != "null" {
+		if err = json.Unmarshal([]byte(output), &labels); err != nil {
+			return nil, errors.New("failed to parse the labels of container " + container + ": " + err.Error())
+		}
+	}
+	if labels[ReadinessHTTPLabel] == "" && labels[ReadinessCommandLabel] == "" {
+		return nil, nil
+	}
+	return &ReadinessProbe{HTTPGet: labels[ReadinessHTTPLabel], Command: labels[ReadinessCommandLabel], Interval: time.Second}, nil
+}
+
+// ContainerReadiness probes the container once using the probe stored in its labels, empty if the container has no probe
+func ContainerReadiness(ctx context.Context, runtime ContainerRuntime, container string) string {
+	probe, err := ContainerReadinessProbe(ctx, runtime, container)
+	if err != nil || probe == nil {
+		return ""
+	}
+	if probe.Probe(ctx, runtime, container) != nil {
+		return ReadinessStarting
+	}
+	return ReadinessReady
+}
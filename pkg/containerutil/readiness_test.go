@@ -0,0 +1,52 @@
+package containerutil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadinessProbeHTTP(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	probe := ReadinessProbe{HTTPGet: server.URL, Timeout: time.Second, Interval: 10 * time.Millisecond}
+	if err := probe.WaitReady(context.Background(), &fakeRuntime{name: "docker"}, "envcli-web"); err != nil || requests != 3 {
+		t.Errorf("expected the probe to succeed after 3 requests, got %d (%v)", requests, err)
+	}
+
+	probe.HTTPGet, probe.Timeout = server.URL+"/missing", 50*time.Millisecond
+	requests = 0
+	server.Config.Handler = http.NotFoundHandler()
+	err := probe.WaitReady(context.Background(), &fakeRuntime{name: "docker"}, "envcli-web")
+	if err == nil || !strings.Contains(err.Error(), "container envcli-web is not ready after 50ms, last probe error: GET "+server.URL+"/missing returned status 404") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestContainerReadinessProbe(t *testing.T) {
+	runtime := &fakeRuntime{name: "podman", output: func(command string) (string, error) {
+		return `{"envcli.managed":"true","envcli.readiness.http":"http://localhost:8080"}`, nil
+	}}
+	probe, err := ContainerReadinessProbe(context.Background(), runtime, "envcli-web")
+	if err != nil || probe == nil || probe.HTTPGet != "http://localhost:8080" {
+		t.Errorf("unexpected probe %+v (%v)", probe, err)
+	}
+	if runtime.commands[0] != `podman inspect --format "{{json .Config.Labels}}" envcli-web` {
+		t.Errorf("unexpected command %s", runtime.commands[0])
+	}
+
+	runtime.output = func(command string) (string, error) { return "null", nil }
+	if probe, err = ContainerReadinessProbe(context.Background(), runtime, "envcli-web"); probe != nil || err != nil {
+		t.Errorf("expected no probe, got %+v (%v)", probe, err)
+	}
+}
@@ -0,0 +1,70 @@
+package containerutil
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// finishedAtLayouts are the formats of the finish time of a container: docker prints RFC 3339, podman the go time format
+var finishedAtLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999 -0700 MST"}
+
+// RetainedLabel marks containers that have been kept after the run for debugging purposes
+const RetainedLabel = "envcli.retained"
+
+// RetainedLabelArgs returns the run arguments to mark a container as retained
+func RetainedLabelArgs() []string {
+	return []string{"--label", RetainedLabel + "=true"}
+}
+
+// ListRetainedContainers returns the ids of all retained containers
+func ListRetainedContainers(ctx context.Context, runtime ContainerRuntime) ([]string, error) {
+	output, err := runtime.Output(ctx, runtimeCommand(runtime, "ps", "-a", "-q", "--filter", "label="+RetainedLabel))
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return []string{}, nil
+	}
+
+	return strings.Fields(output), nil
+}
+
+// RemoveContainer force-removes the container with the given id or name
+func RemoveContainer(ctx context.Context, runtime ContainerRuntime, container string) error {
+	_, err := runtime.Output(ctx, runtimeCommand(runtime, "rm", "-f", container))
+	return err
+}
+
+// ListStoppedContainers returns the ids of the stopped containers started by envcli that finished before the given time, all stopped containers if the time is zero
+func ListStoppedContainers(ctx context.Context, runtime ContainerRuntime, finishedBefore time.Time) ([]string, error) {
+	output, err := runtime.Output(ctx, runtimeCommand(runtime, "ps", "-a", "-q", "--filter", "label="+ManagedLabel, "--filter", "status=exited"))
+	if err != nil {
+		return nil, err
+	}
+	containers := strings.Fields(output)
+	if finishedBefore.IsZero() || len(containers) == 0 {
+		return containers, nil
+	}
+
+	output, err = runtime.Output(ctx, append(runtimeCommand(runtime, "inspect", "--format", "{{.Id}} {{.State.FinishedAt}}"), containers...))
+	if err != nil {
+		return nil, err
+	}
+	var stale []string
+	for _, line := range strings.Split(output, "\n") {
+		id, finishedAt, found := strings.Cut(strings.TrimSpace(line), " ")
+		if !found {
+			continue
+		}
+		for _, layout := range finishedAtLayouts {
+			if finished, parseErr := time.Parse(layout, finishedAt); parseErr == nil {
+				if finished.Before(finishedBefore) {
+					stale = append(stale, id)
+				}
+				break
+			}
+		}
+	}
+	return stale, nil
+}
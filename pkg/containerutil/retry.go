@@ -0,0 +1,79 @@
+package containerutil
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultRetryDelay is used if retries are enabled without a delay
+const DefaultRetryDelay = 5 * time.Second
+
+// RetryPolicy defines how often a failed command is executed again
+type RetryPolicy struct {
+	// Retries is the number of additional attempts
+	Retries int
+
+	// Delay before the first retry, doubled for each following retry
+	Delay time.Duration
+
+	// ExitCodes restricts the retries to these exit codes, all non-zero exit codes are retried if empty
+	ExitCodes []int
+}
+
+// ExitCode returns the exit code of a failed command, 0 if err is nil and 1 if the command couldn't be started
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return 1
+}
+
+// ShouldRetry returns true if the failed attempt (starting at 1) should be retried, cancelled attempts are never retried
+func (p RetryPolicy) ShouldRetry(attempt int, err error) bool {
+	if err == nil || attempt > p.Retries || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if len(p.ExitCodes) == 0 {
+		return true
+	}
+
+	exitCode := ExitCode(err)
+	for _, code := range p.ExitCodes {
+		if code == exitCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff returns the delay after the failed attempt (starting at 1)
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.Delay
+	if delay <= 0 {
+		delay = DefaultRetryDelay
+	}
+	return delay * time.Duration(1<<uint(attempt-1))
+}
+
+// RunWithRetry runs the function and repeats it according to the policy, returns the error of the last attempt
+func RunWithRetry(policy RetryPolicy, run func(attempt int) error, sleep func(time.Duration)) error {
+	for attempt := 1; ; attempt++ {
+		err := run(attempt)
+		if !policy.ShouldRetry(attempt, err) {
+			return err
+		}
+
+		delay := policy.Backoff(attempt)
+		log.Warn().Int("attempt", attempt).Int("attempts", policy.Retries+1).Int("exitCode", ExitCode(err)).Str("delay", delay.String()).Msg("command failed, retrying")
+		sleep(delay)
+	}
+}
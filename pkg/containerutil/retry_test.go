@@ -0,0 +1,72 @@
+package containerutil
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// exitError returns a real exit error with the given exit code
+func exitError(t *testing.T, code string) error {
+	err := exec.Command("sh", "-c", "exit "+code).Run()
+	if err == nil {
+		t.Fatalf("expected a exit error")
+	}
+	return err
+}
+
+func TestRunWithRetry(t *testing.T) {
+	failure := exitError(t, "7")
+
+	var tests = []struct {
+		policy   RetryPolicy
+		failures int
+		attempts int
+		delays   []time.Duration
+		success  bool
+	}{
+		{RetryPolicy{}, 1, 1, nil, false},
+		{RetryPolicy{Retries: 3, Delay: time.Second}, 2, 3, []time.Duration{time.Second, 2 * time.Second}, true},
+		{RetryPolicy{Retries: 2, Delay: time.Second}, 5, 3, []time.Duration{time.Second, 2 * time.Second}, false},
+		{RetryPolicy{Retries: 2}, 1, 2, []time.Duration{DefaultRetryDelay}, true},
+		{RetryPolicy{Retries: 2, Delay: time.Second, ExitCodes: []int{1, 7}}, 1, 2, []time.Duration{time.Second}, true},
+		{RetryPolicy{Retries: 2, Delay: time.Second, ExitCodes: []int{1}}, 1, 1, nil, false},
+	}
+
+	for i, test := range tests {
+		attempts := 0
+		var delays []time.Duration
+		err := RunWithRetry(test.policy, func(attempt int) error {
+			attempts++
+			if attempt <= test.failures {
+				return failure
+			}
+			return nil
+		}, func(d time.Duration) { delays = append(delays, d) })
+
+		if attempts != test.attempts || (err == nil) != test.success || len(delays) != len(test.delays) {
+			t.Errorf("%d: unexpected result, attempts %d, delays %v, err %v", i, attempts, delays, err)
+			continue
+		}
+		for j := range delays {
+			if delays[j] != test.delays[j] {
+				t.Errorf("%d: expected delays %v, got %v", i, test.delays, delays)
+			}
+		}
+	}
+}
+
+func TestShouldRetryCancelled(t *testing.T) {
+	policy := RetryPolicy{Retries: 3}
+	if policy.ShouldRetry(1, fmt.Errorf("%w: signal: killed", context.Canceled)) || policy.ShouldRetry(1, context.DeadlineExceeded) {
+		t.Errorf("expected cancelled attempts not to be retried")
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	if ExitCode(nil) != 0 || ExitCode(exitError(t, "3")) != 3 || ExitCode(exec.Command("/nonexistent/binary").Run()) != 1 {
+		t.Errorf("unexpected exit codes")
+	}
+}
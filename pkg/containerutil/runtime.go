@@ -0,0 +1,205 @@
+package containerutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/cidverse/cidverseutils/pkg/containerruntime"
+	"github.com/rs/zerolog/log"
+)
+
+// ContainerRuntime executes the commands of a container runtime (podman, docker, nerdctl)
+type ContainerRuntime interface {
+	// Name returns the name of the runtime used in the commands, unknown if no runtime is available
+	Name() string
+
+	// Exec runs the command (the executable followed by its arguments) and attaches the provided input and output
+	Exec(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error
+
+	// Output runs the command (the executable followed by its arguments) and returns the trimmed stdout
+	Output(ctx context.Context, args []string) (string, error)
+}
+
+// hostRuntime executes the commands on the host, without a shell
+type hostRuntime struct {
+	name string
+
+	// err explains why no runtime is available, returned by RequireRuntime
+	err error
+
+	// env is added to the environment of the runtime client, see WithEnvironment
+	env []string
+}
+
+func (r hostRuntime) Name() string {
+	return r.name
+}
+
+func (r hostRuntime) Exec(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	return ExecArgsWithEnv(ctx, args, r.env, stdin, stdout, stderr)
+}
+
+func (r hostRuntime) Output(ctx context.Context, args []string) (string, error) {
+	return ExecArgsOutputWithEnv(ctx, args, r.env)
+}
+
+func (r hostRuntime) WithEnvironment(env []string) ContainerRuntime {
+	r.env = append(append([]string{}, r.env...), env...)
+	return r
+}
+
+// runtimeCommand returns the arguments of a runtime client command, ex. docker image inspect alpine
+func runtimeCommand(runtime ContainerRuntime, args ...string) []string {
+	return append([]string{runtime.Name()}, args...)
+}
+
+// RuntimeProvider is a container runtime supported by envcli
+type RuntimeProvider struct {
+	// Name of the runtime, also used as the command
+	Name string
+
+	// Detect returns true if the runtime is available on the host
+	Detect func() bool
+}
+
+// Runtimes are the supported container runtimes, in the order they are detected if no runtime is configured
+var Runtimes = []RuntimeProvider{
+	{Name: "podman", Detect: containerruntime.IsPodman},
+	{Name: "docker", Detect: containerruntime.IsDockerNative},
+	{Name: "nerdctl", Detect: IsNerdctl},
+}
+
+// RuntimeNames returns the names of the supported container runtimes, in detection order
+func RuntimeNames() []string {
+	var names []string
+	for _, provider := range Runtimes {
+		names = append(names, provider.Name)
+	}
+	return names
+}
+
+// DetectRuntimes returns the names of all container runtimes available on the host, in detection order
+func DetectRuntimes() []string {
+	var names []string
+	for _, provider := range Runtimes {
+		if provider.Detect() {
+			names = append(names, provider.Name)
+		}
+	}
+	return names
+}
+
+// DetectRuntime returns the preferred container runtime, or the first available runtime of the host if no runtime is preferred.
+// If the preferred runtime is unknown or not available, RequireRuntime reports it.
+func DetectRuntime(preferred string) ContainerRuntime {
+	if preferred != "" {
+		for _, provider := range Runtimes {
+			if provider.Name != preferred {
+				continue
+			}
+			if !provider.Detect() {
+				return hostRuntime{name: "unknown", err: exitcode.New(exitcode.RuntimeUnavailable, errors.New("the configured container runtime "+preferred+" is not available"))}
+			}
+			return hostRuntime{name: preferred}
+		}
+		return hostRuntime{name: "unknown", err: exitcode.New(exitcode.ConfigError, errors.New("unknown container runtime "+preferred+", supported: "+strings.Join(RuntimeNames(), ", ")))}
+	}
+
+	for _, provider := range Runtimes {
+		if provider.Detect() {
+			return hostRuntime{name: provider.Name}
+		}
+	}
+	return hostRuntime{name: "unknown"}
+}
+
+// RequireRuntime returns a error if no supported container runtime has been detected
+func RequireRuntime(runtime ContainerRuntime) error {
+	if host, ok := runtime.(hostRuntime); ok && host.err != nil {
+		return host.err
+	}
+	if runtime.Name() == "" || runtime.Name() == "unknown" {
+		return exitcode.New(exitcode.RuntimeUnavailable, errors.New("no supported container runtime found ("+strings.Join(RuntimeNames(), ", ")+")"))
+	}
+	if _, ok := runtime.(hostRuntime); ok && runtime.Name() == "docker" {
+		if err := CheckDockerHost(goruntime.GOOS); err != nil {
+			return exitcode.New(exitcode.RuntimeUnavailable, err)
+		}
+	}
+	return nil
+}
+
+// RuntimeVersion returns the client version of the container runtime
+func RuntimeVersion(ctx context.Context, runtime ContainerRuntime) (string, error) {
+	return runtime.Output(ctx, runtimeCommand(runtime, "version", "--format", "{{.Client.Version}}"))
+}
+
+// ErrRateLimited is returned by PullImage if the registry rejected the pull because of its rate limit (ex. anonymous pulls from docker hub)
+var ErrRateLimited = errors.New("the registry rate limit has been reached (toomanyrequests)")
+
+// rateLimitGuidance explains how to avoid the rate limit of the registry
+const rateLimitGuidance = "log in to the registry to raise the limit (ex. docker login), or configure a registry mirror using envcli config set registry-mirrors docker.io=mirror.company.com"
+
+// IsRateLimited returns true if the pull output contains the rate limit error of the registry
+func IsRateLimited(output string) bool {
+	return strings.Contains(strings.ToLower(output), "toomanyrequests")
+}
+
+// PullImage pulls the image from the registry, the pull progress is discarded and the errors are written to stderr
+func PullImage(ctx context.Context, runtime ContainerRuntime, image string) error {
+	var stderr bytes.Buffer
+	if err := runtime.Exec(ctx, runtimeCommand(runtime, "pull", image), nil, io.Discard, io.MultiWriter(os.Stderr, &stderr)); err != nil {
+		if IsRateLimited(stderr.String()) || IsRateLimited(err.Error()) {
+			return exitcode.New(exitcode.ImagePullFailure, fmt.Errorf("failed to pull image %s: %w - %s", image, ErrRateLimited, rateLimitGuidance))
+		}
+		if IsOutOfSpace(stderr.String()) {
+			return exitcode.New(exitcode.ImagePullFailure, fmt.Errorf("failed to pull image %s: %w - %s", image, ErrOutOfSpace, outOfSpaceGuidance))
+		}
+		return exitcode.New(exitcode.ImagePullFailure, fmt.Errorf("failed to pull image %s: %w", image, err))
+	}
+	return nil
+}
+
+// ImageExists returns true if the image is present in the local image store
+func ImageExists(ctx context.Context, runtime ContainerRuntime, image string) bool {
+	_, err := runtime.Output(ctx, runtimeCommand(runtime, "image", "inspect", image))
+	return err == nil
+}
+
+// EnsureImage pulls the image if it isn't present in the local image store
+func EnsureImage(ctx context.Context, runtime ContainerRuntime, image string) error {
+	if ImageExists(ctx, runtime, image) {
+		return nil
+	}
+
+	log.Info().Str("image", image).Msg("image not found locally, pulling it")
+	return PullImage(ctx, runtime, image)
+}
+
+// BuildImage builds the image from the dockerfile, the build output is written to the output writer
+func BuildImage(ctx context.Context, runtime ContainerRuntime, image string, dockerfile string, contextDir string, output io.Writer) error {
+	command := runtimeCommand(runtime, "build", "-t", image, "--label", ManagedLabel+"=true", "-f", dockerfile, contextDir)
+	if err := runtime.Exec(ctx, command, nil, output, output); err != nil {
+		return exitcode.New(exitcode.ImagePullFailure, fmt.Errorf("failed to build image %s from %s: %w", image, dockerfile, err))
+	}
+	return nil
+}
+
+// EnsureBuiltImage builds the image if it isn't present in the local image store or rebuild is set
+func EnsureBuiltImage(ctx context.Context, runtime ContainerRuntime, image string, dockerfile string, contextDir string, rebuild bool, output io.Writer) error {
+	if !rebuild {
+		if ImageExists(ctx, runtime, image) {
+			return nil
+		}
+	}
+
+	log.Info().Str("image", image).Str("dockerfile", dockerfile).Msg("building image")
+	return BuildImage(ctx, runtime, image, dockerfile, contextDir, output)
+}
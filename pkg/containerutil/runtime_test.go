@@ -0,0 +1,111 @@
+package containerutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+)
+
+// fakeRuntime records the commands and answers them using output
+type fakeRuntime struct {
+	name     string
+	commands []string
+	output   func(command string) (string, error)
+
+	// errOutput is written to stderr by Exec, if set
+	errOutput func(command string) string
+}
+
+func (r *fakeRuntime) Name() string {
+	return r.name
+}
+
+func (r *fakeRuntime) Exec(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	_, err := r.Output(ctx, args)
+	if r.errOutput != nil && stderr != nil {
+		_, _ = io.WriteString(stderr, r.errOutput(FormatCommand(args)))
+	}
+	return err
+}
+
+func (r *fakeRuntime) Output(ctx context.Context, args []string) (string, error) {
+	command := FormatCommand(args)
+	r.commands = append(r.commands, command)
+	return r.output(command)
+}
+
+func TestRequireRuntime(t *testing.T) {
+	if exitcode.Of(RequireRuntime(&fakeRuntime{name: "unknown"})) != exitcode.RuntimeUnavailable {
+		t.Errorf("expected the runtime unavailable exit code")
+	}
+	if RequireRuntime(&fakeRuntime{name: "podman"}) != nil {
+		t.Errorf("expected no error for podman")
+	}
+}
+
+func TestDetectRuntime(t *testing.T) {
+	previous := Runtimes
+	t.Cleanup(func() { Runtimes = previous })
+	Runtimes = []RuntimeProvider{
+		{Name: "podman", Detect: func() bool { return false }},
+		{Name: "docker", Detect: func() bool { return true }},
+	}
+
+	if name := DetectRuntime("").Name(); name != "docker" {
+		t.Errorf("expected the first available runtime, got %s", name)
+	}
+	if detected := DetectRuntimes(); strings.Join(detected, ",") != "docker" {
+		t.Errorf("unexpected detected runtimes %v", detected)
+	}
+	if runtime := DetectRuntime("docker"); runtime.Name() != "docker" || RequireRuntime(runtime) != nil {
+		t.Errorf("expected the preferred runtime, got %s", runtime.Name())
+	}
+	if err := RequireRuntime(DetectRuntime("podman")); exitcode.Of(err) != exitcode.RuntimeUnavailable {
+		t.Errorf("expected the runtime unavailable exit code for a missing preferred runtime, got %v", err)
+	}
+	if err := RequireRuntime(DetectRuntime("rkt")); exitcode.Of(err) != exitcode.ConfigError {
+		t.Errorf("expected the config error exit code for an unknown runtime, got %v", err)
+	}
+}
+
+func TestEnsureImage(t *testing.T) {
+	runtime := &fakeRuntime{name: "docker", output: func(command string) (string, error) {
+		return "", errors.New("exit status 1")
+	}}
+	if err := EnsureImage(context.Background(), runtime, "alpine:missing"); exitcode.Of(err) != exitcode.ImagePullFailure {
+		t.Errorf("expected the image pull failure exit code, got %v", err)
+	}
+	if strings.Join(runtime.commands, "|") != "docker image inspect alpine:missing|docker pull alpine:missing" {
+		t.Errorf("unexpected commands %v", runtime.commands)
+	}
+
+	runtime = &fakeRuntime{name: "docker", output: func(command string) (string, error) { return "[]", nil }}
+	if err := EnsureImage(context.Background(), runtime, "alpine"); err != nil {
+		t.Errorf("expected a present image to be used, got %v", err)
+	}
+	if len(runtime.commands) != 1 {
+		t.Errorf("expected only the inspect command, got %v", runtime.commands)
+	}
+}
+
+func TestPullImageRateLimited(t *testing.T) {
+	runtime := &fakeRuntime{name: "docker", output: func(command string) (string, error) {
+		return "", errors.New("toomanyrequests: You have reached your pull rate limit")
+	}}
+	err := PullImage(context.Background(), runtime, "alpine")
+	if !errors.Is(err, ErrRateLimited) || exitcode.Of(err) != exitcode.ImagePullFailure {
+		t.Fatalf("expected the rate limit error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "docker login") || !strings.Contains(err.Error(), "registry-mirrors") {
+		t.Errorf("expected guidance within the error, got %v", err)
+	}
+
+	runtime = &fakeRuntime{name: "docker", output: func(command string) (string, error) { return "", errors.New("exit status 1") }}
+	if err = PullImage(context.Background(), runtime, "alpine"); errors.Is(err, ErrRateLimited) {
+		t.Errorf("expected a plain pull failure, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package containerutil
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+)
+
+// SupportedShells contains all shells that can be used to wrap the command within the container
+var SupportedShells = []string{"sh", "bash", "ash", "zsh", "powershell", "cmd", "none"}
+
+// ValidateShell returns an error if the shell isn't supported, an empty shell is treated as none
+func ValidateShell(shell string) error {
+	if shell == "" {
+		return nil
+	}
+	for _, supported := range SupportedShells {
+		if shell == supported {
+			return nil
+		}
+	}
+
+	return errors.New("unknown shell " + shell + ", allowed: " + strings.Join(SupportedShells, ", "))
+}
+
+// WrapShellCommand wraps the command line into the shell within the container and returns the arguments of the container command.
+// A login shell loads the profile scripts, bash is always started as login shell. Without a shell the command line is split into its arguments.
+func WrapShellCommand(shell string, login bool, command string) ([]string, error) {
+	if err := ValidateShell(shell); err != nil {
+		return nil, err
+	}
+
+	switch shell {
+	case "sh", "bash", "ash", "zsh":
+		args := []string{"/usr/bin/env", shell}
+		if login || shell == "bash" {
+			args = append(args, "-l")
+		}
+		return append(args, "-c", command), nil
+	case "powershell":
+		// powershell always loads the profile
+		return []string{"powershell", command}, nil
+	case "cmd":
+		return []string{"cmd", "/c", command}, nil
+	}
+
+	return common.SplitArgs(command), nil
+}
+
+// ShellJoin double quotes the arguments for the shell within the container, variables ($HOME) are still expanded by the shell
+func ShellJoin(args []string) string {
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, `"`+escape.Replace(arg)+`"`)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,49 @@
+package containerutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrapShellCommand(t *testing.T) {
+	var tests = []struct {
+		shell    string
+		login    bool
+		command  string
+		expected []string
+	}{
+		{"", false, "go build", []string{"go", "build"}},
+		{"none", true, `go build -ldflags="-w -s"`, []string{"go", "build", "-ldflags=-w -s"}},
+		{"sh", false, `echo "hi"`, []string{"/usr/bin/env", "sh", "-c", `echo "hi"`}},
+		{"sh", true, "mvn -v", []string{"/usr/bin/env", "sh", "-l", "-c", "mvn -v"}},
+		{"bash", false, "mvn -v", []string{"/usr/bin/env", "bash", "-l", "-c", "mvn -v"}},
+		{"zsh", true, "node -v", []string{"/usr/bin/env", "zsh", "-l", "-c", "node -v"}},
+		{"powershell", true, "dir", []string{"powershell", "dir"}},
+		{"cmd", true, "dir", []string{"cmd", "/c", "dir"}},
+	}
+
+	for _, test := range tests {
+		result, err := WrapShellCommand(test.shell, test.login, test.command)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.shell, err)
+		}
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("%s: expected %q, got %q", test.shell, test.expected, result)
+		}
+	}
+}
+
+func TestShellJoin(t *testing.T) {
+	if command := ShellJoin([]string{"go", "build", `-ldflags=-X "main.Version=1"`, `C:\tmp`}); command != `"go" "build" "-ldflags=-X \"main.Version=1\"" "C:\\tmp"` {
+		t.Errorf("unexpected command %s", command)
+	}
+}
+
+func TestValidateShell(t *testing.T) {
+	if err := ValidateShell("bsh"); err == nil {
+		t.Errorf("expected an error for an unknown shell")
+	}
+	if _, err := WrapShellCommand("bahs", false, "ls"); err == nil {
+		t.Errorf("expected an error for an unknown shell")
+	}
+}
@@ -0,0 +1,46 @@
+package containerutil
+
+import (
+	"errors"
+	"os"
+)
+
+// dockerDesktopSSHAgentSocket is the ssh agent socket provided by Docker Desktop for Mac inside the VM
+const dockerDesktopSSHAgentSocket = "/run/host-services/ssh-auth.sock"
+
+// containerSSHAgentSocket is the path the host ssh agent socket is mounted to on linux
+const containerSSHAgentSocket = "/run/ssh-agent.sock"
+
+// SSHAgentSocket holds the host socket and the path it should be mounted to inside the container
+type SSHAgentSocket struct {
+	Source string
+	Target string
+}
+
+// ResolveSSHAgentSocket determines the ssh agent socket that should be mounted into containers on the given platform
+func ResolveSSHAgentSocket(goos string, getenv func(string) string, exists func(string) bool) (SSHAgentSocket, error) {
+	if goos == "windows" {
+		return SSHAgentSocket{}, errors.New("ssh agent forwarding is not supported on windows, named-pipe agents can't be mounted into containers")
+	}
+
+	if goos == "darwin" {
+		// docker desktop exposes the host agent at a fixed path within its vm
+		return SSHAgentSocket{Source: dockerDesktopSSHAgentSocket, Target: dockerDesktopSSHAgentSocket}, nil
+	}
+
+	socket := getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return SSHAgentSocket{}, errors.New("no ssh agent found, SSH_AUTH_SOCK is not set")
+	}
+	if !exists(socket) {
+		return SSHAgentSocket{}, errors.New("no ssh agent found, SSH_AUTH_SOCK points to the missing socket " + socket)
+	}
+
+	return SSHAgentSocket{Source: socket, Target: containerSSHAgentSocket}, nil
+}
+
+// FileExists returns true if the file exists
+func FileExists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
@@ -0,0 +1,38 @@
+package containerutil
+
+import (
+	"testing"
+)
+
+func TestResolveSSHAgentSocket(t *testing.T) {
+	env := func(value string) func(string) string {
+		return func(string) string { return value }
+	}
+	exists := func(result bool) func(string) bool {
+		return func(string) bool { return result }
+	}
+
+	var tests = []struct {
+		goos     string
+		getenv   func(string) string
+		exists   func(string) bool
+		expected SSHAgentSocket
+		err      bool
+	}{
+		{"linux", env("/tmp/ssh-agent.sock"), exists(true), SSHAgentSocket{Source: "/tmp/ssh-agent.sock", Target: containerSSHAgentSocket}, false},
+		{"linux", env(""), exists(true), SSHAgentSocket{}, true},
+		{"linux", env("/tmp/missing.sock"), exists(false), SSHAgentSocket{}, true},
+		{"darwin", env(""), exists(false), SSHAgentSocket{Source: dockerDesktopSSHAgentSocket, Target: dockerDesktopSSHAgentSocket}, false},
+		{"windows", env("\\\\.\\pipe\\openssh-ssh-agent"), exists(true), SSHAgentSocket{}, true},
+	}
+
+	for _, test := range tests {
+		socket, err := ResolveSSHAgentSocket(test.goos, test.getenv, test.exists)
+		if (err != nil) != test.err {
+			t.Errorf("%s: unexpected error state %v", test.goos, err)
+		}
+		if socket != test.expected {
+			t.Errorf("%s: expected %+v, got %+v", test.goos, test.expected, socket)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Downloader downloads files into a cache directory, verifies checksums and resumes interrupted downloads
+type Downloader struct {
+	// CacheDir is the directory used to store the downloaded files
+	CacheDir string
+
+	// Client is the http client used for all requests, it respects the proxy environment variables
+	Client *http.Client
+
+	// Retries is the number of additional attempts after transient errors (network errors, 429 and 5xx), each attempt resumes the download
+	Retries int
+
+	// Backoff is the delay before the first retry, it doubles for each further retry (defaults to 1s)
+	Backoff time.Duration
+
+	// Progress is called whenever data has been received, if set
+	Progress func(progress Progress)
+}
+
+// Progress is the state of a download
+type Progress struct {
+	URL string
+
+	// Received is the size of the file received so far, including the resumed part
+	Received int64
+
+	// Total is the size of the file, 0 if the server didn't report it
+	Total int64
+
+	// Resumed is the size of the part that has been received by a previous attempt
+	Resumed int64
+
+	// Started is the start of the current attempt
+	Started time.Time
+}
+
+// ETA estimates the remaining time from the rate of the current attempt, 0 if unknown
+func (p Progress) ETA() time.Duration {
+	transferred := p.Received - p.Resumed
+	elapsed := time.Since(p.Started)
+	if p.Total <= 0 || transferred <= 0 || elapsed <= 0 {
+		return 0
+	}
+	return time.Duration(float64(p.Total-p.Received) / float64(transferred) * float64(elapsed))
+}
+
+// partMeta is stored next to the partial file, so that a resumed download continues the same file
+type partMeta struct {
+	URL      string `json:"url"`
+	Size     int64  `json:"size,omitempty"`
+	ETag     string `json:"etag,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// statusError is returned for unexpected http status codes
+type statusError struct {
+	url    string
+	status string
+	code   int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("download of %s failed with status %s", e.url, e.status)
+}
+
+// isTransient returns true for errors that may succeed if retried: network errors, 429 and 5xx
+func isTransient(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= 500
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// New creates a downloader storing its files within the cache directory
+func New(cacheDir string) *Downloader {
+	return &Downloader{
+		CacheDir: cacheDir,
+		Client:   &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}},
+	}
+}
+
+// CacheFile returns the path of the cached file for the url
+func (d *Downloader) CacheFile(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	return filepath.Join(d.CacheDir, hex.EncodeToString(hash[:]))
+}
+
+// Download downloads the url into the cache and returns the path of the local file.
+// If a checksum (sha256, hex) is provided, a valid cached file will be reused and corrupted files are downloaded again.
+// Without a checksum the file is requested using the ETag of the cached copy, which is kept if it is still up to date or the download fails.
+func (d *Downloader) Download(ctx context.Context, url string, checksum string) (string, error) {
+	checksum = strings.ToLower(strings.TrimPrefix(checksum, "sha256:"))
+	file := d.CacheFile(url)
+
+	if err := os.MkdirAll(d.CacheDir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	// reuse cached file, if the checksum matches
+	if checksum != "" && FileExists(file) {
+		if err := VerifyChecksum(file, checksum); err == nil {
+			log.Debug().Str("url", url).Str("file", file).Msg("using cached download")
+			return file, nil
+		}
+		log.Warn().Str("url", url).Str("file", file).Msg("cached download is corrupted, downloading it again")
+		_ = os.Remove(file)
+	}
+
+	etag := ""
+	if checksum == "" && FileExists(file) && !FileExists(file+".part") {
+		content, _ := os.ReadFile(file + ".etag")
+		etag = strings.TrimSpace(string(content))
+	}
+
+	newETag, err := d.fetchWithRetries(ctx, url, file+".part", etag, checksum)
+	if errors.Is(err, errNotModified) {
+		log.Debug().Str("url", url).Str("file", file).Msg("cached download is up to date")
+		return file, nil
+	}
+	if err != nil {
+		if checksum == "" && FileExists(file) && ctx.Err() == nil {
+			log.Warn().Err(err).Str("url", url).Msg("download failed, using the previously cached file")
+			return file, nil
+		}
+		return "", err
+	}
+
+	if checksum != "" {
+		if verifyErr := VerifyChecksum(file+".part", checksum); verifyErr != nil {
+			_ = os.Remove(file + ".part")
+			_ = os.Remove(file + ".part.json")
+			return "", verifyErr
+		}
+	}
+	_ = os.Remove(file + ".part.json")
+
+	if err = os.Rename(file+".part", file); err != nil {
+		return "", err
+	}
+	if newETag != "" {
+		_ = os.WriteFile(file+".etag", []byte(newETag+"\n"), 0644)
+	} else {
+		_ = os.Remove(file + ".etag")
+	}
+
+	log.Debug().Str("url", url).Str("file", file).Msg("download completed")
+	return file, nil
+}
+
+// errNotModified is returned by fetch if the server confirmed that the cached copy matching the etag is up to date
+var errNotModified = errors.New("not modified")
+
+// fetchWithRetries calls fetch until it succeeds, retrying transient errors with exponential backoff
+func (d *Downloader) fetchWithRetries(ctx context.Context, url string, partFile string, etag string, checksum string) (string, error) {
+	backoff := d.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	for attempt := 0; ; attempt++ {
+		newETag, err := d.fetch(ctx, url, partFile, etag, checksum)
+		if err == nil || errors.Is(err, errNotModified) || attempt >= d.Retries || !isTransient(err) || ctx.Err() != nil {
+			return newETag, err
+		}
+
+		delay := backoff << attempt
+		log.Warn().Err(err).Str("url", url).Int("attempt", attempt+1).Int("retries", d.Retries).Str("delay", delay.String()).Msg("download interrupted, resuming it")
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// fetch downloads the url into the partial file, resuming the download if the partial file already exists.
+// The expected size, etag and checksum are stored next to the partial file, a partial file of a different checksum is discarded and If-Range restarts the download if the file changed on the server.
+// If a etag is provided the request is conditional, errNotModified is returned if the etag still matches. Returns the etag of the response.
+func (d *Downloader) fetch(ctx context.Context, url string, partFile string, etag string, checksum string) (string, error) {
+	metaFile := partFile + ".json"
+	meta := partMeta{URL: url, Checksum: checksum}
+	var offset int64
+	if info, err := os.Stat(partFile); err == nil {
+		offset = info.Size()
+		if previous, found := readPartMeta(metaFile); found {
+			if previous.URL != url || previous.Checksum != checksum {
+				log.Debug().Str("url", url).Msg("discarding the partial download of a different file")
+				offset = 0
+			} else {
+				meta = previous
+			}
+		}
+	}
+	if offset > 0 && meta.Size > 0 && offset == meta.Size {
+		// the partial file is already complete
+		return meta.ETag, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	if offset > 0 {
+		log.Debug().Str("url", url).Int64("offset", offset).Msg("resuming download")
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if meta.ETag != "" && !strings.HasPrefix(meta.ETag, "W/") {
+			req.Header.Set("If-Range", meta.ETag)
+		}
+	} else if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusOK:
+		flags |= os.O_TRUNC
+		offset = 0
+		meta = partMeta{URL: url, Size: resp.ContentLength, ETag: resp.Header.Get("ETag"), Checksum: checksum}
+		if meta.Size < 0 {
+			meta.Size = 0
+		}
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+		if total := contentRangeTotal(resp.Header.Get("Content-Range")); total > 0 {
+			meta.Size = total
+		}
+	case http.StatusNotModified:
+		return etag, errNotModified
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the partial file is already complete
+		return meta.ETag, nil
+	default:
+		return "", &statusError{url: url, status: resp.Status, code: resp.StatusCode}
+	}
+	if err = writePartMeta(metaFile, meta); err != nil {
+		return "", err
+	}
+
+	out, err := os.OpenFile(partFile, flags, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	var body io.Reader = resp.Body
+	if d.Progress != nil {
+		body = &progressReader{reader: resp.Body, report: d.Progress, progress: Progress{URL: url, Received: offset, Total: meta.Size, Resumed: offset, Started: time.Now()}}
+	}
+	written, err := io.Copy(out, body)
+	if err != nil {
+		return "", err
+	}
+	if meta.Size > 0 && offset+written != meta.Size {
+		return "", fmt.Errorf("download of %s ended after %d of %d bytes: %w", url, offset+written, meta.Size, io.ErrUnexpectedEOF)
+	}
+	return meta.ETag, nil
+}
+
+// contentRangeTotal returns the total size of a Content-Range header (ex. bytes 100-199/200), 0 if it is unknown
+func contentRangeTotal(contentRange string) int64 {
+	_, total, found := strings.Cut(contentRange, "/")
+	if !found {
+		return 0
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+func readPartMeta(file string) (partMeta, bool) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return partMeta{}, false
+	}
+	var meta partMeta
+	if err = json.Unmarshal(content, &meta); err != nil {
+		return partMeta{}, false
+	}
+	return meta, true
+}
+
+func writePartMeta(file string, meta partMeta) error {
+	content, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, content, 0644)
+}
+
+// progressReader reports the received data of a download
+type progressReader struct {
+	reader   io.Reader
+	report   func(progress Progress)
+	progress Progress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.progress.Received += int64(n)
+		r.report(r.progress)
+	}
+	return n, err
+}
+
+// VerifyChecksum checks that the sha256 checksum of the file matches the expected checksum
+func VerifyChecksum(file string, expected string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, f); err != nil {
+		return err
+	}
+
+	actual := hex.EncodeToString(hash.Sum(nil))
+	if !strings.EqualFold(actual, expected) {
+		return errors.New("checksum mismatch for " + file + ", expected " + expected + " but got " + actual)
+	}
+	return nil
+}
+
+// FileExists returns true if the file exists
+func FileExists(file string) bool {
+	_, err := os.Stat(file)
+	return err == nil
+}
@@ -0,0 +1,271 @@
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const content = "images:\n- name: go\n  image: golang:1.20\n"
+
+func checksumOf(data string) string {
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])
+}
+
+func newServer(t *testing.T, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		http.ServeContent(w, r, "config.yml", time.Time{}, strings.NewReader(content))
+	}))
+}
+
+func TestDownloadVerifiesChecksum(t *testing.T) {
+	requests := 0
+	server := newServer(t, &requests)
+	defer server.Close()
+	d := New(t.TempDir())
+
+	file, err := d.Download(context.Background(), server.URL, checksumOf(content))
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	data, _ := os.ReadFile(file)
+	if string(data) != content {
+		t.Errorf("unexpected content %q", string(data))
+	}
+
+	// cached
+	if _, err = d.Download(context.Background(), server.URL, checksumOf(content)); err != nil || requests != 1 {
+		t.Errorf("expected cached file to be reused, requests: %d, err: %v", requests, err)
+	}
+
+	// mismatch
+	if _, err = New(t.TempDir()).Download(context.Background(), server.URL, checksumOf("other")); err == nil {
+		t.Errorf("expected checksum mismatch")
+	}
+}
+
+func TestDownloadReplacesCorruptedCache(t *testing.T) {
+	requests := 0
+	server := newServer(t, &requests)
+	defer server.Close()
+	d := New(t.TempDir())
+
+	_ = os.WriteFile(d.CacheFile(server.URL), []byte("corrupted"), 0644)
+
+	file, err := d.Download(context.Background(), server.URL, checksumOf(content))
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	data, _ := os.ReadFile(file)
+	if string(data) != content || requests != 1 {
+		t.Errorf("expected corrupted file to be downloaded again")
+	}
+}
+
+func TestDownloadResumes(t *testing.T) {
+	requests := 0
+	server := newServer(t, &requests)
+	defer server.Close()
+	d := New(t.TempDir())
+
+	// partial download of the first 10 bytes
+	_ = os.WriteFile(d.CacheFile(server.URL)+".part", []byte(content[:10]), 0644)
+
+	file, err := d.Download(context.Background(), server.URL, checksumOf(content))
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	data, _ := os.ReadFile(file)
+	if string(data) != content {
+		t.Errorf("resumed download has unexpected content %q", string(data))
+	}
+}
+
+func TestDownloadFallsBackToCache(t *testing.T) {
+	requests := 0
+	server := newServer(t, &requests)
+	d := New(t.TempDir())
+
+	if _, err := d.Download(context.Background(), server.URL, ""); err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	server.Close()
+
+	if _, err := d.Download(context.Background(), server.URL, ""); err != nil {
+		t.Errorf("expected cached file to be used when offline, got %v", err)
+	}
+}
+
+func TestDownloadCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1024")
+		_, _ = w.Write([]byte(content))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	d := New(t.TempDir())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	started := time.Now()
+	if _, err := d.Download(ctx, server.URL, ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled error, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("expected the download to stop promptly, took %s", elapsed)
+	}
+
+	// the partial file is kept to resume the download
+	if FileExists(d.CacheFile(server.URL)) || !FileExists(d.CacheFile(server.URL)+".part") {
+		t.Errorf("expected only the partial file to exist")
+	}
+}
+
+func TestDownloadETag(t *testing.T) {
+	version, transferred := "v1", 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"`+version+`"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		transferred++
+		w.Header().Set("ETag", `"`+version+`"`)
+		_, _ = w.Write([]byte(content + "# " + version + "\n"))
+	}))
+	defer server.Close()
+	d := New(t.TempDir())
+
+	for i := 0; i < 2; i++ {
+		if _, err := d.Download(context.Background(), server.URL, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if transferred != 1 {
+		t.Errorf("expected the unchanged file to be transferred once, got %d", transferred)
+	}
+
+	version = "v2"
+	file, err := d.Download(context.Background(), server.URL, "")
+	data, _ := os.ReadFile(file)
+	if err != nil || transferred != 2 || !strings.HasSuffix(string(data), "# v2\n") {
+		t.Errorf("expected the changed file to be downloaded, got %q (%v)", string(data), err)
+	}
+}
+
+func TestDownloadRetriesInterruptedDownload(t *testing.T) {
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		switch len(ranges) {
+		case 1:
+			// the connection drops after the first 10 bytes
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = w.Write([]byte(content[:10]))
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			http.ServeContent(w, r, "config.yml", time.Time{}, strings.NewReader(content))
+		}
+	}))
+	defer server.Close()
+	d := New(t.TempDir())
+	d.Retries, d.Backoff = 3, time.Millisecond
+	var progress []Progress
+	d.Progress = func(p Progress) { progress = append(progress, p) }
+
+	file, err := d.Download(context.Background(), server.URL, checksumOf(content))
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	data, _ := os.ReadFile(file)
+	if string(data) != content {
+		t.Errorf("resumed download has unexpected content %q", string(data))
+	}
+	if len(ranges) != 3 || ranges[0] != "" || ranges[2] != "bytes=10-" {
+		t.Errorf("expected the download to be resumed after the interruption, got the ranges %q", ranges)
+	}
+	if last := progress[len(progress)-1]; last.Received != int64(len(content)) || last.Total != int64(len(content)) || last.Resumed != 10 {
+		t.Errorf("unexpected progress %+v", last)
+	}
+	if FileExists(d.CacheFile(server.URL) + ".part.json") {
+		t.Errorf("expected the metadata of the partial file to be removed")
+	}
+}
+
+func TestDownloadDoesNotRetryClientErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	d := New(t.TempDir())
+	d.Retries, d.Backoff = 3, time.Millisecond
+
+	if _, err := d.Download(context.Background(), server.URL, ""); err == nil || requests != 1 {
+		t.Errorf("expected a single failed request, got %d requests (%v)", requests, err)
+	}
+}
+
+func TestDownloadDiscardsPartOfDifferentFile(t *testing.T) {
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		http.ServeContent(w, r, "config.yml", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+	d := New(t.TempDir())
+
+	// partial download of a different checksum
+	part := d.CacheFile(server.URL) + ".part"
+	_ = os.WriteFile(part, []byte("corrupted"), 0644)
+	_ = os.WriteFile(part+".json", []byte(`{"url":"`+server.URL+`","size":100,"checksum":"`+checksumOf("other")+`"}`), 0644)
+
+	file, err := d.Download(context.Background(), server.URL, checksumOf(content))
+	data, _ := os.ReadFile(file)
+	if err != nil || string(data) != content || len(ranges) != 1 || ranges[0] != "" {
+		t.Errorf("expected the partial file to be discarded, got %q %q (%v)", string(data), ranges, err)
+	}
+}
+
+func TestDownloadRestartsChangedFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v2"`)
+		http.ServeContent(w, r, "config.yml", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+	d := New(t.TempDir())
+
+	// the partial file belongs to the previous version of the file, If-Range makes the server send the whole file
+	part := d.CacheFile(server.URL) + ".part"
+	_ = os.WriteFile(part, []byte("# v1 ..."), 0644)
+	_ = os.WriteFile(part+".json", []byte(`{"url":"`+server.URL+`","size":100,"etag":"\"v1\""}`), 0644)
+
+	file, err := d.Download(context.Background(), server.URL, "")
+	data, _ := os.ReadFile(file)
+	if err != nil || string(data) != content {
+		t.Errorf("expected the changed file to be downloaded again, got %q (%v)", string(data), err)
+	}
+}
+
+func TestProgressETA(t *testing.T) {
+	progress := Progress{Received: 40, Resumed: 20, Total: 100, Started: time.Now().Add(-2 * time.Second)}
+	if eta := progress.ETA(); eta < 5*time.Second || eta > 7*time.Second {
+		t.Errorf("expected a eta of 6s, got %s", eta)
+	}
+	if eta := (Progress{Received: 40, Started: time.Now()}).ETA(); eta != 0 {
+		t.Errorf("expected no eta without total, got %s", eta)
+	}
+}
@@ -0,0 +1,141 @@
+// Package encryption encrypts configuration files that must not be published using age (https://age-encryption.org/v1),
+// with X25519 recipients and ascii armor. The keys use the age format (age1... and AGE-SECRET-KEY-1...), files and keys are compatible with the age cli.
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// ErrNoMatchingIdentity is returned if none of the identities can decrypt the file
+var ErrNoMatchingIdentity = errors.New("no identity matches any of the recipients of the file")
+
+// Recipient is a X25519 public key, ex. age1...
+type Recipient struct {
+	recipient *age.X25519Recipient
+}
+
+// String returns the bech32 encoded recipient
+func (r Recipient) String() string {
+	return r.recipient.String()
+}
+
+// Identity is a X25519 private key, ex. AGE-SECRET-KEY-1...
+type Identity struct {
+	identity *age.X25519Identity
+}
+
+// String returns the bech32 encoded identity
+func (i Identity) String() string {
+	return i.identity.String()
+}
+
+// Recipient returns the recipient of the identity
+func (i Identity) Recipient() Recipient {
+	return Recipient{recipient: i.identity.Recipient()}
+}
+
+// GenerateIdentity creates a random identity
+func GenerateIdentity() (Identity, error) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return Identity{}, err
+	}
+	return Identity{identity: identity}, nil
+}
+
+// ParseRecipient parses a age1... recipient
+func ParseRecipient(s string) (Recipient, error) {
+	recipient, err := age.ParseX25519Recipient(s)
+	if err != nil {
+		return Recipient{}, fmt.Errorf("invalid recipient %s: %w", s, err)
+	}
+	return Recipient{recipient: recipient}, nil
+}
+
+// ParseIdentity parses a AGE-SECRET-KEY-1... identity
+func ParseIdentity(s string) (Identity, error) {
+	identity, err := age.ParseX25519Identity(s)
+	if err != nil {
+		return Identity{}, fmt.Errorf("invalid identity: %w", err)
+	}
+	return Identity{identity: identity}, nil
+}
+
+// ParseIdentities parses a identity file as written by age-keygen, empty lines and comments (#) are skipped
+func ParseIdentities(content string) ([]Identity, error) {
+	var identities []Identity
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		identity, err := ParseIdentity(line)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	if len(identities) == 0 {
+		return nil, errors.New("no identity found")
+	}
+	return identities, nil
+}
+
+// IsEncrypted returns true if the content is a armored age file
+func IsEncrypted(content []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(content), []byte(armor.Header))
+}
+
+// Encrypt encrypts the plaintext for the recipients and returns the armored file
+func Encrypt(plaintext []byte, recipients ...Recipient) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("at least one recipient is required")
+	}
+	ageRecipients := make([]age.Recipient, 0, len(recipients))
+	for _, recipient := range recipients {
+		ageRecipients = append(ageRecipients, recipient.recipient)
+	}
+
+	var file bytes.Buffer
+	armored := armor.NewWriter(&file)
+	w, err := age.Encrypt(armored, ageRecipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	if err = armored.Close(); err != nil {
+		return nil, err
+	}
+	file.WriteByte('\n')
+	return file.Bytes(), nil
+}
+
+// Decrypt decrypts the armored file using the first matching identity
+func Decrypt(armored []byte, identities ...Identity) ([]byte, error) {
+	ageIdentities := make([]age.Identity, 0, len(identities))
+	for _, identity := range identities {
+		ageIdentities = append(ageIdentities, identity.identity)
+	}
+
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(bytes.TrimSpace(armored))), ageIdentities...)
+	if err != nil {
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			return nil, ErrNoMatchingIdentity
+		}
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
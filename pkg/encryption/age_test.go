@@ -0,0 +1,161 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"filippo.io/age/armor"
+)
+
+// ageChunkSize is the size of the payload chunks of age files
+const ageChunkSize = 64 * 1024
+
+func TestKeys(t *testing.T) {
+	identity, err := GenerateIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(identity.String(), "AGE-SECRET-KEY-1") || !strings.HasPrefix(identity.Recipient().String(), "age1") {
+		t.Errorf("unexpected keys %s %s", identity, identity.Recipient())
+	}
+
+	parsed, err := ParseIdentities("# created: 2024-01-01\n# public key: " + identity.Recipient().String() + "\n" + identity.String() + "\n")
+	if err != nil || len(parsed) != 1 || parsed[0].String() != identity.String() {
+		t.Errorf("unexpected identities %v (%v)", parsed, err)
+	}
+	recipient, err := ParseRecipient(identity.Recipient().String())
+	if err != nil || recipient.String() != identity.Recipient().String() {
+		t.Errorf("unexpected recipient %v (%v)", recipient, err)
+	}
+
+	if _, err = ParseRecipient(identity.String()); err == nil {
+		t.Error("expected a error for a identity as recipient")
+	}
+	if _, err = ParseIdentities("# only comments\n"); err == nil {
+		t.Error("expected a error for a file without identities")
+	}
+}
+
+func TestKeysAgeKeygen(t *testing.T) {
+	// key.txt has been created by age-keygen
+	identities := readIdentities(t, "testdata/key.txt")
+	if len(identities) != 1 {
+		t.Fatalf("expected a single identity, got %d", len(identities))
+	}
+	if recipient := identities[0].Recipient().String(); recipient != "age1yh5a9u8f53cd6eksky65rkegn83ugp9y5s8lzdntejkh885rxaqqf5agp4" {
+		t.Errorf("expected the public key printed by age-keygen, got %s", recipient)
+	}
+}
+
+func TestDecryptAgeCLI(t *testing.T) {
+	key := readIdentities(t, "testdata/key.txt")
+	otherKey := readIdentities(t, "testdata/other-key.txt")
+
+	// config.yml.age has been created by age -a -R, chunks.bin.age by age -a -r -r with a payload of more than a chunk
+	config, err := os.ReadFile("testdata/config.yml.age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(config) {
+		t.Fatal("expected the file of the age cli to be detected as encrypted")
+	}
+	decrypted, err := Decrypt(config, key...)
+	if err != nil || !strings.Contains(string(decrypted), "registry.internal.example.com") {
+		t.Errorf("unexpected decrypted content %q (%v)", decrypted, err)
+	}
+	if _, err = Decrypt(config, otherKey...); !errors.Is(err, ErrNoMatchingIdentity) {
+		t.Errorf("expected ErrNoMatchingIdentity, got %v", err)
+	}
+
+	chunks, err := os.ReadFile("testdata/chunks.bin.age")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := make([]byte, ageChunkSize+1)
+	for i := range expected {
+		expected[i] = byte((i*7 + 3) % 256)
+	}
+	for _, identities := range [][]Identity{key, otherKey} {
+		decrypted, err = Decrypt(chunks, identities...)
+		if err != nil || !bytes.Equal(decrypted, expected) {
+			t.Errorf("the decrypted content differs (%v)", err)
+		}
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	alice, _ := GenerateIdentity()
+	bob, _ := GenerateIdentity()
+	eve, _ := GenerateIdentity()
+
+	for _, size := range []int{0, 1, 100, ageChunkSize - 1, ageChunkSize, ageChunkSize + 1, 3*ageChunkSize + 17} {
+		plaintext := bytes.Repeat([]byte("registry.internal.example.com\n"), size/30+1)[:size]
+
+		encrypted, err := Encrypt(plaintext, alice.Recipient(), bob.Recipient())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsEncrypted(encrypted) || bytes.Contains(encrypted, []byte("registry.internal")) {
+			t.Fatalf("expected a armored file without plaintext, got %q", encrypted[:64])
+		}
+
+		for _, identity := range []Identity{alice, bob} {
+			decrypted, err := Decrypt(encrypted, eve, identity)
+			if err != nil {
+				t.Fatalf("size %d: unexpected error %v", size, err)
+			}
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("size %d: the decrypted content differs", size)
+			}
+		}
+		if _, err = Decrypt(encrypted, eve); !errors.Is(err, ErrNoMatchingIdentity) {
+			t.Errorf("size %d: expected ErrNoMatchingIdentity, got %v", size, err)
+		}
+	}
+}
+
+func TestDecryptModified(t *testing.T) {
+	identity, _ := GenerateIdentity()
+	encrypted, err := Encrypt([]byte("images: []\n"), identity.Recipient())
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := io.ReadAll(armor.NewReader(bytes.NewReader(bytes.TrimSpace(encrypted))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, index := range []int{len("age-encryption.org/v1") + 5, len(file) - 1} {
+		modified := append([]byte{}, file...)
+		modified[index] ^= 1
+
+		var armored bytes.Buffer
+		w := armor.NewWriter(&armored)
+		_, _ = w.Write(modified)
+		_ = w.Close()
+		if _, err = Decrypt(armored.Bytes(), identity); err == nil {
+			t.Errorf("expected a error for a modification at %d", index)
+		}
+	}
+	if IsEncrypted([]byte("images: []\n")) {
+		t.Error("expected plain yaml to not be detected as encrypted")
+	}
+}
+
+// readIdentities reads a identity file of the testdata
+func readIdentities(t *testing.T, file string) []Identity {
+	t.Helper()
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	identities, err := ParseIdentities(string(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return identities
+}
@@ -0,0 +1,82 @@
+package envcli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/audit"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/support"
+	"github.com/rs/zerolog/log"
+)
+
+// auditTarget returns the audit log of the audit-log property, empty if the runs aren't audited
+func (r *Runner) auditTarget() audit.Target {
+	return audit.Target(r.opts.Properties.Properties["audit-log"])
+}
+
+// auditStrict returns true if runs that can't be audited fail (audit-strict property)
+func (r *Runner) auditStrict() bool {
+	return r.opts.Properties.Properties["audit-strict"] == "true"
+}
+
+// checkAudit returns a error if audit-strict is set and the audit log isn't writable, the command must not run unaudited
+func (r *Runner) checkAudit() error {
+	target := r.auditTarget()
+	if target == "" || !r.auditStrict() {
+		return nil
+	}
+	if err := target.Check(); err != nil {
+		return exitcode.New(exitcode.GeneralError, fmt.Errorf("audit log %s is not writable, not running the command (audit-strict): %w", target, err))
+	}
+	return nil
+}
+
+// writeAudit writes the audit record of the run, also for runs that failed to start. The secrets within the arguments are masked.
+// A failed write is logged as warning, with audit-strict it fails the run unless the run already failed.
+func (r *Runner) writeAudit(args []string, info *runInfo, started time.Time, runErr error) error {
+	target := r.auditTarget()
+	if target == "" {
+		return runErr
+	}
+
+	record := audit.Record{
+		Time:     started.UTC(),
+		User:     auditUser(),
+		Project:  config.GetProjectOrDirectory(r.workingDirectory()),
+		Command:  support.SanitizeArgs(args),
+		Entry:    info.entry,
+		Image:    info.image,
+		Digest:   info.digest,
+		ExitCode: exitcode.Of(runErr),
+	}
+	record.Host, _ = os.Hostname()
+	if runErr != nil {
+		// the exit code of a failed command is sufficient
+		var exitErr *exec.ExitError
+		if !errors.As(runErr, &exitErr) {
+			record.Error = support.SanitizeText(runErr.Error())
+		}
+	}
+
+	if err := target.Write(record); err != nil {
+		if r.auditStrict() && runErr == nil {
+			return exitcode.New(exitcode.GeneralError, fmt.Errorf("failed to write the audit log %s (audit-strict): %w", target, err))
+		}
+		log.Warn().Err(err).Str("audit-log", string(target)).Msg("failed to write the audit log")
+	}
+	return runErr
+}
+
+// auditUser returns the name of the user on the host
+func auditUser() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,243 @@
+package envcli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/rs/zerolog/log"
+)
+
+// Batch result states
+const (
+	BatchPassed  = "pass"
+	BatchFailed  = "fail"
+	BatchSkipped = "skip"
+)
+
+// BatchResult is the result of the command within a single directory of a batch run
+type BatchResult struct {
+	// Directory is the directory as matched by the pattern
+	Directory string
+
+	// Status is pass, fail or skip
+	Status string
+
+	// ExitCode is the exit code of the command, the config error exit code for directories without a matching entry
+	ExitCode int
+
+	// Reason explains why the directory has been skipped or failed before the command ran
+	Reason string
+
+	Duration time.Duration
+}
+
+// BatchOptions configure a batch run
+type BatchOptions struct {
+	// Parallel is the number of directories processed at once, sequential if 1 or less
+	Parallel int
+
+	// Strict fails the directories without a entry providing the command, instead of skipping them
+	Strict bool
+}
+
+// BatchDirectories returns the directories matching the glob pattern (ex. services/*), relative to the base directory and sorted by name
+func BatchDirectories(base string, pattern string) ([]string, error) {
+	if filepath.IsAbs(pattern) {
+		return nil, fmt.Errorf("the directory pattern %s must be relative", pattern)
+	}
+	matches, err := filepath.Glob(filepath.Join(base, pattern))
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory pattern %s: %w", pattern, err)
+	}
+
+	var directories []string
+	for _, match := range matches {
+		if info, statErr := os.Stat(match); statErr == nil && info.IsDir() {
+			relative, _ := filepath.Rel(base, match)
+			directories = append(directories, relative)
+		}
+	}
+	if len(directories) == 0 {
+		return nil, fmt.Errorf("no directories match %s", pattern)
+	}
+	sort.Strings(directories)
+	return directories, nil
+}
+
+// RunBatch runs the command once per directory, relative to the working directory of the runner - each directory uses its nearest project config.
+// Directories without a entry providing the command are skipped unless strict is set. The output of parallel runs is prefixed with the directory.
+// A per-directory summary is written to Stderr, the exit code of the first failed directory is returned.
+func (r *Runner) RunBatch(ctx context.Context, directories []string, command string, args []string, opts BatchOptions) ([]BatchResult, error) {
+	parallel := opts.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	results := make([]BatchResult, len(directories))
+	output := &batchOutput{}
+	semaphore := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, directory := range directories {
+		if ctx.Err() != nil {
+			results[i] = BatchResult{Directory: directory, Status: BatchFailed, ExitCode: exitcode.Interrupted, Reason: "cancelled"}
+			continue
+		}
+
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(i int, directory string) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			results[i] = r.runBatchDirectory(ctx, directory, command, args, opts.Strict, parallel > 1, output)
+		}(i, directory)
+	}
+	wg.Wait()
+
+	writeBatchSummary(r.opts.Stderr, strings.Join(append([]string{command}, args...), " "), results)
+
+	failed := 0
+	code := 0
+	for _, result := range results {
+		if result.Status == BatchFailed {
+			failed++
+			if code == 0 {
+				code = result.ExitCode
+			}
+		}
+	}
+	if failed > 0 {
+		// the failures have already been reported by the summary
+		return results, exitcode.NewSilent(code, fmt.Errorf("%d of %d directories failed", failed, len(results)))
+	}
+	return results, nil
+}
+
+// runBatchDirectory runs the command within a single directory of the batch
+func (r *Runner) runBatchDirectory(ctx context.Context, directory string, command string, args []string, strict bool, prefixed bool, output *batchOutput) BatchResult {
+	result := BatchResult{Directory: directory}
+	absolute := directory
+	if !filepath.IsAbs(absolute) {
+		absolute = filepath.Join(r.workingDirectory(), directory)
+	}
+
+	// directories without a configured entry are skipped, the fallback image doesn't count as configured
+	if _, matchType, err := config.GetCommandVariantMatch(ctx, command, r.opts.Variant, absolute, r.opts.ConfigIncludes); err != nil || matchType == config.MatchByFallback {
+		var noMatch *config.NoMatchError
+		if err != nil && !errors.As(err, &noMatch) {
+			result.Status, result.ExitCode, result.Reason = BatchFailed, exitcode.Of(err), err.Error()
+			return result
+		}
+		result.Status, result.ExitCode, result.Reason = BatchSkipped, exitcode.ConfigError, "no entry provides "+command
+		if strict {
+			result.Status = BatchFailed
+		}
+		return result
+	}
+
+	opts := r.opts
+	opts.WorkingDirectory = absolute
+	if prefixed {
+		// parallel runs can't share the terminal input
+		opts.Stdin = bytes.NewReader(nil)
+		opts.Stdout = output.prefixed(r.opts.Stdout, directory)
+		opts.Stderr = output.prefixed(r.opts.Stderr, directory)
+	} else {
+		fmt.Fprintf(r.opts.Stderr, "---- %s: %s ----\n", directory, strings.Join(append([]string{command}, args...), " "))
+	}
+	runner := &Runner{opts: opts, notify: r.notify, verify: r.verify, goos: r.goos}
+
+	log.Debug().Str("directory", absolute).Str("command", command).Msg("running the command of the batch")
+	started := time.Now()
+	code, err := runner.Run(ctx, command, args)
+	result.Duration = time.Since(started)
+	if prefixed {
+		opts.Stdout.(*prefixWriter).Flush()
+		opts.Stderr.(*prefixWriter).Flush()
+	}
+
+	result.Status, result.ExitCode = BatchPassed, code
+	if err != nil {
+		result.Status = BatchFailed
+		if !exitcode.IsSilent(err) {
+			result.Reason = err.Error()
+		}
+	}
+	return result
+}
+
+// writeBatchSummary prints the result of each directory
+func writeBatchSummary(w io.Writer, command string, results []BatchResult) {
+	fmt.Fprintf(w, "envcli batch summary of %s:\n", command)
+	for _, result := range results {
+		line := fmt.Sprintf("  %-4s  %s", strings.ToUpper(result.Status), result.Directory)
+		if result.Status == BatchFailed && result.ExitCode != 0 {
+			line += fmt.Sprintf(", exit code %d", result.ExitCode)
+		}
+		if result.Reason != "" {
+			line += ", " + strings.SplitN(result.Reason, "\n", 2)[0]
+		}
+		if result.Duration > 0 {
+			line += fmt.Sprintf(" (%s)", result.Duration.Round(time.Millisecond))
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// batchOutput serializes the writes of the parallel runs, so that their lines don't interleave
+type batchOutput struct {
+	mu sync.Mutex
+}
+
+func (o *batchOutput) prefixed(w io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{output: o, w: w, prefix: []byte("[" + prefix + "] ")}
+}
+
+// prefixWriter prefixes each complete line, incomplete lines are buffered until Flush
+type prefixWriter struct {
+	output *batchOutput
+	w      io.Writer
+	prefix []byte
+	buffer []byte
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.output.mu.Lock()
+	defer p.output.mu.Unlock()
+
+	p.buffer = append(p.buffer, data...)
+	for {
+		index := bytes.IndexByte(p.buffer, '\n')
+		if index < 0 {
+			break
+		}
+		if _, err := p.w.Write(append(append([]byte{}, p.prefix...), p.buffer[:index+1]...)); err != nil {
+			return 0, err
+		}
+		p.buffer = p.buffer[index+1:]
+	}
+	return len(data), nil
+}
+
+// Flush writes the incomplete last line
+func (p *prefixWriter) Flush() {
+	p.output.mu.Lock()
+	defer p.output.mu.Unlock()
+
+	if len(p.buffer) > 0 {
+		_, _ = p.w.Write(append(append(append([]byte{}, p.prefix...), p.buffer...), '\n'))
+		p.buffer = nil
+	}
+}
@@ -0,0 +1,138 @@
+package envcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/rs/zerolog/log"
+)
+
+// Chain operators, a command following && only runs if the previous command succeeded
+const (
+	ChainAnd      = "&&"
+	ChainSequence = ";"
+)
+
+// ChainCommand is a command of a command chain, ex. npm ci && npm test
+type ChainCommand struct {
+	// Args are the command and its arguments
+	Args []string
+
+	// Operator is the operator preceding the command, empty for the first command
+	Operator string
+}
+
+// ParseChain splits the command line on && and ; into its commands and their arguments, like a shell would.
+// Operators within quotes or escaped by a backslash are part of the arguments, pipes and || are not supported.
+func ParseChain(chain string) ([]ChainCommand, error) {
+	var commands []ChainCommand
+	var args []string
+	var current strings.Builder
+	inArg := false
+	operator := ""
+	var quote rune
+
+	endArg := func() {
+		if inArg {
+			args = append(args, current.String())
+			current.Reset()
+			inArg = false
+		}
+	}
+	add := func(next string) error {
+		endArg()
+		if len(args) == 0 {
+			// a trailing ; is allowed, like in a shell
+			if next == "" && operator == ChainSequence && len(commands) > 0 {
+				return nil
+			}
+			return fmt.Errorf("empty command in chain %q", chain)
+		}
+		commands = append(commands, ChainCommand{Args: args, Operator: operator})
+		args = nil
+		operator = next
+		return nil
+	}
+
+	runes := []rune(chain)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		var next rune
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\\' && next != 0 && (quote == 0 || strings.ContainsRune("\\\"$`", next)):
+			// within double quotes only the characters special to the shell are escaped
+			current.WriteRune(next)
+			inArg = true
+			i++
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n':
+			endArg()
+		case c == '&' && next == '&':
+			if err := add(ChainAnd); err != nil {
+				return nil, err
+			}
+			i++
+		case c == ';':
+			if err := add(ChainSequence); err != nil {
+				return nil, err
+			}
+		case c == '|':
+			return nil, fmt.Errorf("pipes and || are not supported in chain %q, run the pipe within a shell of the entry instead", chain)
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in chain %q", chain)
+	}
+	if err := add(""); err != nil {
+		return nil, err
+	}
+
+	return commands, nil
+}
+
+// RunChain runs the commands sequentially, each within the container of its entry.
+// A command following && is skipped if the previous command failed, the exit code and error of the last executed command are returned.
+func (r *Runner) RunChain(ctx context.Context, commands []ChainCommand) (int, error) {
+	if len(commands) == 0 {
+		return exitcode.ConfigError, exitcode.New(exitcode.ConfigError, errors.New("the command chain is empty"))
+	}
+
+	code, err := 0, error(nil)
+	for _, command := range commands {
+		if command.Operator == ChainAnd && err != nil {
+			log.Debug().Strs("command", command.Args).Msg("skipping the command, the previous command failed")
+			continue
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		log.Debug().Strs("command", command.Args).Msg("running the next command of the chain")
+		code, err = r.Run(ctx, command.Args[0], command.Args[1:])
+	}
+	return code, err
+}
@@ -0,0 +1,82 @@
+package envcli
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/gitutil"
+	"github.com/EnvCLI/EnvCLI/pkg/proxy"
+	"github.com/EnvCLI/EnvCLI/pkg/runlog"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/cidverse/cidverseutils/pkg/containerruntime"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/rs/zerolog/log"
+)
+
+// pruneRunLogs applies the configured retention to the log directory
+func (r *Runner) pruneRunLogs(logDirectory string) {
+	props := r.opts.Properties.Properties
+	maxCount, _ := strconv.Atoi(collection.MapGetValueOrDefault(props, "log-retention-count", "0"))
+	maxAge, _ := time.ParseDuration(collection.MapGetValueOrDefault(props, "log-retention-age", "0s"))
+
+	if err := runlog.Prune(logDirectory, maxCount, maxAge); err != nil {
+		log.Warn().Err(err).Str("dir", logDirectory).Msg("failed to remove old log files")
+	}
+}
+
+// addCacheMounts mounts the cache directories of the entry from the cache path
+func (r *Runner) addCacheMounts(container *containerutil.Container, commandConfig config.RunConfigurationEntry) {
+	cachePath := config.GetCachePath(*r.opts.Properties).Path
+	for _, cachingEntry := range commandConfig.Caching {
+		if cachePath == "" {
+			log.Warn().Msg("Cache is disabled, CachePath not set.")
+			break
+		}
+
+		var cacheFolder = cachePath + "/" + cachingEntry.Name
+		filesystem.CreateDirectory(cacheFolder)
+		container.AddCacheMount(cachingEntry.Name, cacheFolder, cachingEntry.ContainerDirectory)
+	}
+}
+
+// addProxyEnvironment passes the configured proxy servers into the container
+func (r *Runner) addProxyEnvironment(container *containerutil.Container) {
+	props := r.opts.Properties.Properties
+	httpProxy := collection.MapGetValueOrDefault(props, "http-proxy", "")
+	if httpProxy != "" {
+		container.AddEnvironmentVariable("http_proxy", httpProxy)
+	}
+
+	httpsProxy := collection.MapGetValueOrDefault(props, "https-proxy", "")
+	if httpsProxy != "" {
+		container.AddEnvironmentVariable("https_proxy", httpsProxy)
+	}
+
+	noProxy := proxy.MergeNoProxy(collection.MapGetValueOrDefault(props, "no-proxy", ""), proxy.GetNoProxyEnvironment())
+	if noProxy != "" && (httpProxy != "" || httpsProxy != "") {
+		container.AddEnvironmentVariable("no_proxy", noProxy)
+	}
+}
+
+// forwardGitConfig mounts the git configuration of the host user as system config and passes the identity as environment variables
+func forwardGitConfig(container *containerutil.Container) {
+	gitConfigFile := gitutil.GetGlobalConfigFile()
+	identity, err := gitutil.ReadIdentity(gitConfigFile)
+	if err != nil {
+		log.Warn().Err(err).Str("file", gitConfigFile).Msg("can't forward the git configuration, failed to read the git config")
+		return
+	}
+
+	log.Debug().Str("file", gitConfigFile).Str("name", identity.Name).Str("email", identity.Email).Msg("forwarding git configuration")
+	container.AddVolume(containerruntime.ContainerMount{MountType: "directory", Source: gitConfigFile, Target: "/etc/gitconfig", Mode: containerruntime.ReadMode})
+	if identity.Name != "" {
+		container.AddEnvironmentVariable("GIT_AUTHOR_NAME", identity.Name)
+		container.AddEnvironmentVariable("GIT_COMMITTER_NAME", identity.Name)
+	}
+	if identity.Email != "" {
+		container.AddEnvironmentVariable("GIT_AUTHOR_EMAIL", identity.Email)
+		container.AddEnvironmentVariable("GIT_COMMITTER_EMAIL", identity.Email)
+	}
+}
@@ -0,0 +1,99 @@
+package envcli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/rs/zerolog/log"
+)
+
+// loadCredentials executes the credential helpers of the entry on the host and returns their short-lived credentials (NAME=value).
+// The credentials are secrets, only the names of the variables are logged.
+func (r *Runner) loadCredentials(ctx context.Context, entry config.RunConfigurationEntry, directory string) ([]string, error) {
+	if len(entry.CredentialHelpers) == 0 {
+		return nil, nil
+	}
+	helpers, err := config.GetCredentialHelpers()
+	if err != nil {
+		return nil, exitcode.New(exitcode.ConfigError, err)
+	}
+
+	templateData := config.NewTemplateContext(ctx, directory)
+	var env []string
+	for _, name := range entry.CredentialHelpers {
+		helper, found := helpers[name]
+		if !found {
+			return nil, exitcode.New(exitcode.ConfigError, fmt.Errorf("unknown credential helper %s of entry %s, available: %s", name, entry.Name, strings.Join(config.CredentialHelperNames(helpers), ", ")))
+		}
+		args, err := renderCredentialHelper(name, helper, templateData)
+		if err != nil {
+			return nil, exitcode.New(exitcode.ConfigError, fmt.Errorf("invalid command of credential helper %s: %w", name, err))
+		}
+
+		log.Debug().Str("helper", name).Str("command", containerutil.FormatCommand(args)).Msg("executing credential helper")
+		var stdout bytes.Buffer
+		if err = containerutil.ExecLocalCommand(ctx, args[0], args[1:], directory, nil, nil, &stdout, r.opts.Stderr); err != nil {
+			return nil, fmt.Errorf("credential helper %s of entry %s failed: %w", name, entry.Name, err)
+		}
+		variables, err := config.ParseCredentialOutput(helper, stdout.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid output of credential helper %s: %w", name, err)
+		}
+
+		names := variableNames(variables)
+		if len(names) == 0 {
+			log.Warn().Str("helper", name).Msg("the credential helper returned no credentials")
+		}
+		log.Debug().Str("helper", name).Strs("variables", names).Msg("loaded credentials")
+		env = append(env, variables...)
+	}
+	return env, nil
+}
+
+// templateAction matches the actions within a template, they can contain spaces and quotes
+var templateAction = regexp.MustCompile(`{{.*?}}`)
+
+// renderCredentialHelper splits the command of the helper into its arguments and renders the templates of each argument.
+// The helper runs without a shell, so template values (ex. a branch named `$(curl ...)`) always stay a single argument.
+func renderCredentialHelper(name string, helper config.CredentialHelper, templateData *config.TemplateContext) ([]string, error) {
+	// the actions are replaced by placeholders while splitting, so {{ .GitBranch }} isn't split at its spaces
+	actions := templateAction.FindAllString(helper.Command, -1)
+	index := 0
+	command := templateAction.ReplaceAllStringFunc(helper.Command, func(string) string {
+		index++
+		return fmt.Sprintf("\x00%d\x00", index-1)
+	})
+
+	var args []string
+	for _, arg := range common.SplitArgs(command) {
+		for i, action := range actions {
+			arg = strings.ReplaceAll(arg, fmt.Sprintf("\x00%d\x00", i), action)
+		}
+		rendered, err := config.RenderTemplate("credentialHelpers."+name, arg, templateData)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, rendered)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("the command is empty")
+	}
+	return args, nil
+}
+
+// variableNames returns the names of the variables (NAME=value)
+func variableNames(env []string) []string {
+	var names []string
+	for _, variable := range env {
+		name, _, _ := strings.Cut(variable, "=")
+		names = append(names, name)
+	}
+	return names
+}
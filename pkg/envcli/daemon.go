@@ -0,0 +1,23 @@
+package envcli
+
+import (
+	"context"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/rs/zerolog/log"
+)
+
+// waitForDaemon waits for the daemon of the runtime to start, up to the daemon-wait-timeout property (disabled by default)
+func (r *Runner) waitForDaemon(ctx context.Context, runtime containerutil.ContainerRuntime) error {
+	value := r.opts.Properties.Properties["daemon-wait-timeout"]
+	if value == "" {
+		return nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		log.Warn().Str("daemon-wait-timeout", value).Msg("invalid daemon-wait-timeout property, expected a duration like 30s")
+		return nil
+	}
+	return containerutil.WaitForDaemon(ctx, runtime, timeout, r.opts.Stderr)
+}
@@ -0,0 +1,46 @@
+package envcli
+
+import (
+	"fmt"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/rs/zerolog/log"
+)
+
+// loadEnvFiles returns the variables of the env files of the entry, the paths are relative to the configuration file of the entry
+func (r *Runner) loadEnvFiles(entry config.RunConfigurationEntry) ([]string, error) {
+	var env []string
+	for _, envFile := range entry.EnvFile {
+		variables, err := loadEnvFile(config.ResolveEnvFilePath(envFile.Path, entry.Source), envFile.Optional)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the env files of entry %s: %w", entry.Name, err)
+		}
+		env = append(env, variables...)
+	}
+	return env, nil
+}
+
+// loadOptionEnvFiles returns the variables of the env files passed using --env-file, the files are required
+func (r *Runner) loadOptionEnvFiles() ([]string, error) {
+	var env []string
+	for _, file := range r.opts.EnvFiles {
+		variables, err := loadEnvFile(file, false)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, variables...)
+	}
+	return env, nil
+}
+
+// loadEnvFile loads the env file, only the names of the variables are logged since the values may contain secrets
+func loadEnvFile(file string, optional bool) ([]string, error) {
+	env, err := config.LoadEnvFile(file, optional)
+	if err != nil {
+		return nil, exitcode.New(exitcode.ConfigError, err)
+	}
+
+	log.Debug().Str("file", file).Strs("variables", variableNames(env)).Msg("loaded env file")
+	return env, nil
+}
@@ -0,0 +1,39 @@
+package envcli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+)
+
+// probeFileSharing checks that the mounted directory isn't empty within the container, as directories that aren't shared with the vm of Docker Desktop on macOS are mounted as empty directories.
+// The project configuration file is expected within the container, or the first file of the directory if the configuration is located elsewhere.
+func (r *Runner) probeFileSharing(ctx context.Context, runtime containerutil.ContainerRuntime, image string, source string) error {
+	if collection.MapGetValueOrDefault(r.opts.Properties.Properties, "file-sharing-probe", "true") == "false" {
+		return nil
+	}
+	home, _ := os.UserHomeDir()
+	if !containerutil.NeedsFileSharingProbe(r.goos, home, source) {
+		return nil
+	}
+
+	expected := ""
+	if _, file, err := config.FindProjectConfig(r.workingDirectory(), config.GetProjectConfigFilenames()); err == nil && filepath.Dir(file) == source {
+		expected = filepath.Base(file)
+	} else if entries, err := os.ReadDir(source); err == nil && len(entries) > 0 {
+		expected = entries[0].Name()
+	}
+	if expected == "" {
+		return nil
+	}
+
+	if err := containerutil.ProbeFileSharing(ctx, runtime, image, source, expected); err != nil {
+		return exitcode.New(exitcode.RuntimeUnavailable, err)
+	}
+	return nil
+}
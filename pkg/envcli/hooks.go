@@ -0,0 +1,48 @@
+package envcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/rs/zerolog/log"
+)
+
+// checkHookTrust ensures that the project config defining the hooks is trusted, hooks execute arbitrary code on the host
+func (r *Runner) checkHookTrust(hooks config.ProjectHooks) error {
+	trusted, err := config.IsTrusted(hooks)
+	if err != nil {
+		return fmt.Errorf("failed to check the trust of %s: %w", hooks.ConfigFile, err)
+	}
+	if trusted {
+		return nil
+	}
+
+	if r.opts.ConfirmTrust == nil || !r.opts.ConfirmTrust(hooks) {
+		return exitcode.New(exitcode.ConfigError, errors.New("the project config "+hooks.ConfigFile+" defines hooks executed on the host, but it isn't trusted. Review the hooks and run envcli trust to allow them"))
+	}
+	return config.Trust(hooks)
+}
+
+// runHook executes the hook on the host within the project directory
+func (r *Runner) runHook(ctx context.Context, hooks config.ProjectHooks, name string, hook string, env []string) error {
+	if hook == "" {
+		return nil
+	}
+
+	log.Debug().Str("hook", name).Str("command", hook).Msg("executing hook")
+	return containerutil.ExecHostCommand(ctx, hook, hooks.Directory, env, r.opts.Stdin, r.opts.Stdout, r.opts.Stderr)
+}
+
+// hookEnvironment returns the environment variables passed to the hooks, the exit code is only passed to the post-run hook
+func hookEnvironment(command string, image string, exitCode *int) []string {
+	env := []string{"ENVCLI_COMMAND=" + command, "ENVCLI_IMAGE=" + image}
+	if exitCode != nil {
+		env = append(env, "ENVCLI_EXIT_CODE="+strconv.Itoa(*exitCode))
+	}
+	return env
+}
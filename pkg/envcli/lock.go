@@ -0,0 +1,41 @@
+package envcli
+
+import (
+	"errors"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/rs/zerolog/log"
+)
+
+var errNoLockFile = errors.New("the project has no lock file, run envcli lock to create it")
+
+// lockedImage returns the image to run for the entry, the locked digest is used if the project has a lock file (see config.ResolveLockedImage).
+// Only the entries of the project config are locked.
+func (r *Runner) lockedImage(entry config.RunConfigurationEntry) (string, error) {
+	if entry.Scope != "Project" {
+		return entry.Image, nil
+	}
+	projectDirectory, _, err := config.FindProjectConfig(r.workingDirectory(), config.GetProjectConfigFilenames())
+	if err != nil {
+		return entry.Image, nil
+	}
+
+	lock, found, err := config.LoadLockFile(config.GetLockFilePath(projectDirectory))
+	if err != nil {
+		return "", exitcode.New(exitcode.ConfigError, err)
+	}
+	if !found {
+		if r.opts.Frozen {
+			return "", exitcode.New(exitcode.ConfigError, errNoLockFile)
+		}
+		return entry.Image, nil
+	}
+
+	image, err := config.ResolveLockedImage(entry, lock, r.opts.Frozen)
+	if err != nil {
+		return "", exitcode.New(exitcode.ConfigError, err)
+	}
+	log.Debug().Str("entry", entry.Name).Str("image", image).Msg("resolved the locked image")
+	return image, nil
+}
@@ -0,0 +1,56 @@
+package envcli
+
+import (
+	"context"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/rs/zerolog/log"
+)
+
+// ResolveImageMirror returns the image of the entry with the registry replaced by the imageMirror of the entry or the registry-mirrors property.
+// The images of build entries are built locally and are never mirrored. The mirrored image is checked against the image policies of the entry.
+func ResolveImageMirror(entry config.RunConfigurationEntry, props map[string]string) (string, error) {
+	if entry.IsBuild() {
+		return entry.Image, nil
+	}
+	mirrors, err := config.ParseRegistryMirrors(collection.MapGetValueOrDefault(props, "registry-mirrors", ""))
+	if err != nil {
+		return "", exitcode.New(exitcode.ConfigError, err)
+	}
+	image := config.MirrorImage(entry.Image, entry.ImageMirror, mirrors)
+	if err = config.CheckImagePolicies(image, entry.ImagePolicies); err != nil {
+		return "", exitcode.New(exitcode.ConfigError, err)
+	}
+	return image, nil
+}
+
+// MirroredImage returns the image of the entry pulled from the registry mirror, the image is unchanged without a mirror
+func (r *Runner) MirroredImage(entry config.RunConfigurationEntry) (string, error) {
+	image, err := ResolveImageMirror(entry, r.opts.Properties.Properties)
+	if err == nil && image != entry.Image {
+		log.Info().Str("image", entry.Image).Str("mirror", image).Msg("using the registry mirror")
+	}
+	return image, err
+}
+
+// PullImage pulls the image, if the pull from the mirror fails and mirror-fallback is enabled the original image is pulled instead.
+// Returns the image that has been pulled.
+func (r *Runner) PullImage(ctx context.Context, image string, original string) (string, error) {
+	return r.withMirrorFallback(image, original, func(image string) error {
+		return containerutil.PullImage(ctx, r.runtime(), image)
+	})
+}
+
+// withMirrorFallback calls pull with the mirrored image and with the original image if the mirror fails and mirror-fallback is enabled, returns the image that has been pulled
+func (r *Runner) withMirrorFallback(image string, original string, pull func(image string) error) (string, error) {
+	err := pull(image)
+	if err == nil || image == original || collection.MapGetValueOrDefault(r.opts.Properties.Properties, "mirror-fallback", "") != "true" {
+		return image, err
+	}
+
+	log.Warn().Err(err).Str("mirror", image).Str("image", original).Msg("failed to pull the image from the mirror, falling back to the original reference")
+	return original, pull(original)
+}
@@ -0,0 +1,48 @@
+package envcli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/rs/zerolog/log"
+)
+
+// notifyCompletion shows a desktop notification if the command ran longer than the notify-after property or Notify is set.
+// A terminal bell is used if no desktop notifier is available, cancelled commands never notify.
+func (r *Runner) notifyCompletion(ctx context.Context, args []string, duration time.Duration, err error) {
+	if ctx.Err() != nil || !r.shouldNotify(duration) {
+		return
+	}
+
+	title := "envcli: " + args[0] + " succeeded"
+	if err != nil {
+		title = fmt.Sprintf("envcli: %s failed (exit code %d)", args[0], exitcode.Of(err))
+	}
+	message := fmt.Sprintf("%s finished after %s", common.ParseAndEscapeArgs(args), duration.Round(time.Second))
+
+	if notifyErr := r.notify(ctx, title, message); notifyErr != nil {
+		log.Debug().Err(notifyErr).Msg("desktop notification failed, using the terminal bell")
+		fmt.Fprint(r.opts.Stderr, "\a")
+	}
+}
+
+func (r *Runner) shouldNotify(duration time.Duration) bool {
+	if r.opts.Notify {
+		return true
+	}
+
+	notifyAfter := collection.MapGetValueOrDefault(r.opts.Properties.Properties, "notify-after", "")
+	if notifyAfter == "" {
+		return false
+	}
+	threshold, err := time.ParseDuration(notifyAfter)
+	if err != nil {
+		log.Warn().Str("notify-after", notifyAfter).Msg("invalid notify-after property, expected a duration like 2m")
+		return false
+	}
+	return duration >= threshold
+}
@@ -0,0 +1,24 @@
+package envcli
+
+import (
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+)
+
+// resolveExecution decides if the command runs in the container or using the local binary, see config.ResolveExecution
+func (r *Runner) resolveExecution(entry config.RunConfigurationEntry, matchType string, command string) (config.Execution, error) {
+	return ResolveExecution(entry, matchType, command, func() bool {
+		return containerutil.RequireRuntime(r.runtime()) == nil
+	})
+}
+
+// ResolveExecution decides if the command runs in the container or using the local binary, the envcli aliases and links to envcli are never used as local binary
+func ResolveExecution(entry config.RunConfigurationEntry, matchType string, command string, runtimeAvailable func() bool) (config.Execution, error) {
+	localPath := ""
+	if entry.Passthrough != "" && matchType == config.MatchByProvides {
+		localPath = containerutil.FindLocalCommand(command, []string{filesystem.GetExecutionDirectory()})
+	}
+
+	return config.ResolveExecution(entry, matchType, localPath, runtimeAvailable)
+}
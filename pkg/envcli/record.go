@@ -0,0 +1,109 @@
+package envcli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/record"
+	"github.com/rs/zerolog/log"
+)
+
+// Divergence is a difference between a recorded run and its replay
+type Divergence struct {
+	// Run is the position of the run within the record file, starting at 1
+	Run     int
+	Command string
+	Message string
+}
+
+func (d Divergence) String() string {
+	return fmt.Sprintf("run %d [%s]: %s", d.Run, d.Command, d.Message)
+}
+
+// recordRun appends the run to the record file, a failure is only reported since the command itself has been executed.
+// The environment variables are recorded by name, the replay takes their values from its own environment.
+func (r *Runner) recordRun(ctx context.Context, runtime containerutil.ContainerRuntime, args []string, image string, digest string, container *containerutil.Container, projectDirectory string, exitCode int, timings record.Timings) {
+	runArgs, err := containerutil.RenderRunCommand(ctx, runtime, container.Redacted())
+	if err != nil {
+		log.Warn().Err(err).Str("file", r.opts.RecordFile).Msg("failed to record the run")
+		return
+	}
+	run := record.Run{Time: time.Now(), Command: args, Image: image, Digest: digest, Runtime: runtime.Name(), RunArgs: runArgs, Env: container.EnvironmentNames(), ProjectDirectory: projectDirectory, ExitCode: exitCode, Timings: &timings}
+	if err := record.Append(r.opts.RecordFile, run); err != nil {
+		log.Warn().Err(err).Str("file", r.opts.RecordFile).Msg("failed to record the run")
+	}
+}
+
+// Replay executes the recorded runs of the file in order and returns the divergences from the recording: changed image digests and exit codes.
+// The recorded project directory is replaced by the current project directory, the values of the recorded environment variables are taken from the current environment.
+func (r *Runner) Replay(ctx context.Context, file string) ([]Divergence, error) {
+	runs, err := record.Read(file)
+	if err != nil {
+		return nil, exitcode.New(exitcode.ConfigError, fmt.Errorf("failed to read the record file: %w", err))
+	}
+
+	runtime := r.runtime()
+	if runtimeErr := containerutil.RequireRuntime(runtime); runtimeErr != nil {
+		return nil, runtimeErr
+	}
+	if daemonErr := r.waitForDaemon(ctx, runtime); daemonErr != nil {
+		return nil, daemonErr
+	}
+	projectDirectory := config.GetProjectOrDirectory(r.workingDirectory())
+
+	var divergences []Divergence
+	for i, run := range runs {
+		command := common.ParseAndEscapeArgs(run.Command)
+		diverged := func(format string, a ...interface{}) {
+			divergences = append(divergences, Divergence{Run: i + 1, Command: command, Message: fmt.Sprintf(format, a...)})
+		}
+		fmt.Fprintf(r.opts.Stderr, "---- replaying %d/%d: %s ----\n", i+1, len(runs), command)
+
+		// image
+		if pullErr := containerutil.EnsureImage(ctx, runtime, run.Image); pullErr != nil {
+			if ctx.Err() != nil {
+				return divergences, ctx.Err()
+			}
+			diverged("image %s is not available: %v", run.Image, pullErr)
+			continue
+		}
+		if digest, digestErr := containerutil.ImageID(ctx, runtime, run.Image); digestErr != nil {
+			diverged("failed to resolve the digest of image %s: %v", run.Image, digestErr)
+		} else if run.Digest != "" && digest != run.Digest {
+			diverged("image %s has changed, recorded %s, now %s", run.Image, run.Digest, digest)
+		}
+
+		// adapt the run command to the current environment
+		runCommand := append([]string{}, run.RunArgs...)
+		if len(runCommand) == 0 {
+			diverged("the run has been recorded without its run command")
+			continue
+		}
+		if run.Runtime != runtime.Name() && runCommand[0] == run.Runtime {
+			log.Info().Str("recorded", run.Runtime).Str("runtime", runtime.Name()).Msg("replaying the run using a different container runtime")
+			runCommand[0] = runtime.Name()
+		}
+		if run.ProjectDirectory != "" && run.ProjectDirectory != projectDirectory {
+			log.Debug().Str("recorded", run.ProjectDirectory).Str("project", projectDirectory).Msg("replacing the recorded project directory")
+			for j, arg := range runCommand {
+				runCommand[j] = strings.ReplaceAll(arg, run.ProjectDirectory, projectDirectory)
+			}
+		}
+
+		execErr := runtime.Exec(ctx, runCommand, r.opts.Stdin, r.opts.Stdout, r.opts.Stderr)
+		if ctx.Err() != nil {
+			return divergences, ctx.Err()
+		}
+		if exitCode := exitcode.Of(execErr); exitCode != run.ExitCode {
+			diverged("exit code %d, recorded %d", exitCode, run.ExitCode)
+		}
+	}
+
+	return divergences, nil
+}
@@ -0,0 +1,66 @@
+package envcli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/versionutil"
+	"github.com/rs/zerolog/log"
+)
+
+// checkRuntimeVersion returns a error if the version of the runtime doesn't satisfy the requiresRuntime constraint of the entry.
+// The error names the run flags used by the entry, as they are the usual reason for the constraint (ex. --gpus).
+// If the version can't be determined, a warning is logged and the command runs anyway.
+func checkRuntimeVersion(ctx context.Context, runtime containerutil.ContainerRuntime, entry config.RunConfigurationEntry, userArgs []string) error {
+	constraints, found, err := config.GetRuntimeConstraints(entry, runtime.Name())
+	if err != nil {
+		return exitcode.New(exitcode.ConfigError, fmt.Errorf("entry %s: %w", entry.Name, err))
+	}
+	if !found {
+		return nil
+	}
+
+	output, err := containerutil.RuntimeVersion(ctx, runtime)
+	if err != nil {
+		log.Warn().Err(err).Str("runtime", runtime.Name()).Msg("failed to determine the runtime version, skipping the requiresRuntime check of entry " + entry.Name)
+		return nil
+	}
+	version, err := versionutil.Parse(output)
+	if err != nil {
+		log.Warn().Err(err).Str("runtime", runtime.Name()).Msg("failed to parse the runtime version, skipping the requiresRuntime check of entry " + entry.Name)
+		return nil
+	}
+	if constraints.Check(version) {
+		log.Debug().Str("runtime", runtime.Name()).Str("version", version.String()).Str("constraint", constraints.String()).Msg("the runtime version satisfies the requiresRuntime constraint")
+		return nil
+	}
+
+	message := fmt.Sprintf("entry %s requires %s %s, but %s %s is installed", entry.Name, runtime.Name(), constraints, runtime.Name(), strings.TrimSpace(output))
+	if flags := runFlags(userArgs); len(flags) > 0 {
+		message += fmt.Sprintf(" (the entry uses %s)", strings.Join(flags, ", "))
+	}
+	return exitcode.New(exitcode.RuntimeUnavailable, fmt.Errorf("%s - update %s or use another runtime (--runtime)", message, runtime.Name()))
+}
+
+// runFlags returns the sorted names of the run flags within the arguments, the labels added by envcli are skipped
+func runFlags(args []string) []string {
+	seen := map[string]bool{}
+	var flags []string
+	for _, arg := range args {
+		for _, field := range strings.Fields(arg) {
+			name, _, _ := strings.Cut(field, "=")
+			if !strings.HasPrefix(name, "--") || name == "--label" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			flags = append(flags, name)
+		}
+	}
+	sort.Strings(flags)
+	return flags
+}
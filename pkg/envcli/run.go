@@ -0,0 +1,596 @@
+package envcli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/ciannotation"
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/pathrewrite"
+	"github.com/EnvCLI/EnvCLI/pkg/runlog"
+	"github.com/cidverse/cidverseutils/pkg/cihelper"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/cidverse/cidverseutils/pkg/containerruntime"
+	"github.com/rs/zerolog/log"
+)
+
+// Run runs the command with its arguments within the container of the configured entry.
+// It returns the exit code (see package exitcode) and the error, the exit code of a failed command is passed through.
+func (r *Runner) Run(ctx context.Context, command string, args []string) (int, error) {
+	started := time.Now()
+	info := &runInfo{}
+	if !r.opts.DryRun {
+		if err := r.checkAudit(); err != nil {
+			return exitcode.Of(err), err
+		}
+	}
+	err := r.run(ctx, append([]string{command}, args...), info)
+	if r.opts.DryRun {
+		return exitcode.Of(err), err
+	}
+	err = r.writeAudit(append([]string{command}, args...), info, started, err)
+
+	// feature: documentation of the entry, printed after the error
+	if err != nil && info.docsURL != "" && ctx.Err() == nil {
+		if !exitcode.IsSilent(err) {
+			log.Error().Msg(err.Error())
+			err = exitcode.NewSilent(exitcode.Of(err), err)
+		}
+		fmt.Fprintf(r.opts.Stderr, "See the documentation of %s: %s\n", info.entry, info.docsURL)
+	}
+	if r.opts.Timings && info.timings.Total > 0 {
+		writeTimings(r.opts.Stderr, info.timings)
+	}
+	r.notifyCompletion(ctx, append([]string{command}, args...), time.Since(started), err)
+
+	// the summary is the last line, the error is logged before it
+	if r.opts.Summary != "" {
+		if err != nil && !exitcode.IsSilent(err) {
+			log.Error().Msg(err.Error())
+			err = exitcode.NewSilent(exitcode.Of(err), err)
+		}
+		writeSummary(r.opts.Stderr, r.opts.Summary, command, info, exitcode.Of(err), time.Since(started))
+	}
+	return exitcode.Of(err), err
+}
+
+// run runs the command, the timings are only set once the container has been executed
+func (r *Runner) run(ctx context.Context, args []string, info *runInfo) error {
+	started := time.Now()
+	props := r.opts.Properties.Properties
+	var userArgs []string
+	for _, arg := range r.opts.UserArgs {
+		userArgs = append(userArgs, common.SplitArgs(arg)...)
+	}
+
+	// parse command
+	commandName := args[0]
+	commandArgs := args
+
+	log.Debug().Strs("args", args).Msg("Received request to run command [" + commandName + "].")
+
+	// config: try to load command configuration
+	commandConfig, matchType, commandConfigErr := config.GetCommandVariantMatch(ctx, commandName, r.opts.Variant, r.workingDirectory(), r.opts.ConfigIncludes)
+	if commandConfigErr != nil {
+		return fmt.Errorf("failed to load command config: %w", commandConfigErr)
+	}
+	info.entry, info.docsURL = commandConfig.Name, commandConfig.DocsURL
+	if commandConfig.Variant != "" {
+		log.Debug().Str("entry", commandConfig.Name).Str("variant", commandConfig.Variant).Msg("applied the variant of the entry")
+	}
+	if matchType == config.MatchByFallback {
+		log.Warn().Str("image", commandConfig.Image).Msg("no configuration for command " + commandName + " found, running it in the fallback image - the result may differ from a properly configured entry (tool version, environment, caches)")
+	}
+	if commandConfig.IsBuild() && commandConfig.Image == "" {
+		_, buildErr := config.GetBuildImageName(commandConfig)
+		return exitcode.New(exitcode.ConfigError, buildErr)
+	}
+
+	// feature: templates in the env and default arguments
+	commandConfig, templateErr := config.RenderEntryTemplates(commandConfig, config.NewTemplateContext(ctx, config.GetProjectOrDirectory(r.workingDirectory())))
+	if templateErr != nil {
+		return fmt.Errorf("failed to render the templates of entry %s: %w", commandConfig.Name, exitcode.New(exitcode.ConfigError, templateErr))
+	}
+
+	// feature: default arguments of the command
+	if !r.opts.NoDefaultArgs {
+		defaultedArgs, defaultArgsErr := config.ApplyDefaultArgs(commandConfig, commandName, args[1:])
+		if defaultArgsErr != nil {
+			return fmt.Errorf("invalid default arguments of entry %s: %w", commandConfig.Name, exitcode.New(exitcode.ConfigError, defaultArgsErr))
+		}
+		args = append([]string{commandName}, defaultedArgs...)
+		commandArgs = args
+	}
+
+	// feature: passthrough to a locally installed binary
+	execution, executionErr := r.resolveExecution(commandConfig, matchType, commandName)
+	if executionErr != nil {
+		return executionErr
+	}
+	log.Debug().Bool("local", execution.Local).Str("path", execution.Path).Str("reason", execution.Reason).Msg("resolved the execution of [" + commandName + "]")
+	if execution.Local && r.opts.DryRun {
+		fmt.Fprintln(r.opts.Stdout, containerutil.FormatCommand(append([]string{execution.Path}, args[1:]...)))
+		return nil
+	}
+	if execution.Local {
+		return containerutil.ExecLocalCommand(ctx, execution.Path, args[1:], r.opts.WorkingDirectory, r.opts.Env, r.opts.Stdin, r.opts.Stdout, r.opts.Stderr)
+	}
+
+	// feature: locked image digests
+	lockedImage, lockErr := r.lockedImage(commandConfig)
+	if lockErr != nil {
+		return lockErr
+	}
+	commandConfig.Image = lockedImage
+
+	// feature: registry mirrors
+	originalImage := commandConfig.Image
+	mirroredImage, mirrorErr := r.MirroredImage(commandConfig)
+	if mirrorErr != nil {
+		return mirrorErr
+	}
+	commandConfig.Image = mirroredImage
+	info.image = commandConfig.Image
+
+	// feature: shell override
+	if r.opts.Shell != "" {
+		commandConfig.Shell = r.opts.Shell
+	}
+	if shellErr := containerutil.ValidateShell(commandConfig.Shell); shellErr != nil {
+		return fmt.Errorf("invalid shell of entry %s: %w", commandConfig.Name, exitcode.New(exitcode.ConfigError, shellErr))
+	}
+
+	// name match: run the image default command (or shell) with the remaining arguments
+	if matchType == config.MatchByName {
+		commandArgs = args[1:]
+		if len(args) == 1 && commandConfig.Shell != "" && commandConfig.Shell != "none" {
+			commandArgs = []string{commandConfig.Shell}
+			if commandConfig.LoginShell {
+				commandArgs = append(commandArgs, "-l")
+			}
+			commandConfig.Shell = "none"
+		}
+		log.Debug().Strs("args", commandArgs).Msg("Matched by image name, using the arguments as command.")
+	}
+
+	// container
+	container := &containerutil.Container{}
+	container.SetImage(commandConfig.Image)
+	container.SetEntrypoint(commandConfig.Entrypoint)
+
+	// mounts
+	mountRoot, mountRootErr := config.ResolveMountRoot(r.workingDirectory(), collection.MapGetValueOrDefault(props, "require-project", "") == "true")
+	if mountRootErr != nil {
+		return fmt.Errorf("failed to determine the directory to mount: %w", exitcode.New(exitcode.ConfigError, mountRootErr))
+	}
+	mount := config.ResolveMountPaths(mountRoot, r.workingDirectory(), commandConfig.Directory)
+	log.Debug().Str("source", mount.Source).Str("target", mount.Target).Msg("Adding volume mount")
+	container.AddVolume(containerruntime.ContainerMount{MountType: "directory", Source: mount.Source, Target: mount.Target})
+	container.SetWorkingDirectory(mount.WorkingDirectory)
+
+	// core: expose ports
+	container.AddContainerPorts(r.opts.Ports)
+
+	// core: pass environment variables, later sources take precedence: env files of the entry, passed through host variables, env of the entry, --env-file and -e
+	entryEnvFiles, envFileErr := r.loadEnvFiles(commandConfig)
+	if envFileErr != nil {
+		return envFileErr
+	}
+	container.AddEnvironmentVariables(entryEnvFiles)
+	passthrough := config.ExpandEnvPassthrough(commandConfig.EnvPassthrough, os.Environ())
+	log.Debug().Strs("variables", passthrough).Msg("passing through host environment variables")
+	for _, name := range passthrough {
+		container.AddEnvironmentVariable(name, os.Getenv(name))
+	}
+	container.AddEnvironmentVariables(commandConfig.Env)
+	optionEnvFiles, envFileErr := r.loadOptionEnvFiles()
+	if envFileErr != nil {
+		return envFileErr
+	}
+	container.AddEnvironmentVariables(optionEnvFiles)
+	container.AddEnvironmentVariables(r.opts.Env)
+
+	// feature: short-lived credentials of the host credential helpers, passed by name so that the values are never part of the run command
+	// the credential helpers aren't executed in dry-run mode, their variables are missing from the printed command
+	var credentials []string
+	if !r.opts.DryRun {
+		var credentialsErr error
+		if credentials, credentialsErr = r.loadCredentials(ctx, commandConfig, mount.Source); credentialsErr != nil {
+			return credentialsErr
+		}
+	}
+	container.PassHostEnvironment(variableNames(credentials))
+
+	// feature: container retention
+	retainContainer := r.opts.KeepContainer || commandConfig.KeepOnFailure
+	container.SetRetain(retainContainer)
+	runArgs := containerutil.ManagedLabelArgs()
+	if retainContainer {
+		runArgs = append(runArgs, containerutil.RetainedLabelArgs()...)
+	}
+
+	// feature: detached service-style commands, the readiness probe is stored as labels for envcli ps
+	readiness, readinessErr := config.GetReadinessProbe(commandConfig)
+	if readinessErr != nil {
+		return fmt.Errorf("invalid readiness of entry %s: %w", commandConfig.Name, exitcode.New(exitcode.ConfigError, readinessErr))
+	}
+	if r.opts.Detach {
+		runArgs = append(runArgs, "--detach")
+		if readiness != nil {
+			runArgs = append(runArgs, readiness.LabelArgs()...)
+		}
+	}
+
+	// feature: init process as pid 1, reaps the orphaned child processes and forwards signals (docker, podman (catatonit) and nerdctl (tini))
+	if commandConfig.UsesInit() {
+		runArgs = append(runArgs, "--init")
+	}
+
+	// feature: ulimits and security options
+	ulimits, ulimitErr := config.GetUlimits(commandConfig)
+	if ulimitErr != nil {
+		return fmt.Errorf("invalid ulimits of entry %s: %w", commandConfig.Name, exitcode.New(exitcode.ConfigError, ulimitErr))
+	}
+	for _, ulimit := range ulimits {
+		runArgs = append(runArgs, "--ulimit", ulimit)
+	}
+	securityOpts, securityErr := config.GetSecurityOpts(commandConfig)
+	if securityErr != nil {
+		return fmt.Errorf("invalid securityOpt of entry %s: %w", commandConfig.Name, exitcode.New(exitcode.ConfigError, securityErr))
+	}
+	for _, opt := range securityOpts {
+		runArgs = append(runArgs, "--security-opt", opt)
+	}
+
+	// feature: scheduling priority, the cpu and io weights of the container - on linux also the priority of the runtime client
+	priority, priorityErr := config.GetPriority(commandConfig, r.opts.Priority)
+	if priorityErr != nil {
+		return fmt.Errorf("invalid priority of entry %s: %w", commandConfig.Name, exitcode.New(exitcode.ConfigError, priorityErr))
+	}
+	runArgs = append(runArgs, priority.ContainerArgs()...)
+	container.AddArgs(runArgs...)
+
+	// feature: user args
+	container.AddArgs(userArgs...)
+
+	// feature: extra packages, installed into a derived image or at each container start if deriving is disabled
+	if packagesErr := config.ValidateExtraPackages(commandConfig.ExtraPackages); packagesErr != nil {
+		return fmt.Errorf("invalid extraPackages of entry %s: %w", commandConfig.Name, exitcode.New(exitcode.ConfigError, packagesErr))
+	}
+	deriveImage := len(commandConfig.ExtraPackages) > 0 && !r.opts.NoDerive && props["no-derive"] != "true"
+	if len(commandConfig.ExtraPackages) > 0 && !deriveImage {
+		commandConfig.BeforeScript = append([]string{containerutil.InstallScript(commandConfig.ExtraPackages)}, commandConfig.BeforeScript...)
+		if commandConfig.Shell == "" || commandConfig.Shell == "none" {
+			commandConfig.Shell = "sh"
+		}
+	}
+
+	// feature: before_script, the before scripts and the command run within the shell (sh if the entry has none)
+	if len(commandConfig.BeforeScript) > 0 && len(commandArgs) > 0 && (commandConfig.Shell == "" || commandConfig.Shell == "none") {
+		commandConfig.Shell = "sh"
+	}
+	if len(commandArgs) > 0 && commandConfig.Shell != "" && commandConfig.Shell != "none" {
+		script := containerutil.ShellJoin(commandArgs)
+		if len(commandConfig.BeforeScript) > 0 {
+			script = strings.Join(commandConfig.BeforeScript, ";") + " && " + script
+			script = strings.Replace(script, "{HTTPProxy}", collection.MapGetValueOrDefault(props, "http-proxy", ""), -1)
+			script = strings.Replace(script, "{HTTPSProxy}", collection.MapGetValueOrDefault(props, "https-proxy", ""), -1)
+		}
+		log.Debug().Str("shell", commandConfig.Shell).Msg("Setting new command with before_script: " + script)
+		commandArgs, _ = containerutil.WrapShellCommand(commandConfig.Shell, commandConfig.LoginShell, script)
+	}
+	container.SetCommand(commandArgs)
+
+	// feature: container runtime access
+	if commandConfig.ContainerRuntimeAccess {
+		container.AllowContainerRuntimeAccess()
+	}
+
+	// feature: caching
+	r.addCacheMounts(container, commandConfig)
+
+	// feature: temporary directories of the run, kept together with retained containers
+	keepTmpDirs := false
+	if len(commandConfig.TmpDirs) > 0 {
+		if tmpErr := config.ValidateTmpDirs(commandConfig.TmpDirs); tmpErr != nil {
+			return fmt.Errorf("invalid tmpDirs of entry %s: %w", commandConfig.Name, exitcode.New(exitcode.ConfigError, tmpErr))
+		}
+		tmpRoot, tmpMounts, tmpErr := createTmpDirs("", commandConfig.TmpDirs)
+		if tmpErr != nil {
+			return fmt.Errorf("failed to create the temporary directories: %w", tmpErr)
+		}
+		defer func() {
+			if keepTmpDirs {
+				fmt.Fprintf(r.opts.Stderr, "Temporary directories have been retained in %s\n", tmpRoot)
+			} else if removeErr := os.RemoveAll(tmpRoot); removeErr != nil {
+				log.Warn().Err(removeErr).Str("dir", tmpRoot).Msg("failed to remove the temporary directories")
+			}
+		}()
+		for _, tmpMount := range tmpMounts {
+			log.Debug().Str("source", tmpMount.Source).Str("target", tmpMount.Target).Msg("Adding temporary directory")
+			container.AddVolume(tmpMount)
+		}
+		container.AddEnvironmentVariable(TmpDirEnv, tmpMounts[0].Target)
+	}
+
+	// feature: capabilities
+	for _, cap := range commandConfig.CapAdd {
+		container.AddCapability(cap)
+	}
+
+	// feature: git identity
+	if commandConfig.ForwardGitConfig || collection.MapGetValueOrDefault(props, "forward-git-config", "") == "true" {
+		forwardGitConfig(container)
+	}
+
+	// feature: ssh agent
+	if commandConfig.ForwardSSHAgent {
+		socket, socketErr := containerutil.ResolveSSHAgentSocket(goruntime.GOOS, os.Getenv, containerutil.FileExists)
+		if socketErr != nil && commandConfig.SSHAgentRequired {
+			return fmt.Errorf("ssh agent forwarding is required: %w", exitcode.New(exitcode.ConfigError, socketErr))
+		} else if socketErr != nil {
+			log.Warn().Err(socketErr).Msg("ssh agent forwarding is not available")
+		} else {
+			log.Debug().Str("source", socket.Source).Str("target", socket.Target).Msg("forwarding ssh agent")
+			container.AddVolume(containerruntime.ContainerMount{MountType: "directory", Source: socket.Source, Target: socket.Target})
+			container.AddEnvironmentVariable("SSH_AUTH_SOCK", socket.Target)
+		}
+	}
+
+	// feature: pass all env variables (excludes system variables like PATH, ...) in CI environments
+	if cihelper.IsCIEnvironment() {
+		container.AddAllEnvironmentVariables()
+	}
+
+	// feature: proxy environment
+	r.addProxyEnvironment(container)
+
+	// container service and render the run command
+	runtime := r.runtime()
+	if runtimeErr := containerutil.RequireRuntime(runtime); runtimeErr != nil {
+		return runtimeErr
+	}
+	// feature: wait for the daemon, ex. if docker desktop is still starting
+	if !r.opts.DryRun {
+		if daemonErr := r.waitForDaemon(ctx, runtime); daemonErr != nil {
+			return daemonErr
+		}
+	}
+	if versionErr := checkRuntimeVersion(ctx, runtime, commandConfig, append(runArgs, userArgs...)); versionErr != nil {
+		return versionErr
+	}
+	// feature: readable container names, used in the log lines and by envcli ps
+	container.SetName(containerutil.ContainerName(filepath.Base(mount.Source), commandName))
+	renderRunCommand := func() ([]string, error) {
+		runCommand, err := containerutil.RenderRunCommand(ctx, runtime, container)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render the container run command: %w", err)
+		}
+		return containerutil.WrapHostPriority(goruntime.GOOS, runCommand, priority.Nice, priority.IOClass, priority.IOLevel, exec.LookPath), nil
+	}
+	runCommand, runCommandErr := renderRunCommand()
+	if runCommandErr != nil {
+		return runCommandErr
+	}
+	if r.opts.DryRun {
+		fmt.Fprintln(r.opts.Stdout, containerutil.FormatCommand(runCommand))
+		return nil
+	}
+
+	// feature: capture the command output into a log file
+	stdout := r.opts.Stdout
+	stderr := r.opts.Stderr
+	logDirectory := collection.MapGetValueOrDefault(props, "log-directory", "")
+	if r.opts.LogFile != "" || logDirectory != "" {
+		runLog, runLogErr := runlog.Open(r.opts.LogFile, logDirectory, runlog.Header{Command: strings.Join(args, " "), Image: commandConfig.Image, Started: time.Now()})
+		if runLogErr != nil {
+			return fmt.Errorf("failed to create the log file: %w", runLogErr)
+		}
+		defer runLog.Close()
+		stdout = io.MultiWriter(stdout, runLog)
+		stderr = io.MultiWriter(stderr, runLog)
+
+		if r.opts.LogFile == "" {
+			r.pruneRunLogs(logDirectory)
+		}
+	}
+
+	// feature: ci annotations, the last line of the output is used as error annotation
+	ciProvider, ciProviderErr := ciannotation.Resolve(r.opts.CIAnnotations, os.Getenv)
+	if ciProviderErr != nil {
+		return exitcode.New(exitcode.ConfigError, ciProviderErr)
+	}
+	lastLine := &ciannotation.LastLine{}
+	if ciProvider != ciannotation.ProviderOff {
+		stdout = io.MultiWriter(stdout, lastLine)
+		stderr = io.MultiWriter(stderr, lastLine)
+	}
+
+	// feature: guidance if the disk of the container runtime (ex. the Docker Desktop VM) is full
+	outOfSpace := &containerutil.OutOfSpaceDetector{}
+	stdout, stderr = io.MultiWriter(stdout, outOfSpace), io.MultiWriter(stderr, outOfSpace)
+
+	// feature: rewrite the container project path in the output, also within the log file
+	var rewriters []*pathrewrite.Writer
+	if commandConfig.RewritePaths && mount.Target != mount.Source {
+		stdoutRewriter := pathrewrite.NewWriter(stdout, mount.Target, mount.Source, filepath.Separator)
+		stderrRewriter := pathrewrite.NewWriter(stderr, mount.Target, mount.Source, filepath.Separator)
+		stdout, stderr = stdoutRewriter, stderrRewriter
+		rewriters = append(rewriters, stdoutRewriter, stderrRewriter)
+	}
+
+	// feature: retries
+	retryPolicy, retryPolicyErr := config.GetRetryPolicy(commandConfig)
+	if retryPolicyErr != nil {
+		return fmt.Errorf("invalid retry configuration of entry %s: %w", commandConfig.Name, exitcode.New(exitcode.ConfigError, retryPolicyErr))
+	}
+	if r.opts.Retries != nil {
+		retryPolicy.Retries = *r.opts.Retries
+	}
+
+	// feature: hooks executed on the host
+	hooks, hooksErr := config.GetProjectHooksIn(r.workingDirectory())
+	if hooksErr != nil {
+		return fmt.Errorf("failed to load the hooks: %w", exitcode.New(exitcode.ConfigError, hooksErr))
+	}
+	if !hooks.IsEmpty() {
+		if trustErr := r.checkHookTrust(hooks); trustErr != nil {
+			return trustErr
+		}
+	}
+
+	// pull or build the image if missing, to distinguish image failures from command failures
+	info.timings.Config = time.Since(started)
+	if commandConfig.IsBuild() {
+		dockerfile, contextDir := config.GetBuildPaths(commandConfig)
+		buildStarted := time.Now()
+		if buildErr := containerutil.EnsureBuiltImage(ctx, runtime, commandConfig.Image, dockerfile, contextDir, r.opts.Rebuild, r.opts.Stderr); buildErr != nil {
+			return buildErr
+		}
+		info.timings.Built, info.timings.Pull = true, time.Since(buildStarted)
+	} else {
+		pulledImage, pullErr := r.withMirrorFallback(commandConfig.Image, originalImage, func(image string) error {
+			return ensureImage(ctx, runtime, image, &info.timings)
+		})
+		if pullErr != nil {
+			return pullErr
+		}
+		if pulledImage != commandConfig.Image {
+			// mirror fallback, the container uses the original image
+			commandConfig.Image, info.image = pulledImage, pulledImage
+			container.SetImage(pulledImage)
+			if runCommand, runCommandErr = renderRunCommand(); runCommandErr != nil {
+				return runCommandErr
+			}
+		}
+	}
+
+	// feature: image signatures required by the policy of the global configuration
+	if signatureErr := r.verifySignature(ctx, runtime, commandConfig); signatureErr != nil {
+		return signatureErr
+	}
+
+	// feature: unshared directories of Docker Desktop on macOS are mounted as empty directories
+	// the probe starts a container of the image, it only runs once the signature of the image has been verified
+	if sharingErr := r.probeFileSharing(ctx, runtime, commandConfig.Image, mount.Source); sharingErr != nil {
+		return sharingErr
+	}
+
+	// the derived image is based on the verified image
+	if deriveImage {
+		derivedImage, deriveErr := containerutil.EnsureDerivedImage(ctx, runtime, commandConfig.Image, commandConfig.ExtraPackages, r.opts.Stderr)
+		if deriveErr != nil {
+			return deriveErr
+		}
+		commandConfig.Image = derivedImage
+		container.SetImage(derivedImage)
+		if runCommand, runCommandErr = renderRunCommand(); runCommandErr != nil {
+			return runCommandErr
+		}
+	}
+
+	if hookErr := r.runHook(ctx, hooks, "preRun", hooks.PreRun, hookEnvironment(commandName, commandConfig.Image, nil)); hookErr != nil {
+		return fmt.Errorf("preRun hook failed: %w", hookErr)
+	}
+
+	// feature: timings, the startup of the container is measured until the first output of the command
+	firstOutput := &firstOutput{}
+	stdout, stderr = firstOutput.wrap(stdout), firstOutput.wrap(stderr)
+
+	// send command
+	// the credentials are only added to the environment of the runtime client, the runs of run --parallel don't share them
+	runRuntime := containerutil.WithEnvironment(runtime, credentials)
+	sectionStarted := time.Now()
+	ciannotation.StartSection(r.opts.Stdout, ciProvider, commandName, "envcli: "+strings.Join(args, " "), sectionStarted)
+	execErr := containerutil.RunWithRetry(retryPolicy, func(attempt int) error {
+		if attempt > 1 && retainContainer {
+			// the container of the failed attempt blocks the container name
+			_ = containerutil.RemoveContainer(ctx, runtime, container.GetName())
+		}
+
+		log.Info().Int("attempt", attempt).Str("container", container.GetName()).Msg("Executing command in container [" + commandConfig.Image + "].")
+		name, err := containerutil.ExecWithUniqueName(ctx, runRuntime, runCommand, container.GetName(), r.opts.Stdin, stdout, stderr)
+		for _, rewriter := range rewriters {
+			_ = rewriter.Flush()
+		}
+		if name != container.GetName() {
+			runCommand = containerutil.RenameContainer(runCommand, container.GetName(), name)
+			container.SetName(name)
+		}
+		return err
+	}, func(delay time.Duration) {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	})
+	info.timings.Execution = time.Since(sectionStarted)
+	if first := firstOutput.time(); !first.IsZero() {
+		info.timings.Startup = first.Sub(sectionStarted)
+	}
+	// a detached container keeps running even if it doesn't become ready, to allow inspecting its logs
+	detached := r.opts.Detach && execErr == nil
+	if detached && readiness != nil {
+		fmt.Fprintf(r.opts.Stderr, "Waiting up to %s for container [%s] to become ready ...\n", readiness.Timeout, container.GetName())
+		if readyErr := readiness.WaitReady(ctx, runtime, container.GetName()); readyErr != nil {
+			execErr = exitcode.New(exitcode.Timeout, readyErr)
+		} else {
+			fmt.Fprintf(r.opts.Stderr, "Container [%s] is ready\n", container.GetName())
+		}
+	}
+	ciannotation.EndSection(r.opts.Stdout, ciProvider, commandName, fmt.Sprintf("%s finished after %s with exit code %d", commandName, info.timings.Execution.Round(time.Millisecond), exitcode.Of(execErr)), time.Now())
+	if execErr != nil && outOfSpace.Detected() {
+		containerutil.WriteOutOfSpaceGuidance(ctx, r.opts.Stderr, runtime)
+	}
+	if execErr != nil {
+		workspace := os.Getenv("GITHUB_WORKSPACE")
+		if workspace == "" {
+			workspace = mount.Source
+		}
+		annotation := ciannotation.ParseAnnotation(lastLine.String(), workspace)
+		if annotation.Message == "" {
+			annotation.Message = fmt.Sprintf("%s failed with exit code %d", commandName, exitcode.Of(execErr))
+		}
+		ciannotation.WriteAnnotation(r.opts.Stdout, ciProvider, annotation)
+	}
+	if detached {
+		fmt.Fprintf(r.opts.Stderr, "Container [%s] is running in the background, stop it using: %s stop %s\n", container.GetName(), runtime.Name(), container.GetName())
+	} else if retainContainer && execErr == nil && !r.opts.KeepContainer {
+		log.Debug().Str("container", container.GetName()).Msg("command succeeded, removing container")
+		_ = containerutil.RemoveContainer(ctx, runtime, container.GetName())
+	} else if retainContainer {
+		keepTmpDirs = true
+		fmt.Fprintf(r.opts.Stderr, "Container [%s] has been retained, inspect it using:\n", container.GetName())
+		fmt.Fprintf(r.opts.Stderr, "  %s start %s && %s exec -it %s sh\n", runtime.Name(), container.GetName(), runtime.Name(), container.GetName())
+		fmt.Fprintf(r.opts.Stderr, "Remove retained containers using: envcli cleanup\n")
+	}
+
+	// the exit code of the command is passed through, a failing post-run hook is only reported
+	exitCode := exitcode.Of(execErr)
+	info.timings.Total = time.Since(started)
+	if r.opts.RecordFile != "" || r.opts.Summary != "" || r.auditTarget() != "" {
+		digest, digestErr := containerutil.ImageID(ctx, runtime, commandConfig.Image)
+		if digestErr != nil {
+			log.Warn().Err(digestErr).Str("image", commandConfig.Image).Msg("failed to resolve the image digest")
+		}
+		info.digest = digest
+	}
+	if r.opts.RecordFile != "" {
+		r.recordRun(ctx, runtime, args, commandConfig.Image, info.digest, container, mount.Source, exitCode, info.timings)
+	}
+	if hookErr := r.runHook(ctx, hooks, "postRun", hooks.PostRun, hookEnvironment(commandName, commandConfig.Image, &exitCode)); hookErr != nil {
+		log.Warn().Err(hookErr).Msg("postRun hook failed")
+	}
+
+	return execErr
+}
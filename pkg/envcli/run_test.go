@@ -0,0 +1,1187 @@
+package envcli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	goruntime "runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+)
+
+// recordingRuntime records the commands instead of executing them
+type recordingRuntime struct {
+	name     string
+	commands []string
+	mutex    sync.Mutex
+
+	// outputs are returned by Output for the commands containing the key
+	outputs map[string]string
+
+	// runOutput is written to stdout by the container runs
+	runOutput string
+
+	// runErr is returned by the container runs
+	runErr error
+}
+
+// executedRuns returns the recorded container runs
+func (r *recordingRuntime) executedRuns() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var runs []string
+	for _, command := range r.commands {
+		if strings.Contains(command, " run ") {
+			runs = append(runs, command)
+		}
+	}
+	return runs
+}
+
+func (r *recordingRuntime) Name() string {
+	return r.name
+}
+
+func (r *recordingRuntime) Exec(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	command := containerutil.FormatCommand(args)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.commands = append(r.commands, command)
+	if r.runOutput != "" && strings.Contains(command, " run ") {
+		_, _ = io.WriteString(stdout, r.runOutput)
+	}
+	if strings.Contains(command, " run ") {
+		return r.runErr
+	}
+	return nil
+}
+
+func (r *recordingRuntime) Output(ctx context.Context, args []string) (string, error) {
+	command := containerutil.FormatCommand(args)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.commands = append(r.commands, command)
+	for key, output := range r.outputs {
+		if strings.Contains(command, key) {
+			return output, nil
+		}
+	}
+	return "", nil
+}
+
+// blockingRuntime blocks the commands containing block until the context is cancelled and fails the commands containing fail
+type blockingRuntime struct {
+	block    string
+	fail     string
+	commands []string
+}
+
+func (r *blockingRuntime) Name() string {
+	return "docker"
+}
+
+func (r *blockingRuntime) Exec(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	_, err := r.Output(ctx, args)
+	return err
+}
+
+func (r *blockingRuntime) Output(ctx context.Context, args []string) (string, error) {
+	command := containerutil.FormatCommand(args)
+	r.commands = append(r.commands, command)
+	if r.fail != "" && strings.Contains(command, r.fail) {
+		return "", errors.New("exit status 1")
+	}
+	if strings.Contains(command, r.block) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return "", nil
+}
+
+// streamingRuntime runs a host command producing size bytes of output instead of the container
+type streamingRuntime struct {
+	size int64
+}
+
+func (r *streamingRuntime) Name() string {
+	return "docker"
+}
+
+func (r *streamingRuntime) Exec(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	command := containerutil.FormatCommand(args)
+	if !strings.Contains(command, " run ") {
+		return nil
+	}
+	return containerutil.ExecArgsWithIO(ctx, []string{"sh", "-c", fmt.Sprintf("yes /project/src/main.go:1: output | head -c %d", r.size)}, nil, stdout, stderr)
+}
+
+func (r *streamingRuntime) Output(ctx context.Context, args []string) (string, error) {
+	return "", nil
+}
+
+// chdirProject changes into a temporary project with the given configuration
+func chdirProject(t *testing.T, content string) string {
+	t.Helper()
+	t.Setenv("CI", "false")
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".envcli.yml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(previous) })
+
+	return dir
+}
+
+func TestRunnerRun(t *testing.T) {
+	dir := chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n")
+	runtime := &recordingRuntime{name: "podman"}
+	retries := 0
+
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Env: []string{"A=B"}, Retries: &retries, Stdout: &bytes.Buffer{}})
+	code, err := runner.Run(context.Background(), "echo", []string{"hello world"})
+	if code != exitcode.Success || err != nil {
+		t.Fatalf("expected success, got %d (%v)", code, err)
+	}
+
+	if len(runtime.commands) != 2 || runtime.commands[0] != "podman image inspect alpine:latest" {
+		t.Fatalf("unexpected commands %v", runtime.commands)
+	}
+	for _, expected := range []string{"podman run --rm", "--name envcli-" + filepath.Base(dir) + "-echo-", "--label envcli.managed=true", "-e A=B", "-v " + dir + ":", `alpine:latest echo "hello world"`} {
+		if !strings.Contains(runtime.commands[1], expected) {
+			t.Errorf("expected %s in %s", expected, runtime.commands[1])
+		}
+	}
+}
+
+func TestRunnerSecurity(t *testing.T) {
+	dir := chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n    ulimits:\n      nofile: 1024:65535\n      nproc: \"512\"\n    securityOpt:\n      - seccomp=profile.json\n      - no-new-privileges\n")
+	runtime := &recordingRuntime{name: "podman"}
+	retries := 0
+
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Retries: &retries, Stdout: &bytes.Buffer{}})
+	if code, err := runner.Run(context.Background(), "echo", nil); code != exitcode.Success || err != nil {
+		t.Fatalf("expected success, got %d (%v)", code, err)
+	}
+
+	runs := runtime.executedRuns()
+	if len(runs) != 1 {
+		t.Fatalf("unexpected runs %v", runs)
+	}
+	for _, expected := range []string{"--ulimit nofile=1024:65535 --ulimit nproc=512", "--security-opt seccomp=" + filepath.Join(dir, "profile.json"), "--security-opt no-new-privileges"} {
+		if !strings.Contains(runs[0], expected) {
+			t.Errorf("expected %s in %s", expected, runs[0])
+		}
+	}
+}
+
+func TestRunnerRewritePaths(t *testing.T) {
+	dir := chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n    directory: /project\n    rewritePaths: true\n")
+	runtime := &recordingRuntime{name: "podman", runOutput: "/project/src/main.go:3: error\n"}
+	stdout := &bytes.Buffer{}
+
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Stdout: stdout})
+	if code, err := runner.Run(context.Background(), "echo", nil); code != exitcode.Success || err != nil {
+		t.Fatalf("expected success, got %d (%v)", code, err)
+	}
+
+	expected := filepath.Join(dir, "src", "main.go") + ":3: error\n"
+	if stdout.String() != expected {
+		t.Errorf("expected %q, got %q", expected, stdout.String())
+	}
+}
+
+func TestRunnerCIAnnotations(t *testing.T) {
+	t.Setenv("GITHUB_WORKSPACE", "")
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n    directory: /project\n    rewritePaths: true\n")
+	runtime := &recordingRuntime{name: "podman", runOutput: "compiling\n/project/src/main.go:3: boom\n", runErr: errors.New("exit status 1")}
+	retries := 0
+	stdout := &bytes.Buffer{}
+
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Retries: &retries, CIAnnotations: "github", Stdout: stdout, Stderr: &bytes.Buffer{}})
+	if _, err := runner.Run(context.Background(), "echo", []string{"hi"}); err == nil {
+		t.Fatalf("expected the run to fail")
+	}
+
+	for _, expected := range []string{"::group::envcli: echo hi\n", "echo finished after ", "::endgroup::\n", "::error file=src/main.go,line=3::boom\n"} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("expected %q in %q", expected, stdout.String())
+		}
+	}
+}
+
+func TestRunnerLockFile(t *testing.T) {
+	dir := chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n")
+	runtime := &recordingRuntime{name: "podman"}
+	retries := 0
+
+	// frozen requires a lock file
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Retries: &retries, Frozen: true, Stdout: &bytes.Buffer{}})
+	if code, _ := runner.Run(context.Background(), "echo", nil); code != exitcode.ConfigError {
+		t.Errorf("expected exit code %d without lock file, got %d", exitcode.ConfigError, code)
+	}
+
+	lock := config.LockFile{Images: []config.LockedImage{{Name: "alpine", Image: "alpine:latest", Digest: "sha256:abc"}}}
+	if err := config.WriteLockFile(config.GetLockFilePath(dir), lock); err != nil {
+		t.Fatal(err)
+	}
+	if code, err := runner.Run(context.Background(), "echo", nil); code != exitcode.Success || err != nil {
+		t.Fatalf("expected success, got %d (%v)", code, err)
+	}
+	runs := runtime.executedRuns()
+	if len(runs) != 1 || !strings.Contains(runs[0], "alpine:latest@sha256:abc") {
+		t.Errorf("expected the locked digest to be used, got %v", runs)
+	}
+}
+
+func TestRunnerErrors(t *testing.T) {
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n")
+
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: &recordingRuntime{name: "unknown"}})
+	if code, _ := runner.Run(context.Background(), "echo", nil); code != exitcode.RuntimeUnavailable {
+		t.Errorf("expected exit code %d, got %d", exitcode.RuntimeUnavailable, code)
+	}
+	if code, _ := runner.Run(context.Background(), "unknown-command", nil); code != exitcode.ConfigError {
+		t.Errorf("expected exit code %d, got %d", exitcode.ConfigError, code)
+	}
+}
+
+func TestRunnerCancel(t *testing.T) {
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n")
+
+	var tests = []*blockingRuntime{
+		{block: "docker pull ", fail: "image inspect"},
+		{block: "docker run "},
+	}
+
+	for _, runtime := range tests {
+		retries := 3
+		runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Retries: &retries})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		started := time.Now()
+		code, err := runner.Run(ctx, "echo", nil)
+		if code != exitcode.Interrupted || !errors.Is(err, context.Canceled) {
+			t.Errorf("%s: expected exit code %d, got %d (%v)", runtime.block, exitcode.Interrupted, code, err)
+		}
+		if elapsed := time.Since(started); elapsed > 5*time.Second {
+			t.Errorf("%s: expected the runner to stop promptly, took %s", runtime.block, elapsed)
+		}
+		if last := runtime.commands[len(runtime.commands)-1]; !strings.HasPrefix(last, runtime.block) || len(runtime.commands) != 2 {
+			t.Errorf("%s: expected the cancelled command not to be retried, got %v", runtime.block, runtime.commands)
+		}
+	}
+}
+
+func TestRunnerHooks(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	dir := chdirProject(t, "hooks:\n  preRun: echo \"$ENVCLI_COMMAND $ENVCLI_IMAGE\" > pre.txt\n  postRun: echo \"$ENVCLI_EXIT_CODE\" > post.txt\nimages:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n")
+	config.SetConfigurationDirectory(t.TempDir())
+	t.Cleanup(func() { config.SetConfigurationDirectory(config.UserConfigurationDirectory()) })
+
+	// untrusted
+	runtime := &recordingRuntime{name: "docker"}
+	code, _ := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime}).Run(context.Background(), "echo", nil)
+	if code != exitcode.ConfigError || len(runtime.commands) != 0 {
+		t.Fatalf("expected untrusted hooks to be refused, got %d and commands %v", code, runtime.commands)
+	}
+
+	// trusted after the confirmation
+	confirmed := 0
+	confirm := func(hooks config.ProjectHooks) bool {
+		confirmed++
+		return true
+	}
+	for i := 0; i < 2; i++ {
+		code, err := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, ConfirmTrust: confirm, Stdout: &bytes.Buffer{}}).Run(context.Background(), "echo", nil)
+		if code != exitcode.Success {
+			t.Fatalf("expected success, got %d (%v)", code, err)
+		}
+	}
+	if confirmed != 1 {
+		t.Errorf("expected a single confirmation, got %d", confirmed)
+	}
+
+	for file, expected := range map[string]string{"pre.txt": "echo alpine:latest\n", "post.txt": "0\n"} {
+		if content, err := os.ReadFile(filepath.Join(dir, file)); err != nil || string(content) != expected {
+			t.Errorf("%s: expected %q, got %q (%v)", file, expected, string(content), err)
+		}
+	}
+}
+
+func TestRunnerBuild(t *testing.T) {
+	dir := chdirProject(t, "images:\n  - name: node\n    build:\n      dockerfile: Dockerfile.node\n    provides:\n      - node\n")
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile.node"), []byte("FROM node:20\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rebuild := range []bool{false, true} {
+		runtime := &recordingRuntime{name: "docker"}
+		runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Rebuild: rebuild, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}})
+		if code, err := runner.Run(context.Background(), "node", nil); code != exitcode.Success {
+			t.Fatalf("expected success, got %d (%v)", code, err)
+		}
+
+		// the image exists, it is only built again using rebuild
+		expected := "docker image inspect envcli-build/"
+		if rebuild {
+			expected = "docker build -t envcli-build/"
+		}
+		if len(runtime.commands) != 2 || !strings.HasPrefix(runtime.commands[0], expected) || !strings.Contains(runtime.commands[1], " envcli-build/") {
+			t.Errorf("rebuild %t: unexpected commands %v", rebuild, runtime.commands)
+		}
+		if rebuild && !strings.Contains(runtime.commands[0], "-f "+filepath.Join(dir, "Dockerfile.node")+" "+dir) {
+			t.Errorf("expected the dockerfile and context in %s", runtime.commands[0])
+		}
+	}
+}
+
+func TestRunnerPassthrough(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("the local binary is a shell script")
+	}
+	chdirProject(t, "images:\n  - name: tools\n    image: tools:1.0\n    passthrough: prefer-local\n    provides:\n      - localtool\n      - missingtool\n  - name: strict\n    image: strict:1.0\n    passthrough: local-only\n    provides:\n      - stricttool\n")
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "localtool"), []byte("#!/bin/sh\necho \"local $@\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	runtime := &recordingRuntime{name: "docker"}
+	stdout := &bytes.Buffer{}
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Stdout: stdout})
+
+	// prefer-local runs the installed binary with the original arguments
+	if code, err := runner.Run(context.Background(), "localtool", []string{"a b", "c"}); err != nil || code != exitcode.Success {
+		t.Fatalf("unexpected result %d (%v)", code, err)
+	}
+	if stdout.String() != "local a b c\n" || len(runtime.executedRuns()) != 0 {
+		t.Errorf("expected the local binary to run, got %q and %v", stdout.String(), runtime.commands)
+	}
+
+	// prefer-local falls back to the container
+	if _, err := runner.Run(context.Background(), "missingtool", nil); err != nil || len(runtime.executedRuns()) != 1 {
+		t.Errorf("expected a container run, got %v (%v)", runtime.commands, err)
+	}
+
+	// local-only fails if the binary isn't installed
+	if code, _ := runner.Run(context.Background(), "stricttool", nil); code != exitcode.RuntimeUnavailable {
+		t.Errorf("expected exit code %d, got %d", exitcode.RuntimeUnavailable, code)
+	}
+}
+
+func TestRunnerWatch(t *testing.T) {
+	dir := chdirProject(t, "images:\n  - name: golang\n    image: golang:1.21\n    provides:\n      - go\n")
+	runtime := &recordingRuntime{name: "docker"}
+	stderr := &bytes.Buffer{}
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Stdout: &bytes.Buffer{}, Stderr: stderr})
+
+	// interactive shells are refused
+	if err := runner.Watch(context.Background(), "golang", nil, WatchOptions{Patterns: []string{"**/*.go"}}); exitcode.Of(err) != exitcode.ConfigError {
+		t.Errorf("expected interactive commands to be refused, got %v", err)
+	}
+	interactive := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, UserArgs: []string{"--rm -it"}, Stdout: &bytes.Buffer{}, Stderr: stderr})
+	if err := interactive.Watch(context.Background(), "go", []string{"test"}, WatchOptions{Patterns: []string{"**/*.go"}}); exitcode.Of(err) != exitcode.ConfigError {
+		t.Errorf("expected runs attaching the terminal to be refused, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		// wait for the initial run, then modify a watched file
+		for len(runtime.executedRuns()) < 1 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		_ = os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0644)
+		for len(runtime.executedRuns()) < 2 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		cancel()
+	}()
+
+	err := runner.Watch(ctx, "go", []string{"test", "./..."}, WatchOptions{Patterns: []string{"**/*.go"}, Interval: 10 * time.Millisecond, Debounce: 20 * time.Millisecond})
+	if err != nil || ctx.Err() != context.Canceled {
+		t.Fatalf("expected watch to stop cleanly once cancelled, got %v (%v)", err, ctx.Err())
+	}
+	if !strings.Contains(stderr.String(), "changed: main.go") {
+		t.Errorf("expected a divider with the changed files, got %q", stderr.String())
+	}
+}
+
+func TestRunnerRecordReplay(t *testing.T) {
+	dir := chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n")
+	file := filepath.Join(t.TempDir(), "record.jsonl")
+	runtime := &recordingRuntime{name: "docker", outputs: map[string]string{"{{.Id}}": "sha256:aaa"}}
+
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Env: []string{"TOKEN=s3cret"}, RecordFile: file, Stdout: &bytes.Buffer{}})
+	if _, err := runner.Run(context.Background(), "echo", []string{"hello"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "s3cret") || !strings.Contains(string(data), `"env":["TOKEN"]`) || !strings.Contains(string(data), `"digest":"sha256:aaa"`) || !strings.Contains(string(data), `"imageLocal":true`) {
+		t.Errorf("unexpected record %s", data)
+	}
+
+	// the image changed since the recording
+	replayRuntime := &recordingRuntime{name: "docker", outputs: map[string]string{"{{.Id}}": "sha256:bbb"}}
+	replayer := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: replayRuntime, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}})
+	divergences, err := replayer.Replay(context.Background(), file)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(divergences) != 1 || !strings.Contains(divergences[0].Message, "recorded sha256:aaa, now sha256:bbb") {
+		t.Errorf("expected the changed digest to be reported, got %v", divergences)
+	}
+	runs := replayRuntime.executedRuns()
+	if len(runs) != 1 || !strings.Contains(runs[0], "-e TOKEN ") || !strings.Contains(runs[0], dir) {
+		t.Errorf("unexpected replayed runs %v", runs)
+	}
+}
+
+func TestRunnerEnvFile(t *testing.T) {
+	dir := chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    envFile:\n      - path: .env\n      - path: .env.local\n        optional: true\n    env:\n      - OVERRIDDEN=entry\n    provides:\n      - echo\n")
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("export FROM_FILE=\"file value\"\nOVERRIDDEN=file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	flagFile := filepath.Join(t.TempDir(), "flag.env")
+	if err := os.WriteFile(flagFile, []byte("FROM_FLAG=flag\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	runtime := &recordingRuntime{name: "docker"}
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, EnvFiles: []string{flagFile}, Stdout: &bytes.Buffer{}})
+	if _, err := runner.Run(context.Background(), "echo", []string{"hello"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	runs := runtime.executedRuns()
+	if len(runs) != 1 {
+		t.Fatalf("expected a single run, got %v", runs)
+	}
+	for _, expected := range []string{`-e "FROM_FILE=file value"`, "-e FROM_FLAG=flag"} {
+		if !strings.Contains(runs[0], expected) {
+			t.Errorf("expected %s within the run command, got %s", expected, runs[0])
+		}
+	}
+	// the runtime takes the last value of a variable
+	if strings.LastIndex(runs[0], `OVERRIDDEN="entry"`) < strings.LastIndex(runs[0], `OVERRIDDEN="file"`) {
+		t.Errorf("expected the env of the entry to override the env file, got %s", runs[0])
+	}
+
+	// missing required file
+	runner = NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, EnvFiles: []string{filepath.Join(dir, "missing.env")}, Stdout: &bytes.Buffer{}})
+	if code, err := runner.Run(context.Background(), "echo", []string{"hello"}); code != exitcode.ConfigError || err == nil {
+		t.Errorf("expected a config error for a missing env file, got %d (%v)", code, err)
+	}
+}
+
+func TestRunnerSummary(t *testing.T) {
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n")
+	lastLine := func(output string) string {
+		lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+		return lines[len(lines)-1]
+	}
+
+	stderr := &bytes.Buffer{}
+	runtime := &recordingRuntime{name: "docker", outputs: map[string]string{"{{.Id}}": "sha256:aaa"}}
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Summary: SummaryFormatText, Timings: true, Stdout: &bytes.Buffer{}, Stderr: stderr})
+	if _, err := runner.Run(context.Background(), "echo", []string{"hello"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if line := lastLine(stderr.String()); !strings.HasPrefix(line, "ENVCLI_RESULT command=echo entry=alpine image=alpine:latest digest=sha256:aaa exit=0 duration=") {
+		t.Errorf("unexpected summary line %q", line)
+	}
+
+	// the error is logged before the summary and not again by the caller
+	stderr.Reset()
+	runtime = &recordingRuntime{name: "docker", runErr: exitcode.New(3, errors.New("exit status 3"))}
+	runner = NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Summary: SummaryFormatJSON, Stdout: &bytes.Buffer{}, Stderr: stderr})
+	code, err := runner.Run(context.Background(), "echo", []string{"hello"})
+	if code != 3 || !exitcode.IsSilent(err) {
+		t.Errorf("expected a silent error with exit code 3, got %d (%v)", code, err)
+	}
+	var result map[string]interface{}
+	if jsonErr := json.Unmarshal([]byte(lastLine(stderr.String())), &result); jsonErr != nil || result["exit"] != float64(3) || result["image"] != "alpine:latest" {
+		t.Errorf("unexpected summary line %q (%v)", lastLine(stderr.String()), jsonErr)
+	}
+}
+
+func TestRunnerDocsURL(t *testing.T) {
+	chdirProject(t, "images:\n  - name: helm\n    image: alpine/helm:3\n    docsUrl: https://wiki.company.com/helm\n    provides:\n      - helm\n")
+
+	stderr := &bytes.Buffer{}
+	runtime := &recordingRuntime{name: "docker"}
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Stdout: &bytes.Buffer{}, Stderr: stderr})
+	if _, err := runner.Run(context.Background(), "helm", nil); err != nil || strings.Contains(stderr.String(), "wiki.company.com") {
+		t.Errorf("expected no documentation for a successful run, got %q (%v)", stderr.String(), err)
+	}
+
+	// the error is logged before the documentation and not again by the caller
+	runtime.runErr = exitcode.New(1, errors.New("exit status 1"))
+	code, err := runner.Run(context.Background(), "helm", []string{"install"})
+	if code != 1 || !exitcode.IsSilent(err) {
+		t.Errorf("expected a silent error with exit code 1, got %d (%v)", code, err)
+	}
+	if !strings.HasSuffix(stderr.String(), "See the documentation of helm: https://wiki.company.com/helm\n") {
+		t.Errorf("unexpected epilogue %q", stderr.String())
+	}
+}
+
+func TestRunnerTimings(t *testing.T) {
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n")
+
+	// local image
+	stderr := &bytes.Buffer{}
+	runtime := &recordingRuntime{name: "docker", runOutput: "hello\n"}
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Timings: true, Stdout: &bytes.Buffer{}, Stderr: stderr})
+	if _, err := runner.Run(context.Background(), "echo", []string{"hello"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, expected := range []string{"envcli timings:", "image      local", "startup", "execution", "total"} {
+		if !strings.Contains(stderr.String(), expected) {
+			t.Errorf("expected %q within the timings, got %q", expected, stderr.String())
+		}
+	}
+
+	// pulled image
+	stderr.Reset()
+	pullRuntime := &blockingRuntime{block: "never", fail: "inspect alpine"}
+	runner = NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: pullRuntime, Timings: true, Stdout: &bytes.Buffer{}, Stderr: stderr})
+	if _, err := runner.Run(context.Background(), "echo", []string{"hello"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if !strings.Contains(stderr.String(), "image      pulled in") {
+		t.Errorf("expected the pull within the timings, got %q", stderr.String())
+	}
+}
+
+func TestRunnerImageMirror(t *testing.T) {
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n")
+	properties := &config.PropertyConfigurationFile{Properties: map[string]string{"registry-mirrors": "docker.io=mirror.company.com"}}
+
+	// the run uses the mirror
+	runtime := &recordingRuntime{name: "docker"}
+	runner := NewRunner(Options{Properties: properties, Runtime: runtime, Stdout: &bytes.Buffer{}})
+	if _, err := runner.Run(context.Background(), "echo", []string{"hello"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if runs := runtime.executedRuns(); len(runs) != 1 || !strings.Contains(runs[0], "mirror.company.com/library/alpine:latest") {
+		t.Errorf("expected the run to use the mirror, got %v", runs)
+	}
+
+	// the mirror fails without fallback
+	mirrorRuntime := &blockingRuntime{block: "never", fail: "mirror.company.com"}
+	runner = NewRunner(Options{Properties: properties, Runtime: mirrorRuntime, Stdout: &bytes.Buffer{}})
+	if _, err := runner.Run(context.Background(), "echo", []string{"hello"}); exitcode.Of(err) != exitcode.ImagePullFailure {
+		t.Errorf("expected the image pull failure exit code, got %v", err)
+	}
+
+	// the mirror fails with fallback, the original image is used
+	properties.Properties["mirror-fallback"] = "true"
+	mirrorRuntime = &blockingRuntime{block: "never", fail: "mirror.company.com"}
+	runner = NewRunner(Options{Properties: properties, Runtime: mirrorRuntime, Stdout: &bytes.Buffer{}})
+	if _, err := runner.Run(context.Background(), "echo", []string{"hello"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	last := mirrorRuntime.commands[len(mirrorRuntime.commands)-1]
+	if !strings.Contains(last, " run ") || strings.Contains(last, "mirror.company.com") || !strings.Contains(last, "alpine:latest") {
+		t.Errorf("expected the run to fall back to the original image, got %v", mirrorRuntime.commands)
+	}
+}
+
+// envRuntime records the value of a environment variable of the runtime client during the container runs
+type envRuntime struct {
+	*recordingRuntime
+	variable string
+	env      []string
+	values   *[]string
+}
+
+func (r envRuntime) Exec(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	command := containerutil.FormatCommand(args)
+	if strings.Contains(command, " run ") {
+		value := ""
+		for _, variable := range r.env {
+			if name, v, _ := strings.Cut(variable, "="); name == r.variable {
+				value = v
+			}
+		}
+		*r.values = append(*r.values, value)
+	}
+	return r.recordingRuntime.Exec(ctx, args, stdin, stdout, stderr)
+}
+
+func (r envRuntime) WithEnvironment(env []string) containerutil.ContainerRuntime {
+	r.env = append(append([]string{}, r.env...), env...)
+	return r
+}
+
+func TestRunnerCredentialHelpers(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	chdirProject(t, "images:\n  - name: aws\n    image: amazon/aws-cli\n    credentialHelpers:\n      - test\n    provides:\n      - aws\n")
+	configDir := t.TempDir()
+	config.SetConfigurationDirectory(configDir)
+	t.Cleanup(func() { config.SetConfigurationDirectory(config.UserConfigurationDirectory()) })
+	globalConfig := "credentialHelpers:\n  test:\n    command: echo '{\"AccessKeyId\":\"AKIA\",\"SecretAccessKey\":\"s3cr3t\"}'\n    env:\n      TEST_ACCESS_KEY_ID: AccessKeyId\n      TEST_SECRET_ACCESS_KEY: SecretAccessKey\n      TEST_SESSION_TOKEN: SessionToken\n"
+	if err := os.WriteFile(filepath.Join(configDir, ".envcli.yml"), []byte(globalConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runtime := envRuntime{recordingRuntime: &recordingRuntime{name: "docker"}, variable: "TEST_SECRET_ACCESS_KEY", values: &[]string{}}
+	if _, err := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Stdout: &bytes.Buffer{}}).Run(context.Background(), "aws", []string{"s3", "ls"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// the credentials are passed by name, the runtime client reads them from its environment
+	runs := runtime.executedRuns()
+	if len(runs) != 1 || !strings.Contains(runs[0], " -e TEST_ACCESS_KEY_ID -e TEST_SECRET_ACCESS_KEY ") || strings.Contains(runs[0], "s3cr3t") || strings.Contains(runs[0], "TEST_SESSION_TOKEN") {
+		t.Errorf("expected the credentials to be passed by name, got %v", runs)
+	}
+	if values := *runtime.values; len(values) != 1 || values[0] != "s3cr3t" {
+		t.Errorf("expected the credentials within the environment of the runtime client, got %v", values)
+	}
+	if _, set := os.LookupEnv("TEST_SECRET_ACCESS_KEY"); set {
+		t.Errorf("expected the credentials to stay out of the environment of envcli")
+	}
+
+	// unknown helpers
+	chdirProject(t, "images:\n  - name: aws\n    image: amazon/aws-cli\n    credentialHelpers:\n      - vault\n    provides:\n      - aws\n")
+	code, err := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: &recordingRuntime{name: "docker"}, Stdout: &bytes.Buffer{}}).Run(context.Background(), "aws", nil)
+	if code != exitcode.ConfigError || err == nil || !strings.Contains(err.Error(), "available: aws, gcloud, test") {
+		t.Errorf("expected the available helpers to be listed, got %d (%v)", code, err)
+	}
+}
+
+func TestRunnerCredentialHelperTemplates(t *testing.T) {
+	if goruntime.GOOS == "windows" {
+		t.Skip("requires printf")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := chdirProject(t, "images:\n  - name: aws\n    image: amazon/aws-cli\n    credentialHelpers:\n      - branch\n    provides:\n      - aws\n")
+	branch := "$(touch${IFS}pwned);touch${IFS}pwned"
+	for _, args := range [][]string{{"init", "-q"}, {"checkout", "-q", "-b", branch}, {"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"}} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	configDir := t.TempDir()
+	config.SetConfigurationDirectory(configDir)
+	t.Cleanup(func() { config.SetConfigurationDirectory(config.UserConfigurationDirectory()) })
+	globalConfig := "credentialHelpers:\n  branch:\n    command: printf '%s' {{ .GitBranch }}\n    env:\n      TEST_BRANCH: \"\"\n"
+	if err := os.WriteFile(filepath.Join(configDir, ".envcli.yml"), []byte(globalConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the template values are passed as a single argument, a shell would execute the branch name
+	runtime := envRuntime{recordingRuntime: &recordingRuntime{name: "docker"}, variable: "TEST_BRANCH", values: &[]string{}}
+	if _, err := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Stdout: &bytes.Buffer{}}).Run(context.Background(), "aws", nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if values := *runtime.values; len(values) != 1 || values[0] != branch {
+		t.Errorf("expected the branch name as credential, got %v", values)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); !os.IsNotExist(err) {
+		t.Errorf("expected the branch name not to be executed")
+	}
+}
+
+func TestRunnerLowPriority(t *testing.T) {
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n")
+
+	runtime := &recordingRuntime{name: "docker"}
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Priority: config.PriorityLow, Stdout: &bytes.Buffer{}})
+	if _, err := runner.Run(context.Background(), "echo", []string{"hello"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	runs := runtime.executedRuns()
+	if len(runs) != 1 || !strings.Contains(runs[0], "--cpu-shares 256") || !strings.Contains(runs[0], "--blkio-weight 100") {
+		t.Errorf("expected the low priority container options, got %v", runs)
+	}
+}
+
+func TestRunnerNotify(t *testing.T) {
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n")
+	properties := &config.PropertyConfigurationFile{Properties: map[string]string{"notify-after": "1h"}}
+
+	var titles []string
+	notifier := func(ctx context.Context, title string, message string) error {
+		titles = append(titles, title)
+		return nil
+	}
+
+	// below the notify-after duration
+	runner := NewRunner(Options{Properties: properties, Runtime: &recordingRuntime{name: "docker"}, Stdout: &bytes.Buffer{}})
+	runner.notify = notifier
+	if _, err := runner.Run(context.Background(), "echo", nil); err != nil || len(titles) != 0 {
+		t.Errorf("expected no notification, got %v (%v)", titles, err)
+	}
+
+	// forced using --notify
+	runner = NewRunner(Options{Properties: properties, Runtime: &recordingRuntime{name: "docker"}, Notify: true, Stdout: &bytes.Buffer{}})
+	runner.notify = notifier
+	if _, err := runner.Run(context.Background(), "echo", nil); err != nil || strings.Join(titles, ",") != "envcli: echo succeeded" {
+		t.Errorf("expected a notification, got %v (%v)", titles, err)
+	}
+
+	// terminal bell without notifier
+	stderr := &bytes.Buffer{}
+	runner = NewRunner(Options{Properties: properties, Runtime: &recordingRuntime{name: "docker"}, Notify: true, Stdout: &bytes.Buffer{}, Stderr: stderr})
+	runner.notify = func(ctx context.Context, title string, message string) error { return errors.New("no notifier") }
+	_, _ = runner.Run(context.Background(), "echo", nil)
+	if stderr.String() != "\a" {
+		t.Errorf("expected the terminal bell, got %q", stderr.String())
+	}
+}
+
+// TestRunnerStreamsLargeOutput streams 16MB by default, ENVCLI_TEST_STREAM_MB sets a larger size (ex. 256)
+func TestRunnerStreamsLargeOutput(t *testing.T) {
+	if testing.Short() || goruntime.GOOS == "windows" {
+		t.Skip("streams the output through a shell pipeline")
+	}
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n    directory: /project\n    rewritePaths: true\n")
+	logFile := filepath.Join(t.TempDir(), "run.log")
+	var size int64 = 16 * 1024 * 1024
+	if value := os.Getenv("ENVCLI_TEST_STREAM_MB"); value != "" {
+		megabytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || megabytes <= 0 {
+			t.Fatalf("invalid ENVCLI_TEST_STREAM_MB %q", value)
+		}
+		size = megabytes * 1024 * 1024
+	}
+
+	// sample the heap while the output is streamed through the log file, ci annotation and path rewriting writers
+	// a low gc target collects the garbage of the streamed chunks early, so that the heap in use reflects the retained output and not the heap left by the other tests
+	defer debug.SetGCPercent(debug.SetGCPercent(10))
+	var baseline goruntime.MemStats
+	goruntime.GC()
+	goruntime.ReadMemStats(&baseline)
+	var peak uint64
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		var stats goruntime.MemStats
+		for {
+			goruntime.ReadMemStats(&stats)
+			if stats.HeapInuse > peak {
+				peak = stats.HeapInuse
+			}
+			select {
+			case <-done:
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+		}
+	}()
+
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: &streamingRuntime{size: size}, LogFile: logFile, CIAnnotations: "github", Stdout: io.Discard, Stderr: io.Discard})
+	code, err := runner.Run(context.Background(), "echo", nil)
+	close(done)
+	<-sampled
+	if code != exitcode.Success || err != nil {
+		t.Fatalf("expected success, got %d (%v)", code, err)
+	}
+
+	info, err := os.Stat(logFile)
+	if err != nil || info.Size() < size {
+		t.Fatalf("expected the complete output in the log file, got %v (%v)", info, err)
+	}
+	if growth := int64(peak) - int64(baseline.HeapInuse); growth > size/2 {
+		t.Errorf("expected the heap to stay bounded while streaming, grew by %d MB", growth/1024/1024)
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	buffer := &tailBuffer{limit: 8}
+	_, _ = buffer.Write([]byte("hello "))
+	_, _ = buffer.Write([]byte("world!"))
+	if buffer.String() != "o world!" {
+		t.Errorf("expected the last 8 bytes, got %q", buffer.String())
+	}
+}
+
+// tmpDirSource returns the host directory mounted at the container path
+func tmpDirSource(t *testing.T, run string, target string) string {
+	t.Helper()
+	match := regexp.MustCompile(`-v (\S+):` + regexp.QuoteMeta(target) + ` `).FindStringSubmatch(run)
+	if match == nil {
+		t.Fatalf("expected a mount of %s in %s", target, run)
+	}
+	return match[1]
+}
+
+func TestRunnerTmpDirs(t *testing.T) {
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n    tmpDirs:\n      - /workspace-tmp\n      - /var/cache/tool/\n")
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	t.Setenv("TEMP", tmp)
+	t.Setenv("TMP", tmp)
+
+	for _, keep := range []bool{false, true} {
+		runtime := &recordingRuntime{name: "docker"}
+		runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, KeepContainer: keep, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}})
+		if code, err := runner.Run(context.Background(), "echo", nil); code != exitcode.Success || err != nil {
+			t.Fatalf("expected success, got %d (%v)", code, err)
+		}
+
+		runs := runtime.executedRuns()
+		if len(runs) != 1 || !strings.Contains(runs[0], "-e ENVCLI_TMP=/workspace-tmp") {
+			t.Fatalf("expected ENVCLI_TMP in %v", runs)
+		}
+		first := tmpDirSource(t, runs[0], "/workspace-tmp")
+		second := tmpDirSource(t, runs[0], "/var/cache/tool")
+		if filepath.Dir(first) != filepath.Dir(second) || first == second {
+			t.Errorf("expected separate directories within the run directory, got %s and %s", first, second)
+		}
+		if resolved, _ := filepath.EvalSymlinks(tmp); !strings.HasPrefix(first, resolved) {
+			t.Errorf("expected the directory within the temp dir %s, got %s", resolved, first)
+		}
+
+		_, err := os.Stat(first)
+		if keep && err != nil {
+			t.Errorf("expected the directory to be kept with --keep-container, got %v", err)
+		} else if !keep && !os.IsNotExist(err) {
+			t.Errorf("expected the directory to be removed after the run, got %v", err)
+		}
+	}
+}
+
+func TestRunnerTmpDirsRemovedOnInterrupt(t *testing.T) {
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n    tmpDirs:\n      - /workspace-tmp\n")
+	runtime := &blockingRuntime{block: " run "}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}})
+	if _, err := runner.Run(ctx, "echo", nil); err == nil {
+		t.Fatal("expected the interrupted run to fail")
+	}
+
+	var run string
+	for _, command := range runtime.commands {
+		if strings.Contains(command, " run ") {
+			run = command
+		}
+	}
+	if _, err := os.Stat(tmpDirSource(t, run, "/workspace-tmp")); !os.IsNotExist(err) {
+		t.Errorf("expected the directory to be removed after the interrupt, got %v", err)
+	}
+}
+
+func TestCreateTmpDirsWindowsShortPath(t *testing.T) {
+	if goruntime.GOOS != "windows" {
+		t.Skip("short paths only exist on windows")
+	}
+
+	// the temp dir of windows runners is a short path, ex. C:\Users\RUNNER~1\AppData\Local\Temp
+	root, mounts, err := createTmpDirs(os.TempDir(), []string{"/workspace-tmp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	if strings.Contains(mounts[0].Source, "~") || !filepath.IsAbs(mounts[0].Source) {
+		t.Errorf("expected the long absolute path, got %s", mounts[0].Source)
+	}
+	if mounts[0].Target != "/workspace-tmp" {
+		t.Errorf("unexpected target %s", mounts[0].Target)
+	}
+}
+
+func TestRunnerInit(t *testing.T) {
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - echo\n  - name: s6\n    image: company/s6:latest\n    init: false\n    provides:\n      - s6-svscan\n")
+
+	runtime := &recordingRuntime{name: "docker"}
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Stdout: &bytes.Buffer{}})
+	if _, err := runner.Run(context.Background(), "echo", nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := runner.Run(context.Background(), "s6-svscan", nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	runs := runtime.executedRuns()
+	if len(runs) != 2 || !strings.Contains(runs[0], " --init ") || strings.Contains(runs[1], "--init") {
+		t.Errorf("expected --init unless the entry disables it, got %v", runs)
+	}
+}
+
+// TestInitReapsProcesses verifies that the init process of the runtime reaps orphaned processes, it requires a container runtime and pulls busybox
+func TestInitReapsProcesses(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires a container runtime")
+	}
+	runtime := containerutil.DetectRuntime("")
+	if containerutil.RequireRuntime(runtime) != nil {
+		t.Skip("no container runtime available")
+	}
+	if _, err := containerutil.RuntimeVersion(context.Background(), runtime); err != nil {
+		t.Skipf("container runtime %s is not usable: %v", runtime.Name(), err)
+	}
+
+	// the background sleep is orphaned once the inner shell exits, pid 1 has to reap it
+	output, err := runtime.Output(context.Background(), []string{runtime.Name(), "run", "--rm", "--init", "busybox:1.36", "sh", "-c", "sh -c 'sleep 0 &'; sleep 1; ps -o stat | grep -c ^Z || true"})
+	if err != nil {
+		t.Fatalf("failed to run the container: %v", err)
+	}
+	if zombies := strings.TrimSpace(output); zombies != "0" {
+		t.Errorf("expected all processes to be reaped, got %s zombie processes", zombies)
+	}
+}
+
+func TestRunnerSignaturePolicy(t *testing.T) {
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:3.19\n    provides:\n      - echo\n")
+	configDir := t.TempDir()
+	config.SetConfigurationDirectory(configDir)
+	t.Cleanup(func() { config.SetConfigurationDirectory(config.UserConfigurationDirectory()) })
+	writeGlobal := func(mode string) {
+		content := "policy:\n  mode: " + mode + "\n  signature:\n    required: true\n    publicKey: cosign.pub\n"
+		if err := os.WriteFile(filepath.Join(configDir, ".envcli.yml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "cosign.pub"), []byte("-----BEGIN PUBLIC KEY-----\nAAAA\n-----END PUBLIC KEY-----\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	properties := &config.PropertyConfigurationFile{Properties: map[string]string{"cache-path": t.TempDir()}}
+
+	var verified []string
+	signed := false
+	verify := func(ctx context.Context, publicKey string, image string) error {
+		verified = append(verified, publicKey+" "+image)
+		if !signed {
+			return errors.New("no matching signatures")
+		}
+		return nil
+	}
+	run := func() (int, *recordingRuntime) {
+		runtime := &recordingRuntime{name: "docker", outputs: map[string]string{"RepoDigests": `["alpine@sha256:abc"]`}}
+		runner := NewRunner(Options{Properties: properties, Runtime: runtime, Stdout: &bytes.Buffer{}})
+		runner.verify = verify
+		code, _ := runner.Run(context.Background(), "echo", nil)
+		return code, runtime
+	}
+
+	// unsigned, refused in enforce mode
+	writeGlobal("enforce")
+	if code, runtime := run(); code != exitcode.ConfigError || len(runtime.executedRuns()) != 0 {
+		t.Fatalf("expected the unsigned image to be refused, got %d and runs %v", code, runtime.executedRuns())
+	}
+	if len(verified) != 1 || verified[0] != filepath.Join(configDir, "cosign.pub")+" alpine@sha256:abc" {
+		t.Errorf("unexpected verification %v", verified)
+	}
+
+	// unsigned, only logged in warn mode
+	writeGlobal("warn")
+	if code, runtime := run(); code != 0 || len(runtime.executedRuns()) != 1 {
+		t.Errorf("expected the unsigned image to run in warn mode, got %d and runs %v", code, runtime.executedRuns())
+	}
+
+	// signed, the verification is cached per digest
+	writeGlobal("enforce")
+	signed, verified = true, nil
+	for i := 0; i < 2; i++ {
+		if code, runtime := run(); code != 0 || len(runtime.executedRuns()) != 1 {
+			t.Errorf("expected the signed image to run, got %d and runs %v", code, runtime.executedRuns())
+		}
+	}
+	if len(verified) != 1 {
+		t.Errorf("expected the verification to be cached, got %v", verified)
+	}
+}
+
+func TestRunnerOutOfSpaceGuidance(t *testing.T) {
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - npm\n")
+
+	runtime := &recordingRuntime{name: "docker", runOutput: "npm ERR! nospc ENOSPC: no space left on device, write\n", runErr: errors.New("exit status 1"), outputs: map[string]string{"system df": "Images\t45.2GB\t30.1GB (66%)"}}
+	stderr := &bytes.Buffer{}
+	if _, err := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Stdout: &bytes.Buffer{}, Stderr: stderr}).Run(context.Background(), "npm", []string{"install"}); err == nil {
+		t.Fatal("expected the run to fail")
+	}
+	if !strings.Contains(stderr.String(), "ran out of disk space") || !strings.Contains(stderr.String(), "45.2GB") || !strings.Contains(stderr.String(), "envcli prune --system") {
+		t.Errorf("expected the out of disk space guidance, got %q", stderr.String())
+	}
+}
+
+func TestRunnerFileSharingProbe(t *testing.T) {
+	// the project must be outside of the home directory and the default shared paths of Docker Desktop
+	t.Setenv("TMPDIR", "/var/tmp")
+	chdirProject(t, "images:\n  - name: alpine\n    image: alpine:latest\n    provides:\n      - npm\n")
+
+	run := func(goos string, properties map[string]string, runtime *recordingRuntime) error {
+		runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{Properties: properties}, Runtime: runtime, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}})
+		runner.goos = goos
+		_, err := runner.Run(context.Background(), "npm", []string{"install"})
+		return err
+	}
+
+	// unshared directories are mounted as empty directories
+	runtime := &recordingRuntime{name: "docker"}
+	err := run("darwin", nil, runtime)
+	if exitcode.Of(err) != exitcode.RuntimeUnavailable || !errors.Is(err, containerutil.ErrUnsharedPath) || !strings.Contains(err.Error(), "File sharing") {
+		t.Errorf("expected the file sharing guidance, got %v", err)
+	}
+	if len(runtime.executedRuns()) != 1 || !strings.Contains(runtime.executedRuns()[0], "--entrypoint ls") {
+		t.Errorf("expected only the probe container, got %v", runtime.executedRuns())
+	}
+
+	runtime = &recordingRuntime{name: "docker", outputs: map[string]string{"--entrypoint ls": "node_modules\n.envcli.yml\n"}}
+	if err = run("darwin", nil, runtime); err != nil {
+		t.Errorf("expected the shared directory to pass the probe, got %v", err)
+	}
+
+	// the probe is skipped on other systems and if it's disabled
+	for goos, properties := range map[string]map[string]string{"linux": nil, "darwin": {"file-sharing-probe": "false"}} {
+		runtime = &recordingRuntime{name: "docker"}
+		if err = run(goos, properties, runtime); err != nil {
+			t.Fatal(err)
+		}
+		for _, command := range runtime.commands {
+			if strings.Contains(command, "--entrypoint ls") {
+				t.Errorf("expected no probe on %s with %v, got %s", goos, properties, command)
+			}
+		}
+	}
+
+	// the probe starts a container of the image, unsigned images are refused before
+	configDir := t.TempDir()
+	config.SetConfigurationDirectory(configDir)
+	t.Cleanup(func() { config.SetConfigurationDirectory(config.UserConfigurationDirectory()) })
+	if err = os.WriteFile(filepath.Join(configDir, ".envcli.yml"), []byte("policy:\n  signature:\n    required: true\n    publicKey: cosign.pub\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(configDir, "cosign.pub"), []byte("-----BEGIN PUBLIC KEY-----\nAAAA\n-----END PUBLIC KEY-----\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runtime = &recordingRuntime{name: "docker", outputs: map[string]string{"RepoDigests": `["alpine@sha256:abc"]`}}
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{Properties: map[string]string{"cache-path": t.TempDir()}}, Runtime: runtime, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}})
+	runner.goos = "darwin"
+	runner.verify = func(ctx context.Context, publicKey string, image string) error {
+		return errors.New("no matching signatures")
+	}
+	if code, _ := runner.Run(context.Background(), "npm", []string{"install"}); code != exitcode.ConfigError || len(runtime.executedRuns()) != 0 {
+		t.Errorf("expected the unsigned image to be refused without a probe container, got %d and runs %v", code, runtime.executedRuns())
+	}
+}
+
+func TestParseChain(t *testing.T) {
+	tests := []struct {
+		chain    string
+		expected string
+	}{
+		{"npm ci && npm test", "[npm|ci]&&[npm|test]"},
+		{"terraform fmt;tflint --format compact", "[terraform|fmt];[tflint|--format|compact]"},
+		{`echo "a && b" 'c; d' && git commit -m "x;y"`, "[echo|a && b|c; d]&&[git|commit|-m|x;y]"},
+		{`echo a\;b && ls`, "[echo|a;b]&&[ls]"},
+		{"npm ci ; npm test ;", "[npm|ci];[npm|test]"},
+	}
+	for _, test := range tests {
+		commands, err := ParseChain(test.chain)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.chain, err)
+			continue
+		}
+		var result string
+		for _, command := range commands {
+			result += command.Operator + "[" + strings.Join(command.Args, "|") + "]"
+		}
+		if result != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.chain, test.expected, result)
+		}
+	}
+
+	for _, chain := range []string{"", "npm ci && && npm test", "&& npm test", "npm ci | tee log", "npm ci || true", "echo \"unterminated && ls"} {
+		if _, err := ParseChain(chain); err == nil {
+			t.Errorf("%s: expected an error", chain)
+		}
+	}
+}
+
+func TestRunnerRunChain(t *testing.T) {
+	chdirProject(t, "images:\n  - name: node\n    image: node:20\n    provides:\n      - npm\n  - name: tflint\n    image: ghcr.io/terraform-linters/tflint:latest\n    provides:\n      - tflint\n")
+
+	runtime := &recordingRuntime{name: "docker"}
+	runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}})
+	commands, _ := ParseChain("npm ci && tflint --format compact")
+	if _, err := runner.RunChain(context.Background(), commands); err != nil {
+		t.Fatal(err)
+	}
+	runs := runtime.executedRuns()
+	if len(runs) != 2 || !strings.Contains(runs[0], "node:20") || !strings.Contains(runs[1], "tflint:latest") || !strings.Contains(runs[1], "tflint --format compact") {
+		t.Errorf("expected a container per entry, got %v", runs)
+	}
+
+	// && short-circuits on failures, ; continues
+	runtime = &recordingRuntime{name: "docker", runErr: errors.New("exit status 1")}
+	runner = NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}})
+	commands, _ = ParseChain("npm ci && npm test ; tflint")
+	if _, err := runner.RunChain(context.Background(), commands); err == nil {
+		t.Error("expected the error of the last command")
+	}
+	runs = runtime.executedRuns()
+	if len(runs) != 2 || !strings.Contains(runs[0], "npm ci") || !strings.Contains(runs[1], "tflint") {
+		t.Errorf("expected npm test to be skipped, got %v", runs)
+	}
+}
+
+func TestRunnerRequiresRuntime(t *testing.T) {
+	chdirProject(t, "images:\n  - name: cuda\n    image: nvidia/cuda:12.4.1-base-ubuntu22.04\n    provides:\n      - nvcc\n    requiresRuntime:\n      docker: \">=19.03\"\n")
+
+	run := func(runtime *recordingRuntime) error {
+		runner := NewRunner(Options{Properties: &config.PropertyConfigurationFile{}, Runtime: runtime, UserArgs: []string{"--gpus all"}, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{}})
+		_, err := runner.Run(context.Background(), "nvcc", []string{"--version"})
+		return err
+	}
+
+	runtime := &recordingRuntime{name: "docker", outputs: map[string]string{"version --format": "18.09.1-ce"}}
+	err := run(runtime)
+	if exitcode.Of(err) != exitcode.RuntimeUnavailable || !strings.Contains(err.Error(), "requires docker >=19.03, but docker 18.09.1-ce is installed") || !strings.Contains(err.Error(), "--gpus") {
+		t.Errorf("expected the version error naming the flags, got %v", err)
+	}
+	if len(runtime.executedRuns()) != 0 {
+		t.Errorf("expected no container run, got %v", runtime.executedRuns())
+	}
+
+	for _, runtime = range []*recordingRuntime{
+		{name: "docker", outputs: map[string]string{"version --format": "20.10.21-0ubuntu1~20.04.2"}},
+		// other runtimes and unknown versions aren't checked
+		{name: "podman", outputs: map[string]string{"version --format": "3.4.4"}},
+		{name: "docker"},
+	} {
+		if err = run(runtime); err != nil {
+			t.Errorf("%s: unexpected error %v", runtime.name, err)
+		}
+	}
+}
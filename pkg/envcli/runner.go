@@ -0,0 +1,150 @@
+// Package envcli runs commands within the containers configured in the envcli configuration, the envcli cli is a thin wrapper around it.
+package envcli
+
+import (
+	"context"
+	"io"
+	"os"
+	goruntime "runtime"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/notify"
+	"github.com/cidverse/cidverseutils/pkg/collection"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+)
+
+// Options configure the Runner, all fields are optional. The project config is searched in the current directory.
+type Options struct {
+	// WorkingDirectory is used instead of the current directory to search the project config and to determine the mounted directory, if set
+	WorkingDirectory string
+
+	// ConfigIncludes are additionally included configuration files
+	ConfigIncludes []string
+
+	// Properties are used instead of the property file, if set
+	Properties *config.PropertyConfigurationFile
+
+	// Runtime executes the container commands, the runtime of the host is detected if not set
+	Runtime containerutil.ContainerRuntime
+
+	// Variant selects the variant of the entry, the ENVCLI_VARIANT environment variable is used if not set
+	Variant string
+
+	// Env are environment variables (NAME=value) passed into the container
+	Env []string
+
+	// EnvFiles are dotenv files whose variables are passed into the container, they take precedence over the env of the entry
+	EnvFiles []string
+
+	// Ports are published ports of the container
+	Ports []string
+
+	// UserArgs are passed to the container run command as-is
+	UserArgs []string
+
+	// KeepContainer keeps the container after it exited
+	KeepContainer bool
+
+	// LogFile additionally receives the output of the command
+	LogFile string
+
+	// Shell overrides the configured shell of the entry
+	Shell string
+
+	// NoDefaultArgs skips the default arguments configured for the command
+	NoDefaultArgs bool
+
+	// Frozen fails if the project has no lock file or the image of the entry changed since it has been locked
+	Frozen bool
+
+	// Retries overrides the retries of the entry, if set
+	Retries *int
+
+	// Rebuild builds the image of build entries, even if it already exists
+	Rebuild bool
+
+	// NoDerive installs the extra packages of the entry at each container start, instead of deriving a image with the packages installed
+	NoDerive bool
+
+	// RecordFile receives a JSON line for each container run, which can be replayed using Replay
+	RecordFile string
+
+	// Timings prints the breakdown of the run duration (config, image pull, container startup, execution) to Stderr, they are recorded regardless
+	Timings bool
+
+	// Summary prints a single summary line of the run to Stderr as the last line: text or json, disabled if empty
+	Summary string
+
+	// Priority overrides the scheduling priority of the entry (normal or low), ex. low for envcli run --low-priority
+	Priority string
+
+	// Notify shows a desktop notification once the command finished, regardless of the notify-after property
+	Notify bool
+
+	// CIAnnotations groups the output into a collapsible section and annotates failures: auto, github, gitlab or off (default)
+	CIAnnotations string
+
+	// Detach starts the container in the background and returns once it is ready, see the readiness of the entry
+	Detach bool
+
+	// DryRun prints the rendered run command to Stdout instead of running it, the image is neither pulled nor built and hooks aren't executed
+	DryRun bool
+
+	// ConfirmTrust asks the user to trust a project config defining hooks, untrusted hooks are refused if not set
+	ConfirmTrust func(hooks config.ProjectHooks) bool
+
+	// Stdin, Stdout and Stderr default to the streams of the current process
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Runner runs commands within their configured containers
+type Runner struct {
+	opts Options
+
+	// notify sends the desktop notifications
+	notify func(ctx context.Context, title string, message string) error
+
+	// verify checks the cosign signature of the image with the public key
+	verify func(ctx context.Context, publicKey string, image string) error
+
+	// goos is the operating system of the host
+	goos string
+}
+
+// NewRunner creates a runner, missing options are replaced by their defaults
+func NewRunner(opts Options) *Runner {
+	if opts.Properties == nil {
+		properties, _ := config.LoadPropertyConfig()
+		opts.Properties = &properties
+	}
+	if opts.Stdin == nil {
+		opts.Stdin = os.Stdin
+	}
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = os.Stderr
+	}
+
+	return &Runner{opts: opts, notify: notify.Send, verify: cosignVerify, goos: goruntime.GOOS}
+}
+
+// workingDirectory returns the directory the command runs in, the current directory if not set
+func (r *Runner) workingDirectory() string {
+	if r.opts.WorkingDirectory != "" {
+		return r.opts.WorkingDirectory
+	}
+	return filesystem.GetWorkingDirectory()
+}
+
+// runtime returns the configured runtime or detects the runtime of the host
+func (r *Runner) runtime() containerutil.ContainerRuntime {
+	if r.opts.Runtime == nil {
+		r.opts.Runtime = containerutil.DetectRuntime(collection.MapGetValueOrDefault(r.opts.Properties.Properties, "container-runtime", ""))
+	}
+	return r.opts.Runtime
+}
@@ -0,0 +1,79 @@
+package envcli
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/rs/zerolog/log"
+)
+
+// verifySignature verifies the cosign signature of the image, if the policy of the global configuration requires signatures.
+// Verified digests are cached per public key. Images without a valid signature are refused in enforce mode, in warn mode only a warning is logged.
+func (r *Runner) verifySignature(ctx context.Context, runtime containerutil.ContainerRuntime, entry config.RunConfigurationEntry) error {
+	policy := entry.Signature
+	if !policy.Required {
+		return nil
+	}
+	fingerprint, err := config.PublicKeyFingerprint(policy.PublicKey)
+	if err != nil {
+		return exitcode.New(exitcode.ConfigError, fmt.Errorf("invalid publicKey of the signature policy defined in %s: %w", policy.Source, err))
+	}
+
+	digest, err := containerutil.ImageRepoDigest(ctx, runtime, entry.Image)
+	if err == nil {
+		sum := sha256.Sum256([]byte(digest + "\n" + fingerprint))
+		marker := filepath.Join(config.GetSignatureCacheDirectory(*r.opts.Properties), hex.EncodeToString(sum[:]))
+		if _, statErr := os.Stat(marker); statErr == nil {
+			log.Debug().Str("image", digest).Str("key", fingerprint).Msg("signature has already been verified")
+			return nil
+		}
+
+		if err = r.verify(ctx, policy.PublicKey, digest); err == nil {
+			log.Debug().Str("image", digest).Str("key", fingerprint).Msg("verified the image signature")
+			if cacheErr := os.MkdirAll(filepath.Dir(marker), os.ModePerm); cacheErr == nil {
+				_ = os.WriteFile(marker, []byte(digest+" "+fingerprint+"\n"), 0644)
+			}
+			return nil
+		}
+	}
+
+	if digest == "" {
+		digest = "no digest"
+	}
+	message := fmt.Sprintf("image %s (%s) has no valid signature of the key %s (%s) required by the policy defined in %s: %v", entry.Image, digest, policy.PublicKey, fingerprint, policy.Source, err)
+	if policy.Mode == config.PolicyModeWarn {
+		log.Warn().Str("image", entry.Image).Str("policy", policy.Source).Msg(message)
+		return nil
+	}
+	return exitcode.New(exitcode.ConfigError, errors.New(message))
+}
+
+// cosignVerify verifies the signature of the image reference using the cosign CLI
+func cosignVerify(ctx context.Context, publicKey string, image string) error {
+	if _, err := exec.LookPath("cosign"); err != nil {
+		return errors.New("cosign is not installed, it is required to verify the image signatures")
+	}
+
+	// without a shell, the key path and the image reach cosign unchanged
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "cosign", "verify", "--key", publicKey, image)
+	cmd.Stdout = io.Discard
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+		return fmt.Errorf("cosign verify failed: %s", lines[len(lines)-1])
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package envcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/record"
+)
+
+// Formats of the summary line
+const (
+	// SummaryFormatText prints ENVCLI_RESULT followed by key=value pairs
+	SummaryFormatText = "text"
+
+	// SummaryFormatJSON prints a json object
+	SummaryFormatJSON = "json"
+)
+
+// SummaryFormats are the supported formats of the summary line
+var SummaryFormats = []string{SummaryFormatText, SummaryFormatJSON}
+
+// SummaryPrefix starts the summary line in the text format
+const SummaryPrefix = "ENVCLI_RESULT"
+
+// runInfo collects the details of a run for the timings and the summary line
+type runInfo struct {
+	entry   string
+	docsURL string
+	image   string
+	digest  string
+	timings record.Timings
+}
+
+// summary is the summary line of a run, fields that are unknown (ex. the image of a failed config) are omitted
+type summary struct {
+	Command  string  `json:"command"`
+	Entry    string  `json:"entry,omitempty"`
+	Image    string  `json:"image,omitempty"`
+	Digest   string  `json:"digest,omitempty"`
+	Exit     int     `json:"exit"`
+	Duration float64 `json:"duration"`
+}
+
+// writeSummary prints the summary line of the run
+func writeSummary(w io.Writer, format string, command string, info *runInfo, exitCode int, duration time.Duration) {
+	s := summary{Command: command, Entry: info.entry, Image: info.image, Digest: info.digest, Exit: exitCode, Duration: duration.Round(time.Millisecond).Seconds()}
+
+	if format == SummaryFormatJSON {
+		data, _ := json.Marshal(s)
+		fmt.Fprintf(w, "%s\n", data)
+		return
+	}
+
+	fields := []string{SummaryPrefix, "command=" + summaryValue(s.Command)}
+	for _, field := range [][2]string{{"entry", s.Entry}, {"image", s.Image}, {"digest", s.Digest}} {
+		if field[1] != "" {
+			fields = append(fields, field[0]+"="+summaryValue(field[1]))
+		}
+	}
+	fields = append(fields, "exit="+strconv.Itoa(s.Exit), fmt.Sprintf("duration=%.1fs", duration.Seconds()))
+	fmt.Fprintln(w, strings.Join(fields, " "))
+}
+
+// summaryValue quotes values containing whitespace or quotes, to keep the line parsable
+func summaryValue(value string) string {
+	if strings.ContainsAny(value, " \t\"=") {
+		return strconv.Quote(value)
+	}
+	return value
+}
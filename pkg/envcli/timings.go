@@ -0,0 +1,86 @@
+package envcli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/EnvCLI/EnvCLI/pkg/record"
+	"github.com/rs/zerolog/log"
+)
+
+// ensureImage pulls the image if it isn't present in the local image store and records the pull in the timings
+func ensureImage(ctx context.Context, runtime containerutil.ContainerRuntime, image string, timings *record.Timings) error {
+	if containerutil.ImageExists(ctx, runtime, image) {
+		timings.ImageLocal = true
+		return nil
+	}
+
+	log.Info().Str("image", image).Msg("image not found locally, pulling it")
+	started := time.Now()
+	if err := containerutil.PullImage(ctx, runtime, image); err != nil {
+		return err
+	}
+	timings.Pull = time.Since(started)
+
+	// the pull output of the runtimes has no stable format, the size of the pulled image is taken from the image store
+	size, err := containerutil.ImageSize(ctx, runtime, image)
+	if err != nil {
+		log.Debug().Err(err).Str("image", image).Msg("failed to resolve the size of the pulled image")
+	}
+	timings.PullBytes = size
+	return nil
+}
+
+// firstOutput records the time of the first write to any of its writers
+type firstOutput struct {
+	mu sync.Mutex
+	at time.Time
+}
+
+func (f *firstOutput) wrap(w io.Writer) io.Writer {
+	return firstOutputWriter{first: f, w: w}
+}
+
+func (f *firstOutput) time() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.at
+}
+
+type firstOutputWriter struct {
+	first *firstOutput
+	w     io.Writer
+}
+
+func (w firstOutputWriter) Write(p []byte) (int, error) {
+	w.first.mu.Lock()
+	if w.first.at.IsZero() && len(p) > 0 {
+		w.first.at = time.Now()
+	}
+	w.first.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// writeTimings prints the breakdown of the run
+func writeTimings(w io.Writer, timings record.Timings) {
+	round := func(d time.Duration) time.Duration { return d.Round(time.Millisecond) }
+
+	fmt.Fprintf(w, "envcli timings:\n")
+	fmt.Fprintf(w, "  config     %s\n", round(timings.Config))
+	if timings.Built {
+		fmt.Fprintf(w, "  image      built in %s\n", round(timings.Pull))
+	} else if timings.ImageLocal {
+		fmt.Fprintf(w, "  image      local\n")
+	} else {
+		fmt.Fprintf(w, "  image      pulled in %s (%.1f MB)\n", round(timings.Pull), float64(timings.PullBytes)/1e6)
+	}
+	if timings.Startup > 0 {
+		fmt.Fprintf(w, "  startup    %s (until the first output)\n", round(timings.Startup))
+	}
+	fmt.Fprintf(w, "  execution  %s\n", round(timings.Execution))
+	fmt.Fprintf(w, "  total      %s\n", round(timings.Total))
+}
@@ -0,0 +1,41 @@
+package envcli
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/cidverse/cidverseutils/pkg/containerruntime"
+)
+
+// TmpDirEnv is the environment variable pointing to the first temporary directory within the container
+const TmpDirEnv = "ENVCLI_TMP"
+
+// createTmpDirs creates the host directory of a run within parent (the system temp dir if empty) with one subdirectory per container path.
+// The host path is resolved, as the container runtime can't share short windows paths (ex. C:\Users\RUNNER~1) or symlinks (ex. /var on macOS).
+func createTmpDirs(parent string, targets []string) (string, []containerruntime.ContainerMount, error) {
+	root, err := os.MkdirTemp(parent, "envcli-run-")
+	if err != nil {
+		return "", nil, err
+	}
+	if resolved, resolveErr := filepath.EvalSymlinks(root); resolveErr == nil {
+		root = resolved
+	}
+
+	var mounts []containerruntime.ContainerMount
+	for _, target := range targets {
+		source := filepath.Join(root, tmpDirName(target))
+		if err = os.MkdirAll(source, 0700); err != nil {
+			_ = os.RemoveAll(root)
+			return "", nil, err
+		}
+		mounts = append(mounts, containerruntime.ContainerMount{MountType: "directory", Source: source, Target: path.Clean(target)})
+	}
+	return root, mounts, nil
+}
+
+// tmpDirName returns the name of the host directory for the container path, ex. workspace-tmp for /workspace-tmp
+func tmpDirName(target string) string {
+	return strings.ReplaceAll(strings.Trim(path.Clean(target), "/"), "/", "_")
+}
@@ -0,0 +1,72 @@
+package envcli
+
+import (
+	"context"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"github.com/cidverse/cidverseutils/pkg/containerruntime"
+	"github.com/rs/zerolog/log"
+)
+
+// Warmup executes the warmup command of the entry in a temporary container, the output is logged at debug level
+func (r *Runner) Warmup(ctx context.Context, commandConfig config.RunConfigurationEntry) error {
+	container := &containerutil.Container{}
+	container.SetImage(commandConfig.Image)
+	container.SetEntrypoint(commandConfig.Entrypoint)
+
+	// mounts
+	mountRoot, err := config.ResolveMountRoot(r.workingDirectory(), false)
+	if err != nil {
+		return err
+	}
+	mount := config.ResolveMountPaths(mountRoot, r.workingDirectory(), commandConfig.Directory)
+	container.AddVolume(containerruntime.ContainerMount{MountType: "directory", Source: mount.Source, Target: mount.Target})
+	container.SetWorkingDirectory(mount.WorkingDirectory)
+	r.addCacheMounts(container, commandConfig)
+	r.addProxyEnvironment(container)
+
+	// the warmup is a command line, which always requires a shell
+	shell := commandConfig.Shell
+	if shell == "" || shell == "none" {
+		shell = "sh"
+	}
+	command, err := containerutil.WrapShellCommand(shell, commandConfig.LoginShell, commandConfig.Warmup)
+	if err != nil {
+		return err
+	}
+	container.SetCommand(command)
+
+	runtime := r.runtime()
+	runCommand, err := containerutil.RenderRunCommand(ctx, runtime, container)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Str("entry", commandConfig.Name).Msg("Running warmup command in container [" + commandConfig.Image + "].")
+	output := &tailBuffer{limit: warmupOutputLimit}
+	err = runtime.Exec(ctx, runCommand, nil, output, output)
+	log.Debug().Str("entry", commandConfig.Name).Str("output", output.String()).Msg("warmup output")
+	return err
+}
+
+// warmupOutputLimit is the amount of warmup output kept for the debug log
+const warmupOutputLimit = 64 * 1024
+
+// tailBuffer keeps the last bytes written to it, up to the limit
+type tailBuffer struct {
+	limit int
+	data  []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	if len(b.data) > b.limit {
+		b.data = append(b.data[:0], b.data[len(b.data)-b.limit:]...)
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	return string(b.data)
+}
@@ -0,0 +1,95 @@
+package envcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/ignore"
+	"github.com/EnvCLI/EnvCLI/pkg/watch"
+	"github.com/rs/zerolog/log"
+)
+
+// WatchOptions configure the files watched by Runner.Watch
+type WatchOptions struct {
+	// Patterns are globs of the watched files, relative to the project directory (ex. src/**/*.go)
+	Patterns []string
+
+	// Ignore are globs of files and directories that don't trigger a run
+	Ignore []string
+
+	// Interval and Debounce of the watcher, the defaults of package watch are used if not set
+	Interval time.Duration
+	Debounce time.Duration
+}
+
+// Watch runs the command and runs it again whenever a watched file changes, until the context is cancelled.
+// A change during a run triggers the next run once the current run completed. Interactive commands (shells or runs asking for a tty or stdin) are refused.
+func (r *Runner) Watch(ctx context.Context, command string, args []string, opts WatchOptions) error {
+	if len(opts.Patterns) == 0 {
+		return exitcode.New(exitcode.ConfigError, errors.New("watch mode requires at least one pattern"))
+	}
+
+	// an image name without arguments starts an interactive shell
+	_, matchType, err := config.GetCommandMatch(ctx, command, r.workingDirectory(), r.opts.ConfigIncludes)
+	if err != nil {
+		return fmt.Errorf("failed to load command config: %w", err)
+	}
+	if matchType == config.MatchByName && len(args) == 0 {
+		return exitcode.New(exitcode.ConfigError, errors.New("watch mode can't be used for interactive commands, "+command+" starts a shell"))
+	}
+	if flag := interactiveFlag(r.opts.UserArgs); flag != "" {
+		return exitcode.New(exitcode.ConfigError, errors.New("watch mode can't be used for interactive commands, "+flag+" attaches the terminal"))
+	}
+
+	root := config.GetProjectOrDirectory(r.workingDirectory())
+	excludes, err := ignore.Load(root)
+	if err != nil {
+		return exitcode.New(exitcode.ConfigError, fmt.Errorf("failed to read %s: %w", ignore.FileName, err))
+	}
+
+	watcher := &watch.Watcher{Root: root, Patterns: opts.Patterns, Ignore: opts.Ignore, Excludes: excludes, Interval: opts.Interval, Debounce: opts.Debounce}
+	log.Debug().Str("root", watcher.Root).Strs("patterns", opts.Patterns).Strs("ignore", opts.Ignore).Msg("watching for changes")
+
+	if err = watcher.Reset(); err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	reason := "initial run"
+	for {
+		fmt.Fprintf(r.opts.Stderr, "---- %s - %s ----\n", time.Now().Format("2006-01-02 15:04:05"), reason)
+		if _, runErr := r.Run(ctx, command, args); ctx.Err() != nil {
+			return nil
+		} else if runErr != nil && !exitcode.IsSilent(runErr) {
+			log.Error().Err(runErr).Msg("run failed")
+		}
+
+		changed, waitErr := watcher.Wait(ctx)
+		if ctx.Err() != nil {
+			return nil
+		} else if waitErr != nil {
+			return waitErr
+		}
+		reason = "changed: " + strings.Join(changed, ", ")
+	}
+}
+
+// interactiveFlag returns the user arg asking the container runtime for a tty or stdin (ex. -it), empty if none
+func interactiveFlag(userArgs []string) string {
+	for _, userArg := range userArgs {
+		for _, arg := range common.SplitArgs(userArg) {
+			name := strings.SplitN(arg, "=", 2)[0]
+			shortFlags := len(arg) > 1 && arg[0] == '-' && arg[1] != '-' && strings.Trim(arg[1:], "it") == ""
+			if ((name == "--interactive" || name == "--tty") && !strings.HasSuffix(arg, "=false")) || shortFlags {
+				return arg
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,90 @@
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+)
+
+// Exit codes of envcli, all other codes are passed through from the command executed within the container
+const (
+	Success            = 0
+	GeneralError       = 1
+	ConfigError        = 2
+	RuntimeUnavailable = 3
+	ImagePullFailure   = 4
+	Timeout            = 124
+	Interrupted        = 130
+)
+
+// Error is a error with a dedicated exit code
+type Error struct {
+	Code int
+	Err  error
+
+	// Silent errors have already been reported to the user and only set the exit code
+	Silent bool
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New wraps the error with the exit code, returns nil if err is nil
+func New(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// NewSilent wraps the error with the exit code, the error won't be printed again
+func NewSilent(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Err: err, Silent: true}
+}
+
+// IsSilent returns true if the error shouldn't be printed, this applies to silent errors and failed commands (which printed their own output).
+// Wrapped command failures are printed, since the wrapping error adds context.
+func IsSilent(err error) bool {
+	var codeErr *Error
+	if errors.As(err, &codeErr) {
+		return codeErr.Silent
+	}
+
+	_, isExitErr := err.(*exec.ExitError)
+	return isExitErr
+}
+
+// Of returns the exit code for the error: 0 for nil, 130 or 124 for cancelled operations, the code of a wrapped Error, the exit code of a failed command or 1
+func Of(err error) int {
+	if err == nil {
+		return Success
+	}
+
+	// cancellation takes precedence, ex. over the pull failure of a cancelled pull
+	if errors.Is(err, context.Canceled) {
+		return Interrupted
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Timeout
+	}
+
+	var codeErr *Error
+	if errors.As(err, &codeErr) {
+		return codeErr.Code
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	return GeneralError
+}
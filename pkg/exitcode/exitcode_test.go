@@ -0,0 +1,54 @@
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	commandErr := exec.Command("sh", "-c", "exit 42").Run()
+
+	var tests = []struct {
+		err      error
+		expected int
+	}{
+		{nil, Success},
+		{errors.New("unexpected"), GeneralError},
+		{New(ConfigError, errors.New("no configuration for command npm found")), ConfigError},
+		{fmt.Errorf("wrapped: %w", New(RuntimeUnavailable, errors.New("no runtime"))), RuntimeUnavailable},
+		{New(ImagePullFailure, errors.New("pull failed")), ImagePullFailure},
+		{New(Timeout, errors.New("timeout")), Timeout},
+		{commandErr, 42},
+		{fmt.Errorf("command failed: %w", commandErr), 42},
+		{context.Canceled, Interrupted},
+		{New(ImagePullFailure, fmt.Errorf("pull failed: %w", context.Canceled)), Interrupted},
+		{fmt.Errorf("%w: %v", context.DeadlineExceeded, commandErr), Timeout},
+	}
+
+	for _, test := range tests {
+		if code := Of(test.err); code != test.expected {
+			t.Errorf("%v: expected exit code %d, got %d", test.err, test.expected, code)
+		}
+	}
+
+	if New(ConfigError, nil) != nil {
+		t.Errorf("expected nil for a nil error")
+	}
+}
+
+func TestIsSilent(t *testing.T) {
+	commandErr := exec.Command("sh", "-c", "exit 42").Run()
+
+	if !IsSilent(commandErr) || !IsSilent(NewSilent(GeneralError, errors.New("violations found"))) {
+		t.Errorf("expected command failures and silent errors to be silent")
+	}
+	if IsSilent(New(ConfigError, errors.New("no configuration"))) || IsSilent(errors.New("unexpected")) || IsSilent(fmt.Errorf("warmup failed: %w", commandErr)) {
+		t.Errorf("expected other errors to be printed")
+	}
+	if Of(NewSilent(ConfigError, errors.New("violations found"))) != ConfigError {
+		t.Errorf("expected silent errors to keep their exit code")
+	}
+}
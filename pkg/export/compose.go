@@ -0,0 +1,126 @@
+package export
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+// invalidServiceNameChars matches all characters that are not allowed within a compose service name
+var invalidServiceNameChars = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// compose returns a docker-compose file with one service per entry, the services run the commands like envcli run (ex. docker compose run node npm install).
+// Entries with the same name are only exported once, the first one has precedence like for envcli run.
+func compose(cfg config.ConfigurationFile, opts Options) yaml.MapSlice {
+	var services yaml.MapSlice
+	exported := map[string]bool{}
+	for _, entry := range cfg.Images {
+		name := strings.Trim(invalidServiceNameChars.ReplaceAllString(strings.ToLower(entry.Name), "-"), "-")
+		if name == "" || exported[name] {
+			continue
+		}
+		exported[name] = true
+		services = append(services, yaml.MapItem{Key: name, Value: composeService(entry, opts)})
+	}
+
+	return yaml.MapSlice{{Key: "services", Value: services}}
+}
+
+// composeService returns the compose service of the entry
+func composeService(entry config.RunConfigurationEntry, opts Options) yaml.MapSlice {
+	var service yaml.MapSlice
+	add := func(key string, value interface{}) {
+		service = append(service, yaml.MapItem{Key: key, Value: value})
+	}
+
+	if entry.IsBuild() {
+		add("build", yaml.MapSlice{{Key: "context", Value: entry.Build.Context}, {Key: "dockerfile", Value: entry.Build.Dockerfile}})
+	} else {
+		add("image", entry.Image)
+	}
+
+	// envcli replaces the entrypoint of the image, the command is passed by docker compose run
+	if entry.Entrypoint != "" && entry.Entrypoint != "unset" {
+		add("entrypoint", []string{entry.Entrypoint})
+	} else {
+		add("entrypoint", []string{})
+	}
+
+	mount := config.ResolveMountPaths(opts.ProjectDirectory, opts.ProjectDirectory, entry.Directory)
+	add("working_dir", mount.WorkingDirectory)
+	add("init", entry.UsesInit())
+
+	volumes := []string{mount.Source + ":" + mount.Target}
+	if opts.CachePath != "" {
+		for _, cache := range entry.Caching {
+			volumes = append(volumes, opts.CachePath+"/"+cache.Name+":"+cache.ContainerDirectory)
+		}
+	}
+	add("volumes", volumes)
+
+	environment := append([]string{}, entry.Env...)
+	environment = append(environment, config.ExpandEnvPassthrough(entry.EnvPassthrough, opts.Environ)...)
+	if len(environment) > 0 {
+		add("environment", environment)
+	}
+	if len(entry.EnvFile) > 0 {
+		var envFiles []interface{}
+		for _, envFile := range entry.EnvFile {
+			if envFile.Optional {
+				envFiles = append(envFiles, yaml.MapSlice{{Key: "path", Value: envFile.Path}, {Key: "required", Value: false}})
+			} else {
+				envFiles = append(envFiles, envFile.Path)
+			}
+		}
+		add("env_file", envFiles)
+	}
+
+	if len(entry.CapAdd) > 0 {
+		add("cap_add", entry.CapAdd)
+	}
+	if len(entry.SecurityOpt) > 0 {
+		add("security_opt", entry.SecurityOpt)
+	}
+	if len(entry.Ulimits) > 0 {
+		add("ulimits", composeUlimits(entry.Ulimits))
+	}
+	if entry.CPUShares > 0 {
+		add("cpu_shares", entry.CPUShares)
+	}
+	if entry.BlkioWeight > 0 {
+		add("blkio_config", yaml.MapSlice{{Key: "weight", Value: entry.BlkioWeight}})
+	}
+	if len(entry.Provides) > 0 {
+		add("x-envcli-provides", entry.Provides)
+	}
+
+	return service
+}
+
+// composeUlimits converts the ulimits (a limit or soft:hard) into the compose format, sorted by name
+func composeUlimits(ulimits map[string]string) yaml.MapSlice {
+	var result yaml.MapSlice
+	for _, item := range document(ulimits).(yaml.MapSlice) {
+		value := item.Value.(string)
+		if soft, hard, found := strings.Cut(value, ":"); found {
+			result = append(result, yaml.MapItem{Key: item.Key, Value: yaml.MapSlice{{Key: "soft", Value: ulimitValue(soft)}, {Key: "hard", Value: ulimitValue(hard)}}})
+		} else {
+			result = append(result, yaml.MapItem{Key: item.Key, Value: ulimitValue(value)})
+		}
+	}
+	return result
+}
+
+// ulimitValue returns the limit as number, unlimited is -1 like for docker
+func ulimitValue(value string) interface{} {
+	if value == "unlimited" {
+		return -1
+	}
+	if number, err := strconv.Atoi(value); err == nil {
+		return number
+	}
+	return value
+}
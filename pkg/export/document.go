@@ -0,0 +1,118 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// document converts the value into a yaml document using the yaml names of the struct fields, in the order of the struct.
+// Fields with a zero value are omitted, which keeps the export short - pointers set to a zero value (ex. init: false) are kept.
+func document(value interface{}) interface{} {
+	return documentValue(reflect.ValueOf(value))
+}
+
+func documentValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return documentValue(v.Elem())
+	case reflect.Struct:
+		var fields yaml.MapSlice
+		for i := 0; i < v.NumField(); i++ {
+			name, ok := fieldName(v.Type().Field(i))
+			if !ok || v.Field(i).IsZero() {
+				continue
+			}
+			if value := documentValue(v.Field(i)); !isEmpty(value) {
+				fields = append(fields, yaml.MapItem{Key: name, Value: value})
+			}
+		}
+		return fields
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, documentValue(v.Index(i)))
+		}
+		return items
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		values := make(map[string]reflect.Value, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+			values[key.String()] = v.MapIndex(key)
+		}
+		sort.Strings(keys)
+
+		var items yaml.MapSlice
+		for _, key := range keys {
+			items = append(items, yaml.MapItem{Key: key, Value: documentValue(values[key])})
+		}
+		return items
+	}
+	return v.Interface()
+}
+
+// fieldName returns the yaml name of the struct field, false for fields excluded from yaml
+func fieldName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		return "", false
+	}
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "-" {
+		return "", false
+	} else if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, true
+}
+
+// isEmpty returns true for objects whose fields all have been omitted
+func isEmpty(value interface{}) bool {
+	fields, ok := value.(yaml.MapSlice)
+	return ok && len(fields) == 0
+}
+
+// orderedJSON marshals a yaml document into json, keeping the order of the keys
+type orderedJSON struct {
+	value interface{}
+}
+
+func (o orderedJSON) MarshalJSON() ([]byte, error) {
+	switch v := o.value.(type) {
+	case yaml.MapSlice:
+		var buffer bytes.Buffer
+		buffer.WriteByte('{')
+		for i, item := range v {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			key, err := json.Marshal(item.Key)
+			if err != nil {
+				return nil, err
+			}
+			value, err := json.Marshal(orderedJSON{item.Value})
+			if err != nil {
+				return nil, err
+			}
+			buffer.Write(key)
+			buffer.WriteByte(':')
+			buffer.Write(value)
+		}
+		buffer.WriteByte('}')
+		return buffer.Bytes(), nil
+	case []interface{}:
+		items := make([]orderedJSON, 0, len(v))
+		for _, item := range v {
+			items = append(items, orderedJSON{item})
+		}
+		return json.Marshal(items)
+	}
+	return json.Marshal(o.value)
+}
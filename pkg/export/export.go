@@ -0,0 +1,167 @@
+// Package export writes the merged configuration for external tools, as envcli yaml, json or docker-compose file.
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+// Export formats
+const (
+	FormatEnvCLI  = "envcli"
+	FormatCompose = "compose"
+	FormatJSON    = "json"
+)
+
+// Formats are all supported export formats
+var Formats = []string{FormatEnvCLI, FormatCompose, FormatJSON}
+
+// Options configure the resolution of the configuration
+type Options struct {
+	// ProjectDirectory is mounted into the containers and used by the templates
+	ProjectDirectory string
+
+	// CachePath is the host directory of the cache mounts, caches are not exported to compose if empty
+	CachePath string
+
+	// Variant is applied to the entries defining variants, see config.ApplyVariant
+	Variant string
+
+	// Getenv reads the host environment (ENVCLI_VARIANT and the env passthrough)
+	Getenv func(string) string
+
+	// Environ lists the host environment, used to expand the env passthrough globs of compose services
+	Environ []string
+}
+
+// Resolve returns the configuration with the templates rendered, the inheritance and the variant applied and all paths absolute.
+// Entries with templates that can't be rendered are a error, all failing entries are reported at once.
+func Resolve(ctx context.Context, cfg config.ConfigurationFile, opts Options) (config.ConfigurationFile, error) {
+	templateData := config.NewTemplateContext(ctx, opts.ProjectDirectory)
+
+	result := cfg
+	if result.Version == "" {
+		result.Version = "v1"
+	}
+	result.Extends = ""
+	result.InheritParentConfigs = false
+	result.Images = nil
+
+	var failures []string
+	for _, entry := range cfg.Images {
+		if len(entry.Variants) > 0 {
+			variantEntry, err := config.ApplyVariant(entry, opts.Variant, opts.Getenv)
+			if err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+			if variantEntry.Variant != "" {
+				// the applied variant replaces the variants
+				variantEntry.Variants = nil
+			}
+			entry = variantEntry
+		}
+
+		rendered, err := config.RenderEntryTemplates(entry, templateData)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("entry %s (%s): %s", entry.Name, entry.Source, err))
+			continue
+		}
+		variants := rendered.Variants
+		rendered.Variants = nil
+		for _, name := range config.VariantNames(config.RunConfigurationEntry{Variants: variants}) {
+			variant := variants[name]
+			variant.Name = entry.Name + ".variants." + name
+			renderedVariant, variantErr := config.RenderEntryTemplates(variant, templateData)
+			if variantErr != nil {
+				failures = append(failures, fmt.Sprintf("entry %s (%s): %s", entry.Name, entry.Source, variantErr))
+				continue
+			}
+			renderedVariant.Name = ""
+			if rendered.Variants == nil {
+				rendered.Variants = make(map[string]config.RunConfigurationEntry, len(variants))
+			}
+			rendered.Variants[name] = renderedVariant
+		}
+
+		result.Images = append(result.Images, absolutePaths(rendered))
+	}
+	if len(failures) > 0 {
+		return config.ConfigurationFile{}, errors.New("unresolvable configuration:\n  " + strings.Join(failures, "\n  "))
+	}
+
+	return result, nil
+}
+
+// absolutePaths resolves the paths relative to the configuration file of the entry, the exported file can be stored anywhere
+func absolutePaths(entry config.RunConfigurationEntry) config.RunConfigurationEntry {
+	// the inheritance has already been applied and the scope is internal
+	entry.Extends = ""
+	entry.Scope = ""
+
+	if entry.IsBuild() {
+		entry.Build.Dockerfile, entry.Build.Context = config.GetBuildPaths(entry)
+		entry.Image = ""
+	}
+	if entry.SecurityOpt != nil {
+		securityOpts := make([]string, 0, len(entry.SecurityOpt))
+		for _, opt := range entry.SecurityOpt {
+			securityOpts = append(securityOpts, config.ResolveSecurityOpt(opt, entry.Source))
+		}
+		entry.SecurityOpt = securityOpts
+	}
+	if entry.EnvFile != nil {
+		envFiles := make([]config.EnvFileEntry, 0, len(entry.EnvFile))
+		for _, envFile := range entry.EnvFile {
+			envFile.Path = config.ResolveEnvFilePath(envFile.Path, entry.Source)
+			envFiles = append(envFiles, envFile)
+		}
+		entry.EnvFile = envFiles
+	}
+	return entry
+}
+
+// Write writes the resolved configuration in the format
+func Write(w io.Writer, format string, cfg config.ConfigurationFile, opts Options) error {
+	switch format {
+	case FormatEnvCLI:
+		content, err := yaml.Marshal(document(cfg))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	case FormatJSON:
+		content, err := json.MarshalIndent(orderedJSON{document(cfg)}, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(content))
+		return err
+	case FormatCompose:
+		content, err := yaml.Marshal(compose(cfg, opts))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	}
+	return ValidateFormat(format)
+}
+
+// ValidateFormat returns a error if the export format is not supported
+func ValidateFormat(format string) error {
+	for _, supported := range Formats {
+		if format == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported export format %s - allowed: %s", format, strings.Join(Formats, ","))
+}
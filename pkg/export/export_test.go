@@ -0,0 +1,44 @@
+package export
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+func TestDocumentOmitsZeroValues(t *testing.T) {
+	disabled := false
+	cfg := config.ConfigurationFile{Images: []config.RunConfigurationEntry{{Name: "node", Image: "node:20", Init: &disabled, Source: "/project/.envcli.yml"}}}
+
+	content, err := yaml.Marshal(document(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "images:\n- name: node\n  image: node:20\n  init: false\n"; string(content) != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, content)
+	}
+}
+
+func TestResolveKeepsVariantsOfTheConfiguration(t *testing.T) {
+	cfg := config.ConfigurationFile{Images: []config.RunConfigurationEntry{{
+		Name:     "node",
+		Image:    "node:20",
+		Variants: map[string]config.RunConfigurationEntry{"ci": {Env: []string{"BUILD={{ printf \"%d\" 1 }}"}}},
+	}}}
+
+	resolved, err := Resolve(context.Background(), cfg, Options{Getenv: func(string) string { return "" }})
+	if err != nil || resolved.Images[0].Variants["ci"].Env[0] != "BUILD=1" {
+		t.Fatalf("expected the template of the variant to be rendered, got %+v (%v)", resolved.Images, err)
+	}
+	if cfg.Images[0].Variants["ci"].Env[0] != "BUILD={{ printf \"%d\" 1 }}" {
+		t.Errorf("expected the configuration to be unchanged, got %v", cfg.Images[0].Variants)
+	}
+
+	cfg.Images[0].Variants["ci"] = config.RunConfigurationEntry{Env: []string{"TAG={{ .Tag }}"}}
+	if _, err = Resolve(context.Background(), cfg, Options{Getenv: func(string) string { return "" }}); err == nil || !strings.Contains(err.Error(), "node.variants.ci.env[0]") {
+		t.Errorf("expected the variant field in the error, got %v", err)
+	}
+}
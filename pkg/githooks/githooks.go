@@ -0,0 +1,127 @@
+// Package githooks installs the git hooks that pull the changed images of the envcli config after a merge or checkout.
+package githooks
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/shellsetup"
+)
+
+// markers guarding the block managed by envcli hooks install
+const (
+	BeginMarker = "# >>> envcli hooks >>>"
+	EndMarker   = "# <<< envcli hooks <<<"
+)
+
+// BackgroundPullProperty disables the background pulls of the hooks if set to false, ex. on metered connections
+const BackgroundPullProperty = "hooks-background-pull"
+
+// HookNames are the git hooks envcli installs its block into
+var HookNames = []string{"post-merge", "post-checkout"}
+
+// shebang of hook files created by envcli
+const shebang = "#!/bin/sh\n"
+
+// Block returns the hook script including the markers. The script hashes the config files in the repository root and runs
+// envcli pull --changed-only in the background if the hash differs from the last run. Checkouts of single files are ignored.
+func Block(configFilenames []string) string {
+	var files []string
+	for _, filename := range configFilenames {
+		files = append(files, `"`+filename+`"`)
+	}
+
+	return BeginMarker + "\n" +
+		"# pulls the changed images of the envcli config in the background, remove with: envcli hooks uninstall\n" +
+		`if [ "$(basename "$0")" != "post-checkout" ] || [ "$3" = "1" ]; then` + "\n" +
+		`  envcli_state="$(git rev-parse --git-dir)/envcli-config.hash"` + "\n" +
+		`  envcli_hash="$(cat ` + strings.Join(files, " ") + ` 2>/dev/null | git hash-object --stdin)"` + "\n" +
+		`  if [ "$envcli_hash" != "$(cat "$envcli_state" 2>/dev/null)" ] && command -v envcli >/dev/null 2>&1; then` + "\n" +
+		`    case "$(envcli config get ` + BackgroundPullProperty + ` 2>/dev/null)" in` + "\n" +
+		`      *"[false]"*) ;;` + "\n" +
+		`      *) echo "$envcli_hash" > "$envcli_state"; (envcli pull --changed-only >/dev/null 2>&1 &) ;;` + "\n" +
+		`    esac` + "\n" +
+		`  fi` + "\n" +
+		`fi` + "\n" +
+		EndMarker + "\n"
+}
+
+// Directory returns the hooks directory of the git repository, core.hooksPath is respected
+func Directory(ctx context.Context, dir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--git-path", "hooks")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errors.New(dir + " is not within a git repository")
+	}
+
+	hooksDir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(dir, hooksDir)
+	}
+	return hooksDir, nil
+}
+
+// Install adds the block to the hooks, existing hooks are kept. Returns the files that have been changed, installing twice doesn't change the files.
+func Install(hooksDir string, block string) ([]string, error) {
+	var changed []string
+	for _, name := range HookNames {
+		file := filepath.Join(hooksDir, name)
+		content, err := os.ReadFile(file)
+		if err != nil && !os.IsNotExist(err) {
+			return changed, err
+		}
+		if len(content) == 0 {
+			content = []byte(shebang)
+		}
+
+		updated, ok := shellsetup.ApplyMarkedBlock(string(content), block, BeginMarker, EndMarker)
+		if !ok {
+			continue
+		}
+		if err = os.MkdirAll(hooksDir, os.ModePerm); err != nil {
+			return changed, err
+		}
+		if err = os.WriteFile(file, []byte(updated), 0755); err != nil {
+			return changed, err
+		}
+		if err = os.Chmod(file, 0755); err != nil {
+			return changed, err
+		}
+		changed = append(changed, file)
+	}
+	return changed, nil
+}
+
+// Uninstall removes the block from the hooks, hooks that only contained the block are deleted. Returns the files that have been changed.
+func Uninstall(hooksDir string) ([]string, error) {
+	var changed []string
+	for _, name := range HookNames {
+		file := filepath.Join(hooksDir, name)
+		content, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return changed, err
+		}
+
+		updated, ok := shellsetup.RemoveMarkedBlock(string(content), BeginMarker, EndMarker)
+		if !ok {
+			continue
+		}
+		if strings.TrimSpace(updated) == "" || updated == shebang {
+			err = os.Remove(file)
+		} else {
+			err = os.WriteFile(file, []byte(updated), 0755)
+		}
+		if err != nil {
+			return changed, err
+		}
+		changed = append(changed, file)
+	}
+	return changed, nil
+}
@@ -0,0 +1,117 @@
+package githooks
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInstallAndUninstall(t *testing.T) {
+	hooksDir := t.TempDir()
+	existing := "#!/bin/sh\necho existing\n"
+	if err := os.WriteFile(filepath.Join(hooksDir, "post-merge"), []byte(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+	block := Block([]string{".envcli.yml"})
+
+	changed, err := Install(hooksDir, block)
+	if err != nil || len(changed) != 2 {
+		t.Fatalf("expected both hooks to be installed, got %v (%v)", changed, err)
+	}
+	content, _ := os.ReadFile(filepath.Join(hooksDir, "post-merge"))
+	if !strings.HasPrefix(string(content), existing) || !strings.Contains(string(content), block) {
+		t.Errorf("expected the block to be appended to the existing hook, got %q", content)
+	}
+
+	// idempotent
+	if changed, err = Install(hooksDir, block); err != nil || len(changed) != 0 {
+		t.Errorf("expected no changes on the second install, got %v (%v)", changed, err)
+	}
+
+	changed, err = Uninstall(hooksDir)
+	if err != nil || len(changed) != 2 {
+		t.Fatalf("expected both hooks to be uninstalled, got %v (%v)", changed, err)
+	}
+	if content, _ = os.ReadFile(filepath.Join(hooksDir, "post-merge")); string(content) != existing {
+		t.Errorf("expected the existing hook to be restored, got %q", content)
+	}
+	if _, err = os.Stat(filepath.Join(hooksDir, "post-checkout")); !os.IsNotExist(err) {
+		t.Errorf("expected the hook created by envcli to be removed, got %v", err)
+	}
+}
+
+func TestHookPullsOnConfigChange(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hook script requires a posix shell")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// fake envcli recording its invocations
+	binDir := t.TempDir()
+	calls := filepath.Join(binDir, "calls")
+	fake := "#!/bin/sh\nif [ \"$1\" = config ]; then echo \"$2 [$ENVCLI_TEST_PROPERTY]\"; exit 0; fi\necho \"$@\" >> " + calls + "\n"
+	if err := os.WriteFile(filepath.Join(binDir, "envcli"), []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	hooksDir, err := Directory(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Install(hooksDir, Block([]string{".envcli.yml"})); err != nil {
+		t.Fatal(err)
+	}
+
+	runHook := func() {
+		cmd := exec.Command(filepath.Join(hooksDir, "post-merge"), "0")
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("hook failed: %v\n%s", err, out)
+		}
+	}
+	pulls := func() int {
+		// the pull runs in the background
+		time.Sleep(200 * time.Millisecond)
+		content, _ := os.ReadFile(calls)
+		return strings.Count(string(content), "pull --changed-only")
+	}
+
+	if err = os.WriteFile(filepath.Join(repo, ".envcli.yml"), []byte("images: []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runHook()
+	if count := pulls(); count != 1 {
+		t.Errorf("expected a pull after the config changed, got %d", count)
+	}
+	runHook()
+	if count := pulls(); count != 1 {
+		t.Errorf("expected no pull for an unchanged config, got %d", count)
+	}
+
+	// disabled by the property
+	t.Setenv("ENVCLI_TEST_PROPERTY", "false")
+	if err = os.WriteFile(filepath.Join(repo, ".envcli.yml"), []byte("images: [{name: node}]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runHook()
+	if count := pulls(); count != 1 {
+		t.Errorf("expected no pull if %s is false, got %d", BackgroundPullProperty, count)
+	}
+}
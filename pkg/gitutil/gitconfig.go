@@ -0,0 +1,64 @@
+package gitutil
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Identity holds the git user identity
+type Identity struct {
+	Name  string
+	Email string
+}
+
+// GetGlobalConfigFile returns the path of the git configuration of the current user
+func GetGlobalConfigFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".gitconfig")
+}
+
+// ReadIdentity reads the user.name and user.email from a git configuration file
+func ReadIdentity(file string) (Identity, error) {
+	var identity Identity
+
+	f, err := os.Open(file)
+	if err != nil {
+		return identity, err
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+		if section != "user" {
+			continue
+		}
+
+		pair := strings.SplitN(line, "=", 2)
+		if len(pair) != 2 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(pair[0]))
+		value := strings.Trim(strings.TrimSpace(pair[1]), "\"")
+		if key == "name" {
+			identity.Name = value
+		} else if key == "email" {
+			identity.Email = value
+		}
+	}
+
+	return identity, scanner.Err()
+}
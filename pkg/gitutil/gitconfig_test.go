@@ -0,0 +1,35 @@
+package gitutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadIdentity(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".gitconfig")
+	content := `# comment
+[core]
+	name = not-the-user
+[user]
+	name = "Jane Doe"
+	email = jane@example.com
+[alias]
+	st = status
+`
+	_ = os.WriteFile(file, []byte(content), 0600)
+
+	identity, err := ReadIdentity(file)
+	if err != nil {
+		t.Fatalf("failed to read identity: %v", err)
+	}
+	if identity.Name != "Jane Doe" || identity.Email != "jane@example.com" {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+}
+
+func TestReadIdentityMissingFile(t *testing.T) {
+	if _, err := ReadIdentity(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
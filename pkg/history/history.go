@@ -0,0 +1,100 @@
+// Package history stores the envcli invocations as JSON lines, to list and re-run them later (envcli history, envcli rerun).
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/atomicfile"
+)
+
+// DefaultSize is the maximum number of entries kept in the history
+const DefaultSize = 1000
+
+// Entry is a single envcli invocation
+type Entry struct {
+	Time time.Time `json:"time"`
+
+	// Directory is the working directory of the invocation
+	Directory string `json:"directory"`
+
+	// Args are the envcli arguments (ex. run --env A=b npm test), secrets are masked
+	Args []string `json:"args"`
+
+	ExitCode int `json:"exitCode"`
+
+	Duration time.Duration `json:"duration"`
+
+	// Masked is true if secrets have been removed from the arguments, the entry can't be executed again
+	Masked bool `json:"masked,omitempty"`
+}
+
+// CommandLine returns the envcli command line of the entry
+func (e Entry) CommandLine() string {
+	quoted := []string{"envcli"}
+	for _, arg := range e.Args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'$") {
+			arg = fmt.Sprintf("%q", arg)
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// Append appends the entry to the history file and drops the oldest entries beyond size, the file is created if it doesn't exist
+func Append(file string, entry Entry, size int) error {
+	entries, err := Read(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	entries = append(entries, entry)
+	if size > 0 && len(entries) > size {
+		entries = entries[len(entries)-size:]
+	}
+
+	var data bytes.Buffer
+	for _, e := range entries {
+		line, marshalErr := json.Marshal(e)
+		if marshalErr != nil {
+			return marshalErr
+		}
+		data.Write(append(line, '\n'))
+	}
+	if err = os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(file, data.Bytes(), 0600)
+}
+
+// Read returns all entries of the history file, oldest first
+func Read(file string) ([]Entry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid history entry in line %d of %s: %w", line, file, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
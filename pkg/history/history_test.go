@@ -0,0 +1,27 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendRead(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history", "history.jsonl")
+	for _, exitCode := range []int{0, 1, 2} {
+		if err := Append(file, Entry{Directory: "/src/app", Args: []string{"run", "npm", "test"}, ExitCode: exitCode}, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := Read(file)
+	if err != nil || len(entries) != 2 || entries[0].ExitCode != 1 || entries[1].ExitCode != 2 {
+		t.Errorf("expected the last two entries, got %+v (%v)", entries, err)
+	}
+}
+
+func TestCommandLine(t *testing.T) {
+	entry := Entry{Args: []string{"run", "--env=A=b c", "echo", ""}}
+	if line := entry.CommandLine(); line != `envcli run "--env=A=b c" echo ""` {
+		t.Errorf("unexpected command line %s", line)
+	}
+}
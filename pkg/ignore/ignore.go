@@ -0,0 +1,206 @@
+// Package ignore parses .envcliignore files, which exclude project files using the gitignore syntax (negations, directory patterns and ** wildcards).
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FileName is the name of the ignore file in the project directory
+const FileName = ".envcliignore"
+
+// rule is a single pattern of the ignore file
+type rule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// Matcher decides if a path is excluded, the last matching pattern wins
+type Matcher struct {
+	rules []rule
+}
+
+// Load reads the ignore file of the directory, a missing file results in a matcher that doesn't exclude anything
+func Load(directory string) (*Matcher, error) {
+	file, err := os.Open(filepath.Join(directory, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &Matcher{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Parse reads the patterns, one per line
+func Parse(r io.Reader) (*Matcher, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return New(lines), nil
+}
+
+// New creates a matcher for the patterns, blank lines and comments (#) are skipped
+func New(patterns []string) *Matcher {
+	m := &Matcher{}
+	for _, pattern := range patterns {
+		if r, ok := compile(pattern); ok {
+			m.rules = append(m.rules, r)
+		}
+	}
+	return m
+}
+
+// Match returns true if the path (slash-separated, relative to the project directory) is excluded.
+// A path within an excluded directory is always excluded, it can't be included again by a negation - same as git.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	if m == nil || len(m.rules) == 0 {
+		return false
+	}
+
+	path = strings.Trim(filepath.ToSlash(path), "/")
+	segments := strings.Split(path, "/")
+	for i := 1; i < len(segments); i++ {
+		if m.matchPath(strings.Join(segments[:i], "/"), true) {
+			return true
+		}
+	}
+	return m.matchPath(path, isDir)
+}
+
+// matchPath applies the rules to the path itself, ignoring its parent directories
+func (m *Matcher) matchPath(path string, isDir bool) bool {
+	excluded := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.pattern.MatchString(path) {
+			excluded = !r.negate
+		}
+	}
+	return excluded
+}
+
+// Walk walks the directory like filepath.WalkDir, but skips excluded files and directories.
+// The paths passed to fn are not relative, but the same as with filepath.WalkDir.
+func (m *Matcher) Walk(root string, fn fs.WalkDirFunc) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && path != root {
+			if relative, relErr := filepath.Rel(root, path); relErr == nil && m.matchPath(filepath.ToSlash(relative), entry.IsDir()) {
+				// the parents have already been checked while walking
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		return fn(path, entry, err)
+	})
+}
+
+// compile converts a gitignore pattern into a rule, false is returned for blank lines and comments
+func compile(pattern string) (rule, bool) {
+	var r rule
+
+	pattern = trimTrailingSpaces(pattern)
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return r, false
+	}
+	if strings.HasPrefix(pattern, "!") {
+		r.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\!`) || strings.HasPrefix(pattern, `\#`) {
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		r.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return r, false
+	}
+
+	// patterns with a slash at the start or in the middle are relative to the project directory, others match at any level
+	var expr strings.Builder
+	expr.WriteString("^")
+	if strings.HasPrefix(pattern, "/") {
+		pattern = pattern[1:]
+	} else if !strings.Contains(pattern, "/") {
+		expr.WriteString("(?:.*/)?")
+	}
+	expr.WriteString(translate(pattern))
+	expr.WriteString("$")
+
+	compiled, err := regexp.Compile(expr.String())
+	if err != nil {
+		return r, false
+	}
+	r.pattern = compiled
+	return r, true
+}
+
+// translate converts the glob into a regular expression
+func translate(pattern string) string {
+	var expr strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/") && (i == 0 || pattern[i-1] == '/'):
+			// leading or middle **/ matches zero or more directories
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**") && i+2 == len(pattern) && (i == 0 || pattern[i-1] == '/'):
+			// trailing /** matches everything inside
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			// other consecutive asterisks are regular asterisks
+			for i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+			}
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String()
+}
+
+// trimTrailingSpaces removes trailing spaces, unless they are escaped with a backslash
+func trimTrailingSpaces(pattern string) string {
+	for strings.HasSuffix(pattern, " ") && !strings.HasSuffix(pattern, `\ `) {
+		pattern = pattern[:len(pattern)-1]
+	}
+	return pattern
+}
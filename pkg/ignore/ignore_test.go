@@ -0,0 +1,132 @@
+package ignore
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		patterns []string
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		// basename patterns match at any level
+		{[]string{"*.log"}, "debug.log", false, true},
+		{[]string{"*.log"}, "logs/debug.log", false, true},
+		{[]string{"*.log"}, "debug.log.txt", false, false},
+		{[]string{"debug?.log"}, "debug1.log", false, true},
+		{[]string{"debug?.log"}, "debug10.log", false, false},
+		{[]string{"debug[0-9].log"}, "debug3.log", false, true},
+		{[]string{"debug[!01].log"}, "debug1.log", false, false},
+		{[]string{"debug[!01].log"}, "debug2.log", false, true},
+
+		// leading and middle slashes anchor the pattern to the project directory
+		{[]string{"/debug.log"}, "debug.log", false, true},
+		{[]string{"/debug.log"}, "logs/debug.log", false, false},
+		{[]string{"logs/debug.log"}, "logs/debug.log", false, true},
+		{[]string{"logs/debug.log"}, "build/logs/debug.log", false, false},
+		{[]string{"/*.c"}, "cat-file.c", false, true},
+		{[]string{"/*.c"}, "mozilla-sha1/sha1.c", false, false},
+
+		// trailing slashes only match directories, everything within is excluded
+		{[]string{"build/"}, "build", true, true},
+		{[]string{"build/"}, "build", false, false},
+		{[]string{"build/"}, "build/out/app", false, true},
+		{[]string{"build/"}, "src/build/app", false, true},
+		{[]string{"doc/frotz/"}, "doc/frotz", true, true},
+		{[]string{"doc/frotz/"}, "a/doc/frotz", true, false},
+
+		// ** wildcards
+		{[]string{"**/logs"}, "logs", true, true},
+		{[]string{"**/logs"}, "a/b/logs", true, true},
+		{[]string{"**/logs/debug.log"}, "build/logs/debug.log", false, true},
+		{[]string{"logs/**"}, "logs/a/debug.log", false, true},
+		{[]string{"logs/**"}, "logs", true, false},
+		{[]string{"a/**/b"}, "a/b", false, true},
+		{[]string{"a/**/b"}, "a/x/y/b", false, true},
+		{[]string{"a/**/b"}, "a/xb", false, false},
+		{[]string{"foo**bar"}, "foobazbar", false, true},
+		{[]string{"foo**bar"}, "foo/bar", false, false},
+		{[]string{"**"}, "any/path", false, true},
+
+		// negations, the last matching pattern wins
+		{[]string{"*.log", "!important.log"}, "important.log", false, false},
+		{[]string{"*.log", "!important.log"}, "debug.log", false, true},
+		{[]string{"!important.log", "*.log"}, "important.log", false, true},
+		{[]string{"logs/", "!logs/important.log"}, "logs/important.log", false, true},
+		{[]string{"logs/*", "!logs/important.log"}, "logs/important.log", false, false},
+
+		// comments, blank lines and escapes
+		{[]string{"# comment", "", "   "}, "# comment", false, false},
+		{[]string{`\#file`}, "#file", false, true},
+		{[]string{`\!file`}, "!file", false, true},
+		{[]string{"file.txt   "}, "file.txt", false, true},
+		{[]string{`file\ `}, "file ", false, true},
+		{[]string{`\*.txt`}, "*.txt", false, true},
+		{[]string{`\*.txt`}, "a.txt", false, false},
+	}
+
+	for _, c := range cases {
+		if matched := New(c.patterns).Match(c.path, c.isDir); matched != c.expected {
+			t.Errorf("patterns %q, path %s (dir: %v): expected %v, got %v", c.patterns, c.path, c.isDir, c.expected, matched)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	m, err := Parse(strings.NewReader("# generated\nnode_modules/\n*.tmp\n!keep.tmp\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.Match("node_modules/pkg/index.js", false) || !m.Match("a.tmp", false) || m.Match("keep.tmp", false) || m.Match("src/main.go", false) {
+		t.Errorf("unexpected matches of the parsed patterns")
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	m, err := Load(t.TempDir())
+	if err != nil || m.Match("anything", false) {
+		t.Errorf("expected a matcher that doesn't exclude anything, got %v", err)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"main.go", "debug.log", "build/app", "src/a.go", "src/gen/b.go"} {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("*.log\nbuild/\nsrc/gen\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []string
+	err = m.Walk(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			relative, _ := filepath.Rel(dir, path)
+			files = append(files, filepath.ToSlash(relative))
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{FileName, "main.go", "src/a.go"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+}
@@ -0,0 +1,68 @@
+// Package notify shows desktop notifications using the notifier of the operating system.
+package notify
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNoNotifier is returned if the operating system has no supported notifier
+var ErrNoNotifier = errors.New("no desktop notifier available")
+
+// timeout of the notifier, notifications must not delay envcli
+const timeout = 5 * time.Second
+
+// Send shows a desktop notification: osascript on macOS, notify-send on Linux and a toast on Windows
+func Send(ctx context.Context, title string, message string) error {
+	args := Command(runtime.GOOS, title, message, exec.LookPath)
+	if args == nil {
+		return ErrNoNotifier
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	log.Debug().Str("notifier", args[0]).Str("title", title).Msg("sending desktop notification")
+	return exec.CommandContext(ctx, args[0], args[1:]...).Run()
+}
+
+// Command returns the notifier command for the operating system, or nil if the notifier isn't installed
+func Command(goos string, title string, message string, lookPath func(file string) (string, error)) []string {
+	var args []string
+	switch goos {
+	case "darwin":
+		// the texts are passed as arguments, to avoid escaping them within the script
+		args = []string{"osascript", "-e", "on run argv", "-e", "display notification (item 2 of argv) with title (item 1 of argv)", "-e", "end run", title, message}
+	case "windows":
+		args = []string{"powershell", "-NoProfile", "-Command", windowsToastScript(title, message)}
+	default:
+		args = []string{"notify-send", title, message}
+	}
+
+	if _, err := lookPath(args[0]); err != nil {
+		return nil
+	}
+	return args
+}
+
+// windowsToastScript returns the powershell script showing a toast notification
+func windowsToastScript(title string, message string) string {
+	return strings.Join([]string{
+		"[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null",
+		"$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)",
+		"$text = $template.GetElementsByTagName('text')",
+		"$text.Item(0).AppendChild($template.CreateTextNode(" + powershellQuote(title) + ")) > $null",
+		"$text.Item(1).AppendChild($template.CreateTextNode(" + powershellQuote(message) + ")) > $null",
+		"[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('EnvCLI').Show([Windows.UI.Notifications.ToastNotification]::new($template))",
+	}, "; ")
+}
+
+// powershellQuote returns the text as single-quoted powershell string, which doesn't expand variables
+func powershellQuote(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
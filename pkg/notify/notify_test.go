@@ -0,0 +1,25 @@
+package notify
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	installed := func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	missing := func(file string) (string, error) { return "", errors.New("not found") }
+
+	if args := Command("linux", "envcli: go succeeded", "go test ./...", installed); strings.Join(args, "|") != "notify-send|envcli: go succeeded|go test ./..." {
+		t.Errorf("unexpected linux command %v", args)
+	}
+	if args := Command("darwin", "title", "message", installed); args[0] != "osascript" || args[len(args)-2] != "title" || args[len(args)-1] != "message" {
+		t.Errorf("unexpected macos command %v", args)
+	}
+	if args := Command("windows", "it's done", "message", installed); args[0] != "powershell" || !strings.Contains(args[3], "'it''s done'") {
+		t.Errorf("unexpected windows command %v", args)
+	}
+	if args := Command("linux", "title", "message", missing); args != nil {
+		t.Errorf("expected no command without notifier, got %v", args)
+	}
+}
@@ -0,0 +1,186 @@
+// Package output renders the results of the listing commands as table, json or yaml, selected by the global --output flag.
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats
+const (
+	// FormatTable renders lists as aligned columns and single results as label: value lines (default)
+	FormatTable = "table"
+
+	// FormatJSON renders the complete data as indented json
+	FormatJSON = "json"
+
+	// FormatYAML renders the complete data as yaml
+	FormatYAML = "yaml"
+)
+
+// Formats are all supported output formats
+var Formats = []string{FormatTable, FormatJSON, FormatYAML}
+
+// Validate returns a error if the output format is unknown
+func Validate(format string) error {
+	for _, allowed := range Formats {
+		if format == allowed {
+			return nil
+		}
+	}
+	return errors.New("invalid output format " + format + ", allowed: " + strings.Join(Formats, ","))
+}
+
+// Render writes the value in the format. The table format renders a slice of structs with one row per item and a single struct with one line per field.
+//
+// The table columns are the struct fields with a table tag, ex. `table:"NAME"`. Tag options:
+//   - a group name (ex. `table:"SOURCE,long"`) only shows the column if the group is passed in groups
+//   - omitempty skips empty fields of a single struct
+//
+// json and yaml always contain all fields, as scripts parsing the output should not depend on the selected columns.
+func Render(w io.Writer, format string, value interface{}, groups ...string) error {
+	switch format {
+	case FormatJSON:
+		content, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(content))
+		return err
+	case FormatYAML:
+		content, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	case FormatTable, "":
+		v := reflect.Indirect(reflect.ValueOf(value))
+		if v.Kind() == reflect.Slice {
+			return renderTable(w, v, groups)
+		}
+		return renderDetails(w, v, groups)
+	}
+	return Validate(format)
+}
+
+// column is a struct field rendered by the table format
+type column struct {
+	index     int
+	label     string
+	omitEmpty bool
+}
+
+// columns returns the visible columns of the struct type
+func columns(t reflect.Type, groups []string) []column {
+	var result []column
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup("table")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		options := strings.Split(tag, ",")
+		c := column{index: i, label: options[0]}
+		visible := true
+		for _, option := range options[1:] {
+			if option == "omitempty" {
+				c.omitEmpty = true
+			} else if !contains(groups, option) {
+				visible = false
+			}
+		}
+		if visible {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// renderTable renders the slice of structs as aligned columns
+func renderTable(w io.Writer, items reflect.Value, groups []string) error {
+	elemType := items.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return errors.New("table output requires a list of structs, got " + elemType.String())
+	}
+	cols := columns(elemType, groups)
+
+	tw := tabwriter.NewWriter(w, 1, 1, 2, ' ', 0)
+	var header []string
+	for _, c := range cols {
+		header = append(header, c.label)
+	}
+	_, _ = fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for i := 0; i < items.Len(); i++ {
+		item := reflect.Indirect(items.Index(i))
+		var row []string
+		for _, c := range cols {
+			row = append(row, formatValue(item.Field(c.index)))
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// renderDetails renders the struct as label: value lines, the values are aligned to the longest label
+func renderDetails(w io.Writer, item reflect.Value, groups []string) error {
+	if item.Kind() != reflect.Struct {
+		return errors.New("table output requires a struct, got " + item.Type().String())
+	}
+	cols := columns(item.Type(), groups)
+
+	width := 0
+	for _, c := range cols {
+		if len(c.label)+1 > width {
+			width = len(c.label) + 1
+		}
+	}
+	for _, c := range cols {
+		value := formatValue(item.Field(c.index))
+		if value == "" && c.omitEmpty {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%-*s %s\n", width, c.label+":", value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatValue formats a field for the table output, lists are separated by ", "
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		var items []string
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, formatValue(v.Index(i)))
+		}
+		return strings.Join(items, ", ")
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return ""
+		}
+		return formatValue(v.Elem())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package output
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "updates the golden files")
+
+type testRow struct {
+	Name     string   `json:"name" yaml:"name" table:"NAME"`
+	Provides []string `json:"provides" yaml:"provides" table:"PROVIDES"`
+	Source   string   `json:"source" yaml:"source" table:"SOURCE,long"`
+	Internal string   `json:"-" yaml:"-"`
+}
+
+type testDetails struct {
+	Command string   `json:"command" yaml:"command" table:"Command"`
+	Args    []string `json:"args,omitempty" yaml:"args,omitempty" table:"Args,omitempty"`
+	Runs    string   `json:"runs" yaml:"runs" table:"Runs"`
+}
+
+func TestRender(t *testing.T) {
+	rows := []testRow{
+		{Name: "node", Provides: []string{"node", "npm"}, Source: "/project/.envcli.yml", Internal: "hidden"},
+		{Name: "golang", Provides: []string{"go"}, Source: "/home/user/.envcli.yml"},
+	}
+	details := testDetails{Command: "npm", Runs: "container"}
+
+	var tests = []struct {
+		golden string
+		format string
+		value  interface{}
+		groups []string
+	}{
+		{"list.table", FormatTable, rows, nil},
+		{"list-long.table", FormatTable, rows, []string{"long"}},
+		{"list.json", FormatJSON, rows, nil},
+		{"list.yaml", FormatYAML, rows, nil},
+		{"details.table", FormatTable, details, nil},
+		{"details.json", FormatJSON, details, nil},
+		{"details.yaml", FormatYAML, details, nil},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+		if err := Render(&out, test.format, test.value, test.groups...); err != nil {
+			t.Fatalf("%s: %v", test.golden, err)
+		}
+		assertGolden(t, filepath.Join("testdata", test.golden), out.Bytes())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if Validate("json") != nil || Validate("csv") == nil {
+		t.Errorf("expected only the supported formats to be valid")
+	}
+	if Render(&bytes.Buffer{}, "csv", []testRow{}) == nil {
+		t.Errorf("expected a unknown format to be rejected")
+	}
+}
+
+// assertGolden compares the output with the golden file, go test -update rewrites the golden files
+func assertGolden(t *testing.T, file string, actual []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(file, actual, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("%s: expected\n%s\ngot\n%s", file, expected, actual)
+	}
+}
@@ -0,0 +1,148 @@
+// Package pathrewrite rewrites the container project path in the command output to the host project path, so that editors and CI annotations can resolve the referenced files.
+package pathrewrite
+
+import (
+	"bytes"
+	"io"
+)
+
+// maxPending is the maximum number of bytes held back to complete a path, more bytes are written without waiting for the end of the line
+const maxPending = 4096
+
+// Writer replaces the container path with the host path in everything written to it.
+// Bytes are only held back while they could be the start of the container path, call Flush once the output is complete.
+// The rewriting is disabled as soon as binary output (a NUL byte) is detected.
+type Writer struct {
+	out       io.Writer
+	from      []byte
+	to        []byte
+	separator byte
+	pending   []byte
+	binary    bool
+}
+
+// NewWriter creates a writer that replaces the container path from with the host path to, the separator of the host is used in the rewritten paths
+func NewWriter(out io.Writer, from string, to string, separator byte) *Writer {
+	return &Writer{out: out, from: []byte(from), to: []byte(to), separator: separator}
+}
+
+// Write rewrites the complete lines and writes them to the underlying writer, it always reports the full length as written
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.binary {
+		return len(p), writeAll(w.out, p)
+	}
+
+	data := append(w.pending, p...)
+	w.pending = nil
+	if bytes.IndexByte(data, 0) >= 0 {
+		w.binary = true
+		return len(p), writeAll(w.out, data)
+	}
+
+	// the incomplete last line is held back from the first position a path could be incomplete
+	complete := data
+	var rest []byte
+	if end := bytes.LastIndexByte(data, '\n'); end < len(data)-1 {
+		complete, rest = data[:end+1], data[end+1:]
+	}
+	if hold := w.holdIndex(rest); hold >= 0 && len(rest)-hold <= maxPending {
+		complete = data[:len(complete)+hold]
+		w.pending = append([]byte{}, rest[hold:]...)
+	} else {
+		complete = data
+	}
+
+	return len(p), writeAll(w.out, w.rewrite(complete))
+}
+
+// Flush rewrites and writes the bytes held back, ex. the last line without a line break
+func (w *Writer) Flush() error {
+	data := w.pending
+	w.pending = nil
+	if len(data) == 0 {
+		return nil
+	}
+	return writeAll(w.out, w.rewrite(data))
+}
+
+// holdIndex returns the position within the incomplete line from which the bytes have to be held back, -1 if nothing has to be held back
+func (w *Writer) holdIndex(rest []byte) int {
+	if len(rest) == 0 || len(w.from) == 0 {
+		return -1
+	}
+
+	// a complete occurrence at the end may be followed by more path characters
+	if index := bytes.LastIndex(rest, w.from); index >= 0 && isPathEnd(rest[index+len(w.from):]) {
+		return index
+	}
+
+	// the end may be the start of the container path
+	for length := len(w.from) - 1; length > 0; length-- {
+		if len(rest) >= length && bytes.Equal(rest[len(rest)-length:], w.from[:length]) {
+			return len(rest) - length
+		}
+	}
+	return -1
+}
+
+// rewrite replaces all occurrences of the container path, that are not part of a longer path (ex. /project2)
+func (w *Writer) rewrite(data []byte) []byte {
+	if len(w.from) == 0 || !bytes.Contains(data, w.from) {
+		return data
+	}
+
+	var result bytes.Buffer
+	for {
+		index := bytes.Index(data, w.from)
+		if index < 0 {
+			result.Write(data)
+			return result.Bytes()
+		}
+		end := index + len(w.from)
+		if end < len(data) && data[end] != '/' && isPathChar(data[end]) {
+			result.Write(data[:end])
+			data = data[end:]
+			continue
+		}
+
+		result.Write(data[:index])
+		result.Write(w.to)
+		pathEnd := end
+		for pathEnd < len(data) && isPathChar(data[pathEnd]) {
+			pathEnd++
+		}
+		path := data[end:pathEnd]
+		if w.separator != '/' {
+			path = bytes.ReplaceAll(path, []byte("/"), []byte{w.separator})
+		}
+		result.Write(path)
+		data = data[pathEnd:]
+	}
+}
+
+// isPathEnd returns true if the bytes after an occurrence could still continue the path
+func isPathEnd(data []byte) bool {
+	for _, b := range data {
+		if !isPathChar(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPathChar returns true for the bytes that are part of a path, the path ends at whitespace, quotes, brackets and the colon before line numbers
+func isPathChar(b byte) bool {
+	switch b {
+	case ' ', '\t', '\r', '\n', ':', '"', '\'', '`', '(', ')', '[', ']', '<', '>', ',', ';':
+		return false
+	}
+	return true
+}
+
+func writeAll(out io.Writer, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	_, err := out.Write(data)
+	return err
+}
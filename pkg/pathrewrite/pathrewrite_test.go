@@ -0,0 +1,87 @@
+package pathrewrite
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriterRewrite(t *testing.T) {
+	cases := []struct {
+		name      string
+		separator byte
+		input     string
+		expected  string
+	}{
+		{"compiler error", '/', "/project/src/main.go:12:5: undefined: foo\n", "/home/user/app/src/main.go:12:5: undefined: foo\n"},
+		{"project root", '/', "cd /project\n", "cd /home/user/app\n"},
+		{"longer path", '/', "/project2/main.go and /projects\n", "/project2/main.go and /projects\n"},
+		{"multiple", '/', "(/project/a.go) /project/b.go\n", "(/home/user/app/a.go) /home/user/app/b.go\n"},
+		{"windows", '\\', "/project/src/main.go:12: error\n", "C:\\Users\\user\\app\\src\\main.go:12: error\n"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			to := "/home/user/app"
+			if c.separator == '\\' {
+				to = "C:\\Users\\user\\app"
+			}
+
+			var out bytes.Buffer
+			w := NewWriter(&out, "/project", to, c.separator)
+			if _, err := w.Write([]byte(c.input)); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != c.expected {
+				t.Errorf("expected %q, got %q", c.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestWriterSplitWrites(t *testing.T) {
+	input := "error in /project/src/main.go:3\nprompt> "
+	var out bytes.Buffer
+	w := NewWriter(&out, "/project", "/app", '/')
+
+	// the path is split across all writes
+	for i := 0; i < len(input); i++ {
+		if _, err := w.Write([]byte{input[i]}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if out.String() != "error in /app/src/main.go:3\nprompt> " {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
+
+func TestWriterHoldsOnlyPossiblePaths(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out, "/project", "/app", '/')
+
+	_, _ = w.Write([]byte("Continue? [y/n] "))
+	if out.String() != "Continue? [y/n] " {
+		t.Errorf("expected prompt to be written without a line break, got %q", out.String())
+	}
+
+	_, _ = w.Write([]byte("see /proj"))
+	if out.String() != "Continue? [y/n] see " {
+		t.Errorf("expected the possible path to be held back, got %q", out.String())
+	}
+	_ = w.Flush()
+	if out.String() != "Continue? [y/n] see /proj" {
+		t.Errorf("expected flush to write the held back bytes, got %q", out.String())
+	}
+}
+
+func TestWriterBinary(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out, "/project", "/app", '/')
+
+	_, _ = w.Write([]byte("\x00\x01/project/file\n"))
+	_, _ = w.Write([]byte("/project/file\n"))
+	if out.String() != "\x00\x01/project/file\n/project/file\n" {
+		t.Errorf("expected binary output to be passed through, got %q", out.String())
+	}
+}
@@ -0,0 +1,50 @@
+package proxy
+
+import (
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// GetNoProxyEnvironment returns the value of the NO_PROXY environment variable (or its lowercase variant)
+func GetNoProxyEnvironment() string {
+	if value := os.Getenv("NO_PROXY"); value != "" {
+		return value
+	}
+	return os.Getenv("no_proxy")
+}
+
+// MergeNoProxy merges the no-proxy property with the NO_PROXY environment variable, the entries of the property come first and duplicates are removed
+func MergeNoProxy(property string, environment string) string {
+	var merged []string
+	seen := make(map[string]bool)
+
+	for _, value := range []string{property, environment} {
+		for _, entry := range strings.Split(value, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" || seen[strings.ToLower(entry)] {
+				continue
+			}
+			seen[strings.ToLower(entry)] = true
+			merged = append(merged, entry)
+		}
+	}
+
+	return strings.Join(merged, ",")
+}
+
+// ApplyEnvironment sets the proxy environment variables, which are used by all http clients of envcli (self-update, downloads)
+func ApplyEnvironment(httpProxy string, httpsProxy string, noProxy string) {
+	if httpProxy != "" {
+		_ = os.Setenv("HTTP_PROXY", httpProxy)
+	}
+	if httpsProxy != "" {
+		_ = os.Setenv("HTTPS_PROXY", httpsProxy)
+	}
+	if noProxy != "" {
+		_ = os.Setenv("NO_PROXY", noProxy)
+	}
+
+	log.Trace().Str("http-proxy", httpProxy).Str("https-proxy", httpsProxy).Str("no-proxy", noProxy).Msg("configured proxy environment")
+}
@@ -0,0 +1,27 @@
+package proxy
+
+import (
+	"testing"
+)
+
+func TestMergeNoProxy(t *testing.T) {
+	var tests = []struct {
+		property    string
+		environment string
+		expected    string
+	}{
+		{"", "", ""},
+		{"registry.local", "", "registry.local"},
+		{"", "localhost,127.0.0.1", "localhost,127.0.0.1"},
+		{"registry.local,10.0.0.0/8", "localhost", "registry.local,10.0.0.0/8,localhost"},
+		{"registry.local, localhost", "LOCALHOST,example.com", "registry.local,localhost,example.com"},
+		{" , registry.local,,", "", "registry.local"},
+	}
+
+	for _, test := range tests {
+		result := MergeNoProxy(test.property, test.environment)
+		if result != test.expected {
+			t.Errorf("MergeNoProxy(%q, %q): expected %q, got %q", test.property, test.environment, test.expected, result)
+		}
+	}
+}
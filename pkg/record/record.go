@@ -0,0 +1,114 @@
+// Package record stores envcli runs as JSON lines, to replay them later (ex. to reproduce a failed CI run locally).
+package record
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Run is a single recorded container run
+type Run struct {
+	Time time.Time `json:"time"`
+
+	// Command is the command and its arguments passed to envcli run
+	Command []string `json:"command"`
+
+	Image string `json:"image"`
+
+	// Digest is the id of the local image that has been executed
+	Digest string `json:"digest"`
+
+	// Runtime is the container runtime that executed the run command
+	Runtime string `json:"runtime"`
+
+	// RunArgs are the arguments of the container run command, the environment variables are passed by name without their values
+	RunArgs []string `json:"runArgs"`
+
+	// Env are the names of the environment variables passed into the container
+	Env []string `json:"env"`
+
+	// ProjectDirectory is the directory mounted into the container
+	ProjectDirectory string `json:"projectDirectory"`
+
+	ExitCode int `json:"exitCode"`
+
+	// Timings of the run, only recorded if the run has been timed
+	Timings *Timings `json:"timings,omitempty"`
+}
+
+// Timings break down the duration of a run into its phases
+type Timings struct {
+	// Config is the time spent loading the configuration and preparing the run command
+	Config time.Duration `json:"config"`
+
+	// ImageLocal is true if the image was present in the local image store
+	ImageLocal bool `json:"imageLocal"`
+
+	// Built is true if the image of an entry with a build section has been built or reused, Pull is the time spent on it
+	Built bool `json:"built,omitempty"`
+
+	// Pull is the time spent pulling or building the image, zero if it was present
+	Pull time.Duration `json:"pull"`
+
+	// PullBytes is the size of the pulled image, zero if it was present
+	PullBytes int64 `json:"pullBytes"`
+
+	// Startup is the time from the container run command to the first output of the command, zero if the command had no output
+	Startup time.Duration `json:"startup"`
+
+	// Execution is the time spent running the container, including its startup
+	Execution time.Duration `json:"execution"`
+
+	// Total is the total duration of the run
+	Total time.Duration `json:"total"`
+}
+
+// Append appends the run to the file, the file is created if it doesn't exist
+func Append(file string, run Run) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Read returns all runs of the file in the recorded order
+func Read(file string) ([]Run, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var runs []Run
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var run Run
+		if err = json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			return nil, fmt.Errorf("invalid record in line %d of %s: %w", line, file, err)
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, scanner.Err()
+}
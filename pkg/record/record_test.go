@@ -0,0 +1,21 @@
+package record
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAppendRead(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "runs", "record.jsonl")
+	for _, exitCode := range []int{0, 1} {
+		if err := Append(file, Run{Command: []string{"go", "test"}, Image: "golang:1.21", RunArgs: []string{"docker", "run", "-v", "/My Projects/app:/project", "golang:1.21"}, ExitCode: exitCode}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := Read(file)
+	if err != nil || len(runs) != 2 || runs[1].ExitCode != 1 || strings.Join(runs[0].Command, " ") != "go test" || runs[0].RunArgs[3] != "/My Projects/app:/project" {
+		t.Errorf("unexpected runs %+v (%v)", runs, err)
+	}
+}
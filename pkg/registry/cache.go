@@ -0,0 +1,52 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CacheTTL is the duration a cached image info is used, before it is requested again
+var CacheTTL = 24 * time.Hour
+
+type cacheEntry struct {
+	Image   string    `json:"image"`
+	Fetched time.Time `json:"fetched"`
+	Info    ImageInfo `json:"info"`
+}
+
+// InspectCached returns the cached image info if present and not expired, otherwise the image is inspected and the result is cached.
+// The image is the full reference, used as cache key. An empty cache directory disables caching.
+func (c *Client) InspectCached(cacheDir string, image string, registry string, repository string, reference string, refresh bool) (ImageInfo, error) {
+	var cacheFile string
+	if cacheDir != "" {
+		hash := sha256.Sum256([]byte(image))
+		cacheFile = filepath.Join(cacheDir, hex.EncodeToString(hash[:])+".json")
+	}
+
+	if cacheFile != "" && !refresh {
+		if data, err := os.ReadFile(cacheFile); err == nil {
+			var entry cacheEntry
+			if json.Unmarshal(data, &entry) == nil && time.Since(entry.Fetched) < CacheTTL {
+				return entry.Info, nil
+			}
+		}
+	}
+
+	info, err := c.Inspect(registry, repository, reference)
+	if err != nil {
+		return info, err
+	}
+
+	if cacheFile != "" {
+		if data, marshalErr := json.Marshal(cacheEntry{Image: image, Fetched: time.Now(), Info: info}); marshalErr == nil {
+			_ = os.MkdirAll(cacheDir, os.ModePerm)
+			_ = os.WriteFile(cacheFile, data, 0644)
+		}
+	}
+
+	return info, nil
+}
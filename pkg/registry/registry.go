@@ -0,0 +1,296 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// manifest media types
+const (
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+// ImageInfo holds the details of a image, collected from the registry
+type ImageInfo struct {
+	// Platforms contains all available platforms, ex. linux/amd64 or linux/arm64/v8
+	Platforms []string `json:"platforms"`
+
+	// Size is the compressed size of the image (linux/amd64 or the first platform for multi-arch images)
+	Size int64 `json:"size"`
+
+	// Created is the creation date of the image
+	Created time.Time `json:"created"`
+}
+
+// Client queries image manifests using the registry v2 api, anonymous bearer tokens are requested if required
+type Client struct {
+	HTTP *http.Client
+
+	// Endpoint returns the base url of the registry api
+	Endpoint func(registry string) string
+}
+
+// New creates a registry client, it respects the proxy environment variables
+func New() *Client {
+	return &Client{
+		HTTP:     &http.Client{Transport: &http.Transport{Proxy: http.ProxyFromEnvironment}, Timeout: 30 * time.Second},
+		Endpoint: DefaultEndpoint,
+	}
+}
+
+// DefaultEndpoint returns the https endpoint of the registry, the docker hub api is hosted on registry-1.docker.io
+func DefaultEndpoint(registry string) string {
+	if registry == "docker.io" {
+		registry = "registry-1.docker.io"
+	}
+	return "https://" + registry
+}
+
+// NormalizeRepository adds the library prefix to official docker hub images (alpine -> library/alpine)
+func NormalizeRepository(registry string, repository string) string {
+	if registry == "docker.io" && !strings.Contains(repository, "/") {
+		return "library/" + repository
+	}
+	return repository
+}
+
+type manifest struct {
+	MediaType string `json:"mediaType"`
+	Config    struct {
+		Digest string `json:"digest"`
+		Size   int64  `json:"size"`
+	} `json:"config"`
+	Layers []struct {
+		Size int64 `json:"size"`
+	} `json:"layers"`
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+			Variant      string `json:"variant"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+type imageConfig struct {
+	Created      time.Time `json:"created"`
+	OS           string    `json:"os"`
+	Architecture string    `json:"architecture"`
+	Variant      string    `json:"variant"`
+}
+
+// Inspect collects the platforms, size and creation date of the image, reference is a tag or digest
+func (c *Client) Inspect(registry string, repository string, reference string) (ImageInfo, error) {
+	var info ImageInfo
+	repository = NormalizeRepository(registry, repository)
+	token := ""
+
+	root, err := c.getManifest(registry, repository, reference, &token)
+	if err != nil {
+		return info, err
+	}
+
+	// multi-arch image: collect the platforms and inspect the linux/amd64 (or first) image
+	image := root
+	if root.MediaType == mediaTypeDockerManifestList || root.MediaType == mediaTypeOCIIndex || len(root.Manifests) > 0 {
+		selected := ""
+		for _, m := range root.Manifests {
+			platform := formatPlatform(m.Platform.OS, m.Platform.Architecture, m.Platform.Variant)
+			if platform == "unknown/unknown" {
+				// attestation manifests
+				continue
+			}
+			info.Platforms = append(info.Platforms, platform)
+			if selected == "" || platform == "linux/amd64" {
+				selected = m.Digest
+			}
+		}
+		if selected == "" {
+			return info, errors.New("image index of " + repository + ":" + reference + " doesn't contain any images")
+		}
+
+		image, err = c.getManifest(registry, repository, selected, &token)
+		if err != nil {
+			return info, err
+		}
+	}
+
+	info.Size = image.Config.Size
+	for _, layer := range image.Layers {
+		info.Size += layer.Size
+	}
+
+	var cfg imageConfig
+	if err = c.get(registry, "/v2/"+repository+"/blobs/"+image.Config.Digest, "", &token, &cfg); err != nil {
+		return info, err
+	}
+	info.Created = cfg.Created
+	if len(info.Platforms) == 0 {
+		info.Platforms = []string{formatPlatform(cfg.OS, cfg.Architecture, cfg.Variant)}
+	}
+
+	return info, nil
+}
+
+func (c *Client) getManifest(registry string, repository string, reference string, token *string) (manifest, error) {
+	var m manifest
+	accept := strings.Join([]string{mediaTypeDockerManifestList, mediaTypeOCIIndex, mediaTypeDockerManifest, mediaTypeOCIManifest}, ", ")
+	err := c.get(registry, "/v2/"+repository+"/manifests/"+reference, accept, token, &m)
+	return m, err
+}
+
+// Digest returns the digest of the manifest (or image index for multi-arch images) the reference points to, ex. sha256:...
+func (c *Client) Digest(registry string, repository string, reference string) (string, error) {
+	repository = NormalizeRepository(registry, repository)
+	token := ""
+	accept := strings.Join([]string{mediaTypeDockerManifestList, mediaTypeOCIIndex, mediaTypeDockerManifest, mediaTypeOCIManifest}, ", ")
+
+	resp, err := c.do(registry, "/v2/"+repository+"/manifests/"+reference, accept, &token)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// the digest header is optional, the digest of the manifest content is used otherwise
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+	hash := sha256.New()
+	if _, err = io.Copy(hash, resp.Body); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// get requests the path and decodes the json response
+func (c *Client) get(registry string, path string, accept string, token *string, target interface{}) error {
+	resp, err := c.do(registry, path, accept, token)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+// do requests the path, a anonymous bearer token is requested if the registry requires it. Responses without status 200 are returned as error.
+func (c *Client) do(registry string, path string, accept string, token *string) (*http.Response, error) {
+	url := c.Endpoint(registry) + path
+	resp, err := c.request(url, accept, *token)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && *token == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		*token, err = c.requestToken(challenge)
+		if err != nil {
+			return nil, err
+		}
+		resp, err = c.request(url, accept, *token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("request to %s failed with status %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+func (c *Client) request(url string, accept string, token string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return c.HTTP.Do(req)
+}
+
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// requestToken requests a anonymous token based on the bearer challenge of the registry
+func (c *Client) requestToken(challenge string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", errors.New("registry requires unsupported authentication: " + challenge)
+	}
+
+	params := make(map[string]string)
+	for _, match := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	if params["realm"] == "" {
+		return "", errors.New("registry authentication challenge doesn't contain a realm")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, params["realm"], nil)
+	if err != nil {
+		return "", err
+	}
+	query := req.URL.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	if params["scope"] != "" {
+		query.Set("scope", params["scope"])
+	}
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request failed with status %s", resp.Status)
+	}
+
+	var tokenResponse struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return "", err
+	}
+	if tokenResponse.Token != "" {
+		return tokenResponse.Token, nil
+	}
+	return tokenResponse.AccessToken, nil
+}
+
+func formatPlatform(os string, arch string, variant string) string {
+	if os == "" {
+		os = "unknown"
+	}
+	if arch == "" {
+		arch = "unknown"
+	}
+
+	platform := os + "/" + arch
+	if variant != "" {
+		platform += "/" + variant
+	}
+	return platform
+}
@@ -0,0 +1,123 @@
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newRegistry(t *testing.T, requests *int) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("scope") != "repository:library/node:pull" {
+				t.Errorf("unexpected scope %s", r.URL.Query().Get("scope"))
+			}
+			_, _ = w.Write([]byte(`{"token":"secret"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry",scope="repository:library/node:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v2/library/node/manifests/18":
+			_, _ = w.Write([]byte(`{"mediaType":"` + mediaTypeOCIIndex + `","manifests":[
+				{"digest":"sha256:arm","platform":{"os":"linux","architecture":"arm64","variant":"v8"}},
+				{"digest":"sha256:amd","platform":{"os":"linux","architecture":"amd64"}},
+				{"digest":"sha256:att","platform":{"os":"unknown","architecture":"unknown"}}]}`))
+		case "/v2/library/node/manifests/20":
+			w.Header().Set("Docker-Content-Digest", "sha256:index20")
+			_, _ = w.Write([]byte(`{"mediaType":"` + mediaTypeOCIIndex + `","manifests":[]}`))
+		case "/v2/library/node/manifests/sha256:amd":
+			_, _ = w.Write([]byte(`{"mediaType":"` + mediaTypeOCIManifest + `","config":{"digest":"sha256:cfg","size":100},"layers":[{"size":1000},{"size":24}]}`))
+		case "/v2/library/node/blobs/sha256:cfg":
+			_, _ = w.Write([]byte(`{"created":"2023-02-01T10:00:00Z","os":"linux","architecture":"amd64"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestInspect(t *testing.T) {
+	requests := 0
+	server := newRegistry(t, &requests)
+	defer server.Close()
+
+	client := New()
+	client.Endpoint = func(string) string { return server.URL }
+
+	info, err := client.Inspect("docker.io", "node", "18")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(info.Platforms, ",") != "linux/arm64/v8,linux/amd64" {
+		t.Errorf("unexpected platforms %v", info.Platforms)
+	}
+	if info.Size != 1124 {
+		t.Errorf("unexpected size %d", info.Size)
+	}
+	if !info.Created.Equal(time.Date(2023, 2, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected creation date %s", info.Created)
+	}
+
+	if _, err = client.Inspect("docker.io", "node", "missing"); err == nil {
+		t.Errorf("expected an error for a missing tag")
+	}
+}
+
+func TestDigest(t *testing.T) {
+	requests := 0
+	server := newRegistry(t, &requests)
+	defer server.Close()
+
+	client := New()
+	client.Endpoint = func(string) string { return server.URL }
+
+	if digest, err := client.Digest("docker.io", "node", "20"); err != nil || digest != "sha256:index20" {
+		t.Errorf("expected the digest header, got %s (%v)", digest, err)
+	}
+
+	// without header, the digest of the content is used
+	digest, err := client.Digest("docker.io", "node", "sha256:amd")
+	if err != nil || !strings.HasPrefix(digest, "sha256:") || len(digest) != 71 {
+		t.Errorf("expected the digest of the manifest, got %s (%v)", digest, err)
+	}
+
+	if _, err = client.Digest("docker.io", "node", "missing"); err == nil {
+		t.Errorf("expected an error for a missing tag")
+	}
+}
+
+func TestInspectCached(t *testing.T) {
+	requests := 0
+	server := newRegistry(t, &requests)
+	client := New()
+	client.Endpoint = func(string) string { return server.URL }
+	cacheDir := t.TempDir()
+
+	if _, err := client.InspectCached(cacheDir, "node:18", "docker.io", "node", "18", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server.Close()
+
+	info, err := client.InspectCached(cacheDir, "node:18", "docker.io", "node", "18", false)
+	if err != nil || info.Size != 1124 {
+		t.Errorf("expected the cached result, got %+v %v", info, err)
+	}
+	if _, err = client.InspectCached(cacheDir, "node:18", "docker.io", "node", "18", true); err == nil {
+		t.Errorf("expected refresh to query the registry")
+	}
+}
+
+func TestNormalizeRepository(t *testing.T) {
+	if NormalizeRepository("docker.io", "alpine") != "library/alpine" || NormalizeRepository("docker.io", "cidverse/envcli") != "cidverse/envcli" || NormalizeRepository("quay.io", "alpine") != "alpine" {
+		t.Errorf("unexpected repository normalization")
+	}
+}
@@ -0,0 +1,130 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// StepResult holds the outcome of a single task step
+type StepResult struct {
+	Name     string        `json:"name"`
+	Command  string        `json:"command"`
+	Duration time.Duration `json:"duration"`
+	ExitCode int           `json:"exitCode"`
+	Output   string        `json:"output,omitempty"`
+}
+
+// Failed returns true if the step did not succeed
+func (r StepResult) Failed() bool {
+	return r.ExitCode != 0
+}
+
+// TaskResult holds the outcome of a task run
+type TaskResult struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+	Steps    []StepResult  `json:"steps"`
+}
+
+// Failures returns the number of failed steps
+func (r TaskResult) Failures() int {
+	count := 0
+	for _, step := range r.Steps {
+		if step.Failed() {
+			count++
+		}
+	}
+	return count
+}
+
+// ParseTarget parses a report flag in the format format=file
+func ParseTarget(value string) (string, string, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", errors.New("report must be specified as format=file, for example junit=report.xml")
+	}
+	if parts[0] != "junit" && parts[0] != "json" {
+		return "", "", fmt.Errorf("unsupported report format %s, allowed: junit, json", parts[0])
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// Write renders the task result in the specified format into the file
+func Write(format string, file string, result TaskResult) error {
+	var content []byte
+	var err error
+	if format == "junit" {
+		content, err = RenderJUnit(result)
+	} else if format == "json" {
+		content, err = json.MarshalIndent(result, "", "  ")
+	} else {
+		err = fmt.Errorf("unsupported report format %s", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, content, 0644)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+// RenderJUnit renders the task result as JUnit XML, with one testcase per step
+func RenderJUnit(result TaskResult) ([]byte, error) {
+	suite := junitTestSuite{
+		Name:     result.Name,
+		Tests:    len(result.Steps),
+		Failures: result.Failures(),
+		Time:     formatSeconds(result.Duration),
+	}
+	for _, step := range result.Steps {
+		testCase := junitTestCase{Name: step.Name, ClassName: result.Name, Time: formatSeconds(step.Duration)}
+		if step.Failed() {
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%s failed with exit code %d", step.Command, step.ExitCode),
+				Content: step.Output,
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	content, err := xml.MarshalIndent(junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), content...), nil
+}
+
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
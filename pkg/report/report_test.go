@@ -0,0 +1,55 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRingBufferKeepsTail(t *testing.T) {
+	buffer := NewRingBuffer(5)
+	_, _ = buffer.Write([]byte("hello "))
+	_, _ = buffer.Write([]byte("world"))
+
+	if buffer.String() != "world" {
+		t.Errorf("expected [world], got [%s]", buffer.String())
+	}
+
+	_, _ = buffer.Write([]byte("!"))
+	if buffer.String() != "orld!" {
+		t.Errorf("expected [orld!], got [%s]", buffer.String())
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	format, file, err := ParseTarget("junit=report.xml")
+	if err != nil || format != "junit" || file != "report.xml" {
+		t.Errorf("unexpected result %s %s %v", format, file, err)
+	}
+
+	if _, _, err := ParseTarget("xml=report.xml"); err == nil {
+		t.Errorf("expected error for unsupported format")
+	}
+	if _, _, err := ParseTarget("junit"); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
+
+func TestRenderJUnit(t *testing.T) {
+	result := TaskResult{Name: "ci", Duration: 3 * time.Second, Steps: []StepResult{
+		{Name: "build", Command: "go build", Duration: time.Second},
+		{Name: "test", Command: "go test", Duration: 2 * time.Second, ExitCode: 1, Output: "FAIL pkg/config"},
+	}}
+
+	content, err := RenderJUnit(result)
+	if err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+
+	xml := string(content)
+	for _, expected := range []string{`tests="2"`, `failures="1"`, `<testcase name="build" classname="ci" time="1.000">`, `message="go test failed with exit code 1"`, "FAIL pkg/config"} {
+		if !strings.Contains(xml, expected) {
+			t.Errorf("expected report to contain %s, got %s", expected, xml)
+		}
+	}
+}
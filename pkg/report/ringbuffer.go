@@ -0,0 +1,49 @@
+package report
+
+// RingBuffer is a io.Writer that only retains the last written bytes, up to its capacity
+type RingBuffer struct {
+	data  []byte
+	start int
+	size  int
+}
+
+// NewRingBuffer creates a ring buffer retaining at most capacity bytes
+func NewRingBuffer(capacity int) *RingBuffer {
+	return &RingBuffer{data: make([]byte, capacity)}
+}
+
+// Write appends p to the buffer, overwriting the oldest bytes once the capacity is reached
+func (b *RingBuffer) Write(p []byte) (int, error) {
+	written := len(p)
+	capacity := len(b.data)
+	if capacity == 0 {
+		return written, nil
+	}
+
+	// only the tail of p can be retained
+	if len(p) > capacity {
+		p = p[len(p)-capacity:]
+	}
+
+	for _, c := range p {
+		end := (b.start + b.size) % capacity
+		b.data[end] = c
+		if b.size < capacity {
+			b.size++
+		} else {
+			b.start = (b.start + 1) % capacity
+		}
+	}
+
+	return written, nil
+}
+
+// String returns the retained bytes in write order
+func (b *RingBuffer) String() string {
+	out := make([]byte, 0, b.size)
+	for i := 0; i < b.size; i++ {
+		out = append(out, b.data[(b.start+i)%len(b.data)])
+	}
+
+	return string(out)
+}
@@ -0,0 +1,96 @@
+package runlog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// filePrefix is used for all log files created within the log directory
+const filePrefix = "envcli-"
+
+// fileSuffix is used for all log files created within the log directory
+const fileSuffix = ".log"
+
+var invalidFileNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Header holds the information written at the start of each log file
+type Header struct {
+	Command string
+	Image   string
+	Started time.Time
+}
+
+// FileName returns the timestamped file name for a run of the given command
+func FileName(command string, started time.Time) string {
+	return filePrefix + started.Format("20060102-150405.000") + "-" + invalidFileNameChars.ReplaceAllString(command, "_") + fileSuffix
+}
+
+// Open creates the log file for a run, either the explicitly provided file or a timestamped file within the directory
+func Open(file string, directory string, header Header) (*os.File, error) {
+	if file == "" {
+		file = filepath.Join(directory, FileName(header.Command, header.Started))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	logFile, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = fmt.Fprintf(logFile, "# command: %s\n# image: %s\n# started: %s\n", header.Command, header.Image, header.Started.Format(time.RFC3339))
+	if err != nil {
+		logFile.Close()
+		return nil, err
+	}
+
+	log.Debug().Str("file", file).Msg("capturing command output into log file")
+	return logFile, nil
+}
+
+// Prune removes old log files from the directory, keeping at most maxCount files (0 = unlimited) that are not older than maxAge (0 = unlimited)
+func Prune(directory string, maxCount int, maxAge time.Duration) error {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return err
+	}
+
+	var files []os.FileInfo
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), filePrefix) || !strings.HasSuffix(entry.Name(), fileSuffix) {
+			continue
+		}
+		info, infoErr := entry.Info()
+		if infoErr != nil {
+			continue
+		}
+		files = append(files, info)
+	}
+
+	// newest first
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().After(files[j].ModTime())
+	})
+
+	for i, file := range files {
+		expired := maxAge > 0 && time.Since(file.ModTime()) > maxAge
+		exceeded := maxCount > 0 && i >= maxCount
+		if expired || exceeded {
+			log.Debug().Str("file", file.Name()).Msg("removing old log file")
+			if removeErr := os.Remove(filepath.Join(directory, file.Name())); removeErr != nil {
+				return removeErr
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package runlog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenCreatesMissingDirectory(t *testing.T) {
+	directory := filepath.Join(t.TempDir(), "nested", "logs")
+
+	file, err := Open("", directory, Header{Command: "go test", Image: "golang:1.20", Started: time.Now()})
+	if err != nil {
+		t.Fatalf("failed to open log file: %v", err)
+	}
+	file.Close()
+
+	content, _ := os.ReadFile(file.Name())
+	if len(content) == 0 {
+		t.Errorf("expected the log file to contain a header")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	directory := t.TempDir()
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		name := filepath.Join(directory, FileName("npm", now.Add(time.Duration(-i)*time.Hour)))
+		_ = os.WriteFile(name, []byte("log"), 0600)
+		_ = os.Chtimes(name, now.Add(time.Duration(-i)*time.Hour), now.Add(time.Duration(-i)*time.Hour))
+	}
+	_ = os.WriteFile(filepath.Join(directory, "unrelated.txt"), []byte("keep"), 0600)
+
+	if err := Prune(directory, 3, 0); err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	assertFileCount(t, directory, 4)
+
+	if err := Prune(directory, 0, 90*time.Minute); err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+	assertFileCount(t, directory, 3)
+}
+
+func assertFileCount(t *testing.T, directory string, expected int) {
+	entries, _ := os.ReadDir(directory)
+	if len(entries) != expected {
+		t.Errorf("expected %d files, got %d", expected, len(entries))
+	}
+}
@@ -0,0 +1,224 @@
+package schema
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/common"
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+)
+
+// maxExplainSuggestionDistance is the maximum edit distance of a suggested field path
+const maxExplainSuggestionDistance = 4
+
+// Field is a documented field of the configuration file
+type Field struct {
+	// Path is the yaml path of the field, lists are marked by [] (ex. images[].cache[].name)
+	Path string
+
+	// goPath is the path using the lowercase names of the struct fields (ex. images.caching.name)
+	goPath string
+
+	// unset is true for optional fields whose zero value differs from not setting them (pointers)
+	unset bool
+
+	Type     string
+	Default  string
+	Required bool
+	Enum     []string
+	Doc      string
+
+	// Fields are the nested fields of objects and lists of objects
+	Fields []Field
+}
+
+// Reference returns the documented fields of the .envcli.yml, generated from the config structs
+func Reference() []Field {
+	return referenceFields(reflect.TypeOf(config.ConfigurationFile{}), "", "")
+}
+
+func referenceFields(t reflect.Type, path string, goPath string) []Field {
+	docs := config.FieldDocs()
+
+	var fields []Field
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		name, ok := yamlName(structField)
+		doc := docs[t.Name()+"."+structField.Name]
+		if !ok || strings.Contains(doc, "internal use only") {
+			continue
+		}
+
+		field := Field{
+			Path:     path + name,
+			goPath:   goPath + strings.ToLower(structField.Name),
+			Type:     typeName(structField.Type),
+			Default:  structField.Tag.Get("default"),
+			Required: contains(required[t.Name()], name),
+			Enum:     enums[t.Name()+"."+structField.Name],
+			Doc:      doc,
+			unset:    structField.Type.Kind() == reflect.Ptr,
+		}
+		if nested, list := structType(structField.Type); nested != nil {
+			prefix := field.Path
+			if list {
+				prefix += "[]"
+			}
+			field.Fields = referenceFields(nested, prefix+".", field.goPath+".")
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// structType returns the struct of objects and lists of objects, maps are free-form and not documented further
+func structType(t reflect.Type) (reflect.Type, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		return t, false
+	}
+	if t.Kind() == reflect.Slice {
+		if elem, _ := structType(t.Elem()); elem != nil {
+			return elem, true
+		}
+	}
+	return nil, false
+}
+
+// typeName returns the readable name of the type (ex. list of string)
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeName(t.Elem())
+	case reflect.Slice, reflect.Array:
+		return "list of " + typeName(t.Elem())
+	case reflect.Map:
+		return "map of " + typeName(t.Elem())
+	case reflect.Struct:
+		return "object"
+	}
+	return fromType(t).Type
+}
+
+// Explain returns the field of the path, the path may omit the list markers and use the struct field names (ex. images.caching)
+func Explain(path string) (Field, error) {
+	query := normalizePath(path)
+
+	var candidates []string
+	var found *Field
+	walkFields(Reference(), func(field Field) {
+		if found == nil && (normalizePath(field.Path) == query || field.goPath == query) {
+			found = &field
+		}
+		candidates = append(candidates, field.Path)
+	})
+	if found != nil {
+		return *found, nil
+	}
+
+	bestDistance := maxExplainSuggestionDistance + 1
+	suggestion := ""
+	for _, candidate := range candidates {
+		if distance := common.LevenshteinDistance(query, normalizePath(candidate)); distance < bestDistance {
+			bestDistance = distance
+			suggestion = candidate
+		}
+	}
+	if suggestion != "" {
+		return Field{}, fmt.Errorf("unknown field %s, did you mean %s? See envcli validate --print-reference for all fields", path, suggestion)
+	}
+	return Field{}, fmt.Errorf("unknown field %s, see envcli validate --print-reference for all fields", path)
+}
+
+func normalizePath(path string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(path, "."), "[]", ""))
+}
+
+func walkFields(fields []Field, fn func(Field)) {
+	for _, field := range fields {
+		fn(field)
+		walkFields(field.Fields, fn)
+	}
+}
+
+// Summary returns the type, the default and the allowed values of the field (ex. string, required, default: v1)
+func (f Field) Summary() string {
+	parts := []string{f.Type}
+	if f.Required {
+		parts = append(parts, "required")
+	}
+	if f.Default != "" {
+		parts = append(parts, "default: "+f.Default)
+	}
+	if len(f.Enum) > 0 {
+		parts = append(parts, "one of: "+strings.Join(f.Enum, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// WriteExplanation writes the documentation of a single field
+func WriteExplanation(w io.Writer, field Field) {
+	fmt.Fprintf(w, "%s (%s)\n", field.Path, field.Summary())
+	if field.Doc != "" {
+		fmt.Fprintf(w, "\n  %s\n", field.Doc)
+	}
+	if len(field.Fields) > 0 {
+		fmt.Fprintln(w, "\nFields:")
+		for _, nested := range field.Fields {
+			fmt.Fprintf(w, "  %s (%s)\n", nested.Path[len(field.Path)+1:], nested.Summary())
+		}
+	}
+}
+
+// WriteReference writes a commented yaml document with all fields of the .envcli.yml, the values are the defaults or empty values
+func WriteReference(w io.Writer) {
+	fmt.Fprintln(w, "# EnvCLI configuration reference, generated from the configuration structs")
+	fmt.Fprintln(w, "# the values are the defaults, or empty values for fields without a default")
+	writeReferenceFields(w, Reference(), "")
+}
+
+func writeReferenceFields(w io.Writer, fields []Field, indent string) {
+	for _, field := range fields {
+		name := field.Path[strings.LastIndex(field.Path, ".")+1:]
+
+		fmt.Fprintln(w)
+		if field.Doc != "" {
+			fmt.Fprintf(w, "%s# %s\n", indent, field.Doc)
+		}
+		fmt.Fprintf(w, "%s# %s\n", indent, field.Summary())
+
+		switch {
+		case len(field.Fields) > 0 && strings.HasPrefix(field.Type, "list of "):
+			fmt.Fprintf(w, "%s%s:\n%s  -\n", indent, name, indent)
+			writeReferenceFields(w, field.Fields, indent+"    ")
+		case len(field.Fields) > 0:
+			fmt.Fprintf(w, "%s%s:\n", indent, name)
+			writeReferenceFields(w, field.Fields, indent+"  ")
+		default:
+			fmt.Fprintf(w, "%s%s: %s\n", indent, name, referenceValue(field))
+		}
+	}
+}
+
+// referenceValue returns the yaml value of the field, its default or a empty value - null if the empty value isn't valid or differs from not setting the field
+func referenceValue(field Field) string {
+	switch {
+	case (field.unset || len(field.Enum) > 0) && field.Default == "":
+		return "null"
+	case strings.HasPrefix(field.Type, "list of "):
+		return "[]"
+	case strings.HasPrefix(field.Type, "map of "), field.Type == "object":
+		return "{}"
+	case field.Type == "boolean":
+		return "false"
+	case field.Type == "integer":
+		return "0"
+	case field.Default != "":
+		return fmt.Sprintf("%q", field.Default)
+	}
+	return `""`
+}
@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+// TestReferenceInSync ensures that the reference is a valid configuration file containing every field of the config structs
+func TestReferenceInSync(t *testing.T) {
+	var buffer bytes.Buffer
+	WriteReference(&buffer)
+
+	var cfg config.ConfigurationFile
+	if err := yaml.UnmarshalStrict(buffer.Bytes(), &cfg); err != nil {
+		t.Fatalf("expected the reference to be a valid configuration file: %v\n%s", err, buffer.String())
+	}
+	if len(cfg.Images) != 1 || cfg.Images[0].Directory != "/project" || cfg.Images[0].Init != nil {
+		t.Errorf("expected the defaults to be used as values, got %+v", cfg.Images)
+	}
+
+	var content interface{}
+	_ = yaml.Unmarshal(buffer.Bytes(), &content)
+	if violations := Generate().Validate(content); len(violations) != 0 {
+		t.Errorf("expected the reference to match the schema, got %v", violations)
+	}
+
+	var missing []string
+	walkFields(Reference(), func(field Field) {
+		if field.Doc == "" {
+			missing = append(missing, field.Path)
+		}
+	})
+	if len(missing) != 0 {
+		t.Errorf("expected all fields to be documented, missing: %v", missing)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	for _, path := range []string{"images[].cache", "images.cache", "images[].caching", "IMAGES[].CACHE"} {
+		field, err := Explain(path)
+		if err != nil || field.Path != "images[].cache" || field.Type != "list of object" || len(field.Fields) != 2 {
+			t.Errorf("%s: unexpected field %+v (%v)", path, field, err)
+		}
+	}
+
+	field, err := Explain("policy.lint[].severity")
+	if err != nil || field.Default != "warning" || len(field.Enum) != 3 || !strings.Contains(field.Summary(), "default: warning") {
+		t.Errorf("unexpected field %+v (%v)", field, err)
+	}
+	if field, err = Explain("tasks[].name"); err != nil || !field.Required {
+		t.Errorf("expected the task name to be required, got %+v (%v)", field, err)
+	}
+
+	if _, err = Explain("images[].source"); err == nil {
+		t.Errorf("expected internal fields to be omitted")
+	}
+	if _, err = Explain("images[].imgae"); err == nil || !strings.Contains(err.Error(), "did you mean images[].image?") {
+		t.Errorf("expected a suggestion, got %v", err)
+	}
+}
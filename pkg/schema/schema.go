@@ -0,0 +1,227 @@
+// Package schema generates the JSON schema of the envcli configuration files from the config structs and validates files against it.
+package schema
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/containerutil"
+	"gopkg.in/yaml.v2"
+)
+
+// URL is the location of the published schema, referenced using $schema
+const URL = "https://raw.githubusercontent.com/EnvCLI/EnvCLI/master/docs/schema/envcli.schema.json"
+
+// Schema is the subset of a JSON schema (draft-07) used for the configuration files
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// enums are the allowed values of string fields, by struct and field name
+var enums = map[string][]string{
+	"RunConfigurationEntry.Shell":       containerutil.SupportedShells,
+	"RunConfigurationEntry.Passthrough": {config.PassthroughPreferLocal, config.PassthroughPreferContainer, config.PassthroughLocalOnly},
+	"RunConfigurationEntry.ArgPosition": {config.ArgPositionAppend, config.ArgPositionPrepend},
+	"PolicyConfiguration.Mode":          {"warn", "enforce"},
+	"LintRule.Rule":                     {config.RuleNoLatestTag, config.RuleTagPattern, config.RuleAllowedRegistries},
+	"LintRule.Severity":                 {config.SeverityInfo, config.SeverityWarning, config.SeverityError},
+}
+
+// required are the fields that must be set, by struct name
+var required = map[string][]string{
+	"RunConfigurationEntry": {"name"},
+	"TaskEntry":             {"name", "steps"},
+	"TaskStep":              {"run"},
+}
+
+// Generate returns the schema of the .envcli.yml
+func Generate() *Schema {
+	s := fromType(reflect.TypeOf(config.ConfigurationFile{}))
+	s.Schema = "http://json-schema.org/draft-07/schema#"
+	s.ID = URL
+	s.Title = "EnvCLI configuration"
+
+	// editors reference the schema using the $schema key
+	s.Properties["$schema"] = &Schema{Type: "string"}
+	return s
+}
+
+// fromType returns the schema of the type, struct fields use the name of their yaml tag
+func fromType(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return fromType(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: fromType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		closed := false
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: &closed, Required: required[t.Name()]}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, ok := yamlName(field)
+			if !ok {
+				continue
+			}
+
+			property := fromType(field.Type)
+			property.Enum = enums[t.Name()+"."+field.Name]
+			s.Properties[name] = property
+		}
+		return s
+	}
+
+	return &Schema{}
+}
+
+// yamlName returns the yaml key of the struct field, which defaults to the lowercase field name
+func yamlName(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		return "", false
+	}
+
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "-" {
+		return "", false
+	} else if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, true
+}
+
+// Validate returns the violations of the value (as decoded from yaml) against the schema, prefixed by their path
+func (s *Schema) Validate(value interface{}) []string {
+	return s.validate("$", value)
+}
+
+func (s *Schema) validate(path string, value interface{}) []string {
+	if value == nil {
+		return nil
+	}
+
+	var violations []string
+	switch s.Type {
+	case "object":
+		object, ok := toObject(value)
+		if !ok {
+			return []string{path + ": expected an object"}
+		}
+		for _, name := range s.Required {
+			if _, found := object[name]; !found {
+				violations = append(violations, path+": missing required property "+name)
+			}
+		}
+		for _, name := range sortedKeys(object) {
+			property, found := s.Properties[name]
+			if !found {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					violations = append(violations, path+": unknown property "+name)
+				}
+				continue
+			}
+			violations = append(violations, property.validate(path+"."+name, object[name])...)
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return []string{path + ": expected an array"}
+		}
+		for i, item := range items {
+			violations = append(violations, s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+		}
+	case "string":
+		// scalars are strings in yaml, only collections are rejected
+		if isCollection(value) {
+			return []string{path + ": expected a string"}
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, fmt.Sprint(value)) {
+			violations = append(violations, fmt.Sprintf("%s: invalid value %v, allowed: %s", path, value, strings.Join(s.Enum, ", ")))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []string{path + ": expected a boolean"}
+		}
+	case "integer":
+		if _, ok := value.(int); !ok {
+			return []string{path + ": expected an integer"}
+		}
+	}
+
+	return violations
+}
+
+// toObject converts the yaml mappings (map[interface{}]interface{}) into a map with string keys
+func toObject(value interface{}) (map[string]interface{}, bool) {
+	switch object := value.(type) {
+	case map[string]interface{}:
+		return object, true
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(object))
+		for key, item := range object {
+			result[fmt.Sprint(key)] = item
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+func isCollection(value interface{}) bool {
+	switch value.(type) {
+	case []interface{}, map[interface{}]interface{}, map[string]interface{}:
+		return true
+	}
+	return false
+}
+
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateFile returns the violations of the yaml file against the schema
+func (s *Schema) ValidateFile(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var content interface{}
+	if err = yaml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return s.Validate(content), nil
+}
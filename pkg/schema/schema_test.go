@@ -0,0 +1,62 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestGenerate(t *testing.T) {
+	s := Generate()
+	entry := s.Properties["images"].Items
+
+	if entry.Properties["image"].Type != "string" || entry.Properties["retries"].Type != "integer" || entry.Properties["provides"].Items.Type != "string" {
+		t.Errorf("unexpected entry schema %+v", entry.Properties)
+	}
+	if _, found := entry.Properties["directory"]; !found {
+		t.Errorf("expected fields without yaml tag to use the lowercase field name")
+	}
+	if _, found := entry.Properties["source"]; found {
+		t.Errorf("expected internal fields to be omitted")
+	}
+	if strings.Join(entry.Properties["passthrough"].Enum, ",") != "prefer-local,prefer-container,local-only" {
+		t.Errorf("unexpected passthrough enum %v", entry.Properties["passthrough"].Enum)
+	}
+}
+
+// TestPublishedSchema ensures that the schema referenced by $schema is in sync with the config structs
+func TestPublishedSchema(t *testing.T) {
+	published, err := os.ReadFile("../../docs/schema/envcli.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, _ := json.MarshalIndent(Generate(), "", "  ")
+	if string(published) != string(generated)+"\n" {
+		t.Errorf("docs/schema/envcli.schema.json is outdated, update it using: go run . schema -f docs/schema/envcli.schema.json")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	var tests = []struct {
+		content    string
+		violations string
+	}{
+		{"$schema: " + URL + "\nimages:\n  - name: node\n    image: node:20\n    shell: bash\n    retries: 2\n    provides: [node]\n", ""},
+		{"images:\n  - name: node\n    imgae: node:20\n", "$.images[0]: unknown property imgae"},
+		{"images:\n  - image: node:20\n    shell: fish\n", "$.images[0]: missing required property name|$.images[0].shell: invalid value fish, allowed: sh, bash, ash, zsh, powershell, cmd, none"},
+		{"images:\n  - name: node\n    retries: often\n    provides: node\n", "$.images[0].provides: expected an array|$.images[0].retries: expected an integer"},
+	}
+
+	for _, test := range tests {
+		var content interface{}
+		if err := yaml.Unmarshal([]byte(test.content), &content); err != nil {
+			t.Fatal(err)
+		}
+		if violations := strings.Join(Generate().Validate(content), "|"); violations != test.violations {
+			t.Errorf("%q: expected %q, got %q", test.content, test.violations, violations)
+		}
+	}
+}
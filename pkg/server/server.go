@@ -0,0 +1,319 @@
+// Package server provides the local JSON API of envcli serve, used by editor integrations to resolve commands without spawning a process per request.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+	"github.com/EnvCLI/EnvCLI/pkg/envcli"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
+	"github.com/EnvCLI/EnvCLI/pkg/schema"
+	"github.com/cidverse/cidverseutils/pkg/filesystem"
+	"github.com/rs/zerolog/log"
+)
+
+// shutdownTimeout is the time the requests in progress get to finish once the server is stopped
+const shutdownTimeout = 5 * time.Second
+
+// Server answers the requests of the API, the configuration is read from the working directory of the process
+type Server struct {
+	// Version of envcli, part of every response
+	Version string
+
+	// Token must be sent as bearer token in the Authorization header of every request
+	Token string
+
+	// ConfigIncludes are additionally included configuration files
+	ConfigIncludes []string
+
+	// Properties are used to resolve the image mirrors
+	Properties map[string]string
+
+	// RuntimeAvailable reports if a container runtime is available, used to resolve the passthrough of commands
+	RuntimeAvailable func() bool
+
+	// mutex serializes the requests, the configuration is loaded from the process state (working directory, config directory)
+	mutex sync.Mutex
+}
+
+// Response is the body of every response, either Result or Error is set
+type Response struct {
+	Version string      `json:"version"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// Entry is a configured entry, as listed by /v1/commands
+type Entry struct {
+	Name        string   `json:"name"`
+	Scope       string   `json:"scope"`
+	Image       string   `json:"image"`
+	Provides    []string `json:"provides"`
+	Denied      []string `json:"denied,omitempty"`
+	Source      string   `json:"source"`
+	Description string   `json:"description,omitempty"`
+	DocsURL     string   `json:"docsUrl,omitempty"`
+}
+
+// Resolution describes how a command would be run, as returned by /v1/resolve
+type Resolution struct {
+	Command string   `json:"command"`
+	Entry   string   `json:"entry"`
+	Scope   string   `json:"scope"`
+	Image   string   `json:"image"`
+	Mirror  string   `json:"mirror,omitempty"`
+	Variant string   `json:"variant,omitempty"`
+	Match   string   `json:"match"`
+	Args    []string `json:"args,omitempty"`
+	Local   bool     `json:"local"`
+	Path    string   `json:"path,omitempty"`
+	Reason  string   `json:"reason"`
+}
+
+// Violation is a finding of /v1/validate
+type Violation struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Entry    string `json:"entry"`
+	Message  string `json:"message"`
+}
+
+// Validation is the result of /v1/validate
+type Validation struct {
+	Valid      bool        `json:"valid"`
+	Violations []Violation `json:"violations"`
+}
+
+// NewToken returns a random token for the Authorization header
+func NewToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// WriteToken writes the token into a file only readable by the current user.
+// The token is written to a new temporary file (0600) that replaces the file, the token is never readable through a existing file or a link placed at its path.
+func WriteToken(file string, token string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), "."+filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.WriteString(token + "\n"); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// Listen listens on the port of the loopback interface or on the unix socket, a stale unix socket of a previous server is replaced.
+// Existing files which aren't sockets are never removed.
+func Listen(address string) (net.Listener, error) {
+	if port, err := strconv.Atoi(address); err == nil {
+		if port < 0 || port > 65535 {
+			return nil, fmt.Errorf("invalid port %d", port)
+		}
+		return net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	}
+
+	if info, err := os.Lstat(address); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s already exists and is not a socket", address)
+		}
+		if conn, dialErr := net.Dial("unix", address); dialErr == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("the socket %s is already in use", address)
+		}
+		if err = os.Remove(address); err != nil {
+			return nil, fmt.Errorf("failed to remove the stale socket %s: %w", address, err)
+		}
+	}
+
+	// the api requires the token, the permissions only keep other users from connecting at all
+	listener, err := net.Listen("unix", address)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.Chmod(address, 0600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// Serve answers the requests until the context is cancelled, the requests in progress are finished before it returns
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	httpServer := &http.Server{Handler: s.Handler(), ReadHeaderTimeout: 10 * time.Second}
+
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		done <- httpServer.Shutdown(shutdownCtx)
+	}()
+
+	if err := httpServer.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-done
+}
+
+// Handler returns the handler of the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/commands", s.handle(s.commands))
+	mux.HandleFunc("/v1/resolve", s.handle(s.resolve))
+	mux.HandleFunc("/v1/validate", s.handle(s.validate))
+	mux.HandleFunc("/v1/schema", s.handle(func(r *http.Request) (interface{}, error) {
+		return schema.Generate(), nil
+	}))
+	mux.HandleFunc("/", s.handle(func(r *http.Request) (interface{}, error) {
+		return nil, &requestError{status: http.StatusNotFound, err: fmt.Errorf("unknown endpoint %s", r.URL.Path)}
+	}))
+	return mux
+}
+
+// requestError is a error with the http status of the response
+type requestError struct {
+	status int
+	err    error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+// handle authenticates the request and writes the result or the error of the endpoint
+func (s *Server) handle(endpoint func(r *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var result interface{}
+		var err error
+		if !s.authorized(r) {
+			err = &requestError{status: http.StatusUnauthorized, err: errors.New("missing or invalid token")}
+		} else if r.Method != http.MethodGet {
+			err = &requestError{status: http.StatusMethodNotAllowed, err: fmt.Errorf("method %s is not allowed", r.Method)}
+		} else {
+			s.mutex.Lock()
+			result, err = endpoint(r)
+			s.mutex.Unlock()
+		}
+
+		status := http.StatusOK
+		response := Response{Version: s.Version, Result: result}
+		if err != nil {
+			status = statusOf(err)
+			response = Response{Version: s.Version, Error: err.Error()}
+		}
+		log.Debug().Str("path", r.URL.Path).Int("status", status).Msg("answered api request")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(response)
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	expected := "Bearer " + s.Token
+	return s.Token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) == 1
+}
+
+// statusOf returns the http status of the error: 404 for unknown commands and 422 for invalid configurations
+func statusOf(err error) int {
+	var reqErr *requestError
+	var noMatch *config.NoMatchError
+	switch {
+	case errors.As(err, &reqErr):
+		return reqErr.status
+	case errors.As(err, &noMatch):
+		return http.StatusNotFound
+	case exitcode.Of(err) == exitcode.ConfigError:
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
+// commands lists the configured entries
+func (s *Server) commands(r *http.Request) (interface{}, error) {
+	cfg, err := config.LoadMergedConfiguration(r.Context(), s.ConfigIncludes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", exitcode.New(exitcode.ConfigError, err))
+	}
+
+	entries := []Entry{}
+	for _, image := range cfg.Images {
+		entry := Entry{Name: image.Name, Scope: image.Scope, Image: image.Image, Provides: image.Provides, Source: image.Source, Description: image.Description, DocsURL: image.DocsURL}
+		for _, command := range image.Provides {
+			if _, denied := cfg.GetDeniedCommand(command); denied {
+				entry.Denied = append(entry.Denied, command)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// resolve returns the entry and image of the command (?command=npm&variant=prod), like envcli which
+func (s *Server) resolve(r *http.Request) (interface{}, error) {
+	command := r.URL.Query().Get("command")
+	if command == "" {
+		return nil, &requestError{status: http.StatusBadRequest, err: errors.New("missing query parameter command")}
+	}
+	variant := r.URL.Query().Get("variant")
+
+	entry, matchType, err := config.GetCommandVariantMatch(r.Context(), command, variant, filesystem.GetWorkingDirectory(), s.ConfigIncludes)
+	if err != nil {
+		return nil, err
+	}
+	result := Resolution{Command: command, Entry: entry.Name, Scope: entry.Scope, Image: entry.Image, Variant: entry.Variant, Match: matchType, Args: config.GetDefaultArgs(entry, command)}
+
+	execution, err := envcli.ResolveExecution(entry, matchType, command, s.RuntimeAvailable)
+	if err != nil {
+		return nil, err
+	}
+	result.Local, result.Path, result.Reason = execution.Local, execution.Path, execution.Reason
+	if !execution.Local {
+		mirrored, mirrorErr := envcli.ResolveImageMirror(entry, s.Properties)
+		if mirrorErr != nil {
+			return nil, mirrorErr
+		}
+		if mirrored != entry.Image {
+			result.Mirror = mirrored
+		}
+	}
+	return result, nil
+}
+
+// validate checks the configuration and the lint rules, like envcli validate
+func (s *Server) validate(r *http.Request) (interface{}, error) {
+	cfg, err := config.LoadMergedConfiguration(r.Context(), s.ConfigIncludes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", exitcode.New(exitcode.ConfigError, err))
+	}
+
+	violations := config.ValidateConfiguration(cfg)
+	result := Validation{Valid: !config.HasErrors(violations), Violations: []Violation{}}
+	for _, violation := range violations {
+		result.Violations = append(result.Violations, Violation{Rule: violation.Rule, Severity: violation.Severity, Entry: violation.Entry, Message: violation.Message})
+	}
+	return result, nil
+}
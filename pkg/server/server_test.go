@@ -0,0 +1,227 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/EnvCLI/EnvCLI/pkg/config"
+)
+
+// newTestServer changes into a project with the config and returns a server with the token "secret"
+func newTestServer(t *testing.T, projectConfig string) *Server {
+	t.Helper()
+	t.Setenv("CI", "false")
+	config.SetConfigurationDirectory(t.TempDir())
+	t.Cleanup(func() { config.SetConfigurationDirectory(config.UserConfigurationDirectory()) })
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".envcli.yml"), []byte(projectConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	previous, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(previous) })
+
+	return &Server{Version: "1.2.3", Token: "secret", RuntimeAvailable: func() bool { return true }}
+}
+
+// get requests the path and decodes the response
+func get(t *testing.T, s *Server, path string, token string) (int, Response) {
+	t.Helper()
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	s.Handler().ServeHTTP(recorder, request)
+
+	var response Response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("%s: invalid response %q", path, recorder.Body.String())
+	}
+	if response.Version != "1.2.3" {
+		t.Errorf("%s: expected the version in the response, got %q", path, response.Version)
+	}
+	return recorder.Code, response
+}
+
+func TestServerEndpoints(t *testing.T) {
+	s := newTestServer(t, "images:\n  - name: node\n    image: node:20\n    provides:\n      - npm\n      - node\n")
+
+	status, response := get(t, s, "/v1/commands", "secret")
+	if status != http.StatusOK || !strings.Contains(toJSON(response.Result), `"image":"node:20","name":"node","provides":["npm","node"],"scope":"Project"`) {
+		t.Errorf("unexpected commands %d %s", status, toJSON(response))
+	}
+
+	status, response = get(t, s, "/v1/resolve?command=npm", "secret")
+	if status != http.StatusOK || !strings.Contains(toJSON(response.Result), `"entry":"node"`) || !strings.Contains(toJSON(response.Result), `"match":"provides"`) {
+		t.Errorf("unexpected resolution %d %s", status, toJSON(response))
+	}
+
+	status, response = get(t, s, "/v1/resolve?command=cargo", "secret")
+	if status != http.StatusNotFound || response.Error == "" {
+		t.Errorf("expected 404 for a unknown command, got %d %s", status, toJSON(response))
+	}
+
+	if status, response = get(t, s, "/v1/resolve", "secret"); status != http.StatusBadRequest {
+		t.Errorf("expected 400 without command, got %d %s", status, toJSON(response))
+	}
+
+	status, response = get(t, s, "/v1/validate", "secret")
+	if status != http.StatusOK || !strings.Contains(toJSON(response.Result), `"valid":true`) {
+		t.Errorf("unexpected validation %d %s", status, toJSON(response))
+	}
+
+	status, response = get(t, s, "/v1/schema", "secret")
+	if status != http.StatusOK || !strings.Contains(toJSON(response.Result), `"images"`) {
+		t.Errorf("unexpected schema %d", status)
+	}
+}
+
+func TestServerAuthentication(t *testing.T) {
+	s := newTestServer(t, "images: []\n")
+
+	for _, token := range []string{"", "wrong"} {
+		if status, response := get(t, s, "/v1/commands", token); status != http.StatusUnauthorized || response.Result != nil {
+			t.Errorf("token %q: expected 401, got %d %s", token, status, toJSON(response))
+		}
+	}
+	if status, _ := get(t, s, "/v2/unknown", "secret"); status != http.StatusNotFound {
+		t.Errorf("expected 404 for a unknown endpoint, got %d", status)
+	}
+}
+
+func TestListenUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("stale unix sockets aren't detected on windows")
+	}
+	// unix socket paths are limited to ~100 characters, the test temp directory may exceed it
+	dir, err := os.MkdirTemp("", "envcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "api.sock")
+
+	// other files are never replaced
+	if err = os.WriteFile(socket, []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Listen(socket); err == nil || !strings.Contains(err.Error(), "is not a socket") {
+		t.Errorf("expected the file to be rejected, got %v", err)
+	}
+	if content, _ := os.ReadFile(socket); string(content) != "notes" {
+		t.Errorf("expected the file to be kept, got %q", content)
+	}
+	if err = os.Remove(socket); err != nil {
+		t.Fatal(err)
+	}
+
+	// a stale socket of a previous server is replaced
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+	listener, err := Listen(socket)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if info, _ := os.Stat(socket); info.Mode().Perm() != 0600 {
+		t.Errorf("expected the socket to be only accessible by the user, got %v", info.Mode().Perm())
+	}
+	if _, err = Listen(socket); err == nil || !strings.Contains(err.Error(), "already in use") {
+		t.Errorf("expected the socket to be in use, got %v", err)
+	}
+
+	s := newTestServer(t, "images: []\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, listener) }()
+
+	client := &http.Client{Transport: &http.Transport{DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return net.Dial("unix", socket)
+	}}}
+	request, _ := http.NewRequest(http.MethodGet, "http://envcli/v1/commands", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+	response, err := client.Do(request)
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Errorf("unexpected response %v (%v)", response, err)
+	} else {
+		response.Body.Close()
+	}
+
+	cancel()
+	if err = <-done; err != nil {
+		t.Errorf("expected a graceful shutdown, got %v", err)
+	}
+}
+
+func TestListenPort(t *testing.T) {
+	listener, err := Listen("0")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer listener.Close()
+	if addr := listener.Addr().(*net.TCPAddr); !addr.IP.IsLoopback() || addr.Port == 0 {
+		t.Errorf("expected a port on the loopback interface, got %v", addr)
+	}
+
+	if _, err = Listen("70000"); err == nil {
+		t.Error("expected a error for a invalid port")
+	}
+}
+
+func TestWriteToken(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteToken(file, "secret"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	content, _ := os.ReadFile(file)
+	info, _ := os.Stat(file)
+	if string(content) != "secret\n" || (runtime.GOOS != "windows" && info.Mode().Perm() != 0600) {
+		t.Errorf("unexpected token file %q %v", content, info.Mode().Perm())
+	}
+
+	// a link placed at the path of the token file is replaced, the token isn't written to its target
+	if runtime.GOOS != "windows" {
+		target := filepath.Join(t.TempDir(), "target")
+		if err := os.WriteFile(target, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		link := filepath.Join(t.TempDir(), "token")
+		if err := os.Symlink(target, link); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteToken(link, "secret"); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if content, _ = os.ReadFile(target); len(content) != 0 {
+			t.Errorf("expected the token to be kept out of the link target, got %q", content)
+		}
+	}
+
+	token, err := NewToken()
+	if err != nil || len(token) != 64 {
+		t.Errorf("unexpected token %q (%v)", token, err)
+	}
+}
+
+func toJSON(value interface{}) string {
+	content, _ := json.Marshal(value)
+	return string(content)
+}
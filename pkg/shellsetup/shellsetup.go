@@ -0,0 +1,128 @@
+package shellsetup
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// markers guarding the block managed by envcli setup-shell
+const (
+	BeginMarker = "# >>> envcli setup-shell >>>"
+	EndMarker   = "# <<< envcli setup-shell <<<"
+)
+
+// SupportedShells contains all shells setup-shell can configure
+var SupportedShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// DetectShell detects the shell of the current user based on the SHELL environment variable, powershell is used on windows
+func DetectShell(goos string, getenv func(string) string) (string, error) {
+	if goos == "windows" {
+		return "powershell", nil
+	}
+
+	shell := filepath.Base(getenv("SHELL"))
+	switch shell {
+	case "bash", "zsh", "fish":
+		return shell, nil
+	case "pwsh":
+		return "powershell", nil
+	}
+
+	return "", errors.New("can't detect the shell from SHELL=" + getenv("SHELL") + ", use --shell " + strings.Join(SupportedShells, "|"))
+}
+
+// ProfileFile returns the rc or profile file of the shell
+func ProfileFile(shell string, goos string, home string, getenv func(string) string) (string, error) {
+	switch shell {
+	case "bash":
+		return filepath.Join(home, ".bashrc"), nil
+	case "zsh":
+		if zdotdir := getenv("ZDOTDIR"); zdotdir != "" {
+			return filepath.Join(zdotdir, ".zshrc"), nil
+		}
+		return filepath.Join(home, ".zshrc"), nil
+	case "fish":
+		return filepath.Join(home, ".config", "fish", "config.fish"), nil
+	case "powershell":
+		if goos == "windows" {
+			return filepath.Join(home, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1"), nil
+		}
+		return filepath.Join(home, ".config", "powershell", "Microsoft.PowerShell_profile.ps1"), nil
+	}
+
+	return "", errors.New("unsupported shell " + shell + ", allowed: " + strings.Join(SupportedShells, ", "))
+}
+
+// Snippet returns the lines that add the alias directory to the PATH and load the envcli completions, including the markers
+func Snippet(shell string, binDirectory string) (string, error) {
+	var lines []string
+	switch shell {
+	case "bash":
+		lines = []string{
+			`export PATH="$PATH:` + binDirectory + `"`,
+			`if command -v envcli >/dev/null 2>&1; then source <(envcli completion bash); fi`,
+		}
+	case "zsh":
+		lines = []string{
+			`export PATH="$PATH:` + binDirectory + `"`,
+			`if command -v envcli >/dev/null 2>&1; then source <(envcli completion zsh); compdef _envcli envcli; fi`,
+		}
+	case "fish":
+		lines = []string{
+			`set -gx PATH $PATH "` + binDirectory + `"`,
+			`if command -q envcli; envcli completion fish | source; end`,
+		}
+	case "powershell":
+		lines = []string{
+			`$env:PATH += [IO.Path]::PathSeparator + "` + binDirectory + `"`,
+			`if (Get-Command envcli -ErrorAction SilentlyContinue) { envcli completion powershell | Out-String | Invoke-Expression }`,
+		}
+	default:
+		return "", errors.New("unsupported shell " + shell + ", allowed: " + strings.Join(SupportedShells, ", "))
+	}
+
+	return BeginMarker + "\n" + strings.Join(lines, "\n") + "\n" + EndMarker + "\n", nil
+}
+
+// ApplyBlock adds the block to the content or replaces a previously added block, returns false if the content already contains the block
+func ApplyBlock(content string, block string) (string, bool) {
+	return ApplyMarkedBlock(content, block, BeginMarker, EndMarker)
+}
+
+// RemoveBlock removes the marked block from the content, returns false if the content doesn't contain the block
+func RemoveBlock(content string) (string, bool) {
+	return RemoveMarkedBlock(content, BeginMarker, EndMarker)
+}
+
+// ApplyMarkedBlock adds the block guarded by the markers to the content or replaces a previously added block, returns false if the content already contains the block
+func ApplyMarkedBlock(content string, block string, beginMarker string, endMarker string) (string, bool) {
+	if strings.Contains(content, block) {
+		return content, false
+	}
+
+	stripped, _ := RemoveMarkedBlock(content, beginMarker, endMarker)
+	if stripped != "" && !strings.HasSuffix(stripped, "\n") {
+		stripped += "\n"
+	}
+	return stripped + block, true
+}
+
+// RemoveMarkedBlock removes the block guarded by the markers from the content, returns false if the content doesn't contain the block
+func RemoveMarkedBlock(content string, beginMarker string, endMarker string) (string, bool) {
+	begin := strings.Index(content, beginMarker)
+	if begin < 0 {
+		return content, false
+	}
+
+	end := strings.Index(content[begin:], endMarker)
+	if end < 0 {
+		return content, false
+	}
+	end += begin + len(endMarker)
+	if end < len(content) && content[end] == '\n' {
+		end++
+	}
+
+	return content[:begin] + content[end:], true
+}
@@ -0,0 +1,79 @@
+package shellsetup
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectShell(t *testing.T) {
+	env := func(value string) func(string) string {
+		return func(string) string { return value }
+	}
+
+	var tests = []struct {
+		goos     string
+		shell    string
+		expected string
+	}{
+		{"linux", "/bin/bash", "bash"},
+		{"darwin", "/bin/zsh", "zsh"},
+		{"linux", "/usr/bin/fish", "fish"},
+		{"linux", "/usr/bin/pwsh", "powershell"},
+		{"windows", "", "powershell"},
+		{"linux", "/bin/tcsh", ""},
+	}
+
+	for _, test := range tests {
+		shell, err := DetectShell(test.goos, env(test.shell))
+		if shell != test.expected || (err != nil) != (test.expected == "") {
+			t.Errorf("%s: expected %s, got %s %v", test.shell, test.expected, shell, err)
+		}
+	}
+}
+
+func TestProfileFile(t *testing.T) {
+	noEnv := func(string) string { return "" }
+
+	file, _ := ProfileFile("fish", "linux", "/home/user", noEnv)
+	if file != filepath.Join("/home/user", ".config", "fish", "config.fish") {
+		t.Errorf("unexpected fish profile %s", file)
+	}
+	file, _ = ProfileFile("zsh", "linux", "/home/user", func(string) string { return "/home/user/.zsh" })
+	if file != filepath.Join("/home/user/.zsh", ".zshrc") {
+		t.Errorf("unexpected zsh profile %s", file)
+	}
+	if _, err := ProfileFile("tcsh", "linux", "/home/user", noEnv); err == nil {
+		t.Errorf("expected an error for an unsupported shell")
+	}
+}
+
+func TestApplyAndRemoveBlock(t *testing.T) {
+	block, _ := Snippet("bash", "/opt/envcli")
+	original := "alias ll='ls -la'"
+
+	content, changed := ApplyBlock(original, block)
+	if !changed || content != original+"\n"+block {
+		t.Errorf("unexpected content after apply:\n%s", content)
+	}
+
+	// idempotent
+	if again, changedAgain := ApplyBlock(content, block); changedAgain || again != content {
+		t.Errorf("expected the block to be added only once")
+	}
+
+	// updated block replaces the previous one
+	updatedBlock, _ := Snippet("bash", "/usr/local/envcli")
+	updated, changed := ApplyBlock(content+"export EDITOR=vim\n", updatedBlock)
+	if !changed || strings.Count(updated, BeginMarker) != 1 || !strings.Contains(updated, "/usr/local/envcli") || strings.Contains(updated, "/opt/envcli") {
+		t.Errorf("unexpected content after update:\n%s", updated)
+	}
+
+	removed, changed := RemoveBlock(content + "export EDITOR=vim\n")
+	if !changed || removed != original+"\nexport EDITOR=vim\n" {
+		t.Errorf("unexpected content after remove:\n%q", removed)
+	}
+	if _, changed = RemoveBlock(original); changed {
+		t.Errorf("expected no change without a block")
+	}
+}
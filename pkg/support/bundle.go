@@ -0,0 +1,41 @@
+package support
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// WriteBundle writes the files into a zip archive, the keys are the names within the archive
+func WriteBundle(file string, files map[string][]byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	archive := zip.NewWriter(f)
+	for _, name := range names {
+		w, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(files[name]); err != nil {
+			return err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
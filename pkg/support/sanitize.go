@@ -0,0 +1,112 @@
+// Package support creates support bundles, which contain the logs and the configuration with all secrets removed.
+package support
+
+import (
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Mask replaces removed secrets
+const Mask = "***"
+
+var (
+	// urlCredentialsPattern matches the userinfo of urls, ex. the credentials of a proxy url
+	urlCredentialsPattern = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/@\s"']+@`)
+
+	// sensitiveAssignmentPattern matches assignments to sensitive variables, ex. GITHUB_TOKEN=value
+	sensitiveAssignmentPattern = regexp.MustCompile(`(?i)\b([a-z0-9_]*(?:token|secret|password|passwd|apikey|api_key|credential|auth)[a-z0-9_]*)=([^\s"',]+)`)
+
+	// sensitiveKeyPattern matches the names of keys with sensitive values
+	sensitiveKeyPattern = regexp.MustCompile(`(?i)(token|secret|password|passwd|apikey|api_key|credential|auth)`)
+)
+
+// SanitizeText removes the credentials of urls and the values of sensitive variables from the text
+func SanitizeText(text string) string {
+	text = urlCredentialsPattern.ReplaceAllString(text, "${1}"+Mask+"@")
+	return sensitiveAssignmentPattern.ReplaceAllString(text, "${1}="+Mask)
+}
+
+// SanitizeProperties returns a copy of the properties with the secrets removed
+func SanitizeProperties(properties map[string]string) map[string]string {
+	result := make(map[string]string, len(properties))
+	for key, value := range properties {
+		if sensitiveKeyPattern.MatchString(key) {
+			result[key] = Mask
+		} else {
+			result[key] = SanitizeText(value)
+		}
+	}
+	return result
+}
+
+// SanitizeConfig removes the secrets from a envcli configuration file: the values of env entries, the values of sensitive keys and the credentials of urls
+func SanitizeConfig(content []byte) ([]byte, error) {
+	var document yaml.MapSlice
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(sanitizeValue("", document))
+}
+
+func sanitizeValue(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		for i := range v {
+			itemKey, _ := v[i].Key.(string)
+			v[i].Value = sanitizeValue(itemKey, v[i].Value)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			if s, ok := v[i].(string); ok && key == "env" {
+				v[i] = maskEnvValue(s)
+			} else {
+				v[i] = sanitizeValue(key, v[i])
+			}
+		}
+		return v
+	case string:
+		if sensitiveKeyPattern.MatchString(key) {
+			return Mask
+		}
+		return SanitizeText(v)
+	}
+	return value
+}
+
+// maskEnvValue masks the value of a env entry in the KEY=value format, the key is kept
+func maskEnvValue(entry string) string {
+	if index := strings.Index(entry, "="); index >= 0 {
+		return entry[:index+1] + Mask
+	}
+	return entry
+}
+
+// SanitizeArgs returns a copy of the arguments with the secrets removed, the values of sensitive flags (ex. --password secret, --token=secret) are masked
+func SanitizeArgs(args []string) []string {
+	result := make([]string, 0, len(args))
+	maskNext := false
+	for _, arg := range args {
+		if maskNext && !strings.HasPrefix(arg, "-") {
+			result = append(result, Mask)
+			maskNext = false
+			continue
+		}
+		maskNext = false
+
+		if strings.HasPrefix(arg, "-") {
+			name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+			if sensitiveKeyPattern.MatchString(name) {
+				if hasValue {
+					arg = arg[:strings.Index(arg, "=")+1] + Mask
+				} else {
+					maskNext = true
+				}
+			}
+		}
+		result = append(result, SanitizeText(arg))
+	}
+	return result
+}
@@ -1,9 +1,21 @@
 package updater
 
+import "github.com/EnvCLI/EnvCLI/pkg/download"
+
 /**
  * The Application Update Configuration
  */
 type ApplicationUpdater struct {
-	GitHubOrg         string
-	GitHubRepository  string
+	GitHubOrg        string
+	GitHubRepository string
+	DownloadCacheDir string
+
+	// MaxRetries is the number of additional attempts of the download after transient errors, each attempt resumes the download
+	MaxRetries int
+
+	// SkipChecksum installs releases without checksum file (older releases) without verification, a mismatching checksum is still rejected
+	SkipChecksum bool
+
+	// Progress is called whenever data of the download has been received, if set
+	Progress func(progress download.Progress)
 }
@@ -2,22 +2,26 @@ package updater
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/rs/zerolog/log"
+	"io"
 	"net/http"
+	"os"
 	"runtime"
 	"strings"
 
+	"github.com/EnvCLI/EnvCLI/pkg/download"
+	"github.com/EnvCLI/EnvCLI/pkg/exitcode"
 	"github.com/blang/semver"
 	github "github.com/google/go-github/v26/github"
 	update "github.com/inconshreveable/go-update"
+	"github.com/rs/zerolog/log"
 )
 
 // Find the latest version of the applicaton
-func (appUpdater ApplicationUpdater) getLatestVersion() string {
+func (appUpdater ApplicationUpdater) getLatestVersion(ctx context.Context) string {
 	// Get Latest Version from Link
 	var version = ""
-	ctx := context.Background()
 	client := github.NewClient(nil)
 	opt := &github.ListOptions{Page: 0, PerPage: 500}
 	tags, _, err := client.Repositories.ListTags(ctx, appUpdater.GitHubOrg, appUpdater.GitHubRepository, opt)
@@ -29,7 +33,7 @@ func (appUpdater ApplicationUpdater) getLatestVersion() string {
 	// Find newest tag
 	currentVersion, _ := semver.Make("0.0.0")
 	for _, tag := range tags {
-		log.Debug().Msg("Found Tag in Source Repository: "+*tag.Name+" ["+*tag.Commit.SHA+"]")
+		log.Debug().Msg("Found Tag in Source Repository: " + *tag.Name + " [" + *tag.Commit.SHA + "]")
 
 		tagVersion, err := semver.Make(strings.TrimLeft(*tag.Name, "v"))
 		if err != nil {
@@ -43,105 +47,173 @@ func (appUpdater ApplicationUpdater) getLatestVersion() string {
 		}
 	}
 
-	log.Debug().Msg("Latest version is "+version+".")
+	log.Debug().Msg("Latest version is " + version + ".")
 	return version
 }
 
-// applyUpdate ...
-func applyUpdate(resp *http.Response) {
+// applyUpdate replaces the running binary
+func applyUpdate(binary io.Reader) error {
 	opts := update.Options{}
-	err := opts.CheckPermissions()
+	if err := opts.CheckPermissions(); err != nil {
+		return fmt.Errorf("missing permissions, the update can't be applied: %w", err)
+	}
+	if err := update.Apply(binary, opts); err != nil {
+		if rerr := update.RollbackError(err); rerr != nil {
+			return fmt.Errorf("broken update, failed to rollback - please reinstall the application: %w", err)
+		}
+		return fmt.Errorf("broken update detected, aborted: %w", err)
+	}
+	return nil
+}
+
+// checksumFile is the release asset listing the sha256 checksums of all binaries of the release, as written by sha256sum
+const checksumFile = "checksums.txt"
+
+// ErrNoChecksumFile is returned for releases without checksum file, their downloads can't be verified
+var ErrNoChecksumFile = errors.New("the release publishes no " + checksumFile)
+
+// releaseBaseURL is the url of the release downloads, replaced by the tests
+var releaseBaseURL = "https://github.com/EnvCLI/EnvCLI/releases/download"
+
+// releaseURL returns the download url of a asset of the release
+func releaseURL(version string, asset string) string {
+	return fmt.Sprintf("%s/%s/%s", releaseBaseURL, version, asset)
+}
+
+// fetchChecksum downloads the checksum file of the release and returns the checksum of the asset
+func fetchChecksum(ctx context.Context, client *http.Client, checksumURL string, asset string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, checksumURL, nil)
 	if err != nil {
-		log.Error().Err(err).Msg("Missing permissions, update can't be executed: "+err.Error())
-		return
+		return "", err
 	}
-	err = update.Apply(resp.Body, opts)
+	resp, err := client.Do(req)
 	if err != nil {
-		if rerr := update.RollbackError(err); rerr != nil {
-			log.Error().Err(err).Msg("Broken update, failed to rollback. Please reinstall the application.")
-		} else {
-			log.Error().Err(err).Msg("Broken update detected, aborted.")
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNoChecksumFile
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: %s", checksumURL, resp.Status)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	return parseChecksum(string(content), asset)
+}
+
+// parseChecksum returns the checksum of the asset from a sha256sum file (<checksum>  <file>)
+func parseChecksum(content string, asset string) (string, error) {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == asset {
+			return fields[0], nil
 		}
-		return
 	}
+	return "", fmt.Errorf("no checksum found for %s", asset)
 }
 
-// newVersionDownloader ...
-func (appUpdater ApplicationUpdater) newVersionDownloader(version string) {
-	var downloadURL = fmt.Sprintf("https://github.com/EnvCLI/EnvCLI/releases/download/%s/%s_%s", version, runtime.GOOS, runtime.GOARCH)
+// newVersionDownloader downloads the version and replaces the binary, interrupted downloads are resumed
+func (appUpdater ApplicationUpdater) newVersionDownloader(ctx context.Context, version string) error {
+	asset := fmt.Sprintf("%s_%s", runtime.GOOS, runtime.GOARCH)
+	downloadURL := releaseURL(version, asset)
 	log.Debug().Msg("Starting download from remote: " + downloadURL)
 
+	// the checksum of the release protects against corrupted and tampered downloads
+	downloader := download.New(appUpdater.DownloadCacheDir)
+	downloader.Retries = appUpdater.MaxRetries
+	downloader.Progress = appUpdater.Progress
+	checksum, err := fetchChecksum(ctx, downloader.Client, releaseURL(version, checksumFile), asset)
+	if errors.Is(err, ErrNoChecksumFile) && appUpdater.SkipChecksum {
+		log.Warn().Str("version", version).Msg("the release publishes no " + checksumFile + ", the download isn't verified")
+	} else if errors.Is(err, ErrNoChecksumFile) {
+		return fmt.Errorf("version %s can't be verified, %w - use --skip-checksum to install it without verification", version, err)
+	} else if err != nil {
+		return fmt.Errorf("failed to get the checksum of version %s: %w", version, err)
+	}
+
 	// download new version
-	resp, err := http.Get(downloadURL)
+	file, err := downloader.Download(ctx, downloadURL, checksum)
 	if err != nil {
-		log.Error().Err(err).Msg("Unexpected Error: "+err.Error())
-		return
+		return fmt.Errorf("failed to download version %s: %w", version, err)
 	}
-	if resp.StatusCode != 200 {
-		log.Error().Msg("Update not found on remote server ... aborting.")
-		return
+
+	// validate the artifact before it replaces the running binary
+	if err = ValidateBinary(file, runtime.GOOS); err != nil {
+		_ = os.Remove(file)
+		return fmt.Errorf("the downloaded update is invalid: %w", err)
 	}
 
-	defer resp.Body.Close()
-	applyUpdate(resp)
+	binary, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer binary.Close()
+	return applyUpdate(binary)
 }
 
-// Update interface
-func (appUpdater ApplicationUpdater) Update(version string, force bool, appVersion string) {
+// Update downloads the version (latest for the newest release) and replaces the running binary
+func (appUpdater ApplicationUpdater) Update(ctx context.Context, version string, force bool, appVersion string) error {
 	// current application version
 	applicationVersion, err := semver.Make(strings.TrimLeft(appVersion, "v"))
 	if err != nil {
-		log.Error().Err(err).Msg("Unexpected Error: "+err.Error())
-		return
+		return fmt.Errorf("invalid application version %s: %w", appVersion, err)
 	}
 
 	// set to latest version of no version is specified
 	if version == "latest" {
-		version = appUpdater.getLatestVersion()
+		version = appUpdater.getLatestVersion(ctx)
+		if version == "" {
+			return errors.New("failed to determine the latest version")
+		}
 	}
 
 	// update target version
 	updateTargetVersion, err := semver.Make(strings.TrimLeft(version, "v"))
 	if err != nil {
-		log.Error().Err(err).Msg("Unexpected Error: "+err.Error())
-		return
+		return exitcode.New(exitcode.ConfigError, fmt.Errorf("invalid target version %s: %w", version, err))
 	}
 
 	if applicationVersion.Compare(updateTargetVersion) == 0 && force == false {
 		log.Info().Msg("No update available, already at the latest version!")
-		return
+		return nil
 	}
 
 	if force == true {
 		log.Debug().Msg("Initiating forced update to version: " + updateTargetVersion.String())
 	}
-	appUpdater.newVersionDownloader(version)
+	if err = appUpdater.newVersionDownloader(ctx, version); err != nil {
+		return err
+	}
 
 	// Log Result
 	if applicationVersion.GT(updateTargetVersion) {
-		log.Info().Msg("Successfully downgraded from ["+applicationVersion.String()+"] to ["+updateTargetVersion.String()+"]!")
+		log.Info().Msg("Successfully downgraded from [" + applicationVersion.String() + "] to [" + updateTargetVersion.String() + "]!")
 	} else if applicationVersion.LT(updateTargetVersion) {
-		log.Info().Msg("Successfully upgraded from ["+applicationVersion.String()+"] to ["+updateTargetVersion.String()+"]!")
+		log.Info().Msg("Successfully upgraded from [" + applicationVersion.String() + "] to [" + updateTargetVersion.String() + "]!")
 	} else {
-		log.Info().Msg("Successfully downloaded ["+applicationVersion.String()+"]!")
+		log.Info().Msg("Successfully downloaded [" + applicationVersion.String() + "]!")
 	}
+	return nil
 }
 
 // Update interface
-func (appUpdater ApplicationUpdater) IsUpdateAvailable(appVersion string) bool {
+func (appUpdater ApplicationUpdater) IsUpdateAvailable(ctx context.Context, appVersion string) bool {
 	// current application version
 	applicationVersion, err := semver.Make(strings.TrimLeft(appVersion, "v"))
 	if err != nil {
-		log.Error().Err(err).Msg("Unexpected Error: "+err.Error())
+		log.Error().Err(err).Msg("Unexpected Error: " + err.Error())
 		return false
 	}
 
-	var version = appUpdater.getLatestVersion()
+	var version = appUpdater.getLatestVersion(ctx)
 
 	// update target version
 	updateTargetVersion, err := semver.Make(strings.TrimLeft(version, "v"))
 	if err != nil {
-		log.Error().Err(err).Msg("Unexpected Error: "+err.Error())
+		log.Error().Err(err).Msg("Unexpected Error: " + err.Error())
 		return false
 	}
 
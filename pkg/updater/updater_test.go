@@ -0,0 +1,73 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const checksums = `9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08  linux_amd64
+60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752 *windows_amd64
+`
+
+func TestFetchChecksum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1.0.0/checksums.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(checksums))
+	}))
+	defer server.Close()
+
+	checksum, err := fetchChecksum(context.Background(), server.Client(), server.URL+"/v1.0.0/checksums.txt", "linux_amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checksum != "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08" {
+		t.Errorf("unexpected checksum %s", checksum)
+	}
+
+	// binary mode marker of sha256sum
+	checksum, err = fetchChecksum(context.Background(), server.Client(), server.URL+"/v1.0.0/checksums.txt", "windows_amd64")
+	if err != nil || checksum != "60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752" {
+		t.Errorf("unexpected checksum %s: %v", checksum, err)
+	}
+
+	// a release without a checksum must not be installed
+	if _, err = fetchChecksum(context.Background(), server.Client(), server.URL+"/v1.0.0/checksums.txt", "darwin_arm64"); err == nil {
+		t.Error("expected a error for a asset without checksum")
+	}
+	if _, err = fetchChecksum(context.Background(), server.Client(), server.URL+"/v0.9.0/checksums.txt", "linux_amd64"); !errors.Is(err, ErrNoChecksumFile) {
+		t.Errorf("expected a error for a release without checksum file, got %v", err)
+	}
+}
+
+func TestNewVersionDownloaderWithoutChecksumFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/checksums.txt") {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("not a binary"))
+	}))
+	defer server.Close()
+	previous := releaseBaseURL
+	releaseBaseURL = server.URL
+	t.Cleanup(func() { releaseBaseURL = previous })
+
+	// the download isn't verified, so it's rejected unless requested explicitly
+	appUpdater := ApplicationUpdater{DownloadCacheDir: t.TempDir()}
+	if err := appUpdater.newVersionDownloader(context.Background(), "v0.9.0"); !errors.Is(err, ErrNoChecksumFile) || !strings.Contains(err.Error(), "--skip-checksum") {
+		t.Errorf("expected a error for a release without checksum file, got %v", err)
+	}
+
+	// the downloaded file is still validated before it replaces the binary
+	appUpdater.SkipChecksum = true
+	if err := appUpdater.newVersionDownloader(context.Background(), "v0.9.0"); err == nil || !strings.Contains(err.Error(), "the downloaded update is invalid") {
+		t.Errorf("expected the download to be validated, got %v", err)
+	}
+}
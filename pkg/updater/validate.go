@@ -0,0 +1,43 @@
+package updater
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// executableMagics are the leading bytes of the executables of each operating system: ELF, Mach-O (32/64 bit, both byte orders, universal) and PE
+var executableMagics = map[string][][]byte{
+	"linux":   {{0x7f, 'E', 'L', 'F'}},
+	"darwin":  {{0xfe, 0xed, 0xfa, 0xce}, {0xfe, 0xed, 0xfa, 0xcf}, {0xce, 0xfa, 0xed, 0xfe}, {0xcf, 0xfa, 0xed, 0xfe}, {0xca, 0xfe, 0xba, 0xbe}},
+	"windows": {{'M', 'Z'}},
+}
+
+// ValidateBinary checks that the file is a executable of the operating system, ex. not a truncated download or the html of a error page
+func ValidateBinary(file string, goos string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	header := make([]byte, 4)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return fmt.Errorf("%s is not a executable: %w", file, err)
+	}
+	header = header[:n]
+
+	magics, known := executableMagics[goos]
+	if !known {
+		// other operating systems use ELF
+		magics = executableMagics["linux"]
+	}
+	for _, magic := range magics {
+		if bytes.HasPrefix(header, magic) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not a executable for %s", file, goos)
+}
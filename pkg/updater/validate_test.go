@@ -0,0 +1,43 @@
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateBinary(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	elf := write("elf", "\x7fELF\x02\x01\x01")
+	macho := write("macho", "\xcf\xfa\xed\xfe\x07")
+	pe := write("pe", "MZ\x90\x00")
+	html := write("html", "<!DOCTYPE html>")
+	empty := write("empty", "")
+
+	tests := []struct {
+		file  string
+		goos  string
+		valid bool
+	}{
+		{elf, "linux", true},
+		{elf, "freebsd", true},
+		{macho, "darwin", true},
+		{pe, "windows", true},
+		{elf, "windows", false},
+		{pe, "linux", false},
+		{html, "darwin", false},
+		{empty, "linux", false},
+	}
+	for _, test := range tests {
+		if err := ValidateBinary(test.file, test.goos); (err == nil) != test.valid {
+			t.Errorf("%s on %s: expected valid=%v, got %v", filepath.Base(test.file), test.goos, test.valid, err)
+		}
+	}
+}
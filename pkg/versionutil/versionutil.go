@@ -0,0 +1,206 @@
+// Package versionutil compares the versions of the container runtimes, which don't strictly follow semver (ex. 17.06.0-ce, 20.10, v1.7.0, 20.10.21-0ubuntu1, 28.0.0+dfsg1).
+package versionutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Version is a parsed version, missing segments are zero (ex. 20.10 is 20.10.0)
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+
+	// Prerelease are the dot separated identifiers of a pre-release (ex. rc.1), empty for releases
+	Prerelease []string
+}
+
+// editions are the suffixes of the docker editions, they are releases and not pre-releases
+var editions = []string{"ce", "ee"}
+
+// Parse parses the version, the v prefix, build metadata (+...) and segments after the patch version are ignored.
+// A suffix is a pre-release if it starts with a letter (ex. -rc.1, -beta2, -dev), editions (-ce, -ee) and distribution revisions (ex. -0ubuntu1) are releases.
+func Parse(version string) (Version, error) {
+	value := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.Index(value, "+"); i >= 0 {
+		value = value[:i]
+	}
+
+	var result Version
+	core, suffix, _ := strings.Cut(value, "-")
+	segments := strings.Split(core, ".")
+	if core == "" {
+		return Version{}, fmt.Errorf("invalid version %q", version)
+	}
+	for i, segment := range segments {
+		number, err := strconv.Atoi(segment)
+		if err != nil || number < 0 {
+			return Version{}, fmt.Errorf("invalid version %q: segment %q is not a number", version, segment)
+		}
+		switch i {
+		case 0:
+			result.Major = number
+		case 1:
+			result.Minor = number
+		case 2:
+			result.Patch = number
+		}
+	}
+
+	if suffix != "" && unicode.IsLetter(rune(suffix[0])) && !isEdition(suffix) {
+		result.Prerelease = strings.Split(suffix, ".")
+	}
+	return result, nil
+}
+
+func isEdition(suffix string) bool {
+	for _, edition := range editions {
+		if strings.EqualFold(suffix, edition) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare returns -1 if the version is lower than the other version, 0 if they are equal and 1 if it is greater.
+// Pre-releases are lower than the release, their identifiers are compared like semver does.
+func (v Version) Compare(other Version) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if pair[0] != pair[1] {
+			return compareInt(pair[0], pair[1])
+		}
+	}
+
+	switch {
+	case len(v.Prerelease) == 0 && len(other.Prerelease) == 0:
+		return 0
+	case len(v.Prerelease) == 0:
+		return 1
+	case len(other.Prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Prerelease) && i < len(other.Prerelease); i++ {
+		if result := compareIdentifier(v.Prerelease[i], other.Prerelease[i]); result != 0 {
+			return result
+		}
+	}
+	return compareInt(len(v.Prerelease), len(other.Prerelease))
+}
+
+func (v Version) String() string {
+	result := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		result += "-" + strings.Join(v.Prerelease, ".")
+	}
+	return result
+}
+
+// compareIdentifier compares numeric identifiers numerically, they are lower than alphanumeric identifiers
+func compareIdentifier(a string, b string) int {
+	numberA, errA := strconv.Atoi(a)
+	numberB, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		return compareInt(numberA, numberB)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a int, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// operators are the supported comparison operators, longer operators first
+var operators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+// constraint is a single comparison, ex. >=20.10
+type constraint struct {
+	operator string
+	version  Version
+}
+
+// Constraints are comparisons that must all be satisfied, ex. >=20.10 <25
+type Constraints struct {
+	raw         string
+	constraints []constraint
+}
+
+// ParseConstraints parses comparisons separated by spaces or commas (ex. ">=20.10, <25"), a version without operator is a minimum version (>=).
+// Spaces between the operator and the version are allowed (ex. ">= 20.10").
+func ParseConstraints(value string) (Constraints, error) {
+	result := Constraints{raw: strings.TrimSpace(value)}
+
+	fields := strings.Fields(strings.ReplaceAll(value, ",", " "))
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		operator := ">="
+		for _, op := range operators {
+			if strings.HasPrefix(field, op) {
+				operator = op
+				field = strings.TrimPrefix(field, op)
+				break
+			}
+		}
+		if field == "" && i+1 < len(fields) {
+			i++
+			field = fields[i]
+		}
+
+		version, err := Parse(field)
+		if err != nil {
+			return Constraints{}, fmt.Errorf("invalid version constraint %q: %w", value, err)
+		}
+		if operator == "==" {
+			operator = "="
+		}
+		result.constraints = append(result.constraints, constraint{operator: operator, version: version})
+	}
+	if len(result.constraints) == 0 {
+		return Constraints{}, fmt.Errorf("invalid version constraint %q: no version", value)
+	}
+
+	return result, nil
+}
+
+// Check returns true if the version satisfies all constraints
+func (c Constraints) Check(version Version) bool {
+	for _, constraint := range c.constraints {
+		result := version.Compare(constraint.version)
+		var ok bool
+		switch constraint.operator {
+		case ">=":
+			ok = result >= 0
+		case "<=":
+			ok = result <= 0
+		case ">":
+			ok = result > 0
+		case "<":
+			ok = result < 0
+		case "=":
+			ok = result == 0
+		case "!=":
+			ok = result != 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Constraints) String() string {
+	return c.raw
+}
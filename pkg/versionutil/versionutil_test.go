@@ -0,0 +1,100 @@
+package versionutil
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]string{
+		"20.10.21":                  "20.10.21",
+		"20.10":                     "20.10.0",
+		"v1.7.0":                    "1.7.0",
+		"17.06.0-ce":                "17.6.0",
+		"18.09.1-ee":                "18.9.1",
+		"20.10.21-0ubuntu1~20.04.2": "20.10.21",
+		"28.0.0+dfsg1":              "28.0.0",
+		"24.0.0-rc.1":               "24.0.0-rc.1",
+		"5.0.0-rc1":                 "5.0.0-rc1",
+		"4.9.4-dev":                 "4.9.4-dev",
+		" 26.1.3\n":                 "26.1.3",
+		"1.13.1.2":                  "1.13.1",
+	}
+	for input, expected := range tests {
+		version, err := Parse(input)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", input, err)
+			continue
+		}
+		if version.String() != expected {
+			t.Errorf("%q: expected %s, got %s", input, expected, version)
+		}
+	}
+
+	for _, input := range []string{"", "latest", "20.x", "v"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"20.10.21", "20.10", 1},
+		{"20.10", "20.10.0", 0},
+		{"19.03.12", "20.10", -1},
+		{"17.06.0-ce", "17.6.0", 0},
+		{"24.0.0-rc.1", "24.0.0", -1},
+		{"24.0.0-rc.2", "24.0.0-rc.10", -1},
+		{"24.0.0-beta.1", "24.0.0-rc.1", -1},
+		{"24.0.0-rc", "24.0.0-rc.1", -1},
+		{"24.0.0-rc.1", "23.0.6", 1},
+	}
+	for _, test := range tests {
+		a, _ := Parse(test.a)
+		b, _ := Parse(test.b)
+		if result := a.Compare(b); result != test.expected {
+			t.Errorf("%s <=> %s: expected %d, got %d", test.a, test.b, test.expected, result)
+		}
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{">=20.10", "20.10.21", true},
+		{">=20.10", "19.03.12", false},
+		{">= 20.10", "20.10.0", true},
+		{"20.10", "24.0.7", true},
+		{">=20.10, <25", "24.0.7", true},
+		{">=20.10 <25", "25.0.0", false},
+		{">=20.10 <25", "25.0.0-rc.1", true},
+		{"=4.9.3", "4.9.3", true},
+		{"==4.9.3", "4.9.4", false},
+		{"!=4.9.3", "4.9.4", true},
+		{">4", "4.0.0", false},
+		{"<=1.7", "v1.7.0", true},
+	}
+	for _, test := range tests {
+		constraints, err := ParseConstraints(test.constraint)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", test.constraint, err)
+			continue
+		}
+		version, _ := Parse(test.version)
+		if result := constraints.Check(version); result != test.expected {
+			t.Errorf("%s %q: expected %v, got %v", test.version, test.constraint, test.expected, result)
+		}
+	}
+
+	for _, constraint := range []string{"", ">=", ">=latest", "~> 1.0"} {
+		if _, err := ParseConstraints(constraint); err == nil {
+			t.Errorf("%q: expected an error", constraint)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+// Package watch detects changes of the files matching a set of globs, using file system notifications or polling where those are unsupported.
+package watch
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/ignore"
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultInterval is the interval used to poll for changes
+const DefaultInterval = 500 * time.Millisecond
+
+// DefaultDebounce is the duration without further changes, before the changes are reported
+const DefaultDebounce = 300 * time.Millisecond
+
+// skippedDirectories are never walked, they change often and never contain sources
+var skippedDirectories = []string{".git", ".hg", ".svn", "node_modules"}
+
+// Watcher watches the files within the root directory that match the patterns
+type Watcher struct {
+	// Root is the directory containing the files, patterns are relative to it
+	Root string
+
+	// Patterns are globs of the watched files (ex. src/**/*.go), ** matches any number of directories
+	Patterns []string
+
+	// Ignore are globs of files and directories that are never watched
+	Ignore []string
+
+	// Excludes are the files and directories excluded by the ignore file of the project, optional
+	Excludes *ignore.Matcher
+
+	// Poll detects changes by polling instead of file system notifications, it is used automatically if notifications are unsupported
+	Poll bool
+
+	// Interval is used to poll for changes
+	Interval time.Duration
+	Debounce time.Duration
+
+	// compiled patterns and ignore globs
+	patterns []*regexp.Regexp
+	ignore   []*regexp.Regexp
+
+	// notify receives the file system notifications of the watched directories, nil if polling
+	notify *fsnotify.Watcher
+	dirs   map[string]bool
+
+	// state of the files when the last change has been reported
+	state map[string]fileState
+}
+
+// fileState is the state used to detect modifications of a file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Reset records the current state of the files, Wait reports the changes after this point in time
+func (w *Watcher) Reset() error {
+	if w.patterns == nil {
+		w.patterns = compileGlobs(w.Patterns)
+		w.ignore = compileGlobs(w.Ignore)
+	}
+	if !w.Poll && w.notify == nil {
+		notify, err := fsnotify.NewWatcher()
+		if err != nil {
+			log.Debug().Err(err).Msg("file system notifications are unsupported, polling for changes")
+			w.Poll = true
+		} else {
+			w.notify, w.dirs = notify, make(map[string]bool)
+		}
+	}
+
+	state, err := w.snapshot()
+	w.state = state
+	return err
+}
+
+// Close stops the file system notifications
+func (w *Watcher) Close() error {
+	if w.notify == nil {
+		return nil
+	}
+	err := w.notify.Close()
+	w.notify = nil
+	return err
+}
+
+// Wait blocks until a watched file has been created, modified or removed and no further change happened for the debounce duration.
+// Changes are detected since the last call of Reset or Wait. It returns the changed files (relative to the root) or the error of the context.
+func (w *Watcher) Wait(ctx context.Context) ([]string, error) {
+	debounce := w.Debounce
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+
+	if w.state == nil {
+		if err := w.Reset(); err != nil {
+			return nil, err
+		}
+	}
+	if w.notify == nil {
+		return w.poll(ctx, debounce)
+	}
+
+	// each notification restarts the debounce, the first check also reports changes from before the call
+	timer := time.NewTimer(debounce)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case event, ok := <-w.notify.Events:
+			if !ok {
+				return nil, errors.New("the watcher has been closed")
+			}
+			if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				// removed directories lose their watch, they are watched again once recreated
+				delete(w.dirs, event.Name)
+			}
+			resetTimer(timer, debounce)
+		case err, ok := <-w.notify.Errors:
+			if !ok {
+				return nil, errors.New("the watcher has been closed")
+			}
+			// ex. a overflow of the event queue, the next check compares the files
+			log.Debug().Err(err).Msg("file system notification error")
+			resetTimer(timer, debounce)
+		case <-timer.C:
+			current, err := w.snapshot()
+			if err != nil {
+				return nil, err
+			}
+			if files := diff(w.state, current); len(files) > 0 {
+				w.state = current
+				log.Debug().Strs("files", files).Msg("detected file changes")
+				return files, nil
+			}
+		}
+	}
+}
+
+// poll compares the state of the files in each interval
+func (w *Watcher) poll(ctx context.Context, debounce time.Duration) ([]string, error) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	var changed []string
+	var lastChange time.Time
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		current, err := w.snapshot()
+		if err != nil {
+			return nil, err
+		}
+		if files := diff(w.state, current); len(files) > 0 {
+			changed = mergeFiles(changed, files)
+			lastChange = time.Now()
+			w.state = current
+			log.Debug().Strs("files", files).Msg("detected file changes")
+		} else if len(changed) > 0 && time.Since(lastChange) >= debounce {
+			return changed, nil
+		}
+	}
+}
+
+func resetTimer(timer *time.Timer, duration time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+	timer.Reset(duration)
+}
+
+// snapshot returns the state of all watched files, new directories are added to the file system notifications
+func (w *Watcher) snapshot() (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	err := filepath.WalkDir(w.Root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// files can be removed while walking the directory
+			return nil
+		}
+		relative, relErr := filepath.Rel(w.Root, path)
+		if relErr != nil {
+			return nil
+		}
+		relative = filepath.ToSlash(relative)
+
+		if entry.IsDir() {
+			if relative != "." {
+				for _, skipped := range skippedDirectories {
+					if entry.Name() == skipped {
+						return filepath.SkipDir
+					}
+				}
+				if matchesAny(w.ignore, relative) || matchesAny(w.ignore, relative+"/") || w.Excludes.Match(relative, true) {
+					return filepath.SkipDir
+				}
+			}
+			w.watchDirectory(path)
+			return nil
+		}
+
+		if !matchesAny(w.patterns, relative) || matchesAny(w.ignore, relative) || w.Excludes.Match(relative, false) {
+			return nil
+		}
+		info, infoErr := entry.Info()
+		if infoErr != nil {
+			return nil
+		}
+		files[relative] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+
+	return files, err
+}
+
+// watchDirectory adds the directory to the file system notifications, if it isn't watched yet
+func (w *Watcher) watchDirectory(dir string) {
+	if w.notify == nil || w.dirs[dir] {
+		return
+	}
+	if err := w.notify.Add(dir); err != nil {
+		// ex. the limit of watches has been reached, the changes within the directory are still detected by later checks
+		log.Debug().Err(err).Str("directory", dir).Msg("failed to watch directory")
+		return
+	}
+	w.dirs[dir] = true
+}
+
+// diff returns the files that have been created, modified or removed
+func diff(previous map[string]fileState, current map[string]fileState) []string {
+	var files []string
+	for file, state := range current {
+		if previousState, found := previous[file]; !found || previousState != state {
+			files = append(files, file)
+		}
+	}
+	for file := range previous {
+		if _, found := current[file]; !found {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+func mergeFiles(files []string, added []string) []string {
+	for _, file := range added {
+		found := false
+		for _, existing := range files {
+			if existing == file {
+				found = true
+				break
+			}
+		}
+		if !found {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+func matchesAny(patterns []*regexp.Regexp, path string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileGlobs(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		compiled = append(compiled, compileGlob(pattern))
+	}
+	return compiled
+}
+
+// MatchGlob returns true if the slash-separated path matches the glob: * matches within a path segment, ** across segments and **/ any number of directories (including none)
+func MatchGlob(pattern string, path string) bool {
+	return compileGlob(pattern).MatchString(path)
+}
+
+// compileGlob converts the glob into a regular expression
+func compileGlob(pattern string) *regexp.Regexp {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		}
+	}
+	expr.WriteString("$")
+
+	// all other characters are quoted, so the expression is always valid
+	return regexp.MustCompile(expr.String())
+}
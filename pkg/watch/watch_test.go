@@ -0,0 +1,101 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EnvCLI/EnvCLI/pkg/ignore"
+)
+
+func TestMatchGlob(t *testing.T) {
+	var tests = []struct {
+		pattern string
+		path    string
+		matches bool
+	}{
+		{"src/**/*.go", "src/main.go", true},
+		{"src/**/*.go", "src/pkg/cmd/run.go", true},
+		{"src/**/*.go", "main.go", false},
+		{"**/*.go", "main.go", true},
+		{"*.go", "pkg/main.go", false},
+		{"./*.md", "README.md", true},
+		{"vendor/**", "vendor/github.com/x.go", true},
+		{"docs/?.md", "docs/a.md", true},
+		{"docs/?.md", "docs/ab.md", false},
+	}
+
+	for _, test := range tests {
+		if matches := MatchGlob(test.pattern, test.path); matches != test.matches {
+			t.Errorf("%s %s: expected %t, got %t", test.pattern, test.path, test.matches, matches)
+		}
+	}
+}
+
+func writeFile(t *testing.T, file string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWait(t *testing.T) {
+	testWait(t, false)
+}
+
+func TestWaitPolling(t *testing.T) {
+	testWait(t, true)
+}
+
+func testWait(t *testing.T, poll bool) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "src", "main.go"), "package main")
+	watcher := &Watcher{Root: root, Patterns: []string{"src/**/*.go"}, Ignore: []string{"src/gen/**"}, Excludes: ignore.New([]string{"*_test.go"}), Poll: poll, Interval: 10 * time.Millisecond, Debounce: 30 * time.Millisecond}
+	if err := watcher.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+	if !poll && watcher.notify == nil {
+		t.Fatal("expected file system notifications to be used")
+	}
+
+	// changes before waiting are reported as well, ignored and unmatched files are not
+	writeFile(t, filepath.Join(root, "src", "gen", "types.go"), "package gen")
+	writeFile(t, filepath.Join(root, "README.md"), "readme")
+	writeFile(t, filepath.Join(root, "src", "main_test.go"), "package main")
+	writeFile(t, filepath.Join(root, "src", "main.go"), "package main\n")
+	writeFile(t, filepath.Join(root, "src", "util", "util.go"), "package util")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	changed, err := watcher.Wait(ctx)
+	if err != nil || strings.Join(changed, ",") != "src/main.go,src/util/util.go" {
+		t.Errorf("unexpected changes %v (%v)", changed, err)
+	}
+
+	// removed files
+	if err = os.Remove(filepath.Join(root, "src", "util", "util.go")); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err = watcher.Wait(ctx); err != nil || strings.Join(changed, ",") != "src/util/util.go" {
+		t.Errorf("unexpected changes %v (%v)", changed, err)
+	}
+}
+
+func TestWaitCancel(t *testing.T) {
+	watcher := &Watcher{Root: t.TempDir(), Patterns: []string{"**"}, Interval: 10 * time.Millisecond}
+	defer watcher.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	if _, err := watcher.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled error, got %v", err)
+	}
+}